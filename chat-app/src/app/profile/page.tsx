@@ -141,7 +141,7 @@ export default function ProfilePage() {
 }
 
 function ProfileContent() {
-  const { user, isAuthenticated, isLoading } = useAuth()
+  const { user, token, isAuthenticated, isLoading } = useAuth()
   const router = useRouter()
   const searchParams = useSearchParams()
   const initialTab = searchParams.get('tab') || 'profile'
@@ -201,9 +201,9 @@ function ProfileContent() {
   // Polling interval ref
   const pollingIntervalRef = useRef<NodeJS.Timeout | null>(null)
 
-  // Helper to check if a job is in progress (not completed or failed)
+  // Helper to check if a job is in progress (not completed, failed, waiting for review or dead-lettered)
   const isJobInProgress = useCallback((job: AnalysisJob) => {
-    return job.status !== 'completed' && job.status !== 'failed'
+    return job.status !== 'completed' && job.status !== 'failed' && job.status !== 'needs_review' && job.status !== 'dead_lettered'
   }, [])
 
   // Get all expanded uploads that have in-progress jobs
@@ -225,6 +225,7 @@ function ProfileContent() {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
       if (!response.ok) return null
@@ -232,7 +233,7 @@ function ProfileContent() {
     } catch {
       return null
     }
-  }, [backendUrl])
+  }, [backendUrl, token])
 
   // Update jobs for expanded uploads with in-progress jobs
   const updateInProgressJobs = useCallback(async () => {
@@ -333,10 +334,11 @@ function ProfileContent() {
 
     try {
       setUploadsLoading(true)
-      const response = await fetch(`${backendUrl}/api/uploads?user_id=${user.id}&limit=50`, {
+      const response = await fetch(`${backendUrl}/api/uploads?limit=50`, {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
@@ -345,7 +347,7 @@ function ProfileContent() {
       }
 
       const data = await response.json()
-      setUploads(data.uploads || [])
+      setUploads(data.items || [])
     } catch (err) {
       console.error('Failed to load uploads:', err)
     } finally {
@@ -419,6 +421,7 @@ function ProfileContent() {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
@@ -444,7 +447,10 @@ function ProfileContent() {
       case 'completed':
         return { bg: 'bg-green-100', text: 'text-green-700', icon: Check }
       case 'failed':
+      case 'dead_lettered':
         return { bg: 'bg-red-100', text: 'text-red-700', icon: AlertCircle }
+      case 'needs_review':
+        return { bg: 'bg-amber-100', text: 'text-amber-700', icon: AlertCircle }
       case 'queued':
         return { bg: 'bg-gray-100', text: 'text-gray-700', icon: Clock }
       default:
@@ -483,16 +489,25 @@ function ProfileContent() {
 
     const uploadId = uploadToDelete.id
 
-    try {
-      setDeletingId(uploadId)
-      const response = await fetch(`${backendUrl}/api/upload/delete?id=${uploadId}`, {
+    const deleteUpload = (force: boolean) =>
+      fetch(`${backendUrl}/api/upload/delete?id=${uploadId}${force ? '&force=true' : ''}`, {
         method: 'DELETE',
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
+    try {
+      setDeletingId(uploadId)
+      let response = await deleteUpload(false)
+
+      // The upload is still being analyzed; deleting it cancels the analysis
+      if (response.status === 409 && confirm('This resume is still being analyzed. Cancel the analysis and delete it?')) {
+        response = await deleteUpload(true)
+      }
+
       if (response.ok) {
         // Remove from uploads list
         setUploads(uploads.filter(u => u.id !== uploadId))
@@ -549,6 +564,7 @@ function ProfileContent() {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
@@ -598,6 +614,7 @@ function ProfileContent() {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
@@ -634,6 +651,7 @@ function ProfileContent() {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
       })
 
@@ -670,9 +688,9 @@ function ProfileContent() {
     }
   }
 
-  // Helper function to check if job is deletable (completed or failed)
+  // Helper function to check if job is deletable (completed, failed, waiting for review or dead-lettered)
   const isDeletable = (status: string) => {
-    return status === 'completed' || status === 'failed'
+    return status === 'completed' || status === 'failed' || status === 'needs_review' || status === 'dead_lettered'
   }
 
   // Get all deletable jobs for an upload
@@ -739,6 +757,7 @@ function ProfileContent() {
           'Content-Type': 'application/json',
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
         },
         body: JSON.stringify({
           job_ids: Array.from(selectedJobs)
@@ -1237,6 +1256,13 @@ function ProfileContent() {
                                                             >
                                                               CSV
                                                             </button>
+                                                            <button
+                                                              onClick={() => handleExport(job.job_id, 'csv_flat')}
+                                                              className="w-full px-4 py-2 text-left text-sm hover:bg-gray-100"
+                                                              title="One row per record, for Excel or Google Sheets"
+                                                            >
+                                                              CSV (flat)
+                                                            </button>
                                                             <button
                                                               onClick={() => handleExport(job.job_id, 'pdf')}
                                                               className="w-full px-4 py-2 text-left text-sm hover:bg-gray-100"
@@ -1266,7 +1292,7 @@ function ProfileContent() {
                                                       </button>
                                                     </>
                                                   )}
-                                                  {job.status === 'failed' && (
+                                                  {(job.status === 'failed' || job.status === 'needs_review' || job.status === 'dead_lettered') && (
                                                     <>
                                                       {job.error_message && (
                                                         <span
@@ -1276,18 +1302,21 @@ function ProfileContent() {
                                                           {job.error_message}
                                                         </span>
                                                       )}
-                                                      <button
-                                                        onClick={() => handleRetryJobClick(job, upload.id)}
-                                                        disabled={retryingJobId === job.job_id}
-                                                        className="p-2 text-blue-600 hover:bg-blue-100 rounded-lg transition disabled:opacity-50"
-                                                        title="Retry Job"
-                                                      >
-                                                        {retryingJobId === job.job_id ? (
-                                                          <Loader2 className="w-4 h-4 animate-spin" />
-                                                        ) : (
-                                                          <RefreshCw className="w-4 h-4" />
-                                                        )}
-                                                      </button>
+                                                      {/* Dead-lettered jobs used all their retries and need manual review */}
+                                                      {job.status !== 'dead_lettered' && (
+                                                        <button
+                                                          onClick={() => handleRetryJobClick(job, upload.id)}
+                                                          disabled={retryingJobId === job.job_id}
+                                                          className="p-2 text-blue-600 hover:bg-blue-100 rounded-lg transition disabled:opacity-50"
+                                                          title={job.status === 'needs_review' ? 'Approve Text and Analyze' : 'Retry Job'}
+                                                        >
+                                                          {retryingJobId === job.job_id ? (
+                                                            <Loader2 className="w-4 h-4 animate-spin" />
+                                                          ) : (
+                                                            <RefreshCw className="w-4 h-4" />
+                                                          )}
+                                                        </button>
+                                                      )}
                                                       <button
                                                         onClick={() => handleDeleteJobClick(job, upload.id)}
                                                         disabled={deletingJobId === job.job_id}
@@ -22,10 +22,11 @@ interface SavedQuestion {
 }
 
 interface PaginationInfo {
-  questions: SavedQuestion[]
+  items: SavedQuestion[]
+  total: number
   limit: number
   offset: number
-  count: number
+  has_more: boolean
 }
 
 function SavedQuestionsLoading() {
@@ -114,12 +115,12 @@ function SavedQuestionsContent() {
       }
 
       const data: PaginationInfo = await response.json()
-      setQuestions(data.questions || [])
-      setTotalCount(data.count)
+      setQuestions(data.items || [])
+      setTotalCount(data.total)
 
       // Extract all unique tags from questions
       const tags = new Set<string>()
-      data.questions?.forEach(q => {
+      data.items?.forEach(q => {
         if (q.category) tags.add(q.category.toLowerCase())
         if (q.difficulty) tags.add(q.difficulty.toLowerCase())
         q.tags?.forEach(tag => tags.add(tag.toLowerCase()))
@@ -18,7 +18,7 @@ interface ResumeSelectorPanelProps {
 }
 
 export function ResumeSelectorPanel({ clientId, onQALoaded }: ResumeSelectorPanelProps) {
-  const { user } = useAuth()
+  const { user, token } = useAuth()
   const [uploads, setUploads] = useState<Upload[]>([])
   const [loading, setLoading] = useState(true)
   const [loadingQA, setLoadingQA] = useState(false)
@@ -46,16 +46,22 @@ export function ResumeSelectorPanel({ clientId, onQALoaded }: ResumeSelectorPane
   }, [user?.id])
 
   const loadUploads = async () => {
+    // Only signed-in users have an upload list
+    if (!token) {
+      setUploads([])
+      setLoading(false)
+      return
+    }
+
     try {
       setLoading(true)
       setError(null)
 
-      // Include user_id filter if user is authenticated
-      const userIdParam = user?.id ? `&user_id=${user.id}` : ''
-      const response = await fetch(`${backendUrl}/api/uploads?limit=50${userIdParam}`, {
+      const response = await fetch(`${backendUrl}/api/uploads?limit=50`, {
         headers: {
           'ngrok-skip-browser-warning': 'true',
           'User-Agent': 'ChatApp/1.0',
+          'Authorization': `Bearer ${token}`,
         },
       })
 
@@ -64,7 +70,7 @@ export function ResumeSelectorPanel({ clientId, onQALoaded }: ResumeSelectorPane
       }
 
       const data = await response.json()
-      setUploads(data.uploads || [])
+      setUploads(data.items || [])
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load uploads')
     } finally {
@@ -1,8 +1,17 @@
 'use client'
 
 import { useEffect, useState } from 'react'
+import { useAuth } from '@/contexts/AuthContext'
 import { Loader, CheckCircle, XCircle, FileText, Database, Brain, Sparkles, ChevronDown, ChevronRight } from 'lucide-react'
 
+interface ProfileBasics {
+  name?: string
+  email?: string
+  phone?: string
+  location?: string
+  skills?: Record<string, string[]>
+}
+
 interface AnalysisStatus {
   job_id: string
   status: string
@@ -13,6 +22,7 @@ interface AnalysisStatus {
   updated_at: string
   completed_at?: string
   error_message?: string
+  basics?: ProfileBasics
 }
 
 interface AnalysisProgressProps {
@@ -31,6 +41,7 @@ const STATUS_STEPS = [
 ]
 
 export function AnalysisProgress({ jobId, onComplete, onError }: AnalysisProgressProps) {
+  const { token } = useAuth()
   const [status, setStatus] = useState<AnalysisStatus | null>(null)
   const [error, setError] = useState<string | null>(null)
   const [isPolling, setIsPolling] = useState(true)
@@ -48,7 +59,8 @@ export function AnalysisProgress({ jobId, onComplete, onError }: AnalysisProgres
         const response = await fetch(url, {
           headers: {
             'ngrok-skip-browser-warning': 'true',
-            'User-Agent': 'ChatApp/1.0'
+            'User-Agent': 'ChatApp/1.0',
+            ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
           }
         })
 
@@ -73,7 +85,7 @@ export function AnalysisProgress({ jobId, onComplete, onError }: AnalysisProgres
         if (data.status === 'completed') {
           setIsPolling(false)
           onComplete?.(jobId)
-        } else if (data.status === 'failed') {
+        } else if (data.status === 'failed' || data.status === 'needs_review' || data.status === 'dead_lettered') {
           setIsPolling(false)
           const errorMsg = data.error_message || 'Analysis failed'
           setError(errorMsg)
@@ -100,7 +112,7 @@ export function AnalysisProgress({ jobId, onComplete, onError }: AnalysisProgres
         clearInterval(pollInterval)
       }
     }
-  }, [jobId, isPolling, onComplete, onError])
+  }, [jobId, token, isPolling, onComplete, onError])
 
   const getCurrentStepIndex = () => {
     if (!status) return 0
@@ -219,6 +231,27 @@ export function AnalysisProgress({ jobId, onComplete, onError }: AnalysisProgres
           })}
         </div>
 
+        {/* Early results from two-pass analysis (if available) */}
+        {status.basics && status.status !== 'completed' && (
+          <div className="rounded-lg border border-indigo-200 bg-indigo-50 p-4">
+            <p className="font-semibold text-slate-900">
+              {status.basics.name || 'Candidate'}
+            </p>
+            <p className="text-sm text-slate-600">
+              {[status.basics.email, status.basics.phone, status.basics.location].filter(Boolean).join(' · ')}
+            </p>
+            {status.basics.skills?.technical && status.basics.skills.technical.length > 0 && (
+              <div className="mt-3 flex flex-wrap gap-2">
+                {status.basics.skills.technical.map(skill => (
+                  <span key={skill} className="rounded-full bg-white px-3 py-1 text-xs text-indigo-700">
+                    {skill}
+                  </span>
+                ))}
+              </div>
+            )}
+          </div>
+        )}
+
         {/* Extracted Text (if available) */}
         {status.extracted_text && (
           <div className="rounded-lg border border-slate-200 bg-slate-50 overflow-hidden">
@@ -1,6 +1,7 @@
 'use client'
 
 import { useEffect, useState } from 'react'
+import { useAuth } from '@/contexts/AuthContext'
 import {
   User, MapPin, Calendar, Briefcase, GraduationCap,
   CheckCircle, AlertCircle, Target, TrendingUp, Loader, Linkedin, Sparkles
@@ -14,6 +15,14 @@ interface ExperienceEntry {
   end_date?: string
   years: number
   description?: string
+  flags?: string[]
+}
+
+// Descriptions of the date problems flagged on experience entries
+const experienceFlagLabels: Record<string, string> = {
+  future_date: 'Dated in the future',
+  end_before_start: 'Ends before it starts',
+  overlapping: 'Overlaps another position',
 }
 
 interface EducationEntry {
@@ -34,6 +43,7 @@ interface AnalysisResult {
   race?: string
   location?: string
   total_work_years?: number
+  reported_work_years?: number
   skills?: {
     technical?: string[]
     soft?: string[]
@@ -53,6 +63,7 @@ interface AnalysisResultProps {
 }
 
 export function AnalysisResult({ jobId }: AnalysisResultProps) {
+  const { token } = useAuth()
   const [result, setResult] = useState<AnalysisResult | null>(null)
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
@@ -65,7 +76,8 @@ export function AnalysisResult({ jobId }: AnalysisResultProps) {
         const response = await fetch(`${backendUrl}/api/analysis/result?job_id=${jobId}`, {
           headers: {
             'ngrok-skip-browser-warning': 'true',
-            'User-Agent': 'ChatApp/1.0'
+            'User-Agent': 'ChatApp/1.0',
+            ...(token ? { 'Authorization': `Bearer ${token}` } : {}),
           }
         })
 
@@ -84,7 +96,7 @@ export function AnalysisResult({ jobId }: AnalysisResultProps) {
     }
 
     fetchResult()
-  }, [jobId])
+  }, [jobId, token])
 
   if (loading) {
     return (
@@ -191,6 +203,11 @@ export function AnalysisResult({ jobId }: AnalysisResultProps) {
               ) : (
                 <p className="italic text-slate-400">Not available</p>
               )}
+              {result.reported_work_years !== null && result.reported_work_years !== undefined && (
+                <p className="text-xs text-amber-600">
+                  Estimated at {result.reported_work_years} years from the resume text; check the dates below
+                </p>
+              )}
             </div>
           </div>
         </div>
@@ -289,6 +306,12 @@ export function AnalysisResult({ jobId }: AnalysisResultProps) {
                     <span className="text-slate-400">({exp.years} years)</span>
                   )}
                 </div>
+                {exp.flags && exp.flags.length > 0 && (
+                  <p className="mt-1 flex items-center gap-1 text-xs text-amber-600">
+                    <AlertCircle className="h-3 w-3" />
+                    {exp.flags.map(flag => experienceFlagLabels[flag] ?? flag).join(', ')}
+                  </p>
+                )}
                 {exp.description && (
                   <p className="mt-2 text-sm text-slate-700">{exp.description}</p>
                 )}
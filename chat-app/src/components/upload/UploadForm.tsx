@@ -11,13 +11,15 @@ interface UploadResponse {
   file_name: string
   file_size: number
   linkedin_url?: string
+  notify_email: boolean
   message: string
 }
 
 export function UploadForm() {
   const router = useRouter()
-  const { user } = useAuth()
+  const { user, token } = useAuth()
   const [linkedinUrl, setLinkedinUrl] = useState('')
+  const [notifyEmail, setNotifyEmail] = useState(false)
   const [file, setFile] = useState<File | null>(null)
   const [uploading, setUploading] = useState(false)
   const [success, setSuccess] = useState(false)
@@ -82,12 +84,16 @@ export function UploadForm() {
       }
       if (user?.id) {
         formData.append('user_id', String(user.id))
+        if (notifyEmail) {
+          formData.append('notify_email', 'true')
+        }
       }
 
       // Get backend URL from environment or use default
       const backendUrl = process.env.NEXT_PUBLIC_BACKEND_URL || 'http://localhost:8081'
       const response = await fetch(`${backendUrl}/api/upload`, {
         method: 'POST',
+        headers: token ? { 'Authorization': `Bearer ${token}` } : undefined,
         body: formData,
       })
 
@@ -121,6 +127,7 @@ export function UploadForm() {
       }
       const response = await fetch(`${backendUrl}/api/analyze?${params.toString()}`, {
         method: 'POST',
+        headers: token ? { 'Authorization': `Bearer ${token}` } : undefined,
       })
 
       if (!response.ok) {
@@ -154,6 +161,7 @@ export function UploadForm() {
   const resetForm = () => {
     setFile(null)
     setLinkedinUrl('')
+    setNotifyEmail(false)
     setUploadResponse(null)
     setSuccess(false)
     setError(null)
@@ -253,6 +261,19 @@ export function UploadForm() {
           )}
         </div>
 
+        {/* Email Notification Opt-in */}
+        {user?.email && (
+          <label className="flex items-center gap-3 text-sm text-slate-700">
+            <input
+              type="checkbox"
+              checked={notifyEmail}
+              onChange={(e) => setNotifyEmail(e.target.checked)}
+              className="h-4 w-4 rounded border-slate-300 text-indigo-600 focus:ring-indigo-500"
+            />
+            Email me at {user.email} when the analysis finishes
+          </label>
+        )}
+
         {/* Error Message */}
         {error && (
           <div className="flex items-start gap-3 rounded-lg border border-red-200 bg-red-50 p-4 animate-slide-in">
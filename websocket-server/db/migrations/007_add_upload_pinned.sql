@@ -0,0 +1,9 @@
+-- Migration: Add pinned flag to user_uploads for the retention policy
+-- Pinned uploads (and their jobs, profiles and embeddings) are never removed by retention cleanup
+
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN user_uploads.pinned IS 'When true, the upload is exempt from automatic retention cleanup';
+
+-- Index for finding expired, unpinned uploads
+CREATE INDEX IF NOT EXISTS idx_user_uploads_retention ON user_uploads (created_at) WHERE pinned = FALSE;
@@ -0,0 +1,7 @@
+-- Migration: Allow an analysis job to merge several uploads into one profile
+-- upload_id remains the primary upload; additional uploads (e.g. a project portfolio)
+-- are extracted and appended to its text before chunking and analysis
+
+ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS additional_upload_ids INTEGER[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN analysis_jobs.additional_upload_ids IS 'Uploads merged with upload_id into a single profile, in document order';
@@ -0,0 +1,16 @@
+-- Migration: Move file content out of user_uploads behind a pluggable file store
+-- New uploads keep only a storage key; the content lives in file_blobs (Postgres backend)
+-- or an S3-compatible bucket. Existing rows keep their inline file_content.
+
+CREATE TABLE IF NOT EXISTS file_blobs (
+    key VARCHAR(255) PRIMARY KEY,
+    content BYTEA NOT NULL,
+    content_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE file_blobs IS 'File content for the Postgres file store backend';
+
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS storage_key VARCHAR(255);
+
+COMMENT ON COLUMN user_uploads.storage_key IS 'File store key for the upload content (NULL for legacy rows that use file_content)';
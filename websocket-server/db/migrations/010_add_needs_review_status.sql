@@ -0,0 +1,11 @@
+-- Migration: Allow analysis jobs to be flagged for review
+-- Jobs whose extracted text scores below the quality threshold stop before analysis
+-- with status 'needs_review'; retrying such a job approves the text and skips the check
+
+ALTER TABLE analysis_jobs DROP CONSTRAINT IF EXISTS valid_status;
+
+ALTER TABLE analysis_jobs ADD CONSTRAINT valid_status CHECK (
+    status IN ('queued', 'extracting_text', 'chunking',
+               'generating_embeddings', 'analyzing',
+               'completed', 'failed', 'needs_review')
+);
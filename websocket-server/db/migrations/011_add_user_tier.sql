@@ -0,0 +1,7 @@
+-- Migration: Add a service tier to users
+-- The tier selects which LLM/embedding clients and rate limits apply to the user's requests.
+-- Tiers without a configured plan fall back to the default plan.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tier VARCHAR(20) NOT NULL DEFAULT 'free';
+
+COMMENT ON COLUMN users.tier IS 'Service tier (e.g. free, paid) used to select models and limits';
@@ -0,0 +1,5 @@
+-- Migration: Store early analysis results on jobs
+-- In two-pass analysis mode a fast first LLM pass extracts contact info and skills,
+-- which are stored here so clients can render them before the deep analysis finishes
+
+ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS basics JSONB;
@@ -0,0 +1,42 @@
+-- Migration: Normalize saved question categories and difficulties
+-- New questions are stored with canonical values (see models.NormalizeQuestionCategory);
+-- this rewrites common variant spellings saved before that.
+-- Values that don't match a known variant are left unchanged.
+
+UPDATE saved_interview_questions
+SET category = CASE regexp_replace(lower(trim(category)), '[-_[:space:]]+', ' ', 'g')
+        WHEN 'technical' THEN 'Technical'
+        WHEN 'tech' THEN 'Technical'
+        WHEN 'technology' THEN 'Technical'
+        WHEN 'behavioral' THEN 'Behavioral'
+        WHEN 'behavioural' THEN 'Behavioral'
+        WHEN 'behavior' THEN 'Behavioral'
+        WHEN 'behaviour' THEN 'Behavioral'
+        WHEN 'situational' THEN 'Situational'
+        WHEN 'situation' THEN 'Situational'
+        WHEN 'scenario' THEN 'Situational'
+        WHEN 'scenario based' THEN 'Situational'
+        WHEN 'problem solving' THEN 'Problem-Solving'
+        WHEN 'problemsolving' THEN 'Problem-Solving'
+        WHEN 'problem' THEN 'Problem-Solving'
+        ELSE category
+    END
+WHERE category IS NOT NULL;
+
+UPDATE saved_interview_questions
+SET difficulty = CASE regexp_replace(lower(trim(difficulty)), '[-_[:space:]]+', ' ', 'g')
+        WHEN 'easy' THEN 'Easy'
+        WHEN 'simple' THEN 'Easy'
+        WHEN 'basic' THEN 'Easy'
+        WHEN 'beginner' THEN 'Easy'
+        WHEN 'medium' THEN 'Medium'
+        WHEN 'moderate' THEN 'Medium'
+        WHEN 'intermediate' THEN 'Medium'
+        WHEN 'mid' THEN 'Medium'
+        WHEN 'hard' THEN 'Hard'
+        WHEN 'difficult' THEN 'Hard'
+        WHEN 'advanced' THEN 'Hard'
+        WHEN 'challenging' THEN 'Hard'
+        ELSE difficulty
+    END
+WHERE difficulty IS NOT NULL;
@@ -0,0 +1,24 @@
+-- Migration: Guarantee one profile per analysis job
+-- Profiles are saved with INSERT ... ON CONFLICT (job_id) DO UPDATE, which needs a
+-- unique constraint on user_profile.job_id. 002 declares one; this removes any
+-- duplicates (keeping the newest) and restores the constraint where it is missing.
+
+DELETE FROM user_profile p
+USING user_profile newer
+WHERE p.job_id = newer.job_id
+  AND (p.updated_at, p.id) < (newer.updated_at, newer.id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_index i
+        JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY (i.indkey)
+        WHERE i.indrelid = 'user_profile'::regclass
+          AND i.indisunique
+          AND i.indnatts = 1
+          AND a.attname = 'job_id'
+    ) THEN
+        ALTER TABLE user_profile ADD CONSTRAINT user_profile_job_id_key UNIQUE (job_id);
+    END IF;
+END $$;
@@ -0,0 +1,14 @@
+-- Migration: Limit how often a failing analysis job is retried
+-- retry_count is incremented each time a job is reset for retry. A job that fails after
+-- using all of its retries (or is retried beyond them) gets the terminal status
+-- 'dead_lettered' and is left for manual review instead of being retried again
+
+ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE analysis_jobs DROP CONSTRAINT IF EXISTS valid_status;
+
+ALTER TABLE analysis_jobs ADD CONSTRAINT valid_status CHECK (
+    status IN ('queued', 'extracting_text', 'chunking',
+               'generating_embeddings', 'analyzing',
+               'completed', 'failed', 'needs_review', 'dead_lettered')
+);
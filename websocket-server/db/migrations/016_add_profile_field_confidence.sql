@@ -0,0 +1,6 @@
+-- Migration: Store the LLM's extraction confidence per profile field
+-- field_confidence maps a profile field name (e.g. "location") to a score from 0 to 1,
+-- or null when the LLM didn't report one. Profiles analyzed before this migration have
+-- an empty map
+
+ALTER TABLE user_profile ADD COLUMN IF NOT EXISTS field_confidence JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,6 @@
+-- Migration: Keep the LLM's total work years when it disagrees with the experience dates
+-- total_work_years is computed from the experience dates, counting concurrent positions
+-- once. reported_work_years holds the total the LLM reported when it differs from that
+-- by more than a year, flagging the profile for review; otherwise it is null
+
+ALTER TABLE user_profile ADD COLUMN IF NOT EXISTS reported_work_years NUMERIC(4,1);
@@ -0,0 +1,7 @@
+-- Migration: Let users opt in to email notifications per upload
+-- When set (and email notifications are enabled on the server), the upload's owner is
+-- emailed when an analysis job of the upload completes or fails
+
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS notify_email BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN user_uploads.notify_email IS 'When true, the owner is emailed when analysis of the upload completes or fails';
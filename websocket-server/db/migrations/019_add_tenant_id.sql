@@ -0,0 +1,12 @@
+-- Migration: Namespace uploads by tenant (organization)
+-- Users belong to a tenant and their uploads inherit it. Embeddings in the vector store
+-- are stored under the upload's tenant and searches only see the caller's tenant.
+-- The empty tenant ID is the default tenant, so existing data keeps working unchanged.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_user_uploads_tenant_id ON user_uploads(tenant_id);
+
+COMMENT ON COLUMN users.tenant_id IS 'Organization the user belongs to; empty for the default tenant';
+COMMENT ON COLUMN user_uploads.tenant_id IS 'Tenant of the upload owner, isolating its embeddings in the vector store';
@@ -0,0 +1,11 @@
+-- Migration: Let users organize uploads with tags and notes
+-- Tags are lowercase labels (e.g. "frontend", "2024", "referral") the upload list can be
+-- filtered by; notes are free-form text of the owner
+
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE user_uploads ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_user_uploads_tags ON user_uploads USING GIN (tags);
+
+COMMENT ON COLUMN user_uploads.tags IS 'Lowercase labels for organizing and filtering uploads';
+COMMENT ON COLUMN user_uploads.notes IS 'Free-form notes of the upload owner';
@@ -0,0 +1,8 @@
+-- Migration: Remember each user's preferred export format
+-- /api/analysis/export uses it when a request has no format parameter; an explicit format
+-- always wins. Empty means no preference (JSON). Values are validated by the server
+-- against the supported export formats, so new formats don't need a migration.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS default_export_format VARCHAR(20) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN users.default_export_format IS 'Export format used when a request names none; empty for the server default';
@@ -0,0 +1,9 @@
+-- Migration: Store a stable code alongside the error message of failed jobs
+-- The message is free text meant for people; the code names the step that failed
+-- (extraction_failed, chunking_failed, embedding_failed, storage_failed, llm_failed, ...)
+-- so clients can handle failures programmatically. Jobs that failed before this
+-- migration have no code.
+
+ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS error_code VARCHAR(50);
+
+COMMENT ON COLUMN analysis_jobs.error_code IS 'Stable code of the step that failed; NULL unless the job failed';
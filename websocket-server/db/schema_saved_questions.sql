@@ -127,5 +127,8 @@ GRANT USAGE, SELECT ON SEQUENCE saved_interview_questions_id_seq TO chatapp;
 -- Filter by tags (questions containing 'python' tag)
 -- SELECT * FROM saved_interview_questions WHERE user_id = 'user@example.com' AND 'python' = ANY(tags);
 
+-- Count questions per tag, most used first
+-- SELECT tag, COUNT(*) FROM saved_interview_questions, unnest(tags) AS tag WHERE user_id = 'user@example.com' GROUP BY tag ORDER BY COUNT(*) DESC;
+
 -- Filter by category and difficulty
 -- SELECT * FROM saved_interview_questions WHERE user_id = 'user@example.com' AND category = 'Technical' AND difficulty = 'Hard';
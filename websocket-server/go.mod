@@ -7,12 +7,10 @@ require (
 	github.com/dslipak/pdf v0.0.2
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.1
-	github.com/joho/godotenv v1.5.1
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	github.com/lib/pq v1.10.9
-	github.com/nguyenthenguyen/docx v0.0.0-20230621112118-9c8e795a11db
 	github.com/pdfcpu/pdfcpu v0.11.1
-	github.com/rs/cors v1.10.1
 	github.com/tmc/langchaingo v0.1.14
 	github.com/unidoc/unipdf/v3 v3.69.0
 )
@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"context"
+	"time"
 
 	"github.com/your-org/websocket-server/pkg/models"
 )
@@ -11,29 +12,112 @@ type ResumeAnalyzer interface {
 	// AnalyzeAsync starts an asynchronous analysis job for a resume
 	AnalyzeAsync(ctx context.Context, uploadID int, userID *int) (jobID string, err error)
 
+	// AnalyzeMultipleAsync starts one analysis job over several uploads (e.g. a resume and a
+	// project portfolio) whose texts are merged into a single profile
+	AnalyzeMultipleAsync(ctx context.Context, uploadIDs []int, userID *int) (jobID string, err error)
+
+	// AnalyzeSync starts an analysis job like AnalyzeMultipleAsync and waits up to wait for it
+	// to reach a final status, which finished reports. When the uploads are over the
+	// synchronous size limit it returns without waiting. Unfinished jobs continue in the background.
+	AnalyzeSync(ctx context.Context, uploadIDs []int, userID *int, wait time.Duration) (jobID string, finished bool, err error)
+
 	// GetStatus retrieves the current status of an analysis job
 	GetStatus(ctx context.Context, jobID string) (*models.AnalysisStatus, error)
 
 	// GetResult retrieves the complete analysis result for a completed job
 	GetResult(ctx context.Context, jobID string) (*models.AnalysisResult, error)
 
-	// SearchSimilarResumes finds similar resumes using vector similarity
-	SearchSimilarResumes(ctx context.Context, query string, limit int) ([]*models.UserProfile, error)
+	// SearchSimilarResumes finds similar resumes of a tenant using vector similarity
+	SearchSimilarResumes(ctx context.Context, tenantID string, query string, limit int) ([]*models.UserProfile, error)
+
+	// RerankResumes asks the LLM to refine the order of search results for a query,
+	// justifying each placement
+	RerankResumes(ctx context.Context, query string, profiles []*models.UserProfile) (*RerankResult, error)
+
+	// GetSimilarProfiles returns up to MaxSimilarProfiles profiles of the same tenant most
+	// similar to a completed job's profile. They are precomputed in the background as
+	// jobs complete, so this is usually a cache lookup.
+	GetSimilarProfiles(ctx context.Context, jobID string) ([]*models.SimilarProfile, error)
 
 	// GetJobsByUserID retrieves all analysis jobs for a specific user
 	GetJobsByUserID(ctx context.Context, userID int) ([]*models.AnalysisJob, error)
 
+	// GetUpload retrieves an upload's metadata, so callers can check who may access it
+	// before analyzing it. Returns ErrUploadNotFound when the upload does not exist.
+	GetUpload(ctx context.Context, uploadID int) (*models.Upload, error)
+
 	// GetJobsByUploadID retrieves all analysis jobs for a specific upload
 	GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error)
 
 	// DeleteJob deletes a single analysis job and its associated profile
 	DeleteJob(ctx context.Context, jobID string) error
 
+	// CancelJob stops a queued or processing job and marks it failed
+	CancelJob(ctx context.Context, jobID string) error
+
 	// RetryJob resets a failed job and reprocesses it
 	RetryJob(ctx context.Context, jobID string) error
 
+	// ReanalyzeJob re-runs only the LLM step of a completed job using its stored
+	// text and embeddings, replacing the job's profile
+	ReanalyzeJob(ctx context.Context, jobID string) (*models.AnalysisResult, error)
+
+	// RegenerateRecommendations replaces only the job recommendations of a completed job's
+	// profile, optionally biased towards an industry (empty for none)
+	RegenerateRecommendations(ctx context.Context, jobID, industry string) (*models.AnalysisResult, error)
+
+	// PatchProfile updates only the fields a patch sets on a completed job's profile,
+	// leaving the others as they are
+	PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) (*models.AnalysisResult, error)
+
+	// AnalyzeSkillGaps finds the skills a completed job's profile is missing for each of
+	// up to MaxSkillGapJobDescriptions job descriptions, ranked by how many ask for them
+	AnalyzeSkillGaps(ctx context.Context, jobID string, jobDescriptions []string) (*models.SkillGapReport, error)
+
+	// PreviewChunks extracts and chunks an upload the way an analysis job would, with
+	// optional chunking overrides, without embedding or analyzing it. userID has the same
+	// access rules as AnalyzeAsync.
+	PreviewChunks(ctx context.Context, uploadID int, userID *int, options ChunkPreviewOptions) (*ChunkPreview, error)
+
+	// ReanalyzeAllAsync starts a new analysis job for every upload of the user that is not
+	// already being processed. allow, if not nil, is consulted before each job is started;
+	// uploads left once it returns false or the queue is full are reported as deferred.
+	ReanalyzeAllAsync(ctx context.Context, userID int, allow func() bool) (*BulkReanalyzeResult, error)
+
 	// BatchDeleteJobs deletes multiple analysis jobs and their associated profiles
 	BatchDeleteJobs(ctx context.Context, jobIDs []string) (*BatchDeleteResult, error)
+
+	// GetUserAnalytics returns aggregate statistics across a user's uploads and analyses
+	GetUserAnalytics(ctx context.Context, userID int, topSkills int) (*models.UserAnalytics, error)
+
+	// ListOrgCandidates summarizes the uploads of a tenant and their analyses, filtered by
+	// analysis status and tag (empty matches any). Returns ErrTooManyCandidates when more
+	// than MaxOrgCandidates uploads match.
+	ListOrgCandidates(ctx context.Context, tenantID, status, tag string) ([]*models.CandidateSummary, error)
+
+	// QueueStats reports how busy the worker pool is
+	QueueStats() QueueStats
+
+	// ForgetUpload drops data cached from a deleted upload and its jobs, such as similar
+	// profiles (see UploadForgetter)
+	ForgetUpload(uploadID int)
+}
+
+// QueueStats describes the load on the analyzer's worker pool
+type QueueStats struct {
+	Running       int  `json:"running"`        // Jobs holding a worker slot
+	Queued        int  `json:"queued"`         // Jobs waiting for a worker slot
+	Capacity      int  `json:"capacity"`       // Number of worker slots
+	MaxQueued     int  `json:"max_queued"`     // High-water mark for queued jobs (0 = unlimited)
+	AcceptingJobs bool `json:"accepting_jobs"` // False while new jobs are rejected
+}
+
+// BulkReanalyzeResult contains the result of re-analyzing all of a user's uploads
+type BulkReanalyzeResult struct {
+	JobIDs            []string `json:"job_ids"`             // New jobs, one per started upload
+	UploadIDs         []int    `json:"upload_ids"`          // Upload of each new job, in the same order
+	SkippedUploadIDs  []int    `json:"skipped_upload_ids"`  // Uploads with a job already in progress
+	DeferredUploadIDs []int    `json:"deferred_upload_ids"` // Uploads not started because the queue was full or the rate limit was reached
 }
 
 // BatchDeleteResult contains the result of a batch delete operation
@@ -61,27 +145,77 @@ type EmbeddingGenerator interface {
 	// GenerateEmbedding creates a vector embedding for the given text
 	GenerateEmbedding(ctx context.Context, text string) ([]float32, error)
 
-	// GenerateEmbeddings creates vector embeddings for multiple texts
-	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+	// GenerateEmbeddings creates vector embeddings for multiple texts, in the order of
+	// texts. progress and onBatch, if not nil, are called as batches of texts are
+	// embedded. If it fails or ctx ends partway, the embeddings generated so far may be
+	// returned with the error, nil for the texts not embedded.
+	GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error)
 }
 
-// VectorStore manages storage and retrieval of embeddings
+// EmbeddingProgress reports how many of total texts have been embedded. Calls are never
+// concurrent and done only increases, reaching total when every text is embedded.
+type EmbeddingProgress func(done, total int)
+
+// EmbeddedBatch receives a batch of embeddings as soon as it is generated: embeddings[i]
+// is the embedding of texts[start+i]. Calls are never concurrent, but batches may arrive
+// out of order.
+type EmbeddedBatch func(start int, embeddings [][]float32)
+
+// VectorStore manages storage and retrieval of embeddings. Embeddings are namespaced by
+// tenant (organization): an upload's embeddings belong to the tenant they were stored
+// under and are never visible to lookups or searches of another tenant. The empty
+// tenant ID is the default tenant of uploads without an organization.
 type VectorStore interface {
-	// StoreEmbeddings stores embeddings with metadata in the vector database
-	StoreEmbeddings(ctx context.Context, uploadID int, chunks []string, embeddings [][]float32) error
+	// StoreEmbeddings stores embeddings with metadata in the vector database under the
+	// tenant. Chunks are keyed by ChunkHash; identical chunks of an upload are stored once.
+	// metadata is nil or holds an entry per chunk.
+	StoreEmbeddings(ctx context.Context, tenantID string, uploadID int, chunks []string, embeddings [][]float32, metadata []ChunkMetadata) error
+
+	// LookupEmbeddings returns the stored embeddings of the given chunk hashes, from any
+	// upload of the tenant. Hashes without a stored embedding are absent from the result.
+	LookupEmbeddings(ctx context.Context, tenantID string, hashes []string) (map[string][]float32, error)
 
-	// SearchSimilar finds similar vectors using cosine similarity
-	SearchSimilar(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// SearchSimilar finds similar vectors of the tenant's uploads using cosine similarity,
+	// considering only the chunks matching filter
+	SearchSimilar(ctx context.Context, tenantID string, query string, limit int, filter SearchFilter) ([]SearchResult, error)
+
+	// SearchSimilarInUpload finds similar vectors restricted to a single upload of the
+	// tenant. An upload stored under another tenant has no results.
+	SearchSimilarInUpload(ctx context.Context, tenantID string, uploadID int, query string, limit int, filter SearchFilter) ([]SearchResult, error)
 
 	// DeleteByUploadID removes all embeddings associated with an upload
 	DeleteByUploadID(ctx context.Context, uploadID int) error
 }
 
+// ChunkMetadata is stored with a chunk's embedding
+type ChunkMetadata struct {
+	Section string // Resume section the chunk came from (see LabelChunkSections), "" if unknown
+}
+
+// SearchFilter restricts a vector search to some chunks. The zero value matches every chunk.
+type SearchFilter struct {
+	Sections []string // Only chunks of these sections, e.g. SectionExperience (empty = any)
+}
+
+// matches reports whether a chunk with metadata passes the filter
+func (f SearchFilter) matches(metadata ChunkMetadata) bool {
+	if len(f.Sections) == 0 {
+		return true
+	}
+	for _, section := range f.Sections {
+		if section == metadata.Section {
+			return true
+		}
+	}
+	return false
+}
+
 // SearchResult represents a vector similarity search result
 type SearchResult struct {
 	UploadID int
 	Chunk    string
-	Score    float32
+	Section  string  // Section of the chunk, "" if unknown
+	Score    float32 // Similarity to the query, higher is more similar (1 = identical)
 }
 
 // LLMClient interfaces with external LLM APIs for analysis
@@ -94,11 +228,42 @@ type LLMClient interface {
 	GenerateFromPrompt(ctx context.Context, prompt string) (string, error)
 }
 
+// StreamingLLMClient is implemented by LLM clients that can stream a response while it
+// is generated, e.g. for chat replies rendered progressively
+type StreamingLLMClient interface {
+	// GenerateFromPromptStream is GenerateFromPrompt calling onChunk with each piece of
+	// the response as it arrives. An error from onChunk stops the generation.
+	GenerateFromPromptStream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error)
+}
+
+// JobNotifier is told when an analysis job finishes, e.g. to email its owner
+type JobNotifier interface {
+	// JobFinished is called once a job has completed, failed or been dead-lettered
+	JobFinished(ctx context.Context, job *models.AnalysisJob) error
+}
+
+// UploadForgetter is told when an upload has been deleted, so that data cached from it
+// (e.g. similar profiles) is dropped
+type UploadForgetter interface {
+	// ForgetUpload drops everything cached for the upload and its analysis jobs
+	ForgetUpload(uploadID int)
+}
+
+// AnalysisPass selects which part of the profile an Analyze call extracts
+type AnalysisPass string
+
+const (
+	AnalysisPassFull   AnalysisPass = ""       // The whole profile in one call
+	AnalysisPassBasics AnalysisPass = "basics" // Contact info and skills only (fast)
+	AnalysisPassDeep   AnalysisPass = "deep"   // Experience, education and assessment (slow)
+)
+
 // AnalysisRequest contains all information needed for LLM analysis
 type AnalysisRequest struct {
-	ResumeText       string
-	RetrievedChunks  []string
-	LinkedInURL      *string
+	ResumeText      string
+	RetrievedChunks []string
+	LinkedInURL     *string
+	Pass            AnalysisPass
 }
 
 // AnalysisResponse contains structured analysis results from the LLM
@@ -118,4 +283,5 @@ type AnalysisResponse struct {
 	JobRecommendations []string
 	Strengths          []string
 	Weaknesses         []string
+	FieldConfidence    map[string]*float64 // Confidence (0-1) per field name; nil entries where the LLM gave none
 }
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrInvalidChunkOptions is returned when chunk preview options are out of range
+var ErrInvalidChunkOptions = errors.New("invalid chunking options")
+
+// Sentence splitting strategies a chunk preview can use
+const (
+	ChunkStrategyDefault      = "default"      // The chunker analysis jobs use
+	ChunkStrategyAbbreviation = "abbreviation" // Abbreviation-aware sentence splitting
+	ChunkStrategySimple       = "simple"       // Splits on every terminator followed by a capital
+)
+
+// MaxPreviewChunkSize is the largest chunk size a preview accepts
+const MaxPreviewChunkSize = 20000
+
+// ChunkPreviewOptions overrides how a chunk preview splits the text. Unset options
+// use the analyzer's configuration.
+type ChunkPreviewOptions struct {
+	ChunkSize    int    // 0 = configured chunk size
+	ChunkOverlap *int   // nil = configured overlap
+	Strategy     string // One of the ChunkStrategy constants ("" = ChunkStrategyDefault)
+}
+
+// PreviewChunk is one chunk of a preview
+type PreviewChunk struct {
+	Index int    `json:"index"`
+	Size  int    `json:"size"` // Length in bytes, the unit of the chunk size
+	Text  string `json:"text"`
+}
+
+// ChunkPreview is how an upload's text splits into chunks with the given options
+type ChunkPreview struct {
+	UploadID     int            `json:"upload_id"`
+	TextLength   int            `json:"text_length"` // Bytes of cleaned, extracted text
+	ChunkSize    int            `json:"chunk_size"`
+	ChunkOverlap int            `json:"chunk_overlap"`
+	Strategy     string         `json:"strategy"`
+	ChunkCount   int            `json:"chunk_count"`
+	MaxChunks    int            `json:"max_chunks"`    // Chunks embedded per document (0 = unlimited)
+	SampledCount int            `json:"sampled_count"` // Chunks an analysis job would embed after sampling
+	Chunks       []PreviewChunk `json:"chunks"`
+}
+
+// PreviewChunks extracts an upload's text and splits it into chunks, so chunking
+// parameters can be tuned without paying for embeddings or an LLM call
+func (a *DefaultResumeAnalyzer) PreviewChunks(ctx context.Context, uploadID int, userID *int, options ChunkPreviewOptions) (*ChunkPreview, error) {
+	chunkSize := a.chunkSize
+	if options.ChunkSize != 0 {
+		chunkSize = options.ChunkSize
+	}
+	overlap := a.chunkOverlap
+	if options.ChunkOverlap != nil {
+		overlap = *options.ChunkOverlap
+	}
+
+	if chunkSize <= 0 || chunkSize > MaxPreviewChunkSize {
+		return nil, fmt.Errorf("%w: chunk size must be between 1 and %d", ErrInvalidChunkOptions, MaxPreviewChunkSize)
+	}
+	if overlap < 0 || overlap >= chunkSize {
+		return nil, fmt.Errorf("%w: overlap must be non-negative and less than the chunk size", ErrInvalidChunkOptions)
+	}
+
+	strategy := options.Strategy
+	chunker := a.chunker
+	switch strategy {
+	case "", ChunkStrategyDefault:
+		strategy = ChunkStrategyDefault
+	case ChunkStrategyAbbreviation:
+		chunker = NewTextChunkerWithTokenizer(NewAbbreviationTokenizer(nil))
+	case ChunkStrategySimple:
+		chunker = NewTextChunkerWithTokenizer(NewSimpleSentenceTokenizer())
+	default:
+		return nil, fmt.Errorf("%w: unknown strategy %q", ErrInvalidChunkOptions, strategy)
+	}
+
+	uploads, err := a.loadUploads(ctx, []int{uploadID}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := a.extractUploadText(ctx, uploads[0])
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := chunker.ChunkText(text, chunkSize, overlap)
+	if err != nil {
+		return nil, fmt.Errorf("text chunking failed: %w", err)
+	}
+
+	preview := &ChunkPreview{
+		UploadID:     uploadID,
+		TextLength:   len(text),
+		ChunkSize:    chunkSize,
+		ChunkOverlap: overlap,
+		Strategy:     strategy,
+		ChunkCount:   len(chunks),
+		MaxChunks:    a.maxChunks,
+		SampledCount: len(SampleChunks(chunks, a.maxChunks)),
+		Chunks:       make([]PreviewChunk, len(chunks)),
+	}
+	for i, chunk := range chunks {
+		preview.Chunks[i] = PreviewChunk{Index: i, Size: len(chunk), Text: chunk}
+	}
+
+	log.Printf("Previewed %d chunks of upload %d (size %d, overlap %d, strategy %s)",
+		len(chunks), uploadID, chunkSize, overlap, strategy)
+
+	return preview, nil
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestPreviewChunks(t *testing.T) {
+	ctx := context.Background()
+	ta := newTestAnalyzer(t, func(config *Config) { config.MaxChunks = 4 })
+	text := strings.Repeat("Built Go services backed by SQL at Acme. ", 30) // 1230 bytes
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, text)
+
+	tests := []struct {
+		name        string
+		options     ChunkPreviewOptions
+		wantSize    int
+		wantOverlap int
+		wantCount   int
+	}{
+		{"configured size", ChunkPreviewOptions{}, 200, 0, 7},
+		{"larger chunks", ChunkPreviewOptions{ChunkSize: 500}, 500, 0, 3},
+		{"smaller chunks", ChunkPreviewOptions{ChunkSize: 100}, 100, 0, 15},
+		{"whole text", ChunkPreviewOptions{ChunkSize: 5000}, 5000, 0, 1},
+		{"overlap", ChunkPreviewOptions{ChunkSize: 500, ChunkOverlap: intPtr(100)}, 500, 100, 3},
+	}
+
+	counts := map[int]int{} // By chunk size, without overlap
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := ta.PreviewChunks(ctx, 1, intPtr(7), tt.options)
+			if err != nil {
+				t.Fatalf("PreviewChunks: %v", err)
+			}
+
+			if preview.ChunkSize != tt.wantSize || preview.ChunkOverlap != tt.wantOverlap || preview.Strategy != ChunkStrategyDefault {
+				t.Errorf("preview options = %d/%d/%s, want %d/%d/%s",
+					preview.ChunkSize, preview.ChunkOverlap, preview.Strategy, tt.wantSize, tt.wantOverlap, ChunkStrategyDefault)
+			}
+			if preview.ChunkCount < tt.wantCount-1 || preview.ChunkCount > tt.wantCount+1 {
+				t.Errorf("%d chunks, want about %d", preview.ChunkCount, tt.wantCount)
+			}
+			if len(preview.Chunks) != preview.ChunkCount || preview.SampledCount != min(preview.ChunkCount, 4) {
+				t.Errorf("%d chunks listed, %d sampled, of %d", len(preview.Chunks), preview.SampledCount, preview.ChunkCount)
+			}
+			for i, chunk := range preview.Chunks {
+				if chunk.Index != i || chunk.Size != len(chunk.Text) || chunk.Size > tt.wantSize {
+					t.Errorf("chunk %d = index %d, size %d, %d bytes of text", i, chunk.Index, chunk.Size, len(chunk.Text))
+				}
+			}
+			if tt.options.ChunkOverlap == nil {
+				counts[preview.ChunkSize] = preview.ChunkCount
+			}
+		})
+	}
+
+	// Smaller chunks always split the text into more of them
+	if !(counts[100] > counts[200] && counts[200] > counts[500] && counts[500] > counts[5000]) {
+		t.Errorf("chunk counts by size = %v, want fewer chunks for larger sizes", counts)
+	}
+	if calls := ta.llm.calls(); len(calls) != 0 {
+		t.Errorf("previewing called the LLM %d times", len(calls))
+	}
+}
+
+func TestPreviewChunksErrors(t *testing.T) {
+	ctx := context.Background()
+	ta := newTestAnalyzer(t, nil)
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Built Go services backed by SQL at Acme.")
+
+	tests := []struct {
+		name    string
+		id      int
+		userID  *int
+		options ChunkPreviewOptions
+		want    error
+	}{
+		{"chunk size too large", 1, intPtr(7), ChunkPreviewOptions{ChunkSize: MaxPreviewChunkSize + 1}, ErrInvalidChunkOptions},
+		{"negative chunk size", 1, intPtr(7), ChunkPreviewOptions{ChunkSize: -1}, ErrInvalidChunkOptions},
+		{"overlap as large as the chunks", 1, intPtr(7), ChunkPreviewOptions{ChunkSize: 100, ChunkOverlap: intPtr(100)}, ErrInvalidChunkOptions},
+		{"negative overlap", 1, intPtr(7), ChunkPreviewOptions{ChunkOverlap: intPtr(-1)}, ErrInvalidChunkOptions},
+		{"unknown strategy", 1, intPtr(7), ChunkPreviewOptions{Strategy: "paragraph"}, ErrInvalidChunkOptions},
+		{"other user's upload", 1, intPtr(8), ChunkPreviewOptions{}, ErrUploadNotOwned},
+		{"missing upload", 2, intPtr(7), ChunkPreviewOptions{}, ErrUploadNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ta.PreviewChunks(ctx, tt.id, tt.userID, tt.options); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("strategies", func(t *testing.T) {
+		for _, strategy := range []string{ChunkStrategyAbbreviation, ChunkStrategySimple} {
+			preview, err := ta.PreviewChunks(ctx, 1, intPtr(7), ChunkPreviewOptions{Strategy: strategy})
+			if err != nil || preview.Strategy != strategy || preview.ChunkCount != 1 {
+				t.Errorf("%s preview = %+v, %v", strategy, preview, err)
+			}
+		}
+	})
+}
@@ -7,11 +7,21 @@ import (
 )
 
 // DefaultTextChunker implements TextChunker interface
-type DefaultTextChunker struct{}
+type DefaultTextChunker struct {
+	tokenizer SentenceTokenizer
+}
 
-// NewTextChunker creates a new text chunker instance
+// NewTextChunker creates a new text chunker instance using the abbreviation-aware tokenizer
 func NewTextChunker() TextChunker {
-	return &DefaultTextChunker{}
+	return &DefaultTextChunker{tokenizer: NewAbbreviationTokenizer(nil)}
+}
+
+// NewTextChunkerWithTokenizer creates a text chunker that splits sentences with the given tokenizer
+func NewTextChunkerWithTokenizer(tokenizer SentenceTokenizer) TextChunker {
+	if tokenizer == nil {
+		tokenizer = NewAbbreviationTokenizer(nil)
+	}
+	return &DefaultTextChunker{tokenizer: tokenizer}
 }
 
 // ChunkText splits text into semantic chunks with overlap
@@ -30,7 +40,7 @@ func (c *DefaultTextChunker) ChunkText(text string, chunkSize int, overlap int)
 	}
 
 	// Split into sentences for more semantic chunking
-	sentences := splitIntoSentences(text)
+	sentences := c.tokenizer.Split(text)
 	if len(sentences) == 0 {
 		return nil, fmt.Errorf("no sentences found in text")
 	}
@@ -91,6 +101,31 @@ func (c *DefaultTextChunker) ChunkText(text string, chunkSize int, overlap int)
 	return chunks, nil
 }
 
+// SampleChunks reduces chunks to at most maxChunks by keeping evenly spaced chunks.
+// The first and last chunks are always kept so the document's opening and closing
+// sections are represented. Chunks are returned in their original order. Sampling a
+// slice of per-chunk values of the same length, such as their metadata, keeps the
+// values of the same chunks.
+func SampleChunks[T any](chunks []T, maxChunks int) []T {
+	if maxChunks <= 0 || len(chunks) <= maxChunks {
+		return chunks
+	}
+
+	if maxChunks == 1 {
+		return []T{chunks[0]}
+	}
+
+	sampled := make([]T, maxChunks)
+	last := len(chunks) - 1
+	for i := 0; i < maxChunks; i++ {
+		// Spread indices evenly over [0, last]; since len(chunks) > maxChunks the step is >= 1,
+		// so indices are strictly increasing and never repeat
+		sampled[i] = chunks[i*last/(maxChunks-1)]
+	}
+
+	return sampled
+}
+
 // splitIntoSentences splits text into sentences
 func splitIntoSentences(text string) []string {
 	var sentences []string
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSampleChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		maxChunks int
+		want      []int // Indices of the kept chunks
+	}{
+		{"under cap", 3, 5, []int{0, 1, 2}},
+		{"at cap", 5, 5, []int{0, 1, 2, 3, 4}},
+		{"unlimited", 4, 0, []int{0, 1, 2, 3}},
+		{"one", 4, 1, []int{0}},
+		{"two keeps the ends", 10, 2, []int{0, 9}},
+		{"evenly spaced", 9, 5, []int{0, 2, 4, 6, 8}},
+		{"uneven spacing", 10, 4, []int{0, 3, 6, 9}},
+		{"one over cap", 6, 5, []int{0, 1, 2, 3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := make([]int, tt.count)
+			for i := range chunks {
+				chunks[i] = i
+			}
+
+			if got := SampleChunks(chunks, tt.maxChunks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SampleChunks(%d chunks, %d) = %v, want %v", tt.count, tt.maxChunks, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleChunksOverCapDocument(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&text, "Sentence number %d describes one more achievement in detail. ", i)
+	}
+
+	chunks, err := NewTextChunker().ChunkText(text.String(), 200, 0)
+	if err != nil {
+		t.Fatalf("ChunkText: %v", err)
+	}
+
+	for _, maxChunks := range []int{1, 2, 10, 50} {
+		if len(chunks) <= maxChunks {
+			t.Fatalf("document has only %d chunks, want more than %d", len(chunks), maxChunks)
+		}
+
+		sampled := SampleChunks(chunks, maxChunks)
+		if len(sampled) != maxChunks {
+			t.Errorf("sampled %d chunks, want %d", len(sampled), maxChunks)
+		}
+		if sampled[0] != chunks[0] {
+			t.Errorf("max %d: first chunk not kept", maxChunks)
+		}
+		if maxChunks > 1 && sampled[len(sampled)-1] != chunks[len(chunks)-1] {
+			t.Errorf("max %d: last chunk not kept", maxChunks)
+		}
+
+		// Sampled chunks keep their document order
+		next := 0
+		for _, chunk := range sampled {
+			for next < len(chunks) && chunks[next] != chunk {
+				next++
+			}
+			if next == len(chunks) {
+				t.Fatalf("max %d: sampled chunks are out of order", maxChunks)
+			}
+			next++
+		}
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	chunker := NewTextChunker()
+
+	tests := []struct {
+		name      string
+		text      string
+		chunkSize int
+		overlap   int
+		want      []string
+		wantErr   bool
+	}{
+		{"single chunk", "One sentence. Another one.", 100, 0, []string{"One sentence. Another one."}, false},
+		{"split at sentences", "First sentence here. Second sentence here.", 25, 0, []string{"First sentence here.", "Second sentence here."}, false},
+		{"empty text", "   ", 100, 0, nil, true},
+		{"zero chunk size", "Text.", 0, 0, nil, true},
+		{"overlap not below size", "Text.", 10, 10, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chunker.ChunkText(tt.text, tt.chunkSize, tt.overlap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChunkText error = %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChunkText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package analyzer
+
+import (
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// presentDate is the normalized end date of a current position
+const presentDate = "Present"
+
+// presentAliases are the end dates, lowercased, meaning the position is current
+var presentAliases = map[string]bool{
+	"present": true, "current": true, "now": true, "today": true, "ongoing": true,
+}
+
+// Formats of experience dates. Month names are matched by their first three letters.
+var (
+	yearMonthDate = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})$`)         // 2020-03, 2020/3
+	monthYearDate = regexp.MustCompile(`^(\d{1,2})[-/.](\d{4})$`)         // 03/2020
+	namedDate     = regexp.MustCompile(`^([a-z]{3})[a-z]*\.?,? (\d{4})$`) // Mar 2020, March 2020
+	yearDate      = regexp.MustCompile(`^(\d{4})$`)                       // 2020
+)
+
+// monthNames maps the three-letter month abbreviations to month numbers
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// experienceDate is a parsed experience start or end date
+type experienceDate struct {
+	year    int
+	month   int  // 1-12, 0 when only the year is known
+	present bool // The position is current; year and month are unset
+}
+
+// parseExperienceDate parses "YYYY", "YYYY-MM" (also with / or .), "MM/YYYY",
+// "Mar 2020" or "Present" and its synonyms. ok is false for anything else.
+func parseExperienceDate(s string) (date experienceDate, ok bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if presentAliases[s] {
+		return experienceDate{present: true}, true
+	}
+
+	var year, month int
+	if m := yearMonthDate.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		month, _ = strconv.Atoi(m[2])
+	} else if m := monthYearDate.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		year, _ = strconv.Atoi(m[2])
+	} else if m := namedDate.FindStringSubmatch(s); m != nil {
+		if month = monthNames[m[1]]; month == 0 {
+			return experienceDate{}, false
+		}
+		year, _ = strconv.Atoi(m[2])
+	} else if m := yearDate.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+	} else {
+		return experienceDate{}, false
+	}
+
+	if year < 1900 || month < 0 || month > 12 {
+		return experienceDate{}, false
+	}
+	return experienceDate{year: year, month: month}, true
+}
+
+// String formats the date as "YYYY-MM", "YYYY" or "Present"
+func (d experienceDate) String() string {
+	switch {
+	case d.present:
+		return presentDate
+	case d.month == 0:
+		return strconv.Itoa(d.year)
+	default:
+		return strconv.Itoa(d.year) + "-" + twoDigits(d.month)
+	}
+}
+
+// firstMonth is the earliest month the date can refer to, counted from year 0.
+// A year on its own starts in January; Present is the month of now.
+func (d experienceDate) firstMonth(now time.Time) int {
+	if d.present {
+		return monthIndex(now.Year(), int(now.Month()))
+	}
+	return monthIndex(d.year, max(d.month, 1))
+}
+
+// lastMonth is the latest month the date can refer to: a year on its own ends in December
+func (d experienceDate) lastMonth(now time.Time) int {
+	if d.present || d.month != 0 {
+		return d.firstMonth(now)
+	}
+	return monthIndex(d.year, 12)
+}
+
+// monthIndex numbers months consecutively so differences between them are month counts
+func monthIndex(year, month int) int {
+	return year*12 + month - 1
+}
+
+// twoDigits formats a month with a leading zero
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// Flags set on experience entries whose dates don't add up
+const (
+	ExperienceFlagFutureDate     = "future_date"      // A date is after the current month
+	ExperienceFlagEndBeforeStart = "end_before_start" // The end date is before the start date
+	ExperienceFlagOverlapping    = "overlapping"      // The position overlaps another one by more than a month
+)
+
+// WorkYearsDiscrepancy is how many years the LLM's total work years may differ from the
+// total computed from experience dates before it is kept as the reported total
+const WorkYearsDiscrepancy = 1.0
+
+// experienceSpan is the months covered by an entry with a parsed start and end
+type experienceSpan struct {
+	first, last int  // First and last month the position may cover
+	from, to    int  // Months counted as worked, to exclusive
+	yearOnly    bool // A bound is only known to the year
+}
+
+// newExperienceSpan returns the months between an entry's start and end. "2018 - 2020" is
+// two years, so a bound known only to the year is counted as worked from January.
+func newExperienceSpan(start, end experienceDate, now time.Time) *experienceSpan {
+	span := &experienceSpan{
+		first:    start.firstMonth(now),
+		last:     end.lastMonth(now),
+		yearOnly: start.month == 0 || (!end.present && end.month == 0),
+	}
+	span.from, span.to = span.first, span.last+1
+	if span.yearOnly {
+		span.to = end.firstMonth(now)
+	}
+	return span
+}
+
+// monthsToYears rounds a number of months to tenths of a year
+func monthsToYears(months int) float64 {
+	return math.Round(float64(months)/12*10) / 10
+}
+
+// NormalizeExperience post-processes experience entries extracted by the LLM. Dates it
+// can parse are rewritten as "YYYY-MM", "YYYY" or "Present"; others are kept as they
+// are. Entries with both dates get Years recomputed from them, counting both the start
+// and end months, so the LLM's own arithmetic is only used when a date is missing or
+// the dates are too coarse.
+// Entries dated after now, ending before they start or overlapping another entry
+// are flagged. Entries are modified in place and returned.
+func NormalizeExperience(entries []models.ExperienceEntry, now time.Time) []models.ExperienceEntry {
+	spans := make([]*experienceSpan, len(entries))
+	current := monthIndex(now.Year(), int(now.Month()))
+
+	for i := range entries {
+		entry := &entries[i]
+		entry.Flags = nil
+
+		start, startOK := normalizeExperienceDate(entry.StartDate)
+		end, endOK := normalizeExperienceDate(entry.EndDate)
+
+		if (startOK && !start.present && start.firstMonth(now) > current) ||
+			(endOK && !end.present && end.firstMonth(now) > current) {
+			entry.Flags = append(entry.Flags, ExperienceFlagFutureDate)
+		}
+
+		if !startOK || !endOK || start.present {
+			continue
+		}
+
+		span := newExperienceSpan(start, end, now)
+		if span.last < span.first {
+			entry.Flags = append(entry.Flags, ExperienceFlagEndBeforeStart)
+			continue
+		}
+
+		spans[i] = span
+
+		// Within a single year the dates say too little to improve on the LLM's estimate
+		if span.to > span.from {
+			entry.Years = monthsToYears(span.to - span.from)
+		}
+	}
+
+	for i := range entries {
+		for j := range entries {
+			if i != j && spans[i] != nil && spans[j] != nil && spansOverlap(spans[i], spans[j]) {
+				entries[i].Flags = append(entries[i].Flags, ExperienceFlagOverlapping)
+				break
+			}
+		}
+	}
+
+	return entries
+}
+
+// spansOverlap reports whether two positions share more than a month, the usual overlap
+// when changing jobs. Positions dated only by year may share up to a year, since
+// "2018 - 2020" followed by "2020 - 2022" is a change of jobs within 2020.
+func spansOverlap(a, b *experienceSpan) bool {
+	allowed := 1
+	if a.yearOnly || b.yearOnly {
+		allowed = 12
+	}
+	shared := min(a.last, b.last) - max(a.first, b.first) + 1
+	return shared > allowed
+}
+
+// normalizeExperienceDate parses a date and rewrites it in normalized form. ok is false
+// when the date is missing or can't be parsed, leaving it unchanged.
+func normalizeExperienceDate(s *string) (date experienceDate, ok bool) {
+	if s == nil {
+		return experienceDate{}, false
+	}
+	date, ok = parseExperienceDate(*s)
+	if ok {
+		*s = date.String()
+	}
+	return date, ok
+}
+
+// ExperienceTotalYears computes the years worked across experience entries, counting
+// months covered by several concurrent positions once. Entries without a usable start
+// and end can't be placed in time, so their Years are added as they are. ok is false
+// when no entry has usable dates.
+func ExperienceTotalYears(entries []models.ExperienceEntry, now time.Time) (total float64, ok bool) {
+	type interval struct{ from, to int }
+	var intervals []interval
+	undated := 0.0
+
+	for _, entry := range entries {
+		var span *experienceSpan
+		if entry.StartDate != nil && entry.EndDate != nil {
+			start, startOK := parseExperienceDate(*entry.StartDate)
+			end, endOK := parseExperienceDate(*entry.EndDate)
+			if startOK && endOK && !start.present {
+				span = newExperienceSpan(start, end, now)
+			}
+		}
+
+		switch {
+		case span == nil || span.last < span.first:
+			undated += entry.Years
+		case span.to > span.from:
+			intervals = append(intervals, interval{span.from, span.to})
+		default:
+			// Dated only to a single year: place the entry's own estimate within that year
+			months := min(max(int(math.Round(entry.Years*12)), 1), 12)
+			intervals = append(intervals, interval{span.first, span.first + months})
+		}
+	}
+
+	if len(intervals) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].from < intervals[j].from })
+
+	months := 0
+	current := intervals[0]
+	for _, next := range intervals[1:] {
+		if next.from <= current.to {
+			current.to = max(current.to, next.to)
+			continue
+		}
+		months += current.to - current.from
+		current = next
+	}
+	months += current.to - current.from
+
+	return monthsToYears(months) + undated, true
+}
+
+// reconcileWorkYears replaces the LLM's total work years with the total computed from
+// the experience dates, which counts concurrent positions once. When the two differ by
+// more than WorkYearsDiscrepancy the LLM's total is returned as reported for review.
+// Without dated experience the LLM's total is kept.
+func reconcileWorkYears(llmTotal *float64, entries []models.ExperienceEntry, now time.Time) (total, reported *float64) {
+	computed, ok := ExperienceTotalYears(entries, now)
+	if !ok {
+		return llmTotal, nil
+	}
+
+	computed = math.Round(computed*10) / 10
+	if llmTotal != nil && math.Abs(*llmTotal-computed) > WorkYearsDiscrepancy {
+		log.Printf("Total work years reported as %.1f but experience dates add up to %.1f", *llmTotal, computed)
+		reported = llmTotal
+	}
+	return &computed, reported
+}
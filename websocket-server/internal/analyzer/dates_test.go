@@ -0,0 +1,270 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestParseExperienceDate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  experienceDate
+		ok    bool
+	}{
+		{"2020", experienceDate{year: 2020}, true},
+		{"2020-03", experienceDate{year: 2020, month: 3}, true},
+		{"2020-3", experienceDate{year: 2020, month: 3}, true},
+		{"2020/03", experienceDate{year: 2020, month: 3}, true},
+		{"2020.03", experienceDate{year: 2020, month: 3}, true},
+		{"03/2020", experienceDate{year: 2020, month: 3}, true},
+		{"3-2020", experienceDate{year: 2020, month: 3}, true},
+		{"Mar 2020", experienceDate{year: 2020, month: 3}, true},
+		{"March, 2020", experienceDate{year: 2020, month: 3}, true},
+		{"Sept. 2020", experienceDate{year: 2020, month: 9}, true},
+		{"Present", experienceDate{present: true}, true},
+		{" current ", experienceDate{present: true}, true},
+		{"NOW", experienceDate{present: true}, true},
+		{"", experienceDate{}, false},
+		{"2020-13", experienceDate{}, false},
+		{"1850", experienceDate{}, false},
+		{"Foo 2020", experienceDate{}, false},
+		{"Spring 2019", experienceDate{}, false},
+		{"2020-03-01", experienceDate{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parseExperienceDate(tt.input)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseExperienceDate(%q) = %+v, %t, want %+v, %t", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestExperienceDateString(t *testing.T) {
+	tests := []struct {
+		date experienceDate
+		want string
+	}{
+		{experienceDate{year: 2020}, "2020"},
+		{experienceDate{year: 2020, month: 3}, "2020-03"},
+		{experienceDate{year: 2020, month: 11}, "2020-11"},
+		{experienceDate{present: true}, "Present"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.date.String(); got != tt.want {
+			t.Errorf("%+v formatted as %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeExperience(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		start, end *string
+		years      float64
+		wantStart  *string
+		wantEnd    *string
+		wantYears  float64
+		wantFlags  []string
+	}{
+		{
+			name:  "years recomputed from the months",
+			start: strPtr("2019-01"), end: strPtr("2021-06"), years: 10,
+			wantStart: strPtr("2019-01"), wantEnd: strPtr("2021-06"), wantYears: 2.5,
+		},
+		{
+			name:  "present counts to the current month",
+			start: strPtr("Mar 2020"), end: strPtr("current"), years: 1,
+			wantStart: strPtr("2020-03"), wantEnd: strPtr("Present"), wantYears: 4.3,
+		},
+		{
+			name:  "years only",
+			start: strPtr("2018"), end: strPtr("2020"), years: 3,
+			wantStart: strPtr("2018"), wantEnd: strPtr("2020"), wantYears: 2,
+		},
+		{
+			name:  "single year keeps the estimate",
+			start: strPtr("2020"), end: strPtr("2020"), years: 0.5,
+			wantStart: strPtr("2020"), wantEnd: strPtr("2020"), wantYears: 0.5,
+		},
+		{
+			name:  "missing end keeps the estimate",
+			start: strPtr("03/2019"), years: 1.5,
+			wantStart: strPtr("2019-03"), wantYears: 1.5,
+		},
+		{
+			name:  "unparseable date kept as is",
+			start: strPtr("Spring 2019"), end: strPtr("2020-06"), years: 1,
+			wantStart: strPtr("Spring 2019"), wantEnd: strPtr("2020-06"), wantYears: 1,
+		},
+		{
+			name:  "end before start",
+			start: strPtr("2021-05"), end: strPtr("2020-01"), years: 1,
+			wantStart: strPtr("2021-05"), wantEnd: strPtr("2020-01"), wantYears: 1,
+			wantFlags: []string{ExperienceFlagEndBeforeStart},
+		},
+		{
+			name:  "future end date",
+			start: strPtr("2024-03"), end: strPtr("2025-02"), years: 2,
+			wantStart: strPtr("2024-03"), wantEnd: strPtr("2025-02"), wantYears: 1,
+			wantFlags: []string{ExperienceFlagFutureDate},
+		},
+		{
+			name:  "present start",
+			start: strPtr("Present"), end: strPtr("Present"), years: 1,
+			wantStart: strPtr("Present"), wantEnd: strPtr("Present"), wantYears: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := NormalizeExperience([]models.ExperienceEntry{{StartDate: tt.start, EndDate: tt.end, Years: tt.years}}, now)
+			got := entries[0]
+
+			if !reflect.DeepEqual(got.StartDate, tt.wantStart) || !reflect.DeepEqual(got.EndDate, tt.wantEnd) {
+				t.Errorf("dates = %v - %v, want %v - %v", derefOrNil(got.StartDate), derefOrNil(got.EndDate), derefOrNil(tt.wantStart), derefOrNil(tt.wantEnd))
+			}
+			if got.Years != tt.wantYears {
+				t.Errorf("years = %v, want %v", got.Years, tt.wantYears)
+			}
+			if !reflect.DeepEqual(got.Flags, tt.wantFlags) {
+				t.Errorf("flags = %v, want %v", got.Flags, tt.wantFlags)
+			}
+		})
+	}
+}
+
+func TestNormalizeExperienceFlagsOverlaps(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	entries := NormalizeExperience([]models.ExperienceEntry{
+		{Company: "Acme", StartDate: strPtr("2019-01"), EndDate: strPtr("2021-06")},
+		{Company: "Initech", StartDate: strPtr("2021-06"), EndDate: strPtr("2023-01")}, // Shares only the month of the change
+		{Company: "Globex", StartDate: strPtr("2020-01"), EndDate: strPtr("2020-12")},  // Within Acme
+		{Company: "Hooli", StartDate: strPtr("2023"), EndDate: strPtr("2024")},         // Shares 2023 with Initech, by year only
+		{Company: "Undated", Years: 2},
+	}, now)
+
+	want := map[string][]string{
+		"Acme":    {ExperienceFlagOverlapping},
+		"Initech": nil,
+		"Globex":  {ExperienceFlagOverlapping},
+		"Hooli":   nil,
+		"Undated": nil,
+	}
+	for _, entry := range entries {
+		if !reflect.DeepEqual(entry.Flags, want[entry.Company]) {
+			t.Errorf("%s flags = %v, want %v", entry.Company, entry.Flags, want[entry.Company])
+		}
+	}
+
+	// Normalizing again recomputes the flags instead of adding to them
+	again := NormalizeExperience(entries, now)
+	if !reflect.DeepEqual(again[0].Flags, []string{ExperienceFlagOverlapping}) {
+		t.Errorf("flags after normalizing twice = %v", again[0].Flags)
+	}
+}
+
+func TestExperienceTotalYears(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		entries []models.ExperienceEntry
+		want    float64
+		ok      bool
+	}{
+		{
+			name: "concurrent positions counted once",
+			entries: []models.ExperienceEntry{
+				{StartDate: strPtr("2019-01"), EndDate: strPtr("2021-06"), Years: 2.5},
+				{StartDate: strPtr("2020-01"), EndDate: strPtr("2020-12"), Years: 1},
+				{StartDate: strPtr("2022-01"), EndDate: strPtr("2022-12"), Years: 1},
+			},
+			want: 3.5, // The naive sum is 4.5
+			ok:   true,
+		},
+		{
+			name: "undated entries added as they are",
+			entries: []models.ExperienceEntry{
+				{StartDate: strPtr("2022-01"), EndDate: strPtr("Present"), Years: 2.5},
+				{Years: 1.5},
+				{StartDate: strPtr("2021-05"), EndDate: strPtr("2020-01"), Years: 1},
+			},
+			want: 5,
+			ok:   true,
+		},
+		{
+			name: "single year placed within the year",
+			entries: []models.ExperienceEntry{
+				{StartDate: strPtr("2020"), EndDate: strPtr("2020"), Years: 0.5},
+			},
+			want: 0.5,
+			ok:   true,
+		},
+		{
+			name:    "no dated entries",
+			entries: []models.ExperienceEntry{{Years: 3}, {StartDate: strPtr("2020")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExperienceTotalYears(tt.entries, now)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ExperienceTotalYears = %v, %t, want %v, %t", got, ok, tt.want, tt.ok)
+			}
+
+			naive := 0.0
+			for _, entry := range tt.entries {
+				naive += entry.Years
+			}
+			if got > naive {
+				t.Errorf("total %v is more than the sum of the entries' years %v", got, naive)
+			}
+		})
+	}
+}
+
+func TestReconcileWorkYears(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	dated := []models.ExperienceEntry{{StartDate: strPtr("2019-01"), EndDate: strPtr("2023-12"), Years: 5}}
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		llmTotal     *float64
+		entries      []models.ExperienceEntry
+		wantTotal    *float64
+		wantReported *float64
+	}{
+		{"close to the dates", floatPtr(5.5), dated, floatPtr(5), nil},
+		{"far from the dates", floatPtr(9), dated, floatPtr(5), floatPtr(9)},
+		{"no LLM total", nil, dated, floatPtr(5), nil},
+		{"no dates", floatPtr(9), []models.ExperienceEntry{{Years: 9}}, floatPtr(9), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, reported := reconcileWorkYears(tt.llmTotal, tt.entries, now)
+			if !reflect.DeepEqual(total, tt.wantTotal) || !reflect.DeepEqual(reported, tt.wantReported) {
+				t.Errorf("reconcileWorkYears = %v, %v, want %v, %v", derefOrNil(total), derefOrNil(reported), derefOrNil(tt.wantTotal), derefOrNil(tt.wantReported))
+			}
+		})
+	}
+}
+
+// derefOrNil returns the value p points to, or nil, for readable test failures
+func derefOrNil[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxDocumentPath is the main document part of a DOCX package
+const docxDocumentPath = "word/document.xml"
+
+// maxDOCXDocumentSize caps how much of the uncompressed document part is read, so a
+// small upload can't expand into an unbounded amount of XML
+const maxDOCXDocumentSize = 50 * 1024 * 1024
+
+// docxCell collects the paragraphs of a table cell, or of the document body
+type docxCell struct {
+	paragraphs []string
+	current    strings.Builder
+}
+
+// endParagraph finishes the paragraph being written, dropping it if it's blank
+func (c *docxCell) endParagraph() {
+	if text := strings.TrimSpace(c.current.String()); text != "" {
+		c.paragraphs = append(c.paragraphs, text)
+	}
+	c.current.Reset()
+}
+
+// text returns the cell's paragraphs, one per line
+func (c *docxCell) text() string {
+	c.endParagraph()
+	return strings.Join(c.paragraphs, "\n")
+}
+
+// docxTable collects the cells of the row of a table being read
+type docxTable struct {
+	row []string
+}
+
+// formatRow lays out a table row: cells holding a single line are joined on one line with
+// " | " between them, like a data table ("Go | 5 years"), while rows with multi-line cells,
+// as in table-based resume layouts, put each cell on its own lines. Empty cells are dropped.
+func formatRow(cells []string) string {
+	var filled []string
+	multiline := false
+	for _, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		filled = append(filled, cell)
+		multiline = multiline || strings.Contains(cell, "\n")
+	}
+
+	if multiline {
+		return strings.Join(filled, "\n")
+	}
+	return strings.Join(filled, " | ")
+}
+
+// extractDOCXText extracts the text of a DOCX file from its word/document.xml. Paragraphs
+// and line breaks become newlines and tabs become spaces; table cells are kept apart (see
+// formatRow), including in nested tables. Deleted revisions, field codes and the fallback
+// copies of text boxes are skipped.
+func extractDOCXText(fileContent []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(fileContent), int64(len(fileContent)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX: %w", err)
+	}
+
+	var document *zip.File
+	for _, f := range archive.File {
+		if f.Name == docxDocumentPath {
+			document = f
+			break
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("invalid DOCX: %s not found", docxDocumentPath)
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", docxDocumentPath, err)
+	}
+	defer rc.Close()
+
+	return parseDOCXDocument(io.LimitReader(rc, maxDOCXDocumentSize))
+}
+
+// parseDOCXDocument walks the paragraphs and tables of a WordprocessingML document
+func parseDOCXDocument(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	body := &docxCell{}
+	cells := []*docxCell{body} // Innermost cell last; the body is the outermost
+	var tables []*docxTable
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse DOCX: %w", err)
+		}
+
+		cell := cells[len(cells)-1]
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				cell.current.WriteString(" ")
+			case "br", "cr":
+				cell.current.WriteString("\n")
+			case "tbl":
+				cell.endParagraph()
+				tables = append(tables, &docxTable{})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].row = nil
+				}
+			case "tc":
+				cells = append(cells, &docxCell{})
+			case "instrText", "delText", "Fallback":
+				if err := decoder.Skip(); err != nil {
+					return "", fmt.Errorf("failed to parse DOCX: %w", err)
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				cell.endParagraph()
+			case "tc":
+				if len(cells) > 1 && len(tables) > 0 {
+					cells = cells[:len(cells)-1]
+					table := tables[len(tables)-1]
+					table.row = append(table.row, cell.text())
+				}
+			case "tr":
+				if len(tables) > 0 {
+					if row := formatRow(tables[len(tables)-1].row); row != "" {
+						cell.paragraphs = append(cell.paragraphs, row)
+					}
+				}
+			case "tbl":
+				if len(tables) > 0 {
+					tables = tables[:len(tables)-1]
+				}
+			}
+
+		case xml.CharData:
+			if inText {
+				cell.current.Write(t)
+			}
+		}
+	}
+
+	return body.text(), nil
+}
@@ -0,0 +1,165 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// newDOCX packages the body of a WordprocessingML document as a DOCX file
+func newDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`,
+		docxDocumentPath: `<?xml version="1.0" encoding="UTF-8"?>` +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+			`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"><w:body>` + body + `</w:body></w:document>`,
+	}
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// docxParagraph is a paragraph of a single run of text
+func docxParagraph(text string) string {
+	return `<w:p><w:r><w:t xml:space="preserve">` + text + `</w:t></w:r></w:p>`
+}
+
+// docxRow is a table row with a cell per entry, each holding the entry's paragraphs
+func docxRow(cells ...[]string) string {
+	row := "<w:tr>"
+	for _, paragraphs := range cells {
+		row += "<w:tc>"
+		for _, p := range paragraphs {
+			row += docxParagraph(p)
+		}
+		row += "</w:tc>"
+	}
+	return row + "</w:tr>"
+}
+
+func TestExtractDOCXText(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "paragraphs",
+			body: docxParagraph("Ada Lovelace") + docxParagraph("  ") + docxParagraph("Software Engineer"),
+			want: "Ada Lovelace\nSoftware Engineer",
+		},
+		{
+			name: "runs, tabs and breaks",
+			body: `<w:p><w:r><w:t>Go</w:t></w:r><w:r><w:tab/><w:t>SQL</w:t><w:br/><w:t>Rust</w:t></w:r></w:p>`,
+			want: "Go SQL\nRust",
+		},
+		{
+			name: "data table rows on one line",
+			body: "<w:tbl>" + docxRow([]string{"Skill"}, []string{"Years"}) + docxRow([]string{"Go"}, []string{"5"}, []string{""}) + "</w:tbl>",
+			want: "Skill | Years\nGo | 5",
+		},
+		{
+			name: "layout table cells kept apart",
+			body: docxParagraph("Experience") + "<w:tbl>" +
+				docxRow([]string{"Acme", "2019-2023"}, []string{"Backend Engineer", "Built Go services"}) +
+				"</w:tbl>" + docxParagraph("Education"),
+			want: "Experience\nAcme\n2019-2023\nBackend Engineer\nBuilt Go services\nEducation",
+		},
+		{
+			name: "nested table",
+			body: "<w:tbl><w:tr><w:tc>" + docxParagraph("Skills") +
+				"<w:tbl>" + docxRow([]string{"Go"}, []string{"SQL"}) + "</w:tbl>" +
+				"</w:tc><w:tc>" + docxParagraph("Contact") + "</w:tc></w:tr></w:tbl>",
+			want: "Skills\nGo | SQL\nContact",
+		},
+		{
+			name: "deletions, field codes and fallbacks skipped",
+			body: `<w:p><w:r><w:t>Kept</w:t></w:r><w:del><w:r><w:delText>Deleted</w:delText></w:r></w:del>` +
+				`<w:r><w:instrText>HYPERLINK "x"</w:instrText></w:r></w:p>` +
+				`<w:p><mc:AlternateContent><mc:Choice><w:r><w:t>Text box</w:t></w:r></mc:Choice>` +
+				`<mc:Fallback><w:r><w:t>Text box</w:t></w:r></mc:Fallback></mc:AlternateContent></w:p>`,
+			want: "Kept\nText box",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractDOCXText(newDOCX(t, tt.body))
+			if err != nil {
+				t.Fatalf("extractDOCXText: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractDOCXTextErrors(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if _, err := archive.Create("word/styles.xml"); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"not a zip", []byte("plain text"), "failed to open DOCX"},
+		{"no document part", buf.Bytes(), "word/document.xml not found"},
+		{"malformed XML", newDOCX(t, "<w:p><w:r><w:t>Unclosed</w:r></w:p>"), "failed to parse DOCX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := extractDOCXText(tt.content); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractTextFromTableBasedDOCX(t *testing.T) {
+	body := docxParagraph("Ada Lovelace") + "<w:tbl>" +
+		docxRow([]string{"Acme Corp"}, []string{"2019 - 2023"}) +
+		docxRow([]string{"Initech"}, []string{"2016 - 2019"}) +
+		"</w:tbl>"
+	extractor := NewTextExtractor()
+
+	text, err := extractor.ExtractText(context.Background(), newDOCX(t, body), "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+	if err != nil {
+		t.Fatalf("ExtractText: %v", err)
+	}
+
+	for _, want := range []string{"Acme Corp | 2019 - 2023", "Initech | 2016 - 2019"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text is missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Corp2019") || strings.Contains(text, "<w:") {
+		t.Errorf("cells ran together or markup leaked:\n%s", text)
+	}
+
+	if _, err := extractor.ExtractText(context.Background(), newDOCX(t, docxParagraph(" ")), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"); err == nil {
+		t.Error("expected an error for a DOCX without text")
+	}
+}
@@ -3,19 +3,38 @@ package analyzer
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/llms/openai"
 )
 
+// Defaults for EmbeddingConfig
+const (
+	DefaultEmbeddingBatchSize   = 16 // Texts sent per embedding request
+	DefaultEmbeddingConcurrency = 4  // Embedding requests in flight at once
+)
+
+// EmbeddingConfig tunes how DefaultEmbeddingGenerator embeds multiple texts
+type EmbeddingConfig struct {
+	BatchSize   int // Texts per embedding request; progress is reported per batch (0 = DefaultEmbeddingBatchSize)
+	Concurrency int // Batches embedded at once (0 = DefaultEmbeddingConcurrency)
+}
+
 // DefaultEmbeddingGenerator implements EmbeddingGenerator interface using LangChain
 type DefaultEmbeddingGenerator struct {
-	embedder *embeddings.EmbedderImpl
+	embedder    *embeddings.EmbedderImpl
+	batchSize   int
+	concurrency int
 }
 
-// NewEmbeddingGenerator creates a new embedding generator
+// NewEmbeddingGenerator creates a new embedding generator. A nil config uses the defaults.
 // Note: This uses OpenAI embeddings by default. You can replace with your preferred embedding model.
-func NewEmbeddingGenerator(apiKey string) (EmbeddingGenerator, error) {
+func NewEmbeddingGenerator(apiKey string, config *EmbeddingConfig) (EmbeddingGenerator, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required for embedding generation")
 	}
@@ -35,8 +54,20 @@ func NewEmbeddingGenerator(apiKey string) (EmbeddingGenerator, error) {
 		return nil, fmt.Errorf("failed to create embedder: %w", err)
 	}
 
+	batchSize, concurrency := DefaultEmbeddingBatchSize, DefaultEmbeddingConcurrency
+	if config != nil {
+		if config.BatchSize > 0 {
+			batchSize = config.BatchSize
+		}
+		if config.Concurrency > 0 {
+			concurrency = config.Concurrency
+		}
+	}
+
 	return &DefaultEmbeddingGenerator{
-		embedder: embedder,
+		embedder:    embedder,
+		batchSize:   batchSize,
+		concurrency: concurrency,
 	}, nil
 }
 
@@ -55,56 +86,139 @@ func (e *DefaultEmbeddingGenerator) GenerateEmbedding(ctx context.Context, text
 	return result, nil
 }
 
-// GenerateEmbeddings creates vector embeddings for multiple texts
-func (e *DefaultEmbeddingGenerator) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+// GenerateEmbeddings creates vector embeddings for multiple texts. Texts are embedded in
+// batches, several at once; each batch's embeddings are stored at the batch's offset, so
+// the result keeps the order of texts however the requests complete, and each batch is
+// passed to onBatch as it completes. The first failed batch cancels the others; the
+// embeddings of batches that completed are still returned with the error, nil for the
+// texts that weren't embedded.
+func (e *DefaultEmbeddingGenerator) GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("texts cannot be empty")
 	}
-
-	embeddings := make([][]float32, len(texts))
-
-	// Generate embeddings for each text
 	for i, text := range texts {
 		if text == "" {
 			return nil, fmt.Errorf("text at index %d is empty", i)
 		}
+	}
 
-		embedding, err := e.embedder.EmbedQuery(ctx, text)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	embeddings := make([][]float32, len(texts))
+	counter := &progressCounter{total: len(texts), report: progress, onBatch: onBatch}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		stopped  error // Set when ctx ended before every batch was started
+	)
+	slots := make(chan struct{}, e.concurrency)
+
+batches:
+	for start := 0; start < len(texts); start += e.batchSize {
+		end := min(start+e.batchSize, len(texts))
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			stopped = ctx.Err()
+			break batches
 		}
 
-		embeddings[i] = embedding
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			batch, err := e.embedder.EmbedDocuments(ctx, texts[start:end])
+			if err == nil && len(batch) != end-start {
+				err = fmt.Errorf("embedder returned %d embeddings for %d texts", len(batch), end-start)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("failed to generate embeddings for texts %d-%d: %w", start, end-1, err)
+					cancel()
+				})
+				return
+			}
+
+			copy(embeddings[start:end], batch)
+			counter.add(start, batch)
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return embeddings, firstErr
+	}
+	if stopped != nil {
+		return embeddings, fmt.Errorf("failed to generate embeddings: %w", stopped)
 	}
 
 	return embeddings, nil
 }
 
-// PlaceholderEmbeddingGenerator is a placeholder implementation for testing without API keys
-type PlaceholderEmbeddingGenerator struct{}
+// progressCounter totals the texts embedded by concurrent batches, passing each batch to
+// onBatch and reporting the running count, one call at a time
+type progressCounter struct {
+	mu      sync.Mutex
+	done    int
+	total   int
+	report  EmbeddingProgress
+	onBatch EmbeddedBatch
+}
+
+// add records the embedded batch of texts starting at start
+func (c *progressCounter) add(start int, batch [][]float32) {
+	if c.report == nil && c.onBatch == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.onBatch != nil {
+		c.onBatch(start, batch)
+	}
+	c.done += len(batch)
+	if c.report != nil {
+		c.report(c.done, c.total)
+	}
+}
+
+// DefaultPlaceholderDimensions matches the dimension of OpenAI's ada-002 embeddings
+const DefaultPlaceholderDimensions = 1536
 
-// NewPlaceholderEmbeddingGenerator creates a placeholder embedding generator
-func NewPlaceholderEmbeddingGenerator() EmbeddingGenerator {
-	return &PlaceholderEmbeddingGenerator{}
+// PlaceholderEmbeddingGenerator is a placeholder implementation for testing without API
+// keys. Embeddings are deterministic: each word of a text is hashed into a pseudo-random
+// unit vector and a text's embedding is the normalized sum of its words' vectors. The same
+// text always gets the same embedding, different texts get different ones, and texts
+// sharing more words are more similar, so matching and ranking can be exercised.
+type PlaceholderEmbeddingGenerator struct {
+	dimensions int
 }
 
-// GenerateEmbedding returns a placeholder embedding vector
+// NewPlaceholderEmbeddingGenerator creates a placeholder embedding generator producing
+// vectors of the given dimension (0 or less = DefaultPlaceholderDimensions)
+func NewPlaceholderEmbeddingGenerator(dimensions int) EmbeddingGenerator {
+	if dimensions <= 0 {
+		dimensions = DefaultPlaceholderDimensions
+	}
+	return &PlaceholderEmbeddingGenerator{dimensions: dimensions}
+}
+
+// GenerateEmbedding returns the placeholder embedding of text
 func (e *PlaceholderEmbeddingGenerator) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
 	if text == "" {
 		return nil, fmt.Errorf("text cannot be empty")
 	}
 
-	// Return a simple placeholder embedding (dimension 1536 to match OpenAI's ada-002)
-	embedding := make([]float32, 1536)
-	for i := range embedding {
-		embedding[i] = 0.1 // Simple placeholder values
-	}
-
-	return embedding, nil
+	return e.embed(text), nil
 }
 
-// GenerateEmbeddings returns placeholder embedding vectors
-func (e *PlaceholderEmbeddingGenerator) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+// GenerateEmbeddings returns placeholder embedding vectors, passing on batches and
+// reporting progress every DefaultEmbeddingBatchSize texts like the real generator
+func (e *PlaceholderEmbeddingGenerator) GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("texts cannot be empty")
 	}
@@ -114,13 +228,64 @@ func (e *PlaceholderEmbeddingGenerator) GenerateEmbeddings(ctx context.Context,
 		if text == "" {
 			return nil, fmt.Errorf("text at index %d is empty", i)
 		}
+		embeddings[i] = e.embed(text)
 
-		embedding := make([]float32, 1536)
-		for j := range embedding {
-			embedding[j] = 0.1 // Simple placeholder values
+		if (i+1)%DefaultEmbeddingBatchSize == 0 || i+1 == len(texts) {
+			start := i / DefaultEmbeddingBatchSize * DefaultEmbeddingBatchSize
+			if onBatch != nil {
+				onBatch(start, embeddings[start:i+1])
+			}
+			if progress != nil {
+				progress(i+1, len(texts))
+			}
 		}
-		embeddings[i] = embedding
 	}
 
 	return embeddings, nil
 }
+
+// embed sums the hash-seeded vectors of text's lowercased words and normalizes the sum
+// to unit length. Text without letters or digits is embedded as a single word.
+func (e *PlaceholderEmbeddingGenerator) embed(text string) []float32 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		words = []string{text}
+	}
+
+	sum := make([]float64, e.dimensions)
+	for _, word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		state := h.Sum64()
+		for i := range sum {
+			// Uniform in [-1, 1)
+			sum[i] += float64(splitmix64(&state)>>11)/(1<<52) - 1
+		}
+	}
+
+	var norm float64
+	for _, v := range sum {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	embedding := make([]float32, e.dimensions)
+	for i, v := range sum {
+		if norm > 0 {
+			embedding[i] = float32(v / norm)
+		}
+	}
+	return embedding
+}
+
+// splitmix64 advances state and returns its next pseudo-random value. It is used rather
+// than math/rand so the embeddings never change between Go versions.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,326 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/embeddings"
+)
+
+// indexedTexts returns n texts "text 0" to "text n-1"
+func indexedTexts(n int) []string {
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	return texts
+}
+
+// newIndexEmbedder returns a DefaultEmbeddingGenerator whose embedding of "text N" is
+// [N]. Each request is delayed by delay(first index of its batch), and a request whose
+// batch contains failAt fails. inFlight reports the most requests that ran at once.
+func newIndexEmbedder(t *testing.T, batchSize, concurrency int, delay func(first int) time.Duration, failAt int) (gen *DefaultEmbeddingGenerator, inFlight func() int32) {
+	t.Helper()
+
+	var running, peak atomic.Int32
+	client := embeddings.EmbedderClientFunc(func(ctx context.Context, texts []string) ([][]float32, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		result := make([][]float32, len(texts))
+		for i, text := range texts {
+			var index int
+			if _, err := fmt.Sscanf(text, "text %d", &index); err != nil {
+				return nil, err
+			}
+			if index == failAt {
+				return nil, errors.New("embedding service unavailable")
+			}
+			result[i] = []float32{float32(index)}
+		}
+
+		select {
+		case <-time.After(delay(int(result[0][0]))):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return result, nil
+	})
+
+	embedder, err := embeddings.NewEmbedder(client)
+	if err != nil {
+		t.Fatalf("NewEmbedder: %v", err)
+	}
+	return &DefaultEmbeddingGenerator{embedder: embedder, batchSize: batchSize, concurrency: concurrency}, peak.Load
+}
+
+// progressRecorder records the calls of an EmbeddingProgress
+type progressRecorder struct {
+	mu    sync.Mutex
+	calls [][2]int
+}
+
+func (r *progressRecorder) report(done, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, [2]int{done, total})
+}
+
+func TestGenerateEmbeddingsReportsProgressPerBatch(t *testing.T) {
+	const count, batchSize = 20, 3
+
+	// Later batches answer sooner, so the batches complete out of order
+	gen, inFlight := newIndexEmbedder(t, batchSize, 3, func(first int) time.Duration {
+		return time.Duration(count-first) * time.Millisecond
+	}, -1)
+
+	recorder := &progressRecorder{}
+	got, err := gen.GenerateEmbeddings(context.Background(), indexedTexts(count), recorder.report, nil)
+	if err != nil {
+		t.Fatalf("GenerateEmbeddings: %v", err)
+	}
+
+	if len(got) != count {
+		t.Fatalf("got %d embeddings, want %d", len(got), count)
+	}
+	for i, embedding := range got {
+		if len(embedding) != 1 || embedding[0] != float32(i) {
+			t.Errorf("embedding %d = %v, want [%d]", i, embedding, i)
+		}
+	}
+
+	if want := (count + batchSize - 1) / batchSize; len(recorder.calls) != want {
+		t.Fatalf("progress reported %d times, want once per batch (%d): %v", len(recorder.calls), want, recorder.calls)
+	}
+	previous := 0
+	for _, call := range recorder.calls {
+		done, total := call[0], call[1]
+		if total != count {
+			t.Errorf("progress total = %d, want %d", total, count)
+		}
+		if done <= previous || done > total {
+			t.Errorf("progress %v isn't increasing: %v", call, recorder.calls)
+		}
+		previous = done
+	}
+	if previous != count {
+		t.Errorf("final progress = %d, want %d", previous, count)
+	}
+
+	if peak := inFlight(); peak > 3 {
+		t.Errorf("%d requests ran at once, want at most 3", peak)
+	}
+}
+
+func TestGenerateEmbeddingsWithoutProgress(t *testing.T) {
+	gen, _ := newIndexEmbedder(t, 4, 2, func(int) time.Duration { return 0 }, -1)
+
+	got, err := gen.GenerateEmbeddings(context.Background(), indexedTexts(10), nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateEmbeddings: %v", err)
+	}
+	if len(got) != 10 || got[9][0] != 9 {
+		t.Errorf("embeddings = %v", got)
+	}
+}
+
+func TestGenerateEmbeddingsPassesEachBatch(t *testing.T) {
+	const count, batchSize = 20, 3
+
+	// Later batches answer sooner, so the batches complete out of order
+	gen, _ := newIndexEmbedder(t, batchSize, 3, func(first int) time.Duration {
+		return time.Duration(count-first) * time.Millisecond
+	}, -1)
+
+	var running atomic.Int32
+	seen := make(map[int]bool)
+	onBatch := func(start int, batch [][]float32) {
+		if running.Add(1) > 1 {
+			t.Error("batches passed on concurrently")
+		}
+		defer running.Add(-1)
+
+		if seen[start] {
+			t.Errorf("batch %d passed on twice", start)
+		}
+		seen[start] = true
+		for i, embedding := range batch {
+			if len(embedding) != 1 || embedding[0] != float32(start+i) {
+				t.Errorf("batch %d embedding %d = %v, want [%d]", start, i, embedding, start+i)
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := gen.GenerateEmbeddings(context.Background(), indexedTexts(count), nil, onBatch); err != nil {
+		t.Fatalf("GenerateEmbeddings: %v", err)
+	}
+	for start := 0; start < count; start += batchSize {
+		if !seen[start] {
+			t.Errorf("batch %d never passed on", start)
+		}
+	}
+}
+
+func TestGenerateEmbeddingsReturnsCompletedBatchesOnFailure(t *testing.T) {
+	// One batch at a time, so the batches before the failing one have completed
+	gen, _ := newIndexEmbedder(t, 3, 1, func(int) time.Duration { return 0 }, 7)
+
+	recorder := &progressRecorder{}
+	got, err := gen.GenerateEmbeddings(context.Background(), indexedTexts(12), recorder.report, nil)
+	if err == nil || !strings.Contains(err.Error(), "texts 6-8") {
+		t.Fatalf("error = %v, want one naming texts 6-8", err)
+	}
+
+	for i, embedding := range got {
+		if completed := i < 6; completed != (embedding != nil) {
+			t.Errorf("embedding %d = %v, want it returned only for completed batches", i, embedding)
+		}
+	}
+	if len(recorder.calls) != 2 || recorder.calls[1] != [2]int{6, 12} {
+		t.Errorf("progress calls = %v, want [[3 12] [6 12]]", recorder.calls)
+	}
+}
+
+func TestGenerateEmbeddingsStopsWhenCancelled(t *testing.T) {
+	gen, _ := newIndexEmbedder(t, 2, 1, func(int) time.Duration { return time.Hour }, -1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := gen.GenerateEmbeddings(ctx, indexedTexts(6), nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the context's deadline", err)
+	}
+}
+
+func TestPlaceholderGenerateEmbeddingsProgress(t *testing.T) {
+	recorder := &progressRecorder{}
+	got, err := NewPlaceholderEmbeddingGenerator(8).GenerateEmbeddings(context.Background(), indexedTexts(40), recorder.report, nil)
+	if err != nil {
+		t.Fatalf("GenerateEmbeddings: %v", err)
+	}
+	if len(got) != 40 {
+		t.Fatalf("got %d embeddings, want 40", len(got))
+	}
+
+	want := [][2]int{{16, 40}, {32, 40}, {40, 40}}
+	if fmt.Sprint(recorder.calls) != fmt.Sprint(want) {
+		t.Errorf("progress calls = %v, want %v", recorder.calls, want)
+	}
+
+	var batches [][2]int
+	if _, err := NewPlaceholderEmbeddingGenerator(8).GenerateEmbeddings(context.Background(), indexedTexts(40), nil, func(start int, batch [][]float32) {
+		batches = append(batches, [2]int{start, len(batch)})
+	}); err != nil {
+		t.Fatalf("GenerateEmbeddings: %v", err)
+	}
+	if want := [][2]int{{0, 16}, {16, 16}, {32, 8}}; fmt.Sprint(batches) != fmt.Sprint(want) {
+		t.Errorf("batches (start, size) = %v, want %v", batches, want)
+	}
+}
+
+func TestPlaceholderEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	gen := NewPlaceholderEmbeddingGenerator(64)
+	embed := func(text string) []float32 {
+		t.Helper()
+		embedding, err := gen.GenerateEmbedding(ctx, text)
+		if err != nil {
+			t.Fatalf("GenerateEmbedding(%q): %v", text, err)
+		}
+		return embedding
+	}
+
+	t.Run("reproducible", func(t *testing.T) {
+		first := embed("Go developer at Acme")
+		again, _ := NewPlaceholderEmbeddingGenerator(64).GenerateEmbedding(ctx, "Go developer at Acme")
+		if fmt.Sprint(first) != fmt.Sprint(again) {
+			t.Error("the same text got different embeddings")
+		}
+		// Case and punctuation don't change the words
+		if fmt.Sprint(embed("go developer, at ACME!")) != fmt.Sprint(first) {
+			t.Error("case or punctuation changed the embedding")
+		}
+
+		batch, err := gen.GenerateEmbeddings(ctx, []string{"Go developer at Acme"}, nil, nil)
+		if err != nil || fmt.Sprint(batch[0]) != fmt.Sprint(first) {
+			t.Errorf("GenerateEmbeddings = %v, %v; want the GenerateEmbedding vector", batch, err)
+		}
+	})
+
+	t.Run("stable across releases", func(t *testing.T) {
+		// Stored placeholder embeddings stay comparable with new ones
+		got, _ := NewPlaceholderEmbeddingGenerator(4).GenerateEmbedding(ctx, "Go developer")
+		want := []float32{0.2524569, -0.53212035, 0.77353835, -0.23399112}
+		for i := range want {
+			if diff := got[i] - want[i]; diff > 1e-6 || diff < -1e-6 {
+				t.Fatalf("embedding = %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("distinct texts", func(t *testing.T) {
+		texts := []string{"Go developer", "Rust developer", "Pastry chef", "?", "!"}
+		seen := make(map[string]string)
+		for _, text := range texts {
+			key := fmt.Sprint(embed(text))
+			if other, ok := seen[key]; ok {
+				t.Errorf("%q and %q got the same embedding", text, other)
+			}
+			seen[key] = text
+		}
+	})
+
+	t.Run("unit length", func(t *testing.T) {
+		for _, text := range []string{"Go", "Senior Go backend engineer with SQL", "?"} {
+			var norm float64
+			for _, v := range embed(text) {
+				norm += float64(v) * float64(v)
+			}
+			if norm < 0.9999 || norm > 1.0001 {
+				t.Errorf("squared norm of %q = %v, want 1", text, norm)
+			}
+		}
+	})
+
+	t.Run("shared words rank higher", func(t *testing.T) {
+		query := embed("Go backend developer")
+		close := cosineSimilarity(query, embed("Senior Go backend engineer"))
+		related := cosineSimilarity(query, embed("Backend engineer"))
+		unrelated := cosineSimilarity(query, embed("Pastry chef in Paris"))
+		if !(close > related && related > unrelated) {
+			t.Errorf("similarities = %v, %v, %v; want them decreasing with fewer shared words", close, related, unrelated)
+		}
+	})
+
+	t.Run("dimensions", func(t *testing.T) {
+		if got := len(embed("Go")); got != 64 {
+			t.Errorf("dimensions = %d, want 64", got)
+		}
+		defaulted, _ := NewPlaceholderEmbeddingGenerator(0).GenerateEmbedding(ctx, "Go")
+		if len(defaulted) != DefaultPlaceholderDimensions {
+			t.Errorf("default dimensions = %d, want %d", len(defaulted), DefaultPlaceholderDimensions)
+		}
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		if _, err := gen.GenerateEmbedding(ctx, ""); err == nil {
+			t.Error("expected an error for empty text")
+		}
+		if _, err := gen.GenerateEmbeddings(ctx, []string{"Go", ""}, nil, nil); err == nil {
+			t.Error("expected an error for an empty text in a batch")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// ExperienceMergeRules decides which experience entries MergeDuplicateExperience treats
+// as the same position. Companies and roles are compared ignoring case, punctuation,
+// company suffixes such as "Inc." and common abbreviations such as "Sr.".
+type ExperienceMergeRules struct {
+	Disabled bool // Keep every entry the LLM returned
+
+	// IgnoreRole merges entries at the same company whatever their role, e.g. to show
+	// promotions as a single position. The merged entry keeps the most recent role.
+	IgnoreRole bool
+
+	// MaxGapMonths is how far apart two dated entries may be and still be merged, so a
+	// return to the same role years later stays a separate position. Overlapping entries
+	// are always merged; a negative value merges regardless of the dates.
+	MaxGapMonths int
+}
+
+// DefaultExperienceMergeRules merges entries with the same company and role that overlap
+// or follow each other within a month
+var DefaultExperienceMergeRules = ExperienceMergeRules{MaxGapMonths: 1}
+
+// companySuffixes are the legal-form words dropped when comparing companies
+var companySuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true, "gmbh": true,
+	"plc": true, "ag": true, "sa": true, "bv": true,
+}
+
+// roleAbbreviations expands the abbreviations compared as their full words in roles
+var roleAbbreviations = map[string]string{
+	"sr": "senior", "jr": "junior", "mgr": "manager", "eng": "engineer",
+	"engr": "engineer", "dev": "developer", "assoc": "associate", "asst": "assistant",
+}
+
+// MergeDuplicateExperience merges experience entries describing the same position,
+// which the LLM sometimes returns twice when a resume lists a role more than once. A
+// merged entry spans the earliest start to the latest end and combines the distinct
+// descriptions. It takes the place of the first of its entries; Years is recomputed by
+// NormalizeExperience when the merged dates allow, otherwise the largest is kept.
+func MergeDuplicateExperience(entries []models.ExperienceEntry, rules ExperienceMergeRules, now time.Time) []models.ExperienceEntry {
+	if rules.Disabled || len(entries) < 2 {
+		return entries
+	}
+
+	merged := make([]models.ExperienceEntry, 0, len(entries))
+	for _, entry := range entries {
+		duplicate := -1
+		for i := range merged {
+			if sameExperience(merged[i], entry, rules, now) {
+				duplicate = i
+				break
+			}
+		}
+		if duplicate < 0 {
+			merged = append(merged, entry)
+			continue
+		}
+
+		log.Printf("Merging duplicate experience entry %q at %q", entry.Role, entry.Company)
+		merged[duplicate] = mergeExperience(merged[duplicate], entry, now)
+	}
+
+	return merged
+}
+
+// sameExperience reports whether two entries are the same position under rules
+func sameExperience(a, b models.ExperienceEntry, rules ExperienceMergeRules, now time.Time) bool {
+	company := normalizedCompany(a.Company)
+	if company == "" || company != normalizedCompany(b.Company) {
+		return false
+	}
+	if !rules.IgnoreRole && normalizedRole(a.Role) != normalizedRole(b.Role) {
+		return false
+	}
+	if rules.MaxGapMonths < 0 {
+		return true
+	}
+
+	first, last, aOK := experienceBounds(a, now)
+	bFirst, bLast, bOK := experienceBounds(b, now)
+	if !aOK || !bOK {
+		return true // Without dates on both there's nothing to tell them apart
+	}
+	gap := max(first, bFirst) - min(last, bLast) - 1
+	return gap <= rules.MaxGapMonths
+}
+
+// mergeExperience combines entry into kept
+func mergeExperience(kept, entry models.ExperienceEntry, now time.Time) models.ExperienceEntry {
+	keptFirst, keptLast, keptOK := experienceBounds(kept, now)
+	first, last, ok := experienceBounds(entry, now)
+
+	switch {
+	case keptOK && ok:
+		if first < keptFirst {
+			kept.StartDate = entry.StartDate
+		}
+		if last > keptLast || (last == keptLast && isPresent(entry.EndDate)) {
+			kept.EndDate = entry.EndDate
+			if entry.Role != "" {
+				kept.Role = entry.Role // The most recent role, when roles are ignored
+			}
+		}
+	case ok:
+		kept.StartDate, kept.EndDate = entry.StartDate, entry.EndDate
+	}
+
+	kept.Description = mergeDescriptions(kept.Description, entry.Description)
+	kept.Years = max(kept.Years, entry.Years)
+	return kept
+}
+
+// experienceBounds returns the first and last month of an entry with a parsed start
+// and end, ok is false otherwise
+func experienceBounds(entry models.ExperienceEntry, now time.Time) (first, last int, ok bool) {
+	if entry.StartDate == nil || entry.EndDate == nil {
+		return 0, 0, false
+	}
+	start, startOK := parseExperienceDate(*entry.StartDate)
+	end, endOK := parseExperienceDate(*entry.EndDate)
+	if !startOK || !endOK || start.present {
+		return 0, 0, false
+	}
+	return start.firstMonth(now), end.lastMonth(now), true
+}
+
+// isPresent reports whether an end date means the position is current
+func isPresent(date *string) bool {
+	if date == nil {
+		return false
+	}
+	parsed, ok := parseExperienceDate(*date)
+	return ok && parsed.present
+}
+
+// mergeDescriptions joins two descriptions, dropping one the other already contains
+func mergeDescriptions(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case b == "" || strings.Contains(strings.ToLower(a), strings.ToLower(b)):
+		return a
+	case a == "" || strings.Contains(strings.ToLower(b), strings.ToLower(a)):
+		return b
+	default:
+		return a + "\n" + b
+	}
+}
+
+// normalizedCompany lowercases a company name, dropping punctuation and legal-form suffixes
+func normalizedCompany(company string) string {
+	words := normalizedWords(company)
+	for len(words) > 1 && companySuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizedRole lowercases a role, dropping punctuation and expanding abbreviations
+func normalizedRole(role string) string {
+	words := normalizedWords(role)
+	for i, word := range words {
+		if full, ok := roleAbbreviations[word]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// normalizedWords splits text into lowercase words of letters and digits
+func normalizedWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
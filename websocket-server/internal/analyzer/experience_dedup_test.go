@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestMergeDuplicateExperience(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	entry := func(company, role, start, end, description string) models.ExperienceEntry {
+		e := models.ExperienceEntry{Company: company, Role: role, Description: description}
+		if start != "" {
+			e.StartDate = &start
+		}
+		if end != "" {
+			e.EndDate = &end
+		}
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		rules   ExperienceMergeRules
+		entries []models.ExperienceEntry
+		want    []models.ExperienceEntry
+	}{
+		{
+			name:  "exact duplicate",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2019-01", "2021-12", "Built APIs"),
+				entry("Acme", "Engineer", "2019-01", "2021-12", "Built APIs"),
+			},
+			want: []models.ExperienceEntry{entry("Acme", "Engineer", "2019-01", "2021-12", "Built APIs")},
+		},
+		{
+			name:  "near duplicate with adjacent dates",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				entry("Acme Inc.", "Sr. Software Eng", "2019-01", "2020-06", "Built APIs"),
+				entry("Globex", "Intern", "2018-06", "2018-09", ""),
+				entry("ACME", "Senior Software Engineer", "2020-07", "Present", "Led the payments team"),
+			},
+			want: []models.ExperienceEntry{
+				entry("Acme Inc.", "Senior Software Engineer", "2019-01", "Present", "Built APIs\nLed the payments team"),
+				entry("Globex", "Intern", "2018-06", "2018-09", ""),
+			},
+		},
+		{
+			name:  "overlapping dates and contained description",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2020-03", "2022-01", "APIs"),
+				entry("Acme", "Engineer", "2019-05", "2021-01", "Built APIs in Go"),
+			},
+			want: []models.ExperienceEntry{entry("Acme", "Engineer", "2019-05", "2022-01", "Built APIs in Go")},
+		},
+		{
+			name:  "return to the same role years later",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2015-01", "2016-12", ""),
+				entry("Acme", "Engineer", "2020-01", "Present", ""),
+			},
+			want: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2015-01", "2016-12", ""),
+				entry("Acme", "Engineer", "2020-01", "Present", ""),
+			},
+		},
+		{
+			name:  "gap ignored",
+			rules: ExperienceMergeRules{MaxGapMonths: -1},
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2015-01", "2016-12", ""),
+				entry("Acme", "Engineer", "2020-01", "Present", ""),
+			},
+			want: []models.ExperienceEntry{entry("Acme", "Engineer", "2015-01", "Present", "")},
+		},
+		{
+			name:  "promotion kept apart by default",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2018-01", "2020-12", ""),
+				entry("Acme", "Staff Engineer", "2021-01", "Present", ""),
+			},
+			want: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2018-01", "2020-12", ""),
+				entry("Acme", "Staff Engineer", "2021-01", "Present", ""),
+			},
+		},
+		{
+			name:  "promotion merged ignoring roles",
+			rules: ExperienceMergeRules{IgnoreRole: true, MaxGapMonths: 1},
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Staff Engineer", "2021-01", "Present", "Leads platform"),
+				entry("Acme", "Engineer", "2018-01", "2020-12", "Built APIs"),
+			},
+			want: []models.ExperienceEntry{entry("Acme", "Staff Engineer", "2018-01", "Present", "Leads platform\nBuilt APIs")},
+		},
+		{
+			name:  "undated duplicates",
+			rules: DefaultExperienceMergeRules,
+			entries: []models.ExperienceEntry{
+				{Company: "Acme", Role: "Engineer", Years: 2},
+				{Company: "Acme, LLC", Role: "engineer", Years: 3},
+			},
+			want: []models.ExperienceEntry{{Company: "Acme", Role: "Engineer", Years: 3}},
+		},
+		{
+			name:  "different companies",
+			rules: ExperienceMergeRules{IgnoreRole: true, MaxGapMonths: -1},
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "", "", ""),
+				entry("Acme Labs", "Engineer", "", "", ""),
+				entry("", "Engineer", "", "", ""),
+				entry("", "Engineer", "", "", ""),
+			},
+			want: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "", "", ""),
+				entry("Acme Labs", "Engineer", "", "", ""),
+				entry("", "Engineer", "", "", ""),
+				entry("", "Engineer", "", "", ""),
+			},
+		},
+		{
+			name:  "disabled",
+			rules: ExperienceMergeRules{Disabled: true},
+			entries: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2019-01", "2021-12", ""),
+				entry("Acme", "Engineer", "2019-01", "2021-12", ""),
+			},
+			want: []models.ExperienceEntry{
+				entry("Acme", "Engineer", "2019-01", "2021-12", ""),
+				entry("Acme", "Engineer", "2019-01", "2021-12", ""),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeDuplicateExperience(tt.entries, tt.rules, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged:\n%s\nwant:\n%s", formatEntries(got), formatEntries(tt.want))
+			}
+		})
+	}
+}
+
+// formatEntries renders entries with their dates for failure messages
+func formatEntries(entries []models.ExperienceEntry) string {
+	var s strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&s, "  %s / %s %v - %v (%v years): %q\n", e.Company, e.Role, derefOrNil(e.StartDate), derefOrNil(e.EndDate), e.Years, e.Description)
+	}
+	return s.String()
+}
@@ -10,7 +10,6 @@ import (
 
 	dslipakpdf "github.com/dslipak/pdf"
 	ledongpdf "github.com/ledongthuc/pdf"
-	"github.com/nguyenthenguyen/docx"
 	pdfcpuapi "github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/extractor"
@@ -334,19 +333,13 @@ func (e *DefaultTextExtractor) extractFromPDFWithPdfcpu(fileContent []byte) (str
 	return extractedText, nil
 }
 
-// extractFromDOCX extracts text from a DOCX file
+// extractFromDOCX extracts text from a DOCX file, keeping paragraphs and table cells apart
 func (e *DefaultTextExtractor) extractFromDOCX(fileContent []byte) (string, error) {
-	reader := bytes.NewReader(fileContent)
-
-	// Read DOCX from bytes
-	docxReader, err := docx.ReadDocxFromMemory(reader, int64(len(fileContent)))
+	extractedText, err := extractDOCXText(fileContent)
 	if err != nil {
-		return "", fmt.Errorf("failed to open DOCX: %w", err)
+		return "", err
 	}
 
-	// Extract text from document - the library provides a simple text extraction method
-	extractedText := docxReader.Editable().GetContent()
-
 	if len(strings.TrimSpace(extractedText)) == 0 {
 		return "", fmt.Errorf("no text content found in DOCX")
 	}
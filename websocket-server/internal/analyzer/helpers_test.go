@@ -0,0 +1,585 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// stubEmbedder embeds texts with fixed vectors, failing for texts it has none for
+type stubEmbedder struct {
+	vectors map[string][]float32
+
+	mu    sync.Mutex
+	calls int // Texts embedded
+}
+
+func (e *stubEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	vector, ok := e.vectors[text]
+	if !ok {
+		return nil, fmt.Errorf("no vector for %q", text)
+	}
+	e.calls++
+	return vector, nil
+}
+
+func (e *stubEmbedder) GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embedding, err := e.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return embeddings[:i], err
+		}
+		embeddings[i] = embedding
+		if onBatch != nil {
+			onBatch(i, embeddings[i:i+1])
+		}
+		if progress != nil {
+			progress(i+1, len(texts))
+		}
+	}
+	return embeddings, nil
+}
+
+// newTestVectorStore returns an in-memory vector store embedding queries with embedder
+func newTestVectorStore(t testing.TB, embedder EmbeddingGenerator) *InMemoryVectorStore {
+	t.Helper()
+
+	store, err := NewInMemoryVectorStore(embedder)
+	if err != nil {
+		t.Fatalf("NewInMemoryVectorStore: %v", err)
+	}
+	return store.(*InMemoryVectorStore)
+}
+
+// fakeUploadRepo keeps uploads and their file content in memory. Methods a test needs but
+// the fake doesn't implement panic through the embedded nil interface.
+type fakeUploadRepo struct {
+	repository.UploadRepository
+
+	mu      sync.Mutex
+	uploads map[int]*models.Upload
+	content map[int]string
+}
+
+// add stores an upload whose file content is text
+func (f *fakeUploadRepo) add(upload *models.Upload, text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.uploads == nil {
+		f.uploads = make(map[int]*models.Upload)
+		f.content = make(map[int]string)
+	}
+	upload.FileSize = len(text)
+	f.uploads[upload.ID] = upload
+	f.content[upload.ID] = text
+}
+
+// setJob records jobID, with status, as the latest analysis of an upload, as listing
+// uploads reports them
+func (f *fakeUploadRepo) setJob(uploadID int, jobID, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads[uploadID].JobID = &jobID
+	f.uploads[uploadID].JobStatus = &status
+}
+
+func (f *fakeUploadRepo) GetUploadByID(ctx context.Context, id int) (*models.Upload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	upload, ok := f.uploads[id]
+	if !ok {
+		return nil, fmt.Errorf("upload not found with ID: %d", id)
+	}
+	copied := *upload
+	return &copied, nil
+}
+
+func (f *fakeUploadRepo) GetUploadFileContent(ctx context.Context, id int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	text, ok := f.content[id]
+	if !ok {
+		return nil, fmt.Errorf("upload not found with ID: %d", id)
+	}
+	return []byte(text), nil
+}
+
+func (f *fakeUploadRepo) ListUploadsByUserID(ctx context.Context, userID, limit, offset int) ([]*models.Upload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var uploads []*models.Upload
+	for _, upload := range f.uploads {
+		if upload.UserID != nil && *upload.UserID == userID {
+			uploads = append(uploads, upload)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID < uploads[j].ID })
+	if offset >= len(uploads) {
+		return nil, nil
+	}
+	return uploads[offset:min(offset+limit, len(uploads))], nil
+}
+
+// ListUploadsFiltered lists the uploads matching the user, tenant, status and tag of filter,
+// newest (highest ID) first whatever the order
+func (f *fakeUploadRepo) ListUploadsFiltered(ctx context.Context, filter repository.UploadFilter, order repository.UploadSort, limit, offset int) ([]*models.Upload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var uploads []*models.Upload
+	for _, upload := range f.uploads {
+		switch {
+		case filter.UserID != nil && (upload.UserID == nil || *upload.UserID != *filter.UserID):
+		case filter.TenantID != nil && upload.TenantID != *filter.TenantID:
+		case filter.Status == models.UploadStatusNotAnalyzed && upload.JobID != nil:
+		case filter.Status != "" && filter.Status != models.UploadStatusNotAnalyzed &&
+			(upload.JobStatus == nil || *upload.JobStatus != filter.Status):
+		case filter.Tag != "" && !slices.Contains(upload.Tags, filter.Tag):
+		default:
+			copied := *upload
+			uploads = append(uploads, &copied)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID > uploads[j].ID })
+	if offset >= len(uploads) {
+		return nil, nil
+	}
+	return uploads[offset:min(offset+limit, len(uploads))], nil
+}
+
+// fakeAnalysisRepo keeps jobs and profiles in memory. Methods a test needs but the fake
+// doesn't implement panic through the embedded nil interface.
+type fakeAnalysisRepo struct {
+	repository.AnalysisRepository
+
+	mu       sync.Mutex
+	jobs     map[string]*models.AnalysisJob
+	profiles map[string]*models.UserProfile // By job ID
+	basics   map[string]*models.ProfileBasics
+	steps    map[string][]string // Statuses each job went through, in order
+}
+
+func newFakeAnalysisRepo() *fakeAnalysisRepo {
+	return &fakeAnalysisRepo{
+		jobs:     make(map[string]*models.AnalysisJob),
+		profiles: make(map[string]*models.UserProfile),
+		basics:   make(map[string]*models.ProfileBasics),
+		steps:    make(map[string][]string),
+	}
+}
+
+func (f *fakeAnalysisRepo) CreateJob(ctx context.Context, job *models.AnalysisJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *job
+	f.jobs[job.JobID] = &copied
+	return nil
+}
+
+func (f *fakeAnalysisRepo) GetJobByID(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (f *fakeAnalysisRepo) GetJobsByUserID(ctx context.Context, userID int) ([]*models.AnalysisJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var jobs []*models.AnalysisJob
+	for _, job := range f.jobs {
+		if job.UserID != nil && *job.UserID == userID {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	return jobs, nil
+}
+
+// update applies change to a stored job, failing once ctx is done like a database call
+func (f *fakeAnalysisRepo) update(ctx context.Context, jobID string, change func(job *models.AnalysisJob)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+	change(job)
+	return nil
+}
+
+func (f *fakeAnalysisRepo) UpdateJobStatus(ctx context.Context, jobID string, status string, progress int, currentStep string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) {
+		job.Status, job.Progress, job.CurrentStep = status, progress, currentStep
+		f.steps[jobID] = append(f.steps[jobID], status)
+	})
+}
+
+func (f *fakeAnalysisRepo) UpdateExtractedText(ctx context.Context, jobID string, extractedText string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) { job.ExtractedText = &extractedText })
+}
+
+func (f *fakeAnalysisRepo) UpdateJobError(ctx context.Context, jobID string, errorCode string, errorMessage string) error {
+	return f.update(context.WithoutCancel(ctx), jobID, func(job *models.AnalysisJob) {
+		job.Status, job.ErrorCode, job.ErrorMessage = "failed", &errorCode, &errorMessage
+	})
+}
+
+func (f *fakeAnalysisRepo) FlagJobForReview(ctx context.Context, jobID string, reason string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) { job.Status, job.ErrorMessage = "needs_review", &reason })
+}
+
+func (f *fakeAnalysisRepo) DeadLetterJob(ctx context.Context, jobID string, reason string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) { job.Status, job.ErrorMessage = "dead_lettered", &reason })
+}
+
+func (f *fakeAnalysisRepo) ResetJobForRetry(ctx context.Context, jobID string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) {
+		job.Status, job.Progress, job.ErrorMessage, job.ErrorCode = "queued", 0, nil, nil
+		job.RetryCount++
+	})
+}
+
+func (f *fakeAnalysisRepo) CompleteJob(ctx context.Context, jobID string) error {
+	return f.update(ctx, jobID, func(job *models.AnalysisJob) { job.Status, job.Progress = "completed", 100 })
+}
+
+func (f *fakeAnalysisRepo) UpdateJobBasics(ctx context.Context, jobID string, basics *models.ProfileBasics) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.basics[jobID] = basics
+	return nil
+}
+
+func (f *fakeAnalysisRepo) GetJobBasics(ctx context.Context, jobID string) (*models.ProfileBasics, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.basics[jobID], nil
+}
+
+func (f *fakeAnalysisRepo) SaveProfile(ctx context.Context, profile *models.UserProfile) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *profile
+	if existing, ok := f.profiles[profile.JobID]; ok {
+		copied.ID = existing.ID
+	} else {
+		copied.ID = len(f.profiles) + 1
+	}
+	f.profiles[profile.JobID] = &copied
+	return nil
+}
+
+func (f *fakeAnalysisRepo) UpdateProfile(ctx context.Context, profile *models.UserProfile) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.profiles[profile.JobID]; !ok {
+		return fmt.Errorf("profile not found for job %s", profile.JobID)
+	}
+	copied := *profile
+	f.profiles[profile.JobID] = &copied
+	return nil
+}
+
+// PatchProfile sets the fields the patch sets at confidence 1, like the Postgres
+// repository's UPDATE
+func (f *fakeAnalysisRepo) PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.profiles[jobID]
+	if !ok {
+		return fmt.Errorf("profile not found for job: %s", jobID)
+	}
+
+	profile := *stored
+	setIf(&profile.Name, patch.Name)
+	setIf(&profile.Email, patch.Email)
+	setIf(&profile.Phone, patch.Phone)
+	setIf(&profile.LinkedInURL, patch.LinkedInURL)
+	setIf(&profile.Age, patch.Age)
+	setIf(&profile.Race, patch.Race)
+	setIf(&profile.Location, patch.Location)
+	setIf(&profile.TotalWorkYears, patch.TotalWorkYears)
+	setIf(&profile.Summary, patch.Summary)
+	if patch.Skills != nil {
+		profile.Skills = *patch.Skills
+	}
+	if patch.Experience != nil {
+		profile.Experience = *patch.Experience
+	}
+	if patch.Education != nil {
+		profile.Education = *patch.Education
+	}
+	if patch.JobRecommendations != nil {
+		profile.JobRecommendations = *patch.JobRecommendations
+	}
+	if patch.Strengths != nil {
+		profile.Strengths = *patch.Strengths
+	}
+	if patch.Weaknesses != nil {
+		profile.Weaknesses = *patch.Weaknesses
+	}
+
+	profile.FieldConfidence = maps.Clone(stored.FieldConfidence)
+	if profile.FieldConfidence == nil {
+		profile.FieldConfidence = make(map[string]*float64)
+	}
+	for _, field := range patch.Fields() {
+		one := 1.0
+		profile.FieldConfidence[field] = &one
+	}
+	f.profiles[jobID] = &profile
+	return nil
+}
+
+// setIf sets *field to value when value is set
+func setIf[T any](field **T, value *T) {
+	if value != nil {
+		*field = value
+	}
+}
+
+func (f *fakeAnalysisRepo) GetProfileByJobID(ctx context.Context, jobID string) (*models.UserProfile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	profile, ok := f.profiles[jobID]
+	if !ok {
+		return nil, fmt.Errorf("profile not found for job %s", jobID)
+	}
+	copied := *profile
+	return &copied, nil
+}
+
+// GetProfileByUploadID returns the upload's latest (highest ID) profile
+func (f *fakeAnalysisRepo) GetProfileByUploadID(ctx context.Context, uploadID int) (*models.UserProfile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var latest *models.UserProfile
+	for _, profile := range f.profiles {
+		if profile.UploadID == uploadID && (latest == nil || profile.ID > latest.ID) {
+			latest = profile
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("profile not found for upload %d", uploadID)
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+func (f *fakeAnalysisRepo) GetProfilesByJobIDs(ctx context.Context, jobIDs []string) (map[string]*models.UserProfile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	profiles := make(map[string]*models.UserProfile)
+	for _, jobID := range jobIDs {
+		if profile, ok := f.profiles[jobID]; ok {
+			copied := *profile
+			profiles[jobID] = &copied
+		}
+	}
+	return profiles, nil
+}
+
+// job returns a copy of a stored job, failing the test if there is none
+func (f *fakeAnalysisRepo) job(t testing.TB, jobID string) *models.AnalysisJob {
+	t.Helper()
+	job, err := f.GetJobByID(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// textExtractor "extracts" file content as it is
+type textExtractor struct{}
+
+func (textExtractor) ExtractText(ctx context.Context, fileContent []byte, mimeType string) (string, error) {
+	return string(fileContent), nil
+}
+
+// keywordLLM analyzes resumes by listing the known skills their text mentions, recording
+// the requests it was sent. It fails every request while err is set.
+type keywordLLM struct {
+	skills []string // Known skills, matched case-sensitively
+
+	mu       sync.Mutex
+	err      error
+	requests []AnalysisRequest
+}
+
+func (l *keywordLLM) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
+	l.mu.Lock()
+	l.requests = append(l.requests, *request)
+	err := l.err
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	found := []string{}
+	for _, skill := range l.skills {
+		if strings.Contains(request.ResumeText, skill) {
+			found = append(found, skill)
+		}
+	}
+	name := "Ada"
+	return &AnalysisResponse{Name: &name, Skills: map[string][]string{"technical": found}}, nil
+}
+
+func (l *keywordLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	return "", fmt.Errorf("GenerateFromPrompt is not supported")
+}
+
+// calls returns the requests sent so far
+func (l *keywordLLM) calls() []AnalysisRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AnalysisRequest(nil), l.requests...)
+}
+
+// scriptedModel is an llms.Model that answers each request with respond and records the
+// prompts it was sent
+type scriptedModel struct {
+	respond func(prompt string, options llms.CallOptions) (*llms.ContentResponse, error)
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var prompt strings.Builder
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				prompt.WriteString(text.Text)
+			}
+		}
+	}
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	m.mu.Lock()
+	m.prompts = append(m.prompts, prompt.String())
+	m.mu.Unlock()
+	return m.respond(prompt.String(), opts)
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// textReply is a model response answering with text
+func textReply(text string) *llms.ContentResponse {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: text}}}
+}
+
+// toolReply is a model response calling the analysis tool with arguments
+func toolReply(arguments string) *llms.ContentResponse {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		ToolCalls: []llms.ToolCall{{Type: "function", FunctionCall: &llms.FunctionCall{Name: analysisToolName, Arguments: arguments}}},
+	}}}
+}
+
+// testAnalyzer is a DefaultResumeAnalyzer over in-memory fakes: uploads are plain text,
+// chunks are embedded with deterministic placeholder embeddings into an in-memory vector
+// store and analyzed by a keywordLLM
+type testAnalyzer struct {
+	*DefaultResumeAnalyzer
+
+	uploads  *fakeUploadRepo
+	repo     *fakeAnalysisRepo
+	store    *InMemoryVectorStore
+	embedder EmbeddingGenerator
+	llm      *keywordLLM
+}
+
+// newTestAnalyzer creates a test analyzer; configure, if not nil, adjusts its config
+func newTestAnalyzer(t testing.TB, configure func(config *Config)) *testAnalyzer {
+	t.Helper()
+
+	embedder := NewPlaceholderEmbeddingGenerator(16)
+	return newTestAnalyzerWithEmbedder(t, embedder, configure)
+}
+
+// newTestAnalyzerWithEmbedder creates a test analyzer embedding chunks with embedder
+func newTestAnalyzerWithEmbedder(t testing.TB, embedder EmbeddingGenerator, configure func(config *Config)) *testAnalyzer {
+	t.Helper()
+
+	config := &Config{
+		ChunkSize:         200,
+		ChunkOverlap:      0,
+		MaxConcurrentJobs: 2,
+		MinTextLength:     intPtr(0), // Test documents are short
+		MaxRetries:        intPtr(DefaultMaxRetries),
+	}
+	if configure != nil {
+		configure(config)
+	}
+
+	ta := &testAnalyzer{
+		uploads:  &fakeUploadRepo{},
+		repo:     newFakeAnalysisRepo(),
+		store:    newTestVectorStore(t, embedder),
+		embedder: embedder,
+		llm:      &keywordLLM{skills: []string{"Go", "SQL", "Kubernetes", "Terraform", "Rust"}},
+	}
+	ta.DefaultResumeAnalyzer = NewResumeAnalyzer(ta.uploads, ta.repo, textExtractor{}, NewTextChunker(), embedder, ta.store, ta.llm, config).(*DefaultResumeAnalyzer)
+	return ta
+}
+
+// analyze runs an analysis of the uploads to the end, failing the test if it can't be started
+func (ta *testAnalyzer) analyze(t testing.TB, userID *int, uploadIDs ...int) *models.AnalysisJob {
+	t.Helper()
+
+	jobID, finished, err := ta.AnalyzeSync(context.Background(), uploadIDs, userID, 10*time.Second)
+	if err != nil {
+		t.Fatalf("AnalyzeSync: %v", err)
+	}
+	if !finished {
+		t.Fatalf("job %s did not finish", jobID)
+	}
+	return ta.repo.job(t, jobID)
+}
+
+// waitForStatus waits for a job to reach a status, failing the test after a few seconds
+func (ta *testAnalyzer) waitForStatus(t testing.TB, jobID string, status string) *models.AnalysisJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job := ta.repo.job(t, jobID)
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s has status %q, want %q", jobID, job.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
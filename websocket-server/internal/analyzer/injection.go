@@ -0,0 +1,38 @@
+package analyzer
+
+import "regexp"
+
+// injectionPlaceholder replaces instruction-like passages removed from untrusted text
+const injectionPlaceholder = "[instruction-like text removed]"
+
+// injectionPatterns match phrasing and chat markup commonly used to smuggle instructions
+// to the model inside a document. They are kept narrow so ordinary resume wording,
+// such as "wrote system instructions for new hires", is left alone.
+var injectionPatterns = []*regexp.Regexp{
+	// "Ignore all previous instructions", "disregard the above rules", ...
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+|these\s+|my\s+)?(previous|prior|above|earlier|preceding|system|original)\s+(instructions?|prompts?|directions?|rules|context)\b`),
+	// "You are now a ...", "from now on you will ..."
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|in|the)\b`),
+	regexp.MustCompile(`(?i)\bfrom\s+now\s+on,?\s+you\s+(will|must|should)\b`),
+	// "New instructions:", "System prompt:"
+	regexp.MustCompile(`(?i)\b(new|updated|real)\s+(system\s+)?instructions?\s*:`),
+	regexp.MustCompile(`(?i)\bsystem\s+prompt\s*:`),
+	// Chat template control tokens: <|im_start|>, [INST], <<SYS>>
+	regexp.MustCompile(`<\|[A-Za-z_]+\|>`),
+	regexp.MustCompile(`\[/?INST\]`),
+	regexp.MustCompile(`<</?SYS>>`),
+}
+
+// neutralizeInjection replaces instruction-like passages in untrusted text with a placeholder
+// and reports how many were replaced. The rest of the text is returned unchanged, so the
+// surrounding content can still be analyzed.
+func neutralizeInjection(text string) (string, int) {
+	count := 0
+	for _, pattern := range injectionPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return injectionPlaceholder
+		})
+	}
+	return text, count
+}
@@ -5,21 +5,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/your-org/websocket-server/internal/prompts"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
+// LLMConfig holds the optional features of ExternalLLMClient
+type LLMConfig struct {
+	StructuredOutput bool // Request analyses as a forced tool call; for providers with function calling
+}
+
 // ExternalLLMClient implements LLMClient interface using OpenAI via LangChain
 type ExternalLLMClient struct {
-	llm   llms.Model
-	model string
+	llm              llms.Model
+	model            string
+	structuredOutput bool
 }
 
-// NewExternalLLMClient creates a new OpenAI LLM client using LangChain
-func NewExternalLLMClient(apiKey, apiURL, model string) (LLMClient, error) {
+// NewExternalLLMClient creates a new OpenAI LLM client using LangChain. A nil config
+// leaves the optional features off.
+func NewExternalLLMClient(apiKey, apiURL, model string, config *LLMConfig) (LLMClient, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
@@ -44,16 +55,33 @@ func NewExternalLLMClient(apiKey, apiURL, model string) (LLMClient, error) {
 		return nil, fmt.Errorf("failed to create OpenAI client: %w", err)
 	}
 
-	return &ExternalLLMClient{
+	client := &ExternalLLMClient{
 		llm:   llm,
 		model: model,
-	}, nil
+	}
+	if config != nil {
+		client.structuredOutput = config.StructuredOutput
+	}
+	return client, nil
 }
 
 // Analyze sends resume text and retrieved context to the LLM for analysis
 func (l *ExternalLLMClient) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
 	// Build the prompt
-	prompt := buildAnalysisPrompt(request)
+	prompt, err := buildAnalysisPrompt(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer structured output when enabled; a provider rejecting tool calling gets the
+	// prompt as text, any other failure fails the analysis
+	if l.structuredOutput {
+		analysisResponse, err := l.analyzeStructured(ctx, prompt, request.Pass)
+		if err == nil || !isUnsupportedToolsError(err) {
+			return analysisResponse, err
+		}
+		log.Printf("Provider doesn't support structured analysis output, falling back to parsing text: %v", err)
+	}
 
 	log.Printf("Calling OpenAI LLM for resume analysis...")
 
@@ -88,7 +116,29 @@ func (l *ExternalLLMClient) GenerateFromPrompt(ctx context.Context, prompt strin
 	return response, nil
 }
 
-// parseAnalysisResponse parses the JSON response from the LLM
+// GenerateFromPromptStream sends a raw prompt to the LLM, passing each chunk of the
+// response to onChunk as it is streamed back
+func (l *ExternalLLMClient) GenerateFromPromptStream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error) {
+	log.Printf("Calling OpenAI LLM with custom prompt (streaming)...")
+
+	response, err := llms.GenerateFromSinglePrompt(ctx, l.llm, prompt,
+		llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			return onChunk(string(chunk))
+		}),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate LLM response: %w", err)
+	}
+
+	log.Printf("Received streamed LLM response (%d chars)", len(response))
+	return response, nil
+}
+
+// parseAnalysisResponse parses the JSON response from the LLM.
+// Parsing is lenient: missing or null fields are left empty, common type mismatches
+// (e.g. numbers returned as strings) are coerced, and malformed entries are skipped.
+// It only fails when the response does not contain a JSON object at all. Confidences
+// missing from the response, or out of range, are nil.
 func parseAnalysisResponse(jsonStr string) (*AnalysisResponse, error) {
 	// Clean the response - sometimes LLMs wrap JSON in markdown code blocks
 	jsonStr = strings.TrimSpace(jsonStr)
@@ -97,86 +147,303 @@ func parseAnalysisResponse(jsonStr string) (*AnalysisResponse, error) {
 	jsonStr = strings.TrimSuffix(jsonStr, "```")
 	jsonStr = strings.TrimSpace(jsonStr)
 
-	// Parse JSON
-	var result struct {
-		Name           *string                  `json:"name"`
-		Email          *string                  `json:"email"`
-		Phone          *string                  `json:"phone"`
-		LinkedInURL    *string                  `json:"linkedin_url"`
-		Age            *int                     `json:"age"`
-		Race           *string                  `json:"race"`
-		Location       *string                  `json:"location"`
-		TotalWorkYears *float64                 `json:"total_work_years"`
-		Skills         map[string][]string      `json:"skills"`
-		Experience     []models.ExperienceEntry `json:"experience"`
-		Education      []models.EducationEntry  `json:"education"`
-		Summary        *string                  `json:"summary"`
-		JobRecommendations []string             `json:"job_recommendations"`
-		Strengths      []string                 `json:"strengths"`
-		Weaknesses     []string                 `json:"weaknesses"`
-	}
-
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+	// Drop any prose the LLM added around the JSON object
+	if start, end := strings.Index(jsonStr, "{"), strings.LastIndex(jsonStr, "}"); start >= 0 && end > start {
+		jsonStr = jsonStr[start : end+1]
+	}
+
+	// Parse into raw fields so one bad field doesn't fail the whole response
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(jsonStr), &fields); err != nil {
 		// Log the raw response for debugging
 		log.Printf("Failed to parse JSON. Raw response: %s", jsonStr[:min(500, len(jsonStr))])
 		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
+	return analysisFromFields(fields), nil
+}
+
+// analysisFromFields decodes the fields of an analysis JSON object leniently (see
+// parseAnalysisResponse)
+func analysisFromFields(fields map[string]json.RawMessage) *AnalysisResponse {
 	return &AnalysisResponse{
-		Name:               result.Name,
-		Email:              result.Email,
-		Phone:              result.Phone,
-		LinkedInURL:        result.LinkedInURL,
-		Age:                result.Age,
-		Race:               result.Race,
-		Location:           result.Location,
-		TotalWorkYears:     result.TotalWorkYears,
-		Skills:             result.Skills,
-		Experience:         result.Experience,
-		Education:          result.Education,
-		Summary:            result.Summary,
-		JobRecommendations: result.JobRecommendations,
-		Strengths:          result.Strengths,
-		Weaknesses:         result.Weaknesses,
-	}, nil
+		Name:               lenientString(fields["name"]),
+		Email:              lenientString(fields["email"]),
+		Phone:              lenientString(fields["phone"]),
+		LinkedInURL:        lenientString(fields["linkedin_url"]),
+		Age:                lenientInt(fields["age"]),
+		Race:               lenientString(fields["race"]),
+		Location:           lenientString(fields["location"]),
+		TotalWorkYears:     lenientFloat(fields["total_work_years"]),
+		Skills:             lenientSkills(fields["skills"]),
+		Experience:         lenientExperience(fields["experience"]),
+		Education:          lenientEducation(fields["education"]),
+		Summary:            lenientString(fields["summary"]),
+		JobRecommendations: lenientStringSlice(fields["job_recommendations"]),
+		Strengths:          lenientStringSlice(fields["strengths"]),
+		Weaknesses:         lenientStringSlice(fields["weaknesses"]),
+		FieldConfidence:    lenientConfidence(fields["confidence"]),
+	}
+}
+
+// leadingNumber matches the first number in strings like "5.5", "5+ years" or "~3"
+var leadingNumber = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// lenientString decodes a string field, accepting numbers and treating empty or "null" as missing
+func lenientString(raw json.RawMessage) *string {
+	var value interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &value) != nil {
+		return nil
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil
+	}
+
+	if s == "" || strings.EqualFold(s, "null") || strings.EqualFold(s, "n/a") {
+		return nil
+	}
+	return &s
+}
+
+// lenientFloat decodes a numeric field, accepting numbers embedded in strings
+func lenientFloat(raw json.RawMessage) *float64 {
+	var value interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &value) != nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return &v
+	case string:
+		match := leadingNumber.FindString(v)
+		if match == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(match, 64)
+		if err != nil {
+			return nil
+		}
+		return &f
+	default:
+		return nil
+	}
+}
+
+// lenientInt decodes an integer field, rounding fractional values
+func lenientInt(raw json.RawMessage) *int {
+	f := lenientFloat(raw)
+	if f == nil {
+		return nil
+	}
+	i := int(math.Round(*f))
+	return &i
+}
+
+// lenientStringSlice decodes a list of strings, accepting a single string and skipping
+// empty or non-scalar items. Missing fields yield an empty slice.
+func lenientStringSlice(raw json.RawMessage) []string {
+	result := []string{}
+
+	var items []json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &items) != nil {
+		if s := lenientString(raw); s != nil {
+			result = append(result, *s)
+		}
+		return result
+	}
+
+	for _, item := range items {
+		if s := lenientString(item); s != nil {
+			result = append(result, *s)
+		}
+	}
+	return result
+}
+
+// lenientSkills decodes the skills map. A flat list is treated as technical skills.
+// Missing fields yield an empty map.
+func lenientSkills(raw json.RawMessage) map[string][]string {
+	skills := map[string][]string{}
+
+	var categories map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &categories) != nil {
+		if list := lenientStringSlice(raw); len(list) > 0 {
+			skills["technical"] = list
+		}
+		return skills
+	}
+
+	for category, items := range categories {
+		skills[category] = lenientStringSlice(items)
+	}
+	return skills
 }
 
-// buildAnalysisPrompt constructs the prompt for the LLM
-func buildAnalysisPrompt(request *AnalysisRequest) string {
-	var prompt strings.Builder
+// lenientExperience decodes experience entries, skipping entries that aren't objects
+func lenientExperience(raw json.RawMessage) []models.ExperienceEntry {
+	entries := []models.ExperienceEntry{}
 
-	prompt.WriteString("You are a professional resume analyzer. Analyze the following resume and extract structured information.\n\n")
+	var items []json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &items) != nil {
+		return entries
+	}
+
+	for _, rawItem := range items {
+		var item map[string]json.RawMessage
+		if json.Unmarshal(rawItem, &item) != nil || item == nil {
+			continue
+		}
+		entry := models.ExperienceEntry{
+			Company:   valueOrEmpty(lenientString(item["company"])),
+			Role:      valueOrEmpty(lenientString(item["role"])),
+			StartDate: lenientString(item["start_date"]),
+			EndDate:   lenientString(item["end_date"]),
+		}
+		if years := lenientFloat(item["years"]); years != nil {
+			entry.Years = *years
+		}
+		entry.Description = valueOrEmpty(lenientString(item["description"]))
+		entries = append(entries, entry)
+	}
+	return entries
+}
 
-	prompt.WriteString("Resume Text:\n")
-	prompt.WriteString(request.ResumeText)
-	prompt.WriteString("\n\n")
+// lenientEducation decodes education entries, skipping entries that aren't objects
+func lenientEducation(raw json.RawMessage) []models.EducationEntry {
+	entries := []models.EducationEntry{}
 
-	if len(request.RetrievedChunks) > 0 {
-		prompt.WriteString("Relevant Context from Vector Search:\n")
-		for i, chunk := range request.RetrievedChunks {
-			prompt.WriteString(fmt.Sprintf("Chunk %d: %s\n", i+1, chunk))
+	var items []json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &items) != nil {
+		return entries
+	}
+
+	for _, rawItem := range items {
+		var item map[string]json.RawMessage
+		if json.Unmarshal(rawItem, &item) != nil || item == nil {
+			continue
+		}
+		entries = append(entries, models.EducationEntry{
+			Degree:      valueOrEmpty(lenientString(item["degree"])),
+			Institution: valueOrEmpty(lenientString(item["institution"])),
+			Year:        lenientInt(item["year"]),
+		})
+	}
+	return entries
+}
+
+// lenientConfidence decodes the confidence object into a score per profile field.
+// Scores may be numbers or numeric strings; percentages above 1 (e.g. "85%") are
+// scaled to 0-1. Fields without a valid score are nil.
+func lenientConfidence(raw json.RawMessage) map[string]*float64 {
+	var scores map[string]json.RawMessage
+	if len(raw) > 0 && json.Unmarshal(raw, &scores) != nil {
+		scores = nil
+	}
+
+	confidence := make(map[string]*float64, len(models.ProfileFields))
+	for _, field := range models.ProfileFields {
+		score := lenientFloat(scores[field])
+		if score != nil && *score > 1 && *score <= 100 {
+			scaled := *score / 100
+			score = &scaled
+		}
+		if score != nil && (*score < 0 || *score > 1) {
+			score = nil
 		}
-		prompt.WriteString("\n")
+		confidence[field] = score
 	}
+	return confidence
+}
 
-	if request.LinkedInURL != nil && *request.LinkedInURL != "" {
-		prompt.WriteString(fmt.Sprintf("LinkedIn Profile: %s\n\n", *request.LinkedInURL))
+// valueOrEmpty dereferences s, returning "" for nil
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
 	}
+	return *s
+}
 
-	prompt.WriteString(`Please extract and structure the following information in JSON format:
-{
-  "name": "<full name or null>",
+// buildAnalysisPrompt renders the analysis prompt template for the request. The resume text,
+// retrieved chunks and LinkedIn URL come from the uploaded document, so instruction-like
+// passages are neutralized before the template fences them as untrusted data.
+func buildAnalysisPrompt(request *AnalysisRequest) (string, error) {
+	neutralized := 0
+	untrusted := func(text string) string {
+		text, n := neutralizeInjection(text)
+		neutralized += n
+		return text
+	}
+
+	data := prompts.AnalysisData{
+		ResumeText:      untrusted(request.ResumeText),
+		RetrievedChunks: make([]string, len(request.RetrievedChunks)),
+	}
+	for i, chunk := range request.RetrievedChunks {
+		data.RetrievedChunks[i] = untrusted(chunk)
+	}
+	if request.LinkedInURL != nil {
+		data.LinkedInURL = untrusted(*request.LinkedInURL)
+	}
+	if neutralized > 0 {
+		log.Printf("Warning: neutralized %d instruction-like passages in resume text before analysis", neutralized)
+	}
+
+	switch request.Pass {
+	case AnalysisPassBasics:
+		data.Fields = basicsPromptFields + ",\n" + confidencePromptField(basicsFieldNames)
+	case AnalysisPassDeep:
+		data.Fields = deepPromptFields + ",\n" + confidencePromptField(deepFieldNames)
+	default:
+		data.Fields = basicsPromptFields + ",\n" + deepPromptFields + ",\n" + confidencePromptField(models.ProfileFields)
+	}
+
+	return prompts.Render(prompts.Analysis, data)
+}
+
+// Fields extracted by each pass of a two-pass analysis
+var (
+	basicsFieldNames = []string{"name", "email", "phone", "linkedin_url", "location", "skills"}
+	deepFieldNames   = []string{"age", "race", "total_work_years", "experience", "education", "summary", "job_recommendations", "strengths", "weaknesses"}
+)
+
+// confidencePromptField is the part of the analysis JSON schema asking for a confidence per field
+func confidencePromptField(fields []string) string {
+	var b strings.Builder
+	b.WriteString(`  "confidence": {` + "\n")
+	for i, field := range fields {
+		fmt.Fprintf(&b, `    %q: <number from 0 to 1 or null>`, field)
+		if i < len(fields)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("  }")
+	return b.String()
+}
+
+// basicsPromptFields is the part of the analysis JSON schema extracted by the basics pass
+const basicsPromptFields = `  "name": "<full name or null>",
   "email": "<email address or null>",
   "phone": "<phone number or null>",
   "linkedin_url": "<LinkedIn profile URL or null>",
-  "age": <integer or null>,
-  "race": "<string or null>",
   "location": "<string or null>",
-  "total_work_years": <number or null>,
   "skills": {
     "technical": ["skill1", "skill2", ...],
     "soft": ["skill1", "skill2", ...]
-  },
+  }`
+
+// deepPromptFields is the part of the analysis JSON schema extracted by the deep pass
+const deepPromptFields = `  "age": <integer or null>,
+  "race": "<string or null>",
+  "total_work_years": <number or null>,
   "experience": [
     {
       "company": "Company Name",
@@ -197,22 +464,7 @@ func buildAnalysisPrompt(request *AnalysisRequest) string {
   "summary": "Executive summary of the candidate's profile",
   "job_recommendations": ["Recommended Role 1", "Recommended Role 2", ...],
   "strengths": ["Strength 1", "Strength 2", ...],
-  "weaknesses": ["Area for improvement 1", "Area for improvement 2", ...]
-}
-
-Important notes:
-- Extract ONLY information that is explicitly stated in the resume
-- Do NOT infer or guess age or race unless explicitly stated
-- For location: If location is explicitly stated, use it. If not but phone number is present, infer the country/region from the phone number's country code and area code (e.g., +1 619 = San Diego, CA, United States; +86 = China; +44 = United Kingdom)
-- For skills, extract ALL technical skills mentioned (programming languages, frameworks, tools, etc.)
-- Include exact company names, dates, and descriptions from the resume
-- Total work years should be calculated from all work experiences
-- Be accurate and comprehensive in your analysis
-- Job recommendations should be based on actual skills and experience from the resume
-`)
-
-	return prompt.String()
-}
+  "weaknesses": ["Area for improvement 1", "Area for improvement 2", ...]`
 
 // PlaceholderLLMClient is a placeholder implementation for testing
 type PlaceholderLLMClient struct{}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestParseAnalysisResponse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, got *AnalysisResponse)
+	}{
+		{
+			name:  "well formed",
+			input: `{"name":"Ada Lovelace","age":36,"total_work_years":12.5,"skills":{"technical":["Go","SQL"]},"strengths":["Focus"]}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantString(t, "name", got.Name, "Ada Lovelace")
+				wantInt(t, "age", got.Age, 36)
+				wantFloat(t, "total_work_years", got.TotalWorkYears, 12.5)
+				wantEqual(t, "skills", got.Skills, map[string][]string{"technical": {"Go", "SQL"}})
+				wantEqual(t, "strengths", got.Strengths, []string{"Focus"})
+			},
+		},
+		{
+			name:  "wrapped in a code block with prose",
+			input: "Here is the profile:\n```json\n{\"name\": \"Ada\"}\n```\nLet me know if you need more.",
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantString(t, "name", got.Name, "Ada")
+			},
+		},
+		{
+			name:  "numbers as strings",
+			input: `{"age":"36","total_work_years":"5+ years","education":[{"degree":"BSc","institution":"MIT","year":"2010"}]}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantInt(t, "age", got.Age, 36)
+				wantFloat(t, "total_work_years", got.TotalWorkYears, 5)
+				wantEqual(t, "education", got.Education, []models.EducationEntry{{Degree: "BSc", Institution: "MIT", Year: intPtr(2010)}})
+			},
+		},
+		{
+			name:  "nulls and placeholders",
+			input: `{"name":null,"email":"N/A","phone":"","location":"null","age":"unknown","skills":null,"experience":null}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				for field, value := range map[string]*string{"name": got.Name, "email": got.Email, "phone": got.Phone, "location": got.Location} {
+					if value != nil {
+						t.Errorf("%s = %q, want nil", field, *value)
+					}
+				}
+				if got.Age != nil {
+					t.Errorf("age = %d, want nil", *got.Age)
+				}
+				wantEqual(t, "skills", got.Skills, map[string][]string{})
+				wantEqual(t, "experience", got.Experience, []models.ExperienceEntry{})
+			},
+		},
+		{
+			name:  "missing fields default to empty",
+			input: `{"name":"Ada"}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantEqual(t, "skills", got.Skills, map[string][]string{})
+				wantEqual(t, "experience", got.Experience, []models.ExperienceEntry{})
+				wantEqual(t, "education", got.Education, []models.EducationEntry{})
+				wantEqual(t, "job_recommendations", got.JobRecommendations, []string{})
+				wantEqual(t, "strengths", got.Strengths, []string{})
+				wantEqual(t, "weaknesses", got.Weaknesses, []string{})
+			},
+		},
+		{
+			name:  "single values for lists",
+			input: `{"skills":["Go","Rust"],"strengths":"Communication","weaknesses":["", 3, {"x":1}, "Delegation"]}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantEqual(t, "skills", got.Skills, map[string][]string{"technical": {"Go", "Rust"}})
+				wantEqual(t, "strengths", got.Strengths, []string{"Communication"})
+				wantEqual(t, "weaknesses", got.Weaknesses, []string{"3", "Delegation"})
+			},
+		},
+		{
+			name:  "malformed experience entries skipped",
+			input: `{"experience":["Acme Corp, 2019-2021",{"company":"Acme","role":"Engineer","years":"2.5","start_date":"2019-01"},null]}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantEqual(t, "experience", got.Experience, []models.ExperienceEntry{
+					{Company: "Acme", Role: "Engineer", Years: 2.5, StartDate: strPtr("2019-01")},
+				})
+			},
+		},
+		{
+			name:  "confidence scaled and bounded",
+			input: `{"confidence":{"name":0.9,"email":"85%","phone":150,"skills":"high"}}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantFloat(t, "name confidence", got.FieldConfidence["name"], 0.9)
+				wantFloat(t, "email confidence", got.FieldConfidence["email"], 0.85)
+				for _, field := range []string{"phone", "skills", "summary"} {
+					if score := got.FieldConfidence[field]; score != nil {
+						t.Errorf("%s confidence = %v, want nil", field, *score)
+					}
+				}
+			},
+		},
+		{
+			name:  "confidence for every field",
+			input: `{"name":"Ada","location":"London","confidence":{"name":1,"location":"0.4","age":null}}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantFloat(t, "name confidence", got.FieldConfidence["name"], 1)
+				wantFloat(t, "location confidence", got.FieldConfidence["location"], 0.4)
+				wantNoConfidence(t, got, "age", "email")
+				if len(got.FieldConfidence) != len(models.ProfileFields) {
+					t.Errorf("confidence has %d fields, want %d", len(got.FieldConfidence), len(models.ProfileFields))
+				}
+			},
+		},
+		{
+			name:  "without confidence",
+			input: `{"name":"Ada"}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantString(t, "name", got.Name, "Ada")
+				wantNoConfidence(t, got, models.ProfileFields...)
+			},
+		},
+		{
+			name:  "confidence that isn't an object",
+			input: `{"name":"Ada","confidence":0.9}`,
+			check: func(t *testing.T, got *AnalysisResponse) {
+				wantString(t, "name", got.Name, "Ada")
+				wantNoConfidence(t, got, models.ProfileFields...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAnalysisResponse(tt.input)
+			if err != nil {
+				t.Fatalf("parseAnalysisResponse: %v", err)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestParseAnalysisResponseNotJSON(t *testing.T) {
+	for _, input := range []string{"", "I could not read this resume.", "[1, 2, 3]", "{name: Ada"} {
+		if got, err := parseAnalysisResponse(input); err == nil {
+			t.Errorf("parseAnalysisResponse(%q) = %+v, want an error", input, got)
+		}
+	}
+}
+
+// wantNoConfidence checks that the fields are reported without a confidence
+func wantNoConfidence(t *testing.T, got *AnalysisResponse, fields ...string) {
+	t.Helper()
+	for _, field := range fields {
+		score, ok := got.FieldConfidence[field]
+		if !ok || score != nil {
+			t.Errorf("%s confidence = %v (present %v), want nil", field, score, ok)
+		}
+	}
+}
+
+func wantString(t *testing.T, field string, got *string, want string) {
+	t.Helper()
+	if got == nil || *got != want {
+		t.Errorf("%s = %v, want %q", field, got, want)
+	}
+}
+
+func wantInt(t *testing.T, field string, got *int, want int) {
+	t.Helper()
+	if got == nil || *got != want {
+		t.Errorf("%s = %v, want %d", field, got, want)
+	}
+}
+
+func wantFloat(t *testing.T, field string, got *float64, want float64) {
+	t.Helper()
+	if got == nil || math.Abs(*got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func wantEqual(t *testing.T, field string, got, want any) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %#v, want %#v", field, got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// MaxOrgCandidates caps how many candidates ListOrgCandidates returns; larger
+// organizations have to narrow the export with a status or tag filter
+const MaxOrgCandidates = 5000
+
+// orgCandidatesPageSize is how many uploads ListOrgCandidates reads per query
+const orgCandidatesPageSize = 200
+
+// orgCandidateTopSkills is how many skills a candidate summary lists
+const orgCandidateTopSkills = 5
+
+// ErrTooManyCandidates is returned by ListOrgCandidates when more than MaxOrgCandidates
+// uploads match
+var ErrTooManyCandidates = errors.New("too many candidates")
+
+// ListOrgCandidates summarizes the uploads of a tenant, newest first, filtered by the
+// status of their analysis job and by tag like the upload list (empty matches any).
+// Profiles are fetched one page of uploads at a time.
+func (a *DefaultResumeAnalyzer) ListOrgCandidates(ctx context.Context, tenantID, status, tag string) ([]*models.CandidateSummary, error) {
+	var candidates []*models.CandidateSummary
+
+	for offset := 0; ; offset += orgCandidatesPageSize {
+		uploads, err := a.uploadRepo.ListUploadsFiltered(ctx, repository.UploadFilter{TenantID: &tenantID, Status: status, Tag: tag}, repository.UploadSort{}, orgCandidatesPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list uploads: %w", err)
+		}
+		if len(candidates)+len(uploads) > MaxOrgCandidates {
+			return nil, ErrTooManyCandidates
+		}
+
+		var jobIDs []string
+		for _, upload := range uploads {
+			if upload.JobID != nil && upload.JobStatus != nil && *upload.JobStatus == "completed" {
+				jobIDs = append(jobIDs, *upload.JobID)
+			}
+		}
+		profiles, err := a.analysisRepo.GetProfilesByJobIDs(ctx, jobIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get profiles: %w", err)
+		}
+
+		for _, upload := range uploads {
+			var profile *models.UserProfile
+			if upload.JobID != nil {
+				profile = profiles[*upload.JobID]
+			}
+			candidates = append(candidates, candidateSummary(upload, profile))
+		}
+
+		if len(uploads) < orgCandidatesPageSize {
+			return candidates, nil
+		}
+	}
+}
+
+// candidateSummary builds the summary of an upload; profile is nil if it has none
+func candidateSummary(upload *models.Upload, profile *models.UserProfile) *models.CandidateSummary {
+	summary := &models.CandidateSummary{
+		UploadID:         upload.ID,
+		FileName:         upload.FileName,
+		Tags:             upload.Tags,
+		JobID:            upload.JobID,
+		Status:           models.UploadStatusNotAnalyzed,
+		TopSkills:        []string{},
+		RecommendedRoles: []string{},
+	}
+	if summary.Tags == nil {
+		summary.Tags = []string{}
+	}
+	if upload.JobStatus != nil {
+		summary.Status = *upload.JobStatus
+	}
+	if profile == nil {
+		return summary
+	}
+
+	summary.Name = profile.Name
+	summary.TopSkills = topSkills(profile.Skills, orgCandidateTopSkills)
+	summary.TotalWorkYears = profile.TotalWorkYears
+	if profile.JobRecommendations != nil {
+		summary.RecommendedRoles = profile.JobRecommendations
+	}
+	analyzedAt := profile.UpdatedAt
+	summary.AnalyzedAt = &analyzedAt
+
+	return summary
+}
+
+// topSkills returns up to limit distinct skills, technical skills first and then the other
+// categories in sorted order, each in the order the analysis listed them
+func topSkills(skills map[string][]string, limit int) []string {
+	categories := []string{"technical"}
+	for _, category := range models.SkillCategories(skills) {
+		if category != "technical" {
+			categories = append(categories, category)
+		}
+	}
+
+	top := []string{}
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		for _, skill := range skills[category] {
+			skill = strings.TrimSpace(skill)
+			key := strings.ToLower(skill)
+			if skill == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			top = append(top, skill)
+			if len(top) == limit {
+				return top
+			}
+		}
+	}
+	return top
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestListOrgCandidates(t *testing.T) {
+	ctx := context.Background()
+	ta := newTestAnalyzer(t, nil)
+	analyzedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	years := 6.5
+
+	ta.uploads.add(&models.Upload{ID: 1, TenantID: "acme", FileName: "ada.pdf", Tags: []string{"backend"},
+		JobID: strPtr("job_1"), JobStatus: strPtr("completed")}, "")
+	ta.uploads.add(&models.Upload{ID: 2, TenantID: "acme", FileName: "bob.pdf", Tags: []string{"frontend"},
+		JobID: strPtr("job_2"), JobStatus: strPtr("failed")}, "")
+	ta.uploads.add(&models.Upload{ID: 3, TenantID: "acme", FileName: "new.pdf"}, "")
+	ta.uploads.add(&models.Upload{ID: 4, TenantID: "globex", FileName: "other.pdf", Tags: []string{"backend"}}, "")
+	if err := ta.repo.SaveProfile(ctx, &models.UserProfile{
+		JobID:              "job_1",
+		Name:               strPtr("Ada"),
+		TotalWorkYears:     &years,
+		Skills:             map[string][]string{"soft": {"Mentoring"}, "technical": {"Go", "go", "SQL"}, "cloud": {"AWS"}},
+		JobRecommendations: []string{"Staff Engineer"},
+		UpdatedAt:          analyzedAt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("summaries", func(t *testing.T) {
+		candidates, err := ta.ListOrgCandidates(ctx, "acme", "", "")
+		if err != nil {
+			t.Fatalf("ListOrgCandidates: %v", err)
+		}
+
+		want := []*models.CandidateSummary{
+			{UploadID: 3, FileName: "new.pdf", Tags: []string{}, Status: models.UploadStatusNotAnalyzed, TopSkills: []string{}, RecommendedRoles: []string{}},
+			{UploadID: 2, FileName: "bob.pdf", Tags: []string{"frontend"}, JobID: strPtr("job_2"), Status: "failed", TopSkills: []string{}, RecommendedRoles: []string{}},
+			{
+				UploadID: 1, FileName: "ada.pdf", Tags: []string{"backend"}, JobID: strPtr("job_1"), Status: "completed",
+				Name: strPtr("Ada"), TopSkills: []string{"Go", "SQL", "AWS", "Mentoring"}, TotalWorkYears: &years,
+				RecommendedRoles: []string{"Staff Engineer"}, AnalyzedAt: &analyzedAt,
+			},
+		}
+		if !reflect.DeepEqual(candidates, want) {
+			for i, c := range candidates {
+				t.Logf("candidate %d: %+v", i, c)
+			}
+			t.Errorf("candidates differ from %+v", want)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			tenantID, status, tag string
+			want                  []int
+		}{
+			{"acme", "completed", "", []int{1}},
+			{"acme", models.UploadStatusNotAnalyzed, "", []int{3}},
+			{"acme", "", "backend", []int{1}},
+			{"acme", "failed", "backend", nil},
+			{"globex", "", "", []int{4}},
+			{"", "", "", nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/%s/%s", tt.tenantID, tt.status, tt.tag), func(t *testing.T) {
+				candidates, err := ta.ListOrgCandidates(ctx, tt.tenantID, tt.status, tt.tag)
+				if err != nil {
+					t.Fatalf("ListOrgCandidates: %v", err)
+				}
+				var got []int
+				for _, c := range candidates {
+					got = append(got, c.UploadID)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("candidates = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestListOrgCandidatesPages(t *testing.T) {
+	ctx := context.Background()
+	ta := newTestAnalyzer(t, nil)
+	for id := 1; id <= orgCandidatesPageSize+1; id++ {
+		ta.uploads.add(&models.Upload{ID: id, TenantID: "acme"}, "")
+	}
+
+	candidates, err := ta.ListOrgCandidates(ctx, "acme", "", "")
+	if err != nil {
+		t.Fatalf("ListOrgCandidates: %v", err)
+	}
+	if len(candidates) != orgCandidatesPageSize+1 || candidates[0].UploadID != orgCandidatesPageSize+1 || candidates[len(candidates)-1].UploadID != 1 {
+		t.Errorf("%d candidates, want every upload across pages, newest first", len(candidates))
+	}
+
+	for id := orgCandidatesPageSize + 2; id <= MaxOrgCandidates+1; id++ {
+		ta.uploads.add(&models.Upload{ID: id, TenantID: "acme"}, "")
+	}
+	if _, err := ta.ListOrgCandidates(ctx, "acme", "", ""); !errors.Is(err, ErrTooManyCandidates) {
+		t.Errorf("error = %v, want ErrTooManyCandidates over %d candidates", err, MaxOrgCandidates)
+	}
+}
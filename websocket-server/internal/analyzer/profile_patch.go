@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// PatchProfile updates only the fields a patch sets on a completed job's profile and
+// returns the updated result. Patched experience entries are normalized like the LLM's,
+// but the total work years is only changed when the patch sets it.
+func (a *DefaultResumeAnalyzer) PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) (*models.AnalysisResult, error) {
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+
+	if job.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
+	}
+
+	if patch.Experience != nil {
+		experience := NormalizeExperience(*patch.Experience, a.clock.Now())
+		patch.Experience = &experience
+	}
+
+	if err := a.analysisRepo.PatchProfile(ctx, jobID, patch); err != nil {
+		return nil, fmt.Errorf("failed to patch profile: %w", err)
+	}
+
+	log.Printf("Patched profile fields of job %s: %s", jobID, strings.Join(patch.Fields(), ", "))
+
+	return a.GetResult(ctx, jobID)
+}
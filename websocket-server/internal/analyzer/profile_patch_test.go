@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestPatchProfile(t *testing.T) {
+	summary := "Backend engineer"
+	years, confidence := 4.0, 0.6
+	stored := func() *models.UserProfile {
+		return &models.UserProfile{
+			JobID:           "job_1",
+			Summary:         &summary,
+			TotalWorkYears:  &years,
+			Skills:          map[string][]string{"technical": {"Go", "SQL"}},
+			Experience:      []models.ExperienceEntry{{Company: "Acme", Role: "Engineer", StartDate: strPtr("2020-01"), EndDate: strPtr("Present"), Years: 4}},
+			Strengths:       []string{"Ownership"},
+			FieldConfidence: map[string]*float64{"summary": &confidence, "skills": &confidence},
+		}
+	}
+	setup := func(t *testing.T, status string) *testAnalyzer {
+		fake := clock.NewFake(time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC))
+		ta := newTestAnalyzer(t, func(config *Config) { config.Clock = fake })
+		ta.repo.jobs["job_1"] = &models.AnalysisJob{JobID: "job_1", Status: status}
+		ta.repo.profiles["job_1"] = stored()
+		return ta
+	}
+
+	t.Run("summary only", func(t *testing.T) {
+		ta := setup(t, "completed")
+		patched := "Staff engineer leading payments"
+
+		result, err := ta.PatchProfile(context.Background(), "job_1", &models.ProfilePatch{Summary: &patched})
+		if err != nil {
+			t.Fatalf("PatchProfile: %v", err)
+		}
+
+		want := stored()
+		wantString(t, "summary", result.Summary, patched)
+		wantEqual(t, "skills", result.Skills, want.Skills)
+		wantEqual(t, "experience", result.Experience, want.Experience)
+		wantEqual(t, "strengths", result.Strengths, want.Strengths)
+		wantFloat(t, "total_work_years", result.TotalWorkYears, 4)
+		if got := result.FieldConfidence["summary"]; got == nil || *got != 1 {
+			t.Errorf("summary confidence = %v, want 1", derefOrNil(got))
+		}
+		if got := result.FieldConfidence["skills"]; got == nil || *got != confidence {
+			t.Errorf("skills confidence = %v, want %v", derefOrNil(got), confidence)
+		}
+	})
+
+	t.Run("experience normalized", func(t *testing.T) {
+		ta := setup(t, "completed")
+		experience := []models.ExperienceEntry{{Company: "Globex", Role: "Lead", StartDate: strPtr("2022"), EndDate: strPtr("current")}}
+
+		result, err := ta.PatchProfile(context.Background(), "job_1", &models.ProfilePatch{Experience: &experience})
+		if err != nil {
+			t.Fatalf("PatchProfile: %v", err)
+		}
+		if len(result.Experience) != 1 || derefOrNil(result.Experience[0].EndDate) != "Present" || result.Experience[0].Years == 0 {
+			t.Errorf("experience = %+v, want the entry normalized", result.Experience)
+		}
+		// The total is only changed when the patch sets it
+		wantFloat(t, "total_work_years", result.TotalWorkYears, 4)
+		wantString(t, "summary", result.Summary, summary)
+	})
+
+	t.Run("job not completed", func(t *testing.T) {
+		ta := setup(t, "processing")
+		_, err := ta.PatchProfile(context.Background(), "job_1", &models.ProfilePatch{Summary: &summary})
+		if !errors.Is(err, ErrJobNotCompleted) {
+			t.Errorf("error = %v, want ErrJobNotCompleted", err)
+		}
+		if !reflect.DeepEqual(ta.repo.profiles["job_1"], stored()) {
+			t.Error("profile of an unfinished job was changed")
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		ta := setup(t, "completed")
+		if _, err := ta.PatchProfile(context.Background(), "job_9", &models.ProfilePatch{Summary: &summary}); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Actions taken when extracted text scores below the quality threshold
+const (
+	QualityActionFail = "fail" // Fail the job with an explanatory error
+	QualityActionFlag = "flag" // Stop before analysis and mark the job as needs_review
+)
+
+// minQualityTextLength is the length below which extracted text is considered suspiciously short
+const minQualityTextLength = 200
+
+// QualityReport describes how usable a piece of extracted text looks
+type QualityReport struct {
+	Score        float64  `json:"score"`         // 0 (garbage) to 1 (clean prose)
+	AlnumRatio   float64  `json:"alnum_ratio"`   // Letters and digits among non-space characters
+	WordRatio    float64  `json:"word_ratio"`    // Tokens that look like real words or numbers
+	InvalidRatio float64  `json:"invalid_ratio"` // Replacement and control characters among all characters
+	Length       int      `json:"length"`        // Length in characters
+	Reasons      []string `json:"reasons,omitempty"`
+}
+
+// AssessExtractionQuality scores extracted text using simple heuristics that catch
+// garbled OCR output, binary noise and near-empty extractions
+func AssessExtractionQuality(text string) QualityReport {
+	report := QualityReport{Length: utf8.RuneCountInString(text)}
+	if report.Length == 0 {
+		report.Reasons = []string{"no text was extracted"}
+		return report
+	}
+
+	var nonSpace, alnum, invalid int
+	for _, r := range text {
+		switch {
+		case r == utf8.RuneError || (unicode.IsControl(r) && !unicode.IsSpace(r)):
+			invalid++
+			nonSpace++
+		case unicode.IsSpace(r):
+		default:
+			nonSpace++
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				alnum++
+			}
+		}
+	}
+
+	tokens := strings.Fields(text)
+	words := 0
+	for _, token := range tokens {
+		if isWordLike(token) {
+			words++
+		}
+	}
+
+	if nonSpace > 0 {
+		report.AlnumRatio = float64(alnum) / float64(nonSpace)
+	}
+	if len(tokens) > 0 {
+		report.WordRatio = float64(words) / float64(len(tokens))
+	}
+	report.InvalidRatio = float64(invalid) / float64(report.Length)
+
+	lengthFactor := 1.0
+	if report.Length < minQualityTextLength {
+		lengthFactor = float64(report.Length) / minQualityTextLength
+		report.Reasons = append(report.Reasons, fmt.Sprintf("text is suspiciously short (%d characters)", report.Length))
+	}
+	if report.AlnumRatio < 0.6 {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("only %.0f%% of characters are letters or digits", report.AlnumRatio*100))
+	}
+	if report.WordRatio < 0.5 {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("only %.0f%% of tokens look like words", report.WordRatio*100))
+	}
+	if report.InvalidRatio > 0.02 {
+		report.Reasons = append(report.Reasons, fmt.Sprintf("%.0f%% of characters are invalid or control characters", report.InvalidRatio*100))
+	}
+
+	score := (report.AlnumRatio + report.WordRatio) / 2 * lengthFactor * (1 - math.Min(1, report.InvalidRatio*10))
+	report.Score = math.Max(0, math.Min(1, score))
+	return report
+}
+
+// isWordLike reports whether a token looks like a word or number once surrounding
+// punctuation is removed: mostly letters or digits and of a plausible length
+func isWordLike(token string) bool {
+	token = strings.TrimFunc(token, unicode.IsPunct)
+	length := utf8.RuneCountInString(token)
+	if length == 0 || length > 30 {
+		return false
+	}
+
+	alnum := 0
+	for _, r := range token {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			alnum++
+		}
+	}
+	return float64(alnum)/float64(length) >= 0.7
+}
+
+// TextLength counts the characters of text other than whitespace, so documents that
+// extract to little more than blank pages are measured by their actual content
+func TextLength(text string) int {
+	length := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			length++
+		}
+	}
+	return length
+}
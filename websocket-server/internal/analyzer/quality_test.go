@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// cleanResume is ordinary resume prose, long enough not to count as short
+const cleanResume = `Ada Lovelace - Senior Backend Engineer
+Experience: 6 years building Go services backed by SQL databases at Example Corp.
+Led the migration of 40 services to Kubernetes and cut deploy times by 70%.
+Education: BSc Computer Science, University of London, 2016.`
+
+// garbledResume is typical of a failed OCR pass over a scanned page
+var garbledResume = strings.Repeat("~#| ^^ �� §§ /\\/\\ ;;; |]{[ ", 12)
+
+func TestAssessExtractionQuality(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		minScore   float64
+		maxScore   float64
+		wantReason string // Substring of one of the reasons, "" for none
+	}{
+		{"clean text", cleanResume, 0.9, 1, ""},
+		{"garbled OCR output", garbledResume, 0, 0.3, "letters or digits"},
+		{"control characters", strings.Repeat("resume\x00\x01\x02 ", 30), 0, 0.3, "invalid or control characters"},
+		{"suspiciously short", "Ada Lovelace, engineer.", 0, 0.2, "suspiciously short"},
+		{"empty", "", 0, 0, "no text was extracted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := AssessExtractionQuality(tt.text)
+			if report.Score < tt.minScore || report.Score > tt.maxScore {
+				t.Errorf("score = %.2f, want between %.2f and %.2f (%+v)", report.Score, tt.minScore, tt.maxScore, report)
+			}
+
+			reasons := strings.Join(report.Reasons, "; ")
+			if tt.wantReason == "" && reasons != "" {
+				t.Errorf("unexpected reasons: %s", reasons)
+			}
+			if !strings.Contains(reasons, tt.wantReason) {
+				t.Errorf("reasons %q lack %q", reasons, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTextLength(t *testing.T) {
+	if got := TextLength(" Go \n\n\t SQL  "); got != 5 {
+		t.Errorf("TextLength = %d, want 5", got)
+	}
+	if got := TextLength("\n\f\n  "); got != 0 {
+		t.Errorf("TextLength of blank pages = %d, want 0", got)
+	}
+}
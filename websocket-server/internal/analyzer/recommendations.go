@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/your-org/websocket-server/internal/prompts"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// ErrInvalidRecommendations is returned when the LLM's job recommendations can't be parsed
+var ErrInvalidRecommendations = errors.New("invalid job recommendations from LLM")
+
+// maxJobRecommendations is the most recommendations kept from a regeneration
+const maxJobRecommendations = 10
+
+// recommendationsProfile is the part of a profile the LLM sees when suggesting roles.
+// Contact details are left out since they don't bear on the recommendations.
+type recommendationsProfile struct {
+	TotalWorkYears *float64                 `json:"total_work_years,omitempty"`
+	Skills         map[string][]string      `json:"skills,omitempty"`
+	Experience     []models.ExperienceEntry `json:"experience,omitempty"`
+	Education      []models.EducationEntry  `json:"education,omitempty"`
+	Summary        *string                  `json:"summary,omitempty"`
+	Strengths      []string                 `json:"strengths,omitempty"`
+}
+
+// RegenerateRecommendations asks the LLM for fresh job recommendations for a completed
+// job's profile, optionally biased towards an industry, and replaces the profile's
+// recommendations with them. The rest of the profile is unchanged. When the response
+// isn't valid the existing recommendations are kept and ErrInvalidRecommendations is returned.
+func (a *DefaultResumeAnalyzer) RegenerateRecommendations(ctx context.Context, jobID, industry string) (*models.AnalysisResult, error) {
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+
+	if job.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
+	}
+
+	profile, err := a.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	profileJSON, err := json.MarshalIndent(recommendationsProfile{
+		TotalWorkYears: profile.TotalWorkYears,
+		Skills:         profile.Skills,
+		Experience:     profile.Experience,
+		Education:      profile.Education,
+		Summary:        profile.Summary,
+		Strengths:      profile.Strengths,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	prompt, err := prompts.Render(prompts.JobRecommendations, prompts.JobRecommendationsData{
+		ProfileJSON: string(profileJSON),
+		Industry:    industry,
+		Current:     profile.JobRecommendations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.llmClient.GenerateFromPrompt(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	recommendations, err := parseRecommendations(response)
+	if err != nil {
+		log.Printf("Invalid job recommendations for job %s: %v", jobID, err)
+		return nil, err
+	}
+
+	profile.JobRecommendations = recommendations
+	if err := a.analysisRepo.UpdateProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	log.Printf("Regenerated %d job recommendations for job %s", len(recommendations), jobID)
+
+	return a.GetResult(ctx, jobID)
+}
+
+// parseRecommendations parses a {"job_recommendations": [...]} response strictly: apart
+// from a surrounding markdown code block, the response must be exactly that object, with
+// no other fields and at least one non-empty title. Repeated titles are dropped.
+func parseRecommendations(response string) ([]string, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+		response = strings.TrimSpace(strings.TrimSuffix(response, "```"))
+	}
+
+	var parsed struct {
+		JobRecommendations *[]string `json:"job_recommendations"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(response)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecommendations, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: unexpected content after the JSON object", ErrInvalidRecommendations)
+	}
+	if parsed.JobRecommendations == nil {
+		return nil, fmt.Errorf("%w: missing job_recommendations", ErrInvalidRecommendations)
+	}
+
+	var recommendations []string
+	seen := make(map[string]bool)
+	for _, title := range *parsed.JobRecommendations {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: empty job title", ErrInvalidRecommendations)
+		}
+		if key := strings.ToLower(title); !seen[key] {
+			seen[key] = true
+			recommendations = append(recommendations, title)
+		}
+	}
+
+	if len(recommendations) == 0 {
+		return nil, fmt.Errorf("%w: no job titles", ErrInvalidRecommendations)
+	}
+	if len(recommendations) > maxJobRecommendations {
+		recommendations = recommendations[:maxJobRecommendations]
+	}
+	return recommendations, nil
+}
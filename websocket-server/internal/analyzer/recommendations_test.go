@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// promptLLM answers every prompt with response, recording the prompts
+type promptLLM struct {
+	LLMClient
+
+	response string
+	prompts  []string
+}
+
+func (l *promptLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	l.prompts = append(l.prompts, prompt)
+	return l.response, nil
+}
+
+func TestParseRecommendations(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+	}{
+		{"object", `{"job_recommendations": ["Backend Engineer", "SRE"]}`, []string{"Backend Engineer", "SRE"}},
+		{"code block", "```json\n{\"job_recommendations\": [\"SRE\"]}\n```", []string{"SRE"}},
+		{"titles trimmed and repeats dropped", `{"job_recommendations": [" SRE ", "sre", "Platform Engineer"]}`, []string{"SRE", "Platform Engineer"}},
+		{"capped", `{"job_recommendations": ["A","B","C","D","E","F","G","H","I","J","K","L"]}`, []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}},
+		{"prose", `Here are some roles: SRE, Backend Engineer`, nil},
+		{"truncated", `{"job_recommendations": ["SRE"`, nil},
+		{"trailing text", `{"job_recommendations": ["SRE"]} Hope this helps!`, nil},
+		{"other fields", `{"job_recommendations": ["SRE"], "summary": "Great fit"}`, nil},
+		{"missing field", `{}`, nil},
+		{"not a list", `{"job_recommendations": "SRE"}`, nil},
+		{"empty list", `{"job_recommendations": []}`, nil},
+		{"empty title", `{"job_recommendations": ["SRE", " "]}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecommendations(tt.response)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidRecommendations) {
+					t.Errorf("parseRecommendations = %q, %v; want ErrInvalidRecommendations", got, err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRecommendations = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegenerateRecommendations(t *testing.T) {
+	ctx := context.Background()
+	current := []string{"Backend Engineer", "Database Administrator"}
+
+	// newJob analyzes a resume to a completed job whose profile recommends current
+	newJob := func(t *testing.T) (*testAnalyzer, string) {
+		t.Helper()
+		ta := newTestAnalyzer(t, nil)
+		ta.uploads.add(&models.Upload{ID: 1}, "Go services backed by SQL at Acme.")
+		job := ta.analyze(t, nil, 1)
+
+		profile, err := ta.repo.GetProfileByJobID(ctx, job.JobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		profile.JobRecommendations = current
+		if err := ta.repo.UpdateProfile(ctx, profile); err != nil {
+			t.Fatal(err)
+		}
+		return ta, job.JobID
+	}
+
+	t.Run("recommendations replaced", func(t *testing.T) {
+		ta, jobID := newJob(t)
+		llm := &promptLLM{response: `{"job_recommendations": ["Fintech Backend Engineer", "Payments SRE"]}`}
+		ta.llmClient = llm
+
+		result, err := ta.RegenerateRecommendations(ctx, jobID, "Fintech")
+		if err != nil {
+			t.Fatalf("RegenerateRecommendations: %v", err)
+		}
+
+		want := []string{"Fintech Backend Engineer", "Payments SRE"}
+		if !reflect.DeepEqual(result.JobRecommendations, want) {
+			t.Errorf("result recommendations = %q, want %q", result.JobRecommendations, want)
+		}
+		stored, _ := ta.repo.GetProfileByJobID(ctx, jobID)
+		if !reflect.DeepEqual(stored.JobRecommendations, want) {
+			t.Errorf("stored recommendations = %q, want %q", stored.JobRecommendations, want)
+		}
+		if stored.Name == nil || *stored.Name != "Ada" || !reflect.DeepEqual(stored.Skills["technical"], []string{"Go", "SQL"}) {
+			t.Errorf("rest of the profile changed: name %v, skills %v", derefOrNil(stored.Name), stored.Skills)
+		}
+
+		if len(llm.prompts) != 1 {
+			t.Fatalf("sent %d prompts, want 1", len(llm.prompts))
+		}
+		prompt := llm.prompts[0]
+		for _, want := range []string{"Fintech", "Backend Engineer", `"technical"`} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("prompt is missing %q:\n%s", want, prompt)
+			}
+		}
+		if strings.Contains(prompt, `"name"`) {
+			t.Errorf("prompt includes contact details:\n%s", prompt)
+		}
+	})
+
+	t.Run("malformed response keeps the recommendations", func(t *testing.T) {
+		ta, jobID := newJob(t)
+		ta.llmClient = &promptLLM{response: `Sure! You'd make a great "Backend Engineer".`}
+
+		if _, err := ta.RegenerateRecommendations(ctx, jobID, ""); !errors.Is(err, ErrInvalidRecommendations) {
+			t.Fatalf("error = %v, want ErrInvalidRecommendations", err)
+		}
+		stored, _ := ta.repo.GetProfileByJobID(ctx, jobID)
+		if !reflect.DeepEqual(stored.JobRecommendations, current) {
+			t.Errorf("stored recommendations = %q, want them kept as %q", stored.JobRecommendations, current)
+		}
+	})
+
+	t.Run("job not completed", func(t *testing.T) {
+		ta := newTestAnalyzer(t, nil)
+		if err := ta.repo.CreateJob(ctx, &models.AnalysisJob{JobID: "running", UploadID: 1, Status: "analyzing"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ta.RegenerateRecommendations(ctx, "running", ""); !errors.Is(err, ErrJobNotCompleted) {
+			t.Errorf("error = %v, want ErrJobNotCompleted", err)
+		}
+		if _, err := ta.RegenerateRecommendations(ctx, "missing", ""); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/your-org/websocket-server/internal/prompts"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// ErrInvalidRerank is returned when the LLM's ranking of search results can't be used
+var ErrInvalidRerank = errors.New("invalid search ranking from LLM")
+
+// MaxRerankCandidates is how many of the top search results are re-ranked by the LLM;
+// the rest keep their vector similarity order after them
+const MaxRerankCandidates = 20
+
+// maxJustificationLength bounds each justification kept from the LLM's ranking
+const maxJustificationLength = 300
+
+// RerankResult is the outcome of re-ranking search results
+type RerankResult struct {
+	Profiles       []*models.UserProfile // Re-ranked candidates, then the others in their original order
+	Justifications map[int]string        // Why each re-ranked candidate is placed where it is, by upload ID
+}
+
+// RerankResumes asks the LLM to order the top MaxRerankCandidates search results for a
+// query, as a cross-encoder seeing the query and each profile together, with a brief
+// justification for each. Candidates the LLM leaves out follow the ranked ones in their
+// original order. When the response isn't usable ErrInvalidRerank is returned, and the
+// caller keeps the original order.
+func (a *DefaultResumeAnalyzer) RerankResumes(ctx context.Context, query string, profiles []*models.UserProfile) (*RerankResult, error) {
+	if len(profiles) < 2 {
+		return &RerankResult{Profiles: profiles, Justifications: map[int]string{}}, nil
+	}
+
+	top := profiles[:min(len(profiles), MaxRerankCandidates)]
+	candidates := make([]prompts.RerankCandidate, len(top))
+	for i, profile := range top {
+		// The same profile fields as job recommendations: contact details don't bear on fit
+		profileJSON, err := json.Marshal(recommendationsProfile{
+			TotalWorkYears: profile.TotalWorkYears,
+			Skills:         profile.Skills,
+			Experience:     profile.Experience,
+			Education:      profile.Education,
+			Summary:        profile.Summary,
+			Strengths:      profile.Strengths,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode profile: %w", err)
+		}
+		candidates[i] = prompts.RerankCandidate{UploadID: profile.UploadID, ProfileJSON: string(profileJSON)}
+	}
+
+	prompt, err := prompts.Render(prompts.SearchRerank, prompts.SearchRerankData{
+		Query:      query,
+		Candidates: candidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.llmClient.GenerateFromPrompt(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	ranking, err := parseRanking(response)
+	if err != nil {
+		log.Printf("Invalid search ranking for query %q: %v", query, err)
+		return nil, err
+	}
+
+	return applyRanking(profiles, len(top), ranking)
+}
+
+// rankedCandidate is an entry of the LLM's ranking
+type rankedCandidate struct {
+	UploadID      int    `json:"upload_id"`
+	Justification string `json:"justification"`
+}
+
+// parseRanking parses a {"ranking": [...]} response. Apart from a surrounding markdown
+// code block, the response must be that JSON object.
+func parseRanking(response string) ([]rankedCandidate, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+		response = strings.TrimSpace(strings.TrimSuffix(response, "```"))
+	}
+
+	var parsed struct {
+		Ranking []rankedCandidate `json:"ranking"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRerank, err)
+	}
+	if len(parsed.Ranking) == 0 {
+		return nil, fmt.Errorf("%w: empty ranking", ErrInvalidRerank)
+	}
+	return parsed.Ranking, nil
+}
+
+// applyRanking orders the first ranked profiles by ranking. Upload IDs that aren't among
+// them, or repeat, are ignored; ranked profiles missing from the ranking follow the ones
+// in it, then come the profiles that weren't re-ranked.
+func applyRanking(profiles []*models.UserProfile, ranked int, ranking []rankedCandidate) (*RerankResult, error) {
+	byUpload := make(map[int]*models.UserProfile, ranked)
+	for _, profile := range profiles[:ranked] {
+		byUpload[profile.UploadID] = profile
+	}
+
+	result := &RerankResult{
+		Profiles:       make([]*models.UserProfile, 0, len(profiles)),
+		Justifications: make(map[int]string),
+	}
+	placed := make(map[int]bool, ranked)
+	for _, entry := range ranking {
+		profile, ok := byUpload[entry.UploadID]
+		if !ok || placed[entry.UploadID] {
+			continue
+		}
+		placed[entry.UploadID] = true
+		result.Profiles = append(result.Profiles, profile)
+		result.Justifications[entry.UploadID] = truncateJustification(strings.TrimSpace(entry.Justification))
+	}
+	if len(placed) == 0 {
+		return nil, fmt.Errorf("%w: no known upload IDs", ErrInvalidRerank)
+	}
+
+	for _, profile := range profiles[:ranked] {
+		if !placed[profile.UploadID] {
+			result.Profiles = append(result.Profiles, profile)
+		}
+	}
+	result.Profiles = append(result.Profiles, profiles[ranked:]...)
+
+	return result, nil
+}
+
+// truncateJustification cuts a justification to maxJustificationLength bytes
+func truncateJustification(justification string) string {
+	if len(justification) <= maxJustificationLength {
+		return justification
+	}
+	return strings.ToValidUTF8(justification[:maxJustificationLength], "") + "..."
+}
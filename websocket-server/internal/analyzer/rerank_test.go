@@ -0,0 +1,234 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// crossEncoderLLM ranks the candidates of a re-ranking prompt by how many of the query's
+// words their profile mentions, keeping the prompt's order between equal candidates,
+// much as a model reading the query and each profile together would
+type crossEncoderLLM struct {
+	LLMClient
+
+	prompts []string
+}
+
+var (
+	rerankQueryPattern     = regexp.MustCompile(`Search Query: (.*)`)
+	rerankCandidatePattern = regexp.MustCompile(`(?s)Candidate upload_id=(\d+):(.*?)(?:Candidate upload_id=|Rank every candidate)`)
+)
+
+func (l *crossEncoderLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	l.prompts = append(l.prompts, prompt)
+
+	query := rerankQueryPattern.FindStringSubmatch(prompt)[1]
+	type scored struct {
+		uploadID string
+		matches  int
+	}
+	var candidates []scored
+	for rest := prompt; ; {
+		match := rerankCandidatePattern.FindStringSubmatchIndex(rest)
+		if match == nil {
+			break
+		}
+		candidate := scored{uploadID: rest[match[2]:match[3]]}
+		for _, word := range strings.Fields(query) {
+			candidate.matches += strings.Count(rest[match[4]:match[5]], word)
+		}
+		candidates = append(candidates, candidate)
+		rest = rest[match[5]:]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].matches > candidates[j].matches })
+	ranking := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ranking[i] = fmt.Sprintf(`{"upload_id": %s, "justification": "Mentions %d query terms."}`, candidate.uploadID, candidate.matches)
+	}
+	return `{"ranking": [` + strings.Join(ranking, ", ") + `]}`, nil
+}
+
+// rerankProfile is a search result of an upload with the skills and summary
+func rerankProfile(uploadID int, summary string, skills ...string) *models.UserProfile {
+	email := fmt.Sprintf("candidate%d@example.com", uploadID)
+	return &models.UserProfile{
+		UploadID: uploadID,
+		Email:    &email,
+		Skills:   map[string][]string{"technical": skills},
+		Summary:  &summary,
+	}
+}
+
+// uploadIDs returns the upload IDs of profiles in order
+func uploadIDs(profiles []*models.UserProfile) []int {
+	ids := make([]int, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.UploadID
+	}
+	return ids
+}
+
+func TestParseRanking(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []rankedCandidate
+	}{
+		{"object", `{"ranking": [{"upload_id": 2, "justification": "Best fit."}, {"upload_id": 1}]}`, []rankedCandidate{{2, "Best fit."}, {1, ""}}},
+		{"code block", "```json\n{\"ranking\": [{\"upload_id\": 3}]}\n```", []rankedCandidate{{3, ""}}},
+		{"prose", `Candidate 2 is the best fit`, nil},
+		{"truncated", `{"ranking": [{"upload_id": 2}`, nil},
+		{"empty ranking", `{"ranking": []}`, nil},
+		{"missing ranking", `{}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRanking(tt.response)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidRerank) {
+					t.Errorf("parseRanking = %+v, %v; want ErrInvalidRerank", got, err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRanking = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRerankResumes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("improves on the vector order", func(t *testing.T) {
+		// The vector order puts the Go engineers, who only share wording with the query,
+		// above the one candidate who has run Kubernetes platforms
+		query := "Kubernetes platform engineer"
+		raw := []*models.UserProfile{
+			rerankProfile(1, "Backend engineer writing Go services", "Go", "SQL"),
+			rerankProfile(2, "Frontend engineer building design systems", "TypeScript", "React"),
+			rerankProfile(3, "Platform engineer running Kubernetes clusters at scale", "Kubernetes", "Terraform", "Go"),
+		}
+		llm := &crossEncoderLLM{}
+		ta := newTestAnalyzer(t, nil)
+		ta.llmClient = llm
+
+		result, err := ta.RerankResumes(ctx, query, raw)
+		if err != nil {
+			t.Fatalf("RerankResumes: %v", err)
+		}
+		if got, want := uploadIDs(result.Profiles), []int{3, 1, 2}; !reflect.DeepEqual(got, want) {
+			t.Errorf("re-ranked order = %v, want %v", got, want)
+		}
+		if got := uploadIDs(raw); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Errorf("re-ranking reordered the search results to %v", got)
+		}
+		if result.Justifications[3] != "Mentions 3 query terms." || len(result.Justifications) != 3 {
+			t.Errorf("justifications = %q, want one per candidate", result.Justifications)
+		}
+
+		prompt := llm.prompts[0]
+		if !strings.Contains(prompt, query) {
+			t.Errorf("prompt lacks the query:\n%s", prompt)
+		}
+		if strings.Contains(prompt, "@example.com") {
+			t.Errorf("prompt includes contact details:\n%s", prompt)
+		}
+	})
+
+	t.Run("only the top candidates are re-ranked", func(t *testing.T) {
+		var raw []*models.UserProfile
+		for i := 1; i <= MaxRerankCandidates+2; i++ {
+			raw = append(raw, rerankProfile(i, "Engineer", "Go"))
+		}
+		// The candidates past the cap are the best matches, but the LLM never sees them
+		raw[MaxRerankCandidates].Skills["technical"] = []string{"Kubernetes"}
+		raw[1].Skills["technical"] = []string{"Kubernetes"}
+		llm := &crossEncoderLLM{}
+		ta := newTestAnalyzer(t, nil)
+		ta.llmClient = llm
+
+		result, err := ta.RerankResumes(ctx, "Kubernetes", raw)
+		if err != nil {
+			t.Fatalf("RerankResumes: %v", err)
+		}
+		got := uploadIDs(result.Profiles)
+		if got[0] != 2 || !reflect.DeepEqual(got[MaxRerankCandidates:], []int{MaxRerankCandidates + 1, MaxRerankCandidates + 2}) {
+			t.Errorf("re-ranked order = %v, want upload 2 first and the uncapped candidates last in vector order", got)
+		}
+		if strings.Contains(llm.prompts[0], fmt.Sprintf("upload_id=%d:", MaxRerankCandidates+1)) {
+			t.Error("prompt includes candidates past the cap")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		response string
+		want     []int // Upload IDs in order, nil for ErrInvalidRerank
+	}{
+		{"omitted candidates follow in vector order", `{"ranking": [{"upload_id": 3}]}`, []int{3, 1, 2}},
+		{"unknown and repeated upload IDs ignored", `{"ranking": [{"upload_id": 9}, {"upload_id": 2}, {"upload_id": 2}, {"upload_id": 1}]}`, []int{2, 1, 3}},
+		{"no known upload IDs", `{"ranking": [{"upload_id": 9}]}`, nil},
+		{"prose", `The platform engineer is the best fit.`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, nil)
+			ta.llmClient = &promptLLM{response: tt.response}
+			raw := []*models.UserProfile{rerankProfile(1, "Go"), rerankProfile(2, "SQL"), rerankProfile(3, "Kubernetes")}
+
+			result, err := ta.RerankResumes(ctx, "query", raw)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidRerank) {
+					t.Errorf("RerankResumes = %+v, %v; want ErrInvalidRerank", result, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RerankResumes: %v", err)
+			}
+			if got := uploadIDs(result.Profiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("re-ranked order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("long justifications truncated", func(t *testing.T) {
+		ta := newTestAnalyzer(t, nil)
+		long := strings.Repeat("a", maxJustificationLength+50)
+		ta.llmClient = &promptLLM{response: `{"ranking": [{"upload_id": 2, "justification": "` + long + `"}]}`}
+
+		result, err := ta.RerankResumes(ctx, "query", []*models.UserProfile{rerankProfile(1, "Go"), rerankProfile(2, "SQL")})
+		if err != nil {
+			t.Fatalf("RerankResumes: %v", err)
+		}
+		if got := result.Justifications[2]; got != long[:maxJustificationLength]+"..." {
+			t.Errorf("justification has %d bytes, want it cut to %d", len(got), maxJustificationLength)
+		}
+	})
+
+	t.Run("single result not sent to the LLM", func(t *testing.T) {
+		llm := &promptLLM{}
+		ta := newTestAnalyzer(t, nil)
+		ta.llmClient = llm
+
+		result, err := ta.RerankResumes(ctx, "query", []*models.UserProfile{rerankProfile(1, "Go")})
+		if err != nil || !reflect.DeepEqual(uploadIDs(result.Profiles), []int{1}) {
+			t.Errorf("RerankResumes = %+v, %v; want the result unchanged", result, err)
+		}
+		if len(llm.prompts) != 0 {
+			t.Errorf("LLM asked %d times, want never", len(llm.prompts))
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Resume sections chunks are labeled with. Chunks before the first recognized heading
+// have no section ("").
+const (
+	SectionSummary        = "summary"
+	SectionExperience     = "experience"
+	SectionEducation      = "education"
+	SectionSkills         = "skills"
+	SectionProjects       = "projects"
+	SectionCertifications = "certifications"
+)
+
+// sectionHeadings maps the headings recognized in resume text to their section. Since
+// chunks are flattened to a single line, headings are only recognized in title case or
+// all caps, so the words in running text ("5 years of experience") don't count.
+var sectionHeadings = map[string]string{
+	"Summary":                 SectionSummary,
+	"Professional Summary":    SectionSummary,
+	"About Me":                SectionSummary,
+	"Objective":               SectionSummary,
+	"Experience":              SectionExperience,
+	"Work Experience":         SectionExperience,
+	"Professional Experience": SectionExperience,
+	"Employment History":      SectionExperience,
+	"Work History":            SectionExperience,
+	"Education":               SectionEducation,
+	"Skills":                  SectionSkills,
+	"Technical Skills":        SectionSkills,
+	"Core Competencies":       SectionSkills,
+	"Projects":                SectionProjects,
+	"Personal Projects":       SectionProjects,
+	"Certifications":          SectionCertifications,
+	"Licenses":                SectionCertifications,
+}
+
+// sectionHeading is a heading found in a chunk
+type sectionHeading struct {
+	start, end int // Byte offsets of the heading in the chunk
+	section    string
+}
+
+// LabelChunkSections labels each chunk with the resume section it mostly belongs to.
+// Chunks must be in document order: text before a chunk's first heading belongs to the
+// section of the previous chunk's last heading.
+func LabelChunkSections(chunks []string) []ChunkMetadata {
+	metadata := make([]ChunkMetadata, len(chunks))
+	current := ""
+
+	for i, chunk := range chunks {
+		headings := findSectionHeadings(chunk)
+
+		// Count the text of each section in the chunk, headings included
+		lengths := make(map[string]int)
+		section, offset := current, 0
+		for _, heading := range headings {
+			lengths[section] += heading.start - offset
+			section, offset = heading.section, heading.start
+		}
+		lengths[section] += len(chunk) - offset
+
+		// The longest section wins; a tie goes to the one reached last
+		best := current
+		for _, heading := range headings {
+			if lengths[heading.section] >= lengths[best] {
+				best = heading.section
+			}
+		}
+		metadata[i].Section = best
+		current = section
+	}
+
+	return metadata
+}
+
+// findSectionHeadings returns the headings in a chunk in order. Where headings overlap,
+// such as "Experience" within "Work Experience", the longest is kept.
+func findSectionHeadings(chunk string) []sectionHeading {
+	var found []sectionHeading
+	for heading, section := range sectionHeadings {
+		for _, form := range []string{heading, strings.ToUpper(heading)} {
+			for offset := 0; ; {
+				index := strings.Index(chunk[offset:], form)
+				if index < 0 {
+					break
+				}
+				start := offset + index
+				end := start + len(form)
+				if isWordBoundary(chunk, start, end) {
+					found = append(found, sectionHeading{start: start, end: end, section: section})
+				}
+				offset = end
+			}
+		}
+	}
+
+	// Order by position, the longest heading first where they start together
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].start != found[j].start {
+			return found[i].start < found[j].start
+		}
+		return found[i].end > found[j].end
+	})
+
+	var headings []sectionHeading
+	for _, heading := range found {
+		if n := len(headings); n > 0 && heading.start < headings[n-1].end {
+			continue // Within the previous heading
+		}
+		headings = append(headings, heading)
+	}
+	return headings
+}
+
+// isWordBoundary reports whether text[start:end] isn't part of a longer word
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 && isWordByte(text[start-1]) {
+		return false
+	}
+	return end >= len(text) || !isWordByte(text[end])
+}
+
+// isWordByte reports whether b is an ASCII letter or digit
+func isWordByte(b byte) bool {
+	return b < unicode.MaxASCII && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
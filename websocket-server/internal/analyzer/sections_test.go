@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelChunkSections(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string // Section of each chunk
+	}{
+		{"no headings", []string{"Jane Doe, Go engineer"}, []string{""}},
+		{
+			"sections carry over to later chunks",
+			[]string{"Jane Doe jane@example.com", "EXPERIENCE Go engineer at Acme", "building APIs. Education BSc", "Computer Science, MIT"},
+			[]string{"", SectionExperience, SectionExperience, SectionEducation},
+		},
+		{"running text isn't a heading", []string{"Summary: 5 years of experience and skills in Go"}, []string{SectionSummary}},
+		{"multi-word heading in caps", []string{"WORK EXPERIENCE Acme 2019-2023"}, []string{SectionExperience}},
+		{"heading within a word", []string{"Skillset: Go, SQL"}, []string{""}},
+		{"text before the heading is longer", []string{"Go services at Acme. SKILLS Go"}, []string{""}},
+		{"tie goes to the later section", []string{"Skills Go, SQL, Projects Go, SQL"}, []string{SectionProjects}},
+		{"no chunks", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, metadata := range LabelChunkSections(tt.chunks) {
+				got = append(got, metadata.Section)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sections = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
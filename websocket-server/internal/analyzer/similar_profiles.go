@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// MaxSimilarProfiles is how many similar profiles are kept for each profile
+const MaxSimilarProfiles = 10
+
+// similarSearchLimit is how many chunks the vector search for similar profiles returns.
+// Each resume has several chunks, so it is well above MaxSimilarProfiles.
+const similarSearchLimit = 100
+
+// similarRefreshTimeout bounds recomputing the similar profiles of a whole tenant
+const similarRefreshTimeout = 10 * time.Minute
+
+// similarCache holds the precomputed similar profiles of each job. Tenants are refreshed
+// in the background, one at a time, by a goroutine that runs while refreshes are pending.
+type similarCache struct {
+	mu         sync.Mutex
+	byJob      map[string][]*models.SimilarProfile
+	uploads    map[string]int  // Upload of each job in byJob
+	pending    map[string]bool // Tenants waiting to be refreshed
+	refreshing bool            // Whether the refresh goroutine is running
+}
+
+func newSimilarCache() *similarCache {
+	return &similarCache{
+		byJob:   make(map[string][]*models.SimilarProfile),
+		uploads: make(map[string]int),
+		pending: make(map[string]bool),
+	}
+}
+
+func (c *similarCache) get(jobID string) ([]*models.SimilarProfile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	similar, ok := c.byJob[jobID]
+	return similar, ok
+}
+
+func (c *similarCache) set(jobID string, uploadID int, similar []*models.SimilarProfile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byJob[jobID] = similar
+	c.uploads[jobID] = uploadID
+}
+
+// forget drops deleted jobs, both their own entries and their appearances as neighbors
+func (c *similarCache) forget(jobIDs ...string) {
+	deleted := make(map[string]bool, len(jobIDs))
+	for _, jobID := range jobIDs {
+		deleted[jobID] = true
+	}
+	c.remove(func(jobID string, _ int) bool { return deleted[jobID] })
+}
+
+// forgetUpload drops the jobs of a deleted upload, both their own entries and their
+// appearances as neighbors
+func (c *similarCache) forgetUpload(uploadID int) {
+	c.remove(func(_ string, upload int) bool { return upload == uploadID })
+}
+
+// remove drops the jobs deleted reports true for, given each job and its upload
+func (c *similarCache) remove(deleted func(jobID string, uploadID int) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for jobID, similar := range c.byJob {
+		if deleted(jobID, c.uploads[jobID]) {
+			delete(c.byJob, jobID)
+			delete(c.uploads, jobID)
+			continue
+		}
+		kept := make([]*models.SimilarProfile, 0, len(similar))
+		for _, profile := range similar {
+			if !deleted(profile.JobID, profile.UploadID) {
+				kept = append(kept, profile)
+			}
+		}
+		c.byJob[jobID] = kept
+	}
+}
+
+// ForgetUpload drops the similar profiles cached for the jobs of a deleted upload, and
+// the upload's appearances among the similar profiles of others
+func (a *DefaultResumeAnalyzer) ForgetUpload(uploadID int) {
+	a.similar.forgetUpload(uploadID)
+}
+
+// GetSimilarProfiles returns the profiles of the same tenant most similar to the job's
+// profile, most similar first, from the precomputed cache. Profiles not cached yet (e.g.
+// after a restart) are computed on the spot and cached.
+func (a *DefaultResumeAnalyzer) GetSimilarProfiles(ctx context.Context, jobID string) ([]*models.SimilarProfile, error) {
+	if similar, ok := a.similar.get(jobID); ok {
+		return similar, nil
+	}
+
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+	if job.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
+	}
+
+	upload, err := a.uploadRepo.GetUploadByID(ctx, job.UploadID)
+	if err != nil {
+		return nil, fmt.Errorf("upload not found: %w", err)
+	}
+
+	profile, err := a.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	similar, err := a.computeSimilarProfiles(ctx, upload.TenantID, profile, nil)
+	if err != nil {
+		return nil, err
+	}
+	a.similar.set(jobID, job.UploadID, similar)
+
+	return similar, nil
+}
+
+// refreshSimilarProfiles recomputes the similar profiles of a tenant in the background,
+// e.g. once one of its uploads has been analyzed. Refreshes requested while one is
+// running are merged, so a burst of completed jobs refreshes a tenant only once or twice.
+func (a *DefaultResumeAnalyzer) refreshSimilarProfiles(tenantID string) {
+	c := a.similar
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[tenantID] = true
+	if c.refreshing {
+		return
+	}
+	c.refreshing = true
+
+	go func() {
+		for {
+			tenantID, ok := c.nextPending()
+			if !ok {
+				return
+			}
+			if err := a.refreshTenantSimilarProfiles(tenantID); err != nil {
+				log.Printf("Failed to refresh similar profiles of tenant %q: %v", tenantID, err)
+			}
+		}
+	}()
+}
+
+// nextPending takes a tenant waiting to be refreshed. Once none are left, it reports
+// false and marks the refresh goroutine as stopped.
+func (c *similarCache) nextPending() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for tenantID := range c.pending {
+		delete(c.pending, tenantID)
+		return tenantID, true
+	}
+	c.refreshing = false
+	return "", false
+}
+
+// refreshTenantSimilarProfiles recomputes the similar profiles of every analyzed upload
+// of a tenant, at most MaxOrgCandidates of them. A job whose profiles can't be computed
+// is logged and skipped, keeping its previous entry.
+func (a *DefaultResumeAnalyzer) refreshTenantSimilarProfiles(tenantID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), similarRefreshTimeout)
+	defer cancel()
+
+	startTime := a.clock.Now()
+
+	var jobIDs []string
+	for offset := 0; offset < MaxOrgCandidates; offset += orgCandidatesPageSize {
+		uploads, err := a.uploadRepo.ListUploadsFiltered(ctx, repository.UploadFilter{TenantID: &tenantID, Status: "completed"}, repository.UploadSort{}, orgCandidatesPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list uploads: %w", err)
+		}
+		for _, upload := range uploads {
+			if upload.JobID != nil {
+				jobIDs = append(jobIDs, *upload.JobID)
+			}
+		}
+		if len(uploads) < orgCandidatesPageSize {
+			break
+		}
+	}
+
+	profiles, err := a.analysisRepo.GetProfilesByJobIDs(ctx, jobIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get profiles: %w", err)
+	}
+
+	byUpload := make(map[int]*models.UserProfile, len(profiles))
+	for _, profile := range profiles {
+		byUpload[profile.UploadID] = profile
+	}
+
+	failed := 0
+	for jobID, profile := range profiles {
+		similar, err := a.computeSimilarProfiles(ctx, tenantID, profile, byUpload)
+		if err != nil {
+			log.Printf("Warning: failed to refresh similar profiles of job %s: %v", jobID, err)
+			failed++
+			continue
+		}
+		a.similar.set(jobID, profile.UploadID, similar)
+	}
+
+	log.Printf("Refreshed similar profiles of %d jobs of tenant %q in %v (%d failed)", len(profiles)-failed, tenantID, a.clock.Now().Sub(startTime), failed)
+	return nil
+}
+
+// computeSimilarProfiles finds the profiles of the tenant closest to profile by searching
+// the vector store with its summary, skills and recommended roles. A profile scores the
+// similarity of its best matching chunk. known holds profiles already loaded, by upload
+// ID; other uploads' profiles are fetched.
+func (a *DefaultResumeAnalyzer) computeSimilarProfiles(ctx context.Context, tenantID string, profile *models.UserProfile, known map[int]*models.UserProfile) ([]*models.SimilarProfile, error) {
+	similar := []*models.SimilarProfile{}
+
+	query := similarityQuery(profile)
+	if query == "" {
+		return similar, nil
+	}
+
+	results, err := a.vectorStore.SearchSimilar(ctx, tenantID, query, similarSearchLimit, SearchFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("vector search failed: %w", err)
+	}
+
+	// Results are ordered by score, so an upload's first chunk is its best one
+	seen := map[int]bool{profile.UploadID: true}
+	for _, result := range results {
+		if len(similar) == MaxSimilarProfiles {
+			break
+		}
+		if seen[result.UploadID] {
+			continue
+		}
+		seen[result.UploadID] = true
+
+		neighbor, ok := known[result.UploadID]
+		if !ok {
+			if neighbor, err = a.similarNeighbor(ctx, result.UploadID); err != nil {
+				log.Printf("Warning: failed to get profile for upload %d: %v", result.UploadID, err)
+				continue
+			}
+		}
+
+		similar = append(similar, &models.SimilarProfile{
+			JobID:    neighbor.JobID,
+			UploadID: neighbor.UploadID,
+			Name:     neighbor.Name,
+			Score:    result.Score,
+		})
+	}
+
+	return similar, nil
+}
+
+// similarNeighbor fetches the latest profile of an upload, including its contact fields
+func (a *DefaultResumeAnalyzer) similarNeighbor(ctx context.Context, uploadID int) (*models.UserProfile, error) {
+	latest, err := a.analysisRepo.GetProfileByUploadID(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	return a.analysisRepo.GetProfileByJobID(ctx, latest.JobID)
+}
+
+// similarityQuery describes a profile for the similar profiles search
+func similarityQuery(profile *models.UserProfile) string {
+	var parts []string
+	if profile.Summary != nil && strings.TrimSpace(*profile.Summary) != "" {
+		parts = append(parts, strings.TrimSpace(*profile.Summary))
+	}
+	if skills := topSkills(profile.Skills, orgCandidateTopSkills*2); len(skills) > 0 {
+		parts = append(parts, "Skills: "+strings.Join(skills, ", "))
+	}
+	if len(profile.JobRecommendations) > 0 {
+		parts = append(parts, "Roles: "+strings.Join(profile.JobRecommendations, ", "))
+	}
+	return strings.Join(parts, "\n")
+}
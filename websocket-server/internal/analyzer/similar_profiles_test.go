@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// similarUploadIDs returns the uploads of similar profiles, in order
+func similarUploadIDs(similar []*models.SimilarProfile) []int {
+	ids := make([]int, len(similar))
+	for i, profile := range similar {
+		ids[i] = profile.UploadID
+	}
+	return ids
+}
+
+// waitForSimilar waits until the cached similar profiles of a job are those of uploads
+// wantUploads, in any order, and no refresh is running, failing the test after a few
+// seconds
+func (ta *testAnalyzer) waitForSimilar(t *testing.T, jobID string, wantUploads ...int) []*models.SimilarProfile {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		similar, ok := ta.similar.get(jobID)
+		ta.similar.mu.Lock()
+		idle := !ta.similar.refreshing && len(ta.similar.pending) == 0
+		ta.similar.mu.Unlock()
+
+		if ok && idle && sameUploads(similarUploadIDs(similar), wantUploads) {
+			return similar
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("similar profiles of %s = uploads %v (cached: %t, idle: %t), want %v", jobID, similarUploadIDs(similar), ok, idle, wantUploads)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// sameUploads reports whether got and want hold the same uploads, in any order
+func sameUploads(got, want []int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	counts := make(map[int]int)
+	for _, id := range got {
+		counts[id]++
+	}
+	for _, id := range want {
+		counts[id]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSimilarProfiles(t *testing.T) {
+	ctx := context.Background()
+	ta := newTestAnalyzer(t, nil)
+
+	resumes := []struct {
+		upload *models.Upload
+		text   string
+	}{
+		{&models.Upload{ID: 1, TenantID: "acme"}, "Go services backed by SQL databases at Acme."},
+		{&models.Upload{ID: 2, TenantID: "acme"}, "Go services backed by SQL databases at Globex."},
+		{&models.Upload{ID: 3, TenantID: "acme"}, "Rust tooling and Terraform modules for cloud networks."},
+		{&models.Upload{ID: 4, TenantID: "initech"}, "Go services backed by SQL databases at Initech."},
+	}
+	jobs := make(map[int]string)
+	for _, resume := range resumes {
+		ta.uploads.add(resume.upload, resume.text)
+		job := ta.analyze(t, nil, resume.upload.ID)
+		if job.Status != "completed" {
+			t.Fatalf("job of upload %d: status %q", resume.upload.ID, job.Status)
+		}
+		ta.uploads.setJob(resume.upload.ID, job.JobID, "completed")
+		jobs[resume.upload.ID] = job.JobID
+	}
+
+	// The refresh after each tenant's last job ran before it was listed, so refresh once
+	// more as the next completed job would
+	ta.refreshSimilarProfiles("acme")
+	ta.refreshSimilarProfiles("initech")
+
+	t.Run("cached after analysis", func(t *testing.T) {
+		similar := ta.waitForSimilar(t, jobs[1], 2, 3)
+
+		// The resume sharing the most words ranks first
+		if similar[0].UploadID != 2 || similar[0].JobID != jobs[2] || similar[0].Name == nil || *similar[0].Name != "Ada" {
+			t.Errorf("most similar = %+v, want upload 2's profile", similar[0])
+		}
+		if similar[0].Score < similar[1].Score {
+			t.Errorf("scores %v, %v aren't in descending order", similar[0].Score, similar[1].Score)
+		}
+
+		ta.waitForSimilar(t, jobs[2], 1, 3)
+		ta.waitForSimilar(t, jobs[3], 1, 2)
+
+		// Other tenants' profiles are never similar
+		ta.waitForSimilar(t, jobs[4])
+	})
+
+	t.Run("read from the cache", func(t *testing.T) {
+		cached, _ := ta.similar.get(jobs[1])
+		got, err := ta.GetSimilarProfiles(ctx, jobs[1])
+		if err != nil {
+			t.Fatalf("GetSimilarProfiles: %v", err)
+		}
+		if len(got) != len(cached) || got[0] != cached[0] {
+			t.Errorf("GetSimilarProfiles = %v, want the cached %v", similarUploadIDs(got), similarUploadIDs(cached))
+		}
+	})
+
+	t.Run("computed on a cache miss", func(t *testing.T) {
+		ta.similar = newSimilarCache() // As after a restart
+
+		got, err := ta.GetSimilarProfiles(ctx, jobs[3])
+		if err != nil {
+			t.Fatalf("GetSimilarProfiles: %v", err)
+		}
+		if !sameUploads(similarUploadIDs(got), []int{1, 2}) {
+			t.Errorf("similar profiles = uploads %v, want 1 and 2", similarUploadIDs(got))
+		}
+		if cached, ok := ta.similar.get(jobs[3]); !ok || len(cached) != len(got) {
+			t.Errorf("computed profiles weren't cached: %v", similarUploadIDs(cached))
+		}
+	})
+
+	t.Run("deleted upload forgotten", func(t *testing.T) {
+		ta.similar.set(jobs[1], 1, []*models.SimilarProfile{{JobID: jobs[2], UploadID: 2}, {JobID: jobs[3], UploadID: 3}})
+		ta.similar.set(jobs[2], 2, []*models.SimilarProfile{{JobID: jobs[1], UploadID: 1}})
+
+		ta.ForgetUpload(2)
+
+		if _, ok := ta.similar.get(jobs[2]); ok {
+			t.Error("deleted upload's job still cached")
+		}
+		if similar, _ := ta.similar.get(jobs[1]); !sameUploads(similarUploadIDs(similar), []int{3}) {
+			t.Errorf("similar profiles after deleting upload 2 = uploads %v, want 3", similarUploadIDs(similar))
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := ta.repo.CreateJob(ctx, &models.AnalysisJob{JobID: "running", UploadID: 1, Status: "analyzing"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ta.GetSimilarProfiles(ctx, "running"); !errors.Is(err, ErrJobNotCompleted) {
+			t.Errorf("error = %v, want ErrJobNotCompleted", err)
+		}
+		if _, err := ta.GetSimilarProfiles(ctx, "missing"); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/your-org/websocket-server/internal/prompts"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// ErrInvalidSkillGap is returned when the LLM's gap analysis of a job description can't be parsed
+var ErrInvalidSkillGap = errors.New("invalid skill gap analysis from LLM")
+
+// MaxSkillGapJobDescriptions is the most job descriptions analyzed in one request
+const MaxSkillGapJobDescriptions = 10
+
+// maxSkillGapSkills is the most missing skills kept for one job description
+const maxSkillGapSkills = 30
+
+// AnalyzeSkillGaps runs a gap analysis of a completed job's profile against each job
+// description, concurrently, and aggregates the missing skills, ranked by the number of
+// job descriptions asking for them. Skills are compared case-insensitively, and ones
+// the profile lists are dropped even if the LLM reports them. Any failed analysis fails
+// the whole report.
+func (a *DefaultResumeAnalyzer) AnalyzeSkillGaps(ctx context.Context, jobID string, jobDescriptions []string) (*models.SkillGapReport, error) {
+	if len(jobDescriptions) == 0 || len(jobDescriptions) > MaxSkillGapJobDescriptions {
+		return nil, fmt.Errorf("between 1 and %d job descriptions are required", MaxSkillGapJobDescriptions)
+	}
+
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+
+	if job.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
+	}
+
+	profile, err := a.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	// The same profile fields as job recommendations: contact details don't bear on skills
+	profileJSON, err := json.MarshalIndent(recommendationsProfile{
+		TotalWorkYears: profile.TotalWorkYears,
+		Skills:         profile.Skills,
+		Experience:     profile.Experience,
+		Education:      profile.Education,
+		Summary:        profile.Summary,
+		Strengths:      profile.Strengths,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	perJob := make([][]string, len(jobDescriptions))
+	errs := make([]error, len(jobDescriptions))
+	var wg sync.WaitGroup
+	for i, jobDescription := range jobDescriptions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			perJob[i], errs[i] = a.skillGap(ctx, string(profileJSON), jobDescription)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Skill gap analysis of job description %d for job %s failed: %v", i, jobID, err)
+			return nil, fmt.Errorf("job description %d: %w", i, err)
+		}
+	}
+
+	// Skills the profile lists aren't gaps, however the LLM judged them
+	known := make(map[string]bool)
+	for _, skills := range profile.Skills {
+		for _, skill := range skills {
+			known[skillKey(skill)] = true
+		}
+	}
+	for i, skills := range perJob {
+		missing := skills[:0]
+		for _, skill := range skills {
+			if !known[skillKey(skill)] {
+				missing = append(missing, skill)
+			}
+		}
+		perJob[i] = missing
+	}
+
+	return &models.SkillGapReport{
+		JobID:               jobID,
+		JobDescriptionCount: len(jobDescriptions),
+		MissingSkills:       aggregateSkillGaps(perJob),
+		PerJobDescription:   perJob,
+	}, nil
+}
+
+// skillGap asks the LLM which skills a job description asks for that the profile lacks
+func (a *DefaultResumeAnalyzer) skillGap(ctx context.Context, profileJSON, jobDescription string) ([]string, error) {
+	prompt, err := prompts.Render(prompts.SkillGap, prompts.SkillGapData{
+		ProfileJSON:    profileJSON,
+		JobDescription: jobDescription,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.llmClient.GenerateFromPrompt(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	return parseSkillGap(response)
+}
+
+// parseSkillGap parses a {"missing_skills": [...]} response. Apart from a surrounding
+// markdown code block, the response must be that JSON object. Blank and repeated skills
+// are dropped; an empty list means nothing is missing.
+func parseSkillGap(response string) ([]string, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+		response = strings.TrimSpace(strings.TrimSuffix(response, "```"))
+	}
+
+	var parsed struct {
+		MissingSkills *[]string `json:"missing_skills"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSkillGap, err)
+	}
+	if parsed.MissingSkills == nil {
+		return nil, fmt.Errorf("%w: missing missing_skills", ErrInvalidSkillGap)
+	}
+
+	skills := []string{}
+	seen := make(map[string]bool)
+	for _, skill := range *parsed.MissingSkills {
+		skill = strings.Join(strings.Fields(skill), " ")
+		if key := skillKey(skill); key != "" && !seen[key] {
+			seen[key] = true
+			skills = append(skills, skill)
+		}
+	}
+
+	if len(skills) > maxSkillGapSkills {
+		skills = skills[:maxSkillGapSkills]
+	}
+	return skills, nil
+}
+
+// aggregateSkillGaps counts the job descriptions each skill is missing for, most often
+// missing first. Skills missing equally often keep the order they were first seen in;
+// each is named as it was first seen.
+func aggregateSkillGaps(perJob [][]string) []models.SkillGapCount {
+	counts := []models.SkillGapCount{}
+	index := make(map[string]int)
+	for i, skills := range perJob {
+		for _, skill := range skills {
+			key := skillKey(skill)
+			n, ok := index[key]
+			if !ok {
+				n = len(counts)
+				index[key] = n
+				counts = append(counts, models.SkillGapCount{Skill: skill, JobDescriptions: []int{}})
+			}
+			// A skill repeated within a job description counts once
+			if jobs := counts[n].JobDescriptions; len(jobs) > 0 && jobs[len(jobs)-1] == i {
+				continue
+			}
+			counts[n].Count++
+			counts[n].JobDescriptions = append(counts[n].JobDescriptions, i)
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	return counts
+}
+
+// skillKey is the form skills are compared in
+func skillKey(skill string) string {
+	return strings.ToLower(strings.Join(strings.Fields(skill), " "))
+}
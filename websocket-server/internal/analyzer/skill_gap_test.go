@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// gapLLM answers a skill gap prompt with the response for the job description it names.
+// Job descriptions are analyzed concurrently, so the prompts are recorded under a lock.
+type gapLLM struct {
+	LLMClient
+
+	responses map[string]string // By job description
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (l *gapLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	l.prompts = append(l.prompts, prompt)
+	l.mu.Unlock()
+
+	for jobDescription, response := range l.responses {
+		if strings.Contains(prompt, jobDescription) {
+			return response, nil
+		}
+	}
+	return "", errors.New("unexpected prompt")
+}
+
+func TestParseSkillGap(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+	}{
+		{"object", `{"missing_skills": ["Kubernetes", "Terraform"]}`, []string{"Kubernetes", "Terraform"}},
+		{"code block", "```json\n{\"missing_skills\": [\"Rust\"]}\n```", []string{"Rust"}},
+		{"nothing missing", `{"missing_skills": []}`, []string{}},
+		{"blank and repeated skills dropped", `{"missing_skills": [" People  management ", "", "people management", "Go"]}`, []string{"People management", "Go"}},
+		{"prose", `You should learn Kubernetes.`, nil},
+		{"missing field", `{}`, nil},
+		{"null list", `{"missing_skills": null}`, nil},
+		{"not a list", `{"missing_skills": "Kubernetes"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSkillGap(tt.response)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidSkillGap) {
+					t.Errorf("parseSkillGap = %q, %v; want ErrInvalidSkillGap", got, err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSkillGap = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateSkillGaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		perJob [][]string
+		want   []models.SkillGapCount
+	}{
+		{"none", [][]string{{}, {}}, []models.SkillGapCount{}},
+		{
+			"most often missing first",
+			[][]string{{"Terraform"}, {"Kubernetes", "Rust"}, {"kubernetes"}, {"Kubernetes", "Rust"}},
+			[]models.SkillGapCount{
+				{Skill: "Kubernetes", Count: 3, JobDescriptions: []int{1, 2, 3}},
+				{Skill: "Rust", Count: 2, JobDescriptions: []int{1, 3}},
+				{Skill: "Terraform", Count: 1, JobDescriptions: []int{0}},
+			},
+		},
+		{
+			"ties keep the order first seen",
+			[][]string{{"Spark", "Airflow"}, {"Airflow", "Spark"}},
+			[]models.SkillGapCount{
+				{Skill: "Spark", Count: 2, JobDescriptions: []int{0, 1}},
+				{Skill: "Airflow", Count: 2, JobDescriptions: []int{0, 1}},
+			},
+		},
+		{
+			"repeats within a job description count once",
+			[][]string{{"Go", "go "}, {"SQL"}},
+			[]models.SkillGapCount{
+				{Skill: "Go", Count: 1, JobDescriptions: []int{0}},
+				{Skill: "SQL", Count: 1, JobDescriptions: []int{1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregateSkillGaps(tt.perJob); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aggregateSkillGaps = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSkillGaps(t *testing.T) {
+	ctx := context.Background()
+
+	// newJob analyzes a resume to a completed job whose profile has the skills Go and SQL
+	newJob := func(t *testing.T) (*testAnalyzer, string) {
+		t.Helper()
+		ta := newTestAnalyzer(t, nil)
+		ta.uploads.add(&models.Upload{ID: 1}, "Go services backed by SQL at Acme.")
+		return ta, ta.analyze(t, nil, 1).JobID
+	}
+
+	t.Run("skill missing in most job descriptions ranks highest", func(t *testing.T) {
+		ta, jobID := newJob(t)
+		jobDescriptions := []string{
+			"Platform engineer writing Terraform and Go",
+			"SRE running Kubernetes with Prometheus",
+			"Backend engineer deploying Rust services to Kubernetes",
+			"Data engineer building Spark pipelines on Kubernetes",
+		}
+		llm := &gapLLM{responses: map[string]string{
+			jobDescriptions[0]: `{"missing_skills": ["Terraform", "go"]}`,
+			jobDescriptions[1]: `{"missing_skills": ["Kubernetes", "Prometheus"]}`,
+			jobDescriptions[2]: `{"missing_skills": ["Rust", "kubernetes", "Kubernetes"]}`,
+			jobDescriptions[3]: `{"missing_skills": ["Spark", "Kubernetes", "SQL"]}`,
+		}}
+		ta.llmClient = llm
+
+		report, err := ta.AnalyzeSkillGaps(ctx, jobID, jobDescriptions)
+		if err != nil {
+			t.Fatalf("AnalyzeSkillGaps: %v", err)
+		}
+
+		// Terraform is seen first, but Kubernetes is missing for three of the four
+		want := []models.SkillGapCount{
+			{Skill: "Kubernetes", Count: 3, JobDescriptions: []int{1, 2, 3}},
+			{Skill: "Terraform", Count: 1, JobDescriptions: []int{0}},
+			{Skill: "Prometheus", Count: 1, JobDescriptions: []int{1}},
+			{Skill: "Rust", Count: 1, JobDescriptions: []int{2}},
+			{Skill: "Spark", Count: 1, JobDescriptions: []int{3}},
+		}
+		if !reflect.DeepEqual(report.MissingSkills, want) {
+			t.Errorf("missing skills = %+v, want %+v", report.MissingSkills, want)
+		}
+
+		// The profile's own skills aren't gaps, whatever the LLM says
+		wantPerJob := [][]string{{"Terraform"}, {"Kubernetes", "Prometheus"}, {"Rust", "kubernetes"}, {"Spark", "Kubernetes"}}
+		if !reflect.DeepEqual(report.PerJobDescription, wantPerJob) {
+			t.Errorf("per job description = %q, want %q", report.PerJobDescription, wantPerJob)
+		}
+		if report.JobID != jobID || report.JobDescriptionCount != 4 {
+			t.Errorf("report for %s of %d job descriptions, want %s of 4", report.JobID, report.JobDescriptionCount, jobID)
+		}
+
+		if len(llm.prompts) != 4 {
+			t.Fatalf("sent %d prompts, want one per job description", len(llm.prompts))
+		}
+		for _, prompt := range llm.prompts {
+			if !strings.Contains(prompt, `"technical"`) || strings.Contains(prompt, `"name"`) {
+				t.Errorf("prompt should have the profile's skills but not its contact details:\n%s", prompt)
+			}
+		}
+	})
+
+	t.Run("invalid analysis fails the report", func(t *testing.T) {
+		ta, jobID := newJob(t)
+		ta.llmClient = &gapLLM{responses: map[string]string{
+			"Platform engineer": `{"missing_skills": ["Terraform"]}`,
+			"SRE":               `Learn Kubernetes!`,
+		}}
+
+		if _, err := ta.AnalyzeSkillGaps(ctx, jobID, []string{"Platform engineer", "SRE"}); !errors.Is(err, ErrInvalidSkillGap) {
+			t.Errorf("error = %v, want ErrInvalidSkillGap", err)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		ta, jobID := newJob(t)
+		if err := ta.repo.CreateJob(ctx, &models.AnalysisJob{JobID: "running", UploadID: 1, Status: "analyzing"}); err != nil {
+			t.Fatal(err)
+		}
+		ta.llmClient = &gapLLM{}
+
+		if _, err := ta.AnalyzeSkillGaps(ctx, "running", []string{"SRE"}); !errors.Is(err, ErrJobNotCompleted) {
+			t.Errorf("error = %v, want ErrJobNotCompleted", err)
+		}
+		if _, err := ta.AnalyzeSkillGaps(ctx, "missing", []string{"SRE"}); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("error = %v, want ErrJobNotFound", err)
+		}
+		if _, err := ta.AnalyzeSkillGaps(ctx, jobID, nil); err == nil {
+			t.Error("no job descriptions accepted")
+		}
+		if _, err := ta.AnalyzeSkillGaps(ctx, jobID, make([]string, MaxSkillGapJobDescriptions+1)); err == nil {
+			t.Errorf("%d job descriptions accepted", MaxSkillGapJobDescriptions+1)
+		}
+	})
+}
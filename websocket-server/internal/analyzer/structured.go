@@ -0,0 +1,204 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// analysisToolName is the function the LLM is made to call with the analysis
+const analysisToolName = "record_resume_analysis"
+
+// analyzeStructured asks the LLM for the analysis as the arguments of a forced
+// analysisToolName call, so the JSON arrives without markdown or prose around it. A
+// provider that ignores the tool and answers in text has that text parsed like
+// GenerateFromSinglePrompt's; a provider rejecting the request returns its error, and
+// Analyze falls back to a plain text prompt if isUnsupportedToolsError says so.
+func (l *ExternalLLMClient) analyzeStructured(ctx context.Context, prompt string, pass AnalysisPass) (*AnalysisResponse, error) {
+	log.Printf("Calling OpenAI LLM for resume analysis (structured output)...")
+
+	messages := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)}
+	response, err := l.llm.GenerateContent(ctx, messages,
+		llms.WithTools([]llms.Tool{analysisTool(pass)}),
+		llms.WithToolChoice(llms.ToolChoice{
+			Type:     "function",
+			Function: &llms.FunctionReference{Name: analysisToolName},
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate structured LLM response: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from LLM")
+	}
+	choice := response.Choices[0]
+
+	if arguments, ok := analysisToolArguments(choice); ok {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(arguments), &fields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tool call arguments: %w", err)
+		}
+		return analysisFromFields(fields), nil
+	}
+
+	if choice.Content == "" {
+		return nil, fmt.Errorf("LLM returned neither a %s call nor text", analysisToolName)
+	}
+	log.Printf("LLM answered without calling %s, parsing text...", analysisToolName)
+	return parseAnalysisResponse(choice.Content)
+}
+
+// Phrases of the errors providers return for requests with tools they can't handle, e.g.
+// OpenAI's "Unrecognized request argument supplied: tools" or a local server's "tools
+// are not supported by this model". A message needs one of each list.
+var (
+	toolsErrorSubjects      = []string{"tool", "function"}
+	unsupportedErrorPhrases = []string{"not support", "unsupported", "unrecognized", "unknown parameter", "not implemented", "not permitted"}
+)
+
+// isUnsupportedToolsError reports whether err is a provider rejecting tool calling, as
+// opposed to a failure a plain text request would hit too (rate limits, timeouts,
+// outages) or a bad reply
+func isUnsupportedToolsError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if llms.IsNotImplementedError(err) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	containsAny := func(phrases []string) bool {
+		for _, phrase := range phrases {
+			if strings.Contains(message, phrase) {
+				return true
+			}
+		}
+		return false
+	}
+	return containsAny(toolsErrorSubjects) && containsAny(unsupportedErrorPhrases)
+}
+
+// analysisToolArguments returns the arguments of the choice's analysisToolName call
+func analysisToolArguments(choice *llms.ContentChoice) (string, bool) {
+	for _, call := range choice.ToolCalls {
+		if call.FunctionCall != nil && call.FunctionCall.Name == analysisToolName {
+			return call.FunctionCall.Arguments, true
+		}
+	}
+	if choice.FuncCall != nil && choice.FuncCall.Name == analysisToolName {
+		return choice.FuncCall.Arguments, true
+	}
+	return "", false
+}
+
+// analysisTool describes the fields extracted by a pass as a function the LLM calls, with
+// the same fields as the JSON schema in the analysis prompt
+func analysisTool(pass AnalysisPass) llms.Tool {
+	var fields []string
+	switch pass {
+	case AnalysisPassBasics:
+		fields = basicsFieldNames
+	case AnalysisPassDeep:
+		fields = deepFieldNames
+	default:
+		fields = models.ProfileFields
+	}
+
+	properties := make(map[string]any, len(fields)+1)
+	confidence := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if schema, ok := analysisFieldSchemas[field]; ok {
+			properties[field] = schema
+			confidence[field] = nullable("number")
+		}
+	}
+	properties["confidence"] = map[string]any{
+		"type":        "object",
+		"description": "Confidence from 0 to 1 in each extracted field, null when unsure",
+		"properties":  confidence,
+	}
+
+	return llms.Tool{
+		Type: "function",
+		Function: &llms.FunctionDefinition{
+			Name:        analysisToolName,
+			Description: "Record the information extracted from the resume. Use null for anything the resume doesn't state.",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": properties,
+			},
+		},
+	}
+}
+
+// analysisFieldSchemas is the JSON schema of each field of AnalysisResponse, keyed by
+// its name in the analysis JSON
+var analysisFieldSchemas = map[string]any{
+	"name":             nullable("string"),
+	"email":            nullable("string"),
+	"phone":            nullable("string"),
+	"linkedin_url":     nullable("string"),
+	"location":         nullable("string"),
+	"age":              nullable("integer"),
+	"race":             nullable("string"),
+	"total_work_years": nullable("number"),
+	"summary":          describe(nullable("string"), "Executive summary of the candidate's profile"),
+	"skills": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"technical": stringArray(),
+			"soft":      stringArray(),
+		},
+	},
+	"experience": map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"company":     map[string]any{"type": "string"},
+				"role":        map[string]any{"type": "string"},
+				"start_date":  describe(nullable("string"), "YYYY-MM or YYYY"),
+				"end_date":    describe(nullable("string"), "YYYY-MM, YYYY or Present"),
+				"years":       map[string]any{"type": "number"},
+				"description": describe(map[string]any{"type": "string"}, "Brief description"),
+			},
+		},
+	},
+	"education": map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"degree":      map[string]any{"type": "string"},
+				"institution": map[string]any{"type": "string"},
+				"year":        nullable("integer"),
+			},
+		},
+	},
+	"job_recommendations": stringArray(),
+	"strengths":           stringArray(),
+	"weaknesses":          describe(stringArray(), "Areas for improvement"),
+}
+
+// nullable is the schema of a value of the JSON type or null
+func nullable(jsonType string) map[string]any {
+	return map[string]any{"type": []string{jsonType, "null"}}
+}
+
+// stringArray is the schema of a list of strings
+func stringArray() map[string]any {
+	return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+}
+
+// describe adds a description to a schema
+func describe(schema map[string]any, description string) map[string]any {
+	schema["description"] = description
+	return schema
+}
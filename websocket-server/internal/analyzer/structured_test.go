@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestAnalyzeStructuredOutput(t *testing.T) {
+	const fencedJSON = "Here you go:\n```json\n{\"name\": \"Ada Lovelace\", \"total_work_years\": \"5+\"}\n```"
+	unsupported := errors.New("API returned unexpected status code: 400: Unrecognized request argument supplied: tools")
+
+	tests := []struct {
+		name       string
+		structured bool
+		withTools  func() (*llms.ContentResponse, error) // Reply to a request with the analysis tool
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "disabled",
+			structured: false,
+			wantCalls:  1,
+		},
+		{
+			name:       "tool call",
+			structured: true,
+			withTools: func() (*llms.ContentResponse, error) {
+				return toolReply(`{"name": "Ada Lovelace", "total_work_years": "5+"}`), nil
+			},
+			wantCalls: 1,
+		},
+		{
+			name:       "text instead of a tool call",
+			structured: true,
+			withTools:  func() (*llms.ContentResponse, error) { return textReply(fencedJSON), nil },
+			wantCalls:  1,
+		},
+		{
+			name:       "tools unsupported",
+			structured: true,
+			withTools:  func() (*llms.ContentResponse, error) { return nil, unsupported },
+			wantCalls:  2,
+		},
+		{
+			name:       "rate limited",
+			structured: true,
+			withTools: func() (*llms.ContentResponse, error) {
+				return nil, errors.New("API returned unexpected status code: 429: Rate limit reached")
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:       "malformed tool arguments",
+			structured: true,
+			withTools:  func() (*llms.ContentResponse, error) { return toolReply(`{"name": "Ada`), nil },
+			wantCalls:  1,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &scriptedModel{respond: func(prompt string, options llms.CallOptions) (*llms.ContentResponse, error) {
+				if len(options.Tools) == 0 {
+					return textReply(fencedJSON), nil
+				}
+				if tt.withTools == nil {
+					t.Error("analysis requested a tool call with structured output disabled")
+					return nil, unsupported
+				}
+				choice, ok := options.ToolChoice.(llms.ToolChoice)
+				if options.Tools[0].Function.Name != analysisToolName || !ok || choice.Function == nil || choice.Function.Name != analysisToolName {
+					t.Errorf("tools = %+v, choice = %+v; want the analysis tool forced", options.Tools, options.ToolChoice)
+				}
+				return tt.withTools()
+			}}
+			client := &ExternalLLMClient{llm: model, model: "test", structuredOutput: tt.structured}
+
+			got, err := client.Analyze(context.Background(), &AnalysisRequest{ResumeText: "Ada Lovelace, Go developer"})
+			if len(model.prompts) != tt.wantCalls {
+				t.Errorf("%d LLM calls, want %d", len(model.prompts), tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Analyze = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			wantString(t, "name", got.Name, "Ada Lovelace")
+			wantFloat(t, "total_work_years", got.TotalWorkYears, 5)
+		})
+	}
+}
+
+func TestIsUnsupportedToolsError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("API returned unexpected status code: 400: Unrecognized request argument supplied: tools"), true},
+		{errors.New("API returned unexpected status code: 400: tools is not supported by this model"), true},
+		{errors.New("API returned unexpected status code: 400: This model does not support function calling"), true},
+		{errors.New("API returned unexpected status code: 422: tool_choice: Extra inputs are not permitted"), true},
+		{fmt.Errorf("failed to generate structured LLM response: %w", llms.NewError(llms.ErrCodeNotImplemented, "openai", "not implemented")), true},
+		{errors.New("API returned unexpected status code: 429: Rate limit reached"), false},
+		{errors.New("API returned unexpected status code: 503: Service unavailable"), false},
+		{errors.New("API returned unexpected status code: 400: This model's maximum context length is 8192 tokens"), false},
+		{errors.New("failed to unmarshal tool call arguments: unexpected end of JSON input"), false},
+		{fmt.Errorf("calling tools: %w", context.DeadlineExceeded), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := isUnsupportedToolsError(tt.err); got != tt.want {
+				t.Errorf("isUnsupportedToolsError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SentenceTokenizer splits text into sentences for chunking
+type SentenceTokenizer interface {
+	Split(text string) []string
+}
+
+// DefaultAbbreviations lists common abbreviations in resumes that end with a period
+// but do not end a sentence. Entries are lowercase and omit the final period.
+var DefaultAbbreviations = []string{
+	// Titles and names
+	"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st",
+	// Degrees
+	"ph.d", "m.d", "b.sc", "m.sc", "b.eng", "m.eng", "m.b.a", "b.a", "m.a", "b.s", "m.s",
+	// Latin and common shorthand
+	"e.g", "i.e", "etc", "vs", "approx", "cf", "al",
+	// Organizations and places
+	"inc", "ltd", "co", "corp", "dept", "univ", "assn", "u.s", "u.k", "u.s.a",
+	// Numbering and dates
+	"no", "vol", "fig", "jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec",
+	"a.m", "p.m",
+}
+
+// SimpleSentenceTokenizer splits on sentence terminators followed by a capital letter.
+// It is fast but mis-splits abbreviations such as "U.S. Army".
+type SimpleSentenceTokenizer struct{}
+
+// NewSimpleSentenceTokenizer creates a simple sentence tokenizer
+func NewSimpleSentenceTokenizer() SentenceTokenizer {
+	return &SimpleSentenceTokenizer{}
+}
+
+// Split splits text into sentences
+func (t *SimpleSentenceTokenizer) Split(text string) []string {
+	return splitIntoSentences(text)
+}
+
+// AbbreviationTokenizer splits text into sentences without breaking on known
+// abbreviations, initials (e.g. "J. Smith", "U.S."), decimal numbers or list numbering
+type AbbreviationTokenizer struct {
+	abbreviations map[string]bool
+}
+
+// NewAbbreviationTokenizer creates an abbreviation-aware tokenizer.
+// If abbreviations is nil, DefaultAbbreviations is used.
+func NewAbbreviationTokenizer(abbreviations []string) SentenceTokenizer {
+	if abbreviations == nil {
+		abbreviations = DefaultAbbreviations
+	}
+
+	set := make(map[string]bool, len(abbreviations))
+	for _, abbr := range abbreviations {
+		abbr = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(abbr)), ".")
+		if abbr != "" {
+			set[abbr] = true
+		}
+	}
+
+	return &AbbreviationTokenizer{abbreviations: set}
+}
+
+// Split splits text into sentences
+func (t *AbbreviationTokenizer) Split(text string) []string {
+	var sentences []string
+	var currentSentence strings.Builder
+
+	flush := func() {
+		sentence := strings.TrimSpace(currentSentence.String())
+		if len(sentence) > 0 {
+			sentences = append(sentences, sentence)
+		}
+		currentSentence.Reset()
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		currentSentence.WriteRune(runes[i])
+
+		switch runes[i] {
+		case '\n':
+			flush()
+		case '!', '?':
+			if isSentenceBoundary(runes, i) {
+				flush()
+			}
+		case '.':
+			if isSentenceBoundary(runes, i) && !t.isNonTerminalPeriod(runes, i, currentSentence.String()) {
+				flush()
+			}
+		}
+	}
+
+	// Add remaining text as last sentence
+	flush()
+
+	return sentences
+}
+
+// isNonTerminalPeriod reports whether the period at runes[i] belongs to an abbreviation,
+// an initial or a list number rather than ending the sentence
+func (t *AbbreviationTokenizer) isNonTerminalPeriod(runes []rune, i int, sentence string) bool {
+	// Find the word that ends with this period
+	start := i
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+	word := strings.TrimLeftFunc(string(runes[start:i]), func(r rune) bool {
+		return r == '(' || r == '[' || r == '"' || r == '\''
+	})
+	if word == "" {
+		return false
+	}
+
+	lower := strings.ToLower(word)
+	if t.abbreviations[lower] {
+		return true
+	}
+
+	// Initials such as "J." or dotted acronyms such as "U.S" / "N.Y"
+	if isInitials(word) {
+		return true
+	}
+
+	// List numbering such as "1." at the start of a sentence
+	if strings.TrimSpace(sentence) == word+"." && isDigits(word) {
+		return true
+	}
+
+	return false
+}
+
+// isSentenceBoundary applies the basic boundary rule: the terminator is followed by
+// whitespace and then an uppercase letter, more whitespace, or the end of the text
+func isSentenceBoundary(runes []rune, i int) bool {
+	if i+1 >= len(runes) {
+		return true
+	}
+	if !unicode.IsSpace(runes[i+1]) {
+		return false
+	}
+	return i+2 >= len(runes) || unicode.IsUpper(runes[i+2]) || unicode.IsSpace(runes[i+2])
+}
+
+// isInitials reports whether word consists of single uppercase letters separated by periods (e.g. "J", "U.S")
+func isInitials(word string) bool {
+	for _, part := range strings.Split(word, ".") {
+		runes := []rune(part)
+		if len(runes) != 1 || !unicode.IsUpper(runes[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAbbreviationTokenizer(t *testing.T) {
+	tokenizer := NewAbbreviationTokenizer(nil)
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"degree", "He has a Ph.D. in CS.", []string{"He has a Ph.D. in CS."}},
+		{"degree before a capital", "She holds an M.Sc. Computer Science degree. She graduated in 2015.", []string{"She holds an M.Sc. Computer Science degree.", "She graduated in 2015."}},
+		{"decimal number", "Graduated with a 3.5 GPA. Dean's list.", []string{"Graduated with a 3.5 GPA.", "Dean's list."}},
+		{"country", "Served in the U.S. Army for four years.", []string{"Served in the U.S. Army for four years."}},
+		{"latin shorthand", "Used cloud tools, e.g. AWS and GCP. Led migrations.", []string{"Used cloud tools, e.g. AWS and GCP.", "Led migrations."}},
+		{"title", "Reported to Dr. Smith. Promoted twice.", []string{"Reported to Dr. Smith.", "Promoted twice."}},
+		{"initials", "Mentored by J. R. Tolkien. Wrote docs.", []string{"Mentored by J. R. Tolkien.", "Wrote docs."}},
+		{"list numbering", "1. Built APIs. Shipped features.", []string{"1. Built APIs.", "Shipped features."}},
+		{"company suffix", "Worked at Acme Inc. Before that, freelancing.", []string{"Worked at Acme Inc. Before that, freelancing."}},
+		{"other terminators", "Ready? Yes! Let's go.", []string{"Ready?", "Yes!", "Let's go."}},
+		{"newlines", "Skills\nGo, SQL", []string{"Skills", "Go, SQL"}},
+		{"empty", "   ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenizer.Split(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbbreviationTokenizerCustomList(t *testing.T) {
+	tests := []struct {
+		name          string
+		abbreviations []string
+		want          []string
+	}{
+		{"default list", nil, []string{"Joined Acme Corp. Later Globex."}},
+		{"custom list", []string{"Globex."}, []string{"Joined Acme Corp.", "Later Globex."}},
+		{"custom entries normalized", []string{" CORP. "}, []string{"Joined Acme Corp. Later Globex."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAbbreviationTokenizer(tt.abbreviations).Split("Joined Acme Corp. Later Globex.")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimpleSentenceTokenizer(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"sentences", "Built APIs. Shipped features.", []string{"Built APIs.", "Shipped features."}},
+		{"decimal number", "Graduated with a 3.5 GPA.", []string{"Graduated with a 3.5 GPA."}},
+		{"splits abbreviations", "Served in the U.S. Army.", []string{"Served in the U.S.", "Army."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSimpleSentenceTokenizer().Split(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkerUsesTokenizer(t *testing.T) {
+	text := "He has a Ph.D. Physics background. He taught at MIT."
+
+	tests := []struct {
+		name      string
+		tokenizer SentenceTokenizer
+		want      []string
+	}{
+		{"abbreviation aware", NewAbbreviationTokenizer(nil), []string{"He has a Ph.D. Physics background.", "He taught at MIT."}},
+		{"simple", NewSimpleSentenceTokenizer(), []string{"He has a Ph.D.", "Physics background.", "He taught at MIT."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTextChunkerWithTokenizer(tt.tokenizer).ChunkText(text, 20, 0)
+			if err != nil {
+				t.Fatalf("ChunkText: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChunkText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
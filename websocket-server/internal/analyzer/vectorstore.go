@@ -2,7 +2,12 @@ package analyzer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"sort"
+	"sync"
 
 	// chroma "github.com/amikos-tech/chroma-go" // Commented out - API pending
 	_ "github.com/amikos-tech/chroma-go" // Keep import for future use
@@ -35,11 +40,12 @@ func NewChromaVectorStore(host string, port int) (VectorStore, error) {
 		return nil, fmt.Errorf("failed to create ChromaDB client: %w", err)
 	}
 
-	// Get or create collection
+	// Get or create collection. Chroma defaults to L2 distance; search scores are
+	// 1 - distance, which is only a similarity for cosine distance.
 	collection, err := client.CreateCollection(
 		context.Background(),
 		CollectionName,
-		nil,  // metadata
+		map[string]interface{}{"hnsw:space": "cosine"}, // metadata
 		true, // getOrCreate
 		nil,  // embedding function
 		nil,  // distance function
@@ -57,7 +63,7 @@ func NewChromaVectorStore(host string, port int) (VectorStore, error) {
 
 // StoreEmbeddings stores embeddings with metadata in the vector database
 // TODO: Complete when ChromaDB client is integrated
-func (v *ChromaVectorStore) StoreEmbeddings(ctx context.Context, uploadID int, chunks []string, embeddings [][]float32) error {
+func (v *ChromaVectorStore) StoreEmbeddings(ctx context.Context, tenantID string, uploadID int, chunks []string, embeddings [][]float32, metadata []ChunkMetadata) error {
 	return fmt.Errorf("ChromaVectorStore methods not yet implemented - use PlaceholderVectorStore")
 	/*
 	if len(chunks) != len(embeddings) {
@@ -81,9 +87,13 @@ func (v *ChromaVectorStore) StoreEmbeddings(ctx context.Context, uploadID int, c
 
 		// Add metadata
 		metadatas[i] = map[string]interface{}{
-			"upload_id":  uploadID,
+			"tenant_id":   tenantID,
+			"upload_id":   uploadID,
 			"chunk_index": i,
 		}
+		if metadata != nil {
+			metadatas[i]["section"] = metadata[i].Section
+		}
 
 		// Convert float32 to float64 for ChromaDB
 		embeddingsFloat64[i] = make([]float64, len(embeddings[i]))
@@ -108,9 +118,17 @@ func (v *ChromaVectorStore) StoreEmbeddings(ctx context.Context, uploadID int, c
 	*/
 }
 
+// LookupEmbeddings returns stored embeddings by chunk hash
+// TODO: Complete when ChromaDB client is integrated (store the hash as the record ID,
+// filtering on the tenant_id metadata)
+func (v *ChromaVectorStore) LookupEmbeddings(ctx context.Context, tenantID string, hashes []string) (map[string][]float32, error) {
+	return nil, fmt.Errorf("ChromaVectorStore methods not yet implemented - use PlaceholderVectorStore")
+}
+
 // SearchSimilar finds similar vectors using cosine similarity
-// TODO: Complete when ChromaDB client is integrated
-func (v *ChromaVectorStore) SearchSimilar(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+// TODO: Complete when ChromaDB client is integrated (filter.Sections becomes a
+// {"section": {"$in": [...]}} where clause)
+func (v *ChromaVectorStore) SearchSimilar(ctx context.Context, tenantID string, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
 	return nil, fmt.Errorf("ChromaVectorStore methods not yet implemented - use PlaceholderVectorStore")
 	/*
 	if query == "" {
@@ -127,7 +145,7 @@ func (v *ChromaVectorStore) SearchSimilar(ctx context.Context, query string, lim
 		ctx,
 		[]string{query},
 		int32(limit),
-		nil, // where filter
+		map[string]interface{}{"tenant_id": tenantID}, // where filter: never search other tenants
 		nil, // where document filter
 		nil, // include fields (default: documents, metadatas, distances)
 	)
@@ -156,10 +174,11 @@ func (v *ChromaVectorStore) SearchSimilar(ctx context.Context, query string, lim
 						}
 					}
 
-					// Get distance/score
+					// The collection uses cosine distance (1 - cosine similarity, see
+					// NewChromaVectorStore); convert it back so higher is better
 					score := float32(0.0)
 					if len(queryResult.Distances) > i && len(queryResult.Distances[i]) > j {
-						score = float32(queryResult.Distances[i][j])
+						score = 1 - float32(queryResult.Distances[i][j])
 					}
 
 					results = append(results, SearchResult{
@@ -176,6 +195,12 @@ func (v *ChromaVectorStore) SearchSimilar(ctx context.Context, query string, lim
 	*/
 }
 
+// SearchSimilarInUpload finds similar vectors restricted to a single upload
+// TODO: Complete when ChromaDB client is integrated (use a tenant_id and upload_id where filter)
+func (v *ChromaVectorStore) SearchSimilarInUpload(ctx context.Context, tenantID string, uploadID int, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	return nil, fmt.Errorf("ChromaVectorStore methods not yet implemented - use PlaceholderVectorStore")
+}
+
 // DeleteByUploadID removes all embeddings associated with an upload
 // TODO: Complete when ChromaDB client is integrated
 func (v *ChromaVectorStore) DeleteByUploadID(ctx context.Context, uploadID int) error {
@@ -202,35 +227,60 @@ func (v *ChromaVectorStore) DeleteByUploadID(ctx context.Context, uploadID int)
 
 // PlaceholderVectorStore is a placeholder implementation for testing
 type PlaceholderVectorStore struct {
-	store map[int][]string // uploadID -> chunks
+	store    map[int][]string        // uploadID -> chunks
+	metadata map[int][]ChunkMetadata // uploadID -> metadata of each chunk
+	tenants  map[int]string          // uploadID -> tenant ID
 }
 
 // NewPlaceholderVectorStore creates a placeholder vector store
 func NewPlaceholderVectorStore() VectorStore {
 	return &PlaceholderVectorStore{
-		store: make(map[int][]string),
+		store:    make(map[int][]string),
+		metadata: make(map[int][]ChunkMetadata),
+		tenants:  make(map[int]string),
 	}
 }
 
 // StoreEmbeddings stores chunks in memory (placeholder)
-func (v *PlaceholderVectorStore) StoreEmbeddings(ctx context.Context, uploadID int, chunks []string, embeddings [][]float32) error {
+func (v *PlaceholderVectorStore) StoreEmbeddings(ctx context.Context, tenantID string, uploadID int, chunks []string, embeddings [][]float32, metadata []ChunkMetadata) error {
+	if metadata == nil {
+		metadata = make([]ChunkMetadata, len(chunks))
+	}
+	if len(metadata) != len(chunks) {
+		return fmt.Errorf("chunks and metadata length mismatch: %d vs %d", len(chunks), len(metadata))
+	}
 	v.store[uploadID] = chunks
+	v.metadata[uploadID] = metadata
+	v.tenants[uploadID] = tenantID
 	return nil
 }
 
-// SearchSimilar returns placeholder results
-func (v *PlaceholderVectorStore) SearchSimilar(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+// LookupEmbeddings returns no embeddings, since the placeholder store keeps none
+func (v *PlaceholderVectorStore) LookupEmbeddings(ctx context.Context, tenantID string, hashes []string) (map[string][]float32, error) {
+	return map[string][]float32{}, nil
+}
+
+// SearchSimilar returns placeholder results from the tenant's uploads
+func (v *PlaceholderVectorStore) SearchSimilar(ctx context.Context, tenantID string, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
 	var results []SearchResult
 	count := 0
 
 	for uploadID, chunks := range v.store {
-		for _, chunk := range chunks {
+		if v.tenants[uploadID] != tenantID {
+			continue
+		}
+		for i, chunk := range chunks {
 			if count >= limit {
 				break
 			}
+			metadata := v.metadata[uploadID][i]
+			if !filter.matches(metadata) {
+				continue
+			}
 			results = append(results, SearchResult{
 				UploadID: uploadID,
 				Chunk:    chunk,
+				Section:  metadata.Section,
 				Score:    0.9,
 			})
 			count++
@@ -243,8 +293,274 @@ func (v *PlaceholderVectorStore) SearchSimilar(ctx context.Context, query string
 	return results, nil
 }
 
+// SearchSimilarInUpload returns placeholder results for a single upload of the tenant
+func (v *PlaceholderVectorStore) SearchSimilarInUpload(ctx context.Context, tenantID string, uploadID int, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	var results []SearchResult
+	if v.tenants[uploadID] != tenantID {
+		return results, nil
+	}
+	for i, chunk := range v.store[uploadID] {
+		if len(results) >= limit {
+			break
+		}
+		metadata := v.metadata[uploadID][i]
+		if !filter.matches(metadata) {
+			continue
+		}
+		results = append(results, SearchResult{
+			UploadID: uploadID,
+			Chunk:    chunk,
+			Section:  metadata.Section,
+			Score:    0.9,
+		})
+	}
+
+	return results, nil
+}
+
 // DeleteByUploadID removes chunks from memory
 func (v *PlaceholderVectorStore) DeleteByUploadID(ctx context.Context, uploadID int) error {
 	delete(v.store, uploadID)
+	delete(v.metadata, uploadID)
+	delete(v.tenants, uploadID)
 	return nil
 }
+
+// ChunkHash returns the content hash that identifies a chunk in the vector store
+func ChunkHash(chunk string) string {
+	sum := sha256.Sum256([]byte(chunk))
+	return hex.EncodeToString(sum[:])
+}
+
+// storedChunk holds a single chunk with its embedding
+type storedChunk struct {
+	Hash      string
+	Chunk     string
+	Embedding []float32
+	Metadata  ChunkMetadata
+}
+
+// tenantHash identifies a shared embedding: embeddings are only shared within a tenant
+type tenantHash struct {
+	tenantID string
+	hash     string
+}
+
+// hashedEmbedding is an embedding shared by every stored chunk of a tenant with the same hash
+type hashedEmbedding struct {
+	embedding []float32
+	refs      int // Stored chunks using the embedding
+}
+
+// InMemoryVectorStore keeps embeddings in memory and ranks them by cosine similarity.
+// It is safe for concurrent use and suitable for tests and small single-node deployments.
+type InMemoryVectorStore struct {
+	embedder EmbeddingGenerator
+	store    map[int][]storedChunk           // uploadID -> chunks with embeddings
+	tenants  map[int]string                  // uploadID -> tenant ID
+	byHash   map[tenantHash]*hashedEmbedding // chunk hash -> embedding, across the tenant's uploads
+	mu       sync.RWMutex
+}
+
+// NewInMemoryVectorStore creates an in-memory vector store that embeds queries with the given embedder
+func NewInMemoryVectorStore(embedder EmbeddingGenerator) (VectorStore, error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("embedder is required for in-memory vector store")
+	}
+
+	return &InMemoryVectorStore{
+		embedder: embedder,
+		store:    make(map[int][]storedChunk),
+		tenants:  make(map[int]string),
+		byHash:   make(map[tenantHash]*hashedEmbedding),
+	}, nil
+}
+
+// StoreEmbeddings stores chunks and their embeddings under the tenant, replacing any previous
+// entries for the upload. A chunk whose hash is already stored (in any upload of the tenant)
+// shares the stored vector instead of keeping another copy, and repeated chunks within the
+// upload are stored once, with the metadata of their first occurrence.
+func (v *InMemoryVectorStore) StoreEmbeddings(ctx context.Context, tenantID string, uploadID int, chunks []string, embeddings [][]float32, metadata []ChunkMetadata) error {
+	if len(chunks) != len(embeddings) {
+		return fmt.Errorf("chunks and embeddings length mismatch: %d vs %d", len(chunks), len(embeddings))
+	}
+
+	if metadata != nil && len(metadata) != len(chunks) {
+		return fmt.Errorf("chunks and metadata length mismatch: %d vs %d", len(chunks), len(metadata))
+	}
+
+	if len(chunks) == 0 {
+		return fmt.Errorf("no chunks to store")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Release the upload's previous entries first so unchanged chunks keep their vector
+	previousTenant, previous := v.tenants[uploadID], v.store[uploadID]
+	delete(v.store, uploadID)
+	defer v.release(previousTenant, previous)
+
+	entries := make([]storedChunk, 0, len(chunks))
+	seen := make(map[string]bool, len(chunks))
+	for i, chunk := range chunks {
+		hash := ChunkHash(chunk)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		key := tenantHash{tenantID: tenantID, hash: hash}
+		shared, ok := v.byHash[key]
+		if !ok {
+			// Copy the embedding so callers can't mutate stored vectors
+			embedding := make([]float32, len(embeddings[i]))
+			copy(embedding, embeddings[i])
+
+			shared = &hashedEmbedding{embedding: embedding}
+			v.byHash[key] = shared
+		}
+		shared.refs++
+
+		entry := storedChunk{
+			Hash:      hash,
+			Chunk:     chunk,
+			Embedding: shared.embedding,
+		}
+		if metadata != nil {
+			entry.Metadata = metadata[i]
+		}
+		entries = append(entries, entry)
+	}
+	v.store[uploadID] = entries
+	v.tenants[uploadID] = tenantID
+
+	return nil
+}
+
+// LookupEmbeddings returns copies of the tenant's stored embeddings of the given chunk hashes
+func (v *InMemoryVectorStore) LookupEmbeddings(ctx context.Context, tenantID string, hashes []string) (map[string][]float32, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	found := make(map[string][]float32)
+	for _, hash := range hashes {
+		if shared, ok := v.byHash[tenantHash{tenantID: tenantID, hash: hash}]; ok {
+			embedding := make([]float32, len(shared.embedding))
+			copy(embedding, shared.embedding)
+			found[hash] = embedding
+		}
+	}
+
+	return found, nil
+}
+
+// SearchSimilar finds the chunks most similar to the query across the tenant's uploads
+func (v *InMemoryVectorStore) SearchSimilar(ctx context.Context, tenantID string, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	return v.search(ctx, query, limit, filter, &tenantID, nil)
+}
+
+// SearchSimilarInUpload finds the chunks most similar to the query within a single upload of the tenant
+func (v *InMemoryVectorStore) SearchSimilarInUpload(ctx context.Context, tenantID string, uploadID int, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	return v.search(ctx, query, limit, filter, &tenantID, &uploadID)
+}
+
+// DeleteByUploadID removes all embeddings associated with an upload
+func (v *InMemoryVectorStore) DeleteByUploadID(ctx context.Context, uploadID int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.release(v.tenants[uploadID], v.store[uploadID])
+	delete(v.store, uploadID)
+	delete(v.tenants, uploadID)
+	return nil
+}
+
+// release drops the references of a tenant's entries to their shared embeddings, removing
+// embeddings no stored chunk uses any more. The caller must hold the write lock.
+func (v *InMemoryVectorStore) release(tenantID string, entries []storedChunk) {
+	for _, entry := range entries {
+		key := tenantHash{tenantID: tenantID, hash: entry.Hash}
+		if shared, ok := v.byHash[key]; ok {
+			shared.refs--
+			if shared.refs <= 0 {
+				delete(v.byHash, key)
+			}
+		}
+	}
+}
+
+// search embeds the query and ranks the stored chunks matching filter by cosine similarity.
+// If tenantID or uploadID is non-nil, only chunks of that tenant or upload are considered.
+func (v *InMemoryVectorStore) search(ctx context.Context, query string, limit int, filter SearchFilter, tenantID *string, uploadID *int) ([]SearchResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	if limit <= 0 {
+		limit = 5
+	}
+
+	// Embed the query before taking the lock - this may be a slow API call
+	queryEmbedding, err := v.embedder.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	v.mu.RLock()
+	var results []SearchResult
+	for id, entries := range v.store {
+		if uploadID != nil && id != *uploadID {
+			continue
+		}
+		if tenantID != nil && v.tenants[id] != *tenantID {
+			continue
+		}
+		for _, entry := range entries {
+			if !filter.matches(entry.Metadata) {
+				continue
+			}
+			results = append(results, SearchResult{
+				UploadID: id,
+				Chunk:    entry.Chunk,
+				Section:  entry.Metadata.Section,
+				Score:    cosineSimilarity(queryEmbedding, entry.Embedding),
+			})
+		}
+	}
+	v.mu.RUnlock()
+
+	// Highest score first; break ties by upload ID for deterministic output
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].UploadID < results[j].UploadID
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// cosineSimilarity calculates cosine similarity between two embeddings
+// Returns 0 for mismatched dimensions or zero vectors
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dotProduct, normA, normB float64
+	for i := range a {
+		dotProduct += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return float32(dotProduct / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,375 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float32
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"45 degrees", []float32{1, 0}, []float32{1, 1}, float32(1 / math.Sqrt2)},
+		{"mismatched dimensions", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+		{"empty", nil, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); math.Abs(float64(got-tt.want)) > 1e-6 {
+				t.Errorf("cosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreSearch(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"go developer":    {1, 0, 0},
+		"golang backend":  {0.9, 0.1, 0},
+		"python analyst":  {0.1, 0.9, 0},
+		"accounting":      {0, 0, 1},
+		"backend roles":   {1, 0, 0},
+		"numbers and tax": {0, 0.2, 1},
+	}}
+	store := newTestVectorStore(t, embedder)
+	ctx := context.Background()
+
+	if err := store.StoreEmbeddings(ctx, "", 1, []string{"go developer", "python analyst"},
+		[][]float32{embedder.vectors["go developer"], embedder.vectors["python analyst"]}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+	if err := store.StoreEmbeddings(ctx, "", 2, []string{"golang backend", "accounting"},
+		[][]float32{embedder.vectors["golang backend"], embedder.vectors["accounting"]}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		limit    int
+		uploadID *int
+		want     []string // Chunks, most similar first
+	}{
+		{"ranked by similarity", "backend roles", 4, nil, []string{"go developer", "golang backend", "python analyst", "accounting"}},
+		{"limited", "backend roles", 2, nil, []string{"go developer", "golang backend"}},
+		{"other query", "numbers and tax", 1, nil, []string{"accounting"}},
+		{"default limit", "backend roles", 0, nil, []string{"go developer", "golang backend", "python analyst", "accounting"}},
+		{"within upload", "backend roles", 4, intPtr(2), []string{"golang backend", "accounting"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []SearchResult
+			var err error
+			if tt.uploadID != nil {
+				results, err = store.SearchSimilarInUpload(ctx, "", *tt.uploadID, tt.query, tt.limit, SearchFilter{})
+			} else {
+				results, err = store.SearchSimilar(ctx, "", tt.query, tt.limit, SearchFilter{})
+			}
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d: %+v", len(results), len(tt.want), results)
+			}
+			for i, result := range results {
+				if result.Chunk != tt.want[i] {
+					t.Errorf("result %d = %q, want %q", i, result.Chunk, tt.want[i])
+				}
+				if i > 0 && result.Score > results[i-1].Score {
+					t.Errorf("result %d scores %v, above the previous %v", i, result.Score, results[i-1].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreSearchScore(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{"query": {1, 0}}}
+	store := newTestVectorStore(t, embedder)
+	ctx := context.Background()
+
+	if err := store.StoreEmbeddings(ctx, "", 1, []string{"same", "orthogonal"}, [][]float32{{2, 0}, {0, 3}}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+
+	results, err := store.SearchSimilar(ctx, "", "query", 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if len(results) != 2 || results[0].UploadID != 1 || results[0].Score != 1 || results[1].Score != 0 {
+		t.Errorf("results = %+v, want scores 1 and 0 for upload 1", results)
+	}
+}
+
+func TestInMemoryVectorStoreErrors(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{"known": {1}}}
+	ctx := context.Background()
+
+	if _, err := NewInMemoryVectorStore(nil); err == nil {
+		t.Error("NewInMemoryVectorStore(nil) succeeded")
+	}
+
+	store := newTestVectorStore(t, embedder)
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"length mismatch", func() error {
+			return store.StoreEmbeddings(ctx, "", 1, []string{"a", "b"}, [][]float32{{1}}, nil)
+		}},
+		{"metadata length mismatch", func() error {
+			return store.StoreEmbeddings(ctx, "", 1, []string{"a"}, [][]float32{{1}}, []ChunkMetadata{{}, {}})
+		}},
+		{"no chunks", func() error {
+			return store.StoreEmbeddings(ctx, "", 1, nil, nil, nil)
+		}},
+		{"empty query", func() error {
+			_, err := store.SearchSimilar(ctx, "", "", 5, SearchFilter{})
+			return err
+		}},
+		{"query embedding fails", func() error {
+			_, err := store.SearchSimilar(ctx, "", "unknown", 5, SearchFilter{})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil {
+				t.Error("succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreReplaceAndDelete(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{"query": {1, 0}}}
+	store := newTestVectorStore(t, embedder)
+	ctx := context.Background()
+
+	if err := store.StoreEmbeddings(ctx, "", 1, []string{"old"}, [][]float32{{1, 0}}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+	if err := store.StoreEmbeddings(ctx, "", 1, []string{"new"}, [][]float32{{1, 0}}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+
+	results, err := store.SearchSimilar(ctx, "", "query", 5, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk != "new" {
+		t.Errorf("results after replacing = %+v, want only the new chunk", results)
+	}
+
+	if err := store.DeleteByUploadID(ctx, 1); err != nil {
+		t.Fatalf("DeleteByUploadID: %v", err)
+	}
+	results, err = store.SearchSimilar(ctx, "", "query", 5, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchSimilar: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("results after deleting = %+v, want none", results)
+	}
+}
+
+func TestInMemoryVectorStoreSharesEmbeddingsByHash(t *testing.T) {
+	store := newTestVectorStore(t, &stubEmbedder{})
+	ctx := context.Background()
+
+	// Repeated chunks within an upload are stored once
+	if err := store.StoreEmbeddings(ctx, "acme", 1, []string{"go", "sql", "go"}, [][]float32{{1, 0}, {0, 1}, {1, 0}}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+	if got := len(store.store[1]); got != 2 {
+		t.Errorf("upload 1 has %d chunks, want 2", got)
+	}
+
+	// Storing a known chunk again, even with another vector, reuses the stored one
+	if err := store.StoreEmbeddings(ctx, "acme", 2, []string{"go"}, [][]float32{{0.5, 0.5}}, nil); err != nil {
+		t.Fatalf("StoreEmbeddings: %v", err)
+	}
+	if got := len(store.byHash); got != 2 {
+		t.Errorf("%d vectors stored, want 2", got)
+	}
+	if got := store.byHash[tenantHash{tenantID: "acme", hash: ChunkHash("go")}].refs; got != 2 {
+		t.Errorf("shared vector has %d references, want 2", got)
+	}
+
+	found, err := store.LookupEmbeddings(ctx, "acme", []string{ChunkHash("go"), ChunkHash("sql"), ChunkHash("rust")})
+	if err != nil {
+		t.Fatalf("LookupEmbeddings: %v", err)
+	}
+	want := map[string][]float32{ChunkHash("go"): {1, 0}, ChunkHash("sql"): {0, 1}}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("lookup = %v, want %v", found, want)
+	}
+
+	// Returned vectors are copies
+	found[ChunkHash("go")][0] = 9
+	if again, _ := store.LookupEmbeddings(ctx, "acme", []string{ChunkHash("go")}); again[ChunkHash("go")][0] != 1 {
+		t.Error("mutating a looked up embedding changed the stored vector")
+	}
+
+	// Other tenants don't see the tenant's vectors
+	if found, _ := store.LookupEmbeddings(ctx, "globex", []string{ChunkHash("go")}); len(found) != 0 {
+		t.Errorf("lookup for another tenant = %v, want none", found)
+	}
+
+	// A vector is dropped once no upload uses it
+	if err := store.DeleteByUploadID(ctx, 1); err != nil {
+		t.Fatalf("DeleteByUploadID: %v", err)
+	}
+	found, _ = store.LookupEmbeddings(ctx, "acme", []string{ChunkHash("go"), ChunkHash("sql")})
+	if _, ok := found[ChunkHash("go")]; !ok || len(found) != 1 {
+		t.Errorf("lookup after deleting upload 1 = %v, want only the chunk upload 2 still has", found)
+	}
+	if err := store.DeleteByUploadID(ctx, 2); err != nil {
+		t.Fatalf("DeleteByUploadID: %v", err)
+	}
+	if len(store.byHash) != 0 {
+		t.Errorf("%d vectors left after deleting every upload", len(store.byHash))
+	}
+}
+
+func TestVectorStoreTenantIsolation(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"go developer":   {1, 0},
+		"golang backend": {0.9, 0.1},
+		"backend roles":  {1, 0},
+	}}
+	ctx := context.Background()
+
+	stores := map[string]VectorStore{
+		"in memory":   newTestVectorStore(t, embedder),
+		"placeholder": NewPlaceholderVectorStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.StoreEmbeddings(ctx, "acme", 1, []string{"go developer"}, [][]float32{embedder.vectors["go developer"]}, nil); err != nil {
+				t.Fatalf("StoreEmbeddings: %v", err)
+			}
+			if err := store.StoreEmbeddings(ctx, "globex", 2, []string{"golang backend"}, [][]float32{embedder.vectors["golang backend"]}, nil); err != nil {
+				t.Fatalf("StoreEmbeddings: %v", err)
+			}
+
+			tests := []struct {
+				name     string
+				tenantID string
+				uploadID *int
+				want     []int // Upload IDs of the results
+			}{
+				{"same tenant", "acme", nil, []int{1}},
+				{"other tenant", "globex", nil, []int{2}},
+				{"tenant without uploads", "initech", nil, nil},
+				{"default tenant", "", nil, nil},
+				{"own upload", "acme", intPtr(1), []int{1}},
+				{"other tenant's upload", "acme", intPtr(2), nil},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					var results []SearchResult
+					var err error
+					if tt.uploadID != nil {
+						results, err = store.SearchSimilarInUpload(ctx, tt.tenantID, *tt.uploadID, "backend roles", 10, SearchFilter{})
+					} else {
+						results, err = store.SearchSimilar(ctx, tt.tenantID, "backend roles", 10, SearchFilter{})
+					}
+					if err != nil {
+						t.Fatalf("search: %v", err)
+					}
+
+					var got []int
+					for _, result := range results {
+						got = append(got, result.UploadID)
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("results from uploads %v, want %v", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestVectorStoreSectionFilter(t *testing.T) {
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"Go at Acme":   {1, 0},
+		"Go, SQL":      {0.9, 0.1},
+		"Jane Doe":     {0, 1},
+		"BSc, MIT":     {0.5, 0.5},
+		"backend work": {1, 0},
+	}}
+	ctx := context.Background()
+
+	stores := map[string]VectorStore{
+		"in memory":   newTestVectorStore(t, embedder),
+		"placeholder": NewPlaceholderVectorStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			chunks := []string{"Go at Acme", "Go, SQL", "Jane Doe"}
+			metadata := []ChunkMetadata{{Section: SectionExperience}, {Section: SectionSkills}, {}}
+			embeddings := [][]float32{embedder.vectors["Go at Acme"], embedder.vectors["Go, SQL"], embedder.vectors["Jane Doe"]}
+			if err := store.StoreEmbeddings(ctx, "acme", 1, chunks, embeddings, metadata); err != nil {
+				t.Fatalf("StoreEmbeddings: %v", err)
+			}
+			// Chunks stored without metadata have no section
+			if err := store.StoreEmbeddings(ctx, "acme", 2, []string{"BSc, MIT"}, [][]float32{embedder.vectors["BSc, MIT"]}, nil); err != nil {
+				t.Fatalf("StoreEmbeddings: %v", err)
+			}
+
+			tests := []struct {
+				name     string
+				sections []string
+				uploadID *int
+				want     map[string]string // Chunk -> section of the results
+			}{
+				{"no filter", nil, nil, map[string]string{"Go at Acme": SectionExperience, "Go, SQL": SectionSkills, "Jane Doe": "", "BSc, MIT": ""}},
+				{"one section", []string{SectionExperience}, nil, map[string]string{"Go at Acme": SectionExperience}},
+				{"several sections", []string{SectionSkills, SectionExperience}, nil, map[string]string{"Go at Acme": SectionExperience, "Go, SQL": SectionSkills}},
+				{"section without chunks", []string{SectionEducation}, nil, map[string]string{}},
+				{"within upload", []string{SectionSkills}, intPtr(1), map[string]string{"Go, SQL": SectionSkills}},
+				{"upload without sections", []string{SectionSkills}, intPtr(2), map[string]string{}},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					filter := SearchFilter{Sections: tt.sections}
+					var results []SearchResult
+					var err error
+					if tt.uploadID != nil {
+						results, err = store.SearchSimilarInUpload(ctx, "acme", *tt.uploadID, "backend work", 10, filter)
+					} else {
+						results, err = store.SearchSimilar(ctx, "acme", "backend work", 10, filter)
+					}
+					if err != nil {
+						t.Fatalf("search: %v", err)
+					}
+
+					got := make(map[string]string)
+					for _, result := range results {
+						got[result.Chunk] = result.Section
+					}
+					if !reflect.DeepEqual(got, tt.want) {
+						t.Errorf("results = %v, want %v", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
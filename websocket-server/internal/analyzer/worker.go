@@ -2,34 +2,136 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/internal/repository"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
+var (
+	// ErrUploadNotFound is returned when an analysis references an upload that does not exist
+	ErrUploadNotFound = errors.New("upload not found")
+
+	// ErrUploadNotOwned is returned when an analysis references an upload belonging to another user
+	ErrUploadNotOwned = errors.New("upload does not belong to user")
+
+	// ErrJobNotFound is returned when an operation references an analysis job that can't be loaded
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobNotCompleted is returned when the results of a job that has not completed are
+	// requested or reanalyzed
+	ErrJobNotCompleted = errors.New("job is not completed")
+
+	// ErrQueueFull is returned when a job is submitted while the queue is over its high-water mark
+	ErrQueueFull = errors.New("analysis queue is full")
+
+	// ErrRetryLimitReached is returned when a job that has used all of its retries is retried again
+	ErrRetryLimitReached = errors.New("job retry limit reached")
+)
+
 // DefaultResumeAnalyzer implements the ResumeAnalyzer interface
 type DefaultResumeAnalyzer struct {
-	uploadRepo    repository.UploadRepository
-	analysisRepo  repository.AnalysisRepository
-	extractor     TextExtractor
-	chunker       TextChunker
-	embedder      EmbeddingGenerator
-	vectorStore   VectorStore
-	llmClient     LLMClient
-	workerPool    chan struct{} // Semaphore for limiting concurrent jobs
+	uploadRepo   repository.UploadRepository
+	analysisRepo repository.AnalysisRepository
+	extractor    TextExtractor
+	chunker      TextChunker
+	embedder     EmbeddingGenerator
+	vectorStore  VectorStore
+	llmClient    LLMClient
+	workerPool   chan struct{} // Semaphore for limiting concurrent jobs
+	queued       atomic.Int64  // Jobs started but waiting for a worker slot
+	maxQueued    int           // Queued jobs allowed before new ones are rejected (0 = unlimited)
+	chunkSize    int
+	chunkOverlap int
+	maxChunks    int // Maximum chunks embedded per document (0 = unlimited)
+	syncMaxBytes int // Largest total upload size AnalyzeSync waits for
+	maxRetries   int // Retries allowed before a failing job is dead-lettered
+
+	retrievalLimit    int     // Chunks retrieved for the analysis prompt
+	minRelevanceScore float64 // Similarity below which retrieved chunks are dropped (0 = disabled)
+
+	minQualityScore  float64 // Extraction quality threshold (0 = disabled)
+	minTextLength    int     // Non-whitespace characters required before analysis
+	lowQualityAction string  // QualityActionFail or QualityActionFlag
+	analysisMode     string  // AnalysisModeSingle or AnalysisModeTwoPass
+	experienceMerge  ExperienceMergeRules
+	notifier         JobNotifier
+	clock            clock.Clock
+
+	mu      sync.Mutex
+	running map[string]*runningJob // Jobs queued or processing on this analyzer, by job ID
+
+	similar *similarCache // Precomputed similar profiles, see GetSimilarProfiles
+}
+
+// runningJob lets a job's worker be stopped
+type runningJob struct {
+	cancel context.CancelCauseFunc
+	done   chan struct{} // Closed once the worker has exited
 }
 
+// CancelledJobMessage is the error message of a job stopped with CancelJob
+const CancelledJobMessage = "Cancelled"
+
+// errJobCancelled is the cause of a job context cancelled by CancelJob
+var errJobCancelled = errors.New("job cancelled")
+
+// Modes for the LLM analysis step
+const (
+	AnalysisModeSingle  = "single"   // One LLM call extracts the whole profile
+	AnalysisModeTwoPass = "two_pass" // A fast basics pass is stored before a deep pass
+)
+
 // Config holds configuration for the analyzer
 type Config struct {
-	ChunkSize       int
-	ChunkOverlap    int
+	ChunkSize         int
+	ChunkOverlap      int
 	MaxConcurrentJobs int
+	MaxChunks         int         // Longer documents are sampled down to this many chunks (0 = unlimited)
+	MinQualityScore   float64     // Extracted text scoring below this is not analyzed (0 = disabled)
+	MinTextLength     *int        // Jobs extracting fewer non-whitespace characters fail early (nil = DefaultMinTextLength, 0 = disabled)
+	LowQualityAction  string      // What to do with low-quality text: "fail" (default) or "flag" for review
+	AnalysisMode      string      // "single" (default) or "two_pass" to surface contact info and skills early
+	MaxQueuedJobs     int         // Jobs allowed to wait for a worker before new ones are rejected (0 = unlimited)
+	SyncMaxBytes      int         // Largest total upload size analyzed synchronously (0 = DefaultSyncMaxBytes)
+	MaxRetries        *int        // Retries allowed before a failing job is dead-lettered (nil = DefaultMaxRetries, 0 = none)
+	RetrievalLimit    int         // Chunks retrieved for the analysis prompt (0 = DefaultRetrievalLimit)
+	MinRelevanceScore float64     // Retrieved chunks less similar to the query are dropped (0 = disabled)
+	Notifier          JobNotifier // Told when a job completes or fails (nil = no notifications)
+	Clock             clock.Clock // Times job processing (default: system clock)
+
+	// ExperienceMerge decides which duplicate experience entries in a profile are merged
+	// (nil = DefaultExperienceMergeRules)
+	ExperienceMerge *ExperienceMergeRules
 }
 
+// DefaultSyncMaxBytes is the largest total upload size AnalyzeSync waits for by default
+const DefaultSyncMaxBytes = 256 * 1024
+
+// DefaultMinTextLength is how many non-whitespace characters a document has to extract
+// to by default before it is worth chunking, embedding and analyzing
+const DefaultMinTextLength = 50
+
+// DefaultMaxRetries is how many times a failing job may be retried by default
+const DefaultMaxRetries = 3
+
+// DefaultRetrievalLimit is how many chunks are retrieved for the analysis prompt by default
+const DefaultRetrievalLimit = 10
+
+// retrievalQuery is the vector search query for chunks relevant to profile analysis
+const retrievalQuery = "skills experience education"
+
+// retrievalFilter restricts the analysis retrieval to the sections retrievalQuery asks for
+var retrievalFilter = SearchFilter{Sections: []string{SectionSkills, SectionExperience, SectionEducation}}
+
 // NewResumeAnalyzer creates a new resume analyzer instance
 func NewResumeAnalyzer(
 	uploadRepo repository.UploadRepository,
@@ -43,12 +145,49 @@ func NewResumeAnalyzer(
 ) ResumeAnalyzer {
 	if config == nil {
 		config = &Config{
-			ChunkSize:       1000,
-			ChunkOverlap:    200,
+			ChunkSize:         1000,
+			ChunkOverlap:      200,
 			MaxConcurrentJobs: 5,
+			MaxChunks:         50,
+			MinQualityScore:   0.5,
 		}
 	}
 
+	lowQualityAction := config.LowQualityAction
+	if lowQualityAction != QualityActionFlag {
+		lowQualityAction = QualityActionFail
+	}
+
+	analysisMode := config.AnalysisMode
+	if analysisMode != AnalysisModeTwoPass {
+		analysisMode = AnalysisModeSingle
+	}
+
+	syncMaxBytes := config.SyncMaxBytes
+	if syncMaxBytes <= 0 {
+		syncMaxBytes = DefaultSyncMaxBytes
+	}
+
+	maxRetries := DefaultMaxRetries
+	if config.MaxRetries != nil {
+		maxRetries = max(*config.MaxRetries, 0)
+	}
+
+	minTextLength := DefaultMinTextLength
+	if config.MinTextLength != nil {
+		minTextLength = max(*config.MinTextLength, 0)
+	}
+
+	experienceMerge := DefaultExperienceMergeRules
+	if config.ExperienceMerge != nil {
+		experienceMerge = *config.ExperienceMerge
+	}
+
+	retrievalLimit := config.RetrievalLimit
+	if retrievalLimit <= 0 {
+		retrievalLimit = DefaultRetrievalLimit
+	}
+
 	return &DefaultResumeAnalyzer{
 		uploadRepo:   uploadRepo,
 		analysisRepo: analysisRepo,
@@ -58,15 +197,97 @@ func NewResumeAnalyzer(
 		vectorStore:  vectorStore,
 		llmClient:    llmClient,
 		workerPool:   make(chan struct{}, config.MaxConcurrentJobs),
+		maxQueued:    config.MaxQueuedJobs,
+		chunkSize:    config.ChunkSize,
+		chunkOverlap: config.ChunkOverlap,
+		maxChunks:    config.MaxChunks,
+		syncMaxBytes: syncMaxBytes,
+		maxRetries:   maxRetries,
+
+		retrievalLimit:    retrievalLimit,
+		minRelevanceScore: config.MinRelevanceScore,
+
+		minQualityScore:  config.MinQualityScore,
+		minTextLength:    minTextLength,
+		lowQualityAction: lowQualityAction,
+		analysisMode:     analysisMode,
+		experienceMerge:  experienceMerge,
+		notifier:         config.Notifier,
+		clock:            clock.OrReal(config.Clock),
+		running:          make(map[string]*runningJob),
+		similar:          newSimilarCache(),
 	}
 }
 
 // AnalyzeAsync starts an asynchronous analysis job for a resume
 func (a *DefaultResumeAnalyzer) AnalyzeAsync(ctx context.Context, uploadID int, userID *int) (string, error) {
-	// Verify the upload exists
-	upload, err := a.uploadRepo.GetUploadByID(ctx, uploadID)
+	return a.AnalyzeMultipleAsync(ctx, []int{uploadID}, userID)
+}
+
+// AnalyzeMultipleAsync starts an asynchronous analysis job whose documents are merged into one profile.
+// The first upload is the primary one; the job and its embeddings are stored under it.
+func (a *DefaultResumeAnalyzer) AnalyzeMultipleAsync(ctx context.Context, uploadIDs []int, userID *int) (string, error) {
+	uploads, err := a.loadAnalysisUploads(ctx, uploadIDs, userID)
 	if err != nil {
-		return "", fmt.Errorf("upload not found: %w", err)
+		return "", err
+	}
+
+	jobID, _, err := a.startJob(ctx, uploads, userID)
+	return jobID, err
+}
+
+// AnalyzeSync starts an analysis job and waits up to wait for it to finish, unless the
+// uploads total more than the synchronous size limit. The job runs on the worker pool
+// like any other, so time spent waiting for a worker counts against wait.
+func (a *DefaultResumeAnalyzer) AnalyzeSync(ctx context.Context, uploadIDs []int, userID *int, wait time.Duration) (string, bool, error) {
+	uploads, err := a.loadAnalysisUploads(ctx, uploadIDs, userID)
+	if err != nil {
+		return "", false, err
+	}
+
+	jobID, done, err := a.startJob(ctx, uploads, userID)
+	if err != nil {
+		return "", false, err
+	}
+
+	size := 0
+	for _, upload := range uploads {
+		size += upload.FileSize
+	}
+	if size > a.syncMaxBytes {
+		log.Printf("Analysis job %s runs asynchronously: uploads total %d bytes (sync limit %d)", jobID, size, a.syncMaxBytes)
+		return jobID, false, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return jobID, true, nil
+	case <-timer.C:
+		log.Printf("Analysis job %s did not finish within %s, continuing asynchronously", jobID, wait)
+	case <-ctx.Done():
+	}
+	return jobID, false, nil
+}
+
+// loadAnalysisUploads loads the uploads of a new analysis, primary upload first
+func (a *DefaultResumeAnalyzer) loadAnalysisUploads(ctx context.Context, uploadIDs []int, userID *int) ([]*models.Upload, error) {
+	if len(uploadIDs) == 0 {
+		return nil, fmt.Errorf("at least one upload ID is required")
+	}
+	return a.loadUploads(ctx, uniqueUploadIDs(uploadIDs), userID)
+}
+
+// startJob stores a new job over the uploads and starts processing it in the background.
+// The returned channel is closed once processing ends, whatever the outcome.
+func (a *DefaultResumeAnalyzer) startJob(ctx context.Context, uploads []*models.Upload, userID *int) (string, <-chan struct{}, error) {
+	upload, additional := uploads[0], uploads[1:]
+
+	// Reject the job before it is stored when too many are already waiting
+	if !a.reserveQueueSlot() {
+		return "", nil, ErrQueueFull
 	}
 
 	// Generate unique job ID
@@ -75,22 +296,143 @@ func (a *DefaultResumeAnalyzer) AnalyzeAsync(ctx context.Context, uploadID int,
 	// Create analysis job record
 	job := &models.AnalysisJob{
 		JobID:       jobID,
-		UploadID:    uploadID,
+		UploadID:    upload.ID,
 		UserID:      userID,
 		Status:      "queued",
 		Progress:    0,
 		CurrentStep: "Job queued for processing",
 	}
+	for _, u := range additional {
+		job.AdditionalUploadIDs = append(job.AdditionalUploadIDs, int64(u.ID))
+	}
 
-	err = a.analysisRepo.CreateJob(ctx, job)
+	err := a.analysisRepo.CreateJob(ctx, job)
 	if err != nil {
-		return "", fmt.Errorf("failed to create job: %w", err)
+		a.queued.Add(-1)
+		return "", nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	// Start async worker, registered first so CancelJob can stop it right away
+	jobCtx, untrack := a.track(jobID)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.processJob(jobCtx, untrack, jobID, upload, additional, false)
+	}()
+
+	return jobID, done, nil
+}
+
+// loadUploads fetches the given uploads and checks they don't belong to another user.
+// Anonymous callers (nil userID) may only analyze anonymous uploads. Anonymous uploads are
+// not rejected for users: whether they are open at all depends on the server's anonymous
+// mode, which handlers check with GetUpload before starting a job. Merged uploads must
+// all have the same owner.
+func (a *DefaultResumeAnalyzer) loadUploads(ctx context.Context, uploadIDs []int, userID *int) ([]*models.Upload, error) {
+	uploads := make([]*models.Upload, 0, len(uploadIDs))
+
+	for _, id := range uploadIDs {
+		upload, err := a.uploadRepo.GetUploadByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUploadNotFound, err)
+		}
+
+		if upload.UserID != nil && (userID == nil || *upload.UserID != *userID) {
+			return nil, fmt.Errorf("%w: upload %d", ErrUploadNotOwned, id)
+		}
+		if len(uploadIDs) > 1 && (userID == nil) != (upload.UserID == nil) {
+			return nil, fmt.Errorf("%w: upload %d", ErrUploadNotOwned, id)
+		}
+
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
+
+// uniqueUploadIDs removes duplicate IDs, keeping the first occurrence of each
+func uniqueUploadIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// bulkReanalyzePageSize is how many uploads are read at a time when re-analyzing all of a user's uploads
+const bulkReanalyzePageSize = 100
+
+// ReanalyzeAllAsync starts a new analysis job for each of the user's uploads, skipping
+// uploads (primary or merged) of jobs that have not finished yet
+func (a *DefaultResumeAnalyzer) ReanalyzeAllAsync(ctx context.Context, userID int, allow func() bool) (*BulkReanalyzeResult, error) {
+	jobs, err := a.analysisRepo.GetJobsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	busy := make(map[int]bool)
+	for _, job := range jobs {
+		if job.Finished() {
+			continue
+		}
+		busy[job.UploadID] = true
+		for _, id := range job.AdditionalUploadIDs {
+			busy[int(id)] = true
+		}
+	}
+
+	var uploadIDs []int
+	for offset := 0; ; offset += bulkReanalyzePageSize {
+		uploads, err := a.uploadRepo.ListUploadsByUserID(ctx, userID, bulkReanalyzePageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list uploads: %w", err)
+		}
+		for _, upload := range uploads {
+			uploadIDs = append(uploadIDs, upload.ID)
+		}
+		if len(uploads) < bulkReanalyzePageSize {
+			break
+		}
 	}
 
-	// Start async worker
-	go a.processJob(jobID, upload)
+	result := &BulkReanalyzeResult{
+		JobIDs:            []string{},
+		UploadIDs:         []int{},
+		SkippedUploadIDs:  []int{},
+		DeferredUploadIDs: []int{},
+	}
+	deferred := false
+	for _, uploadID := range uploadIDs {
+		switch {
+		case busy[uploadID]:
+			result.SkippedUploadIDs = append(result.SkippedUploadIDs, uploadID)
+			continue
+		case deferred:
+			result.DeferredUploadIDs = append(result.DeferredUploadIDs, uploadID)
+			continue
+		case allow != nil && !allow():
+			deferred = true
+			result.DeferredUploadIDs = append(result.DeferredUploadIDs, uploadID)
+			continue
+		}
 
-	return jobID, nil
+		jobID, err := a.AnalyzeAsync(ctx, uploadID, &userID)
+		if errors.Is(err, ErrQueueFull) {
+			deferred = true
+			result.DeferredUploadIDs = append(result.DeferredUploadIDs, uploadID)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to start analysis of upload %d: %w", uploadID, err)
+		}
+		result.JobIDs = append(result.JobIDs, jobID)
+		result.UploadIDs = append(result.UploadIDs, uploadID)
+	}
+
+	return result, nil
 }
 
 // GetJobsByUserID retrieves all analysis jobs for a specific user
@@ -98,6 +440,20 @@ func (a *DefaultResumeAnalyzer) GetJobsByUserID(ctx context.Context, userID int)
 	return a.analysisRepo.GetJobsByUserID(ctx, userID)
 }
 
+// GetUserAnalytics returns aggregate statistics across a user's uploads and analyses
+func (a *DefaultResumeAnalyzer) GetUserAnalytics(ctx context.Context, userID int, topSkills int) (*models.UserAnalytics, error) {
+	return a.analysisRepo.GetUserAnalytics(ctx, userID, topSkills)
+}
+
+// GetUpload retrieves an upload's metadata, returning ErrUploadNotFound when it does not exist
+func (a *DefaultResumeAnalyzer) GetUpload(ctx context.Context, uploadID int) (*models.Upload, error) {
+	upload, err := a.uploadRepo.GetUploadByID(ctx, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUploadNotFound, err)
+	}
+	return upload, nil
+}
+
 // GetJobsByUploadID retrieves all analysis jobs for a specific upload
 func (a *DefaultResumeAnalyzer) GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error) {
 	return a.analysisRepo.GetJobsByUploadID(ctx, uploadID)
@@ -105,21 +461,33 @@ func (a *DefaultResumeAnalyzer) GetJobsByUploadID(ctx context.Context, uploadID
 
 // DeleteJob deletes a single analysis job and its associated profile
 func (a *DefaultResumeAnalyzer) DeleteJob(ctx context.Context, jobID string) error {
-	return a.analysisRepo.DeleteJob(ctx, jobID)
+	if err := a.analysisRepo.DeleteJob(ctx, jobID); err != nil {
+		return err
+	}
+	a.similar.forget(jobID)
+	return nil
 }
 
-// RetryJob resets a failed job and reprocesses it
+// RetryJob resets a failed job and reprocesses it. Retrying a job flagged for review
+// approves its extracted text, so the quality check is skipped. A job that has used all
+// of its retries is dead-lettered instead and ErrRetryLimitReached is returned.
 func (a *DefaultResumeAnalyzer) RetryJob(ctx context.Context, jobID string) error {
 	// Get the job to validate it exists and check status
 	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
 	if err != nil {
-		return fmt.Errorf("job not found: %w", err)
+		return fmt.Errorf("%w: %w", ErrJobNotFound, err)
 	}
 
-	// Only allow retry of failed jobs
-	if job.Status != "failed" {
+	// Only allow retry of failed jobs and jobs flagged for review
+	if job.Status != "failed" && job.Status != "needs_review" {
 		return fmt.Errorf("only failed jobs can be retried, current status: %s", job.Status)
 	}
+	approved := job.Status == "needs_review"
+
+	if job.RetryCount >= a.maxRetries {
+		a.deadLetter(ctx, job)
+		return fmt.Errorf("%w (%d of %d retries used)", ErrRetryLimitReached, job.RetryCount, a.maxRetries)
+	}
 
 	// Get the upload information
 	upload, err := a.uploadRepo.GetUploadByID(ctx, job.UploadID)
@@ -127,16 +495,64 @@ func (a *DefaultResumeAnalyzer) RetryJob(ctx context.Context, jobID string) erro
 		return fmt.Errorf("upload not found: %w", err)
 	}
 
+	additional := make([]*models.Upload, 0, len(job.AdditionalUploadIDs))
+	for _, id := range job.AdditionalUploadIDs {
+		u, err := a.uploadRepo.GetUploadByID(ctx, int(id))
+		if err != nil {
+			return fmt.Errorf("upload not found: %w", err)
+		}
+		additional = append(additional, u)
+	}
+
+	if !a.reserveQueueSlot() {
+		return ErrQueueFull
+	}
+
 	// Reset the job to queued status
 	if err := a.analysisRepo.ResetJobForRetry(ctx, jobID); err != nil {
+		a.queued.Add(-1)
 		return fmt.Errorf("failed to reset job: %w", err)
 	}
 
-	log.Printf("Retrying analysis job %s for upload %d", jobID, upload.ID)
+	log.Printf("Retrying analysis job %s for upload %d (retry %d of %d)", jobID, upload.ID, job.RetryCount+1, a.maxRetries)
 
 	// Start async worker with existing processJob method
-	go a.processJob(jobID, upload)
+	jobCtx, untrack := a.track(jobID)
+	go a.processJob(jobCtx, untrack, jobID, upload, additional, approved)
+
+	return nil
+}
 
+// CancelJob stops a queued or processing job, waits for its worker to exit and marks the
+// job failed. A job no worker of this analyzer is processing is only marked failed; a job
+// that finished, even while being cancelled, is left as it is.
+func (a *DefaultResumeAnalyzer) CancelJob(ctx context.Context, jobID string) error {
+	a.mu.Lock()
+	job := a.running[jobID]
+	a.mu.Unlock()
+
+	if job != nil {
+		job.cancel(errJobCancelled)
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			return fmt.Errorf("job %s did not stop: %w", jobID, ctx.Err())
+		}
+	}
+
+	stored, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+	if stored.Finished() {
+		return nil
+	}
+
+	if err := a.analysisRepo.UpdateJobError(ctx, jobID, models.JobErrorCancelled, CancelledJobMessage); err != nil {
+		return fmt.Errorf("failed to mark job cancelled: %w", err)
+	}
+
+	log.Printf("Cancelled analysis job %s", jobID)
 	return nil
 }
 
@@ -156,6 +572,7 @@ func (a *DefaultResumeAnalyzer) BatchDeleteJobs(ctx context.Context, jobIDs []st
 	if err != nil {
 		return nil, fmt.Errorf("batch deletion failed: %w", err)
 	}
+	a.similar.forget(deletedJobs...)
 
 	return &BatchDeleteResult{
 		Success:      true,
@@ -165,65 +582,140 @@ func (a *DefaultResumeAnalyzer) BatchDeleteJobs(ctx context.Context, jobIDs []st
 	}, nil
 }
 
-// processJob processes a resume analysis job asynchronously
-func (a *DefaultResumeAnalyzer) processJob(jobID string, upload *models.Upload) {
-	// Acquire semaphore slot
-	a.workerPool <- struct{}{}
+// reserveQueueSlot counts a job as queued, reporting false (and counting nothing) when
+// the queue is already at its high-water mark
+func (a *DefaultResumeAnalyzer) reserveQueueSlot() bool {
+	queued := a.queued.Add(1)
+	if a.maxQueued > 0 && queued > int64(a.maxQueued) {
+		a.queued.Add(-1)
+		return false
+	}
+	return true
+}
+
+// QueueStats reports how many jobs hold or are waiting for a worker slot
+func (a *DefaultResumeAnalyzer) QueueStats() QueueStats {
+	queued := int(a.queued.Load())
+	return QueueStats{
+		Running:       len(a.workerPool),
+		Queued:        queued,
+		Capacity:      cap(a.workerPool),
+		MaxQueued:     a.maxQueued,
+		AcceptingJobs: a.maxQueued <= 0 || queued < a.maxQueued,
+	}
+}
+
+// processJob processes a resume analysis job asynchronously.
+// Text from additional uploads is appended to the primary upload's text before chunking.
+// skipQualityCheck is set when a job flagged for review has been approved.
+// jobCtx and untrack come from track, which registered the job so CancelJob can stop it,
+// whether it is queued or processing.
+func (a *DefaultResumeAnalyzer) processJob(jobCtx context.Context, untrack func(), jobID string, upload *models.Upload, additional []*models.Upload, skipQualityCheck bool) {
+	defer untrack()
+
+	// Runs after the worker slot is released, so a slow notification doesn't hold it up
+	defer a.notifyFinished(jobID)
+
+	// Acquire semaphore slot; the job was counted as queued when it was submitted
+	select {
+	case a.workerPool <- struct{}{}:
+		a.queued.Add(-1)
+	case <-jobCtx.Done():
+		a.queued.Add(-1)
+		log.Printf("Analysis job %s cancelled while queued", jobID)
+		return
+	}
 	defer func() { <-a.workerPool }()
 
-	// Use a context with overall timeout for the entire job (10 minutes)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	// Use a context with overall timeout for the entire job (10 minutes). Once the job is
+	// cancelled, its remaining steps and status updates fail with the context.
+	ctx, cancel := context.WithTimeout(jobCtx, 10*time.Minute)
 	defer cancel()
 
-	startTime := time.Now()
+	startTime := a.clock.Now()
 
 	log.Printf("Starting analysis job %s for upload %d", jobID, upload.ID)
 
-	// Fetch file content from database
-	fileContent, err := a.uploadRepo.GetUploadFileContent(ctx, upload.ID)
-	if err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Failed to fetch file content: %v", err))
-		return
-	}
-
-	log.Printf("Fetched file content: %d bytes", len(fileContent))
-
 	// Step 1: Extract text (0-20%)
 	if err := a.updateProgress(ctx, jobID, "extracting_text", 10, "Extracting text from resume"); err != nil {
 		log.Printf("Failed to update progress: %v", err)
 	}
 
-	// Create a timeout context specifically for text extraction (2 minutes max)
-	extractCtx, extractCancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer extractCancel()
-
-	resumeText, err := a.extractor.ExtractText(extractCtx, fileContent, upload.MimeType)
+	resumeText, err := a.extractUploadText(ctx, upload)
 	if err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Text extraction failed: %v", err))
+		a.handleError(ctx, jobID, models.JobErrorExtraction, fmt.Sprintf("Upload %d: %v", upload.ID, err))
 		return
 	}
 
-	resumeText = CleanText(resumeText)
-	log.Printf("Extracted %d characters from upload %d", len(resumeText), upload.ID)
+	if len(additional) > 0 {
+		documents := []string{resumeText}
+		for _, u := range additional {
+			text, err := a.extractUploadText(ctx, u)
+			if err != nil {
+				a.handleError(ctx, jobID, models.JobErrorExtraction, fmt.Sprintf("Upload %d: %v", u.ID, err))
+				return
+			}
+			documents = append(documents, text)
+		}
+		resumeText = mergeDocuments(append([]*models.Upload{upload}, additional...), documents)
+		log.Printf("Merged %d documents into %d characters for job %s", len(documents), len(resumeText), jobID)
+	}
 
 	// Save extracted text to database
 	if err := a.analysisRepo.UpdateExtractedText(ctx, jobID, resumeText); err != nil {
 		log.Printf("Failed to save extracted text: %v", err)
 	}
 
+	// Nothing useful comes out of analyzing a few characters, e.g. of a scanned PDF
+	if length := TextLength(resumeText); length < a.minTextLength {
+		a.handleError(ctx, jobID, models.JobErrorInsufficientText, fmt.Sprintf("Insufficient text extracted: %d characters (minimum %d)", length, a.minTextLength))
+		return
+	}
+
+	// Don't feed garbled or near-empty extractions to the LLM
+	if a.minQualityScore > 0 && !skipQualityCheck {
+		quality := AssessExtractionQuality(resumeText)
+		if quality.Score < a.minQualityScore {
+			reason := fmt.Sprintf("Extracted text quality too low (score %.2f, minimum %.2f): %s",
+				quality.Score, a.minQualityScore, strings.Join(quality.Reasons, "; "))
+
+			if a.lowQualityAction == QualityActionFlag {
+				log.Printf("Job %s flagged for review: %s", jobID, reason)
+				if err := a.analysisRepo.FlagJobForReview(ctx, jobID, reason); err != nil {
+					log.Printf("Failed to flag job for review: %v", err)
+				}
+				return
+			}
+
+			a.handleError(ctx, jobID, models.JobErrorLowQualityText, reason)
+			return
+		}
+	}
+
 	// Step 2: Chunk text (20-40%)
 	if err := a.updateProgress(ctx, jobID, "chunking", 25, "Chunking document into segments"); err != nil {
 		log.Printf("Failed to update progress: %v", err)
 	}
 
-	chunks, err := a.chunker.ChunkText(resumeText, 1000, 200)
+	chunks, err := a.chunker.ChunkText(resumeText, a.chunkSize, a.chunkOverlap)
 	if err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Text chunking failed: %v", err))
+		a.handleError(ctx, jobID, models.JobErrorChunking, fmt.Sprintf("Text chunking failed: %v", err))
 		return
 	}
 
 	log.Printf("Created %d chunks for upload %d", len(chunks), upload.ID)
 
+	// Label sections before sampling, since a chunk's section can come from the chunks before it
+	metadata := LabelChunkSections(chunks)
+
+	// Sample very long documents so we don't pay for hundreds of embedding calls
+	if a.maxChunks > 0 && len(chunks) > a.maxChunks {
+		originalCount := len(chunks)
+		chunks = SampleChunks(chunks, a.maxChunks)
+		metadata = SampleChunks(metadata, a.maxChunks)
+		log.Printf("Sampled %d of %d chunks for upload %d (max chunks: %d)", len(chunks), originalCount, upload.ID, a.maxChunks)
+	}
+
 	// Step 3: Generate embeddings (40-60%)
 	if err := a.updateProgress(ctx, jobID, "generating_embeddings", 45, "Generating vector embeddings"); err != nil {
 		log.Printf("Failed to update progress: %v", err)
@@ -233,69 +725,389 @@ func (a *DefaultResumeAnalyzer) processJob(jobID string, upload *models.Upload)
 	embedCtx, embedCancel := context.WithTimeout(ctx, 3*time.Minute)
 	defer embedCancel()
 
-	embeddings, err := a.embedder.GenerateEmbeddings(embedCtx, chunks)
+	embeddings, generated, err := a.embedChunks(embedCtx, upload.TenantID, upload.ID, chunks, metadata, func(done, total int) {
+		// Spread the embedding calls over 45-54%, leaving 55% for storing them
+		progress := 45 + done*9/total
+		if err := a.updateProgress(ctx, jobID, "generating_embeddings", progress, fmt.Sprintf("Embedded %d/%d chunks", done, total)); err != nil {
+			log.Printf("Failed to update progress: %v", err)
+		}
+	})
 	if err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Embedding generation failed: %v", err))
+		a.handleError(ctx, jobID, models.JobErrorEmbedding, fmt.Sprintf("Embedding generation failed: %v", err))
 		return
 	}
 
-	log.Printf("Generated %d embeddings for upload %d", len(embeddings), upload.ID)
+	log.Printf("Generated %d embeddings for upload %d (%d reused from the vector store)", generated, upload.ID, len(embeddings)-generated)
 
 	// Store embeddings in vector database
 	if err := a.updateProgress(ctx, jobID, "generating_embeddings", 55, "Storing embeddings in vector database"); err != nil {
 		log.Printf("Failed to update progress: %v", err)
 	}
 
-	if err := a.vectorStore.StoreEmbeddings(ctx, upload.ID, chunks, embeddings); err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Vector storage failed: %v", err))
+	if err := a.vectorStore.StoreEmbeddings(ctx, upload.TenantID, upload.ID, chunks, embeddings, metadata); err != nil {
+		a.handleError(ctx, jobID, models.JobErrorStorage, fmt.Sprintf("Vector storage failed: %v", err))
 		return
 	}
 
 	// Step 4: RAG Analysis with LLM (60-95%)
-	if err := a.updateProgress(ctx, jobID, "analyzing", 70, "Analyzing resume with AI"); err != nil {
+	analysisResponse, err := a.analyzeResume(ctx, jobID, upload, resumeText, func(progress int, step string) {
+		if err := a.updateProgress(ctx, jobID, "analyzing", progress, step); err != nil {
+			log.Printf("Failed to update progress: %v", err)
+		}
+	})
+	if err != nil {
+		a.handleError(ctx, jobID, models.JobErrorLLM, fmt.Sprintf("LLM analysis failed: %v", err))
+		return
+	}
+
+	// Step 5: Store results (95-100%)
+	if err := a.updateProgress(ctx, jobID, "analyzing", 95, "Saving analysis results"); err != nil {
 		log.Printf("Failed to update progress: %v", err)
 	}
 
-	// Retrieve relevant chunks using vector search
-	searchResults, err := a.vectorStore.SearchSimilar(ctx, "skills experience education", 10)
-	if err != nil {
-		log.Printf("Warning: vector search failed: %v", err)
-		searchResults = []SearchResult{} // Continue without retrieved chunks
+	profile := newProfile(upload.ID, jobID, analysisResponse, a.experienceMerge, a.clock.Now())
+
+	if err := a.analysisRepo.SaveProfile(ctx, profile); err != nil {
+		a.handleError(ctx, jobID, models.JobErrorStorage, fmt.Sprintf("Failed to save profile: %v", err))
+		return
+	}
+
+	// Complete the job
+	if err := a.analysisRepo.CompleteJob(ctx, jobID); err != nil {
+		log.Printf("Failed to mark job as completed: %v", err)
+	} else {
+		a.refreshSimilarProfiles(upload.TenantID)
 	}
 
-	retrievedChunks := make([]string, len(searchResults))
-	for i, result := range searchResults {
-		retrievedChunks[i] = result.Chunk
+	duration := a.clock.Now().Sub(startTime)
+	log.Printf("Analysis job %s completed in %v", jobID, duration)
+}
+
+// analyzeResume runs the RAG/LLM step on a job's extracted text, in one pass or in two
+// depending on the analysis mode. progress, if not nil, receives the job's progress.
+func (a *DefaultResumeAnalyzer) analyzeResume(ctx context.Context, jobID string, upload *models.Upload, resumeText string, progress func(percent int, step string)) (*AnalysisResponse, error) {
+	if progress == nil {
+		progress = func(int, string) {}
 	}
 
-	// Call LLM for analysis
-	analysisRequest := &AnalysisRequest{
+	request := AnalysisRequest{
 		ResumeText:      resumeText,
-		RetrievedChunks: retrievedChunks,
+		RetrievedChunks: a.retrieveChunks(ctx, upload.TenantID, upload.ID),
 		LinkedInURL:     upload.LinkedinURL,
 	}
 
-	if err := a.updateProgress(ctx, jobID, "analyzing", 85, "Processing analysis results"); err != nil {
-		log.Printf("Failed to update progress: %v", err)
+	if a.analysisMode == AnalysisModeTwoPass {
+		return a.analyzeInTwoPasses(ctx, jobID, request, progress)
 	}
 
+	progress(70, "Analyzing resume with AI")
+
 	// Create a timeout context for LLM analysis (3 minutes max)
 	llmCtx, llmCancel := context.WithTimeout(ctx, 3*time.Minute)
 	defer llmCancel()
 
-	analysisResponse, err := a.llmClient.Analyze(llmCtx, analysisRequest)
+	response, err := a.llmClient.Analyze(llmCtx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(85, "Processing analysis results")
+	return response, nil
+}
+
+// analyzeInTwoPasses runs a fast basics pass and stores its result on the job, so
+// clients polling the job status can render contact info and skills while the slower
+// deep pass runs. If the basics pass fails, the whole profile is extracted in one pass.
+func (a *DefaultResumeAnalyzer) analyzeInTwoPasses(ctx context.Context, jobID string, request AnalysisRequest, progress func(percent int, step string)) (*AnalysisResponse, error) {
+	progress(60, "Extracting contact info and skills")
+
+	basicsRequest := request
+	basicsRequest.Pass = AnalysisPassBasics
+
+	basicsCtx, basicsCancel := context.WithTimeout(ctx, time.Minute)
+	defer basicsCancel()
+
+	basics, err := a.llmClient.Analyze(basicsCtx, &basicsRequest)
+	if err != nil {
+		log.Printf("Warning: basics pass failed for job %s, falling back to a single pass: %v", jobID, err)
+		progress(70, "Analyzing resume with AI")
+
+		llmCtx, llmCancel := context.WithTimeout(ctx, 3*time.Minute)
+		defer llmCancel()
+		return a.llmClient.Analyze(llmCtx, &request)
+	}
+
+	if err := a.analysisRepo.UpdateJobBasics(ctx, jobID, profileBasics(basics)); err != nil {
+		log.Printf("Failed to save basics for job %s: %v", jobID, err)
+	}
+
+	progress(65, "Basic profile ready, analyzing experience")
+
+	deepRequest := request
+	deepRequest.Pass = AnalysisPassDeep
+
+	// Create a timeout context for the deep pass (3 minutes max)
+	llmCtx, llmCancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer llmCancel()
+
+	deep, err := a.llmClient.Analyze(llmCtx, &deepRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(85, "Processing analysis results")
+
+	return mergeAnalysisPasses(basics, deep), nil
+}
+
+// profileBasics extracts the fields produced by the basics pass
+func profileBasics(response *AnalysisResponse) *models.ProfileBasics {
+	return &models.ProfileBasics{
+		Name:        response.Name,
+		Email:       response.Email,
+		Phone:       response.Phone,
+		LinkedInURL: response.LinkedInURL,
+		Location:    response.Location,
+		Skills:      response.Skills,
+	}
+}
+
+// mergeAnalysisPasses combines the basics and deep passes into a complete response.
+// Basics fields come from the basics pass unless it left them empty.
+func mergeAnalysisPasses(basics, deep *AnalysisResponse) *AnalysisResponse {
+	merged := *deep
+	if basics.Name != nil {
+		merged.Name = basics.Name
+	}
+	if basics.Email != nil {
+		merged.Email = basics.Email
+	}
+	if basics.Phone != nil {
+		merged.Phone = basics.Phone
+	}
+	if basics.LinkedInURL != nil {
+		merged.LinkedInURL = basics.LinkedInURL
+	}
+	if basics.Location != nil {
+		merged.Location = basics.Location
+	}
+	if len(basics.Skills) > 0 {
+		merged.Skills = basics.Skills
+	}
+
+	merged.FieldConfidence = make(map[string]*float64, len(models.ProfileFields))
+	for field, score := range deep.FieldConfidence {
+		merged.FieldConfidence[field] = score
+	}
+	for _, field := range basicsFieldNames {
+		if score := basics.FieldConfidence[field]; score != nil {
+			merged.FieldConfidence[field] = score
+		}
+	}
+	return &merged
+}
+
+// ReanalyzeJob re-runs only the RAG/LLM step of a completed job, using its stored
+// extracted text and the embeddings already in the vector store, and replaces its profile.
+// Extraction, chunking and embedding are skipped; the analysis mode applies as for a new
+// job. The whole profile is replaced, so fields edited with PatchProfile are discarded.
+func (a *DefaultResumeAnalyzer) ReanalyzeJob(ctx context.Context, jobID string) (*models.AnalysisResult, error) {
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
+	}
+
+	if job.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
+	}
+	if job.ExtractedText == nil || *job.ExtractedText == "" {
+		return nil, fmt.Errorf("job %s has no stored extracted text", jobID)
+	}
+
+	upload, err := a.uploadRepo.GetUploadByID(ctx, job.UploadID)
+	if err != nil {
+		return nil, fmt.Errorf("upload not found: %w", err)
+	}
+
+	existing, err := a.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found: %w", err)
+	}
+
+	// Share the worker pool, and the queue limit, with full analyses so reanalysis can't
+	// overload the LLM and shows up in QueueStats while it waits
+	if !a.reserveQueueSlot() {
+		return nil, ErrQueueFull
+	}
+	select {
+	case a.workerPool <- struct{}{}:
+		a.queued.Add(-1)
+		defer func() { <-a.workerPool }()
+	case <-ctx.Done():
+		a.queued.Add(-1)
+		return nil, ctx.Err()
+	}
+
+	startTime := a.clock.Now()
+	log.Printf("Reanalyzing job %s for upload %d with stored embeddings", jobID, upload.ID)
+
+	// The job stays completed, so its progress isn't reported
+	analysisResponse, err := a.analyzeResume(ctx, jobID, upload, *job.ExtractedText, nil)
+	if err != nil {
+		return nil, fmt.Errorf("LLM analysis failed: %w", err)
+	}
+
+	profile := newProfile(upload.ID, jobID, analysisResponse, a.experienceMerge, a.clock.Now())
+	profile.ID = existing.ID
+
+	if err := a.analysisRepo.UpdateProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	log.Printf("Reanalysis of job %s completed in %v", jobID, a.clock.Now().Sub(startTime))
+	a.refreshSimilarProfiles(upload.TenantID)
+
+	return a.GetResult(ctx, jobID)
+}
+
+// retrieveChunks returns the chunks of a tenant's upload most relevant to profile analysis, at
+// most retrievalLimit of them and only those scoring at least minRelevanceScore. Chunks
+// of the sections in retrievalFilter are searched; resumes without any, e.g. because no
+// headings were recognized, are searched whole. A failed search is logged and yields no
+// chunks, so analysis can continue without them.
+func (a *DefaultResumeAnalyzer) retrieveChunks(ctx context.Context, tenantID string, uploadID int) []string {
+	searchResults, err := a.vectorStore.SearchSimilarInUpload(ctx, tenantID, uploadID, retrievalQuery, a.retrievalLimit, retrievalFilter)
+	if err == nil && len(searchResults) == 0 {
+		searchResults, err = a.vectorStore.SearchSimilarInUpload(ctx, tenantID, uploadID, retrievalQuery, a.retrievalLimit, SearchFilter{})
+	}
+	if err != nil {
+		log.Printf("Warning: vector search failed: %v", err)
+		return []string{}
+	}
+
+	retrievedChunks := make([]string, 0, len(searchResults))
+	for _, result := range searchResults {
+		if len(retrievedChunks) >= a.retrievalLimit {
+			break
+		}
+		if a.minRelevanceScore > 0 && float64(result.Score) < a.minRelevanceScore {
+			continue
+		}
+		retrievedChunks = append(retrievedChunks, result.Chunk)
+	}
+
+	if a.minRelevanceScore > 0 {
+		log.Printf("Retrieved %d of %d chunks of upload %d scoring at least %.2f",
+			len(retrievedChunks), len(searchResults), uploadID, a.minRelevanceScore)
+	}
+	return retrievedChunks
+}
+
+// embeddedBatchStoreTimeout bounds storing the embeddings generated so far, which may
+// run after the job's context was cancelled
+const embeddedBatchStoreTimeout = 30 * time.Second
+
+// embedChunks returns an embedding for each chunk, reusing the vector store's embedding of
+// any chunk the tenant already stored (by ChunkHash) and generating only the rest, so re-analyzing or
+// retrying a document doesn't pay to embed it again. generated is the number of embeddings
+// requested from the embedder. A failed lookup is logged and every chunk is embedded.
+// progress, if not nil, is passed to the embedder and counts only the chunks generated.
+// As each batch is embedded, the chunks embedded so far are stored under the upload with
+// their metadata, so if embedding fails, is cancelled or the worker dies partway, a retry
+// only embeds the remaining chunks.
+func (a *DefaultResumeAnalyzer) embedChunks(ctx context.Context, tenantID string, uploadID int, chunks []string, metadata []ChunkMetadata, progress EmbeddingProgress) (embeddings [][]float32, generated int, err error) {
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		hashes[i] = ChunkHash(chunk)
+	}
+
+	known, err := a.vectorStore.LookupEmbeddings(ctx, tenantID, hashes)
 	if err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("LLM analysis failed: %v", err))
+		log.Printf("Warning: embedding lookup failed, embedding all chunks: %v", err)
+		known = nil
+	}
+	if known == nil {
+		known = make(map[string][]float32)
+	}
+
+	// Embed each missing chunk once, even if it repeats within the document
+	var missing []string
+	pending := make(map[string]bool)
+	for i, hash := range hashes {
+		if _, ok := known[hash]; !ok && !pending[hash] {
+			pending[hash] = true
+			missing = append(missing, chunks[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		onBatch := func(start int, batch [][]float32) {
+			for i, embedding := range batch {
+				if start+i < len(missing) && embedding != nil {
+					known[ChunkHash(missing[start+i])] = embedding
+				}
+			}
+			a.storeEmbeddedChunks(ctx, tenantID, uploadID, chunks, metadata, hashes, known)
+		}
+
+		fresh, err := a.embedder.GenerateEmbeddings(ctx, missing, progress, onBatch)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(fresh) != len(missing) {
+			return nil, 0, fmt.Errorf("embedder returned %d embeddings for %d chunks", len(fresh), len(missing))
+		}
+		for i, embedding := range fresh {
+			known[ChunkHash(missing[i])] = embedding
+		}
+	}
+
+	embeddings = make([][]float32, len(chunks))
+	for i, hash := range hashes {
+		embeddings[i] = known[hash]
+	}
+	return embeddings, len(missing), nil
+}
+
+// storeEmbeddedChunks stores the chunks of an upload that have an embedding in known, in
+// document order, replacing what was stored for the upload before. The store happens even
+// if ctx was cancelled; failures are only logged, since the chunks are stored again after
+// the next batch and once the job finishes embedding.
+func (a *DefaultResumeAnalyzer) storeEmbeddedChunks(ctx context.Context, tenantID string, uploadID int, chunks []string, metadata []ChunkMetadata, hashes []string, known map[string][]float32) {
+	var stored []string
+	var embeddings [][]float32
+	var storedMetadata []ChunkMetadata
+	for i, hash := range hashes {
+		if embedding, ok := known[hash]; ok {
+			stored = append(stored, chunks[i])
+			embeddings = append(embeddings, embedding)
+			if metadata != nil {
+				storedMetadata = append(storedMetadata, metadata[i])
+			}
+		}
+	}
+	if len(stored) == 0 {
 		return
 	}
 
-	// Step 5: Store results (95-100%)
-	if err := a.updateProgress(ctx, jobID, "analyzing", 95, "Saving analysis results"); err != nil {
-		log.Printf("Failed to update progress: %v", err)
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), embeddedBatchStoreTimeout)
+	defer cancel()
+
+	if err := a.vectorStore.StoreEmbeddings(storeCtx, tenantID, uploadID, stored, embeddings, storedMetadata); err != nil {
+		log.Printf("Warning: failed to store the embedded chunks of upload %d: %v", uploadID, err)
 	}
+}
+
+// newProfile builds the profile stored for a job from the LLM's analysis, merging
+// duplicate experience entries under mergeRules, normalizing experience dates as of now
+// and computing the total work years from them
+func newProfile(uploadID int, jobID string, analysisResponse *AnalysisResponse, mergeRules ExperienceMergeRules, now time.Time) *models.UserProfile {
+	experience := MergeDuplicateExperience(analysisResponse.Experience, mergeRules, now)
+	experience = NormalizeExperience(experience, now)
+	totalWorkYears, reportedWorkYears := reconcileWorkYears(analysisResponse.TotalWorkYears, experience, now)
 
-	profile := &models.UserProfile{
-		UploadID:           upload.ID,
+	return &models.UserProfile{
+		UploadID:           uploadID,
 		JobID:              jobID,
 		Name:               analysisResponse.Name,
 		Email:              analysisResponse.Email,
@@ -304,28 +1116,77 @@ func (a *DefaultResumeAnalyzer) processJob(jobID string, upload *models.Upload)
 		Age:                analysisResponse.Age,
 		Race:               analysisResponse.Race,
 		Location:           analysisResponse.Location,
-		TotalWorkYears:     analysisResponse.TotalWorkYears,
+		TotalWorkYears:     totalWorkYears,
 		Skills:             analysisResponse.Skills,
-		Experience:         analysisResponse.Experience,
+		Experience:         experience,
 		Education:          analysisResponse.Education,
 		Summary:            analysisResponse.Summary,
 		JobRecommendations: analysisResponse.JobRecommendations,
 		Strengths:          analysisResponse.Strengths,
 		Weaknesses:         analysisResponse.Weaknesses,
+		FieldConfidence:    models.CompleteFieldConfidence(analysisResponse.FieldConfidence),
+		ReportedWorkYears:  reportedWorkYears,
 	}
+}
 
-	if err := a.analysisRepo.CreateProfile(ctx, profile); err != nil {
-		a.handleError(ctx, jobID, fmt.Sprintf("Failed to save profile: %v", err))
-		return
+// extractUploadText fetches an upload's file content and extracts cleaned text from it
+func (a *DefaultResumeAnalyzer) extractUploadText(ctx context.Context, upload *models.Upload) (string, error) {
+	// Fetch file content from database
+	fileContent, err := a.uploadRepo.GetUploadFileContent(ctx, upload.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch file content: %w", err)
 	}
 
-	// Complete the job
-	if err := a.analysisRepo.CompleteJob(ctx, jobID); err != nil {
-		log.Printf("Failed to mark job as completed: %v", err)
+	log.Printf("Fetched file content: %d bytes", len(fileContent))
+
+	// Create a timeout context specifically for text extraction (2 minutes max)
+	extractCtx, extractCancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer extractCancel()
+
+	text, err := a.extractor.ExtractText(extractCtx, fileContent, upload.MimeType)
+	if err != nil {
+		return "", fmt.Errorf("text extraction failed: %w", err)
 	}
 
-	duration := time.Since(startTime)
-	log.Printf("Analysis job %s completed in %v", jobID, duration)
+	text = CleanText(text)
+	log.Printf("Extracted %d characters from upload %d", len(text), upload.ID)
+
+	return text, nil
+}
+
+// mergeDocuments joins the extracted texts of several uploads, separating them
+// with a header naming each document so the LLM can tell them apart
+func mergeDocuments(uploads []*models.Upload, texts []string) string {
+	var merged strings.Builder
+	for i, text := range texts {
+		if i > 0 {
+			merged.WriteString("\n\n")
+		}
+		fmt.Fprintf(&merged, "--- Document %d: %s ---\n\n", i+1, uploads[i].FileName)
+		merged.WriteString(text)
+	}
+	return merged.String()
+}
+
+// track registers a job's worker for CancelJob before it starts. It returns the context
+// CancelJob cancels and the function that unregisters the worker once it exits.
+func (a *DefaultResumeAnalyzer) track(jobID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	job := &runningJob{cancel: cancel, done: make(chan struct{})}
+
+	a.mu.Lock()
+	a.running[jobID] = job
+	a.mu.Unlock()
+
+	return ctx, func() {
+		a.mu.Lock()
+		if a.running[jobID] == job {
+			delete(a.running, jobID)
+		}
+		a.mu.Unlock()
+		cancel(nil)
+		close(job.done)
+	}
 }
 
 // updateProgress updates the job progress
@@ -333,11 +1194,69 @@ func (a *DefaultResumeAnalyzer) updateProgress(ctx context.Context, jobID, statu
 	return a.analysisRepo.UpdateJobStatus(ctx, jobID, status, progress, step)
 }
 
-// handleError handles job errors
-func (a *DefaultResumeAnalyzer) handleError(ctx context.Context, jobID, errorMsg string) {
+// notifyFinished tells the notifier about a job that completed or failed. Jobs that
+// stopped for another reason, such as being cancelled or flagged for review, are skipped.
+func (a *DefaultResumeAnalyzer) notifyFinished(jobID string) {
+	if a.notifier == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		log.Printf("Failed to get job %s for notification: %v", jobID, err)
+		return
+	}
+
+	switch job.Status {
+	case "completed", "failed", "dead_lettered":
+	default:
+		return
+	}
+
+	if err := a.notifier.JobFinished(ctx, job); err != nil {
+		log.Printf("Failed to notify about job %s: %v", jobID, err)
+	}
+}
+
+// handleError marks a job failed with the code of the step that failed (a models.JobError*
+// code) and a message. A job whose last allowed retry failed is dead-lettered right away,
+// so it surfaces for manual review without another retry attempt. A job stopped by
+// CancelJob is left for CancelJob to mark cancelled.
+func (a *DefaultResumeAnalyzer) handleError(ctx context.Context, jobID, errorCode, errorMsg string) {
+	if errors.Is(context.Cause(ctx), errJobCancelled) {
+		log.Printf("Job %s cancelled: %s", jobID, errorMsg)
+		return
+	}
 	log.Printf("Job %s failed: %s", jobID, errorMsg)
-	if err := a.analysisRepo.UpdateJobError(ctx, jobID, errorMsg); err != nil {
+	if err := a.analysisRepo.UpdateJobError(ctx, jobID, errorCode, errorMsg); err != nil {
 		log.Printf("Failed to update job error: %v", err)
+		return
+	}
+
+	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		log.Printf("Failed to get job %s after error: %v", jobID, err)
+		return
+	}
+	if job.RetryCount >= a.maxRetries {
+		a.deadLetter(ctx, job)
+	}
+}
+
+// deadLetter moves a job that keeps failing to the terminal dead_lettered status,
+// keeping its last error in the message
+func (a *DefaultResumeAnalyzer) deadLetter(ctx context.Context, job *models.AnalysisJob) {
+	reason := fmt.Sprintf("Gave up after %d retries", job.RetryCount)
+	if job.ErrorMessage != nil && *job.ErrorMessage != "" {
+		reason += ": " + *job.ErrorMessage
+	}
+
+	log.Printf("Dead-lettering job %s: %s", job.JobID, reason)
+	if err := a.analysisRepo.DeadLetterJob(ctx, job.JobID, reason); err != nil {
+		log.Printf("Failed to dead-letter job %s: %v", job.JobID, err)
 	}
 }
 
@@ -350,6 +1269,7 @@ func (a *DefaultResumeAnalyzer) GetStatus(ctx context.Context, jobID string) (*m
 
 	status := &models.AnalysisStatus{
 		JobID:         job.JobID,
+		UserID:        job.UserID,
 		Status:        job.Status,
 		Progress:      job.Progress,
 		CurrentStep:   job.CurrentStep,
@@ -358,6 +1278,17 @@ func (a *DefaultResumeAnalyzer) GetStatus(ctx context.Context, jobID string) (*m
 		UpdatedAt:     job.UpdatedAt,
 		CompletedAt:   job.CompletedAt,
 		ErrorMessage:  job.ErrorMessage,
+		ErrorCode:     job.ErrorCode,
+		RetryCount:    job.RetryCount,
+	}
+
+	// Early results are only stored in two-pass mode, once the analysis step is reached
+	if job.Status == "analyzing" || job.Status == "completed" {
+		basics, err := a.analysisRepo.GetJobBasics(ctx, jobID)
+		if err != nil {
+			log.Printf("Failed to get basics for job %s: %v", jobID, err)
+		}
+		status.Basics = basics
 	}
 
 	return status, nil
@@ -368,11 +1299,11 @@ func (a *DefaultResumeAnalyzer) GetResult(ctx context.Context, jobID string) (*m
 	// Get job to verify it's completed
 	job, err := a.analysisRepo.GetJobByID(ctx, jobID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrJobNotFound, err)
 	}
 
 	if job.Status != "completed" {
-		return nil, fmt.Errorf("job is not completed yet (status: %s)", job.Status)
+		return nil, fmt.Errorf("%w (status: %s)", ErrJobNotCompleted, job.Status)
 	}
 
 	// Get profile
@@ -399,6 +1330,8 @@ func (a *DefaultResumeAnalyzer) GetResult(ctx context.Context, jobID string) (*m
 		JobRecommendations: profile.JobRecommendations,
 		Strengths:          profile.Strengths,
 		Weaknesses:         profile.Weaknesses,
+		FieldConfidence:    models.CompleteFieldConfidence(profile.FieldConfidence),
+		ReportedWorkYears:  profile.ReportedWorkYears,
 		CreatedAt:          profile.CreatedAt,
 		CompletedAt:        job.CompletedAt,
 	}
@@ -406,10 +1339,10 @@ func (a *DefaultResumeAnalyzer) GetResult(ctx context.Context, jobID string) (*m
 	return result, nil
 }
 
-// SearchSimilarResumes finds similar resumes using vector similarity
-func (a *DefaultResumeAnalyzer) SearchSimilarResumes(ctx context.Context, query string, limit int) ([]*models.UserProfile, error) {
-	// Search vector store
-	searchResults, err := a.vectorStore.SearchSimilar(ctx, query, limit)
+// SearchSimilarResumes finds similar resumes of a tenant using vector similarity
+func (a *DefaultResumeAnalyzer) SearchSimilarResumes(ctx context.Context, tenantID string, query string, limit int) ([]*models.UserProfile, error) {
+	// Search the tenant's vectors only
+	searchResults, err := a.vectorStore.SearchSimilar(ctx, tenantID, query, limit, SearchFilter{})
 	if err != nil {
 		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
@@ -0,0 +1,1359 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestAnalyzeMultipleMergesDocuments(t *testing.T) {
+	ta := newTestAnalyzer(t, nil)
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7), FileName: "resume.pdf"}, "Backend engineer writing Go services backed by SQL databases.")
+	ta.uploads.add(&models.Upload{ID: 2, UserID: intPtr(7), FileName: "portfolio.pdf"}, "Side project: a Kubernetes operator provisioning clusters.")
+
+	job := ta.analyze(t, intPtr(7), 1, 2)
+	if job.Status != "completed" {
+		t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+	}
+	if job.UploadID != 1 || !reflect.DeepEqual([]int64(job.AdditionalUploadIDs), []int64{2}) {
+		t.Errorf("job uploads = %d + %v, want 1 + [2]", job.UploadID, job.AdditionalUploadIDs)
+	}
+
+	// The merged text names both documents in order
+	text := *job.ExtractedText
+	first, second := strings.Index(text, "Document 1: resume.pdf"), strings.Index(text, "Document 2: portfolio.pdf")
+	if first < 0 || second < first {
+		t.Errorf("extracted text lacks the document headers in order:\n%s", text)
+	}
+
+	profile, err := ta.repo.GetProfileByJobID(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Go", "SQL", "Kubernetes"}; !reflect.DeepEqual(profile.Skills["technical"], want) {
+		t.Errorf("skills = %v, want %v including the portfolio's", profile.Skills["technical"], want)
+	}
+
+	// Chunks of both documents are stored under the primary upload
+	results, err := ta.store.SearchSimilarInUpload(context.Background(), "", 1, "Kubernetes operator", 10, SearchFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, result := range results {
+		found = found || strings.Contains(result.Chunk, "Kubernetes")
+	}
+	if !found {
+		t.Errorf("portfolio chunks not stored under the primary upload")
+	}
+}
+
+func TestAnalyzeMultipleChecksOwnership(t *testing.T) {
+	ta := newTestAnalyzer(t, nil)
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go")
+	ta.uploads.add(&models.Upload{ID: 2, UserID: intPtr(8)}, "SQL")
+	ta.uploads.add(&models.Upload{ID: 3}, "Rust")
+
+	tests := []struct {
+		name      string
+		userID    *int
+		uploadIDs []int
+		wantErr   error
+	}{
+		{"another user's upload", intPtr(7), []int{1, 2}, ErrUploadNotOwned},
+		{"anonymous merged with owned", intPtr(7), []int{1, 3}, ErrUploadNotOwned},
+		{"anonymous caller", nil, []int{3, 1}, ErrUploadNotOwned},
+		{"missing upload", intPtr(7), []int{1, 99}, ErrUploadNotFound},
+		{"no uploads", intPtr(7), nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobID, err := ta.AnalyzeMultipleAsync(context.Background(), tt.uploadIDs, tt.userID)
+			if err == nil {
+				t.Fatalf("job %s started, want an error", jobID)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if len(ta.repo.jobs) != 0 {
+		t.Errorf("%d jobs created for rejected analyses", len(ta.repo.jobs))
+	}
+}
+
+func TestLowQualityTextIsNotAnalyzed(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     string
+		text       string
+		wantStatus string
+	}{
+		{"clean text is analyzed", QualityActionFail, cleanResume, "completed"},
+		{"garbled text fails", QualityActionFail, garbledResume, "failed"},
+		{"garbled text is flagged", QualityActionFlag, garbledResume, "needs_review"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) {
+				config.MinQualityScore = 0.5
+				config.LowQualityAction = tt.action
+			})
+			ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, tt.text)
+
+			job := ta.analyze(t, intPtr(7), 1)
+			if job.Status != tt.wantStatus {
+				t.Fatalf("job status = %q, want %q: %v", job.Status, tt.wantStatus, job.ErrorMessage)
+			}
+			if tt.wantStatus == "completed" {
+				return
+			}
+
+			if calls := ta.llm.calls(); len(calls) != 0 {
+				t.Errorf("LLM called %d times for low-quality text", len(calls))
+			}
+			if job.ErrorMessage == nil || !strings.Contains(*job.ErrorMessage, "quality too low") {
+				t.Errorf("error message = %v, want the quality explanation", job.ErrorMessage)
+			}
+			if tt.action == QualityActionFail && (job.ErrorCode == nil || *job.ErrorCode != models.JobErrorLowQualityText) {
+				t.Errorf("error code = %v, want %s", job.ErrorCode, models.JobErrorLowQualityText)
+			}
+		})
+	}
+}
+
+func TestRetryingFlaggedJobApprovesText(t *testing.T) {
+	ta := newTestAnalyzer(t, func(config *Config) {
+		config.MinQualityScore = 0.5
+		config.LowQualityAction = QualityActionFlag
+	})
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, garbledResume+" Go")
+
+	job := ta.analyze(t, intPtr(7), 1)
+	if job.Status != "needs_review" {
+		t.Fatalf("job status = %q, want needs_review", job.Status)
+	}
+
+	if err := ta.RetryJob(context.Background(), job.JobID); err != nil {
+		t.Fatalf("RetryJob: %v", err)
+	}
+	ta.waitForStatus(t, job.JobID, "completed")
+
+	if calls := ta.llm.calls(); len(calls) != 1 {
+		t.Errorf("LLM called %d times after approval, want 1", len(calls))
+	}
+}
+
+// forbiddenExtractor fails the test if text is extracted
+type forbiddenExtractor struct{ t *testing.T }
+
+func (e forbiddenExtractor) ExtractText(ctx context.Context, fileContent []byte, mimeType string) (string, error) {
+	e.t.Error("text extracted during reanalysis")
+	return "", errors.New("extraction is not allowed")
+}
+
+// forbiddenEmbedder fails the test if chunks are embedded
+type forbiddenEmbedder struct {
+	EmbeddingGenerator
+	t *testing.T
+}
+
+func (e forbiddenEmbedder) GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error) {
+	e.t.Error("chunks embedded during reanalysis")
+	return nil, errors.New("embedding is not allowed")
+}
+
+func TestReanalyzeJobSkipsExtractionAndEmbedding(t *testing.T) {
+	for _, mode := range []string{AnalysisModeSingle, AnalysisModeTwoPass} {
+		t.Run(mode, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) { config.AnalysisMode = mode })
+			ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go and SQL services, some Python tooling.")
+
+			job := ta.analyze(t, intPtr(7), 1)
+			if job.Status != "completed" {
+				t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+			}
+			before, err := ta.repo.GetProfileByJobID(context.Background(), job.JobID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			callsBefore := len(ta.llm.calls())
+
+			// The improved prompt now recognizes Python
+			ta.llm.skills = append(ta.llm.skills, "Python")
+			ta.extractor = forbiddenExtractor{t}
+			ta.DefaultResumeAnalyzer.embedder = forbiddenEmbedder{ta.embedder, t}
+
+			result, err := ta.ReanalyzeJob(context.Background(), job.JobID)
+			if err != nil {
+				t.Fatalf("ReanalyzeJob: %v", err)
+			}
+			if want := []string{"Go", "SQL", "Python"}; !reflect.DeepEqual(result.Skills["technical"], want) {
+				t.Errorf("skills = %v, want %v", result.Skills["technical"], want)
+			}
+
+			after, err := ta.repo.GetProfileByJobID(context.Background(), job.JobID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.ID != before.ID || !reflect.DeepEqual(after.Skills["technical"], []string{"Go", "SQL", "Python"}) {
+				t.Errorf("stored profile %d = %v, want profile %d replaced", after.ID, after.Skills, before.ID)
+			}
+
+			// The job keeps its status and is analyzed in the configured number of passes
+			if job := ta.repo.job(t, job.JobID); job.Status != "completed" {
+				t.Errorf("job status = %q after reanalysis, want completed", job.Status)
+			}
+			var passes []AnalysisPass
+			for _, call := range ta.llm.calls()[callsBefore:] {
+				passes = append(passes, call.Pass)
+				if len(call.RetrievedChunks) == 0 {
+					t.Errorf("%q pass has no chunks retrieved from the stored embeddings", call.Pass)
+				}
+			}
+			wantPasses := []AnalysisPass{AnalysisPassFull}
+			if mode == AnalysisModeTwoPass {
+				wantPasses = []AnalysisPass{AnalysisPassBasics, AnalysisPassDeep}
+			}
+			if !reflect.DeepEqual(passes, wantPasses) {
+				t.Errorf("passes = %q, want %q", passes, wantPasses)
+			}
+		})
+	}
+}
+
+func TestReanalyzeJobRequiresCompletedJob(t *testing.T) {
+	ta := newTestAnalyzer(t, nil)
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go")
+
+	ta.llm.err = errors.New("LLM down")
+	job := ta.analyze(t, intPtr(7), 1)
+
+	if _, err := ta.ReanalyzeJob(context.Background(), job.JobID); !errors.Is(err, ErrJobNotCompleted) {
+		t.Errorf("error = %v, want ErrJobNotCompleted", err)
+	}
+	if _, err := ta.ReanalyzeJob(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("error = %v, want ErrJobNotFound", err)
+	}
+}
+
+// passLLM answers each analysis pass with its part of a profile. The deep pass waits
+// for release to be closed, if it is set, and the basics pass fails while basicsErr is set.
+type passLLM struct {
+	LLMClient
+
+	release   chan struct{}
+	basicsErr error
+
+	mu     sync.Mutex
+	passes []AnalysisPass
+}
+
+func (l *passLLM) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
+	l.mu.Lock()
+	l.passes = append(l.passes, request.Pass)
+	l.mu.Unlock()
+
+	name, email, summary := "Ada", "ada@example.com", "Backend engineer."
+	basics := &AnalysisResponse{Name: &name, Email: &email, Skills: map[string][]string{"technical": {"Go"}}}
+	deep := &AnalysisResponse{Summary: &summary, Experience: []models.ExperienceEntry{{Company: "Acme", Role: "Engineer", Years: 3}}}
+
+	switch request.Pass {
+	case AnalysisPassBasics:
+		if l.basicsErr != nil {
+			return nil, l.basicsErr
+		}
+		return basics, nil
+	case AnalysisPassDeep:
+		if l.release != nil {
+			select {
+			case <-l.release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return deep, nil
+	default:
+		full := *deep
+		full.Name, full.Email, full.Skills = basics.Name, basics.Email, basics.Skills
+		return &full, nil
+	}
+}
+
+// calls returns the passes requested so far
+func (l *passLLM) calls() []AnalysisPass {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AnalysisPass(nil), l.passes...)
+}
+
+// checkCompleteProfile fails the test unless result has both passes' fields
+func checkCompleteProfile(t *testing.T, result *models.AnalysisResult) {
+	t.Helper()
+	if result.Name == nil || *result.Name != "Ada" || result.Email == nil || !reflect.DeepEqual(result.Skills["technical"], []string{"Go"}) {
+		t.Errorf("result lacks the basics: %+v", result)
+	}
+	if result.Summary == nil || *result.Summary != "Backend engineer." || len(result.Experience) != 1 {
+		t.Errorf("result lacks the deep analysis: %+v", result)
+	}
+}
+
+func TestTwoPassAnalysisSurfacesBasicsEarly(t *testing.T) {
+	llm := &passLLM{release: make(chan struct{})}
+	ta := newTestAnalyzer(t, func(config *Config) { config.AnalysisMode = AnalysisModeTwoPass })
+	ta.llmClient = llm
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go services at Acme.")
+
+	jobID, err := ta.AnalyzeAsync(context.Background(), 1, intPtr(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// While the deep pass runs, the status carries the basics but no result is ready
+	var status *models.AnalysisStatus
+	for deadline := time.Now().Add(5 * time.Second); status == nil || status.Basics == nil; {
+		if time.Now().After(deadline) {
+			t.Fatalf("no basics before the deep pass finished: %+v", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if status, err = ta.GetStatus(context.Background(), jobID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status.Status != "analyzing" || status.Progress < 60 || status.Progress >= 95 {
+		t.Errorf("status = %s at %d%%, want analyzing between 60%% and 95%%", status.Status, status.Progress)
+	}
+	if status.Basics.Name == nil || *status.Basics.Name != "Ada" || !reflect.DeepEqual(status.Basics.Skills["technical"], []string{"Go"}) {
+		t.Errorf("basics = %+v, want the basics pass result", status.Basics)
+	}
+	if _, err := ta.GetResult(context.Background(), jobID); !errors.Is(err, ErrJobNotCompleted) {
+		t.Errorf("result available before the deep pass: %v", err)
+	}
+
+	close(llm.release)
+	ta.waitForStatus(t, jobID, "completed")
+
+	result, err := ta.GetResult(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCompleteProfile(t, result)
+	if passes := llm.calls(); !reflect.DeepEqual(passes, []AnalysisPass{AnalysisPassBasics, AnalysisPassDeep}) {
+		t.Errorf("passes = %q, want basics then deep", passes)
+	}
+}
+
+func TestTwoPassAnalysisFallsBackToSinglePass(t *testing.T) {
+	llm := &passLLM{basicsErr: errors.New("basics prompt rejected")}
+	ta := newTestAnalyzer(t, func(config *Config) { config.AnalysisMode = AnalysisModeTwoPass })
+	ta.llmClient = llm
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go services at Acme.")
+
+	job := ta.analyze(t, intPtr(7), 1)
+	if job.Status != "completed" {
+		t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+	}
+
+	result, err := ta.GetResult(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCompleteProfile(t, result)
+	if passes := llm.calls(); !reflect.DeepEqual(passes, []AnalysisPass{AnalysisPassBasics, AnalysisPassFull}) {
+		t.Errorf("passes = %q, want basics then a full pass", passes)
+	}
+}
+
+func TestSinglePassAnalysis(t *testing.T) {
+	llm := &passLLM{}
+	ta := newTestAnalyzer(t, nil)
+	ta.llmClient = llm
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, "Go services at Acme.")
+
+	job := ta.analyze(t, intPtr(7), 1)
+	result, err := ta.GetResult(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCompleteProfile(t, result)
+
+	if passes := llm.calls(); !reflect.DeepEqual(passes, []AnalysisPass{AnalysisPassFull}) {
+		t.Errorf("passes = %q, want one full pass", passes)
+	}
+	if status, _ := ta.GetStatus(context.Background(), job.JobID); status.Basics != nil {
+		t.Errorf("basics stored in single-pass mode: %+v", status.Basics)
+	}
+}
+
+func TestMergeAnalysisPasses(t *testing.T) {
+	basicsName, deepName, summary := "Ada Lovelace", "A. Lovelace", "Engineer."
+	high, low := 0.9, 0.4
+
+	basics := &AnalysisResponse{
+		Name:            &basicsName,
+		Skills:          map[string][]string{"technical": {"Go"}},
+		FieldConfidence: map[string]*float64{"name": &high, "summary": &low},
+	}
+	deep := &AnalysisResponse{
+		Name:            &deepName,
+		Location:        strPtr("London"),
+		Summary:         &summary,
+		FieldConfidence: map[string]*float64{"name": &low, "summary": &high},
+	}
+
+	merged := mergeAnalysisPasses(basics, deep)
+	if *merged.Name != basicsName {
+		t.Errorf("name = %q, want the basics pass's", *merged.Name)
+	}
+	if merged.Location == nil || *merged.Location != "London" {
+		t.Errorf("location = %v, want the deep pass's when basics left it empty", merged.Location)
+	}
+	if merged.Summary != &summary || !reflect.DeepEqual(merged.Skills, basics.Skills) {
+		t.Errorf("merged = %+v, want the deep summary and basics skills", merged)
+	}
+	if *merged.FieldConfidence["name"] != high || *merged.FieldConfidence["summary"] != high {
+		t.Errorf("confidence = name %v, summary %v, want each from the pass that owns the field",
+			*merged.FieldConfidence["name"], *merged.FieldConfidence["summary"])
+	}
+	if *deep.Name != deepName {
+		t.Errorf("merging changed the deep pass's response")
+	}
+}
+
+// blockingLLM holds every analysis until release is closed
+type blockingLLM struct {
+	LLMClient
+
+	release chan struct{}
+}
+
+func (l *blockingLLM) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
+	select {
+	case <-l.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	name := "Ada"
+	return &AnalysisResponse{Name: &name}, nil
+}
+
+// waitForQueue polls the queue stats until running and queued jobs match
+func waitForQueue(t *testing.T, ta *testAnalyzer, running, queued int) QueueStats {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := ta.QueueStats()
+		if stats.Running == running && stats.Queued == queued {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queue stats = %+v, want %d running and %d queued", stats, running, queued)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueStatsAndBackpressure(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxQueued int
+		submitted int // Jobs submitted while the only worker is busy
+		wantQueue int // Jobs queued behind it
+	}{
+		{"rejects over the high-water mark", 2, 4, 2},
+		{"unlimited without a high-water mark", 0, 4, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &blockingLLM{release: make(chan struct{})}
+			ta := newTestAnalyzer(t, func(config *Config) {
+				config.MaxConcurrentJobs = 1
+				config.MaxQueuedJobs = tt.maxQueued
+			})
+			ta.llmClient = llm
+			for id := 1; id <= tt.submitted; id++ {
+				ta.uploads.add(&models.Upload{ID: id}, "Go services at Acme.")
+			}
+
+			if stats := ta.QueueStats(); stats != (QueueStats{Capacity: 1, MaxQueued: tt.maxQueued, AcceptingJobs: true}) {
+				t.Errorf("idle stats = %+v", stats)
+			}
+
+			var jobIDs []string
+			rejected := 0
+			for id := 1; id <= tt.submitted; id++ {
+				jobID, err := ta.AnalyzeAsync(context.Background(), id, nil)
+				switch {
+				case errors.Is(err, ErrQueueFull):
+					rejected++
+				case err != nil:
+					t.Fatalf("AnalyzeAsync(%d): %v", id, err)
+				default:
+					jobIDs = append(jobIDs, jobID)
+				}
+				if id == 1 {
+					// Let the first job take the worker so the others queue behind it
+					waitForQueue(t, ta, 1, 0)
+				}
+			}
+
+			if want := tt.submitted - 1 - tt.wantQueue; rejected != want {
+				t.Errorf("rejected %d jobs, want %d", rejected, want)
+			}
+			stats := waitForQueue(t, ta, 1, tt.wantQueue)
+			if wantAccepting := tt.maxQueued == 0; stats.AcceptingJobs != wantAccepting {
+				t.Errorf("accepting jobs = %t under load, want %t", stats.AcceptingJobs, wantAccepting)
+			}
+
+			close(llm.release)
+			for _, jobID := range jobIDs {
+				ta.waitForStatus(t, jobID, "completed")
+			}
+			if stats := waitForQueue(t, ta, 0, 0); !stats.AcceptingJobs {
+				t.Errorf("drained queue still rejects jobs: %+v", stats)
+			}
+		})
+	}
+}
+
+// fixedLLM answers every analysis with a copy of response
+type fixedLLM struct {
+	LLMClient
+
+	response AnalysisResponse
+}
+
+func (l *fixedLLM) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
+	response := l.response
+	response.Experience = append([]models.ExperienceEntry(nil), l.response.Experience...)
+	return &response, nil
+}
+
+func TestJobDatesUseTheAnalyzerClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC))
+	ta := newTestAnalyzer(t, func(config *Config) { config.Clock = fake })
+	ta.llmClient = &fixedLLM{response: AnalysisResponse{Experience: []models.ExperienceEntry{
+		{Company: "Acme", Role: "Engineer", StartDate: strPtr("2024-01"), EndDate: strPtr("Present"), Years: 3},
+		{Company: "Initech", Role: "Lead", StartDate: strPtr("2025-02"), EndDate: strPtr("2025-06")},
+	}}}
+	ta.uploads.add(&models.Upload{ID: 1}, "Go services at Acme.")
+
+	job := ta.analyze(t, nil, 1)
+	result, err := ta.GetResult(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Experience) != 2 {
+		t.Fatalf("experience = %+v, want 2 entries", result.Experience)
+	}
+
+	// "Present" is December 2024 on the fake clock: January through December is a year
+	current, future := result.Experience[0], result.Experience[1]
+	if current.Years != 1 || len(current.Flags) != 0 {
+		t.Errorf("current role = %.1f years, flags %v; want 1 year and no flags", current.Years, current.Flags)
+	}
+	if !reflect.DeepEqual(future.Flags, []string{ExperienceFlagFutureDate}) {
+		t.Errorf("role starting after the fake clock has flags %v, want %q", future.Flags, ExperienceFlagFutureDate)
+	}
+}
+
+func TestResultMergesOverlappingRoles(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC))
+	ta := newTestAnalyzer(t, func(config *Config) { config.Clock = fake })
+
+	// A full-time job with a concurrent side role: the LLM sums them to 6 years
+	naive := 6.0
+	ta.llmClient = &fixedLLM{response: AnalysisResponse{
+		TotalWorkYears: &naive,
+		Experience: []models.ExperienceEntry{
+			{Company: "Acme", Role: "Engineer", StartDate: strPtr("2020-01"), EndDate: strPtr("2023-12"), Years: 4},
+			{Company: "Initech", Role: "Advisor", StartDate: strPtr("2021-01"), EndDate: strPtr("2022-12"), Years: 2},
+		},
+	}}
+	ta.uploads.add(&models.Upload{ID: 1}, "Go services at Acme.")
+
+	job := ta.analyze(t, nil, 1)
+	result, err := ta.GetResult(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.TotalWorkYears == nil || *result.TotalWorkYears != 4 {
+		t.Errorf("total work years = %v, want the 4 merged years", derefOrNil(result.TotalWorkYears))
+	}
+	if result.ReportedWorkYears == nil || *result.ReportedWorkYears != naive {
+		t.Errorf("reported work years = %v, want the LLM's %v kept for review", derefOrNil(result.ReportedWorkYears), naive)
+	}
+}
+
+func TestReanalyzeAllAsync(t *testing.T) {
+	const userID = 7
+	ta := newTestAnalyzer(t, nil)
+	for id := 1; id <= 5; id++ {
+		ta.uploads.add(&models.Upload{ID: id, UserID: intPtr(userID)}, "Go services at Acme.")
+	}
+	ta.uploads.add(&models.Upload{ID: 6, UserID: intPtr(8)}, "Another user's resume.")
+
+	// Upload 1 has a finished job, 2 a running one and 3 is merged into a queued one
+	ctx := context.Background()
+	for _, job := range []*models.AnalysisJob{
+		{JobID: "done", UploadID: 1, UserID: intPtr(userID), Status: "completed"},
+		{JobID: "running", UploadID: 2, UserID: intPtr(userID), Status: "analyzing"},
+		{JobID: "merged", UploadID: 4, AdditionalUploadIDs: []int64{3}, UserID: intPtr(userID), Status: "queued"},
+	} {
+		if err := ta.repo.CreateJob(ctx, job); err != nil {
+			t.Fatalf("CreateJob: %v", err)
+		}
+	}
+
+	t.Run("skips uploads in progress", func(t *testing.T) {
+		result, err := ta.ReanalyzeAllAsync(ctx, userID, nil)
+		if err != nil {
+			t.Fatalf("ReanalyzeAllAsync: %v", err)
+		}
+		if !reflect.DeepEqual(result.UploadIDs, []int{1, 5}) {
+			t.Errorf("started uploads = %v, want [1 5]", result.UploadIDs)
+		}
+		if !reflect.DeepEqual(result.SkippedUploadIDs, []int{2, 3, 4}) {
+			t.Errorf("skipped uploads = %v, want [2 3 4]", result.SkippedUploadIDs)
+		}
+		if len(result.DeferredUploadIDs) != 0 {
+			t.Errorf("deferred uploads = %v, want none", result.DeferredUploadIDs)
+		}
+
+		if len(result.JobIDs) != len(result.UploadIDs) {
+			t.Fatalf("%d jobs for %d uploads", len(result.JobIDs), len(result.UploadIDs))
+		}
+		for i, jobID := range result.JobIDs {
+			job := ta.waitForStatus(t, jobID, "completed")
+			if job.UploadID != result.UploadIDs[i] || job.UserID == nil || *job.UserID != userID {
+				t.Errorf("job %s is for upload %d of user %v, want upload %d of user %d", jobID, job.UploadID, job.UserID, result.UploadIDs[i], userID)
+			}
+		}
+	})
+
+	t.Run("defers uploads once not allowed", func(t *testing.T) {
+		allowed := 1
+		allow := func() bool {
+			allowed--
+			return allowed >= 0
+		}
+
+		result, err := ta.ReanalyzeAllAsync(ctx, userID, allow)
+		if err != nil {
+			t.Fatalf("ReanalyzeAllAsync: %v", err)
+		}
+		if !reflect.DeepEqual(result.UploadIDs, []int{1}) || !reflect.DeepEqual(result.DeferredUploadIDs, []int{5}) {
+			t.Errorf("started %v and deferred %v, want [1] and [5]", result.UploadIDs, result.DeferredUploadIDs)
+		}
+		for _, jobID := range result.JobIDs {
+			ta.waitForStatus(t, jobID, "completed")
+		}
+	})
+}
+
+// countingEmbedder counts the texts embedded through it
+type countingEmbedder struct {
+	EmbeddingGenerator
+
+	mu    sync.Mutex
+	texts int
+}
+
+func (e *countingEmbedder) GenerateEmbeddings(ctx context.Context, texts []string, progress EmbeddingProgress, onBatch EmbeddedBatch) ([][]float32, error) {
+	e.mu.Lock()
+	e.texts += len(texts)
+	e.mu.Unlock()
+	return e.EmbeddingGenerator.GenerateEmbeddings(ctx, texts, progress, onBatch)
+}
+
+// embedded returns the number of texts embedded so far
+func (e *countingEmbedder) embedded() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.texts
+}
+
+func TestAnalysisReusesStoredEmbeddings(t *testing.T) {
+	embedder := &countingEmbedder{EmbeddingGenerator: NewPlaceholderEmbeddingGenerator(16)}
+	ta := newTestAnalyzerWithEmbedder(t, embedder, func(config *Config) { config.ChunkSize = 20 })
+
+	text := "Go services at Acme. SQL databases at Globex. Go services at Acme."
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7)}, text)
+	ta.uploads.add(&models.Upload{ID: 2, UserID: intPtr(7)}, text)
+
+	if job := ta.analyze(t, intPtr(7), 1); job.Status != "completed" {
+		t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+	}
+	first := embedder.embedded()
+	if first == 0 {
+		t.Fatal("nothing embedded")
+	}
+	vectors := len(ta.store.byHash)
+	if vectors > first {
+		t.Errorf("%d vectors stored for %d embedded chunks", vectors, first)
+	}
+
+	// The same document, uploaded again, is analyzed without embedding anything
+	if job := ta.analyze(t, intPtr(7), 2); job.Status != "completed" {
+		t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+	}
+	if got := embedder.embedded(); got != first {
+		t.Errorf("embedded %d more chunks for an identical document, want 0", got-first)
+	}
+	if got := len(ta.store.byHash); got != vectors {
+		t.Errorf("%d vectors stored after the second upload, want %d", got, vectors)
+	}
+	if len(ta.store.store[2]) != len(ta.store.store[1]) {
+		t.Errorf("upload 2 has %d chunks, want %d like upload 1", len(ta.store.store[2]), len(ta.store.store[1]))
+	}
+}
+
+func TestEmbedChunksResumesAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// One batch of two at a time; the third batch cancels the job while it is embedded
+	gen, _ := newIndexEmbedder(t, 2, 1, func(first int) time.Duration {
+		if first == 4 {
+			cancel()
+			return time.Hour
+		}
+		return 0
+	}, -1)
+	ta := newTestAnalyzerWithEmbedder(t, gen, nil)
+
+	chunks := indexedTexts(10)
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		hashes[i] = ChunkHash(chunk)
+	}
+
+	if _, _, err := ta.embedChunks(ctx, "acme", 1, chunks, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want the job's cancellation", err)
+	}
+
+	stored, err := ta.store.LookupEmbeddings(context.Background(), "acme", hashes)
+	if err != nil {
+		t.Fatalf("LookupEmbeddings: %v", err)
+	}
+	if len(stored) != 4 {
+		t.Errorf("%d chunks stored, want the 4 of the batches completed before the cancel", len(stored))
+	}
+	for i := range 4 {
+		if embedding := stored[hashes[i]]; len(embedding) != 1 || embedding[0] != float32(i) {
+			t.Errorf("chunk %d stored as %v, want [%d]", i, embedding, i)
+		}
+	}
+	if got := len(ta.store.store[1]); got != 4 {
+		t.Errorf("upload 1 has %d chunks, want 4", got)
+	}
+
+	// The retry only embeds the chunks that weren't stored
+	gen, _ = newIndexEmbedder(t, 2, 1, func(int) time.Duration { return 0 }, -1)
+	retry := &countingEmbedder{EmbeddingGenerator: gen}
+	ta.DefaultResumeAnalyzer.embedder = retry
+
+	embeddings, generated, err := ta.embedChunks(context.Background(), "acme", 1, chunks, nil, nil)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if generated != 6 || retry.embedded() != 6 {
+		t.Errorf("retry generated %d and embedded %d chunks, want 6", generated, retry.embedded())
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) != 1 || embedding[0] != float32(i) {
+			t.Errorf("embedding %d = %v, want [%d]", i, embedding, i)
+		}
+	}
+	if got := len(ta.store.store[1]); got != len(chunks) {
+		t.Errorf("upload 1 has %d chunks after the retry, want %d", got, len(chunks))
+	}
+}
+
+func TestAnalyzeSync(t *testing.T) {
+	const text = "Backend engineer writing Go services backed by SQL databases."
+
+	t.Run("small document finishes synchronously", func(t *testing.T) {
+		ta := newTestAnalyzer(t, nil)
+		ta.uploads.add(&models.Upload{ID: 1}, text)
+
+		jobID, finished, err := ta.AnalyzeSync(context.Background(), []int{1}, nil, 5*time.Second)
+		if err != nil || !finished {
+			t.Fatalf("AnalyzeSync = %q, %v, %v; want a finished job", jobID, finished, err)
+		}
+		if job := ta.repo.job(t, jobID); job.Status != "completed" {
+			t.Errorf("job status = %q, want completed", job.Status)
+		}
+	})
+
+	t.Run("large document runs asynchronously", func(t *testing.T) {
+		llm := &blockingLLM{release: make(chan struct{})}
+		ta := newTestAnalyzer(t, func(config *Config) { config.SyncMaxBytes = len(text) - 1 })
+		ta.llmClient = llm
+		ta.uploads.add(&models.Upload{ID: 1}, text)
+
+		// The job can't finish before it is released, so returning at all means no wait
+		jobID, finished, err := ta.AnalyzeSync(context.Background(), []int{1}, nil, time.Minute)
+		if err != nil || finished {
+			t.Fatalf("AnalyzeSync = %q, %v, %v; want an unfinished job", jobID, finished, err)
+		}
+		close(llm.release)
+		ta.waitForStatus(t, jobID, "completed")
+	})
+
+	t.Run("slow analysis continues in the background", func(t *testing.T) {
+		llm := &blockingLLM{release: make(chan struct{})}
+		ta := newTestAnalyzer(t, nil)
+		ta.llmClient = llm
+		ta.uploads.add(&models.Upload{ID: 1}, text)
+
+		start := time.Now()
+		jobID, finished, err := ta.AnalyzeSync(context.Background(), []int{1}, nil, 50*time.Millisecond)
+		if err != nil || finished {
+			t.Fatalf("AnalyzeSync = %q, %v, %v; want an unfinished job", jobID, finished, err)
+		}
+		if waited := time.Since(start); waited < 50*time.Millisecond {
+			t.Errorf("returned after %s, before the wait was over", waited)
+		}
+		close(llm.release)
+		ta.waitForStatus(t, jobID, "completed")
+	})
+}
+
+// failingLLM fails every analysis
+type failingLLM struct {
+	LLMClient
+}
+
+func (failingLLM) Analyze(ctx context.Context, request *AnalysisRequest) (*AnalysisResponse, error) {
+	return nil, errors.New("model unavailable")
+}
+
+func TestRetriesBeyondTheLimitDeadLetterTheJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+	}{
+		{"no retries", 0},
+		{"two retries", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) { config.MaxRetries = intPtr(tt.maxRetries) })
+			ta.llmClient = failingLLM{}
+			ta.uploads.add(&models.Upload{ID: 1}, "Go services at Acme.")
+
+			job := ta.analyze(t, nil, 1)
+			for retry := 1; retry <= tt.maxRetries; retry++ {
+				if job.Status != "failed" {
+					t.Fatalf("before retry %d: status = %q, want failed", retry, job.Status)
+				}
+				if err := ta.RetryJob(context.Background(), job.JobID); err != nil {
+					t.Fatalf("retry %d: %v", retry, err)
+				}
+				if retry < tt.maxRetries {
+					job = ta.waitForStatus(t, job.JobID, "failed")
+				}
+			}
+
+			// The last allowed attempt failing dead-letters the job without another retry
+			job = ta.waitForStatus(t, job.JobID, "dead_lettered")
+			if job.RetryCount != tt.maxRetries {
+				t.Errorf("retry count = %d, want %d", job.RetryCount, tt.maxRetries)
+			}
+			if job.ErrorMessage == nil || !strings.Contains(*job.ErrorMessage, "model unavailable") {
+				t.Errorf("error message = %v, want it to keep the last error", job.ErrorMessage)
+			}
+			if err := ta.RetryJob(context.Background(), job.JobID); err == nil {
+				t.Error("dead-lettered job was retried")
+			}
+		})
+	}
+}
+
+func TestRetryJobOverTheLimitDeadLettersTheJob(t *testing.T) {
+	ta := newTestAnalyzer(t, func(config *Config) { config.MaxRetries = intPtr(3) })
+	ta.llmClient = failingLLM{}
+	ta.uploads.add(&models.Upload{ID: 1}, "Go services at Acme.")
+
+	// A job that used its retries under a higher limit is still failed
+	job := ta.analyze(t, nil, 1)
+	ta.repo.update(context.Background(), job.JobID, func(job *models.AnalysisJob) {
+		job.Status = "failed"
+		job.RetryCount = 5
+	})
+
+	if err := ta.RetryJob(context.Background(), job.JobID); !errors.Is(err, ErrRetryLimitReached) {
+		t.Fatalf("RetryJob = %v, want ErrRetryLimitReached", err)
+	}
+	if job := ta.repo.job(t, job.JobID); job.Status != "dead_lettered" || job.RetryCount != 5 {
+		t.Errorf("job = %s after %d retries, want dead_lettered after 5", job.Status, job.RetryCount)
+	}
+}
+
+func TestResultReportsFieldConfidence(t *testing.T) {
+	ta := newTestAnalyzer(t, nil)
+	name, high := "Ada", 0.95
+	ta.llmClient = &fixedLLM{response: AnalysisResponse{Name: &name, FieldConfidence: map[string]*float64{"name": &high}}}
+	ta.uploads.add(&models.Upload{ID: 1}, "Ada, Go services at Acme.")
+
+	job := ta.analyze(t, nil, 1)
+	result, err := ta.GetResult(context.Background(), job.JobID)
+	if err != nil {
+		t.Fatalf("GetResult: %v", err)
+	}
+
+	if len(result.FieldConfidence) != len(models.ProfileFields) {
+		t.Errorf("result has confidence for %d fields, want all %d", len(result.FieldConfidence), len(models.ProfileFields))
+	}
+	wantFloat(t, "name confidence", result.FieldConfidence["name"], high)
+	if score, ok := result.FieldConfidence["location"]; !ok || score != nil {
+		t.Errorf("location confidence = %v (present %v), want null", score, ok)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	llm := &blockingLLM{release: make(chan struct{})}
+	ta := newTestAnalyzer(t, func(config *Config) { config.MaxConcurrentJobs = 1 })
+	for id := 1; id <= 3; id++ {
+		ta.uploads.add(&models.Upload{ID: id}, "Go services at Acme.")
+	}
+
+	finished := ta.analyze(t, nil, 3)
+	ta.llmClient = llm
+
+	processing, err := ta.AnalyzeAsync(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeAsync: %v", err)
+	}
+	ta.waitForStatus(t, processing, "analyzing")
+	queued, err := ta.AnalyzeAsync(context.Background(), 2, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeAsync: %v", err)
+	}
+	waitForQueue(t, ta, 1, 1)
+
+	wantCancelled := func(jobID string) {
+		t.Helper()
+		job := ta.repo.job(t, jobID)
+		if job.Status != "failed" || job.ErrorCode == nil || *job.ErrorCode != models.JobErrorCancelled ||
+			job.ErrorMessage == nil || *job.ErrorMessage != CancelledJobMessage {
+			t.Errorf("job %s = %s (code %v, message %v), want it cancelled", jobID, job.Status, job.ErrorCode, job.ErrorMessage)
+		}
+	}
+
+	t.Run("queued job", func(t *testing.T) {
+		if err := ta.CancelJob(context.Background(), queued); err != nil {
+			t.Fatalf("CancelJob: %v", err)
+		}
+		wantCancelled(queued)
+		waitForQueue(t, ta, 1, 0)
+	})
+
+	t.Run("processing job", func(t *testing.T) {
+		if err := ta.CancelJob(context.Background(), processing); err != nil {
+			t.Fatalf("CancelJob: %v", err)
+		}
+		// The worker has exited by the time CancelJob returns
+		ta.mu.Lock()
+		running := len(ta.running)
+		ta.mu.Unlock()
+		if running != 0 {
+			t.Errorf("%d jobs still running", running)
+		}
+		wantCancelled(processing)
+		waitForQueue(t, ta, 0, 0)
+	})
+
+	t.Run("finished job is left as it is", func(t *testing.T) {
+		if err := ta.CancelJob(context.Background(), finished.JobID); err != nil {
+			t.Fatalf("CancelJob: %v", err)
+		}
+		if job := ta.repo.job(t, finished.JobID); job.Status != "completed" {
+			t.Errorf("finished job became %s", job.Status)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		if err := ta.CancelJob(context.Background(), "job_missing"); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
+
+// scoredStore answers upload searches with fixed results, recording the searches. Methods a
+// test needs but the fake doesn't implement panic through the embedded nil interface.
+type scoredStore struct {
+	VectorStore
+
+	filtered []SearchResult // Returned for searches filtered by section
+	results  []SearchResult // Returned for unfiltered searches
+	err      error
+
+	tenants []string
+	limits  []int
+	filters []SearchFilter
+}
+
+func (s *scoredStore) SearchSimilarInUpload(ctx context.Context, tenantID string, uploadID int, query string, limit int, filter SearchFilter) ([]SearchResult, error) {
+	s.tenants = append(s.tenants, tenantID)
+	s.limits = append(s.limits, limit)
+	s.filters = append(s.filters, filter)
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(filter.Sections) > 0 {
+		return s.filtered, nil
+	}
+	return s.results, nil
+}
+
+func TestRetrieveChunks(t *testing.T) {
+	scored := []SearchResult{
+		{Chunk: "Go at Acme", Score: 0.9},
+		{Chunk: "SQL at Initech", Score: 0.6},
+		{Chunk: "Hobbies", Score: 0.4},
+		{Chunk: "Kubernetes at Globex", Score: 0.7},
+		{Chunk: "References", Score: 0.2},
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		minScore  float64
+		store     *scoredStore
+		want      []string
+		wantLimit int
+	}{
+		{
+			name:      "count capped",
+			limit:     3,
+			store:     &scoredStore{filtered: scored},
+			want:      []string{"Go at Acme", "SQL at Initech", "Hobbies"},
+			wantLimit: 3,
+		},
+		{
+			name:      "low scores excluded",
+			minScore:  0.5,
+			store:     &scoredStore{filtered: scored},
+			want:      []string{"Go at Acme", "SQL at Initech", "Kubernetes at Globex"},
+			wantLimit: DefaultRetrievalLimit,
+		},
+		{
+			name:      "cap applies to the chunks passing the threshold",
+			limit:     2,
+			minScore:  0.5,
+			store:     &scoredStore{filtered: scored},
+			want:      []string{"Go at Acme", "SQL at Initech"},
+			wantLimit: 2,
+		},
+		{
+			name:      "no chunk scores enough",
+			minScore:  0.95,
+			store:     &scoredStore{filtered: scored},
+			want:      []string{},
+			wantLimit: DefaultRetrievalLimit,
+		},
+		{
+			name:      "unlabelled chunks searched without the section filter",
+			minScore:  0.5,
+			store:     &scoredStore{results: scored[3:]},
+			want:      []string{"Kubernetes at Globex"},
+			wantLimit: DefaultRetrievalLimit,
+		},
+		{
+			name:      "search failure",
+			store:     &scoredStore{err: errors.New("store unavailable")},
+			want:      []string{},
+			wantLimit: DefaultRetrievalLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) {
+				config.RetrievalLimit = tt.limit
+				config.MinRelevanceScore = tt.minScore
+			})
+			ta.vectorStore = tt.store
+
+			got := ta.retrieveChunks(context.Background(), "acme", 1)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+			for _, tenantID := range tt.store.tenants {
+				if tenantID != "acme" {
+					t.Errorf("searched tenant %q, want the upload's tenant acme", tenantID)
+				}
+			}
+			for _, limit := range tt.store.limits {
+				if limit != tt.wantLimit {
+					t.Errorf("searched for %d chunks, want %d", limit, tt.wantLimit)
+				}
+			}
+			if len(tt.store.filters) == 0 || len(tt.store.filters[0].Sections) == 0 {
+				t.Errorf("first search filters = %+v, want it restricted to sections", tt.store.filters)
+			}
+		})
+	}
+}
+
+func TestAnalysisStoresChunkSections(t *testing.T) {
+	ta := newTestAnalyzer(t, func(config *Config) { config.ChunkSize = 60 })
+	ta.uploads.add(&models.Upload{ID: 1, UserID: intPtr(7), FileName: "resume.pdf"},
+		"Jane Doe, backend engineer in Berlin.\n\nEXPERIENCE\nBuilt Go services and SQL pipelines at Acme for five years.\n\n"+
+			"EDUCATION\nBSc in Computer Science from the Technical University of Munich.")
+
+	job := ta.analyze(t, intPtr(7), 1)
+	if job.Status != "completed" {
+		t.Fatalf("job status = %q: %v", job.Status, job.ErrorMessage)
+	}
+
+	tests := []struct {
+		section string
+		want    string // Text one of the section's chunks has
+	}{
+		{SectionExperience, "Acme"},
+		{SectionEducation, "Munich"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.section, func(t *testing.T) {
+			results, err := ta.store.SearchSimilarInUpload(context.Background(), "", 1, "resume", 10, SearchFilter{Sections: []string{tt.section}})
+			if err != nil {
+				t.Fatalf("SearchSimilarInUpload: %v", err)
+			}
+			found := false
+			for _, result := range results {
+				if result.Section != tt.section {
+					t.Errorf("chunk %q of section %q returned for %q", result.Chunk, result.Section, tt.section)
+				}
+				found = found || strings.Contains(result.Chunk, tt.want)
+			}
+			if !found {
+				t.Errorf("no %s chunk mentions %q: %+v", tt.section, tt.want, results)
+			}
+		})
+	}
+}
+
+// recordingNotifier records the status of each job it is told about
+type recordingNotifier struct {
+	mu       sync.Mutex
+	statuses map[string]string
+}
+
+func (n *recordingNotifier) JobFinished(ctx context.Context, job *models.AnalysisJob) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.statuses[job.JobID] = job.Status
+	return nil
+}
+
+func (n *recordingNotifier) status(jobID string) (string, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	status, ok := n.statuses[jobID]
+	return status, ok
+}
+
+func TestFinishedJobsNotify(t *testing.T) {
+	notifier := &recordingNotifier{statuses: make(map[string]string)}
+	ta := newTestAnalyzer(t, func(config *Config) { config.Notifier = notifier })
+	ta.uploads.add(&models.Upload{ID: 1}, "Go services at Acme.")
+
+	waitForNotification := func(jobID, want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if status, ok := notifier.status(jobID); ok {
+				if status != want {
+					t.Errorf("notified about job %s as %s, want %s", jobID, status, want)
+				}
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("no notification about job %s", jobID)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	completed := ta.analyze(t, nil, 1)
+	waitForNotification(completed.JobID, "completed")
+
+	ta.llmClient = failingLLM{}
+	failed, err := ta.AnalyzeAsync(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeAsync: %v", err)
+	}
+	waitForNotification(failed, "failed")
+
+	// A cancelled job isn't reported: it neither completed nor failed on its own
+	llm := &blockingLLM{release: make(chan struct{})}
+	ta.llmClient = llm
+	cancelled, err := ta.AnalyzeAsync(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeAsync: %v", err)
+	}
+	ta.waitForStatus(t, cancelled, "analyzing")
+	if err := ta.CancelJob(context.Background(), cancelled); err != nil {
+		t.Fatalf("CancelJob: %v", err)
+	}
+	if status, ok := notifier.status(cancelled); ok {
+		t.Errorf("notified about the cancelled job as %s", status)
+	}
+}
+
+func TestMinTextLength(t *testing.T) {
+	const minimum = 20
+	tests := []struct {
+		name       string
+		text       string
+		wantStatus string
+	}{
+		// Whitespace doesn't count towards the minimum
+		{"just under", "  Go developer\n\n at AcmeCo  \f", "failed"},         // 19 characters
+		{"at the minimum", "  Go developer\n\n at AcmeCo.  \f", "completed"}, // 20 characters
+		{"just over", "Go developer at Acme, Inc.", "completed"},             // 22 characters
+		{"blank pages", "\n\f\n   \f\n", "failed"},                           // 0 characters
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) { config.MinTextLength = intPtr(minimum) })
+			ta.uploads.add(&models.Upload{ID: 1}, tt.text)
+
+			job := ta.analyze(t, nil, 1)
+			if job.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", job.Status, tt.wantStatus)
+			}
+			if tt.wantStatus == "completed" {
+				return
+			}
+
+			if job.ErrorCode == nil || *job.ErrorCode != models.JobErrorInsufficientText {
+				t.Errorf("error code = %v, want %s", derefOrNil(job.ErrorCode), models.JobErrorInsufficientText)
+			}
+			wantMessage := fmt.Sprintf("Insufficient text extracted: %d characters (minimum %d)", TextLength(tt.text), minimum)
+			if job.ErrorMessage == nil || *job.ErrorMessage != wantMessage {
+				t.Errorf("error message = %v, want %q", derefOrNil(job.ErrorMessage), wantMessage)
+			}
+			if calls := ta.llm.calls(); len(calls) != 0 {
+				t.Errorf("LLM called %d times for a job without enough text", len(calls))
+			}
+		})
+	}
+}
+
+func TestMinTextLengthConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *int
+		want   int
+	}{
+		{"default", nil, DefaultMinTextLength},
+		{"disabled", intPtr(0), 0},
+		{"negative disables", intPtr(-5), 0},
+		{"custom", intPtr(200), 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, func(config *Config) { config.MinTextLength = tt.config })
+			if ta.minTextLength != tt.want {
+				t.Errorf("minimum text length = %d, want %d", ta.minTextLength, tt.want)
+			}
+		})
+	}
+}
+
+// failingExtractor fails every extraction
+type failingExtractor struct{}
+
+func (failingExtractor) ExtractText(ctx context.Context, fileContent []byte, mimeType string) (string, error) {
+	return "", errors.New("PDF is password protected")
+}
+
+// failingChunker fails every split
+type failingChunker struct{}
+
+func (failingChunker) ChunkText(text string, chunkSize int, overlap int) ([]string, error) {
+	return nil, errors.New("tokenizer unavailable")
+}
+
+// failingStore fails to store embeddings, serving everything else from its VectorStore
+type failingStore struct {
+	VectorStore
+}
+
+func (failingStore) StoreEmbeddings(ctx context.Context, tenantID string, uploadID int, chunks []string, embeddings [][]float32, metadata []ChunkMetadata) error {
+	return errors.New("connection refused")
+}
+
+// failingProfileRepo fails to save profiles, keeping everything else in its fakeAnalysisRepo
+type failingProfileRepo struct {
+	*fakeAnalysisRepo
+}
+
+func (failingProfileRepo) SaveProfile(ctx context.Context, profile *models.UserProfile) error {
+	return errors.New("disk full")
+}
+
+func TestJobErrorCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        func(ta *testAnalyzer)
+		wantCode    string
+		wantMessage string
+	}{
+		{"extraction", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.extractor = failingExtractor{} }, models.JobErrorExtraction, "Upload 1: text extraction failed: PDF is password protected"},
+		{"chunking", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.chunker = failingChunker{} }, models.JobErrorChunking, "Text chunking failed: tokenizer unavailable"},
+		{"embedding", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.embedder = &stubEmbedder{} }, models.JobErrorEmbedding, "Embedding generation failed"},
+		{"vector storage", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.vectorStore = failingStore{ta.store} }, models.JobErrorStorage, "Vector storage failed: connection refused"},
+		{"llm", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.llmClient = failingLLM{} }, models.JobErrorLLM, "LLM analysis failed: model unavailable"},
+		{"profile storage", func(ta *testAnalyzer) { ta.DefaultResumeAnalyzer.analysisRepo = failingProfileRepo{ta.repo} }, models.JobErrorStorage, "Failed to save profile: disk full"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAnalyzer(t, nil)
+			ta.uploads.add(&models.Upload{ID: 1}, "Go developer at Acme.")
+			tt.fail(ta)
+
+			job := ta.analyze(t, nil, 1)
+			if job.Status != "failed" {
+				t.Fatalf("status = %s, want failed", job.Status)
+			}
+			if job.ErrorCode == nil || *job.ErrorCode != tt.wantCode {
+				t.Errorf("error code = %v, want %s", derefOrNil(job.ErrorCode), tt.wantCode)
+			}
+			if job.ErrorMessage == nil || !strings.HasPrefix(*job.ErrorMessage, tt.wantMessage) {
+				t.Errorf("error message = %v, want it to start with %q", derefOrNil(job.ErrorMessage), tt.wantMessage)
+			}
+
+			status, err := ta.GetStatus(context.Background(), job.JobID)
+			if err != nil {
+				t.Fatalf("GetStatus: %v", err)
+			}
+			if status.ErrorCode == nil || *status.ErrorCode != tt.wantCode {
+				t.Errorf("status error code = %v, want %s", derefOrNil(status.ErrorCode), tt.wantCode)
+			}
+		})
+	}
+}
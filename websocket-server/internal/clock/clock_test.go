@@ -0,0 +1,40 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake := NewFake(start)
+
+	if got := fake.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	if got := fake.Now(); !got.Equal(start) {
+		t.Errorf("fake clock moved on its own to %v", got)
+	}
+
+	fake.Advance(90 * time.Second)
+	if want := start.Add(90 * time.Second); !fake.Now().Equal(want) {
+		t.Errorf("after Advance, Now() = %v, want %v", fake.Now(), want)
+	}
+
+	later := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	fake.Set(later)
+	if !fake.Now().Equal(later) {
+		t.Errorf("after Set, Now() = %v, want %v", fake.Now(), later)
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	if _, ok := OrReal(nil).(Real); !ok {
+		t.Errorf("OrReal(nil) is not the system clock")
+	}
+
+	fake := NewFake(time.Time{})
+	if OrReal(fake) != Clock(fake) {
+		t.Errorf("OrReal replaced a given clock")
+	}
+}
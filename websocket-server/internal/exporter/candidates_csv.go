@@ -0,0 +1,80 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// candidatesCSVHeader is the header row of ExportCandidatesCSV
+var candidatesCSVHeader = []string{
+	"upload_id", "file_name", "name", "top_skills", "total_work_years",
+	"recommended_roles", "status", "analyzed_at", "tags", "job_id",
+}
+
+// candidatesCSVSeparator joins the values of list columns within a cell
+const candidatesCSVSeparator = "; "
+
+// ExportCandidatesCSV exports candidate summaries as a CSV with one row per candidate,
+// for importing into applicant tracking systems. List columns (skills, roles, tags) are
+// joined with "; ", analyzed_at is RFC 3339 in UTC, and columns a candidate has no value
+// for are left empty.
+func ExportCandidatesCSV(candidates []*models.CandidateSummary) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write(candidatesCSVHeader)
+
+	for _, c := range candidates {
+		var name, years, analyzedAt, jobID string
+		if c.Name != nil {
+			name = *c.Name
+		}
+		if c.TotalWorkYears != nil {
+			years = fmt.Sprintf("%.1f", *c.TotalWorkYears)
+		}
+		if c.AnalyzedAt != nil {
+			analyzedAt = c.AnalyzedAt.UTC().Format(time.RFC3339)
+		}
+		if c.JobID != nil {
+			jobID = *c.JobID
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(c.UploadID),
+			flattenCell(c.FileName),
+			flattenCell(name),
+			joinCandidateList(c.TopSkills),
+			years,
+			joinCandidateList(c.RecommendedRoles),
+			c.Status,
+			analyzedAt,
+			joinCandidateList(c.Tags),
+			jobID,
+		})
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// joinCandidateList joins the values of a list column into one single-line cell
+func joinCandidateList(values []string) string {
+	cells := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(flattenCell(value)); value != "" {
+			cells = append(cells, value)
+		}
+	}
+	return strings.Join(cells, candidatesCSVSeparator)
+}
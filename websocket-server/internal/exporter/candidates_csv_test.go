@@ -0,0 +1,61 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestExportCandidatesCSV(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	years := 6.5
+	analyzedAt := time.Date(2024, 5, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	data, err := ExportCandidatesCSV([]*models.CandidateSummary{
+		{
+			UploadID:         1,
+			FileName:         "ada.pdf",
+			Name:             strPtr("Ada \"Countess\"\nLovelace"),
+			TopSkills:        []string{"Go", " ", "SQL; Postgres"},
+			TotalWorkYears:   &years,
+			RecommendedRoles: []string{"Staff Engineer", "Tech Lead"},
+			Status:           "completed",
+			AnalyzedAt:       &analyzedAt,
+			Tags:             []string{"backend", "referral"},
+			JobID:            strPtr("job_1"),
+		},
+		{UploadID: 2, FileName: "new.pdf", Status: models.UploadStatusNotAnalyzed},
+	})
+	if err != nil {
+		t.Fatalf("ExportCandidatesCSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v\n%s", err, data)
+	}
+
+	want := [][]string{
+		{"upload_id", "file_name", "name", "top_skills", "total_work_years", "recommended_roles", "status", "analyzed_at", "tags", "job_id"},
+		{"1", "ada.pdf", `Ada "Countess" Lovelace`, "Go; SQL; Postgres", "6.5", "Staff Engineer; Tech Lead", "completed", "2024-05-01T12:30:00Z", "backend; referral", "job_1"},
+		{"2", "new.pdf", "", "", "", "", models.UploadStatusNotAnalyzed, "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q\nwant %q", rows, want)
+	}
+}
+
+func TestExportCandidatesCSVWithoutCandidates(t *testing.T) {
+	data, err := ExportCandidatesCSV(nil)
+	if err != nil {
+		t.Fatalf("ExportCandidatesCSV: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) != 1 || !reflect.DeepEqual(rows[0], candidatesCSVHeader) {
+		t.Errorf("rows = %q, %v; want only the header", rows, err)
+	}
+}
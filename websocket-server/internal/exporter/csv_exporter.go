@@ -8,15 +8,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // CSVExporter exports profile data as CSV
-type CSVExporter struct{}
+type CSVExporter struct {
+	clock clock.Clock // Source of export timestamps
+}
 
-// NewCSVExporter creates a new CSV exporter
-func NewCSVExporter() *CSVExporter {
-	return &CSVExporter{}
+// NewCSVExporter creates a new CSV exporter. A nil clock uses the system clock.
+func NewCSVExporter(clk clock.Clock) *CSVExporter {
+	return &CSVExporter{clock: clock.OrReal(clk)}
 }
 
 // ExportCSV exports a UserProfile to CSV format
@@ -77,8 +80,8 @@ func (e *CSVExporter) ExportCSV(ctx context.Context, profile *models.UserProfile
 	writer.Write([]string{"Category", "Skills"})
 
 	if profile.Skills != nil {
-		for category, skillList := range profile.Skills {
-			writer.Write([]string{category, strings.Join(skillList, ", ")})
+		for _, category := range models.SkillCategories(profile.Skills) {
+			writer.Write([]string{category, strings.Join(profile.Skills[category], ", ")})
 		}
 	}
 
@@ -94,19 +97,10 @@ func (e *CSVExporter) ExportCSV(ctx context.Context, profile *models.UserProfile
 
 	for _, exp := range profile.Experience {
 		years := ""
-		if exp.Years != nil {
-			years = fmt.Sprintf("%.1f", *exp.Years)
-		}
-		description := ""
-		if exp.Description != nil {
-			description = *exp.Description
+		if exp.Years > 0 {
+			years = fmt.Sprintf("%.1f", exp.Years)
 		}
-		writer.Write([]string{
-			stringOrEmpty(exp.Company),
-			stringOrEmpty(exp.Role),
-			years,
-			description,
-		})
+		writer.Write([]string{exp.Company, exp.Role, years, exp.Description})
 	}
 
 	writer.Write([]string{}) // Empty row
@@ -124,11 +118,7 @@ func (e *CSVExporter) ExportCSV(ctx context.Context, profile *models.UserProfile
 		if edu.Year != nil {
 			year = fmt.Sprintf("%d", *edu.Year)
 		}
-		writer.Write([]string{
-			stringOrEmpty(edu.Degree),
-			stringOrEmpty(edu.Institution),
-			year,
-		})
+		writer.Write([]string{edu.Degree, edu.Institution, year})
 	}
 
 	writer.Write([]string{}) // Empty row
@@ -190,7 +180,7 @@ func (e *CSVExporter) ExportCSV(ctx context.Context, profile *models.UserProfile
 	}
 	writer.Write([]string{}) // Empty row
 	writeKeyValue("Job ID", profile.JobID)
-	writeKeyValue("Exported At", time.Now().UTC().Format(time.RFC3339))
+	writeKeyValue("Exported At", e.clock.Now().UTC().Format(time.RFC3339))
 
 	writer.Flush()
 
@@ -200,11 +190,3 @@ func (e *CSVExporter) ExportCSV(ctx context.Context, profile *models.UserProfile
 
 	return buf.Bytes(), nil
 }
-
-// stringOrEmpty returns the string value or empty string if nil
-func stringOrEmpty(s *string) string {
-	if s == nil {
-		return ""
-	}
-	return *s
-}
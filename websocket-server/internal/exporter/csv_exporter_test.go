@@ -0,0 +1,88 @@
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestExportCSVTimestamp(t *testing.T) {
+	exp := NewCSVExporter(clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	data, err := exp.ExportCSV(context.Background(), &models.UserProfile{JobID: "job-1"})
+	if err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+
+	metadata := map[string]string{}
+	for _, record := range records {
+		if len(record) == 2 {
+			metadata[record[0]] = record[1]
+		}
+	}
+	if got, want := metadata["Exported At"], "2024-01-02T03:04:05Z"; got != want {
+		t.Errorf("Exported At = %q, want %q", got, want)
+	}
+	if got := metadata["Job ID"]; got != "job-1" {
+		t.Errorf("Job ID = %q, want job-1", got)
+	}
+}
+
+// unorderedSkills returns a skills map with enough categories that ranging over it
+// visits them in a different order from run to run
+func unorderedSkills() map[string][]string {
+	return map[string][]string{
+		"Tools":      {"Docker"},
+		"Languages":  {"Go", "Python"},
+		"Frameworks": {"React"},
+		"Databases":  {"Postgres", "Redis"},
+	}
+}
+
+// assertInOrder fails the test unless each of parts appears in text after the previous one
+func assertInOrder(t *testing.T, text string, parts ...string) {
+	t.Helper()
+
+	rest := text
+	for _, part := range parts {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			t.Fatalf("%q missing or out of order in:\n%s", part, text)
+		}
+		rest = rest[i+len(part):]
+	}
+}
+
+func TestExportCSVSkillOrder(t *testing.T) {
+	profile := &models.UserProfile{JobID: "job-1", Skills: unorderedSkills()}
+	exp := NewCSVExporter(clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		data, err := exp.ExportCSV(context.Background(), profile)
+		if err != nil {
+			t.Fatalf("ExportCSV: %v", err)
+		}
+		if i == 0 {
+			first = data
+			continue
+		}
+		if string(data) != string(first) {
+			t.Fatalf("export %d differs from the first export", i)
+		}
+	}
+
+	assertInOrder(t, string(first), "Databases,\"Postgres, Redis\"", "Frameworks,React", "Languages,\"Go, Python\"", "Tools,Docker")
+}
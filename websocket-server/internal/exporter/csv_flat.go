@@ -0,0 +1,130 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// FormatCSVFlat is a CSV with a single header row and one row per record, an
+// alternative to the sectioned CSV layout that imports cleanly into spreadsheets
+const FormatCSVFlat Format = "csv_flat"
+
+// flatCSVHeader is the header row of the flat CSV layout. Every row has the same
+// columns; those that don't apply to a record type are left empty.
+var flatCSVHeader = []string{
+	"job_id", "record_type", "category", "title", "organization", "start_date", "end_date", "years", "value",
+}
+
+// Record types of the flat CSV layout
+const (
+	flatRecordField             = "field"              // category: field name, value: field value
+	flatRecordSkill             = "skill"              // category: skill category, value: skill
+	flatRecordExperience        = "experience"         // title: role, organization: company, value: description
+	flatRecordEducation         = "education"          // title: degree, organization: institution, end_date: year
+	flatRecordJobRecommendation = "job_recommendation" // value: recommended role
+	flatRecordStrength          = "strength"           // value: strength
+	flatRecordWeakness          = "weakness"           // value: area for improvement
+)
+
+// ExportFlatCSV exports a UserProfile as a flat CSV: a header row followed by one row
+// per record. Line breaks inside values are replaced with spaces so every record
+// stays on one line. The job_id column lets exports of several profiles be concatenated.
+func (e *CSVExporter) ExportFlatCSV(ctx context.Context, profile *models.UserProfile) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	record := func(recordType string, values map[string]string) {
+		row := make([]string, len(flatCSVHeader))
+		row[0] = profile.JobID
+		row[1] = recordType
+		for i, column := range flatCSVHeader[2:] {
+			row[i+2] = flattenCell(values[column])
+		}
+		writer.Write(row)
+	}
+	field := func(name string, value *string) {
+		if value != nil && *value != "" {
+			record(flatRecordField, map[string]string{"category": name, "value": *value})
+		}
+	}
+
+	writer.Write(flatCSVHeader)
+
+	field("name", profile.Name)
+	field("email", profile.Email)
+	field("phone", profile.Phone)
+	field("linkedin_url", profile.LinkedInURL)
+	if profile.Age != nil {
+		age := strconv.Itoa(*profile.Age)
+		field("age", &age)
+	}
+	field("race", profile.Race)
+	field("location", profile.Location)
+	if profile.TotalWorkYears != nil {
+		years := fmt.Sprintf("%.1f", *profile.TotalWorkYears)
+		field("total_work_years", &years)
+	}
+	field("summary", profile.Summary)
+
+	for _, category := range models.SkillCategories(profile.Skills) {
+		for _, skill := range profile.Skills[category] {
+			record(flatRecordSkill, map[string]string{"category": category, "value": skill})
+		}
+	}
+
+	for _, exp := range profile.Experience {
+		values := map[string]string{
+			"title":        exp.Role,
+			"organization": exp.Company,
+			"years":        fmt.Sprintf("%.1f", exp.Years),
+			"value":        exp.Description,
+		}
+		if exp.StartDate != nil {
+			values["start_date"] = *exp.StartDate
+		}
+		if exp.EndDate != nil {
+			values["end_date"] = *exp.EndDate
+		}
+		record(flatRecordExperience, values)
+	}
+
+	for _, edu := range profile.Education {
+		values := map[string]string{"title": edu.Degree, "organization": edu.Institution}
+		if edu.Year != nil {
+			values["end_date"] = strconv.Itoa(*edu.Year)
+		}
+		record(flatRecordEducation, values)
+	}
+
+	for _, rec := range profile.JobRecommendations {
+		record(flatRecordJobRecommendation, map[string]string{"value": rec})
+	}
+	for _, strength := range profile.Strengths {
+		record(flatRecordStrength, map[string]string{"value": strength})
+	}
+	for _, weakness := range profile.Weaknesses {
+		record(flatRecordWeakness, map[string]string{"value": weakness})
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// flattenCell joins the lines of a multiline value with single spaces so the cell renders on one line
+func flattenCell(value string) string {
+	if !strings.ContainsAny(value, "\r\n") {
+		return value
+	}
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,125 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestExportFlatCSVRoundTrips(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	years := 6.5
+	gradYear := 2015
+	profile := &models.UserProfile{
+		JobID:          "job-1",
+		Name:           strPtr(`Ada "Countess" Lovelace`),
+		Email:          strPtr("ada@example.com"),
+		TotalWorkYears: &years,
+		Summary:        strPtr("Engineer, writer.\nLikes engines,\r\n  and poetry."),
+		Skills:         map[string][]string{"technical": {"Go", "SQL"}, "languages": {"English"}},
+		Experience: []models.ExperienceEntry{{
+			Company:     "Acme, Inc.",
+			Role:        "Engineer",
+			Years:       2.5,
+			StartDate:   strPtr("2019-01"),
+			EndDate:     strPtr("2021-06"),
+			Description: "Built things,\nshipped them.",
+		}},
+		Education:          []models.EducationEntry{{Degree: "BSc", Institution: "MIT", Year: &gradYear}},
+		JobRecommendations: []string{"Staff Engineer"},
+		Strengths:          []string{"Clear writing"},
+		Weaknesses:         []string{"Delegation"},
+	}
+
+	data, err := NewCSVExporter(clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))).ExportFlatCSV(context.Background(), profile)
+	if err != nil {
+		t.Fatalf("ExportFlatCSV: %v", err)
+	}
+
+	// Every record is one line: the header plus one line per row
+	if lines := bytes.Count(data, []byte("\n")); lines != 13 {
+		t.Errorf("export has %d lines, want 13:\n%s", lines, data)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+
+	want := [][]string{
+		flatCSVHeader,
+		{"job-1", "field", "name", "", "", "", "", "", `Ada "Countess" Lovelace`},
+		{"job-1", "field", "email", "", "", "", "", "", "ada@example.com"},
+		{"job-1", "field", "total_work_years", "", "", "", "", "", "6.5"},
+		{"job-1", "field", "summary", "", "", "", "", "", "Engineer, writer. Likes engines, and poetry."},
+		{"job-1", "skill", "languages", "", "", "", "", "", "English"},
+		{"job-1", "skill", "technical", "", "", "", "", "", "Go"},
+		{"job-1", "skill", "technical", "", "", "", "", "", "SQL"},
+		{"job-1", "experience", "", "Engineer", "Acme, Inc.", "2019-01", "2021-06", "2.5", "Built things, shipped them."},
+		{"job-1", "education", "", "BSc", "MIT", "", "2015", "", ""},
+		{"job-1", "job_recommendation", "", "", "", "", "", "", "Staff Engineer"},
+		{"job-1", "strength", "", "", "", "", "", "", "Clear writing"},
+		{"job-1", "weakness", "", "", "", "", "", "", "Delegation"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records differ\n got: %q\nwant: %q", records, want)
+	}
+}
+
+func TestExportFlatCSVEmptyProfile(t *testing.T) {
+	data, err := NewCSVExporter(nil).ExportFlatCSV(context.Background(), &models.UserProfile{JobID: "job-1"})
+	if err != nil {
+		t.Fatalf("ExportFlatCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if !reflect.DeepEqual(records, [][]string{flatCSVHeader}) {
+		t.Errorf("records = %q, want only the header", records)
+	}
+}
+
+func TestFlattenCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"one line, with commas", "one line, with commas"},
+		{"  kept  as is  ", "  kept  as is  "},
+		{"two\nlines", "two lines"},
+		{"windows\r\nline\r\n\r\nbreaks ", "windows line breaks"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := flattenCell(tt.value); got != tt.want {
+			t.Errorf("flattenCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultExporterFlatCSV(t *testing.T) {
+	exp := NewDefaultExporter(nil, nil)
+	if got := exp.GetContentType(FormatCSVFlat); got != "text/csv" {
+		t.Errorf("content type = %q, want text/csv", got)
+	}
+	if got := exp.GetFileExtension(FormatCSVFlat); got != ".csv" {
+		t.Errorf("extension = %q, want .csv", got)
+	}
+
+	data, err := exp.Export(context.Background(), &models.UserProfile{JobID: "job-1"}, FormatCSVFlat)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("job_id,record_type,")) {
+		t.Errorf("export = %q, want the flat layout", data)
+	}
+}
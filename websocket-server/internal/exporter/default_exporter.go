@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
@@ -13,25 +14,47 @@ type DefaultExporter struct {
 	csvExporter  *CSVExporter
 	pdfExporter  *PDFExporter
 	docxExporter *DOCXExporter
+	keepMarkdown bool
 }
 
-// NewDefaultExporter creates a new default exporter with all formats
-func NewDefaultExporter() Exporter {
+// Config controls how DefaultExporter renders profiles
+type Config struct {
+	// KeepMarkdown exports free-text fields as the LLM wrote them. By default, markdown
+	// in them (**bold**, `code`, headings, ...) is stripped from PDF, DOCX and CSV
+	// exports; JSON exports are never changed.
+	KeepMarkdown bool
+}
+
+// NewDefaultExporter creates a new default exporter with all formats.
+// clk stamps exports with their generation time; nil uses the system clock.
+// A nil config uses the defaults.
+func NewDefaultExporter(clk clock.Clock, config *Config) Exporter {
+	if config == nil {
+		config = &Config{}
+	}
+
 	return &DefaultExporter{
-		jsonExporter: NewJSONExporter(),
-		csvExporter:  NewCSVExporter(),
-		pdfExporter:  NewPDFExporter(),
-		docxExporter: NewDOCXExporter(),
+		jsonExporter: NewJSONExporter(clk),
+		csvExporter:  NewCSVExporter(clk),
+		pdfExporter:  NewPDFExporter(clk),
+		docxExporter: NewDOCXExporter(clk),
+		keepMarkdown: config.KeepMarkdown,
 	}
 }
 
 // Export converts a UserProfile to the specified format
 func (e *DefaultExporter) Export(ctx context.Context, profile *models.UserProfile, format Format) ([]byte, error) {
+	if !e.keepMarkdown && format != FormatJSON {
+		profile = stripMarkdownFields(profile)
+	}
+
 	switch format {
 	case FormatJSON:
 		return e.jsonExporter.ExportJSON(ctx, profile)
 	case FormatCSV:
 		return e.csvExporter.ExportCSV(ctx, profile)
+	case FormatCSVFlat:
+		return e.csvExporter.ExportFlatCSV(ctx, profile)
 	case FormatPDF:
 		return e.pdfExporter.ExportPDF(ctx, profile)
 	case FormatDOCX:
@@ -46,7 +69,7 @@ func (e *DefaultExporter) GetContentType(format Format) string {
 	switch format {
 	case FormatJSON:
 		return "application/json"
-	case FormatCSV:
+	case FormatCSV, FormatCSVFlat:
 		return "text/csv"
 	case FormatPDF:
 		return "application/pdf"
@@ -62,7 +85,7 @@ func (e *DefaultExporter) GetFileExtension(format Format) string {
 	switch format {
 	case FormatJSON:
 		return ".json"
-	case FormatCSV:
+	case FormatCSV, FormatCSVFlat:
 		return ".csv"
 	case FormatPDF:
 		return ".pdf"
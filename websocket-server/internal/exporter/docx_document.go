@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxContentTypes declares the part types of the generated package
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+// docxRootRels points the package at its main document part
+const docxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// docxHeadingSizes maps heading levels to font sizes in half-points
+var docxHeadingSizes = map[int]int{1: 36, 2: 28, 3: 24}
+
+// docxDocument builds the body of a minimal WordprocessingML document: a list of
+// headings and plain paragraphs, with no styles part of its own
+type docxDocument struct {
+	body strings.Builder
+}
+
+// newDOCXDocument creates an empty document
+func newDOCXDocument() *docxDocument {
+	return &docxDocument{}
+}
+
+// AddHeading adds a bold heading; level 1 is the largest
+func (d *docxDocument) AddHeading(text string, level int) {
+	size, ok := docxHeadingSizes[level]
+	if !ok {
+		size = docxHeadingSizes[3]
+	}
+	d.addRun(text, fmt.Sprintf(`<w:b/><w:sz w:val="%d"/>`, size))
+}
+
+// AddParagraph adds a paragraph of plain text
+func (d *docxDocument) AddParagraph(text string) {
+	d.addRun(text, "")
+}
+
+// addRun writes a paragraph holding a single run with the given run properties
+func (d *docxDocument) addRun(text, props string) {
+	d.body.WriteString("<w:p><w:r>")
+	if props != "" {
+		d.body.WriteString("<w:rPr>" + props + "</w:rPr>")
+	}
+	d.body.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(&d.body, []byte(text))
+	d.body.WriteString("</w:t></w:r></w:p>")
+}
+
+// Write writes the document as a DOCX package
+func (d *docxDocument) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"word/document.xml", d.documentXML()},
+	}
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// documentXML wraps the body in the main document part
+func (d *docxDocument) documentXML() string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		d.body.String() +
+		`</w:body></w:document>`
+}
@@ -5,24 +5,25 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
-	"github.com/nguyenthenguyen/docx"
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // DOCXExporter exports profile data as DOCX
-type DOCXExporter struct{}
+type DOCXExporter struct {
+	clock clock.Clock // Source of export timestamps
+}
 
-// NewDOCXExporter creates a new DOCX exporter
-func NewDOCXExporter() *DOCXExporter {
-	return &DOCXExporter{}
+// NewDOCXExporter creates a new DOCX exporter. A nil clock uses the system clock.
+func NewDOCXExporter(clk clock.Clock) *DOCXExporter {
+	return &DOCXExporter{clock: clock.OrReal(clk)}
 }
 
 // ExportDOCX exports a UserProfile to DOCX format
 func (e *DOCXExporter) ExportDOCX(ctx context.Context, profile *models.UserProfile) ([]byte, error) {
 	// Create a new document
-	doc := docx.NewFile()
+	doc := newDOCXDocument()
 
 	// Title
 	doc.AddHeading("Resume Analysis Report", 1)
@@ -74,7 +75,7 @@ func (e *DOCXExporter) ExportDOCX(ctx context.Context, profile *models.UserProfi
 }
 
 // addPersonalInfo adds personal information section
-func (e *DOCXExporter) addPersonalInfo(doc *docx.Document, profile *models.UserProfile) {
+func (e *DOCXExporter) addPersonalInfo(doc *docxDocument, profile *models.UserProfile) {
 	if profile.Name != nil {
 		doc.AddParagraph(fmt.Sprintf("Name: %s", *profile.Name))
 	}
@@ -96,63 +97,36 @@ func (e *DOCXExporter) addPersonalInfo(doc *docx.Document, profile *models.UserP
 }
 
 // addSkillsTable adds skills in a table format
-func (e *DOCXExporter) addSkillsTable(doc *docx.Document, skills map[string][]string) {
+func (e *DOCXExporter) addSkillsTable(doc *docxDocument, skills map[string][]string) {
 	// Note: docx library has limited table support, so we'll use formatted text
-	for category, skillList := range skills {
-		skillText := fmt.Sprintf("%s: %s", category, strings.Join(skillList, ", "))
+	for _, category := range models.SkillCategories(skills) {
+		skillText := fmt.Sprintf("%s: %s", category, strings.Join(skills[category], ", "))
 		doc.AddParagraph(skillText)
 	}
 }
 
 // addExperienceList adds work experience as bullet lists
-func (e *DOCXExporter) addExperienceList(doc *docx.Document, experiences []models.ExperienceEntry) {
+func (e *DOCXExporter) addExperienceList(doc *docxDocument, experiences []models.ExperienceEntry) {
 	for _, exp := range experiences {
 		// Job title and company
-		jobTitle := ""
-		if exp.Role != nil {
-			jobTitle = *exp.Role
-		}
-		if exp.Company != nil {
-			if jobTitle != "" {
-				jobTitle += " at "
-			}
-			jobTitle += *exp.Company
-		}
-		if exp.Years != nil {
-			jobTitle += fmt.Sprintf(" (%.1f years)", *exp.Years)
-		}
-
-		doc.AddParagraph(jobTitle)
+		doc.AddParagraph(experienceHeading(exp))
 
 		// Description as indented paragraph
-		if exp.Description != nil && *exp.Description != "" {
-			doc.AddParagraph("  • " + *exp.Description)
+		if exp.Description != "" {
+			doc.AddParagraph("  • " + exp.Description)
 		}
 	}
 }
 
 // addEducationList adds education entries
-func (e *DOCXExporter) addEducationList(doc *docx.Document, education []models.EducationEntry) {
+func (e *DOCXExporter) addEducationList(doc *docxDocument, education []models.EducationEntry) {
 	for _, edu := range education {
-		eduText := ""
-		if edu.Degree != nil {
-			eduText = *edu.Degree
-		}
-		if edu.Institution != nil {
-			if eduText != "" {
-				eduText += " - "
-			}
-			eduText += *edu.Institution
-		}
-		if edu.Year != nil {
-			eduText += fmt.Sprintf(" (%d)", *edu.Year)
-		}
-		doc.AddParagraph(eduText)
+		doc.AddParagraph(educationLine(edu))
 	}
 }
 
 // addAIAnalysis adds AI-generated analysis
-func (e *DOCXExporter) addAIAnalysis(doc *docx.Document, strengths, weaknesses, recommendations []string) {
+func (e *DOCXExporter) addAIAnalysis(doc *docxDocument, strengths, weaknesses, recommendations []string) {
 	// Strengths
 	if len(strengths) > 0 {
 		doc.AddHeading("Strengths", 3)
@@ -179,10 +153,10 @@ func (e *DOCXExporter) addAIAnalysis(doc *docx.Document, strengths, weaknesses,
 }
 
 // addMetadata adds document metadata
-func (e *DOCXExporter) addMetadata(doc *docx.Document, jobID string) {
+func (e *DOCXExporter) addMetadata(doc *docxDocument, jobID string) {
 	doc.AddParagraph("") // Empty line
 	metadataText := fmt.Sprintf("Generated: %s | Job ID: %s",
-		time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+		e.clock.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
 		jobID)
 	doc.AddParagraph(metadataText)
 }
@@ -0,0 +1,114 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestExportDOCX(t *testing.T) {
+	name := "Ada <Lovelace>"
+	year := 2010
+	profile := &models.UserProfile{
+		JobID: "job-1",
+		Name:  &name,
+		Experience: []models.ExperienceEntry{
+			{Company: "Acme", Role: "Engineer", Years: 2.5, Description: "Built things & more"},
+		},
+		Education: []models.EducationEntry{{Degree: "BSc", Institution: "MIT", Year: &year}},
+	}
+
+	exp := NewDOCXExporter(clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	data, err := exp.ExportDOCX(context.Background(), profile)
+	if err != nil {
+		t.Fatalf("ExportDOCX: %v", err)
+	}
+
+	document := readDocumentXML(t, data)
+
+	for _, want := range []string{
+		"Name: Ada &lt;Lovelace&gt;",
+		"Engineer at Acme (2.5 years)",
+		"Built things &amp; more",
+		"BSc - MIT (2010)",
+		"Generated: 2024-01-02 03:04:05 UTC | Job ID: job-1",
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+}
+
+func TestExperienceHeading(t *testing.T) {
+	tests := []struct {
+		exp  models.ExperienceEntry
+		want string
+	}{
+		{models.ExperienceEntry{Role: "Engineer", Company: "Acme", Years: 3}, "Engineer at Acme (3.0 years)"},
+		{models.ExperienceEntry{Company: "Acme"}, "Acme"},
+		{models.ExperienceEntry{Role: "Engineer"}, "Engineer"},
+	}
+
+	for _, tt := range tests {
+		if got := experienceHeading(tt.exp); got != tt.want {
+			t.Errorf("experienceHeading(%+v) = %q, want %q", tt.exp, got, tt.want)
+		}
+	}
+}
+
+// readDocumentXML returns the word/document.xml part of a DOCX package
+func readDocumentXML(t *testing.T, data []byte) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip package: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open document.xml: %v", err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("read document.xml: %v", err)
+		}
+		return string(content)
+	}
+	t.Fatal("package has no word/document.xml")
+	return ""
+}
+
+func TestExportDOCXSkillOrder(t *testing.T) {
+	profile := &models.UserProfile{JobID: "job-1", Skills: unorderedSkills()}
+	exp := NewDOCXExporter(clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	var first string
+	for i := 0; i < 20; i++ {
+		data, err := exp.ExportDOCX(context.Background(), profile)
+		if err != nil {
+			t.Fatalf("ExportDOCX: %v", err)
+		}
+		document := readDocumentXML(t, data)
+		if i == 0 {
+			first = document
+			continue
+		}
+		if document != first {
+			t.Fatalf("export %d differs from the first export", i)
+		}
+	}
+
+	assertInOrder(t, first, "Databases: Postgres, Redis", "Frameworks: React", "Languages: Go, Python", "Tools: Docker")
+}
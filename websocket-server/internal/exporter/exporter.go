@@ -2,6 +2,7 @@ package exporter
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/your-org/websocket-server/pkg/models"
 )
@@ -16,6 +17,9 @@ const (
 	FormatDOCX Format = "docx"
 )
 
+// AnalysisFormats lists the formats analysis results can be exported in
+var AnalysisFormats = []Format{FormatJSON, FormatCSV, FormatCSVFlat, FormatPDF, FormatDOCX}
+
 // Exporter is the main interface for exporting analysis results
 type Exporter interface {
 	// Export converts a UserProfile to the specified format
@@ -40,3 +44,35 @@ type ExportResponse struct {
 	ContentType string
 	FileName    string
 }
+
+// experienceHeading formats an experience entry as "Role at Company (N years)", leaving
+// out the parts the entry has no value for
+func experienceHeading(exp models.ExperienceEntry) string {
+	heading := exp.Role
+	if exp.Company != "" {
+		if heading != "" {
+			heading += " at "
+		}
+		heading += exp.Company
+	}
+	if exp.Years > 0 {
+		heading += fmt.Sprintf(" (%.1f years)", exp.Years)
+	}
+	return heading
+}
+
+// educationLine formats an education entry as "Degree - Institution (Year)", leaving out
+// the parts the entry has no value for
+func educationLine(edu models.EducationEntry) string {
+	line := edu.Degree
+	if edu.Institution != "" {
+		if line != "" {
+			line += " - "
+		}
+		line += edu.Institution
+	}
+	if edu.Year != nil {
+		line += fmt.Sprintf(" (%d)", *edu.Year)
+	}
+	return line
+}
@@ -6,15 +6,18 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // JSONExporter exports profile data as JSON
-type JSONExporter struct{}
+type JSONExporter struct {
+	clock clock.Clock // Source of export timestamps
+}
 
-// NewJSONExporter creates a new JSON exporter
-func NewJSONExporter() *JSONExporter {
-	return &JSONExporter{}
+// NewJSONExporter creates a new JSON exporter. A nil clock uses the system clock.
+func NewJSONExporter(clk clock.Clock) *JSONExporter {
+	return &JSONExporter{clock: clock.OrReal(clk)}
 }
 
 // ExportedProfile represents the JSON structure for export
@@ -64,7 +67,7 @@ func (e *JSONExporter) ExportJSON(ctx context.Context, profile *models.UserProfi
 		JobRecommendations: profile.JobRecommendations,
 		Strengths:          profile.Strengths,
 		Weaknesses:         profile.Weaknesses,
-		ExportedAt:         time.Now().UTC().Format(time.RFC3339),
+		ExportedAt:         e.clock.Now().UTC().Format(time.RFC3339),
 	}
 
 	data, err := json.MarshalIndent(exported, "", "  ")
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestExportJSONTimestamp(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)))
+	exp := NewJSONExporter(fake)
+
+	data, err := exp.ExportJSON(context.Background(), &models.UserProfile{JobID: "job-1"})
+	if err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+
+	var exported ExportedProfile
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if want := "2024-01-02T02:04:05Z"; exported.ExportedAt != want {
+		t.Errorf("exported_at = %q, want %q", exported.ExportedAt, want)
+	}
+
+	// Every export is stamped with the time it was made
+	fake.Advance(time.Hour)
+	data, err = exp.ExportJSON(context.Background(), &models.UserProfile{JobID: "job-1"})
+	if err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if want := "2024-01-02T03:04:05Z"; exported.ExportedAt != want {
+		t.Errorf("exported_at after an hour = %q, want %q", exported.ExportedAt, want)
+	}
+}
@@ -5,18 +5,20 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/jung-kurt/gofpdf"
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // PDFExporter exports profile data as PDF
-type PDFExporter struct{}
+type PDFExporter struct {
+	clock clock.Clock // Source of export timestamps
+}
 
-// NewPDFExporter creates a new PDF exporter
-func NewPDFExporter() *PDFExporter {
-	return &PDFExporter{}
+// NewPDFExporter creates a new PDF exporter. A nil clock uses the system clock.
+func NewPDFExporter(clk clock.Clock) *PDFExporter {
+	return &PDFExporter{clock: clock.OrReal(clk)}
 }
 
 // ExportPDF exports a UserProfile to PDF format
@@ -30,6 +32,24 @@ func (e *PDFExporter) ExportPDF(ctx context.Context, profile *models.UserProfile
 	// Header
 	e.addHeader(pdf, profile)
 
+	e.addProfileSections(pdf, profile)
+
+	// Footer
+	e.addFooter(pdf, profile.JobID)
+
+	// Output to buffer
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addProfileSections adds the summary, skills, experience, education and AI analysis
+// sections, skipping those the profile has no data for
+func (e *PDFExporter) addProfileSections(pdf *gofpdf.Fpdf, profile *models.UserProfile) {
 	// Professional Summary
 	if profile.Summary != nil && *profile.Summary != "" {
 		e.addSection(pdf, "Professional Summary")
@@ -64,18 +84,6 @@ func (e *PDFExporter) ExportPDF(ctx context.Context, profile *models.UserProfile
 		e.addSection(pdf, "AI Analysis")
 		e.addAIAnalysis(pdf, profile.Strengths, profile.Weaknesses, profile.JobRecommendations)
 	}
-
-	// Footer
-	e.addFooter(pdf, profile.JobID)
-
-	// Output to buffer
-	var buf bytes.Buffer
-	err := pdf.Output(&buf)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
-	}
-
-	return buf.Bytes(), nil
 }
 
 // addHeader adds the document header with personal info
@@ -146,7 +154,9 @@ func (e *PDFExporter) addSection(pdf *gofpdf.Fpdf, title string) {
 func (e *PDFExporter) addSkills(pdf *gofpdf.Fpdf, skills map[string][]string) {
 	pdf.SetFont("Arial", "", 11)
 
-	for category, skillList := range skills {
+	for _, category := range models.SkillCategories(skills) {
+		skillList := skills[category]
+
 		// Category name in bold
 		pdf.SetFont("Arial", "B", 11)
 		pdf.Cell(35, 5, category+":")
@@ -164,26 +174,13 @@ func (e *PDFExporter) addExperience(pdf *gofpdf.Fpdf, experiences []models.Exper
 	for i, exp := range experiences {
 		// Job title and company
 		pdf.SetFont("Arial", "B", 11)
-		jobTitle := ""
-		if exp.Role != nil {
-			jobTitle = *exp.Role
-		}
-		if exp.Company != nil {
-			if jobTitle != "" {
-				jobTitle += " at "
-			}
-			jobTitle += *exp.Company
-		}
-		if exp.Years != nil {
-			jobTitle += fmt.Sprintf(" (%.1f years)", *exp.Years)
-		}
-		pdf.Cell(0, 6, jobTitle)
+		pdf.Cell(0, 6, experienceHeading(exp))
 		pdf.Ln(6)
 
 		// Description
-		if exp.Description != nil && *exp.Description != "" {
+		if exp.Description != "" {
 			pdf.SetFont("Arial", "", 10)
-			pdf.MultiCell(0, 5, "• "+*exp.Description, "", "", false)
+			pdf.MultiCell(0, 5, "• "+exp.Description, "", "", false)
 		}
 
 		// Add spacing between entries
@@ -197,20 +194,7 @@ func (e *PDFExporter) addExperience(pdf *gofpdf.Fpdf, experiences []models.Exper
 func (e *PDFExporter) addEducation(pdf *gofpdf.Fpdf, education []models.EducationEntry) {
 	for _, edu := range education {
 		pdf.SetFont("Arial", "B", 11)
-		eduText := ""
-		if edu.Degree != nil {
-			eduText = *edu.Degree
-		}
-		if edu.Institution != nil {
-			if eduText != "" {
-				eduText += " - "
-			}
-			eduText += *edu.Institution
-		}
-		if edu.Year != nil {
-			eduText += fmt.Sprintf(" (%d)", *edu.Year)
-		}
-		pdf.Cell(0, 6, eduText)
+		pdf.Cell(0, 6, educationLine(edu))
 		pdf.Ln(6)
 	}
 }
@@ -265,8 +249,130 @@ func (e *PDFExporter) addFooter(pdf *gofpdf.Fpdf, jobID string) {
 	pdf.SetTextColor(128, 128, 128)
 
 	footerText := fmt.Sprintf("Generated: %s | Job ID: %s",
-		time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+		e.clock.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
 		jobID)
 
 	pdf.Cell(0, 10, footerText)
 }
+
+// ExportTranscriptPDF exports a conversation transcript to PDF format
+func (e *PDFExporter) ExportTranscriptPDF(ctx context.Context, transcript *Transcript) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	e.addSection(pdf, transcript.Title)
+
+	for _, entry := range transcript.Entries {
+		// Speaker line
+		pdf.SetFont("Arial", "B", 11)
+		pdf.SetTextColor(26, 54, 93) // Dark blue
+		speaker := fmt.Sprintf("%s  %s", entry.Speaker, entry.CreatedAt.UTC().Format(transcriptTimeLayout))
+		if label := entry.Label(); label != "" {
+			speaker += " (" + label + ")"
+		}
+		pdf.Cell(0, 6, speaker)
+		pdf.Ln(6)
+
+		if entry.MatchedQuestion != "" {
+			pdf.SetFont("Arial", "I", 10)
+			pdf.SetTextColor(100, 100, 100)
+			pdf.MultiCell(0, 5, "Matched question: "+entry.MatchedQuestion, "", "", false)
+		}
+
+		pdf.SetFont("Arial", "", 11)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.MultiCell(0, 5, entry.Body(), "", "", false)
+		pdf.Ln(3)
+	}
+
+	// Footer
+	pdf.SetFont("Arial", "I", 9)
+	pdf.SetTextColor(128, 128, 128)
+	pdf.Cell(0, 10, fmt.Sprintf("Generated: %s", e.clock.Now().UTC().Format(transcriptTimeLayout)))
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExportPrepPackPDF exports an interview prep pack to PDF format: the candidate's profile
+// first, then the saved questions grouped by category with their answers. A missing
+// profile or an empty question list is noted in its section rather than failing the export.
+func (e *PDFExporter) ExportPrepPackPDF(ctx context.Context, pack *PrepPack) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	// Title
+	pdf.SetFont("Arial", "B", 18)
+	pdf.SetTextColor(26, 54, 93) // Dark blue
+	pdf.Cell(0, 10, "Interview Prep Pack")
+	pdf.Ln(10)
+
+	// Candidate Profile
+	if pack.Profile != nil {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.SetTextColor(0, 0, 0)
+		if pack.Profile.Name != nil {
+			pdf.Cell(0, 7, *pack.Profile.Name)
+			pdf.Ln(10)
+		}
+		e.addProfileSections(pdf, pack.Profile)
+		pdf.Ln(5)
+	} else {
+		e.addSection(pdf, "Candidate Profile")
+		e.addNote(pdf, "No analyzed profile is available for this job.")
+	}
+
+	// Interview Questions
+	e.addSection(pdf, "Interview Questions")
+	groups := pack.Groups()
+	if len(groups) == 0 {
+		e.addNote(pdf, "No questions have been saved for this job yet.")
+	}
+	for _, group := range groups {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.SetTextColor(26, 54, 93) // Dark blue
+		pdf.Cell(0, 7, fmt.Sprintf("%s (%d)", group.Category, len(group.Questions)))
+		pdf.Ln(8)
+
+		for i, q := range group.Questions {
+			pdf.SetFont("Arial", "B", 11)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.MultiCell(0, 5, fmt.Sprintf("%d. %s", i+1, q.Question), "", "", false)
+
+			if q.Difficulty != nil && *q.Difficulty != "" {
+				pdf.SetFont("Arial", "I", 9)
+				pdf.SetTextColor(100, 100, 100)
+				pdf.Cell(0, 5, "Difficulty: "+*q.Difficulty)
+				pdf.Ln(5)
+			}
+
+			pdf.SetFont("Arial", "", 10)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.MultiCell(0, 5, q.Answer, "", "", false)
+			pdf.Ln(4)
+		}
+	}
+
+	// Footer
+	e.addFooter(pdf, pack.JobID)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addNote adds a line of muted text, used for sections without data
+func (e *PDFExporter) addNote(pdf *gofpdf.Fpdf, text string) {
+	pdf.SetFont("Arial", "I", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.MultiCell(0, 5, text, "", "", false)
+	pdf.Ln(5)
+	pdf.SetTextColor(0, 0, 0)
+}
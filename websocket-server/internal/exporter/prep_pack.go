@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"sort"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// uncategorizedGroup is the heading of saved questions without a category
+const uncategorizedGroup = "Other"
+
+// PrepPack is an interview prep pack: a candidate's analyzed profile together with
+// the questions and answers they saved for a job
+type PrepPack struct {
+	JobID     string
+	Profile   *models.UserProfile              // Nil when the job has no analyzed profile
+	Questions []*models.SavedInterviewQuestion // In the order they should appear within a category
+}
+
+// PrepPackGroup is the saved questions of one category
+type PrepPackGroup struct {
+	Category  string
+	Questions []*models.SavedInterviewQuestion
+}
+
+// Groups returns the saved questions grouped by category: the canonical categories
+// first, then any others alphabetically, then questions without a category
+func (p *PrepPack) Groups() []PrepPackGroup {
+	byCategory := make(map[string][]*models.SavedInterviewQuestion)
+	for _, q := range p.Questions {
+		category := uncategorizedGroup
+		if q.Category != nil && *q.Category != "" {
+			category = *q.Category
+		}
+		byCategory[category] = append(byCategory[category], q)
+	}
+
+	var groups []PrepPackGroup
+	for _, category := range models.QuestionCategories {
+		if questions, ok := byCategory[category]; ok {
+			groups = append(groups, PrepPackGroup{Category: category, Questions: questions})
+			delete(byCategory, category)
+		}
+	}
+
+	uncategorized := byCategory[uncategorizedGroup]
+	delete(byCategory, uncategorizedGroup)
+
+	others := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		others = append(others, category)
+	}
+	sort.Strings(others)
+	for _, category := range others {
+		groups = append(groups, PrepPackGroup{Category: category, Questions: byCategory[category]})
+	}
+
+	if len(uncategorized) > 0 {
+		groups = append(groups, PrepPackGroup{Category: uncategorizedGroup, Questions: uncategorized})
+	}
+	return groups
+}
@@ -0,0 +1,136 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// pdfStream matches the content streams of a PDF
+var pdfStream = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+// pdfText returns the text drawn by a PDF's content streams, which gofpdf compresses
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var text strings.Builder
+	for _, match := range pdfStream.FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			continue // Not compressed content, such as an embedded font
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("inflating PDF stream: %v", err)
+		}
+		text.Write(content)
+	}
+	return text.String()
+}
+
+// savedQuestion is a saved question in category, empty for none
+func savedQuestion(question, answer, category string) *models.SavedInterviewQuestion {
+	q := &models.SavedInterviewQuestion{Question: question, Answer: answer}
+	if category != "" {
+		q.Category = &category
+	}
+	return q
+}
+
+func TestPrepPackGroups(t *testing.T) {
+	pack := &PrepPack{Questions: []*models.SavedInterviewQuestion{
+		savedQuestion("Q1", "A1", "Leadership"),
+		savedQuestion("Q2", "A2", ""),
+		savedQuestion("Q3", "A3", models.QuestionCategories[1]),
+		savedQuestion("Q4", "A4", models.QuestionCategories[0]),
+		savedQuestion("Q5", "A5", "Culture"),
+		savedQuestion("Q6", "A6", models.QuestionCategories[1]),
+	}}
+
+	var got []string
+	for _, group := range pack.Groups() {
+		var questions []string
+		for _, q := range group.Questions {
+			questions = append(questions, q.Question)
+		}
+		got = append(got, group.Category+": "+strings.Join(questions, ","))
+	}
+
+	want := []string{
+		models.QuestionCategories[0] + ": Q4",
+		models.QuestionCategories[1] + ": Q3,Q6",
+		"Culture: Q5",
+		"Leadership: Q1",
+		"Other: Q2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %q, want %q", got, want)
+	}
+}
+
+func TestExportPrepPackPDF(t *testing.T) {
+	name, summary := "Ada Lovelace", "Engineer who ships."
+	profile := &models.UserProfile{JobID: "job-1", Name: &name, Summary: &summary}
+	questions := []*models.SavedInterviewQuestion{
+		savedQuestion("Why Go?", "It compiles fast.", models.QuestionCategories[0]),
+	}
+
+	tests := []struct {
+		name    string
+		pack    *PrepPack
+		want    []string // In order
+		notWant []string
+	}{
+		{
+			name:    "profile and questions",
+			pack:    &PrepPack{JobID: "job-1", Profile: profile, Questions: questions},
+			want:    []string{"Interview Prep Pack", "Ada Lovelace", "Professional Summary", "Engineer who ships.", "Interview Questions", models.QuestionCategories[0] + " \\(1\\)", "1. Why Go?", "It compiles fast."},
+			notWant: []string{"No analyzed profile", "No questions have been saved"},
+		},
+		{
+			name:    "no profile",
+			pack:    &PrepPack{JobID: "job-1", Questions: questions},
+			want:    []string{"Candidate Profile", "No analyzed profile is available for this job.", "Interview Questions", "1. Why Go?"},
+			notWant: []string{"Ada Lovelace"},
+		},
+		{
+			name: "no questions",
+			pack: &PrepPack{JobID: "job-1", Profile: profile},
+			want: []string{"Ada Lovelace", "Interview Questions", "No questions have been saved for this job yet."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewPDFExporter(nil).ExportPrepPackPDF(context.Background(), tt.pack)
+			if err != nil {
+				t.Fatalf("ExportPrepPackPDF: %v", err)
+			}
+			if !bytes.HasPrefix(data, []byte("%PDF-")) {
+				t.Fatalf("export starts with %q, want a PDF", data[:min(len(data), 8)])
+			}
+
+			text := pdfText(t, data)
+			rest := text
+			for _, want := range tt.want {
+				i := strings.Index(rest, "("+want+")")
+				if i < 0 {
+					t.Fatalf("%q missing or out of order in:\n%s", want, text)
+				}
+				rest = rest[i:]
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("PDF unexpectedly contains %q", notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package exporter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// Markdown the LLM occasionally puts in free-text fields, rewritten by StripMarkdown
+var (
+	markdownFence      = regexp.MustCompile("(?m)^\\s*```[^\\n]*$\\n?")
+	markdownHeading    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	markdownQuote      = regexp.MustCompile(`(?m)^\s{0,3}>\s?`)
+	markdownBullet     = regexp.MustCompile(`(?m)^(\s*)[*+]\s+`)
+	markdownLink       = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	markdownBold       = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	markdownItalic     = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*\n]*?)\*([^\w*]|$)`)
+	markdownStrayMarks = regexp.MustCompile("\\*\\*|`")
+)
+
+// StripMarkdown turns basic markdown into plain text: emphasis and code markers are
+// removed, headings and quotes lose their markers, "*" and "+" bullets become "-" and
+// links become "text (url)". Text without markdown is returned unchanged.
+func StripMarkdown(text string) string {
+	text = markdownFence.ReplaceAllString(text, "")
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownQuote.ReplaceAllString(text, "")
+	text = markdownBullet.ReplaceAllString(text, "$1- ")
+	text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	text = markdownBold.ReplaceAllString(text, "$1$2")
+	text = markdownItalic.ReplaceAllString(text, "$1$2$3")
+	text = markdownStrayMarks.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// stripMarkdownFields returns a copy of profile whose free-text fields (summary,
+// strengths, weaknesses, job recommendations and experience descriptions) are
+// StripMarkdown'ed. The profile itself is not modified.
+func stripMarkdownFields(profile *models.UserProfile) *models.UserProfile {
+	clean := *profile
+
+	if profile.Summary != nil {
+		summary := StripMarkdown(*profile.Summary)
+		clean.Summary = &summary
+	}
+	clean.Strengths = stripMarkdownList(profile.Strengths)
+	clean.Weaknesses = stripMarkdownList(profile.Weaknesses)
+	clean.JobRecommendations = stripMarkdownList(profile.JobRecommendations)
+
+	if profile.Experience != nil {
+		clean.Experience = make([]models.ExperienceEntry, len(profile.Experience))
+		for i, exp := range profile.Experience {
+			exp.Description = StripMarkdown(exp.Description)
+			clean.Experience[i] = exp
+		}
+	}
+
+	return &clean
+}
+
+// stripMarkdownList StripMarkdown's every item of a list into a new list
+func stripMarkdownList(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	clean := make([]string, len(items))
+	for i, item := range items {
+		clean[i] = StripMarkdown(item)
+	}
+	return clean
+}
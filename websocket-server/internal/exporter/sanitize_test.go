@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text", "Led a team of 5 engineers.", "Led a team of 5 engineers."},
+		{"bold", "A **strong** engineer", "A strong engineer"},
+		{"underscore bold", "A __strong__ engineer", "A strong engineer"},
+		{"italic", "Knows *Go* well", "Knows Go well"},
+		{"multiplication kept", "Handled 2 * 3 shards", "Handled 2 * 3 shards"},
+		{"code", "Wrote `gRPC` services", "Wrote gRPC services"},
+		{"fence", "```markdown\nBuilt APIs\n```", "Built APIs"},
+		{"heading", "## Summary\nBackend engineer", "Summary\nBackend engineer"},
+		{"quote", "> Strong communicator", "Strong communicator"},
+		{"bullets", "* Go\n  + SQL\n- Docker", "- Go\n  - SQL\n- Docker"},
+		{"link", "See [portfolio](https://example.com/ada)", "See portfolio (https://example.com/ada)"},
+		{"unbalanced markers", "A **strong engineer", "A strong engineer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMarkdown(tt.text); got != tt.want {
+				t.Errorf("StripMarkdown(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// markdownProfile has markdown in each free-text field
+func markdownProfile() *models.UserProfile {
+	summary := "**Senior** backend engineer"
+	return &models.UserProfile{
+		JobID:              "job-1",
+		Summary:            &summary,
+		Strengths:          []string{"`Go` expertise"},
+		Weaknesses:         []string{"*Limited* frontend work"},
+		JobRecommendations: []string{"## Staff Engineer"},
+		Experience:         []models.ExperienceEntry{{Company: "Acme", Description: "Built **payments** APIs"}},
+	}
+}
+
+func TestDefaultExporterStripsMarkdown(t *testing.T) {
+	plain := []string{"Senior backend engineer", "Go expertise", "Limited frontend work", "Staff Engineer", "Built payments APIs"}
+
+	// text returns the text of an export the markers are looked for in
+	text := func(t *testing.T, format Format, data []byte) string {
+		switch format {
+		case FormatPDF:
+			return pdfText(t, data)
+		case FormatDOCX:
+			return readDocumentXML(t, data)
+		}
+		return string(data)
+	}
+
+	for _, format := range []Format{FormatPDF, FormatDOCX, FormatCSV, FormatCSVFlat} {
+		t.Run(string(format), func(t *testing.T) {
+			profile := markdownProfile()
+			data, err := NewDefaultExporter(nil, nil).Export(context.Background(), profile, format)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+
+			got := text(t, format, data)
+			for _, marker := range []string{"**", "`", "*Limited*", "## "} {
+				if strings.Contains(got, marker) {
+					t.Errorf("export still contains %q", marker)
+				}
+			}
+			for _, want := range plain {
+				if !strings.Contains(got, want) {
+					t.Errorf("export is missing %q", want)
+				}
+			}
+			if *profile.Summary != "**Senior** backend engineer" || profile.Experience[0].Description != "Built **payments** APIs" {
+				t.Error("exporting changed the profile")
+			}
+		})
+	}
+
+	t.Run("json unchanged", func(t *testing.T) {
+		data, err := NewDefaultExporter(nil, nil).Export(context.Background(), markdownProfile(), FormatJSON)
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		if !strings.Contains(string(data), `**Senior** backend engineer`) {
+			t.Errorf("JSON export lost the markdown:\n%s", data)
+		}
+	})
+
+	t.Run("markdown kept", func(t *testing.T) {
+		data, err := NewDefaultExporter(nil, &Config{KeepMarkdown: true}).Export(context.Background(), markdownProfile(), FormatCSV)
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		if !strings.Contains(string(data), "**Senior** backend engineer") {
+			t.Errorf("CSV export lost the markdown with KeepMarkdown:\n%s", data)
+		}
+	})
+}
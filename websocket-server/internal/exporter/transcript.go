@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+// Formats supported only by conversation transcripts
+const (
+	FormatMarkdown Format = "md"
+	FormatText     Format = "txt"
+)
+
+// TranscriptFormats lists the formats conversation transcripts can be exported in
+var TranscriptFormats = []Format{FormatPDF, FormatMarkdown, FormatText}
+
+// transcriptTimeLayout is how turn timestamps are written in transcripts
+const transcriptTimeLayout = "2006-01-02 15:04:05 UTC"
+
+// Transcript is a conversation prepared for export
+type Transcript struct {
+	Title   string
+	Entries []TranscriptEntry // Oldest first
+}
+
+// TranscriptEntry is one turn of a conversation
+type TranscriptEntry struct {
+	Speaker         string
+	Text            string
+	Audio           bool   // Text is the transcript of an audio message
+	DurationMs      int    // Length of an audio message
+	MatchedQuestion string // Saved question a Q&A answer was matched from
+	CreatedAt       time.Time
+}
+
+// TranscriptExporter renders conversation transcripts as PDF, Markdown or plain text
+type TranscriptExporter struct {
+	clock clock.Clock
+	pdf   *PDFExporter
+}
+
+// NewTranscriptExporter creates a new transcript exporter. A nil clock uses the system clock.
+func NewTranscriptExporter(clk clock.Clock) *TranscriptExporter {
+	return &TranscriptExporter{
+		clock: clock.OrReal(clk),
+		pdf:   NewPDFExporter(clk),
+	}
+}
+
+// Export renders a transcript in the given format
+func (e *TranscriptExporter) Export(ctx context.Context, transcript *Transcript, format Format) ([]byte, error) {
+	switch format {
+	case FormatPDF:
+		return e.pdf.ExportTranscriptPDF(ctx, transcript)
+	case FormatMarkdown:
+		return e.exportMarkdown(transcript), nil
+	case FormatText:
+		return e.exportText(transcript), nil
+	default:
+		return nil, fmt.Errorf("unsupported transcript format: %s", format)
+	}
+}
+
+// GetContentType returns the MIME type for a transcript format
+func (e *TranscriptExporter) GetContentType(format Format) string {
+	switch format {
+	case FormatPDF:
+		return "application/pdf"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// GetFileExtension returns the file extension for a transcript format
+func (e *TranscriptExporter) GetFileExtension(format Format) string {
+	switch format {
+	case FormatPDF:
+		return ".pdf"
+	case FormatMarkdown:
+		return ".md"
+	case FormatText:
+		return ".txt"
+	default:
+		return ".bin"
+	}
+}
+
+// exportMarkdown renders each turn as a bold speaker line followed by its text
+func (e *TranscriptExporter) exportMarkdown(transcript *Transcript) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", transcript.Title)
+	for _, entry := range transcript.Entries {
+		fmt.Fprintf(&b, "**%s** — %s", entry.Speaker, entry.CreatedAt.UTC().Format(transcriptTimeLayout))
+		if label := entry.Label(); label != "" {
+			fmt.Fprintf(&b, " _(%s)_", label)
+		}
+		b.WriteString("\n\n")
+
+		if entry.MatchedQuestion != "" {
+			fmt.Fprintf(&b, "> Matched question: %s\n\n", entry.MatchedQuestion)
+		}
+		fmt.Fprintf(&b, "%s\n\n", entry.Body())
+	}
+	fmt.Fprintf(&b, "---\n\n_Exported: %s_\n", e.clock.Now().UTC().Format(transcriptTimeLayout))
+
+	return []byte(b.String())
+}
+
+// exportText renders each turn as "[time] Speaker: text"
+func (e *TranscriptExporter) exportText(transcript *Transcript) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n%s\n\n", transcript.Title, strings.Repeat("=", len(transcript.Title)))
+	for _, entry := range transcript.Entries {
+		fmt.Fprintf(&b, "[%s] %s", entry.CreatedAt.UTC().Format(transcriptTimeLayout), entry.Speaker)
+		if label := entry.Label(); label != "" {
+			fmt.Fprintf(&b, " (%s)", label)
+		}
+		fmt.Fprintf(&b, ": %s\n", entry.Body())
+
+		if entry.MatchedQuestion != "" {
+			fmt.Fprintf(&b, "    Matched question: %s\n", entry.MatchedQuestion)
+		}
+	}
+	fmt.Fprintf(&b, "\nExported: %s\n", e.clock.Now().UTC().Format(transcriptTimeLayout))
+
+	return []byte(b.String())
+}
+
+// Label describes how a turn was sent, e.g. "audio, 12s", or "" for plain text
+func (t TranscriptEntry) Label() string {
+	if !t.Audio {
+		return ""
+	}
+	if t.DurationMs > 0 {
+		return fmt.Sprintf("audio, %ds", (t.DurationMs+500)/1000)
+	}
+	return "audio"
+}
+
+// Body returns the turn's text, or a placeholder for audio without a transcript
+func (t TranscriptEntry) Body() string {
+	if t.Text == "" && t.Audio {
+		return "[no transcript available]"
+	}
+	return t.Text
+}
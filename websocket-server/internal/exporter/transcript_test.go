@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+func TestExportTranscript(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 1, 2, 3, minute, 0, 0, time.UTC) }
+	transcript := &Transcript{
+		Title: "Conversation transcript (user 7)",
+		Entries: []TranscriptEntry{
+			{Speaker: "User 7", Text: "Why Go?", CreatedAt: at(1)},
+			{Speaker: "Assistant", Text: "It compiles fast.", MatchedQuestion: "Why do you like Go?", CreatedAt: at(2)},
+			{Speaker: "User 7", Text: "And Rust?", Audio: true, DurationMs: 11600, CreatedAt: at(3)},
+			{Speaker: "User 7", Audio: true, CreatedAt: at(4)},
+		},
+	}
+	exp := NewTranscriptExporter(clock.NewFake(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)))
+
+	tests := []struct {
+		format Format
+		want   []string // In order
+	}{
+		{FormatMarkdown, []string{
+			"# Conversation transcript (user 7)",
+			"**User 7** — 2024-01-02 03:01:00 UTC\n\nWhy Go?",
+			"**Assistant** — 2024-01-02 03:02:00 UTC\n\n> Matched question: Why do you like Go?\n\nIt compiles fast.",
+			"**User 7** — 2024-01-02 03:03:00 UTC _(audio, 12s)_\n\nAnd Rust?",
+			"**User 7** — 2024-01-02 03:04:00 UTC _(audio)_\n\n[no transcript available]",
+			"_Exported: 2024-01-03 00:00:00 UTC_",
+		}},
+		{FormatText, []string{
+			"Conversation transcript (user 7)\n================================",
+			"[2024-01-02 03:01:00 UTC] User 7: Why Go?",
+			"[2024-01-02 03:02:00 UTC] Assistant: It compiles fast.\n    Matched question: Why do you like Go?",
+			"[2024-01-02 03:03:00 UTC] User 7 (audio, 12s): And Rust?",
+			"[2024-01-02 03:04:00 UTC] User 7 (audio): [no transcript available]",
+			"Exported: 2024-01-03 00:00:00 UTC",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			data, err := exp.Export(context.Background(), transcript, tt.format)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+
+			rest := string(data)
+			for _, want := range tt.want {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("transcript lacks %q after the previous turn:\n%s", want, data)
+				}
+				rest = rest[i+len(want):]
+			}
+		})
+	}
+
+	t.Run("pdf", func(t *testing.T) {
+		data, err := exp.Export(context.Background(), transcript, FormatPDF)
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		if !bytes.HasPrefix(data, []byte("%PDF-")) {
+			t.Errorf("PDF transcript starts with %q", data[:min(len(data), 8)])
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if _, err := exp.Export(context.Background(), transcript, FormatDOCX); err == nil {
+			t.Errorf("exported a transcript as docx")
+		}
+	})
+}
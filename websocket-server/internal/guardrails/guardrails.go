@@ -0,0 +1,159 @@
+package guardrails
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PIIType identifies a kind of personally identifiable information
+type PIIType string
+
+const (
+	PIIEmail   PIIType = "email"
+	PIIPhone   PIIType = "phone"
+	PIIAddress PIIType = "address"
+	PIISSN     PIIType = "ssn"
+)
+
+// DefaultReplacement is substituted for redacted PII when no replacement is configured
+const DefaultReplacement = "[REDACTED]"
+
+// DefaultBlockedTerms are flagged as disallowed content when no terms are configured
+var DefaultBlockedTerms = []string{
+	"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "cunt",
+	"retard", "retarded", "nigger", "faggot",
+}
+
+// piiPatterns holds the detection pattern for each PII type.
+// SSNs are checked before phone numbers so they are reported as SSNs.
+var piiPatterns = []struct {
+	kind    PIIType
+	pattern *regexp.Regexp
+}{
+	{PIIEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{PIISSN, regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	{PIIPhone, regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-]?)\d{3}[\s.-]?\d{4}\b`)},
+	{PIIAddress, regexp.MustCompile(`\b\d{1,6}\s+(?:[A-Z][A-Za-z]*\.?\s+){1,4}(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way|Place|Pl|Terrace|Parkway|Pkwy)\b\.?(?:,?\s+(?:Apt|Suite|Unit|#)\.?\s*\w+)?`)},
+}
+
+// Config holds the guardrail rules applied to generated content
+type Config struct {
+	PIITypes     []PIIType // PII kinds redacted from generated text (default: all)
+	BlockedTerms []string  // Words or phrases flagged as disallowed content (default: DefaultBlockedTerms)
+	Replacement  string    // Text substituted for redacted PII (default: DefaultReplacement)
+
+	MinAnswerLength  int  // Characters an answer needs to be saved automatically (0 = DefaultMinAnswerLength)
+	SaveFlaggedTerms bool // Save answers with disallowed terms automatically instead of holding them back
+}
+
+// Result describes what the filter changed or found in a piece of text
+type Result struct {
+	Text     string    `json:"-"`
+	Redacted []PIIType `json:"redacted,omitempty"` // PII kinds removed from the text
+	Flags    []string  `json:"flags,omitempty"`    // Disallowed terms found in the text
+}
+
+// Flagged reports whether the text contained disallowed content
+func (r *Result) Flagged() bool {
+	return len(r.Flags) > 0
+}
+
+// Filter redacts PII from and flags disallowed content in LLM generated text
+type Filter struct {
+	piiTypes    map[PIIType]bool
+	blocked     *regexp.Regexp // nil when no terms are blocked
+	replacement string
+
+	minAnswerLength  int
+	saveFlaggedTerms bool
+}
+
+// NewFilter creates a filter from config. A nil config enables all rules with defaults.
+func NewFilter(config *Config) *Filter {
+	if config == nil {
+		config = &Config{}
+	}
+
+	piiTypes := make(map[PIIType]bool)
+	if config.PIITypes == nil {
+		for _, p := range piiPatterns {
+			piiTypes[p.kind] = true
+		}
+	}
+	for _, t := range config.PIITypes {
+		piiTypes[t] = true
+	}
+
+	terms := config.BlockedTerms
+	if terms == nil {
+		terms = DefaultBlockedTerms
+	}
+
+	replacement := config.Replacement
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+
+	minAnswerLength := config.MinAnswerLength
+	if minAnswerLength <= 0 {
+		minAnswerLength = DefaultMinAnswerLength
+	}
+
+	return &Filter{
+		piiTypes:    piiTypes,
+		blocked:     compileBlockedTerms(terms),
+		replacement: replacement,
+
+		minAnswerLength:  minAnswerLength,
+		saveFlaggedTerms: config.SaveFlaggedTerms,
+	}
+}
+
+// Apply redacts PII from text, unless allowPII is set, and flags disallowed terms.
+// Flagged text is returned unchanged apart from redactions; callers decide how to surface it.
+func (f *Filter) Apply(text string, allowPII bool) Result {
+	result := Result{Text: text}
+
+	if !allowPII {
+		for _, p := range piiPatterns {
+			if !f.piiTypes[p.kind] || !p.pattern.MatchString(result.Text) {
+				continue
+			}
+			result.Text = p.pattern.ReplaceAllLiteralString(result.Text, f.replacement)
+			result.Redacted = append(result.Redacted, p.kind)
+		}
+	}
+
+	if f.blocked != nil {
+		seen := make(map[string]bool)
+		for _, match := range f.blocked.FindAllString(result.Text, -1) {
+			term := strings.ToLower(match)
+			if !seen[term] {
+				seen[term] = true
+				result.Flags = append(result.Flags, term)
+			}
+		}
+		sort.Strings(result.Flags)
+	}
+
+	return result
+}
+
+// compileBlockedTerms builds a case-insensitive whole-word pattern matching any of terms
+func compileBlockedTerms(terms []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	// Longer terms first so "fucking" is reported rather than "fuck"
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
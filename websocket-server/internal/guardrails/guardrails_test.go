@@ -0,0 +1,136 @@
+package guardrails
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyRedactsPII(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantText     string
+		wantRedacted []PIIType
+	}{
+		{
+			name:         "phone number",
+			text:         "Reach me at (555) 123-4567 to talk about Go.",
+			wantText:     "Reach me at [REDACTED] to talk about Go.",
+			wantRedacted: []PIIType{PIIPhone},
+		},
+		{
+			name:         "international phone number",
+			text:         "Call +1 555.123.4567 anytime.",
+			wantText:     "Call [REDACTED] anytime.",
+			wantRedacted: []PIIType{PIIPhone},
+		},
+		{
+			name:         "email",
+			text:         "Email ada@example.com for references.",
+			wantText:     "Email [REDACTED] for references.",
+			wantRedacted: []PIIType{PIIEmail},
+		},
+		{
+			name:         "ssn is not reported as a phone number",
+			text:         "My SSN is 123-45-6789.",
+			wantText:     "My SSN is [REDACTED].",
+			wantRedacted: []PIIType{PIISSN},
+		},
+		{
+			name:         "street address",
+			text:         "I live at 221 Baker Street, Apt 2 in London.",
+			wantText:     "I live at [REDACTED] in London.",
+			wantRedacted: []PIIType{PIIAddress},
+		},
+		{
+			name:     "numbers that are not PII",
+			text:     "I cut latency by 40% across 12 services in 2023.",
+			wantText: "I cut latency by 40% across 12 services in 2023.",
+		},
+	}
+
+	filter := NewFilter(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filter.Apply(tt.text, false)
+			if result.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", result.Text, tt.wantText)
+			}
+			if !reflect.DeepEqual(result.Redacted, tt.wantRedacted) {
+				t.Errorf("redacted = %v, want %v", result.Redacted, tt.wantRedacted)
+			}
+			if result.Flagged() {
+				t.Errorf("unexpected flags %v", result.Flags)
+			}
+		})
+	}
+}
+
+func TestApplyAllowPII(t *testing.T) {
+	text := "Reach me at 555-123-4567 or ada@example.com."
+
+	result := NewFilter(nil).Apply(text, true)
+	if result.Text != text || len(result.Redacted) != 0 {
+		t.Errorf("opted-in text changed: %q (redacted %v)", result.Text, result.Redacted)
+	}
+}
+
+func TestApplyFlagsDisallowedContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantFlags []string
+	}{
+		{"blocked term", "That legacy code was shit.", []string{"shit"}},
+		{"case insensitive and once per term", "Shit happens, SHIT happens.", []string{"shit"}},
+		{"longest term reported", "Fucking deadlines.", []string{"fucking"}},
+		{"several terms sorted", "What a bastard of a shit deploy.", []string{"bastard", "shit"}},
+		{"whole words only", "Our Scunthorpe office shipped it.", nil},
+		{"clean text", "I led the migration to Kubernetes.", nil},
+	}
+
+	filter := NewFilter(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filter.Apply(tt.text, false)
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("flags = %v, want %v", result.Flags, tt.wantFlags)
+			}
+			if result.Text != tt.text {
+				t.Errorf("flagged text changed to %q", result.Text)
+			}
+		})
+	}
+}
+
+func TestNewFilterConfig(t *testing.T) {
+	filter := NewFilter(&Config{
+		PIITypes:     []PIIType{PIIEmail},
+		BlockedTerms: []string{" synergy ", ""},
+		Replacement:  "***",
+	})
+
+	result := filter.Apply("Synergy! Mail ada@example.com or call 555-123-4567.", false)
+	if want := "Synergy! Mail *** or call 555-123-4567."; result.Text != want {
+		t.Errorf("text = %q, want %q", result.Text, want)
+	}
+	if !reflect.DeepEqual(result.Redacted, []PIIType{PIIEmail}) {
+		t.Errorf("redacted = %v, want [email]", result.Redacted)
+	}
+	if !reflect.DeepEqual(result.Flags, []string{"synergy"}) {
+		t.Errorf("flags = %v, want [synergy]", result.Flags)
+	}
+
+	// An empty term list disables the content check
+	result = NewFilter(&Config{BlockedTerms: []string{}}).Apply("shit", false)
+	if result.Flagged() {
+		t.Errorf("flags = %v with no blocked terms", result.Flags)
+	}
+
+	// An empty PII list disables redaction
+	result = NewFilter(&Config{PIITypes: []PIIType{}}).Apply("call 555-123-4567", false)
+	if !strings.Contains(result.Text, "555-123-4567") {
+		t.Errorf("text = %q with no PII types", result.Text)
+	}
+}
@@ -0,0 +1,139 @@
+package guardrails
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMinAnswerLength is how many characters a generated answer needs by default
+// before it is saved without review
+const DefaultMinAnswerLength = 80
+
+// Reasons a generated answer is held back from being saved automatically
+const (
+	IssueTooShort          = "too_short"          // Shorter than the minimum answer length
+	IssueTruncated         = "truncated"          // Cut off mid-sentence or inside a code block
+	IssueRepetitive        = "repetitive"         // Mostly the same words or sentences over and over
+	IssueRefusal           = "refusal"            // The model declined to answer
+	IssueDisallowedContent = "disallowed_content" // Contains blocked terms (see Result.Flags)
+)
+
+// repetitionMinWords is how long an answer must be before its word variety is judged
+const repetitionMinWords = 30
+
+// minDistinctWordRatio is the share of distinct words below which an answer is repetitive
+const minDistinctWordRatio = 0.3
+
+// refusalPrefixes are openings, lowercased, of answers in which the model declines
+var refusalPrefixes = []string{
+	"i'm sorry, but", "i am sorry, but", "sorry, i can't", "sorry, i cannot",
+	"i can't help with", "i cannot help with", "i can't answer", "i cannot answer",
+	"i'm unable to", "i am unable to", "as an ai",
+}
+
+// sentenceEnders are the characters a complete answer may end with, after any
+// closing quotes, brackets or markdown emphasis
+const sentenceEnders = ".!?。！？…"
+
+// CheckAnswer judges whether a filtered, generated answer is good enough to be saved
+// without the user reviewing it first. It returns the issues found, or nil for an answer
+// that passes. Blocked terms are an issue unless the filter was configured to save them.
+func (f *Filter) CheckAnswer(result Result) []string {
+	text := strings.TrimSpace(result.Text)
+	var issues []string
+
+	if utf8.RuneCountInString(text) < f.minAnswerLength {
+		issues = append(issues, IssueTooShort)
+	}
+	if text != "" && isTruncated(text) {
+		issues = append(issues, IssueTruncated)
+	}
+	if isRepetitive(text) {
+		issues = append(issues, IssueRepetitive)
+	}
+	if isRefusal(text) {
+		issues = append(issues, IssueRefusal)
+	}
+	if result.Flagged() && !f.saveFlaggedTerms {
+		issues = append(issues, IssueDisallowedContent)
+	}
+
+	return issues
+}
+
+// isTruncated reports whether text stops mid-sentence, as when the model runs out of
+// tokens: it leaves a code block open or doesn't end with a sentence terminator.
+// A final list item or heading is complete without one.
+func isTruncated(text string) bool {
+	if strings.Count(text, "```")%2 == 1 {
+		return true
+	}
+	if strings.HasSuffix(text, "```") {
+		return false
+	}
+
+	lines := strings.Split(text, "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if isListItem(last) || strings.HasPrefix(last, "#") {
+		return strings.HasSuffix(last, ",") || strings.HasSuffix(last, ":")
+	}
+
+	last = strings.TrimRight(last, "\"'”’)]*_")
+	r, _ := utf8.DecodeLastRuneInString(last)
+	return !strings.ContainsRune(sentenceEnders, r)
+}
+
+// isListItem reports whether line is a markdown bullet or numbered list item
+func isListItem(line string) bool {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "• ") {
+		return true
+	}
+	digits := strings.TrimLeftFunc(line, unicode.IsDigit)
+	return len(digits) < len(line) && (strings.HasPrefix(digits, ". ") || strings.HasPrefix(digits, ") "))
+}
+
+// isRepetitive reports whether a long enough text uses too few distinct words, or
+// repeats one of its sentences three or more times
+func isRepetitive(text string) bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	if len(words) < repetitionMinWords {
+		return false
+	}
+
+	distinct := make(map[string]bool, len(words))
+	for _, word := range words {
+		distinct[word] = true
+	}
+	if float64(len(distinct))/float64(len(words)) < minDistinctWordRatio {
+		return true
+	}
+
+	sentences := make(map[string]int)
+	for _, sentence := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return strings.ContainsRune(sentenceEnders, r) || r == '\n'
+	}) {
+		sentence = strings.Join(strings.Fields(sentence), " ")
+		if len(sentence) < 20 {
+			continue
+		}
+		if sentences[sentence]++; sentences[sentence] >= 3 {
+			return true
+		}
+	}
+	return false
+}
+
+// isRefusal reports whether text opens by declining to answer
+func isRefusal(text string) bool {
+	opening := strings.ToLower(text)
+	opening = strings.ReplaceAll(opening, "’", "'")
+	for _, prefix := range refusalPrefixes {
+		if strings.HasPrefix(opening, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package guardrails
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckAnswer(t *testing.T) {
+	const complete = "I moved our billing service to Go and halved its p99 latency without slowing our releases."
+
+	tests := []struct {
+		name   string
+		answer string
+		want   []string
+	}{
+		{"complete answer", complete, nil},
+		{"closing quote and emphasis", `My manager called it "the fastest migration we've had."` + ` **Really.**` + strings.Repeat(" It went well.", 3), nil},
+		{"final list item", "Three things matter to me when I join a team:\n- Clear ownership of services\n- Fast, honest code review\n- Time set aside for on-call follow-ups", nil},
+		{"closed code block", "Here is how I would write the retry loop in Go for this service:\n```go\nfor attempt := 0; attempt < 3; attempt++ {}\n```", nil},
+		{"truncated mid-sentence", "I moved our billing service to Go and halved its p99 latency, which let the team finally stop paging us at", []string{IssueTruncated}},
+		{"open code block", "Here is how I would write the retry loop in Go for this service:\n```go\nfor attempt := 0; attempt < 3; attempt++ {", []string{IssueTruncated}},
+		{"list cut off", "Three things matter to me when I join a team, in this order:\n- Clear ownership of services,", []string{IssueTruncated}},
+		{"too short", "I like Go.", []string{IssueTooShort}},
+		{"empty", "  ", []string{IssueTooShort}},
+		{"repetitive words", strings.Repeat("Go is great and Go is fast. ", 8), []string{IssueRepetitive}},
+		{"repeated sentence", strings.Repeat("I always write tests before shipping. ", 3) + complete, []string{IssueRepetitive}},
+		{"refusal", "I’m sorry, but I can't answer questions about my previous employer's internal systems.", []string{IssueRefusal}},
+		{"blocked term", "Honestly the legacy billing code was a pile of shit, so I rewrote it in Go over a quarter.", []string{IssueDisallowedContent}},
+		{"several issues", "As an AI I", []string{IssueTooShort, IssueTruncated, IssueRefusal}},
+	}
+
+	filter := NewFilter(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.CheckAnswer(filter.Apply(tt.answer, false)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckAnswer(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAnswerConfig(t *testing.T) {
+	short := "I like Go."
+	flagged := "Honestly the legacy billing code was a pile of shit, so I rewrote it in Go over a quarter."
+
+	tests := []struct {
+		name   string
+		config *Config
+		answer string
+		want   []string
+	}{
+		{"lower minimum length", &Config{MinAnswerLength: 5}, short, nil},
+		{"higher minimum length", &Config{MinAnswerLength: 200}, flagged, []string{IssueTooShort, IssueDisallowedContent}},
+		{"flagged terms saved", &Config{SaveFlaggedTerms: true}, flagged, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewFilter(tt.config)
+			if got := filter.CheckAnswer(filter.Apply(tt.answer, false)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckAnswer(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// AdminTokenHeader carries the shared secret for admin-only endpoints
+const AdminTokenHeader = "X-Admin-Token"
+
+// requireAdmin checks the admin token header and writes an error response when it
+// is missing or wrong. Admin endpoints are disabled when no token is configured.
+func requireAdmin(w http.ResponseWriter, r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Admin access is not configured"})
+		return false
+	}
+
+	token := r.Header.Get(AdminTokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Invalid admin token"})
+		return false
+	}
+
+	return true
+}
@@ -2,32 +2,52 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/your-org/websocket-server/internal/analyzer"
 	"github.com/your-org/websocket-server/internal/exporter"
+	"github.com/your-org/websocket-server/internal/tier"
+	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // AnalysisHandler handles resume analysis HTTP requests
 type AnalysisHandler struct {
-	analyzer analyzer.ResumeAnalyzer
-	exporter exporter.Exporter
+	analyzer  analyzer.ResumeAnalyzer
+	exporter  exporter.Exporter
+	llmClient analyzer.LLMClient // Optional; used for comparison summaries
+	auth      Authenticator      // Resolves the caller for ownership checks
+	tiers     tier.Resolver      // Optional; rate limits bulk re-analysis
 }
 
-// NewAnalysisHandler creates a new analysis handler instance
-func NewAnalysisHandler(analyzer analyzer.ResumeAnalyzer, exp exporter.Exporter) *AnalysisHandler {
+// NewAnalysisHandler creates a new analysis handler instance.
+// With a nil authenticator every caller is anonymous and can only analyze anonymous uploads.
+// With a nil tier resolver bulk re-analysis is limited only by the analysis queue.
+func NewAnalysisHandler(analyzer analyzer.ResumeAnalyzer, exp exporter.Exporter, llmClient analyzer.LLMClient, auth Authenticator, tiers tier.Resolver) *AnalysisHandler {
 	return &AnalysisHandler{
-		analyzer: analyzer,
-		exporter: exp,
+		analyzer:  analyzer,
+		exporter:  exp,
+		llmClient: llmClient,
+		auth:      auth,
+		tiers:     tiers,
 	}
 }
 
-// HandleAnalyzeResume starts asynchronous resume analysis
+// syncAnalysisWait is how long a synchronous analysis request waits for the job to finish
+// before answering with its job ID instead
+const syncAnalysisWait = 20 * time.Second
+
+// HandleAnalyzeResume starts asynchronous resume analysis. With sync=true it waits a bounded
+// time for small documents and returns the result directly, falling back to the asynchronous
+// response when the documents are large or the analysis doesn't finish in time.
 func (h *AnalysisHandler) HandleAnalyzeResume(w http.ResponseWriter, r *http.Request) {
 	// Only allow POST requests
 	if r.Method != http.MethodPost {
@@ -35,47 +55,179 @@ func (h *AnalysisHandler) HandleAnalyzeResume(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Get upload ID from query parameter
-	idStr := r.URL.Query().Get("id")
-	if idStr == "" {
-		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Upload ID is required"})
-		return
-	}
-
-	uploadID, err := strconv.Atoi(idStr)
+	// Get upload IDs from query parameter. Several uploads (repeated or comma-separated id)
+	// are merged into one profile; the first is the primary upload.
+	uploadIDs, err := parseUploadIDs(r.URL.Query()["id"])
 	if err != nil {
-		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid upload ID"})
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
 	}
+	uploadID := uploadIDs[0]
 
-	// Get optional user ID from query parameter
+	// The job belongs to the authenticated caller. An explicit user_id must match them.
 	var userID *int
+	if uid, ok := callerID(h.auth, r); ok {
+		userID = &uid
+	} else if !anonymousMode(h.auth) {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
 	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
-		if uid, err := strconv.Atoi(userIDStr); err == nil {
-			userID = &uid
+		uid, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+			return
+		}
+		if userID == nil {
+			respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+			return
+		}
+		if uid != *userID {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "Cannot start analysis for another user"})
+			return
 		}
 	}
 
-	// Start async analysis
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	synchronous := r.URL.Query().Get("sync") == "true"
+
+	// Start analysis
+	timeout := 10 * time.Second
+	if synchronous {
+		timeout += syncAnalysisWait
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
 	defer cancel()
 
-	jobID, err := h.analyzer.AnalyzeAsync(ctx, uploadID, userID)
+	if !h.authorizeUploads(ctx, w, r, uploadIDs) {
+		return
+	}
+
+	var jobID string
+	var finished bool
+	if synchronous {
+		jobID, finished, err = h.analyzer.AnalyzeSync(ctx, uploadIDs, userID, syncAnalysisWait)
+	} else {
+		jobID, err = h.analyzer.AnalyzeMultipleAsync(ctx, uploadIDs, userID)
+	}
 	if err != nil {
 		log.Printf("Error starting analysis: %v", err)
-		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to start analysis"})
+		switch {
+		case errors.Is(err, analyzer.ErrUploadNotOwned):
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "You do not have access to these uploads"})
+		case errors.Is(err, analyzer.ErrUploadNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		case errors.Is(err, analyzer.ErrQueueFull):
+			respondQueueFull(w)
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to start analysis"})
+		}
+		return
+	}
+
+	if finished {
+		h.respondSyncAnalysis(ctx, w, jobID)
 		return
 	}
 
 	// Return job ID for status tracking
-	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+	response := map[string]interface{}{
 		"status":    "analysis_started",
 		"job_id":    jobID,
 		"upload_id": uploadID,
 		"message":   "Resume analysis has been started. Use /api/analysis/status to track progress.",
+	}
+	if len(uploadIDs) > 1 {
+		response["additional_upload_ids"] = uploadIDs[1:]
+	}
+	if synchronous {
+		response["message"] = "The document is too large to analyze synchronously or the analysis is still running. Use /api/analysis/status to track progress."
+	}
+	respondJSON(w, http.StatusAccepted, response)
+
+	log.Printf("Analysis job %s started for upload IDs: %v", jobID, uploadIDs)
+}
+
+// respondSyncAnalysis responds to a synchronous analysis whose job has finished: with the
+// result when it completed, or with the job's status when it failed or needs review
+func (h *AnalysisHandler) respondSyncAnalysis(ctx context.Context, w http.ResponseWriter, jobID string) {
+	status, err := h.analyzer.GetStatus(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting status of job %s: %v", jobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get analysis status", "job_id": jobID})
+		return
+	}
+
+	if status.Status != "completed" {
+		respondJSON(w, http.StatusUnprocessableEntity, status)
+		log.Printf("Synchronous analysis job %s finished with status %s", jobID, status.Status)
+		return
+	}
+
+	result, err := h.analyzer.GetResult(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting result of job %s: %v", jobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get analysis result", "job_id": jobID})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+	log.Printf("Synchronous analysis job %s completed", jobID)
+}
+
+// authorizeUploads checks that the caller may access every upload (see authorizeUpload),
+// writing an error response when one is missing or not accessible. Anonymous uploads are
+// only open in anonymous mode, which the analyzer doesn't know about.
+func (h *AnalysisHandler) authorizeUploads(ctx context.Context, w http.ResponseWriter, r *http.Request, uploadIDs []int) bool {
+	for _, id := range uploadIDs {
+		upload, err := h.analyzer.GetUpload(ctx, id)
+		if err != nil {
+			log.Printf("Error getting upload %d: %v", id, err)
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+			return false
+		}
+		if !authorizeUpload(w, r, h.auth, upload) {
+			return false
+		}
+	}
+	return true
+}
+
+// respondQueueFull tells the client to retry once the analysis queue has drained
+func respondQueueFull(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "30")
+	respondJSON(w, http.StatusServiceUnavailable, map[string]string{
+		"error":   "Analysis queue is full",
+		"message": "Too many analyses are waiting to run. Please try again shortly.",
 	})
+}
+
+// maxUploadsPerAnalysis limits how many documents can be merged into one analysis
+const maxUploadsPerAnalysis = 5
+
+// parseUploadIDs parses upload IDs from repeated and/or comma-separated id parameters
+func parseUploadIDs(values []string) ([]int, error) {
+	var ids []int
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("Invalid upload ID: %s", part)
+			}
+			ids = append(ids, id)
+		}
+	}
 
-	log.Printf("Analysis job %s started for upload ID: %d", jobID, uploadID)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Upload ID is required")
+	}
+	if len(ids) > maxUploadsPerAnalysis {
+		return nil, fmt.Errorf("At most %d uploads can be analyzed together", maxUploadsPerAnalysis)
+	}
+	return ids, nil
 }
 
 // HandleAnalysisStatus returns the current status of an analysis job
@@ -104,9 +256,73 @@ func (h *AnalysisHandler) HandleAnalysisStatus(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !authorizeJob(w, r, h.auth, status) {
+		return
+	}
+
 	respondJSON(w, http.StatusOK, status)
 }
 
+// HandleGetFullJob returns a job's status together with its result once it has
+// completed, saving clients a status call before the result call. The extracted text
+// is left out unless include_text=true, as it can be large.
+func (h *AnalysisHandler) HandleGetFullJob(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get job ID from query parameter
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.analyzer.GetStatus(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting analysis status for full job: %v", err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		return
+	}
+
+	if !authorizeJob(w, r, h.auth, status) {
+		return
+	}
+
+	if r.URL.Query().Get("include_text") != "true" {
+		status.ExtractedText = nil
+	}
+
+	full := &models.FullJob{Job: status}
+	if status.Status == "completed" {
+		full.Result, err = h.analyzer.GetResult(ctx, jobID)
+		if err != nil {
+			log.Printf("Error getting analysis result for full job %s: %v", jobID, err)
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get result"})
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, full)
+}
+
+// authorizeJobID looks up a job and checks that the caller may access it, writing an
+// error response when the job doesn't exist or belongs to another user
+func (h *AnalysisHandler) authorizeJobID(ctx context.Context, w http.ResponseWriter, r *http.Request, jobID string) bool {
+	status, err := h.analyzer.GetStatus(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting job %s for authorization: %v", jobID, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		return false
+	}
+	return authorizeJob(w, r, h.auth, status)
+}
+
 // HandleAnalysisResult returns the complete analysis result for a completed job
 func (h *AnalysisHandler) HandleAnalysisResult(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
@@ -125,11 +341,20 @@ func (h *AnalysisHandler) HandleAnalysisResult(w http.ResponseWriter, r *http.Re
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
 	// Get result
 	result, err := h.analyzer.GetResult(ctx, jobID)
 	if err != nil {
 		log.Printf("Error getting analysis result: %v", err)
-		if err.Error() == "job is not completed yet" {
+		if errors.Is(err, analyzer.ErrJobNotCompleted) {
+			// A job that stopped without a result reports why, with its error code
+			if status, statusErr := h.analyzer.GetStatus(ctx, jobID); statusErr == nil && status.ErrorMessage != nil {
+				respondJSON(w, http.StatusUnprocessableEntity, status)
+				return
+			}
 			respondJSON(w, http.StatusAccepted, map[string]string{
 				"error":   "Analysis not yet completed",
 				"message": "Please check /api/analysis/status for current progress",
@@ -143,7 +368,9 @@ func (h *AnalysisHandler) HandleAnalysisResult(w http.ResponseWriter, r *http.Re
 	respondJSON(w, http.StatusOK, result)
 }
 
-// HandleSearchResumes searches for similar resumes using vector similarity
+// HandleSearchResumes searches for similar resumes using vector similarity.
+// Only resumes of the caller's tenant are searched. With rerank=true the top results are
+// re-ranked by the LLM, falling back to the vector order if that fails.
 func (h *AnalysisHandler) HandleSearchResumes(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
 	if r.Method != http.MethodGet {
@@ -158,52 +385,134 @@ func (h *AnalysisHandler) HandleSearchResumes(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Get limit (default 10)
-	limit := 10
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
-			limit = l
+	// Get limit (default 10, max 50)
+	limit := ParsePagination(r, 10, 50).Limit
+
+	rerank := false
+	if rerankStr := r.URL.Query().Get("rerank"); rerankStr != "" {
+		var err error
+		rerank, err = strconv.ParseBool(rerankStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid rerank value"})
+			return
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	// Re-ranking waits for the LLM
+	timeout := 10 * time.Second
+	if rerank {
+		timeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
 	defer cancel()
 
+	tenantID, err := callerTenant(ctx, h.auth, r)
+	if err != nil {
+		log.Printf("Error resolving tenant for resume search: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Search failed"})
+		return
+	}
+
 	// Search
-	profiles, err := h.analyzer.SearchSimilarResumes(ctx, query, limit)
+	profiles, err := h.analyzer.SearchSimilarResumes(ctx, tenantID, query, limit)
 	if err != nil {
 		log.Printf("Error searching resumes: %v", err)
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Search failed"})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	response := map[string]interface{}{
 		"query":   query,
 		"count":   len(profiles),
 		"results": profiles,
-	})
+	}
+
+	if rerank {
+		reranked, err := h.analyzer.RerankResumes(ctx, query, profiles)
+		if err != nil {
+			log.Printf("Error re-ranking search results, keeping the vector order: %v", err)
+			response["reranked"] = false
+		} else {
+			response["results"] = reranked.Profiles
+			response["reranked"] = true
+			response["justifications"] = reranked.Justifications
+		}
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
-// HandleGetUserJobs returns all analysis jobs for a specific user
-func (h *AnalysisHandler) HandleGetUserJobs(w http.ResponseWriter, r *http.Request) {
+// HandleSimilarProfiles returns the profiles of the job's organization most similar to
+// its profile ("similar candidates"), precomputed as jobs complete
+func (h *AnalysisHandler) HandleSimilarProfiles(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get user ID from query parameter
-	userIDStr := r.URL.Query().Get("user_id")
-	if userIDStr == "" {
-		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "User ID is required"})
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	// A cache miss searches the vector store
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
 		return
 	}
 
-	userID, err := strconv.Atoi(userIDStr)
+	similar, err := h.analyzer.GetSimilarProfiles(ctx, jobID)
 	if err != nil {
-		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		log.Printf("Error getting similar profiles for job %s: %v", jobID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrJobNotCompleted):
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Analysis not yet completed",
+				"message": err.Error(),
+			})
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get similar profiles"})
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_id":  jobID,
+		"count":   len(similar),
+		"results": similar,
+	})
+}
+
+// HandleGetUserJobs returns all analysis jobs of the authenticated user
+// Query parameters: user_id (optional, must be the caller)
+func (h *AnalysisHandler) HandleGetUserJobs(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
 		return
 	}
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		uid, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+			return
+		}
+		if uid != userID {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "Cannot view jobs of another user"})
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -223,7 +532,133 @@ func (h *AnalysisHandler) HandleGetUserJobs(w http.ResponseWriter, r *http.Reque
 	})
 }
 
-// HandleGetUploadJobs returns all analysis jobs for a specific upload
+// HandleUserAnalytics returns aggregate statistics across the authenticated user's resumes
+// and analyses
+// Query parameters: user_id (optional, must be the caller), top_skills (optional, default 10, max 50)
+func (h *AnalysisHandler) HandleUserAnalytics(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		uid, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+			return
+		}
+		if uid != userID {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "Cannot view analytics of another user"})
+			return
+		}
+	}
+
+	topSkills := 10
+	if topStr := r.URL.Query().Get("top_skills"); topStr != "" {
+		if n, err := strconv.Atoi(topStr); err == nil && n > 0 {
+			topSkills = n
+		}
+	}
+	if topSkills > 50 {
+		topSkills = 50
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	analytics, err := h.analyzer.GetUserAnalytics(ctx, userID, topSkills)
+	if err != nil {
+		log.Printf("Error getting analytics for user %d: %v", userID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to get analytics"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, analytics)
+}
+
+// HandleExportOrgCandidates exports the candidates of the caller's organization (tenant)
+// as a CSV with one row per upload, for importing into applicant tracking systems.
+// Callers without an organization get 403.
+// Query parameters: status and tag (optional), filtering like the upload list
+func (h *AnalysisHandler) HandleExportOrgCandidates(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))
+	tag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
+
+	if status != "" && !validUploadStatuses[status] {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid status",
+			"message": "status must be one of: " + strings.Join(uploadStatusValues(), ", "),
+		})
+		return
+	}
+
+	// The export spans every user of the organization, so it needs a session
+	if _, ok := callerID(h.auth, r); !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	tenantID, err := callerTenant(ctx, h.auth, r)
+	if err != nil {
+		log.Printf("Error resolving tenant for candidate export: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Export failed"})
+		return
+	}
+	// The default tenant holds the uploads of every user without an organization
+	if tenantID == "" {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Candidate export requires an organization"})
+		return
+	}
+
+	candidates, err := h.analyzer.ListOrgCandidates(ctx, tenantID, status, tag)
+	if errors.Is(err, analyzer.ErrTooManyCandidates) {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error":   "Too many candidates",
+			"message": fmt.Sprintf("The export is limited to %d candidates; filter by status or tag", analyzer.MaxOrgCandidates),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error listing candidates for tenant %q: %v", tenantID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Export failed"})
+		return
+	}
+
+	data, err := exporter.ExportCandidatesCSV(candidates)
+	if err != nil {
+		log.Printf("Error exporting candidates for tenant %q: %v", tenantID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Export failed"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=candidates.csv")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error writing candidate export: %v", err)
+	}
+
+	log.Printf("Exported %d candidates for tenant %q (%d bytes)", len(candidates), tenantID, len(data))
+}
+
+// HandleGetUploadJobs returns all analysis jobs for a specific upload. The caller must have
+// access to the upload.
 func (h *AnalysisHandler) HandleGetUploadJobs(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
 	if r.Method != http.MethodGet {
@@ -247,6 +682,10 @@ func (h *AnalysisHandler) HandleGetUploadJobs(w http.ResponseWriter, r *http.Req
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	if !h.authorizeUploads(ctx, w, r, []int{uploadID}) {
+		return
+	}
+
 	// Get jobs for upload
 	jobs, err := h.analyzer.GetJobsByUploadID(ctx, uploadID)
 	if err != nil {
@@ -288,12 +727,16 @@ func (h *AnalysisHandler) HandleDeleteJob(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Only allow deletion of completed or failed jobs
-	if status.Status != "completed" && status.Status != "failed" {
+	if !authorizeJob(w, r, h.auth, status) {
+		return
+	}
+
+	// Only allow deletion of completed, failed, flagged or dead-lettered jobs
+	if status.Status != "completed" && status.Status != "failed" && status.Status != "needs_review" && status.Status != "dead_lettered" {
 		respondJSON(w, http.StatusBadRequest, map[string]string{
 			"error":   "Cannot delete job in progress",
 			"status":  status.Status,
-			"message": "Only completed or failed jobs can be deleted",
+			"message": "Only completed, failed, needs_review or dead_lettered jobs can be deleted",
 		})
 		return
 	}
@@ -333,13 +776,31 @@ func (h *AnalysisHandler) HandleRetryJob(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
 	// Retry the job
 	err := h.analyzer.RetryJob(ctx, jobID)
 	if err != nil {
 		log.Printf("Error retrying job %s: %v", jobID, err)
 
+		if errors.Is(err, analyzer.ErrQueueFull) {
+			respondQueueFull(w)
+			return
+		}
+
+		if errors.Is(err, analyzer.ErrRetryLimitReached) {
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Retry limit reached",
+				"status":  "dead_lettered",
+				"message": "The job failed too many times and was moved to dead_lettered for manual review",
+			})
+			return
+		}
+
 		// Check for specific error messages
-		if err.Error() == "job not found" || err.Error()[:14] == "job not found:" {
+		if errors.Is(err, analyzer.ErrJobNotFound) {
 			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
 			return
 		}
@@ -366,23 +827,439 @@ func (h *AnalysisHandler) HandleRetryJob(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// HandleBatchDeleteJobs deletes multiple analysis jobs in a single operation
-func (h *AnalysisHandler) HandleBatchDeleteJobs(w http.ResponseWriter, r *http.Request) {
+// HandleReanalyzeLLM re-runs only the LLM step of a completed job against its
+// stored embeddings and returns the replaced result
+func (h *AnalysisHandler) HandleReanalyzeLLM(w http.ResponseWriter, r *http.Request) {
 	// Only allow POST requests
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Parse request body
-	var req struct {
-		JobIDs []string `json:"job_ids"`
+	// Get job ID from query parameter
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondJSON(w, http.StatusBadRequest, map[string]string{
-			"error":   "Invalid request body",
-			"message": "Request body must be valid JSON with job_ids array",
+	ctx, cancel := context.WithTimeout(r.Context(), 150*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	result, err := h.analyzer.ReanalyzeJob(ctx, jobID)
+	if err != nil {
+		log.Printf("Error reanalyzing job %s: %v", jobID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrQueueFull):
+			respondQueueFull(w)
+		case errors.Is(err, analyzer.ErrJobNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		case errors.Is(err, analyzer.ErrJobNotCompleted):
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Cannot reanalyze job",
+				"message": err.Error(),
+			})
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to reanalyze job"})
+		}
+		return
+	}
+
+	log.Printf("Reanalyzed job: %s", jobID)
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// maxIndustryLength is the longest target industry accepted when regenerating recommendations
+const maxIndustryLength = 100
+
+// HandleRegenerateRecommendations asks the LLM for fresh job recommendations for a completed
+// job, leaving the rest of the profile as it is, and returns the updated result
+// Query parameters: job_id (required), industry (optional target industry)
+func (h *AnalysisHandler) HandleRegenerateRecommendations(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	industry := strings.TrimSpace(r.URL.Query().Get("industry"))
+	if len(industry) > maxIndustryLength {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Industry must be at most %d characters", maxIndustryLength),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	result, err := h.analyzer.RegenerateRecommendations(ctx, jobID, industry)
+	if err != nil {
+		log.Printf("Error regenerating recommendations for job %s: %v", jobID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrJobNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		case errors.Is(err, analyzer.ErrJobNotCompleted):
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Cannot regenerate recommendations",
+				"message": err.Error(),
+			})
+		case errors.Is(err, analyzer.ErrInvalidRecommendations):
+			respondJSON(w, http.StatusBadGateway, map[string]string{
+				"error":   "Invalid recommendations from LLM",
+				"message": "The existing recommendations were kept; please try again",
+			})
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to regenerate recommendations"})
+		}
+		return
+	}
+
+	log.Printf("Regenerated recommendations for job: %s", jobID)
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// maxProfilePatchBytes bounds the body of a profile patch
+const maxProfilePatchBytes = 1 << 20 // 1 MB
+
+// HandlePatchProfile updates only the fields given in the request body on a completed
+// job's profile, leaving the others untouched, and returns the updated result
+// Query parameters: job_id (required). Body: a JSON object of profile fields.
+func (h *AnalysisHandler) HandlePatchProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	var patch models.ProfilePatch
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxProfilePatchBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+	if len(patch.Fields()) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "No fields to update",
+			"message": "Editable fields: " + strings.Join(models.ProfileFields, ", "),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	result, err := h.analyzer.PatchProfile(ctx, jobID, &patch)
+	if err != nil {
+		log.Printf("Error patching profile of job %s: %v", jobID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrJobNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		case errors.Is(err, analyzer.ErrJobNotCompleted):
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Cannot update profile",
+				"message": err.Error(),
+			})
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to update profile"})
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Limits of a skill gap request
+const (
+	maxSkillGapBodyBytes            = 1 << 20 // 1 MB
+	maxSkillGapJobDescriptionLength = 20000
+)
+
+// SkillGapRequest is the body of a skill gap request
+type SkillGapRequest struct {
+	JobDescriptions []string `json:"job_descriptions"`
+}
+
+// HandleSkillGaps runs a gap analysis of a completed job's profile against each job
+// description in the body and returns the missing skills ranked by how many of the job
+// descriptions ask for them
+// Query parameters: job_id (required). Body: {"job_descriptions": ["...", ...]}
+func (h *AnalysisHandler) HandleSkillGaps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	var req SkillGapRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSkillGapBodyBytes)).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+	if len(req.JobDescriptions) == 0 || len(req.JobDescriptions) > analyzer.MaxSkillGapJobDescriptions {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Between 1 and %d job descriptions are required", analyzer.MaxSkillGapJobDescriptions),
+		})
+		return
+	}
+	for i, jobDescription := range req.JobDescriptions {
+		jobDescription = strings.TrimSpace(jobDescription)
+		if jobDescription == "" || len(jobDescription) > maxSkillGapJobDescriptionLength {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("Job description %d must be between 1 and %d characters", i, maxSkillGapJobDescriptionLength),
+			})
+			return
+		}
+		req.JobDescriptions[i] = jobDescription
+	}
+
+	// The job descriptions are analyzed concurrently, each waiting for the LLM
+	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	report, err := h.analyzer.AnalyzeSkillGaps(ctx, jobID, req.JobDescriptions)
+	if err != nil {
+		log.Printf("Error analyzing skill gaps for job %s: %v", jobID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrJobNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		case errors.Is(err, analyzer.ErrJobNotCompleted):
+			respondJSON(w, http.StatusConflict, map[string]string{
+				"error":   "Cannot analyze skill gaps",
+				"message": err.Error(),
+			})
+		case errors.Is(err, analyzer.ErrInvalidSkillGap):
+			respondJSON(w, http.StatusBadGateway, map[string]string{
+				"error":   "Invalid skill gap analysis from LLM",
+				"message": "Please try again",
+			})
+		default:
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to analyze skill gaps"})
+		}
+		return
+	}
+
+	log.Printf("Analyzed skill gaps for job %s against %d job descriptions: %d missing skills",
+		jobID, report.JobDescriptionCount, len(report.MissingSkills))
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// HandleChunkPreview extracts and chunks an upload without embedding or analyzing it,
+// returning the chunks with their indices and sizes for tuning chunking parameters.
+// chunk_size, chunk_overlap and strategy override the analyzer's configuration. The caller
+// must have access to the upload.
+func (h *AnalysisHandler) HandleChunkPreview(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	uploadID, err := strconv.Atoi(query.Get("id"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Valid upload ID is required"})
+		return
+	}
+
+	options := analyzer.ChunkPreviewOptions{Strategy: query.Get("strategy")}
+	if sizeStr := query.Get("chunk_size"); sizeStr != "" {
+		if options.ChunkSize, err = strconv.Atoi(sizeStr); err != nil || options.ChunkSize <= 0 {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid chunk_size"})
+			return
+		}
+	}
+	if overlapStr := query.Get("chunk_overlap"); overlapStr != "" {
+		overlap, err := strconv.Atoi(overlapStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid chunk_overlap"})
+			return
+		}
+		options.ChunkOverlap = &overlap
+	}
+
+	var userID *int
+	if uid, ok := callerID(h.auth, r); ok {
+		userID = &uid
+	} else if !anonymousMode(h.auth) {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Minute)
+	defer cancel()
+
+	if !h.authorizeUploads(ctx, w, r, []int{uploadID}) {
+		return
+	}
+
+	preview, err := h.analyzer.PreviewChunks(ctx, uploadID, userID, options)
+	if err != nil {
+		log.Printf("Error previewing chunks of upload %d: %v", uploadID, err)
+
+		switch {
+		case errors.Is(err, analyzer.ErrInvalidChunkOptions):
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid chunking options", "message": err.Error()})
+		case errors.Is(err, analyzer.ErrUploadNotOwned):
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "You do not have access to this upload"})
+		case errors.Is(err, analyzer.ErrUploadNotFound):
+			respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		default:
+			respondJSON(w, http.StatusUnprocessableEntity, map[string]string{
+				"error":   "Failed to chunk upload",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preview)
+}
+
+// HandleReanalyzeAll starts a fresh analysis of every upload of the authenticated user,
+// e.g. after the analysis prompt or model changes. Uploads with a job still in progress
+// are skipped. Each new job counts against the caller's plan rate limit; uploads left
+// when the limit is reached or the analysis queue is full are reported as deferred.
+func (h *AnalysisHandler) HandleReanalyzeAll(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		uid, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+			return
+		}
+		if uid != userID {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "Cannot start analysis for another user"})
+			return
+		}
+	}
+
+	// Every started job consumes one request from the caller's plan
+	var allow func() bool
+	rateLimited := false
+	if h.tiers != nil {
+		plan, key := h.tiers.Resolve(r)
+		allow = func() bool {
+			if plan.Allow(key) {
+				return true
+			}
+			rateLimited = true
+			return false
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.analyzer.ReanalyzeAllAsync(ctx, userID, allow)
+	if err != nil {
+		log.Printf("Error re-analyzing uploads for user %d: %v", userID, err)
+		response := map[string]interface{}{"error": "Failed to re-analyze uploads"}
+		if result != nil && len(result.JobIDs) > 0 {
+			// Jobs started before the failure keep running
+			response["job_ids"] = result.JobIDs
+			response["upload_ids"] = result.UploadIDs
+		}
+		respondJSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	if len(result.JobIDs) == 0 && len(result.DeferredUploadIDs) > 0 {
+		if rateLimited {
+			respondJSON(w, http.StatusTooManyRequests, map[string]string{
+				"error":   "Rate limit exceeded",
+				"message": "Your plan's request limit was reached before any upload could be re-analyzed",
+			})
+		} else {
+			respondQueueFull(w)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.JobIDs) > 0 {
+		status = http.StatusAccepted
+	}
+	respondJSON(w, status, result)
+
+	log.Printf("Re-analysis of user %d: %d jobs started, %d uploads skipped, %d deferred",
+		userID, len(result.JobIDs), len(result.SkippedUploadIDs), len(result.DeferredUploadIDs))
+}
+
+// HandleBatchDeleteJobs deletes multiple analysis jobs in a single operation
+func (h *AnalysisHandler) HandleBatchDeleteJobs(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req struct {
+		JobIDs []string `json:"job_ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid request body",
+			"message": "Request body must be valid JSON with job_ids array",
 		})
 		return
 	}
@@ -407,6 +1284,18 @@ func (h *AnalysisHandler) HandleBatchDeleteJobs(w http.ResponseWriter, r *http.R
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
+	// Every existing job must be accessible to the caller; nothing is deleted otherwise.
+	// Missing jobs are left for the batch delete to report.
+	for _, jobID := range req.JobIDs {
+		status, err := h.analyzer.GetStatus(ctx, jobID)
+		if err != nil {
+			continue
+		}
+		if !authorizeJob(w, r, h.auth, status) {
+			return
+		}
+	}
+
 	// Call batch delete
 	result, err := h.analyzer.BatchDeleteJobs(ctx, req.JobIDs)
 	if err != nil {
@@ -448,27 +1337,13 @@ func (h *AnalysisHandler) HandleExportAnalysis(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Get format from query parameter (default to JSON)
-	formatStr := strings.ToLower(r.URL.Query().Get("format"))
-	if formatStr == "" {
-		formatStr = "json"
-	}
-
-	// Validate format
-	var format exporter.Format
-	switch formatStr {
-	case "json":
-		format = exporter.FormatJSON
-	case "csv":
-		format = exporter.FormatCSV
-	case "pdf":
-		format = exporter.FormatPDF
-	case "docx":
-		format = exporter.FormatDOCX
-	default:
+	// Get format from query parameter (default to the caller's preference, then JSON)
+	formatParam := r.URL.Query().Get("format")
+	format, ok := parseExportFormat(formatParam)
+	if !ok {
 		respondJSON(w, http.StatusBadRequest, map[string]string{
 			"error":   "Invalid format",
-			"message": "Supported formats: json, csv, pdf, docx",
+			"message": "Supported formats: " + exportFormatNames,
 		})
 		return
 	}
@@ -476,11 +1351,19 @@ func (h *AnalysisHandler) HandleExportAnalysis(w http.ResponseWriter, r *http.Re
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	if strings.TrimSpace(formatParam) == "" {
+		format = callerExportFormat(ctx, h.auth, r)
+	}
+
 	// Get the analysis result
 	result, err := h.analyzer.GetResult(ctx, jobID)
 	if err != nil {
 		log.Printf("Error getting analysis result for export: %v", err)
-		if err.Error() == "job is not completed yet" {
+		if errors.Is(err, analyzer.ErrJobNotCompleted) {
 			respondJSON(w, http.StatusBadRequest, map[string]string{
 				"error":   "Analysis not yet completed",
 				"message": "Only completed analysis jobs can be exported",
@@ -492,7 +1375,7 @@ func (h *AnalysisHandler) HandleExportAnalysis(w http.ResponseWriter, r *http.Re
 	}
 
 	// Export to the requested format
-	data, err := h.exporter.Export(ctx, result, format)
+	data, err := h.exporter.Export(ctx, result.ToProfile(), format)
 	if err != nil {
 		log.Printf("Error exporting analysis result: %v", err)
 		respondJSON(w, http.StatusInternalServerError, map[string]string{
@@ -518,3 +1401,392 @@ func (h *AnalysisHandler) HandleExportAnalysis(w http.ResponseWriter, r *http.Re
 
 	log.Printf("Exported analysis job %s as %s (%d bytes)", jobID, format, len(data))
 }
+
+// HandleDownloadExtractedText returns the text extracted from a job's resume as a plain-text attachment
+func (h *AnalysisHandler) HandleDownloadExtractedText(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get job ID from query parameter
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := h.analyzer.GetStatus(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting analysis status for extracted text: %v", err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		return
+	}
+
+	if !authorizeJob(w, r, h.auth, status) {
+		return
+	}
+
+	if status.ExtractedText == nil || *status.ExtractedText == "" {
+		respondJSON(w, http.StatusNotFound, map[string]string{
+			"error":   "Extracted text not available",
+			"message": fmt.Sprintf("Text has not been extracted yet (job status: %s)", status.Status),
+		})
+		return
+	}
+
+	data := []byte(*status.ExtractedText)
+	fileName := fmt.Sprintf("resume_text_%s.txt", jobID)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error writing extracted text: %v", err)
+	}
+}
+
+// inlineExportFormats lists the formats that may be embedded in a bundle response.
+// DOCX is excluded because the base64 payload gets too large for a JSON envelope.
+var inlineExportFormats = map[exporter.Format]bool{
+	exporter.FormatJSON:    true,
+	exporter.FormatCSV:     true,
+	exporter.FormatCSVFlat: true,
+	exporter.FormatPDF:     true,
+}
+
+// ExportBundle is the embedded export part of a bundle response
+type ExportBundle struct {
+	Format      exporter.Format `json:"format"`
+	ContentType string          `json:"content_type"`
+	FileName    string          `json:"file_name"`
+	Encoding    string          `json:"encoding"` // always "base64"
+	Data        string          `json:"data"`
+	Size        int             `json:"size"` // Size of the decoded data in bytes
+}
+
+// HandleExportBundle returns the structured analysis result together with a base64-encoded export
+func (h *AnalysisHandler) HandleExportBundle(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get job ID from query parameter
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Job ID is required"})
+		return
+	}
+
+	// Get format from query parameter (default to PDF) and check it can be inlined
+	formatStr := r.URL.Query().Get("format")
+	if formatStr == "" {
+		formatStr = string(exporter.FormatPDF)
+	}
+
+	format, ok := parseExportFormat(formatStr)
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid format",
+			"message": "Supported formats: " + exportFormatNames,
+		})
+		return
+	}
+
+	if !inlineExportFormats[format] {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Format not allowed inline",
+			"message": "Supported inline formats: json, csv, csv_flat, pdf. Use /api/analysis/export for docx",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if !h.authorizeJobID(ctx, w, r, jobID) {
+		return
+	}
+
+	// Get the analysis result
+	result, err := h.analyzer.GetResult(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting analysis result for export bundle: %v", err)
+		if errors.Is(err, analyzer.ErrJobNotCompleted) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error":   "Analysis not yet completed",
+				"message": "Only completed analysis jobs can be exported",
+			})
+			return
+		}
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Result not found"})
+		return
+	}
+
+	// Export to the requested format
+	data, err := h.exporter.Export(ctx, result.ToProfile(), format)
+	if err != nil {
+		log.Printf("Error exporting analysis result for bundle: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error":   "Export failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_id": jobID,
+		"result": result,
+		"export": ExportBundle{
+			Format:      format,
+			ContentType: h.exporter.GetContentType(format),
+			FileName:    fmt.Sprintf("resume_analysis_%s%s", jobID, h.exporter.GetFileExtension(format)),
+			Encoding:    "base64",
+			Data:        base64.StdEncoding.EncodeToString(data),
+			Size:        len(data),
+		},
+	})
+
+	log.Printf("Exported analysis bundle for job %s as %s (%d bytes)", jobID, format, len(data))
+}
+
+// exportFormatNames lists the formats accepted by parseExportFormat, for error messages
+var exportFormatNames = strings.Join(formatNames(exporter.AnalysisFormats), ", ")
+
+// formatNames returns the names of export formats
+func formatNames(formats []exporter.Format) []string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return names
+}
+
+// ExportFormatResolver is implemented by authenticators that store a default export
+// format per user
+type ExportFormatResolver interface {
+	DefaultExportFormatFromRequest(ctx context.Context, r *http.Request) (string, error)
+}
+
+// callerExportFormat returns the caller's default export format, or JSON when the caller
+// has none, it can't be resolved, or the stored value is not a supported format
+func callerExportFormat(ctx context.Context, auth Authenticator, r *http.Request) exporter.Format {
+	resolver, ok := auth.(ExportFormatResolver)
+	if !ok {
+		return exporter.FormatJSON
+	}
+
+	stored, err := resolver.DefaultExportFormatFromRequest(ctx, r)
+	if err != nil {
+		log.Printf("Error resolving default export format, using JSON: %v", err)
+		return exporter.FormatJSON
+	}
+
+	format, ok := parseExportFormat(stored)
+	if !ok {
+		log.Printf("Ignoring unsupported default export format %q, using JSON", stored)
+		return exporter.FormatJSON
+	}
+	return format
+}
+
+// parseExportFormat converts a format query parameter into one of
+// exporter.AnalysisFormats (default: JSON)
+func parseExportFormat(formatStr string) (exporter.Format, bool) {
+	name := strings.ToLower(strings.TrimSpace(formatStr))
+	if name == "" {
+		return exporter.FormatJSON, true
+	}
+	for _, format := range exporter.AnalysisFormats {
+		if string(format) == name {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// ProfileComparison is the response for HandleCompareProfiles
+type ProfileComparison struct {
+	JobIDA          string   `json:"job_id_a"`
+	JobIDB          string   `json:"job_id_b"`
+	NameA           *string  `json:"name_a,omitempty"`
+	NameB           *string  `json:"name_b,omitempty"`
+	CommonSkills    []string `json:"common_skills"`
+	UniqueSkillsA   []string `json:"unique_skills_a"`
+	UniqueSkillsB   []string `json:"unique_skills_b"`
+	TotalWorkYearsA *float64 `json:"total_work_years_a,omitempty"`
+	TotalWorkYearsB *float64 `json:"total_work_years_b,omitempty"`
+	WorkYearsDiff   *float64 `json:"work_years_difference,omitempty"` // A minus B
+	Summary         *string  `json:"summary,omitempty"`
+	SummaryError    string   `json:"summary_error,omitempty"`
+}
+
+// HandleCompareProfiles compares two analyzed profiles side by side
+// Query parameters: job_id_a, job_id_b, summary=true to request an LLM comparative summary
+func (h *AnalysisHandler) HandleCompareProfiles(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobIDA := r.URL.Query().Get("job_id_a")
+	jobIDB := r.URL.Query().Get("job_id_b")
+	if jobIDA == "" || jobIDB == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Both job_id_a and job_id_b are required"})
+		return
+	}
+
+	withSummary := r.URL.Query().Get("summary") == "true"
+
+	timeout := 5 * time.Second
+	if withSummary {
+		timeout = 60 * time.Second // LLM summary generation
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// Load both results, reporting which one is unavailable
+	results := make([]*models.AnalysisResult, 2)
+	for i, jobID := range []string{jobIDA, jobIDB} {
+		if !h.authorizeJobID(ctx, w, r, jobID) {
+			return
+		}
+
+		result, err := h.analyzer.GetResult(ctx, jobID)
+		if err != nil {
+			log.Printf("Error getting analysis result %s for comparison: %v", jobID, err)
+			if errors.Is(err, analyzer.ErrJobNotCompleted) {
+				respondJSON(w, http.StatusConflict, map[string]string{
+					"error":   "Analysis not yet completed",
+					"message": fmt.Sprintf("Job %s has not completed yet", jobID),
+					"job_id":  jobID,
+				})
+				return
+			}
+			respondJSON(w, http.StatusNotFound, map[string]string{
+				"error":   "Profile not found",
+				"message": fmt.Sprintf("No analysis result for job %s", jobID),
+				"job_id":  jobID,
+			})
+			return
+		}
+		results[i] = result
+	}
+	a, b := results[0], results[1]
+
+	comparison := ProfileComparison{
+		JobIDA:          jobIDA,
+		JobIDB:          jobIDB,
+		NameA:           a.Name,
+		NameB:           b.Name,
+		TotalWorkYearsA: a.TotalWorkYears,
+		TotalWorkYearsB: b.TotalWorkYears,
+	}
+	comparison.CommonSkills, comparison.UniqueSkillsA, comparison.UniqueSkillsB = compareSkills(a.Skills, b.Skills)
+
+	if a.TotalWorkYears != nil && b.TotalWorkYears != nil {
+		diff := *a.TotalWorkYears - *b.TotalWorkYears
+		comparison.WorkYearsDiff = &diff
+	}
+
+	if withSummary {
+		if h.llmClient == nil {
+			comparison.SummaryError = "summary generation is not configured"
+		} else if summary, err := h.llmClient.GenerateFromPrompt(ctx, buildComparisonPrompt(a, b, &comparison)); err != nil {
+			log.Printf("Error generating comparison summary: %v", err)
+			comparison.SummaryError = "failed to generate summary"
+		} else {
+			summary = strings.TrimSpace(summary)
+			comparison.Summary = &summary
+		}
+	}
+
+	respondJSON(w, http.StatusOK, comparison)
+}
+
+// compareSkills flattens both skill maps and returns the skills they share and the skills
+// unique to each. Matching is case-insensitive; results are sorted and use the first spelling seen.
+func compareSkills(a, b map[string][]string) (common, onlyA, onlyB []string) {
+	setA := flattenSkills(a)
+	setB := flattenSkills(b)
+
+	common, onlyA, onlyB = []string{}, []string{}, []string{}
+	for key, name := range setA {
+		if _, ok := setB[key]; ok {
+			common = append(common, name)
+		} else {
+			onlyA = append(onlyA, name)
+		}
+	}
+	for key, name := range setB {
+		if _, ok := setA[key]; !ok {
+			onlyB = append(onlyB, name)
+		}
+	}
+
+	sort.Strings(common)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return common, onlyA, onlyB
+}
+
+// flattenSkills merges all skill categories into a lowercase-keyed set
+func flattenSkills(skills map[string][]string) map[string]string {
+	set := make(map[string]string)
+
+	// Iterate categories in a fixed order so the kept spelling is deterministic
+	for _, category := range models.SkillCategories(skills) {
+		for _, skill := range skills[category] {
+			skill = strings.TrimSpace(skill)
+			key := strings.ToLower(skill)
+			if key == "" {
+				continue
+			}
+			if _, exists := set[key]; !exists {
+				set[key] = skill
+			}
+		}
+	}
+	return set
+}
+
+// buildComparisonPrompt builds the LLM prompt for a comparative summary of two profiles
+func buildComparisonPrompt(a, b *models.AnalysisResult, comparison *ProfileComparison) string {
+	describe := func(label string, r *models.AnalysisResult, years *float64, unique []string) string {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("Candidate %s", label))
+		if r.Name != nil {
+			sb.WriteString(fmt.Sprintf(" (%s)", *r.Name))
+		}
+		sb.WriteString(":\n")
+		if years != nil {
+			sb.WriteString(fmt.Sprintf("- Total work experience: %.1f years\n", *years))
+		}
+		if r.Summary != nil {
+			sb.WriteString(fmt.Sprintf("- Summary: %s\n", *r.Summary))
+		}
+		if len(unique) > 0 {
+			sb.WriteString(fmt.Sprintf("- Skills only this candidate has: %s\n", strings.Join(unique, ", ")))
+		}
+		return sb.String()
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are assisting a recruiter. Compare the following two candidates objectively in one or two short paragraphs, ")
+	prompt.WriteString("highlighting relative strengths, experience level and skill fit. Do not invent facts.\n\n")
+	prompt.WriteString(describe("A", a, comparison.TotalWorkYearsA, comparison.UniqueSkillsA))
+	prompt.WriteString("\n")
+	prompt.WriteString(describe("B", b, comparison.TotalWorkYearsB, comparison.UniqueSkillsB))
+	if len(comparison.CommonSkills) > 0 {
+		prompt.WriteString(fmt.Sprintf("\nShared skills: %s\n", strings.Join(comparison.CommonSkills, ", ")))
+	}
+	return prompt.String()
+}
@@ -0,0 +1,1133 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/tier"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestHandleExportBundle(t *testing.T) {
+	fake := &fakeAnalyzer{}
+	fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{UploadID: 3, Name: strPtr("Ada")})
+	fake.statuses["job_running"] = &models.AnalysisStatus{JobID: "job_running", Status: "analyzing"}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, anonymousHeaderAuth{}, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		userID     int // 0 = anonymous
+		wantStatus int
+		wantError  string
+		wantFormat string // Format of a successful bundle
+	}{
+		{"default format", "job_id=job_1", 7, http.StatusOK, "", "pdf"},
+		{"csv", "job_id=job_1&format=csv", 7, http.StatusOK, "", "csv"},
+		{"format ignores case", "job_id=job_1&format=JSON", 7, http.StatusOK, "", "json"},
+		{"docx not inline", "job_id=job_1&format=docx", 7, http.StatusBadRequest, "Format not allowed inline", ""},
+		{"unknown format", "job_id=job_1&format=xml", 7, http.StatusBadRequest, "Invalid format", ""},
+		{"missing job ID", "format=csv", 7, http.StatusBadRequest, "Job ID is required", ""},
+		{"unknown job", "job_id=job_missing", 7, http.StatusNotFound, "Job not found", ""},
+		{"other user's job", "job_id=job_1", 8, http.StatusForbidden, "You do not have access to this job", ""},
+		{"anonymous caller", "job_id=job_1", 0, http.StatusUnauthorized, "Authentication required", ""},
+		{"not completed", "job_id=job_running", 0, http.StatusBadRequest, "Analysis not yet completed", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/analysis/export/bundle?"+tt.query, nil)
+			if tt.userID != 0 {
+				asUser(r, tt.userID)
+			}
+			w := serve(h.HandleExportBundle, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantError != "" {
+				var body map[string]string
+				decodeBody(t, w, &body)
+				if body["error"] != tt.wantError {
+					t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+				}
+				return
+			}
+
+			var body struct {
+				JobID  string                `json:"job_id"`
+				Result models.AnalysisResult `json:"result"`
+				Export ExportBundle          `json:"export"`
+			}
+			decodeBody(t, w, &body)
+
+			if body.JobID != "job_1" || body.Result.JobID != "job_1" || body.Result.Name == nil || *body.Result.Name != "Ada" {
+				t.Errorf("result = %+v, want the profile of job_1", body.Result)
+			}
+			if string(body.Export.Format) != tt.wantFormat {
+				t.Errorf("format = %q, want %q", body.Export.Format, tt.wantFormat)
+			}
+			if body.Export.Encoding != "base64" {
+				t.Errorf("encoding = %q, want base64", body.Export.Encoding)
+			}
+			data, err := base64.StdEncoding.DecodeString(body.Export.Data)
+			if err != nil {
+				t.Fatalf("decoding export data: %v", err)
+			}
+			if want := tt.wantFormat + ":job_1"; string(data) != want || body.Export.Size != len(want) {
+				t.Errorf("export = %q (size %d), want %q", data, body.Export.Size, want)
+			}
+			if body.Export.ContentType != "application/x-"+tt.wantFormat || body.Export.FileName != "resume_analysis_job_1."+tt.wantFormat {
+				t.Errorf("content type %q, file name %q", body.Export.ContentType, body.Export.FileName)
+			}
+		})
+	}
+}
+
+func TestCompareSkills(t *testing.T) {
+	tests := []struct {
+		name                     string
+		a, b                     map[string][]string
+		wantCommon, wantA, wantB []string
+	}{
+		{
+			name:       "overlap across categories",
+			a:          map[string][]string{"technical": {"Go", "SQL"}, "soft": {"Leadership"}},
+			b:          map[string][]string{"technical": {"Python"}, "tools": {"sql", "Go"}},
+			wantCommon: []string{"Go", "SQL"},
+			wantA:      []string{"Leadership"},
+			wantB:      []string{"Python"},
+		},
+		{
+			name:       "disjoint",
+			a:          map[string][]string{"technical": {"Rust"}},
+			b:          map[string][]string{"technical": {"Java"}},
+			wantCommon: []string{},
+			wantA:      []string{"Rust"},
+			wantB:      []string{"Java"},
+		},
+		{
+			name:       "duplicates and blanks ignored",
+			a:          map[string][]string{"technical": {"Go", " go ", ""}, "tools": {"GO"}},
+			b:          map[string][]string{"technical": {"Go"}},
+			wantCommon: []string{"Go"},
+			wantA:      []string{},
+			wantB:      []string{},
+		},
+		{
+			name:       "one side empty",
+			a:          nil,
+			b:          map[string][]string{"technical": {"Kafka", "Docker"}},
+			wantCommon: []string{},
+			wantA:      []string{},
+			wantB:      []string{"Docker", "Kafka"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			common, onlyA, onlyB := compareSkills(tt.a, tt.b)
+			if !reflect.DeepEqual(common, tt.wantCommon) || !reflect.DeepEqual(onlyA, tt.wantA) || !reflect.DeepEqual(onlyB, tt.wantB) {
+				t.Errorf("compareSkills = %q, %q, %q, want %q, %q, %q", common, onlyA, onlyB, tt.wantCommon, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestHandleCompareProfiles(t *testing.T) {
+	fake := &fakeAnalyzer{}
+	fake.completedJob("job_a", nil, &models.AnalysisResult{
+		Name:           strPtr("Ada"),
+		TotalWorkYears: floatPtr(8),
+		Skills:         map[string][]string{"technical": {"Go", "SQL"}},
+	})
+	fake.completedJob("job_b", nil, &models.AnalysisResult{
+		Name:           strPtr("Grace"),
+		TotalWorkYears: floatPtr(5.5),
+		Skills:         map[string][]string{"technical": {"COBOL", "sql"}},
+	})
+	fake.statuses["job_running"] = &models.AnalysisStatus{JobID: "job_running", Status: "analyzing"}
+
+	tests := []struct {
+		name        string
+		query       string
+		llm         *recordingLLM // nil = no LLM configured
+		wantStatus  int
+		wantSummary string
+		wantSumErr  string
+	}{
+		{"without summary", "job_id_a=job_a&job_id_b=job_b", &recordingLLM{response: "unused"}, http.StatusOK, "", ""},
+		{"with summary", "job_id_a=job_a&job_id_b=job_b&summary=true", &recordingLLM{response: "  Ada has more experience.\n"}, http.StatusOK, "Ada has more experience.", ""},
+		{"summary not configured", "job_id_a=job_a&job_id_b=job_b&summary=true", nil, http.StatusOK, "", "summary generation is not configured"},
+		{"summary fails", "job_id_a=job_a&job_id_b=job_b&summary=true", &recordingLLM{err: errors.New("LLM down")}, http.StatusOK, "", "failed to generate summary"},
+		{"missing job ID", "job_id_a=job_a", nil, http.StatusBadRequest, "", ""},
+		{"one profile missing", "job_id_a=job_a&job_id_b=job_missing", nil, http.StatusNotFound, "", ""},
+		{"one profile not completed", "job_id_a=job_running&job_id_b=job_b", nil, http.StatusConflict, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var llm analyzer.LLMClient
+			if tt.llm != nil {
+				llm = tt.llm
+			}
+			h := NewAnalysisHandler(fake, stubExporter{}, llm, anonymousHeaderAuth{}, nil)
+
+			w := serve(h.HandleCompareProfiles, httptest.NewRequest(http.MethodGet, "/api/analysis/compare?"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got ProfileComparison
+			decodeBody(t, w, &got)
+
+			if !reflect.DeepEqual(got.CommonSkills, []string{"SQL"}) || !reflect.DeepEqual(got.UniqueSkillsA, []string{"Go"}) || !reflect.DeepEqual(got.UniqueSkillsB, []string{"COBOL"}) {
+				t.Errorf("skills = %q, %q, %q", got.CommonSkills, got.UniqueSkillsA, got.UniqueSkillsB)
+			}
+			if got.WorkYearsDiff == nil || *got.WorkYearsDiff != 2.5 {
+				t.Errorf("work years difference = %v, want 2.5", got.WorkYearsDiff)
+			}
+			if got.NameA == nil || *got.NameA != "Ada" || got.NameB == nil || *got.NameB != "Grace" {
+				t.Errorf("names = %v, %v", got.NameA, got.NameB)
+			}
+
+			gotSummary := ""
+			if got.Summary != nil {
+				gotSummary = *got.Summary
+			}
+			if gotSummary != tt.wantSummary || got.SummaryError != tt.wantSumErr {
+				t.Errorf("summary = %q (error %q), want %q (error %q)", gotSummary, got.SummaryError, tt.wantSummary, tt.wantSumErr)
+			}
+
+			if tt.llm != nil && tt.wantSummary != "" {
+				if len(tt.llm.prompts) != 1 {
+					t.Fatalf("LLM called %d times, want 1", len(tt.llm.prompts))
+				}
+				for _, want := range []string{"Ada", "Grace", "Skills only this candidate has: Go", "Shared skills: SQL"} {
+					if !strings.Contains(tt.llm.prompts[0], want) {
+						t.Errorf("prompt lacks %q:\n%s", want, tt.llm.prompts[0])
+					}
+				}
+			}
+			if tt.llm != nil && tt.wantSummary == "" && tt.wantSumErr == "" && len(tt.llm.prompts) != 0 {
+				t.Errorf("LLM called without a summary requested")
+			}
+		})
+	}
+}
+
+func TestHandleDownloadExtractedText(t *testing.T) {
+	fake := &fakeAnalyzer{statuses: map[string]*models.AnalysisStatus{
+		"job_text":    {JobID: "job_text", Status: "completed", ExtractedText: strPtr("Ada Lovelace\nAnalyst")},
+		"job_pending": {JobID: "job_pending", Status: "queued"},
+		"job_empty":   {JobID: "job_empty", Status: "extracting_text", ExtractedText: strPtr("")},
+		"job_owned":   {JobID: "job_owned", UserID: intPtr(7), Status: "completed", ExtractedText: strPtr("Private")},
+	}}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, anonymousHeaderAuth{}, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		userID     int // 0 = anonymous
+		wantStatus int
+		wantBody   string // Text of a successful download
+	}{
+		{"extracted text", "job_id=job_text", 0, http.StatusOK, "Ada Lovelace\nAnalyst"},
+		{"not extracted yet", "job_id=job_pending", 0, http.StatusNotFound, ""},
+		{"empty text", "job_id=job_empty", 0, http.StatusNotFound, ""},
+		{"unknown job", "job_id=job_missing", 0, http.StatusNotFound, ""},
+		{"missing job ID", "", 0, http.StatusBadRequest, ""},
+		{"owner", "job_id=job_owned", 7, http.StatusOK, "Private"},
+		{"other user", "job_id=job_owned", 8, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/analysis/extracted-text?"+tt.query, nil)
+			if tt.userID != 0 {
+				asUser(r, tt.userID)
+			}
+			w := serve(h.HandleDownloadExtractedText, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+				t.Errorf("content type = %q", ct)
+			}
+			if cd := w.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") || !strings.Contains(cd, ".txt") {
+				t.Errorf("content disposition = %q, want a .txt attachment", cd)
+			}
+		})
+	}
+}
+
+func TestHandleUserAnalytics(t *testing.T) {
+	fake := &fakeAnalyzer{analytics: map[int]*models.UserAnalytics{
+		7: {
+			UserID:           7,
+			TotalUploads:     3,
+			TotalJobs:        3,
+			JobsByStatus:     map[string]int{"completed": 2, "failed": 1},
+			TotalProfiles:    2,
+			AverageWorkYears: floatPtr(6.5),
+			TopSkills:        []models.SkillCount{{Skill: "go", Count: 2}, {Skill: "sql", Count: 1}},
+		},
+		8: {UserID: 8, TotalUploads: 1, JobsByStatus: map[string]int{}, TopSkills: []models.SkillCount{}},
+	}}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+	tests := []struct {
+		name          string
+		query         string
+		userID        int // 0 = anonymous
+		wantStatus    int
+		wantUploads   int
+		wantTopSkills int // topSkills passed to the analyzer
+	}{
+		{"own analytics", "", 7, http.StatusOK, 3, 10},
+		{"own user ID", "user_id=7", 7, http.StatusOK, 3, 10},
+		{"other user", "", 8, http.StatusOK, 1, 10},
+		{"top skills", "top_skills=3", 7, http.StatusOK, 3, 3},
+		{"top skills capped", "top_skills=500", 7, http.StatusOK, 3, 50},
+		{"invalid top skills uses default", "top_skills=-1", 7, http.StatusOK, 3, 10},
+		{"another user's ID", "user_id=8", 7, http.StatusForbidden, 0, 0},
+		{"invalid user ID", "user_id=abc", 7, http.StatusBadRequest, 0, 0},
+		{"anonymous", "", 0, http.StatusUnauthorized, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.analyticsTopSkills = 0
+			r := httptest.NewRequest(http.MethodGet, "/api/analysis/analytics?"+tt.query, nil)
+			if tt.userID != 0 {
+				asUser(r, tt.userID)
+			}
+			w := serve(h.HandleUserAnalytics, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if fake.analyticsTopSkills != 0 {
+					t.Errorf("analytics loaded for a rejected request")
+				}
+				return
+			}
+
+			var got models.UserAnalytics
+			decodeBody(t, w, &got)
+			if got.UserID != tt.userID || got.TotalUploads != tt.wantUploads {
+				t.Errorf("analytics of user %d with %d uploads, want user %d with %d", got.UserID, got.TotalUploads, tt.userID, tt.wantUploads)
+			}
+			if fake.analyticsTopSkills != tt.wantTopSkills {
+				t.Errorf("top skills = %d, want %d", fake.analyticsTopSkills, tt.wantTopSkills)
+			}
+		})
+	}
+
+	// Aggregates are passed through as computed
+	r := asUser(httptest.NewRequest(http.MethodGet, "/api/analysis/analytics", nil), 7)
+	var got models.UserAnalytics
+	decodeBody(t, serve(h.HandleUserAnalytics, r), &got)
+	if !reflect.DeepEqual(&got, fake.analytics[7]) {
+		t.Errorf("analytics = %+v, want %+v", got, fake.analytics[7])
+	}
+}
+
+func TestJobOwnership(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymous  bool // Anonymous mode
+		owner      *int
+		caller     int // 0 for no session
+		wantStatus int
+	}{
+		{"owner", false, intPtr(7), 7, http.StatusOK},
+		{"non-owner", false, intPtr(7), 8, http.StatusForbidden},
+		{"non-owner in anonymous mode", true, intPtr(7), 8, http.StatusForbidden},
+		{"no session", false, intPtr(7), 0, http.StatusUnauthorized},
+		{"anonymous job in anonymous mode", true, nil, 8, http.StatusOK},
+		{"anonymous job without a session in anonymous mode", true, nil, 0, http.StatusOK},
+		{"anonymous job", false, nil, 8, http.StatusForbidden},
+		{"anonymous job without a session", false, nil, 0, http.StatusUnauthorized},
+	}
+
+	handlers := []struct {
+		name   string
+		method string
+		serve  func(h *AnalysisHandler) http.HandlerFunc
+	}{
+		{"status", http.MethodGet, func(h *AnalysisHandler) http.HandlerFunc { return h.HandleAnalysisStatus }},
+		{"result", http.MethodGet, func(h *AnalysisHandler) http.HandlerFunc { return h.HandleAnalysisResult }},
+		{"delete", http.MethodDelete, func(h *AnalysisHandler) http.HandlerFunc { return h.HandleDeleteJob }},
+		{"upload jobs", http.MethodGet, func(h *AnalysisHandler) http.HandlerFunc { return h.HandleGetUploadJobs }},
+	}
+
+	for _, handler := range handlers {
+		for _, tt := range tests {
+			t.Run(handler.name+" "+tt.name, func(t *testing.T) {
+				fake := &fakeAnalyzer{}
+				fake.completedJob("job_1", tt.owner, &models.AnalysisResult{Name: strPtr("Ada")})
+				fake.upload(1, tt.owner)
+				var auth Authenticator = headerAuth{}
+				if tt.anonymous {
+					auth = anonymousHeaderAuth{}
+				}
+				h := NewAnalysisHandler(fake, stubExporter{}, nil, auth, nil)
+
+				r := httptest.NewRequest(handler.method, "/api/analysis/job?job_id=job_1&upload_id=1", nil)
+				if tt.caller != 0 {
+					asUser(r, tt.caller)
+				}
+				w := serve(handler.serve(h), r)
+				if w.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+				}
+
+				_, kept := fake.statuses["job_1"]
+				if wantDeleted := handler.method == http.MethodDelete && tt.wantStatus == http.StatusOK; kept == wantDeleted {
+					t.Errorf("job kept = %t after %s", kept, handler.name)
+				}
+			})
+		}
+	}
+
+	userJobs := []struct {
+		name       string
+		anonymous  bool // Anonymous mode
+		query      string
+		caller     int // 0 for no session
+		wantStatus int
+		wantJobs   int
+	}{
+		{"user jobs of the owner", false, "?user_id=7", 7, http.StatusOK, 1},
+		{"user jobs of the caller", false, "", 7, http.StatusOK, 1},
+		{"user jobs of another user", false, "?user_id=7", 8, http.StatusForbidden, 0},
+		{"user jobs of another user in anonymous mode", true, "?user_id=7", 8, http.StatusForbidden, 0},
+		{"user jobs without a session", false, "?user_id=7", 0, http.StatusUnauthorized, 0},
+		{"user jobs without a session in anonymous mode", true, "?user_id=7", 0, http.StatusUnauthorized, 0},
+		{"user jobs with an invalid user ID", false, "?user_id=x", 7, http.StatusBadRequest, 0},
+	}
+	for _, tt := range userJobs {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{}
+			fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{Name: strPtr("Ada")})
+			fake.completedJob("job_2", intPtr(8), &models.AnalysisResult{Name: strPtr("Grace")})
+			fake.completedJob("job_3", nil, &models.AnalysisResult{Name: strPtr("Linus")})
+			var auth Authenticator = headerAuth{}
+			if tt.anonymous {
+				auth = anonymousHeaderAuth{}
+			}
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, auth, nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/analysis/jobs/user"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleGetUserJobs, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				UserID int                   `json:"user_id"`
+				Jobs   []*models.AnalysisJob `json:"jobs"`
+			}
+			decodeBody(t, w, &resp)
+			if resp.UserID != tt.caller || len(resp.Jobs) != tt.wantJobs {
+				t.Fatalf("user_id = %d with %d jobs, want %d with %d", resp.UserID, len(resp.Jobs), tt.caller, tt.wantJobs)
+			}
+			if resp.Jobs[0].JobID != "job_1" {
+				t.Errorf("jobs[0] = %s, want job_1", resp.Jobs[0].JobID)
+			}
+		})
+	}
+}
+
+func TestHandleAnalyzeResumeAnonymousMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       Authenticator
+		query      string
+		caller     int // 0 for no session
+		wantStatus int
+	}{
+		{"signed in", headerAuth{}, "?id=1", 7, http.StatusAccepted},
+		{"no session", headerAuth{}, "?id=1", 0, http.StatusUnauthorized},
+		{"another user's upload", headerAuth{}, "?id=2", 7, http.StatusForbidden},
+		{"missing upload", headerAuth{}, "?id=9", 7, http.StatusNotFound},
+		{"anonymous upload", headerAuth{}, "?id=3", 7, http.StatusForbidden},
+		{"anonymous upload in anonymous mode", anonymousHeaderAuth{}, "?id=3", 7, http.StatusAccepted},
+		{"no session in anonymous mode", anonymousHeaderAuth{}, "?id=3", 0, http.StatusAccepted},
+		{"merged with another user's upload", headerAuth{}, "?id=1,2", 7, http.StatusForbidden},
+		{"merged with an anonymous upload", headerAuth{}, "?id=1,3", 7, http.StatusForbidden},
+		{"synchronous with an anonymous upload", headerAuth{}, "?id=3&sync=true", 7, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{}
+			fake.upload(1, intPtr(7))
+			fake.upload(2, intPtr(8))
+			fake.upload(3, nil)
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, tt.auth, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/analysis/start"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			if w := serve(h.HandleAnalyzeResume, r); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleChunkPreviewOwnership(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       Authenticator
+		uploadID   int
+		caller     int // 0 for no session
+		wantStatus int
+	}{
+		{"owner", headerAuth{}, 1, 7, http.StatusOK},
+		{"non-owner", headerAuth{}, 1, 8, http.StatusForbidden},
+		{"no session", headerAuth{}, 1, 0, http.StatusUnauthorized},
+		{"missing upload without a session", headerAuth{}, 9, 0, http.StatusUnauthorized},
+		{"missing upload", headerAuth{}, 9, 7, http.StatusNotFound},
+		{"anonymous upload", headerAuth{}, 2, 7, http.StatusForbidden},
+		{"anonymous upload without a session", headerAuth{}, 2, 0, http.StatusUnauthorized},
+		{"anonymous upload in anonymous mode", anonymousHeaderAuth{}, 2, 7, http.StatusOK},
+		{"anonymous upload without a session in anonymous mode", anonymousHeaderAuth{}, 2, 0, http.StatusOK},
+		{"non-owner in anonymous mode", anonymousHeaderAuth{}, 1, 8, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{}
+			fake.upload(1, intPtr(7))
+			fake.upload(2, nil)
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, tt.auth, nil)
+
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/analysis/chunks?id=%d", tt.uploadID), nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			if w := serve(h.HandleChunkPreview, r); w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if previewed := fake.previews > 0; previewed != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("previewed = %t with status %d", previewed, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleAnalyzeResumeSync(t *testing.T) {
+	fake := &fakeAnalyzer{}
+	fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{JobID: "job_1", UploadID: 1, Name: strPtr("Ada")})
+	fake.statuses["job_2"] = &models.AnalysisStatus{JobID: "job_2", Status: "failed"}
+	for id := 1; id <= 3; id++ {
+		fake.upload(id, intPtr(7))
+	}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantField  string // A field of the JSON response
+		wantValue  any
+	}{
+		{"finished job returns the result", "?id=1&sync=true", http.StatusOK, "name", "Ada"},
+		{"failed job returns its status", "?id=2&sync=true", http.StatusUnprocessableEntity, "status", "failed"},
+		{"unfinished job returns the job ID", "?id=3&sync=true", http.StatusAccepted, "job_id", "job_3"},
+		{"asynchronous by default", "?id=1", http.StatusAccepted, "job_id", "job_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleAnalyzeResume, asUser(httptest.NewRequest(http.MethodPost, "/api/analysis/start"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp map[string]any
+			decodeBody(t, w, &resp)
+			if resp[tt.wantField] != tt.wantValue {
+				t.Errorf("%s = %v, want %v", tt.wantField, resp[tt.wantField], tt.wantValue)
+			}
+			explained := strings.Contains(fmt.Sprint(resp["message"]), "synchronously")
+			if explained != (tt.wantStatus == http.StatusAccepted && strings.Contains(tt.query, "sync")) {
+				t.Errorf("message = %v, want it to explain only a synchronous request answered asynchronously", resp["message"])
+			}
+		})
+	}
+}
+
+func TestHandleReanalyzeAll(t *testing.T) {
+	users := &fakeUsers{users: map[int]*models.User{7: {ID: 7, Tier: tier.Free}, 8: {ID: 8, Tier: tier.Paid}}}
+	// newResolver returns a resolver with fresh rate limits: 2 jobs a minute for free users
+	newResolver := func() tier.Resolver {
+		defaultPlan := tier.NewPlan(tier.Plan{}, nil)
+		plans := map[string]*tier.Plan{
+			tier.Free: tier.NewPlan(tier.Plan{Tier: tier.Free, RequestsPerMinute: 2}, defaultPlan),
+			tier.Paid: tier.NewPlan(tier.Plan{Tier: tier.Paid}, defaultPlan),
+		}
+		return tier.NewUserResolver(headerAuth{}, users, defaultPlan, plans)
+	}
+
+	tests := []struct {
+		name         string
+		caller       int // 0 for no session
+		query        string
+		startErr     error
+		wantStatus   int
+		wantJobs     []string
+		wantDeferred []int
+	}{
+		{"one job per upload", 8, "", nil, http.StatusAccepted, []string{"job_1", "job_2", "job_3"}, []int{}},
+		{"rate limit defers the rest", 7, "", nil, http.StatusAccepted, []string{"job_4", "job_5"}, []int{6}},
+		{"own user ID", 8, "?user_id=8", nil, http.StatusAccepted, []string{"job_1", "job_2", "job_3"}, []int{}},
+		{"queue full", 8, "", analyzer.ErrQueueFull, http.StatusServiceUnavailable, nil, nil},
+		{"start fails", 8, "", errors.New("database down"), http.StatusInternalServerError, nil, nil},
+		{"another user", 8, "?user_id=7", nil, http.StatusForbidden, nil, nil},
+		{"invalid user ID", 8, "?user_id=abc", nil, http.StatusBadRequest, nil, nil},
+		{"no session", 0, "", nil, http.StatusUnauthorized, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fa := &fakeAnalyzer{
+				reanalyzable: map[int][]int{8: {1, 2, 3}, 7: {4, 5, 6}},
+				startErr:     tt.startErr,
+			}
+			h := NewAnalysisHandler(fa, stubExporter{}, nil, headerAuth{}, newResolver())
+
+			r := httptest.NewRequest(http.MethodPost, "/api/analysis/reanalyze-all"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleReanalyzeAll, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("queue full response has no Retry-After header")
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			if fa.reanalyzedUser != tt.caller {
+				t.Errorf("re-analyzed uploads of user %d, want %d", fa.reanalyzedUser, tt.caller)
+			}
+			var result analyzer.BulkReanalyzeResult
+			decodeBody(t, w, &result)
+			if !reflect.DeepEqual(result.JobIDs, tt.wantJobs) {
+				t.Errorf("job IDs = %v, want %v", result.JobIDs, tt.wantJobs)
+			}
+			if !reflect.DeepEqual(result.DeferredUploadIDs, tt.wantDeferred) {
+				t.Errorf("deferred uploads = %v, want %v", result.DeferredUploadIDs, tt.wantDeferred)
+			}
+		})
+	}
+
+	t.Run("rate limited before any job", func(t *testing.T) {
+		fa := &fakeAnalyzer{reanalyzable: map[int][]int{7: {4, 5, 6}}}
+		h := NewAnalysisHandler(fa, stubExporter{}, nil, headerAuth{}, newResolver())
+
+		reanalyze := func() *httptest.ResponseRecorder {
+			return serve(h.HandleReanalyzeAll, asUser(httptest.NewRequest(http.MethodPost, "/api/analysis/reanalyze-all", nil), 7))
+		}
+		if w := reanalyze(); w.Code != http.StatusAccepted {
+			t.Fatalf("first request status = %d, want 202", w.Code)
+		}
+		if w := reanalyze(); w.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want 429 once the plan's limit is used up", w.Code)
+		}
+	})
+}
+
+func TestHandleRegenerateRecommendations(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		err          error
+		wantStatus   int
+		wantIndustry string
+	}{
+		{"regenerated", "job_id=job_1&industry=+Fintech+", nil, http.StatusOK, "Fintech"},
+		{"malformed response", "job_id=job_1", fmt.Errorf("%w: no job titles", analyzer.ErrInvalidRecommendations), http.StatusBadGateway, ""},
+		{"job not completed", "job_id=job_1", fmt.Errorf("%w (status: analyzing)", analyzer.ErrJobNotCompleted), http.StatusConflict, ""},
+		{"LLM failure", "job_id=job_1", errors.New("LLM request failed: timeout"), http.StatusInternalServerError, ""},
+		{"missing job ID", "", nil, http.StatusBadRequest, ""},
+		{"unknown job", "job_id=job_2", nil, http.StatusNotFound, ""},
+		{"industry too long", "job_id=job_1&industry=" + strings.Repeat("x", maxIndustryLength+1), nil, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{recommendations: []string{"Payments SRE"}, recommendationsErr: tt.err}
+			fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{JobRecommendations: []string{"Backend Engineer"}})
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+			r := asUser(httptest.NewRequest(http.MethodPost, "/api/analysis/recommendations?"+tt.query, nil), 7)
+			w := serve(h.HandleRegenerateRecommendations, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var result models.AnalysisResult
+			decodeBody(t, w, &result)
+			if !reflect.DeepEqual(result.JobRecommendations, []string{"Payments SRE"}) {
+				t.Errorf("recommendations = %q, want the regenerated ones", result.JobRecommendations)
+			}
+			if fake.industry != tt.wantIndustry {
+				t.Errorf("industry = %q, want %q", fake.industry, tt.wantIndustry)
+			}
+		})
+	}
+
+	t.Run("other user's job", func(t *testing.T) {
+		fake := &fakeAnalyzer{}
+		fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{})
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+		w := serve(h.HandleRegenerateRecommendations, asUser(httptest.NewRequest(http.MethodPost, "/api/analysis/recommendations?job_id=job_1", nil), 8))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", w.Code)
+		}
+	})
+}
+
+func TestHandleSimilarProfiles(t *testing.T) {
+	similar := []*models.SimilarProfile{
+		{JobID: "job_2", UploadID: 2, Name: strPtr("Grace"), Score: 0.9},
+		{JobID: "job_3", UploadID: 3, Score: 0.7},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		userID     int
+		err        error
+		wantStatus int
+	}{
+		{"similar profiles", "job_id=job_1", 7, nil, http.StatusOK},
+		{"job not completed", "job_id=job_1", 7, fmt.Errorf("%w (status: analyzing)", analyzer.ErrJobNotCompleted), http.StatusConflict},
+		{"search failure", "job_id=job_1", 7, errors.New("vector search failed"), http.StatusInternalServerError},
+		{"missing job ID", "", 7, nil, http.StatusBadRequest},
+		{"unknown job", "job_id=job_9", 7, nil, http.StatusNotFound},
+		{"other user's job", "job_id=job_1", 8, nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{similar: map[string][]*models.SimilarProfile{"job_1": similar}, similarErr: tt.err}
+			fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{})
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+			w := serve(h.HandleSimilarProfiles, asUser(httptest.NewRequest(http.MethodGet, "/api/analysis/similar?"+tt.query, nil), tt.userID))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusInternalServerError && strings.Contains(w.Body.String(), "vector search") {
+				t.Errorf("response leaks the cause: %s", w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				JobID   string                   `json:"job_id"`
+				Count   int                      `json:"count"`
+				Results []*models.SimilarProfile `json:"results"`
+			}
+			decodeBody(t, w, &body)
+			if body.JobID != "job_1" || body.Count != 2 || !reflect.DeepEqual(body.Results, similar) {
+				t.Errorf("body = %+v, want job_1's 2 similar profiles", body)
+			}
+		})
+	}
+
+	t.Run("none cached yet", func(t *testing.T) {
+		fake := &fakeAnalyzer{}
+		fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{})
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+		w := serve(h.HandleSimilarProfiles, asUser(httptest.NewRequest(http.MethodGet, "/api/analysis/similar?job_id=job_1", nil), 7))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"results":[]`) {
+			t.Errorf("status = %d, body %s; want 200 with empty results", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestHandleSearchResumes(t *testing.T) {
+	raw := []*models.UserProfile{{UploadID: 1}, {UploadID: 2}, {UploadID: 3}}
+	reranked := &analyzer.RerankResult{
+		Profiles:       []*models.UserProfile{raw[2], raw[0], raw[1]},
+		Justifications: map[int]string{3: "Runs Kubernetes platforms.", 1: "Go only."},
+	}
+
+	type response struct {
+		Count          int                   `json:"count"`
+		Results        []*models.UserProfile `json:"results"`
+		Reranked       *bool                 `json:"reranked"`
+		Justifications map[int]string        `json:"justifications"`
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		rerankErr          error
+		wantOrder          []int
+		wantReranks        int
+		wantReranked       *bool // nil when the response has no reranked field
+		wantJustifications map[int]string
+	}{
+		{"vector order", "query=kubernetes", nil, []int{1, 2, 3}, 0, nil, nil},
+		{"rerank off", "query=kubernetes&rerank=false", nil, []int{1, 2, 3}, 0, nil, nil},
+		{"reranked", "query=kubernetes&rerank=true", nil, []int{3, 1, 2}, 1, boolPtr(true), reranked.Justifications},
+		{"rerank failure keeps the vector order", "query=kubernetes&rerank=1", analyzer.ErrInvalidRerank, []int{1, 2, 3}, 1, boolPtr(false), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAnalyzer{searchResults: raw, reranked: reranked, rerankErr: tt.rerankErr}
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, anonymousHeaderAuth{}, nil)
+
+			w := serve(h.HandleSearchResumes, httptest.NewRequest(http.MethodGet, "/api/search?"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var body response
+			decodeBody(t, w, &body)
+			var order []int
+			for _, profile := range body.Results {
+				order = append(order, profile.UploadID)
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) || body.Count != 3 {
+				t.Errorf("results = %v (count %d), want %v", order, body.Count, tt.wantOrder)
+			}
+			if fake.reranks != tt.wantReranks {
+				t.Errorf("re-ranked %d times, want %d", fake.reranks, tt.wantReranks)
+			}
+			if !reflect.DeepEqual(body.Reranked, tt.wantReranked) || !reflect.DeepEqual(body.Justifications, tt.wantJustifications) {
+				t.Errorf("body = %s, want reranked %v with justifications %v", w.Body.String(), tt.wantReranked != nil && *tt.wantReranked, tt.wantJustifications)
+			}
+		})
+	}
+
+	t.Run("invalid rerank value", func(t *testing.T) {
+		fake := &fakeAnalyzer{searchResults: raw}
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, anonymousHeaderAuth{}, nil)
+		if w := serve(h.HandleSearchResumes, httptest.NewRequest(http.MethodGet, "/api/search?query=go&rerank=maybe", nil)); w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestHandleSkillGaps(t *testing.T) {
+	report := &models.SkillGapReport{
+		JobID:               "job_1",
+		JobDescriptionCount: 2,
+		MissingSkills: []models.SkillGapCount{
+			{Skill: "Kubernetes", Count: 2, JobDescriptions: []int{0, 1}},
+			{Skill: "Terraform", Count: 1, JobDescriptions: []int{0}},
+		},
+		PerJobDescription: [][]string{{"Kubernetes", "Terraform"}, {"Kubernetes"}},
+	}
+	newFake := func(err error) *fakeAnalyzer {
+		fake := &fakeAnalyzer{skillGaps: report, skillGapErr: err}
+		fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{})
+		return fake
+	}
+
+	t.Run("ranked gaps", func(t *testing.T) {
+		fake := newFake(nil)
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+		body := strings.NewReader(`{"job_descriptions": ["  Platform engineer  ", "SRE"]}`)
+		w := serve(h.HandleSkillGaps, asUser(httptest.NewRequest(http.MethodPost, "/api/analysis/skill-gaps?job_id=job_1", body), 7))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		if want := []string{"Platform engineer", "SRE"}; !reflect.DeepEqual(fake.jobDescriptions, want) {
+			t.Errorf("analyzed %q, want the trimmed %q", fake.jobDescriptions, want)
+		}
+
+		var got models.SkillGapReport
+		decodeBody(t, w, &got)
+		if !reflect.DeepEqual(&got, report) {
+			t.Errorf("body = %+v, want %+v", got, report)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		body   string
+		userID int
+		err    error
+		want   int
+	}{
+		{"wrong method", http.MethodGet, "job_id=job_1", "", 7, nil, http.StatusMethodNotAllowed},
+		{"missing job ID", http.MethodPost, "", `{"job_descriptions": ["SRE"]}`, 7, nil, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "job_id=job_1", `{"job_descriptions":`, 7, nil, http.StatusBadRequest},
+		{"no job descriptions", http.MethodPost, "job_id=job_1", `{"job_descriptions": []}`, 7, nil, http.StatusBadRequest},
+		{"too many job descriptions", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["a","b","c","d","e","f","g","h","i","j","k"]}`, 7, nil, http.StatusBadRequest},
+		{"blank job description", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["SRE", "  "]}`, 7, nil, http.StatusBadRequest},
+		{"job description too long", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["` + strings.Repeat("a", maxSkillGapJobDescriptionLength+1) + `"]}`, 7, nil, http.StatusBadRequest},
+		{"unknown job", http.MethodPost, "job_id=job_9", `{"job_descriptions": ["SRE"]}`, 7, nil, http.StatusNotFound},
+		{"other user's job", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["SRE"]}`, 8, nil, http.StatusForbidden},
+		{"job not completed", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["SRE"]}`, 7, fmt.Errorf("%w (status: analyzing)", analyzer.ErrJobNotCompleted), http.StatusConflict},
+		{"invalid LLM response", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["SRE"]}`, 7, fmt.Errorf("job description 0: %w", analyzer.ErrInvalidSkillGap), http.StatusBadGateway},
+		{"LLM failure", http.MethodPost, "job_id=job_1", `{"job_descriptions": ["SRE"]}`, 7, errors.New("LLM request failed: timeout"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFake(tt.err)
+			h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+			r := asUser(httptest.NewRequest(tt.method, "/api/analysis/skill-gaps?"+tt.query, strings.NewReader(tt.body)), tt.userID)
+			if w := serve(h.HandleSkillGaps, r); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleGetFullJob(t *testing.T) {
+	text := "Ada Lovelace\nGo, SQL"
+	fake := &fakeAnalyzer{}
+	fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{UploadID: 1, Name: strPtr("Ada")})
+	fake.statuses["job_1"].ExtractedText = &text
+	fake.statuses["job_2"] = &models.AnalysisStatus{JobID: "job_2", UserID: intPtr(7), Status: "analyzing", Progress: 40, ExtractedText: &text}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		userID     int
+		wantStatus int
+		wantJob    string // Status of the job in the response
+		wantResult bool
+		wantText   bool
+	}{
+		{"completed", "job_id=job_1", 7, http.StatusOK, "completed", true, false},
+		{"completed with text", "job_id=job_1&include_text=true", 7, http.StatusOK, "completed", true, true},
+		{"in progress", "job_id=job_2", 7, http.StatusOK, "analyzing", false, false},
+		{"in progress with text", "job_id=job_2&include_text=true", 7, http.StatusOK, "analyzing", false, true},
+		{"missing job ID", "", 7, http.StatusBadRequest, "", false, false},
+		{"unknown job", "job_id=job_9", 7, http.StatusNotFound, "", false, false},
+		{"other user's job", "job_id=job_1", 8, http.StatusForbidden, "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleGetFullJob, asUser(httptest.NewRequest(http.MethodGet, "/api/analysis/full-job?"+tt.query, nil), tt.userID))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var full models.FullJob
+			decodeBody(t, w, &full)
+			if full.Job == nil || full.Job.Status != tt.wantJob {
+				t.Fatalf("job = %+v, want status %s", full.Job, tt.wantJob)
+			}
+			if (full.Job.ExtractedText != nil) != tt.wantText {
+				t.Errorf("extracted text included = %t, want %t", full.Job.ExtractedText != nil, tt.wantText)
+			}
+			if !tt.wantResult {
+				if full.Result != nil {
+					t.Errorf("result = %+v, want none before the job completes", full.Result)
+				}
+				return
+			}
+			if full.Result == nil || full.Result.JobID != "job_1" || full.Result.Name == nil || *full.Result.Name != "Ada" {
+				t.Errorf("result = %+v, want job_1's profile", full.Result)
+			}
+		})
+	}
+}
+
+func TestHandleExportOrgCandidates(t *testing.T) {
+	fake := &fakeAnalyzer{candidates: map[string][]*models.CandidateSummary{
+		"acme": {
+			{UploadID: 1, FileName: "ada.pdf", Status: "completed", Tags: []string{"backend"}, Name: strPtr("Ada")},
+			{UploadID: 2, FileName: "bob.pdf", Status: "failed", Tags: []string{"frontend"}},
+		},
+		"": {{UploadID: 3, FileName: "default.pdf", Status: "completed"}},
+	}}
+	auth := tenantAuth{tenants: map[int]string{7: "acme", 8: ""}}
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, auth, nil)
+
+	tests := []struct {
+		name       string
+		caller     int // 0 for no session
+		query      string
+		wantStatus int
+		wantRows   []string // Upload IDs of the rows after the header
+	}{
+		{"all candidates", 7, "", http.StatusOK, []string{"1", "2"}},
+		{"filtered by status", 7, "status=Completed", http.StatusOK, []string{"1"}},
+		{"filtered by tag", 7, "tag=frontend", http.StatusOK, []string{"2"}},
+		{"no match", 7, "status=completed&tag=frontend", http.StatusOK, nil},
+		{"invalid status", 7, "status=done", http.StatusBadRequest, nil},
+		{"no session", 0, "", http.StatusUnauthorized, nil},
+		{"default tenant", 8, "", http.StatusForbidden, nil},
+		{"unresolved tenant", 9, "", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/analysis/org-export?"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleExportOrgCandidates, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != "text/csv" {
+				t.Errorf("Content-Type = %q, want text/csv", got)
+			}
+			rows, err := csv.NewReader(w.Body).ReadAll()
+			if err != nil || len(rows) == 0 || rows[0][0] != "upload_id" {
+				t.Fatalf("rows = %q, %v; want a CSV with a header", rows, err)
+			}
+			var ids []string
+			for _, row := range rows[1:] {
+				ids = append(ids, row[0])
+			}
+			if !reflect.DeepEqual(ids, tt.wantRows) {
+				t.Errorf("rows for uploads %v, want %v", ids, tt.wantRows)
+			}
+		})
+	}
+
+	t.Run("too many candidates", func(t *testing.T) {
+		fake := &fakeAnalyzer{candidatesErr: analyzer.ErrTooManyCandidates}
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, auth, nil)
+		if w := serve(h.HandleExportOrgCandidates, asUser(httptest.NewRequest(http.MethodGet, "/api/analysis/org-export", nil), 7)); w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want 413", w.Code)
+		}
+	})
+}
+
+func TestHandlePatchProfile(t *testing.T) {
+	summary := "Backend engineer"
+	skills := map[string][]string{"technical": {"Go", "SQL"}}
+	experience := []models.ExperienceEntry{{Company: "Acme", Role: "Engineer"}}
+	newFake := func() *fakeAnalyzer {
+		fake := &fakeAnalyzer{}
+		fake.completedJob("job_1", intPtr(7), &models.AnalysisResult{Summary: &summary, Skills: skills, Experience: experience})
+		return fake
+	}
+	patch := func(fake *fakeAnalyzer, query, body string, userID int) *httptest.ResponseRecorder {
+		h := NewAnalysisHandler(fake, stubExporter{}, nil, headerAuth{}, nil)
+		r := asUser(httptest.NewRequest(http.MethodPatch, "/api/analysis/profile?"+query, strings.NewReader(body)), userID)
+		return serve(h.HandlePatchProfile, r)
+	}
+
+	t.Run("summary only", func(t *testing.T) {
+		fake := newFake()
+		w := patch(fake, "job_id=job_1", `{"summary": "Staff engineer"}`, 7)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		if len(fake.patches) != 1 || !reflect.DeepEqual(fake.patches[0].Fields(), []string{"summary"}) {
+			t.Fatalf("patches = %+v, want one setting only the summary", fake.patches)
+		}
+		var result models.AnalysisResult
+		decodeBody(t, w, &result)
+		if result.Summary == nil || *result.Summary != "Staff engineer" {
+			t.Errorf("summary = %v, want the patched one", result.Summary)
+		}
+		if !reflect.DeepEqual(result.Skills, skills) || !reflect.DeepEqual(result.Experience, experience) {
+			t.Errorf("skills = %v, experience = %+v; want them unchanged", result.Skills, result.Experience)
+		}
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		fake := newFake()
+		if w := patch(fake, "job_id=job_1", `{"strengths": []}`, 7); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		if got := fake.patches[0]; got.Strengths == nil || len(*got.Strengths) != 0 || len(got.Fields()) != 1 {
+			t.Errorf("patch = %+v, want only the strengths cleared", got)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		query  string
+		body   string
+		userID int
+		want   int
+	}{
+		{"missing job ID", "", `{"summary": "x"}`, 7, http.StatusBadRequest},
+		{"no fields", "job_id=job_1", `{}`, 7, http.StatusBadRequest},
+		{"null field", "job_id=job_1", `{"summary": null}`, 7, http.StatusBadRequest},
+		{"unknown field", "job_id=job_1", `{"summary": "x", "salary": 1}`, 7, http.StatusBadRequest},
+		{"read-only field", "job_id=job_1", `{"job_id": "job_2"}`, 7, http.StatusBadRequest},
+		{"invalid body", "job_id=job_1", `{"summary":`, 7, http.StatusBadRequest},
+		{"unknown job", "job_id=job_9", `{"summary": "x"}`, 7, http.StatusNotFound},
+		{"other user's job", "job_id=job_1", `{"summary": "x"}`, 8, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFake()
+			if w := patch(fake, tt.query, tt.body, tt.userID); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if len(fake.patches) != 0 {
+				t.Errorf("profile was patched: %+v", fake.patches)
+			}
+		})
+	}
+
+	t.Run("job not completed", func(t *testing.T) {
+		fake := newFake()
+		fake.statuses["job_1"].Status = "processing"
+		if w := patch(fake, "job_id=job_1", `{"summary": "x"}`, 7); w.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", w.Code)
+		}
+	})
+}
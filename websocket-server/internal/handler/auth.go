@@ -1,35 +1,56 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 
-	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/internal/clock"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
-// AuthHandler handles authentication-related HTTP requests
+// AuthHandler handles authentication-related HTTP requests. Credentials and users come
+// from an AuthProvider; sessions are kept by the handler.
 type AuthHandler struct {
-	repo     repository.UserRepository
+	provider AuthProvider
+	clock    clock.Clock
+	signup   *signupGuard
 	sessions sync.Map // Simple in-memory session store (token -> userID)
+
+	// allowAnonymous keeps uploads and jobs created without a session open to every caller
+	allowAnonymous bool
 }
 
-// NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(repo repository.UserRepository) *AuthHandler {
+// NewAuthHandler creates a new AuthHandler, e.g. with a LocalAuthProvider. A nil clock
+// uses the system clock; a nil signup protection limits signups per address with the
+// defaults. allowAnonymous enables anonymous mode: callers without a session may upload
+// and analyze, and what they create is accessible to everyone. Without it, every upload
+// and job needs a signed-in owner.
+func NewAuthHandler(provider AuthProvider, clk clock.Clock, signup *SignupProtection, allowAnonymous bool) *AuthHandler {
+	clk = clock.OrReal(clk)
 	return &AuthHandler{
-		repo: repo,
+		provider:       provider,
+		clock:          clk,
+		signup:         newSignupGuard(signup, clk),
+		allowAnonymous: allowAnonymous,
 	}
 }
 
+// AllowsAnonymous reports whether the handler runs in anonymous mode
+func (h *AuthHandler) AllowsAnonymous() bool {
+	return h.allowAnonymous
+}
+
 // generateToken generates a simple session token
-func generateToken() string {
-	return fmt.Sprintf("token_%d_%d", time.Now().UnixNano(), time.Now().Unix())
+func (h *AuthHandler) generateToken() string {
+	now := h.clock.Now()
+	return fmt.Sprintf("token_%d_%d", now.UnixNano(), now.Unix())
 }
 
 // validateEmail checks if email format is valid
@@ -45,6 +66,17 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	registrar, ok := h.provider.(UserRegistrar)
+	if !ok {
+		sendAuthError(w, "Signup is not available", http.StatusForbidden)
+		return
+	}
+
+	if !h.signup.check(w, r) {
+		log.Printf("Signup from %s refused by signup protection", r.RemoteAddr)
+		return
+	}
+
 	var req models.SignupRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		sendAuthError(w, "Invalid request body", http.StatusBadRequest)
@@ -86,27 +118,12 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if email already exists
-	exists, err := h.repo.EmailExists(r.Context(), req.Email)
-	if err != nil {
-		log.Printf("Error checking email existence: %v", err)
-		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	if exists {
+	// Create user
+	createdUser, err := registrar.Register(r.Context(), req.Name, req.Email, req.Password)
+	if errors.Is(err, ErrEmailTaken) {
 		sendAuthError(w, "Email already registered", http.StatusConflict)
 		return
 	}
-
-	// Create user
-	user := &models.User{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: req.Password, // Plain text for mock implementation
-	}
-
-	createdUser, err := h.repo.CreateUser(r.Context(), user)
 	if err != nil {
 		log.Printf("Error creating user: %v", err)
 		sendAuthError(w, "Failed to create user", http.StatusInternalServerError)
@@ -114,7 +131,7 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate session token
-	token := generateToken()
+	token := h.generateToken()
 	h.sessions.Store(token, createdUser.ID)
 
 	log.Printf("User signed up successfully: %s (%s)", createdUser.Name, createdUser.Email)
@@ -157,27 +174,19 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user by email
-	user, err := h.repo.GetUserByEmail(r.Context(), req.Email)
-	if err != nil {
-		log.Printf("Error getting user: %v", err)
-		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	if user == nil {
+	user, err := h.provider.Authenticate(r.Context(), req.Email, req.Password)
+	if errors.Is(err, ErrInvalidCredentials) {
 		sendAuthError(w, "Invalid email or password", http.StatusUnauthorized)
 		return
 	}
-
-	// Check password (plain text comparison for mock implementation)
-	if user.Password != req.Password {
-		sendAuthError(w, "Invalid email or password", http.StatusUnauthorized)
+	if err != nil {
+		log.Printf("Error authenticating user: %v", err)
+		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	// Generate session token
-	token := generateToken()
+	token := h.generateToken()
 	h.sessions.Store(token, user.ID)
 
 	log.Printf("User logged in successfully: %s (%s)", user.Name, user.Email)
@@ -245,8 +254,7 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 
 	userID := userIDValue.(int)
 
-	// Get user from database
-	user, err := h.repo.GetUserByID(r.Context(), userID)
+	user, err := h.provider.GetUser(r.Context(), userID)
 	if err != nil {
 		log.Printf("Error getting user: %v", err)
 		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
@@ -265,6 +273,117 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SetExportFormat sets the current user's default export format, used by
+// /api/analysis/export when a request has no format parameter. An empty format clears it.
+func (h *AuthHandler) SetExportFormat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := h.UserIDFromRequest(r)
+	if !ok {
+		sendAuthError(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+
+	updater, ok := h.provider.(ExportFormatUpdater)
+	if !ok {
+		sendAuthError(w, "Preferences are not available", http.StatusForbidden)
+		return
+	}
+
+	var req models.ExportFormatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendAuthError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Store the canonical name, or "" to clear the preference
+	var format string
+	if strings.TrimSpace(req.Format) != "" {
+		parsed, ok := parseExportFormat(req.Format)
+		if !ok {
+			sendAuthError(w, "Invalid format. Supported formats: "+exportFormatNames, http.StatusBadRequest)
+			return
+		}
+		format = string(parsed)
+	}
+
+	if err := updater.UpdateDefaultExportFormat(r.Context(), userID, format); err != nil {
+		log.Printf("Error setting default export format of user %d: %v", userID, err)
+		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.provider.GetUser(r.Context(), userID)
+	if err != nil || user == nil {
+		log.Printf("Error getting user %d after setting export format: %v", userID, err)
+		sendAuthError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.AuthResponse{
+		Success: true,
+		Message: "Default export format updated",
+		User:    user.ToResponse(),
+	})
+}
+
+// DefaultExportFormatFromRequest returns the default export format of the user whose
+// session token is in the Authorization header, or "" without a valid session or preference
+func (h *AuthHandler) DefaultExportFormatFromRequest(ctx context.Context, r *http.Request) (string, error) {
+	userID, ok := h.UserIDFromRequest(r)
+	if !ok {
+		return "", nil
+	}
+
+	user, err := h.provider.GetUser(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+	if user == nil {
+		return "", fmt.Errorf("user %d not found", userID)
+	}
+	return user.DefaultExportFormat, nil
+}
+
+// UserIDFromRequest returns the ID of the user whose session token is in the
+// Authorization header, if the session is valid
+func (h *AuthHandler) UserIDFromRequest(r *http.Request) (int, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return 0, false
+	}
+
+	userIDValue, ok := h.sessions.Load(strings.TrimPrefix(authHeader, "Bearer "))
+	if !ok {
+		return 0, false
+	}
+
+	userID, ok := userIDValue.(int)
+	return userID, ok
+}
+
+// TenantIDFromRequest returns the tenant of the user whose session token is in the
+// Authorization header, or the default tenant "" without a valid session
+func (h *AuthHandler) TenantIDFromRequest(ctx context.Context, r *http.Request) (string, error) {
+	userID, ok := h.UserIDFromRequest(r)
+	if !ok {
+		return "", nil
+	}
+
+	user, err := h.provider.GetUser(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user %d: %w", userID, err)
+	}
+	if user == nil {
+		return "", fmt.Errorf("user %d not found", userID)
+	}
+	return user.TenantID, nil
+}
+
 // sendAuthError sends an error response
 func sendAuthError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+var (
+	// ErrInvalidCredentials is returned by AuthProvider.Authenticate for unknown users and wrong passwords
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrEmailTaken is returned by UserRegistrar.Register when the email is already registered
+	ErrEmailTaken = errors.New("email already registered")
+)
+
+// AuthProvider verifies credentials and looks up users for AuthHandler, which owns the
+// sessions. The local provider checks passwords against the users table; an OAuth/SSO
+// provider can be plugged in without changing the handlers.
+type AuthProvider interface {
+	// Authenticate returns the user with the given credentials, or ErrInvalidCredentials
+	Authenticate(ctx context.Context, email, password string) (*models.User, error)
+
+	// GetUser returns a user by ID, or nil if there is no such user
+	GetUser(ctx context.Context, id int) (*models.User, error)
+}
+
+// UserRegistrar is implemented by auth providers that let users sign up
+type UserRegistrar interface {
+	// Register creates a user, or returns ErrEmailTaken
+	Register(ctx context.Context, name, email, password string) (*models.User, error)
+}
+
+// ExportFormatUpdater is implemented by auth providers that store user preferences
+type ExportFormatUpdater interface {
+	// UpdateDefaultExportFormat sets a user's default export format ("" clears it)
+	UpdateDefaultExportFormat(ctx context.Context, id int, format string) error
+}
+
+// LocalAuthProvider authenticates users against the users table
+type LocalAuthProvider struct {
+	repo repository.UserRepository
+}
+
+// NewLocalAuthProvider creates an auth provider backed by the user repository
+func NewLocalAuthProvider(repo repository.UserRepository) *LocalAuthProvider {
+	return &LocalAuthProvider{repo: repo}
+}
+
+// Authenticate checks the password of the user with the email
+func (p *LocalAuthProvider) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	user, err := p.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Plain text comparison for mock implementation
+	if user == nil || user.Password != password {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// GetUser returns a user by ID, or nil if there is no such user
+func (p *LocalAuthProvider) GetUser(ctx context.Context, id int) (*models.User, error) {
+	return p.repo.GetUserByID(ctx, id)
+}
+
+// Register creates a user unless the email is already registered
+func (p *LocalAuthProvider) Register(ctx context.Context, name, email, password string) (*models.User, error) {
+	exists, err := p.repo.EmailExists(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return nil, ErrEmailTaken
+	}
+
+	return p.repo.CreateUser(ctx, &models.User{
+		Name:     name,
+		Email:    email,
+		Password: password, // Plain text for mock implementation
+	})
+}
+
+// UpdateDefaultExportFormat sets a user's default export format ("" clears it)
+func (p *LocalAuthProvider) UpdateDefaultExportFormat(ctx context.Context, id int, format string) error {
+	return p.repo.UpdateDefaultExportFormat(ctx, id, format)
+}
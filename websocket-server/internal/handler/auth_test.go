@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// login signs in email at the handler's current time and returns the session token
+func login(t *testing.T, h *AuthHandler, email string) string {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"email":%q,"password":"secret"}`, email)
+	w := serve(h.Login, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("login status = %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp models.AuthResponse
+	decodeBody(t, w, &resp)
+	return resp.Token
+}
+
+// withToken adds token to r as a bearer token
+func withToken(r *http.Request, token string) *http.Request {
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestLoginTokensComeFromTheClock(t *testing.T) {
+	provider := &fakeAuthProvider{users: map[string]*models.User{
+		"ada@example.com":   {ID: 1, Name: "Ada", Email: "ada@example.com"},
+		"grace@example.com": {ID: 2, Name: "Grace", Email: "grace@example.com"},
+	}}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	fake := clock.NewFake(start)
+	h := NewAuthHandler(provider, fake, nil, false)
+
+	adaToken := login(t, h, "ada@example.com")
+	if want := fmt.Sprintf("token_%d_%d", start.UnixNano(), start.Unix()); adaToken != want {
+		t.Errorf("token = %q, want %q", adaToken, want)
+	}
+
+	fake.Advance(time.Nanosecond)
+	graceToken := login(t, h, "grace@example.com")
+	if graceToken == adaToken {
+		t.Fatalf("logins at different times got the same token %q", adaToken)
+	}
+
+	for token, wantID := range map[string]int{adaToken: 1, graceToken: 2} {
+		if id, ok := h.UserIDFromRequest(withToken(httptest.NewRequest(http.MethodGet, "/", nil), token)); !ok || id != wantID {
+			t.Errorf("token %q resolves to %d (%t), want user %d", token, id, ok, wantID)
+		}
+	}
+
+	// Sessions don't expire with time; they last until logout
+	fake.Advance(365 * 24 * time.Hour)
+	if _, ok := h.UserIDFromRequest(withToken(httptest.NewRequest(http.MethodGet, "/", nil), adaToken)); !ok {
+		t.Errorf("session ended without a logout")
+	}
+
+	w := serve(h.Logout, withToken(httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil), adaToken))
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout status = %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := h.UserIDFromRequest(withToken(httptest.NewRequest(http.MethodGet, "/", nil), adaToken)); ok {
+		t.Errorf("token still valid after logout")
+	}
+	if _, ok := h.UserIDFromRequest(withToken(httptest.NewRequest(http.MethodGet, "/", nil), graceToken)); !ok {
+		t.Errorf("logging out one user ended another's session")
+	}
+}
+
+func TestDefaultExportFormat(t *testing.T) {
+	ada := &models.User{ID: 1, Name: "Ada", Email: "ada@example.com"}
+	provider := &fakeAuthProvider{users: map[string]*models.User{ada.Email: ada}}
+	auth := NewAuthHandler(provider, nil, nil, false)
+	token := login(t, auth, ada.Email)
+
+	fake := &fakeAnalyzer{}
+	fake.completedJob("job_1", intPtr(ada.ID), &models.AnalysisResult{UploadID: 3})
+	analysis := NewAnalysisHandler(fake, stubExporter{}, nil, auth, nil)
+
+	// setFormat sets Ada's default export format, returning the response status
+	setFormat := func(t *testing.T, format string) int {
+		t.Helper()
+		body := fmt.Sprintf(`{"format":%q}`, format)
+		return serve(auth.SetExportFormat, withToken(httptest.NewRequest(http.MethodPost, "/api/auth/export-format", strings.NewReader(body)), token)).Code
+	}
+	// export exports job_1 as Ada with query, returning the exported format
+	export := func(t *testing.T, query string) string {
+		t.Helper()
+		w := serve(analysis.HandleExportAnalysis, withToken(httptest.NewRequest(http.MethodGet, "/api/analysis/export?job_id=job_1"+query, nil), token))
+		if w.Code != http.StatusOK {
+			t.Fatalf("export status = %d: %s", w.Code, w.Body.String())
+		}
+		format, _, _ := strings.Cut(w.Body.String(), ":")
+		return format
+	}
+
+	if got := export(t, ""); got != "json" {
+		t.Errorf("format without a preference = %q, want json", got)
+	}
+
+	if status := setFormat(t, " PDF "); status != http.StatusOK {
+		t.Fatalf("setting the format: status %d", status)
+	}
+	if ada.DefaultExportFormat != "pdf" {
+		t.Errorf("stored format = %q, want the canonical pdf", ada.DefaultExportFormat)
+	}
+	if got := export(t, ""); got != "pdf" {
+		t.Errorf("default format = %q, want the preferred pdf", got)
+	}
+	if got := export(t, "&format=csv"); got != "csv" {
+		t.Errorf("explicit format = %q, want csv over the preference", got)
+	}
+
+	if status := setFormat(t, "xml"); status != http.StatusBadRequest {
+		t.Errorf("unknown format: status %d, want 400", status)
+	}
+	if ada.DefaultExportFormat != "pdf" {
+		t.Errorf("rejected format replaced the preference: %q", ada.DefaultExportFormat)
+	}
+
+	// A stored value that is no longer a supported format falls back to JSON
+	ada.DefaultExportFormat = "xml"
+	if got := export(t, ""); got != "json" {
+		t.Errorf("format with an unsupported preference = %q, want json", got)
+	}
+
+	if status := setFormat(t, ""); status != http.StatusOK || ada.DefaultExportFormat != "" {
+		t.Errorf("clearing the format: status %d, stored %q", status, ada.DefaultExportFormat)
+	}
+	if got := export(t, ""); got != "json" {
+		t.Errorf("format after clearing = %q, want json", got)
+	}
+
+	w := serve(auth.SetExportFormat, httptest.NewRequest(http.MethodPost, "/api/auth/export-format", strings.NewReader(`{"format":"pdf"}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("without a session: status %d, want 401", w.Code)
+	}
+}
+
+// currentUser returns the status of a current-user request with token, and the user it reports
+func currentUser(t *testing.T, h *AuthHandler, token string) (int, *models.UserResponse) {
+	t.Helper()
+
+	w := serve(h.GetCurrentUser, withToken(httptest.NewRequest(http.MethodGet, "/api/auth/me", nil), token))
+	var resp models.AuthResponse
+	decodeBody(t, w, &resp)
+	return w.Code, resp.User
+}
+
+// failingAuthProvider fails every lookup with err
+type failingAuthProvider struct {
+	err error
+}
+
+func (p failingAuthProvider) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	return nil, p.err
+}
+
+func (p failingAuthProvider) GetUser(ctx context.Context, id int) (*models.User, error) {
+	return nil, p.err
+}
+
+func TestAuthHandlerUsesTheProvider(t *testing.T) {
+	ada := &models.User{ID: 1, Name: "Ada", Email: "ada@example.com"}
+	provider := &fakeAuthProvider{users: map[string]*models.User{ada.Email: ada}}
+	h := NewAuthHandler(provider, nil, nil, false)
+
+	t.Run("login, current user and logout", func(t *testing.T) {
+		token := login(t, h, " ADA@example.com ")
+
+		status, user := currentUser(t, h, token)
+		if status != http.StatusOK || user == nil || user.ID != ada.ID || user.Email != ada.Email {
+			t.Fatalf("current user = %d %+v, want Ada", status, user)
+		}
+
+		// A user the provider no longer knows
+		delete(provider.users, ada.Email)
+		if status, _ := currentUser(t, h, token); status != http.StatusNotFound {
+			t.Errorf("current user of a removed user: status %d, want 404", status)
+		}
+		provider.users[ada.Email] = ada
+
+		w := serve(h.Logout, withToken(httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil), token))
+		if w.Code != http.StatusOK {
+			t.Fatalf("logout status = %d: %s", w.Code, w.Body.String())
+		}
+		if status, _ := currentUser(t, h, token); status != http.StatusUnauthorized {
+			t.Errorf("current user after logout: status %d, want 401", status)
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		for _, body := range []string{
+			`{"email":"ada@example.com","password":"wrong"}`,
+			`{"email":"eve@example.com","password":"secret"}`,
+		} {
+			w := serve(h.Login, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body)))
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("login %s: status %d, want 401", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		failing := NewAuthHandler(failingAuthProvider{err: errors.New("directory unreachable")}, nil, nil, false)
+
+		w := serve(failing.Login, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(`{"email":"ada@example.com","password":"secret"}`)))
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "unreachable") {
+			t.Errorf("login: status %d (%s), want 500 without the cause", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("signup needs a registrar", func(t *testing.T) {
+		body := `{"name":"Grace","email":"grace@example.com","password":"secret"}`
+		w := serve(h.Signup, httptest.NewRequest(http.MethodPost, "/api/auth/signup", strings.NewReader(body)))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("signup without a registrar: status %d, want 403", w.Code)
+		}
+	})
+}
+
+// fakeUserRepo stores users in memory. Methods a test needs but the fake doesn't
+// implement panic through the embedded nil interface.
+type fakeUserRepo struct {
+	repository.UserRepository
+
+	users []*models.User
+}
+
+func (f *fakeUserRepo) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	created := *user
+	created.ID = len(f.users) + 1
+	f.users = append(f.users, &created)
+	return &created, nil
+}
+
+func (f *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUserRepo) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUserRepo) EmailExists(ctx context.Context, email string) (bool, error) {
+	user, err := f.GetUserByEmail(ctx, email)
+	return user != nil, err
+}
+
+func TestLocalAuthProvider(t *testing.T) {
+	repo := &fakeUserRepo{}
+	h := NewAuthHandler(NewLocalAuthProvider(repo), nil, nil, false)
+
+	signup := func(t *testing.T, email string) int {
+		t.Helper()
+		body := fmt.Sprintf(`{"name":"Grace","email":%q,"password":"secret"}`, email)
+		return serve(h.Signup, httptest.NewRequest(http.MethodPost, "/api/auth/signup", strings.NewReader(body))).Code
+	}
+
+	if status := signup(t, "grace@example.com"); status != http.StatusCreated {
+		t.Fatalf("signup status = %d, want 201", status)
+	}
+	if status := signup(t, "Grace@Example.com"); status != http.StatusConflict {
+		t.Errorf("second signup with the email: status %d, want 409", status)
+	}
+
+	token := login(t, h, "grace@example.com")
+	if status, user := currentUser(t, h, token); status != http.StatusOK || user == nil || user.Name != "Grace" {
+		t.Errorf("current user = %d %+v, want Grace", status, user)
+	}
+
+	provider := NewLocalAuthProvider(repo)
+	if _, err := provider.Authenticate(context.Background(), "grace@example.com", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password: error %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := provider.Authenticate(context.Background(), "nobody@example.com", "secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown user: error %v, want ErrInvalidCredentials", err)
+	}
+}
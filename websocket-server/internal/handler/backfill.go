@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/qamatcher"
+)
+
+// BackfillHandler exposes manual triggers for background maintenance jobs
+type BackfillHandler struct {
+	embeddingBackfiller *qamatcher.EmbeddingBackfiller
+	adminToken          string // Required in the X-Admin-Token header; empty disables the endpoint
+}
+
+// NewBackfillHandler creates a new backfill handler instance
+func NewBackfillHandler(embeddingBackfiller *qamatcher.EmbeddingBackfiller, adminToken string) *BackfillHandler {
+	return &BackfillHandler{
+		embeddingBackfiller: embeddingBackfiller,
+		adminToken:          adminToken,
+	}
+}
+
+// HandleRunEmbeddingBackfill handles POST /api/interview/embeddings/backfill
+// Generates embeddings for saved questions that were stored without one. Admin only,
+// since a run spends embedding API credits.
+func (h *BackfillHandler) HandleRunEmbeddingBackfill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	result, err := h.embeddingBackfiller.RunOnce(ctx)
+	if err != nil {
+		log.Printf("Error running embedding backfill: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":  "Embedding backfill failed",
+			"result": result,
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleRunEmbeddingBackfillRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string // Configured token
+		header     string // Sent token
+		wantStatus int
+	}{
+		{"not configured", "", "secret", http.StatusForbidden},
+		{"missing token", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "guess", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A nil backfiller panics if the guard lets the request through
+			h := NewBackfillHandler(nil, tt.adminToken)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/interview/embeddings/backfill", nil)
+			if tt.header != "" {
+				r.Header.Set(AdminTokenHeader, tt.header)
+			}
+			if w := serve(h.HandleRunEmbeddingBackfill, r); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/your-org/websocket-server/internal/exporter"
+	"github.com/your-org/websocket-server/internal/prompts"
+)
+
+// CapabilitiesConfig holds the settings reported by HandleCapabilities that aren't
+// constants of this package. It is filled from the same values the other handlers are
+// configured with.
+type CapabilitiesConfig struct {
+	AudioLimits          AudioLimits // As given to NewChatMessageHandler; zero limits report the defaults
+	SyncAnalysisMaxBytes int         // Largest upload analyzed synchronously (0 = sync analysis disabled)
+	LLMFallback          bool        // An LLM client is configured, so chats can load Q&A with the LLM fallback mode
+	Streaming            bool        // LLM fallback answers can be streamed as message_chunk messages
+}
+
+// Capabilities describes the server's limits and optional features so frontends can adapt
+// their UI. It holds no secrets.
+type Capabilities struct {
+	Upload    UploadCapabilities    `json:"upload"`
+	Interview InterviewCapabilities `json:"interview"`
+	Export    ExportCapabilities    `json:"export"`
+	Audio     AudioCapabilities     `json:"audio"`
+	Features  FeatureCapabilities   `json:"features"`
+}
+
+// UploadCapabilities describes which resume files are accepted
+type UploadCapabilities struct {
+	MaxBytes             int      `json:"max_bytes"`
+	AllowedMimeTypes     []string `json:"allowed_mime_types"`
+	SyncAnalysisMaxBytes int      `json:"sync_analysis_max_bytes"` // 0 when sync=true isn't available
+}
+
+// InterviewCapabilities describes the limits of interview question generation
+type InterviewCapabilities struct {
+	QuestionsPerGeneration int      `json:"questions_per_generation"`
+	MaxCheckSavedBatch     int      `json:"max_check_saved_batch"`
+	Languages              []string `json:"languages"` // Answer language codes
+}
+
+// ExportCapabilities lists the supported export formats
+type ExportCapabilities struct {
+	AnalysisFormats   []string `json:"analysis_formats"`
+	TranscriptFormats []string `json:"transcript_formats"`
+}
+
+// AudioCapabilities describes the limits of audio chat messages
+type AudioCapabilities struct {
+	MaxBytes      int `json:"max_bytes"`
+	MaxDurationMs int `json:"max_duration_ms"`
+}
+
+// FeatureCapabilities reports which optional features are enabled
+type FeatureCapabilities struct {
+	LLMFallback bool `json:"llm_fallback"`
+	Streaming   bool `json:"streaming"`
+	OCR         bool `json:"ocr"` // Always false: text comes from the document's text layer
+}
+
+// CapabilitiesHandler reports the server's limits and optional features
+type CapabilitiesHandler struct {
+	capabilities Capabilities
+}
+
+// NewCapabilitiesHandler creates a capabilities handler reporting config and this
+// package's limits
+func NewCapabilitiesHandler(config CapabilitiesConfig) *CapabilitiesHandler {
+	return &CapabilitiesHandler{capabilities: buildCapabilities(config)}
+}
+
+// buildCapabilities assembles the capabilities reported for config
+func buildCapabilities(config CapabilitiesConfig) Capabilities {
+	audio := config.AudioLimits
+	if audio.MaxBytes <= 0 {
+		audio.MaxBytes = DefaultMaxAudioBytes
+	}
+	if audio.MaxDurationMs <= 0 {
+		audio.MaxDurationMs = DefaultMaxAudioDurationMs
+	}
+
+	languages := make([]string, 0, len(supportedLanguages))
+	for code := range supportedLanguages {
+		languages = append(languages, code)
+	}
+	sort.Strings(languages)
+
+	return Capabilities{
+		Upload: UploadCapabilities{
+			MaxBytes:             MaxUploadSize,
+			AllowedMimeTypes:     strings.Split(AllowedMimeTypes, ","),
+			SyncAnalysisMaxBytes: max(config.SyncAnalysisMaxBytes, 0),
+		},
+		Interview: InterviewCapabilities{
+			QuestionsPerGeneration: prompts.InterviewQuestionCount,
+			MaxCheckSavedBatch:     maxCheckSavedBatch,
+			Languages:              languages,
+		},
+		Export: ExportCapabilities{
+			AnalysisFormats:   formatNames(exporter.AnalysisFormats),
+			TranscriptFormats: formatNames(exporter.TranscriptFormats),
+		},
+		Audio: AudioCapabilities{
+			MaxBytes:      audio.MaxBytes,
+			MaxDurationMs: audio.MaxDurationMs,
+		},
+		Features: FeatureCapabilities{
+			LLMFallback: config.LLMFallback,
+			Streaming:   config.LLMFallback && config.Streaming,
+		},
+	}
+}
+
+// HandleCapabilities handles GET /api/capabilities
+// Returns the upload, interview, export and audio limits and the enabled optional features
+func (h *CapabilitiesHandler) HandleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.capabilities)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandleCapabilities(t *testing.T) {
+	capabilities := func(t *testing.T, config CapabilitiesConfig) (Capabilities, string) {
+		t.Helper()
+		w := serve(NewCapabilitiesHandler(config).HandleCapabilities, httptest.NewRequest(http.MethodGet, "/api/capabilities", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		var got Capabilities
+		decodeBody(t, w, &got)
+		return got, body
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		got, body := capabilities(t, CapabilitiesConfig{
+			AudioLimits:          AudioLimits{MaxBytes: 1 << 20, MaxDurationMs: 60000},
+			SyncAnalysisMaxBytes: 65536,
+			LLMFallback:          true,
+			Streaming:            true,
+		})
+
+		want := Capabilities{
+			Upload: UploadCapabilities{
+				MaxBytes: 10 * 1024 * 1024,
+				AllowedMimeTypes: []string{
+					"application/pdf",
+					"application/msword",
+					"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+				},
+				SyncAnalysisMaxBytes: 65536,
+			},
+			Interview: InterviewCapabilities{
+				QuestionsPerGeneration: 10,
+				MaxCheckSavedBatch:     100,
+				Languages:              []string{"de", "en", "es", "fr", "hi", "it", "ja", "ko", "nl", "pt", "zh"},
+			},
+			Export: ExportCapabilities{
+				AnalysisFormats:   []string{"json", "csv", "csv_flat", "pdf", "docx"},
+				TranscriptFormats: []string{"pdf", "md", "txt"},
+			},
+			Audio:    AudioCapabilities{MaxBytes: 1 << 20, MaxDurationMs: 60000},
+			Features: FeatureCapabilities{LLMFallback: true, Streaming: true},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("capabilities = %+v\nwant %+v", got, want)
+		}
+
+		for _, secret := range []string{"key", "token", "password", "secret"} {
+			if strings.Contains(strings.ToLower(body), secret) {
+				t.Errorf("response mentions %q: %s", secret, body)
+			}
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		got, _ := capabilities(t, CapabilitiesConfig{SyncAnalysisMaxBytes: -1, Streaming: true})
+		if got.Audio != (AudioCapabilities{MaxBytes: DefaultMaxAudioBytes, MaxDurationMs: DefaultMaxAudioDurationMs}) {
+			t.Errorf("audio = %+v, want the defaults", got.Audio)
+		}
+		if got.Upload.SyncAnalysisMaxBytes != 0 {
+			t.Errorf("sync analysis max bytes = %d, want 0", got.Upload.SyncAnalysisMaxBytes)
+		}
+		// Streaming needs the LLM fallback it streams
+		if got.Features != (FeatureCapabilities{}) {
+			t.Errorf("features = %+v, want none", got.Features)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := serve(NewCapabilitiesHandler(CapabilitiesConfig{}).HandleCapabilities, httptest.NewRequest(http.MethodPost, "/api/capabilities", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("status = %d, want 405", w.Code)
+		}
+	})
+}
@@ -3,48 +3,78 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/your-org/websocket-server/internal/analyzer"
 	"github.com/your-org/websocket-server/internal/hub"
 	"github.com/your-org/websocket-server/internal/qamatcher"
 	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/internal/tier"
+	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // ChatHandler handles chat-related requests
 type ChatHandler struct {
 	hub               *hub.Hub
 	savedQuestionRepo repository.SavedQuestionRepository
+	analysisRepo      repository.AnalysisRepository
 	embedder          analyzer.EmbeddingGenerator
+	llmClient         analyzer.LLMClient // Used by the LLM fallback mode
+	tiers             tier.Resolver      // Selects the matcher embedder and fallback LLM for the caller
 }
 
-// NewChatHandler creates a new chat handler instance
-func NewChatHandler(h *hub.Hub, savedQuestionRepo repository.SavedQuestionRepository, embedder analyzer.EmbeddingGenerator) *ChatHandler {
+// NewChatHandler creates a new chat handler instance.
+// A nil tier resolver uses embedder and llmClient for every request.
+func NewChatHandler(h *hub.Hub, savedQuestionRepo repository.SavedQuestionRepository, analysisRepo repository.AnalysisRepository, embedder analyzer.EmbeddingGenerator, llmClient analyzer.LLMClient, tiers tier.Resolver) *ChatHandler {
+	if tiers == nil {
+		tiers = tier.NewStaticResolver(tier.NewPlan(tier.Plan{LLMClient: llmClient, Embedder: embedder}, nil))
+	}
+
 	return &ChatHandler{
 		hub:               h,
 		savedQuestionRepo: savedQuestionRepo,
+		analysisRepo:      analysisRepo,
 		embedder:          embedder,
+		llmClient:         llmClient,
+		tiers:             tiers,
 	}
 }
 
 // LoadQARequest represents the request to load Q&A pairs for a chat session
 type LoadQARequest struct {
-	ClientID string `json:"client_id"` // WebSocket client ID
-	UserID   string `json:"user_id"`   // User ID who owns the questions
-	JobID    string `json:"job_id"`    // Job ID to load questions from
-	Limit    int    `json:"limit"`     // Number of Q&A pairs to load (default: 20)
+	ClientID       string   `json:"client_id"`                 // WebSocket client ID
+	UserID         string   `json:"user_id"`                   // User ID who owns the questions
+	JobID          string   `json:"job_id"`                    // Job ID to load questions from
+	Limit          int      `json:"limit"`                     // Number of Q&A pairs to load (default: 20)
+	FallbackMode   string   `json:"fallback_mode,omitempty"`   // Reply when nothing matches: echo (default), canned or llm
+	CannedResponse string   `json:"canned_response,omitempty"` // Reply for canned mode (and when the LLM fallback fails)
+	HistoryTurns   int      `json:"history_turns,omitempty"`   // Recent turns the LLM fallback sees (default 5, max 20, -1 disables)
+	Stream         bool     `json:"stream,omitempty"`          // Stream LLM fallback answers as message_chunk messages
+	Metric         string   `json:"metric,omitempty"`          // Similarity metric: cosine (default), dot or euclidean
+	Threshold      *float64 `json:"threshold,omitempty"`       // On the metric's scale; default depends on the metric
 }
 
 // LoadQAResponse represents the response after loading Q&A pairs
 type LoadQAResponse struct {
-	Success   bool   `json:"success"`
-	Count     int    `json:"count"`     // Number of Q&A pairs loaded
-	Threshold float64 `json:"threshold"` // Similarity threshold
-	Message   string `json:"message"`
+	Success      bool    `json:"success"`
+	Count        int     `json:"count"`         // Number of Q&A pairs loaded
+	Threshold    float64 `json:"threshold"`     // Similarity threshold
+	Metric       string  `json:"metric"`        // Similarity metric the threshold applies to
+	FallbackMode string  `json:"fallback_mode"` // Reply mode used when nothing matches
+	Message      string  `json:"message"`
 }
 
+// Errors of loadQA, reported to the caller as they are
+var (
+	errLoadQuestions = errors.New("Failed to load Q&A pairs")
+	errInitMatcher   = errors.New("Failed to initialize Q&A matcher")
+)
+
 // HandleLoadQA loads Q&A pairs into memory for a chat session
 func (h *ChatHandler) HandleLoadQA(w http.ResponseWriter, r *http.Request) {
 	// Only allow POST requests
@@ -74,12 +104,26 @@ func (h *ChatHandler) HandleLoadQA(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set default limit
-	if req.Limit <= 0 {
-		req.Limit = 20
+	fallbackMode, err := hub.ParseFallbackMode(req.FallbackMode)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid fallback_mode", "message": err.Error()})
+		return
 	}
-	if req.Limit > 100 {
-		req.Limit = 100 // Cap at 100 for performance
+	metric, err := qamatcher.ParseMetric(req.Metric)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid metric", "message": err.Error()})
+		return
+	}
+	if req.Threshold != nil {
+		if err := metric.ValidateThreshold(*req.Threshold); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid threshold", "message": err.Error()})
+			return
+		}
+	}
+	plan, _ := h.tiers.Resolve(r)
+	if fallbackMode == hub.FallbackModeLLM && plan.LLMClient == nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "LLM fallback is not configured on this server"})
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
@@ -92,12 +136,85 @@ func (h *ChatHandler) HandleLoadQA(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response, err := h.loadQA(ctx, client, plan, req, fallbackMode, metric)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// LoadQAForClient loads Q&A pairs for a load_qa WebSocket message, with the same rules
+// as HandleLoadQA. The tier is resolved from the client's upgrade request. Install it with
+// hub.SetQALoader.
+func (h *ChatHandler) LoadQAForClient(ctx context.Context, client *hub.Client, req hub.QALoadRequest) (*hub.QALoadResult, error) {
+	fallbackMode, err := hub.ParseFallbackMode(req.FallbackMode)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid fallback_mode: %v", err)
+	}
+	metric, err := qamatcher.ParseMetric(req.Metric)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid metric: %v", err)
+	}
+	if req.Threshold != nil {
+		if err := metric.ValidateThreshold(*req.Threshold); err != nil {
+			return nil, fmt.Errorf("Invalid threshold: %v", err)
+		}
+	}
+
+	// Clients whose upgrade request is unknown are treated as anonymous
+	r := client.UpgradeRequest()
+	if r == nil {
+		r = &http.Request{Header: make(http.Header)}
+	}
+	plan, _ := h.tiers.Resolve(r.WithContext(ctx))
+	if fallbackMode == hub.FallbackModeLLM && plan.LLMClient == nil {
+		return nil, errors.New("LLM fallback is not configured on this server")
+	}
+
+	response, err := h.loadQA(ctx, client, plan, LoadQARequest{
+		ClientID:       client.ID(),
+		UserID:         req.UserID,
+		JobID:          req.JobID,
+		Limit:          req.Limit,
+		FallbackMode:   req.FallbackMode,
+		CannedResponse: req.CannedResponse,
+		HistoryTurns:   req.HistoryTurns,
+		Stream:         req.Stream,
+		Metric:         req.Metric,
+		Threshold:      req.Threshold,
+	}, fallbackMode, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hub.QALoadResult{
+		Count:        response.Count,
+		Threshold:    response.Threshold,
+		Metric:       response.Metric,
+		FallbackMode: response.FallbackMode,
+		Message:      response.Message,
+	}, nil
+}
+
+// loadQA loads the saved Q&A pairs of a job into the client's matcher and configures
+// its fallback reply, matching with the given metric. Errors are errLoadQuestions or
+// errInitMatcher; causes are logged.
+func (h *ChatHandler) loadQA(ctx context.Context, client *hub.Client, plan *tier.Plan, req LoadQARequest, fallbackMode hub.FallbackMode, metric qamatcher.Metric) (*LoadQAResponse, error) {
+	// Set default limit
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100 // Cap at 100 for performance
+	}
+
 	// Get saved questions for the specific user and job
 	questions, err := h.savedQuestionRepo.GetSavedQuestionsByJob(ctx, req.UserID, req.JobID)
 	if err != nil {
 		log.Printf("Error loading saved questions for user %s, job %s: %v", req.UserID, req.JobID, err)
-		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to load Q&A pairs"})
-		return
+		return nil, errLoadQuestions
 	}
 
 	// Limit the number of questions
@@ -105,39 +222,101 @@ func (h *ChatHandler) HandleLoadQA(w http.ResponseWriter, r *http.Request) {
 		questions = questions[:req.Limit]
 	}
 
+	// Configure the reply for unmatched messages, even when no Q&A pairs exist
+	fallback := &hub.FallbackConfig{
+		Mode:           fallbackMode,
+		CannedResponse: req.CannedResponse,
+		HistoryTurns:   req.HistoryTurns,
+		Stream:         req.Stream,
+	}
+	if fallbackMode == hub.FallbackModeLLM {
+		fallback.LLMClient = plan.LLMClient
+		fallback.Context = h.buildFallbackContext(ctx, req.JobID, questions)
+	}
+	client.SetFallback(fallback)
+
 	if len(questions) == 0 {
-		respondJSON(w, http.StatusOK, LoadQAResponse{
-			Success: true,
-			Count:   0,
-			Message: "No saved Q&A pairs found for this job",
-		})
-		return
+		return &LoadQAResponse{
+			Success:      true,
+			Count:        0,
+			Metric:       string(metric),
+			FallbackMode: string(fallbackMode),
+			Message:      "No saved Q&A pairs found for this job",
+		}, nil
+	}
+
+	threshold := metric.DefaultThreshold()
+	if req.Threshold != nil {
+		threshold = *req.Threshold
 	}
 
 	// Create a new embedding matcher
-	// Similarity threshold: 0.75 means 75% similarity required for a match
-	matcher := qamatcher.NewEmbeddingMatcher(h.embedder, 0.75)
+	matcher := qamatcher.NewEmbeddingMatcher(plan.Embedder, threshold, metric)
 
 	// Load questions into the matcher
 	if err := matcher.LoadQuestions(questions); err != nil {
 		log.Printf("Error loading questions into matcher: %v", err)
-		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to initialize Q&A matcher"})
-		return
+		return nil, errInitMatcher
 	}
 
 	// Set the matcher for this client
 	client.SetQAMatcher(matcher)
 
-	log.Printf("Loaded %d Q&A pairs for client %s (user: %s, job: %s, threshold: %.2f)",
-		matcher.Count(), req.ClientID, req.UserID, req.JobID, matcher.GetThreshold())
+	log.Printf("Loaded %d Q&A pairs for client %s (user: %s, job: %s, metric: %s, threshold: %.2f)",
+		matcher.Count(), client.ID(), req.UserID, req.JobID, metric, matcher.GetThreshold())
 
-	// Return success response
-	respondJSON(w, http.StatusOK, LoadQAResponse{
-		Success:   true,
-		Count:     matcher.Count(),
-		Threshold: matcher.GetThreshold(),
-		Message:   "Q&A pairs loaded successfully",
-	})
+	return &LoadQAResponse{
+		Success:      true,
+		Count:        matcher.Count(),
+		Threshold:    matcher.GetThreshold(),
+		Metric:       string(metric),
+		FallbackMode: string(fallbackMode),
+		Message:      "Q&A pairs loaded successfully",
+	}, nil
+}
+
+// buildFallbackContext summarizes the analyzed profile and target job for LLM fallback prompts
+func (h *ChatHandler) buildFallbackContext(ctx context.Context, jobID string, questions []*models.SavedInterviewQuestion) string {
+	var sb strings.Builder
+
+	// Target role comes from the saved questions
+	for _, q := range questions {
+		if q.JobTitle != nil && *q.JobTitle != "" {
+			sb.WriteString("Target role: " + *q.JobTitle)
+			if q.Company != nil && *q.Company != "" {
+				sb.WriteString(" at " + *q.Company)
+			}
+			sb.WriteString("\n")
+			break
+		}
+	}
+
+	if h.analysisRepo == nil {
+		return sb.String()
+	}
+
+	profile, err := h.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		log.Printf("Warning: Failed to load profile for fallback context (job %s): %v", jobID, err)
+		return sb.String()
+	}
+
+	if profile.Summary != nil {
+		sb.WriteString("Candidate summary: " + *profile.Summary + "\n")
+	}
+	if profile.TotalWorkYears != nil {
+		sb.WriteString(fmt.Sprintf("Years of experience: %.1f\n", *profile.TotalWorkYears))
+	}
+	for _, category := range models.SkillCategories(profile.Skills) {
+		if skills := profile.Skills[category]; len(skills) > 0 {
+			sb.WriteString(fmt.Sprintf("%s skills: %s\n", category, strings.Join(skills, ", ")))
+		}
+	}
+	for _, exp := range profile.Experience {
+		sb.WriteString(fmt.Sprintf("Experience: %s at %s\n", exp.Role, exp.Company))
+	}
+
+	return sb.String()
 }
 
 // HandleUnloadQA removes Q&A pairs from memory for a chat session
@@ -175,6 +354,7 @@ func (h *ChatHandler) HandleUnloadQA(w http.ResponseWriter, r *http.Request) {
 		matcher.Clear()
 	}
 	client.SetQAMatcher(nil)
+	client.SetFallback(nil)
 
 	log.Printf("Unloaded Q&A pairs for client %s", req.ClientID)
 
@@ -1,27 +1,64 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"mime"
 	"net/http"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/your-org/websocket-server/internal/exporter"
 	"github.com/your-org/websocket-server/internal/repository"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
+// Default limits for audio messages. They are independent of the WebSocket read limit
+// since audio is only sent over HTTP.
+const (
+	DefaultMaxAudioBytes      = 5 * 1024 * 1024 // 5 MB of decoded audio
+	DefaultMaxAudioDurationMs = 5 * 60 * 1000   // 5 minutes
+)
+
+// audioRequestOverhead is the room left in an audio request body for fields other
+// than the audio data, such as the transcript
+const audioRequestOverhead = 64 * 1024
+
+// AudioLimits bounds the size and length of audio messages
+type AudioLimits struct {
+	MaxBytes      int // Decoded audio size (0 = DefaultMaxAudioBytes)
+	MaxDurationMs int // Declared clip length (0 = DefaultMaxAudioDurationMs)
+}
+
 // ChatMessageHandler handles chat message HTTP requests
 type ChatMessageHandler struct {
-	repo repository.ChatMessageRepository
+	repo        repository.ChatMessageRepository
+	transcripts *exporter.TranscriptExporter
+	audioLimits AudioLimits
+	auth        Authenticator // Resolves the caller for conversation exports
 }
 
-// NewChatMessageHandler creates a new chat message handler
-func NewChatMessageHandler(repo repository.ChatMessageRepository) *ChatMessageHandler {
-	return &ChatMessageHandler{repo: repo}
+// NewChatMessageHandler creates a new chat message handler. A nil transcript exporter
+// uses one stamped by the system clock; zero audio limits use the defaults.
+func NewChatMessageHandler(repo repository.ChatMessageRepository, transcripts *exporter.TranscriptExporter, audioLimits AudioLimits, auth Authenticator) *ChatMessageHandler {
+	if transcripts == nil {
+		transcripts = exporter.NewTranscriptExporter(nil)
+	}
+	if audioLimits.MaxBytes <= 0 {
+		audioLimits.MaxBytes = DefaultMaxAudioBytes
+	}
+	if audioLimits.MaxDurationMs <= 0 {
+		audioLimits.MaxDurationMs = DefaultMaxAudioDurationMs
+	}
+	return &ChatMessageHandler{repo: repo, transcripts: transcripts, audioLimits: audioLimits, auth: auth}
 }
 
 // HandleSendTextMessage handles POST /api/chat/message/text
@@ -52,8 +89,13 @@ func (h *ChatMessageHandler) HandleSendTextMessage(w http.ResponseWriter, r *htt
 	}
 
 	// Add metadata if provided
-	if req.Metadata != nil {
-		metaBytes, _ := json.Marshal(req.Metadata)
+	metadata, err := models.ParseChatMessageMetadata(req.Metadata)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid metadata", "message": err.Error()})
+		return
+	}
+	if metadata != nil {
+		metaBytes, _ := json.Marshal(metadata)
 		msg.Metadata = metaBytes
 	}
 
@@ -76,8 +118,17 @@ func (h *ChatMessageHandler) HandleSendAudioMessage(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Limit request body size to the largest allowed clip once base64 encoded
+	maxBody := int64(base64.StdEncoding.EncodedLen(h.audioLimits.MaxBytes)) + audioRequestOverhead
+	r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+
 	var req models.SendAudioMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.respondAudioTooLarge(w)
+			return
+		}
 		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 		return
 	}
@@ -87,18 +138,34 @@ func (h *ChatMessageHandler) HandleSendAudioMessage(w http.ResponseWriter, r *ht
 		return
 	}
 
+	if req.DurationMs > h.audioLimits.MaxDurationMs {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error":   "Audio too long",
+			"message": fmt.Sprintf("audio must be at most %d ms long", h.audioLimits.MaxDurationMs),
+		})
+		return
+	}
+
 	// Decode base64 audio data
 	audioBytes, err := base64.StdEncoding.DecodeString(req.AudioData)
 	if err != nil {
 		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid audio data encoding"})
 		return
 	}
+	if len(audioBytes) > h.audioLimits.MaxBytes {
+		h.respondAudioTooLarge(w)
+		return
+	}
 
 	// Create metadata
 	metadata := models.ChatMessageMetadata{
 		DurationMs: req.DurationMs,
 		MimeType:   req.MimeType,
 	}
+	if err := metadata.Validate(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid metadata", "message": err.Error()})
+		return
+	}
 	metaBytes, _ := json.Marshal(metadata)
 
 	// Create message
@@ -127,7 +194,15 @@ func (h *ChatMessageHandler) HandleSendAudioMessage(w http.ResponseWriter, r *ht
 	respondJSON(w, http.StatusCreated, msg.ToResponse("/api/chat/message/audio"))
 }
 
-// HandleGetAudioContent handles GET /api/chat/message/audio?id=X
+// respondAudioTooLarge reports an audio message over the size limit
+func (h *ChatMessageHandler) respondAudioTooLarge(w http.ResponseWriter) {
+	respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+		"error":   "Audio too large",
+		"message": fmt.Sprintf("audio exceeds %d bytes", h.audioLimits.MaxBytes),
+	})
+}
+
+// HandleGetAudioContent handles GET /api/chat/message/audio/content?id=X, including Range requests
 func (h *ChatMessageHandler) HandleGetAudioContent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -179,21 +254,29 @@ func (h *ChatMessageHandler) HandleGetAudioContent(w http.ResponseWriter, r *htt
 		}
 	}
 
-	// Set headers and return audio
+	// ServeContent answers Range requests with 206 and the requested bytes (or 416 when
+	// the range can't be satisfied), so players can seek, and sends the whole audio otherwise
 	w.Header().Set("Content-Type", mimeType)
-	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
 	w.Header().Set("Cache-Control", "private, max-age=3600")
-	w.Write(content)
+	http.ServeContent(w, r, "", msg.CreatedAt, bytes.NewReader(content))
 }
 
-// HandleGetMessages handles GET /api/chat/messages
+// maxSessionFilter caps how many sessions one message list request may filter by
+const maxSessionFilter = 50
+
+// HandleGetMessages handles GET /api/chat/messages. It lists the user's messages in the
+// given sessions (session_id, repeated or comma-separated), or else the user's
+// conversation with the system user across all sessions, optionally only those of one
+// msg_type. Session messages are listed oldest first, the conversation newest first.
 func (h *ChatMessageHandler) HandleGetMessages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userIDStr := r.URL.Query().Get("user_id")
+	query := r.URL.Query()
+
+	userIDStr := query.Get("user_id")
 	if userIDStr == "" {
 		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id is required"})
 		return
@@ -205,31 +288,45 @@ func (h *ChatMessageHandler) HandleGetMessages(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Parse pagination
-	limit := 50
-	offset := 0
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
+	filter := repository.MessageFilter{UserID: userID}
+
+	if msgType := query.Get("msg_type"); msgType != "" {
+		var ok bool
+		filter.MsgType, ok = parseMessageType(msgType)
+		if !ok {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error":   "Invalid msg_type",
+				"message": "Supported types: text, image, audio, video",
+			})
+			return
 		}
 	}
-	if o := r.URL.Query().Get("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+
+	filter.SessionIDs = parseSessionIDs(query["session_id"])
+	if len(filter.SessionIDs) > maxSessionFilter {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Too many sessions",
+			"message": fmt.Sprintf("At most %d session_id values are allowed", maxSessionFilter),
+		})
+		return
 	}
+	if len(filter.SessionIDs) > 0 {
+		filter.OldestFirst = true
+	} else {
+		systemUserID := models.SystemUserID
+		filter.WithUserID = &systemUserID
+	}
+
+	// Parse pagination
+	page := ParsePagination(r, 50, 100)
 
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	// Check for session filter
-	sessionID := r.URL.Query().Get("session_id")
-
-	var messages []*models.ChatMessage
-	if sessionID != "" {
-		messages, err = h.repo.GetMessagesBySession(ctx, sessionID, limit, offset)
-	} else {
-		messages, err = h.repo.GetConversation(ctx, userID, models.SystemUserID, limit, offset)
+	messages, err := h.repo.FindMessages(ctx, filter, page.Limit, page.Offset)
+	var total int
+	if err == nil {
+		total, err = h.repo.CountMatchingMessages(ctx, filter)
 	}
 
 	if err != nil {
@@ -245,12 +342,34 @@ func (h *ChatMessageHandler) HandleGetMessages(w http.ResponseWriter, r *http.Re
 		responses[i] = msg.ToResponse(audioBaseURL)
 	}
 
-	respondJSON(w, http.StatusOK, models.GetMessagesResponse{
-		Messages: responses,
-		Total:    len(responses),
-		Limit:    limit,
-		Offset:   offset,
-	})
+	respondJSON(w, http.StatusOK, NewPage(responses, len(responses), total, page))
+}
+
+// parseMessageType maps a msg_type query parameter to a message type
+func parseMessageType(value string) (models.MessageType, bool) {
+	switch msgType := models.MessageType(strings.ToLower(strings.TrimSpace(value))); msgType {
+	case models.MessageTypeText, models.MessageTypeImage, models.MessageTypeAudio, models.MessageTypeVideo:
+		return msgType, true
+	default:
+		return "", false
+	}
+}
+
+// parseSessionIDs collects the session IDs of repeated and comma-separated session_id
+// parameters, without blanks or duplicates
+func parseSessionIDs(values []string) []string {
+	var sessionIDs []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, sessionID := range strings.Split(value, ",") {
+			sessionID = strings.TrimSpace(sessionID)
+			if sessionID != "" && !seen[sessionID] {
+				seen[sessionID] = true
+				sessionIDs = append(sessionIDs, sessionID)
+			}
+		}
+	}
+	return sessionIDs
 }
 
 // HandleSendSystemMessage creates a system message (for Q&A matches, etc.)
@@ -261,10 +380,10 @@ func (h *ChatMessageHandler) HandleSendSystemMessage(w http.ResponseWriter, r *h
 	}
 
 	var req struct {
-		ToUserID    int                        `json:"to_user_id"`
-		TextContent string                     `json:"text_content"`
-		Metadata    *models.ChatMessageMetadata `json:"metadata,omitempty"`
-		SessionID   *string                    `json:"session_id,omitempty"`
+		ToUserID    int             `json:"to_user_id"`
+		TextContent string          `json:"text_content"`
+		Metadata    json.RawMessage `json:"metadata,omitempty"`
+		SessionID   *string         `json:"session_id,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -281,8 +400,13 @@ func (h *ChatMessageHandler) HandleSendSystemMessage(w http.ResponseWriter, r *h
 		SessionID:   req.SessionID,
 	}
 
-	if req.Metadata != nil {
-		metaBytes, _ := json.Marshal(req.Metadata)
+	metadata, err := models.ParseChatMessageMetadata(req.Metadata)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid metadata", "message": err.Error()})
+		return
+	}
+	if metadata != nil {
+		metaBytes, _ := json.Marshal(metadata)
 		msg.Metadata = metaBytes
 	}
 
@@ -297,3 +421,233 @@ func (h *ChatMessageHandler) HandleSendSystemMessage(w http.ResponseWriter, r *h
 
 	respondJSON(w, http.StatusCreated, msg.ToResponse("/api/chat/message/audio"))
 }
+
+// maxTranscriptMessages caps how many messages a conversation export may contain
+const maxTranscriptMessages = 5000
+
+// transcriptPageSize is how many messages are loaded per query when exporting
+const transcriptPageSize = 500
+
+// unsafeFileNameChars matches characters not allowed in export file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// HandleExportConversation handles GET /api/chat/export. It downloads a session
+// (session_id) or the conversation between two users (user_id, with_user_id defaulting
+// to the system user) as a PDF, Markdown or plain text transcript, oldest turn first.
+// The caller must be signed in and take part in the conversation: user_id defaults to
+// the caller, and every message of a session must be from or to them.
+func (h *ChatMessageHandler) HandleExportConversation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	caller, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	query := r.URL.Query()
+
+	format, ok := parseTranscriptFormat(query.Get("format"))
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid format",
+			"message": "Supported formats: pdf, md, txt",
+		})
+		return
+	}
+
+	sessionID := query.Get("session_id")
+	userID, withUserID := caller, models.SystemUserID
+	if sessionID == "" {
+		var err error
+		if userIDStr := query.Get("user_id"); userIDStr != "" {
+			userID, err = strconv.Atoi(userIDStr)
+			if err != nil {
+				respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+				return
+			}
+		}
+
+		if withUserIDStr := query.Get("with_user_id"); withUserIDStr != "" {
+			withUserID, err = strconv.Atoi(withUserIDStr)
+			if err != nil {
+				respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid with_user_id"})
+				return
+			}
+		}
+
+		if userID != caller && withUserID != caller {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "You do not have access to this conversation"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	// Check the size first so oversized conversations aren't loaded
+	var total int
+	var err error
+	if sessionID != "" {
+		total, err = h.repo.CountMessagesBySession(ctx, sessionID)
+	} else {
+		total, err = h.repo.CountConversation(ctx, userID, withUserID)
+	}
+	if err != nil {
+		log.Printf("Error counting messages for export: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to export conversation"})
+		return
+	}
+
+	// A session isn't tied to a user, so the caller must take part in all of it. Sessions
+	// that don't exist are refused the same way, so their existence isn't revealed.
+	if sessionID != "" {
+		involving, err := h.repo.CountMatchingMessages(ctx, repository.MessageFilter{UserID: caller, SessionIDs: []string{sessionID}})
+		if err != nil {
+			log.Printf("Error counting messages for export: %v", err)
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to export conversation"})
+			return
+		}
+		if total == 0 || involving != total {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "You do not have access to this conversation"})
+			return
+		}
+	}
+
+	if total == 0 {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Conversation not found"})
+		return
+	}
+	if total > maxTranscriptMessages {
+		respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+			"error":   "Conversation too long",
+			"message": fmt.Sprintf("Conversations of at most %d messages can be exported", maxTranscriptMessages),
+		})
+		return
+	}
+
+	var messages []*models.ChatMessage
+	for offset := 0; offset < total; offset += transcriptPageSize {
+		var page []*models.ChatMessage
+		if sessionID != "" {
+			page, err = h.repo.GetMessagesBySession(ctx, sessionID, transcriptPageSize, offset)
+		} else {
+			page, err = h.repo.GetConversation(ctx, userID, withUserID, transcriptPageSize, offset)
+		}
+		if err != nil {
+			log.Printf("Error getting messages for export: %v", err)
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to export conversation"})
+			return
+		}
+		messages = append(messages, page...)
+		if len(page) < transcriptPageSize {
+			break
+		}
+	}
+
+	// Messages may have been added to the session since it was counted
+	if sessionID != "" && !participatesInAll(caller, messages) {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "You do not have access to this conversation"})
+		return
+	}
+
+	var title, fileName string
+	if sessionID != "" {
+		title = fmt.Sprintf("Conversation transcript (session %s)", sessionID)
+		fileName = "conversation_" + unsafeFileNameChars.ReplaceAllString(sessionID, "_")
+	} else {
+		title = fmt.Sprintf("Conversation transcript (user %d)", userID)
+		fileName = fmt.Sprintf("conversation_user_%d", userID)
+	}
+
+	data, err := h.transcripts.Export(ctx, buildTranscript(title, messages), format)
+	if err != nil {
+		log.Printf("Error exporting conversation: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error":   "Export failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	fileName += h.transcripts.GetFileExtension(format)
+	w.Header().Set("Content-Type", h.transcripts.GetContentType(format))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error writing conversation export: %v", err)
+	}
+}
+
+// participatesInAll reports whether every message is from or to userID
+func participatesInAll(userID int, messages []*models.ChatMessage) bool {
+	for _, msg := range messages {
+		if msg.UserID != userID && msg.ToUserID != userID {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTranscriptFormat maps a format query parameter to a transcript format (default: Markdown)
+func parseTranscriptFormat(formatStr string) (exporter.Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(formatStr)) {
+	case "", "md", "markdown":
+		return exporter.FormatMarkdown, true
+	case "txt", "text":
+		return exporter.FormatText, true
+	case "pdf":
+		return exporter.FormatPDF, true
+	default:
+		return "", false
+	}
+}
+
+// buildTranscript orders messages by creation time and labels each turn with its speaker.
+// Audio messages contribute their transcript.
+func buildTranscript(title string, messages []*models.ChatMessage) *exporter.Transcript {
+	sorted := make([]*models.ChatMessage, len(messages))
+	copy(sorted, messages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	transcript := &exporter.Transcript{
+		Title:   title,
+		Entries: make([]exporter.TranscriptEntry, 0, len(sorted)),
+	}
+	for _, msg := range sorted {
+		entry := exporter.TranscriptEntry{
+			Speaker:   fmt.Sprintf("User %d", msg.UserID),
+			Audio:     msg.MsgType == models.MessageTypeAudio,
+			CreatedAt: msg.CreatedAt,
+		}
+		if msg.IsFromSystem() {
+			entry.Speaker = "Assistant"
+		}
+		if msg.TextContent != nil {
+			entry.Text = *msg.TextContent
+		}
+
+		if len(msg.Metadata) > 0 {
+			var meta models.ChatMessageMetadata
+			if json.Unmarshal(msg.Metadata, &meta) == nil {
+				entry.DurationMs = meta.DurationMs
+				if meta.FromQA {
+					entry.MatchedQuestion = meta.MatchedQuestion
+				}
+			}
+		}
+
+		transcript.Entries = append(transcript.Entries, entry)
+	}
+
+	return transcript
+}
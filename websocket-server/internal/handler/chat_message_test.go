@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestSendMessageMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		system       bool
+		body         string
+		wantStatus   int
+		wantMetadata string // Stored metadata, "" for none
+	}{
+		{
+			name:         "text with metadata",
+			path:         "/api/chat/message/text",
+			body:         `{"user_id":1,"to_user_id":2,"text_content":"hi","metadata":{"file_name":"cv.pdf","file_size":10}}`,
+			wantStatus:   http.StatusCreated,
+			wantMetadata: `{"file_name":"cv.pdf","file_size":10}`,
+		},
+		{
+			name:       "text without metadata",
+			path:       "/api/chat/message/text",
+			body:       `{"user_id":1,"to_user_id":2,"text_content":"hi"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "text with unknown field",
+			path:       "/api/chat/message/text",
+			body:       `{"user_id":1,"to_user_id":2,"text_content":"hi","metadata":{"admin":true}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "system Q&A match normalized",
+			path:         "/api/chat/message/system",
+			system:       true,
+			body:         `{"to_user_id":2,"text_content":"Answer","metadata":{"from_qa":true,"similarity":0.9,"question":"Why Go?"}}`,
+			wantStatus:   http.StatusCreated,
+			wantMetadata: `{"from_qa":true,"similarity":0.9,"matched_question":"Why Go?"}`,
+		},
+		{
+			name:       "system similarity out of range",
+			path:       "/api/chat/message/system",
+			system:     true,
+			body:       `{"to_user_id":2,"text_content":"Answer","metadata":{"from_qa":true,"similarity":95}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "system from_qa not a boolean",
+			path:       "/api/chat/message/system",
+			system:     true,
+			body:       `{"to_user_id":2,"text_content":"Answer","metadata":{"from_qa":"true"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeChatMessages{}
+			h := NewChatMessageHandler(repo, nil, AudioLimits{}, nil)
+			handler := h.HandleSendTextMessage
+			if tt.system {
+				handler = h.HandleSendSystemMessage
+			}
+
+			w := serve(handler, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantStatus != http.StatusCreated {
+				var body map[string]string
+				decodeBody(t, w, &body)
+				if body["error"] != "Invalid metadata" || body["message"] == "" {
+					t.Errorf("response = %v, want an invalid metadata error with a message", body)
+				}
+				if len(repo.messages) != 0 {
+					t.Errorf("message with invalid metadata was saved")
+				}
+				return
+			}
+
+			if len(repo.messages) != 1 {
+				t.Fatalf("saved %d messages, want 1", len(repo.messages))
+			}
+			stored := repo.messages[0].Metadata
+			if tt.wantMetadata == "" {
+				if stored != nil {
+					t.Errorf("metadata = %s, want none", stored)
+				}
+				return
+			}
+			if !jsonEqual(t, stored, []byte(tt.wantMetadata)) {
+				t.Errorf("metadata = %s, want %s", stored, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+// jsonEqual reports whether two JSON documents hold the same values
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("decoding %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+func TestHandleExportConversation(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 1, 2, 3, minute, 0, 0, time.UTC) }
+	session := strPtr("s1")
+	repo := &fakeChatMessages{messages: []*models.ChatMessage{
+		// Stored out of order; the transcript is oldest first
+		{ID: 3, UserID: 7, ToUserID: models.SystemUserID, MsgType: models.MessageTypeText, TextContent: strPtr("Thanks!"), SessionID: session, CreatedAt: at(3)},
+		{ID: 1, UserID: 7, ToUserID: models.SystemUserID, MsgType: models.MessageTypeAudio, TextContent: strPtr("Why Go?"), Metadata: json.RawMessage(`{"duration_ms":4000}`), SessionID: session, CreatedAt: at(1)},
+		{ID: 2, UserID: models.SystemUserID, ToUserID: 7, MsgType: models.MessageTypeText, TextContent: strPtr("It compiles fast."), Metadata: json.RawMessage(`{"from_qa":true,"matched_question":"Why do you like Go?"}`), SessionID: session, CreatedAt: at(2)},
+		{ID: 4, UserID: 8, ToUserID: models.SystemUserID, MsgType: models.MessageTypeText, TextContent: strPtr("Someone else"), SessionID: strPtr("s2"), CreatedAt: at(4)},
+		{ID: 5, UserID: 7, ToUserID: models.SystemUserID, MsgType: models.MessageTypeText, TextContent: strPtr("Hi"), SessionID: strPtr("s3"), CreatedAt: at(5)},
+		{ID: 6, UserID: 8, ToUserID: models.SystemUserID, MsgType: models.MessageTypeText, TextContent: strPtr("Hello"), SessionID: strPtr("s3"), CreatedAt: at(6)},
+	}}
+	for i := 0; i <= maxTranscriptMessages; i++ {
+		repo.messages = append(repo.messages, &models.ChatMessage{ID: int64(100 + i), UserID: 8, ToUserID: models.SystemUserID, MsgType: models.MessageTypeText, TextContent: strPtr("More"), SessionID: strPtr("s4"), CreatedAt: at(7)})
+	}
+	h := NewChatMessageHandler(repo, nil, AudioLimits{}, headerAuth{})
+
+	wantTurns := []string{
+		"[2024-01-02 03:01:00 UTC] User 7 (audio, 4s): Why Go?",
+		"[2024-01-02 03:02:00 UTC] Assistant: It compiles fast.\n    Matched question: Why do you like Go?",
+		"[2024-01-02 03:03:00 UTC] User 7: Thanks!",
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		caller     int // 0 for no session
+		wantStatus int
+		wantFile   string
+	}{
+		{"own conversation", "format=txt", 7, http.StatusOK, "conversation_user_7.txt"},
+		{"session", "format=txt&session_id=s1", 7, http.StatusOK, "conversation_s1.txt"},
+		{"other user's conversation", "format=txt&user_id=8", 7, http.StatusForbidden, ""},
+		{"other user's session", "format=txt&session_id=s1", 8, http.StatusForbidden, ""},
+		{"unknown session", "format=txt&session_id=s9", 7, http.StatusForbidden, ""},
+		{"session the caller is only partly in", "format=txt&session_id=s3", 7, http.StatusForbidden, ""},
+		{"other user's oversized session", "format=txt&session_id=s4", 7, http.StatusForbidden, ""},
+		{"own oversized session", "format=txt&session_id=s4", 8, http.StatusRequestEntityTooLarge, ""},
+		{"unknown conversation", "format=txt&user_id=7&with_user_id=9", 7, http.StatusNotFound, ""},
+		{"invalid format", "format=docx", 7, http.StatusBadRequest, ""},
+		{"no session", "format=txt", 0, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/chat/export?"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleExportConversation, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, tt.wantFile) {
+				t.Errorf("Content-Disposition = %q, want file %s", got, tt.wantFile)
+			}
+
+			// User and system turns appear oldest first
+			rest := w.Body.String()
+			for _, want := range wantTurns {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("transcript lacks %q after the previous turn:\n%s", want, w.Body.String())
+				}
+				rest = rest[i+len(want):]
+			}
+			if strings.Contains(w.Body.String(), "Someone else") {
+				t.Errorf("transcript includes another conversation:\n%s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleSendAudioMessageLimits(t *testing.T) {
+	limits := AudioLimits{MaxBytes: 1024, MaxDurationMs: 60000}
+
+	// audioRequest returns a request body with size bytes of audio lasting durationMs
+	audioRequest := func(size, durationMs int) string {
+		audio := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x1a}, size))
+		return fmt.Sprintf(`{"user_id":1,"to_user_id":10,"audio_data":%q,"duration_ms":%d,"mime_type":"audio/webm"}`, audio, durationMs)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{"at the limits", audioRequest(1024, 60000), http.StatusCreated, ""},
+		{"one byte over", audioRequest(1025, 1000), http.StatusRequestEntityTooLarge, "Audio too large"},
+		{"body far over the limit", audioRequest(1024*1024, 1000), http.StatusRequestEntityTooLarge, "Audio too large"},
+		{"one millisecond too long", audioRequest(10, 60001), http.StatusRequestEntityTooLarge, "Audio too long"},
+		{"no audio", audioRequest(0, 1000), http.StatusBadRequest, "Audio data is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeChatMessages{}
+			h := NewChatMessageHandler(repo, nil, limits, nil)
+
+			w := serve(h.HandleSendAudioMessage, httptest.NewRequest(http.MethodPost, "/api/chat/message/audio", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantStatus == http.StatusCreated {
+				if len(repo.messages) != 1 || len(repo.messages[0].Content) != 1024 {
+					t.Errorf("stored messages = %+v, want one 1024-byte clip", repo.messages)
+				}
+				return
+			}
+
+			var body map[string]string
+			decodeBody(t, w, &body)
+			if body["error"] != tt.wantError {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+			}
+			if len(repo.messages) != 0 {
+				t.Errorf("rejected clip was stored")
+			}
+		})
+	}
+}
+
+func TestNewChatMessageHandlerDefaultAudioLimits(t *testing.T) {
+	h := NewChatMessageHandler(&fakeChatMessages{}, nil, AudioLimits{}, nil)
+	if h.audioLimits.MaxBytes != DefaultMaxAudioBytes || h.audioLimits.MaxDurationMs != DefaultMaxAudioDurationMs {
+		t.Errorf("limits = %+v, want the defaults", h.audioLimits)
+	}
+}
+
+func TestHandleGetMessagesFilters(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 1, 2, 3, minute, 0, 0, time.UTC) }
+	message := func(from, to int, msgType models.MessageType, session string, minute int) *models.ChatMessage {
+		return &models.ChatMessage{UserID: from, ToUserID: to, MsgType: msgType, TextContent: strPtr("hi"), SessionID: strPtr(session), CreatedAt: at(minute)}
+	}
+	system := models.SystemUserID
+	repo := &fakeChatMessages{}
+	for _, msg := range []*models.ChatMessage{
+		message(7, system, models.MessageTypeAudio, "s1", 1),
+		message(system, 7, models.MessageTypeText, "s1", 2),
+		message(7, system, models.MessageTypeText, "s2", 3),
+		message(7, system, models.MessageTypeAudio, "s2", 4),
+		message(7, system, models.MessageTypeAudio, "s3", 5),
+		message(8, system, models.MessageTypeAudio, "s1", 6), // Another user's
+	} {
+		repo.CreateMessage(context.Background(), msg)
+	}
+	h := NewChatMessageHandler(repo, nil, AudioLimits{}, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int64
+		wantTotal  int
+	}{
+		{"conversation newest first", "user_id=7", http.StatusOK, []int64{5, 4, 3, 2, 1}, 5},
+		{"audio across sessions", "user_id=7&msg_type=audio", http.StatusOK, []int64{5, 4, 1}, 3},
+		{"type is case insensitive", "user_id=7&msg_type=AUDIO", http.StatusOK, []int64{5, 4, 1}, 3},
+		{"one session oldest first", "user_id=7&session_id=s1", http.StatusOK, []int64{1, 2}, 2},
+		{"repeated sessions", "user_id=7&session_id=s1&session_id=s2", http.StatusOK, []int64{1, 2, 3, 4}, 4},
+		{"comma-separated sessions", "user_id=7&session_id=s2,+s3,s2,", http.StatusOK, []int64{3, 4, 5}, 3},
+		{"audio in sessions", "user_id=7&session_id=s1,s2&msg_type=audio", http.StatusOK, []int64{1, 4}, 2},
+		{"paginated", "user_id=7&msg_type=audio&limit=2&offset=1", http.StatusOK, []int64{4, 1}, 3},
+		{"invalid type", "user_id=7&msg_type=gif", http.StatusBadRequest, nil, 0},
+		{"invalid user", "user_id=abc", http.StatusBadRequest, nil, 0},
+		{"missing user", "msg_type=audio", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleGetMessages, httptest.NewRequest(http.MethodGet, "/api/chat/messages?"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page struct {
+				Items   []models.ChatMessageResponse `json:"items"`
+				Total   int                          `json:"total"`
+				HasMore bool                         `json:"has_more"`
+			}
+			decodeBody(t, w, &page)
+			var ids []int64
+			for _, item := range page.Items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("message IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if page.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", page.Total, tt.wantTotal)
+			}
+		})
+	}
+
+	t.Run("too many sessions", func(t *testing.T) {
+		sessions := make([]string, maxSessionFilter+1)
+		for i := range sessions {
+			sessions[i] = fmt.Sprintf("s%d", i)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/api/chat/messages?user_id=7&session_id="+strings.Join(sessions, ","), nil)
+		if w := serve(h.HandleGetMessages, r); w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestParseSessionIDs(t *testing.T) {
+	got := parseSessionIDs([]string{"s1, s2", "", "s2,,s3 ", " s1"})
+	if want := []string{"s1", "s2", "s3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSessionIDs = %q, want %q", got, want)
+	}
+	if got := parseSessionIDs(nil); got != nil {
+		t.Errorf("parseSessionIDs(nil) = %q, want nil", got)
+	}
+}
+
+func TestHandleGetAudioContentRanges(t *testing.T) {
+	audio := []byte("0123456789abcdefghij")
+	repo := &fakeChatMessages{}
+	repo.CreateMessage(context.Background(), &models.ChatMessage{
+		MsgType:  models.MessageTypeAudio,
+		Content:  audio,
+		Metadata: []byte(`{"mime_type":"audio/ogg"}`),
+	})
+	repo.CreateMessage(context.Background(), &models.ChatMessage{MsgType: models.MessageTypeText})
+	h := NewChatMessageHandler(repo, nil, AudioLimits{}, nil)
+
+	tests := []struct {
+		name             string
+		id               string
+		rangeHeader      string
+		wantStatus       int
+		wantBody         string
+		wantContentRange string
+	}{
+		{"full request", "1", "", http.StatusOK, string(audio), ""},
+		{"valid range", "1", "bytes=5-9", http.StatusPartialContent, "56789", "bytes 5-9/20"},
+		{"open-ended range", "1", "bytes=15-", http.StatusPartialContent, "fghij", "bytes 15-19/20"},
+		{"suffix range", "1", "bytes=-3", http.StatusPartialContent, "hij", "bytes 17-19/20"},
+		{"unsatisfiable range", "1", "bytes=20-30", http.StatusRequestedRangeNotSatisfiable, "", "bytes */20"},
+		{"text message", "2", "", http.StatusBadRequest, "", ""},
+		{"unknown message", "3", "", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/chat/messages/audio?id="+tt.id, nil)
+			if tt.rangeHeader != "" {
+				r.Header.Set("Range", tt.rangeHeader)
+			}
+			w := serve(h.HandleGetAudioContent, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+			if tt.wantStatus != http.StatusOK && tt.wantStatus != http.StatusPartialContent {
+				return
+			}
+
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Length"); got != strconv.Itoa(len(tt.wantBody)) {
+				t.Errorf("Content-Length = %s, want %d", got, len(tt.wantBody))
+			}
+			if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Errorf("Accept-Ranges = %q, want bytes", got)
+			}
+			if got := w.Header().Get("Content-Type"); got != "audio/ogg" {
+				t.Errorf("Content-Type = %q, want the stored MIME type", got)
+			}
+		})
+	}
+}
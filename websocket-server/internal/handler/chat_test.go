@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/hub"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestQAThresholdMustBeOnTheMetricsScale(t *testing.T) {
+	chat := NewChatHandler(nil, nil, nil, nil, nil, nil)
+	regression := NewQARegressionHandler(nil, nil, "secret")
+
+	tests := []struct {
+		name   string
+		metric string
+		body   string
+	}{
+		{"cosine above 1", "cosine", `"threshold": 1.5`},
+		{"default metric below -1", "", `"threshold": -2`},
+		{"euclidean above 0", "euclidean", `"threshold": 0.75`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := `"metric": "` + tt.metric + `", ` + tt.body
+
+			r := httptest.NewRequest(http.MethodPost, "/api/chat/load-qa",
+				strings.NewReader(`{"client_id": "c1", "user_id": "u1", "job_id": "j1", `+fields+`}`))
+			w := serve(chat.HandleLoadQA, r)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Invalid threshold") {
+				t.Errorf("load-qa: status %d, body %s; want 400 Invalid threshold", w.Code, w.Body)
+			}
+
+			r = httptest.NewRequest(http.MethodPost, "/api/admin/chat/qa-test",
+				strings.NewReader(`{"user_id": "u1", "queries": ["tell me"], `+fields+`}`))
+			r.Header.Set(AdminTokenHeader, "secret")
+			w = serve(regression.HandleRunQARegression, r)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Invalid threshold") {
+				t.Errorf("qa-test: status %d, body %s; want 400 Invalid threshold", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestLoadQAForClient(t *testing.T) {
+	repo := &fakeSavedQuestions{byJob: []*models.SavedInterviewQuestion{
+		{QuestionID: "q_intro", Question: "Tell me about yourself", Answer: "I build backends."},
+		{QuestionID: "q_conflict", Question: "How do you handle conflict?", Answer: "I listen first."},
+	}}
+	embedder := &vectorEmbedder{vectors: map[string][]float32{
+		"Tell me about yourself":      {1, 0},
+		"How do you handle conflict?": {0, 1},
+	}}
+	h := hub.NewHub(nil)
+	chat := NewChatHandler(h, repo, nil, embedder, nil, nil)
+
+	t.Run("loads the job's questions", func(t *testing.T) {
+		client := hub.NewClient(h, nil, "c1")
+		result, err := chat.LoadQAForClient(context.Background(), client, hub.QALoadRequest{UserID: "u1", JobID: "job_1", Limit: 5})
+		if err != nil {
+			t.Fatalf("LoadQAForClient: %v", err)
+		}
+		if result.Count != 2 || result.Metric != "cosine" || result.Threshold != 0.75 {
+			t.Errorf("result = %+v, want 2 pairs at the cosine default threshold", result)
+		}
+		if state := client.QAState(); !state.Loaded || state.QuestionCount != 2 {
+			t.Errorf("client state = %+v, want 2 questions loaded", state)
+		}
+	})
+
+	t.Run("limit applied", func(t *testing.T) {
+		client := hub.NewClient(h, nil, "c2")
+		result, err := chat.LoadQAForClient(context.Background(), client, hub.QALoadRequest{UserID: "u1", JobID: "job_1", Limit: 1})
+		if err != nil || result.Count != 1 {
+			t.Errorf("LoadQAForClient = %+v, %v; want 1 pair", result, err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		req  hub.QALoadRequest
+		want string
+	}{
+		{"unknown fallback mode", hub.QALoadRequest{UserID: "u1", JobID: "job_1", FallbackMode: "shout"}, "Invalid fallback_mode"},
+		{"unknown metric", hub.QALoadRequest{UserID: "u1", JobID: "job_1", Metric: "jaccard"}, "Invalid metric"},
+		{"LLM fallback without an LLM", hub.QALoadRequest{UserID: "u1", JobID: "job_1", FallbackMode: "llm"}, "LLM fallback is not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := hub.NewClient(h, nil, "c3")
+			if _, err := chat.LoadQAForClient(context.Background(), client, tt.req); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+			if client.QAState().Loaded {
+				t.Error("questions loaded despite the error")
+			}
+		})
+	}
+}
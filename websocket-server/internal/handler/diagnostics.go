@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/your-org/websocket-server/internal/hub"
+)
+
+// DiagnosticsHandler exposes admin-only views of in-memory server state
+type DiagnosticsHandler struct {
+	hub        *hub.Hub
+	adminToken string // Required in the X-Admin-Token header; empty disables the endpoints
+}
+
+// NewDiagnosticsHandler creates a new diagnostics handler instance
+func NewDiagnosticsHandler(hub *hub.Hub, adminToken string) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		hub:        hub,
+		adminToken: adminToken,
+	}
+}
+
+// HandleQAMatcherState handles GET /api/admin/chat/qa-state?client_id=X
+// Reports whether a Q&A matcher is loaded for the client, its size and threshold,
+// and the similarity of the client's last lookup
+func (h *DiagnosticsHandler) HandleQAMatcherState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	clientID := r.URL.Query().Get("client_id")
+	if clientID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required parameter: client_id"})
+		return
+	}
+
+	client := h.hub.FindClientByID(clientID)
+	if client == nil {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Client not found or not connected"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, client.QAState())
+}
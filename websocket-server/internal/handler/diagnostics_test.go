@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/hub"
+	"github.com/your-org/websocket-server/internal/qamatcher"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// connectedClient registers a client without a connection with a running hub
+func connectedClient(t *testing.T, h *hub.Hub, id string) *hub.Client {
+	t.Helper()
+
+	client := hub.NewClient(h, nil, id)
+	h.Register(client)
+	for deadline := time.Now().Add(5 * time.Second); h.FindClientByID(id) == nil; {
+		if time.Now().After(deadline) {
+			t.Fatalf("client %s was not registered", id)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return client
+}
+
+func TestHandleQAMatcherState(t *testing.T) {
+	h := hub.NewHub(nil)
+	go h.Run()
+
+	connectedClient(t, h, "unloaded")
+	loaded := connectedClient(t, h, "loaded")
+
+	matcher := qamatcher.NewEmbeddingMatcher(analyzer.NewPlaceholderEmbeddingGenerator(16), 0.8, qamatcher.MetricCosine)
+	if err := matcher.LoadQuestions([]*models.SavedInterviewQuestion{
+		{QuestionID: "q1", Question: "Why Go?", Answer: "Simplicity."},
+		{QuestionID: "q2", Question: "Why SQL?", Answer: "Joins."},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	loaded.SetQAMatcher(matcher)
+
+	diagnostics := NewDiagnosticsHandler(h, "secret")
+	get := func(clientID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/admin/chat/qa-state?client_id="+clientID, nil)
+		r.Header.Set(AdminTokenHeader, "secret")
+		return serve(diagnostics.HandleQAMatcherState, r)
+	}
+
+	t.Run("unloaded client", func(t *testing.T) {
+		w := get("unloaded")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		var state hub.QAState
+		decodeBody(t, w, &state)
+		if state.ClientID != "unloaded" || state.Loaded || state.QuestionCount != 0 || state.LastSimilarity != nil {
+			t.Errorf("state = %+v, want nothing loaded", state)
+		}
+	})
+
+	t.Run("loaded client", func(t *testing.T) {
+		w := get("loaded")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		var state hub.QAState
+		decodeBody(t, w, &state)
+		if !state.Loaded || state.QuestionCount != 2 || state.Threshold != 0.8 {
+			t.Errorf("state = %+v, want 2 questions at threshold 0.8", state)
+		}
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		if w := get("gone"); w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	})
+
+	t.Run("missing client id", func(t *testing.T) {
+		if w := get(""); w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("requires the admin token", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/admin/chat/qa-state?client_id=loaded", nil)
+		if w := serve(diagnostics.HandleQAMatcherState, r); w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", w.Code)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+// Lifetime of signed download links
+const (
+	DefaultDownloadLinkTTL = 15 * time.Minute
+	MaxDownloadLinkTTL     = 24 * time.Hour
+)
+
+// minDownloadLinkKeyBytes is the shortest signing key accepted
+const minDownloadLinkKeyBytes = 32
+
+// Errors returned by DownloadLinkSigner.Verify
+var (
+	ErrDownloadLinkInvalid = errors.New("download link is invalid")
+	ErrDownloadLinkExpired = errors.New("download link has expired")
+)
+
+// DownloadLinkSigner issues and checks tokens granting the download of one upload until
+// they expire. A token is the upload ID and expiry sealed with AES-GCM, so it doesn't
+// reveal the upload ID and can't be changed to reach another upload or live longer, and
+// nothing is stored server side. Links can't be revoked before they expire, except by
+// changing the key, which revokes them all.
+type DownloadLinkSigner struct {
+	aead  cipher.AEAD
+	clock clock.Clock
+}
+
+// NewDownloadLinkSigner creates a signer with a secret key of at least 32 bytes. A nil
+// clock uses the system clock.
+func NewDownloadLinkSigner(key []byte, clk clock.Clock) (*DownloadLinkSigner, error) {
+	if len(key) < minDownloadLinkKeyBytes {
+		return nil, fmt.Errorf("download link key must be at least %d bytes", minDownloadLinkKeyBytes)
+	}
+	sealKey := sha256.Sum256(key)
+	block, err := aes.NewCipher(sealKey[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download link cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download link cipher: %w", err)
+	}
+	return &DownloadLinkSigner{aead: aead, clock: clock.OrReal(clk)}, nil
+}
+
+// Sign returns a token for uploadID that expires after ttl
+func (s *DownloadLinkSigner) Sign(uploadID int, ttl time.Duration) (token string, expiresAt time.Time) {
+	expiresAt = s.clock.Now().Add(ttl).Truncate(time.Second)
+
+	var payload [16]byte
+	binary.BigEndian.PutUint64(payload[:8], uint64(uploadID))
+	binary.BigEndian.PutUint64(payload[8:], uint64(expiresAt.Unix()))
+
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(payload)+s.aead.Overhead())
+	rand.Read(nonce) // Never fails
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, payload[:], nil)), expiresAt
+}
+
+// Verify returns the upload ID of a token issued by Sign, ErrDownloadLinkExpired once it
+// has expired and ErrDownloadLinkInvalid when it wasn't issued with this key or was changed
+func (s *DownloadLinkSigner) Verify(token string) (int, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return 0, ErrDownloadLinkInvalid
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	payload, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil || len(payload) != 16 {
+		return 0, ErrDownloadLinkInvalid
+	}
+
+	uploadID := int(binary.BigEndian.Uint64(payload[:8]))
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[8:])), 0)
+	if !s.clock.Now().Before(expiresAt) {
+		return 0, ErrDownloadLinkExpired
+	}
+
+	return uploadID, nil
+}
+
+// DownloadLinkResponse is returned when a download link is created
+type DownloadLinkResponse struct {
+	UploadID  int       `json:"upload_id"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url"` // Path of the shared download, relative to the API host
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HandleCreateDownloadLink handles POST /api/upload/download-link?id=X[&ttl_seconds=N]
+// Issues a link downloading the upload without authentication until it expires, by default
+// after 15 minutes and at most after 24 hours. The caller must have access to the upload.
+func (h *UploadHandler) HandleCreateDownloadLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.links == nil {
+		respondJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Download links are not enabled"})
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Valid upload ID is required"})
+		return
+	}
+
+	ttl := DefaultDownloadLinkTTL
+	if ttlStr := r.URL.Query().Get("ttl_seconds"); ttlStr != "" {
+		seconds, err := strconv.Atoi(ttlStr)
+		if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > MaxDownloadLinkTTL {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("ttl_seconds must be between 1 and %d", int(MaxDownloadLinkTTL.Seconds())),
+			})
+			return
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	upload, err := h.repo.GetUploadByID(ctx, id)
+	if err != nil {
+		log.Printf("Error getting upload: %v", err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
+	token, expiresAt := h.links.Sign(upload.ID, ttl)
+	respondJSON(w, http.StatusCreated, DownloadLinkResponse{
+		UploadID:  upload.ID,
+		Token:     token,
+		URL:       "/api/upload/shared?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// HandleSharedDownload handles GET /api/upload/shared?token=X[&disposition=inline]
+// Serves the file of a download link's upload without authentication while the link is valid
+func (h *UploadHandler) HandleSharedDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.links == nil {
+		http.Error(w, "Download links are not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "Token is required", http.StatusBadRequest)
+		return
+	}
+
+	disposition, ok := parseDisposition(r.URL.Query().Get("disposition"))
+	if !ok {
+		http.Error(w, "Invalid disposition (must be inline or attachment)", http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.links.Verify(token)
+	switch {
+	case errors.Is(err, ErrDownloadLinkExpired):
+		http.Error(w, "Download link has expired", http.StatusGone)
+		return
+	case err != nil:
+		http.Error(w, "Invalid download link", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	upload, err := h.repo.GetUploadByID(ctx, id)
+	if err != nil {
+		log.Printf("Error getting upload %d of download link: %v", id, err)
+		http.Error(w, "Upload not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeUploadFile(ctx, w, upload, disposition)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+var testLinkKey = bytes.Repeat([]byte("k"), 32)
+
+// tamper flips a bit in byte i of a token
+func tamper(t *testing.T, token string, i int) string {
+	t.Helper()
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("token %q isn't base64: %v", token, err)
+	}
+	sealed[i] ^= 0x01
+	return base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+func TestDownloadLinkSigner(t *testing.T) {
+	if _, err := NewDownloadLinkSigner([]byte("short"), nil); err == nil {
+		t.Error("expected an error for a short key")
+	}
+
+	fake := clock.NewFake(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	signer, err := NewDownloadLinkSigner(testLinkKey, fake)
+	if err != nil {
+		t.Fatalf("NewDownloadLinkSigner: %v", err)
+	}
+
+	token, expiresAt := signer.Sign(42, 15*time.Minute)
+	if want := fake.Now().Add(15 * time.Minute); !expiresAt.Equal(want) {
+		t.Errorf("expires at %v, want %v", expiresAt, want)
+	}
+	if again, _ := signer.Sign(42, 15*time.Minute); again == token {
+		t.Error("links for the same upload got the same token")
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		if id, err := signer.Verify(token); err != nil || id != 42 {
+			t.Errorf("Verify = %d, %v; want 42", id, err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		other, _ := NewDownloadLinkSigner(bytes.Repeat([]byte("o"), 32), fake)
+		otherToken, _ := other.Sign(42, time.Hour)
+
+		tokens := map[string]string{
+			"nonce changed":   tamper(t, token, 0),
+			"payload changed": tamper(t, token, 12),
+			"tag changed":     tamper(t, token, 43),
+			"truncated":       token[:len(token)-4],
+			"not base64":      "not a token!",
+			"empty":           "",
+			"other key":       otherToken,
+		}
+		for name, tampered := range tokens {
+			if id, err := signer.Verify(tampered); !errors.Is(err, ErrDownloadLinkInvalid) {
+				t.Errorf("%s: Verify = %d, %v; want ErrDownloadLinkInvalid", name, id, err)
+			}
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		fake.Advance(15*time.Minute - time.Second)
+		if _, err := signer.Verify(token); err != nil {
+			t.Fatalf("Verify a second before expiry: %v", err)
+		}
+		fake.Advance(time.Second)
+		if id, err := signer.Verify(token); !errors.Is(err, ErrDownloadLinkExpired) {
+			t.Errorf("Verify = %d, %v; want ErrDownloadLinkExpired", id, err)
+		}
+	})
+}
+
+func TestSharedDownloadLinks(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	signer, err := NewDownloadLinkSigner(testLinkKey, fake)
+	if err != nil {
+		t.Fatalf("NewDownloadLinkSigner: %v", err)
+	}
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "resume.pdf", MimeType: "application/pdf", FileContent: []byte("%PDF")},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, signer)
+
+	createLink := func(t *testing.T, query string, userID int) *httptest.ResponseRecorder {
+		t.Helper()
+		return serve(h.HandleCreateDownloadLink, asUser(httptest.NewRequest(http.MethodPost, "/api/upload/download-link?"+query, nil), userID))
+	}
+	// download fetches a shared link without a session
+	download := func(token string) *httptest.ResponseRecorder {
+		return serve(h.HandleSharedDownload, httptest.NewRequest(http.MethodGet, "/api/upload/shared?token="+token, nil))
+	}
+
+	w := createLink(t, "id=1&ttl_seconds=600", 7)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var link DownloadLinkResponse
+	decodeBody(t, w, &link)
+	if link.UploadID != 1 || !strings.HasPrefix(link.URL, "/api/upload/shared?token=") || !link.ExpiresAt.Equal(fake.Now().Add(10*time.Minute)) {
+		t.Errorf("link = %+v", link)
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		w := serve(h.HandleSharedDownload, httptest.NewRequest(http.MethodGet, link.URL, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "%PDF" {
+			t.Fatalf("status = %d, body %q; want the file", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=resume.pdf" {
+			t.Errorf("Content-Disposition = %q", got)
+		}
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		if w := download(tamper(t, link.Token, 12)); w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", w.Code)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		fake.Advance(10 * time.Minute)
+		if w := download(link.Token); w.Code != http.StatusGone {
+			t.Errorf("status = %d, want 410", w.Code)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query string
+			user  int
+			want  int
+		}{
+			{"non-owner", "id=1", 8, http.StatusForbidden},
+			{"unknown upload", "id=9", 7, http.StatusNotFound},
+			{"missing ID", "", 7, http.StatusBadRequest},
+			{"zero TTL", "id=1&ttl_seconds=0", 7, http.StatusBadRequest},
+			{"TTL over the maximum", fmt.Sprintf("id=1&ttl_seconds=%d", int(MaxDownloadLinkTTL.Seconds())+1), 7, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if w := createLink(t, tt.query, tt.user); w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+			})
+		}
+
+		if w := download(""); w.Code != http.StatusBadRequest {
+			t.Errorf("download without a token: status %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("links disabled", func(t *testing.T) {
+		h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+		if w := serve(h.HandleCreateDownloadLink, asUser(httptest.NewRequest(http.MethodPost, "/api/upload/download-link?id=1", nil), 7)); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("create status = %d, want 503", w.Code)
+		}
+		if w := serve(h.HandleSharedDownload, httptest.NewRequest(http.MethodGet, link.URL, nil)); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("download status = %d, want 503", w.Code)
+		}
+	})
+}
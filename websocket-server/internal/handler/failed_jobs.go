@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// isErrorCategory reports whether category is one of models.ErrorCategories
+func isErrorCategory(category string) bool {
+	for _, c := range models.ErrorCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// FailedJobsHandler lists failed analysis jobs for operators debugging extraction and
+// LLM failures
+type FailedJobsHandler struct {
+	analysisRepo repository.AnalysisRepository
+	adminToken   string // Required in the X-Admin-Token header; empty disables the endpoint
+}
+
+// NewFailedJobsHandler creates a new failed jobs handler instance
+func NewFailedJobsHandler(analysisRepo repository.AnalysisRepository, adminToken string) *FailedJobsHandler {
+	return &FailedJobsHandler{
+		analysisRepo: analysisRepo,
+		adminToken:   adminToken,
+	}
+}
+
+// FailedJobsPage is a page of failed jobs, with the number of failed jobs per error
+// category when grouping was requested
+type FailedJobsPage struct {
+	Page
+	Groups []models.FailedJobGroup `json:"groups,omitempty"`
+}
+
+// HandleListFailedJobs handles GET /api/admin/jobs/failed?limit=20&offset=0&category=X&group_by=category
+// Lists failed and dead-lettered jobs, most recently updated first, with their error
+// message and category and the file name and type of their upload
+func (h *FailedJobsHandler) HandleListFailedJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	page := ParsePagination(r, 20, 100)
+	category := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("category")))
+	groupBy := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("group_by")))
+
+	if category != "" && !isErrorCategory(category) {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid category",
+			"message": "category must be one of: " + strings.Join(models.ErrorCategories, ", "),
+		})
+		return
+	}
+	if groupBy != "" && groupBy != "category" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid group_by (use category)"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// The category counts give the total, and the groups when they were requested
+	groups, err := h.analysisRepo.CountFailedJobsByCategory(ctx)
+	if err != nil {
+		log.Printf("Error counting failed jobs: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve failed jobs"})
+		return
+	}
+
+	total := 0
+	for _, group := range groups {
+		if category == "" || group.Category == category {
+			total += group.Count
+		}
+	}
+
+	jobs, err := h.analysisRepo.ListFailedJobs(ctx, category, page.Limit, page.Offset)
+	if err != nil {
+		log.Printf("Error listing failed jobs: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve failed jobs"})
+		return
+	}
+
+	response := FailedJobsPage{Page: NewPage(jobs, len(jobs), total, page)}
+	if groupBy == "category" {
+		response.Groups = groups
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
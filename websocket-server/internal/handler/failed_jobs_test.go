@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestHandleListFailedJobs(t *testing.T) {
+	failed := func(jobID, category, message string) *models.FailedJob {
+		return &models.FailedJob{JobID: jobID, Status: "failed", ErrorCategory: category, ErrorMessage: &message}
+	}
+	repo := &fakeAnalysisRepo{failedJobs: []*models.FailedJob{
+		failed("job_5", models.ErrorCategoryNoText, "no text content found in PDF"),
+		failed("job_4", models.ErrorCategoryLLMTimeout, "LLM request timed out"),
+		failed("job_3", models.ErrorCategoryNoText, "Insufficient text extracted"),
+		failed("job_2", models.ErrorCategoryPasswordProtected, "PDF is password protected"),
+		failed("job_1", models.ErrorCategoryNoText, "no text content found in PDF"),
+	}}
+	h := NewFailedJobsHandler(repo, "secret")
+
+	type response struct {
+		Items   []*models.FailedJob     `json:"items"`
+		Total   int                     `json:"total"`
+		HasMore bool                    `json:"has_more"`
+		Groups  []models.FailedJobGroup `json:"groups"`
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantJobs   []string
+		wantTotal  int
+		wantMore   bool
+		wantGroups []models.FailedJobGroup
+	}{
+		{"first page", "limit=2", []string{"job_5", "job_4"}, 5, true, nil},
+		{"last page", "limit=2&offset=4", []string{"job_1"}, 5, false, nil},
+		{"one category", "category=No_Text", []string{"job_5", "job_3", "job_1"}, 3, false, nil},
+		{"grouped by category", "group_by=category&limit=1", []string{"job_5"}, 5, true, []models.FailedJobGroup{
+			{Category: models.ErrorCategoryNoText, Count: 3, Example: "no text content found in PDF"},
+			{Category: models.ErrorCategoryLLMTimeout, Count: 1, Example: "LLM request timed out"},
+			{Category: models.ErrorCategoryPasswordProtected, Count: 1, Example: "PDF is password protected"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed?"+tt.query, nil)
+			r.Header.Set(AdminTokenHeader, "secret")
+			w := serve(h.HandleListFailedJobs, r)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var body response
+			decodeBody(t, w, &body)
+			var jobIDs []string
+			for _, job := range body.Items {
+				jobIDs = append(jobIDs, job.JobID)
+			}
+			if !reflect.DeepEqual(jobIDs, tt.wantJobs) || body.Total != tt.wantTotal || body.HasMore != tt.wantMore {
+				t.Errorf("page = %v of %d (more %t), want %v of %d (more %t)", jobIDs, body.Total, body.HasMore, tt.wantJobs, tt.wantTotal, tt.wantMore)
+			}
+			if !reflect.DeepEqual(body.Groups, tt.wantGroups) {
+				t.Errorf("groups = %+v, want %+v", body.Groups, tt.wantGroups)
+			}
+		})
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			token string
+			query string
+			want  int
+		}{
+			{"no admin token", "", "", http.StatusUnauthorized},
+			{"wrong admin token", "guess", "", http.StatusUnauthorized},
+			{"unknown category", "secret", "category=gremlins", http.StatusBadRequest},
+			{"unknown grouping", "secret", "group_by=error_message", http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed?"+tt.query, nil)
+				if tt.token != "" {
+					r.Header.Set(AdminTokenHeader, tt.token)
+				}
+				if w := serve(h.HandleListFailedJobs, r); w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		h := NewFailedJobsHandler(&fakeAnalysisRepo{failedErr: errors.New("connection refused")}, "secret")
+		r := httptest.NewRequest(http.MethodGet, "/api/admin/jobs/failed", nil)
+		r.Header.Set(AdminTokenHeader, "secret")
+		if w := serve(h.HandleListFailedJobs, r); w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", w.Code)
+		}
+	})
+}
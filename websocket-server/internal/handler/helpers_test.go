@@ -0,0 +1,901 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/exporter"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// testUserHeader carries the caller's user ID for headerAuth
+const testUserHeader = "X-Test-User"
+
+// headerAuth authenticates requests by the user ID in testUserHeader
+type headerAuth struct{}
+
+func (headerAuth) UserIDFromRequest(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.Header.Get(testUserHeader))
+	return id, err == nil
+}
+
+// anonymousHeaderAuth is headerAuth in anonymous mode, where uploads and jobs without an
+// owner are open to every caller
+type anonymousHeaderAuth struct{ headerAuth }
+
+func (anonymousHeaderAuth) AllowsAnonymous() bool { return true }
+
+// tenantAuth is headerAuth resolving callers to the tenants of tenants. Callers missing
+// from it fail to resolve.
+type tenantAuth struct {
+	headerAuth
+
+	tenants map[int]string // By user ID
+}
+
+func (a tenantAuth) TenantIDFromRequest(ctx context.Context, r *http.Request) (string, error) {
+	userID, ok := a.UserIDFromRequest(r)
+	if !ok {
+		return "", nil
+	}
+	tenantID, ok := a.tenants[userID]
+	if !ok {
+		return "", fmt.Errorf("user %d not found", userID)
+	}
+	return tenantID, nil
+}
+
+// asUser marks a request as made by userID
+func asUser(r *http.Request, userID int) *http.Request {
+	r.Header.Set(testUserHeader, strconv.Itoa(userID))
+	return r
+}
+
+// fakeAuthProvider authenticates the users it holds, by email, with the password "secret"
+type fakeAuthProvider struct {
+	users map[string]*models.User // By email
+}
+
+func (p *fakeAuthProvider) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	user, ok := p.users[email]
+	if !ok || password != "secret" {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (p *fakeAuthProvider) GetUser(ctx context.Context, id int) (*models.User, error) {
+	for _, user := range p.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *fakeAuthProvider) UpdateDefaultExportFormat(ctx context.Context, id int, format string) error {
+	user, err := p.GetUser(ctx, id)
+	if err != nil || user == nil {
+		return fmt.Errorf("no user %d", id)
+	}
+	user.DefaultExportFormat = format
+	return nil
+}
+
+// fakeAnalyzer serves stored jobs and results. Methods a test needs but the fake doesn't
+// implement panic through the embedded nil interface.
+type fakeAnalyzer struct {
+	analyzer.ResumeAnalyzer
+
+	statuses  map[string]*models.AnalysisStatus
+	results   map[string]*models.AnalysisResult
+	uploads   map[int]*models.Upload // GetUpload results by upload ID
+	analytics map[int]*models.UserAnalytics // By user ID
+
+	analyticsTopSkills int   // topSkills of the latest GetUserAnalytics call
+	startErr           error // Returned by AnalyzeMultipleAsync and, per upload, ReanalyzeAllAsync
+
+	reanalyzable   map[int][]int // Uploads ReanalyzeAllAsync starts jobs for, by user ID
+	reanalyzedUser int           // userID of the latest ReanalyzeAllAsync call
+
+	recommendations    []string // Set by RegenerateRecommendations
+	recommendationsErr error    // Returned by RegenerateRecommendations
+	industry           string   // industry of the latest RegenerateRecommendations call
+
+	candidates    map[string][]*models.CandidateSummary // ListOrgCandidates results by tenant
+	candidatesErr error                                 // Returned by ListOrgCandidates
+
+	similar    map[string][]*models.SimilarProfile // GetSimilarProfiles results by job ID
+	similarErr error                               // Returned by GetSimilarProfiles
+
+	patches []*models.ProfilePatch // Each PatchProfile call's patch
+
+	searchResults []*models.UserProfile  // Returned by SearchSimilarResumes
+	reranked      *analyzer.RerankResult // Returned by RerankResumes
+	rerankErr     error                  // Returned by RerankResumes
+	reranks       int                    // RerankResumes calls
+
+	skillGaps       *models.SkillGapReport // Returned by AnalyzeSkillGaps
+	skillGapErr     error                  // Returned by AnalyzeSkillGaps
+	jobDescriptions []string               // Job descriptions of the latest AnalyzeSkillGaps call
+
+	previews int // PreviewChunks calls
+}
+
+func (a *fakeAnalyzer) AnalyzeMultipleAsync(ctx context.Context, uploadIDs []int, userID *int) (string, error) {
+	if a.startErr != nil {
+		return "", a.startErr
+	}
+	return fmt.Sprintf("job_%d", uploadIDs[0]), nil
+}
+
+// AnalyzeSync starts a job like AnalyzeMultipleAsync, reporting it finished when the fake
+// already has a status for it
+func (a *fakeAnalyzer) AnalyzeSync(ctx context.Context, uploadIDs []int, userID *int, wait time.Duration) (string, bool, error) {
+	jobID, err := a.AnalyzeMultipleAsync(ctx, uploadIDs, userID)
+	if err != nil {
+		return "", false, err
+	}
+	_, finished := a.statuses[jobID]
+	return jobID, finished, nil
+}
+
+func (a *fakeAnalyzer) ReanalyzeAllAsync(ctx context.Context, userID int, allow func() bool) (*analyzer.BulkReanalyzeResult, error) {
+	a.reanalyzedUser = userID
+	result := &analyzer.BulkReanalyzeResult{JobIDs: []string{}, UploadIDs: []int{}, SkippedUploadIDs: []int{}, DeferredUploadIDs: []int{}}
+	deferred := false
+	for _, uploadID := range a.reanalyzable[userID] {
+		switch {
+		case deferred, allow != nil && !allow(), errors.Is(a.startErr, analyzer.ErrQueueFull):
+			deferred = true
+			result.DeferredUploadIDs = append(result.DeferredUploadIDs, uploadID)
+			continue
+		case a.startErr != nil:
+			return result, a.startErr
+		}
+		result.JobIDs = append(result.JobIDs, fmt.Sprintf("job_%d", uploadID))
+		result.UploadIDs = append(result.UploadIDs, uploadID)
+	}
+	return result, nil
+}
+
+func (a *fakeAnalyzer) GetStatus(ctx context.Context, jobID string) (*models.AnalysisStatus, error) {
+	status, ok := a.statuses[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	copied := *status
+	return &copied, nil
+}
+
+func (a *fakeAnalyzer) GetResult(ctx context.Context, jobID string) (*models.AnalysisResult, error) {
+	status, ok := a.statuses[jobID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", analyzer.ErrJobNotFound, jobID)
+	}
+	if status.Status != "completed" {
+		return nil, fmt.Errorf("%w (status: %s)", analyzer.ErrJobNotCompleted, status.Status)
+	}
+	return a.results[jobID], nil
+}
+
+// ListOrgCandidates returns the tenant's candidates with the status, when one is given
+func (a *fakeAnalyzer) ListOrgCandidates(ctx context.Context, tenantID, status, tag string) ([]*models.CandidateSummary, error) {
+	if a.candidatesErr != nil {
+		return nil, a.candidatesErr
+	}
+	var matching []*models.CandidateSummary
+	for _, c := range a.candidates[tenantID] {
+		if (status == "" || c.Status == status) && (tag == "" || slices.Contains(c.Tags, tag)) {
+			matching = append(matching, c)
+		}
+	}
+	return matching, nil
+}
+
+func (a *fakeAnalyzer) RegenerateRecommendations(ctx context.Context, jobID, industry string) (*models.AnalysisResult, error) {
+	a.industry = industry
+	if a.recommendationsErr != nil {
+		return nil, a.recommendationsErr
+	}
+	result, err := a.GetResult(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	result.JobRecommendations = a.recommendations
+	return result, nil
+}
+
+// PatchProfile records the patch and returns the job's result with the summary patched
+func (a *fakeAnalyzer) PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) (*models.AnalysisResult, error) {
+	result, err := a.GetResult(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	a.patches = append(a.patches, patch)
+	patched := *result
+	if patch.Summary != nil {
+		patched.Summary = patch.Summary
+	}
+	return &patched, nil
+}
+
+func (a *fakeAnalyzer) SearchSimilarResumes(ctx context.Context, tenantID string, query string, limit int) ([]*models.UserProfile, error) {
+	return a.searchResults, nil
+}
+
+func (a *fakeAnalyzer) RerankResumes(ctx context.Context, query string, profiles []*models.UserProfile) (*analyzer.RerankResult, error) {
+	a.reranks++
+	if a.rerankErr != nil {
+		return nil, a.rerankErr
+	}
+	return a.reranked, nil
+}
+
+func (a *fakeAnalyzer) AnalyzeSkillGaps(ctx context.Context, jobID string, jobDescriptions []string) (*models.SkillGapReport, error) {
+	a.jobDescriptions = jobDescriptions
+	if a.skillGapErr != nil {
+		return nil, a.skillGapErr
+	}
+	return a.skillGaps, nil
+}
+
+func (a *fakeAnalyzer) GetSimilarProfiles(ctx context.Context, jobID string) ([]*models.SimilarProfile, error) {
+	if a.similarErr != nil {
+		return nil, a.similarErr
+	}
+	return a.similar[jobID], nil
+}
+
+func (a *fakeAnalyzer) GetUserAnalytics(ctx context.Context, userID int, topSkills int) (*models.UserAnalytics, error) {
+	a.analyticsTopSkills = topSkills
+	analytics, ok := a.analytics[userID]
+	if !ok {
+		return &models.UserAnalytics{UserID: userID, JobsByStatus: map[string]int{}, TopSkills: []models.SkillCount{}}, nil
+	}
+	return analytics, nil
+}
+
+func (a *fakeAnalyzer) DeleteJob(ctx context.Context, jobID string) error {
+	if _, ok := a.statuses[jobID]; !ok {
+		return fmt.Errorf("%w: %s", analyzer.ErrJobNotFound, jobID)
+	}
+	delete(a.statuses, jobID)
+	delete(a.results, jobID)
+	return nil
+}
+
+func (a *fakeAnalyzer) GetUpload(ctx context.Context, uploadID int) (*models.Upload, error) {
+	upload, ok := a.uploads[uploadID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", analyzer.ErrUploadNotFound, uploadID)
+	}
+	return upload, nil
+}
+
+// GetJobsByUserID returns the jobs owned by userID, in job ID order
+func (a *fakeAnalyzer) GetJobsByUserID(ctx context.Context, userID int) ([]*models.AnalysisJob, error) {
+	jobs := []*models.AnalysisJob{}
+	for jobID, status := range a.statuses {
+		if status.UserID != nil && *status.UserID == userID {
+			jobs = append(jobs, &models.AnalysisJob{JobID: jobID, UserID: status.UserID, Status: status.Status})
+		}
+	}
+	slices.SortFunc(jobs, func(x, y *models.AnalysisJob) int { return strings.Compare(x.JobID, y.JobID) })
+	return jobs, nil
+}
+
+// GetJobsByUploadID returns no jobs; tests only check who may list them
+func (a *fakeAnalyzer) GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error) {
+	return []*models.AnalysisJob{}, nil
+}
+
+func (a *fakeAnalyzer) PreviewChunks(ctx context.Context, uploadID int, userID *int, options analyzer.ChunkPreviewOptions) (*analyzer.ChunkPreview, error) {
+	a.previews++
+	return &analyzer.ChunkPreview{UploadID: uploadID, Chunks: []analyzer.PreviewChunk{}}, nil
+}
+
+// upload adds an upload owned by userID (nil = anonymous)
+func (a *fakeAnalyzer) upload(uploadID int, userID *int) {
+	if a.uploads == nil {
+		a.uploads = make(map[int]*models.Upload)
+	}
+	a.uploads[uploadID] = &models.Upload{ID: uploadID, UserID: userID}
+}
+
+// completedJob adds a completed job owned by userID (nil = anonymous) with result
+func (a *fakeAnalyzer) completedJob(jobID string, userID *int, result *models.AnalysisResult) {
+	if a.statuses == nil {
+		a.statuses = make(map[string]*models.AnalysisStatus)
+		a.results = make(map[string]*models.AnalysisResult)
+	}
+	result.JobID = jobID
+	result.Status = "completed"
+	a.statuses[jobID] = &models.AnalysisStatus{JobID: jobID, UserID: userID, Status: "completed", Progress: 100}
+	a.results[jobID] = result
+}
+
+// stubExporter exports every profile as "<format>:<job ID>"
+type stubExporter struct{}
+
+func (stubExporter) Export(ctx context.Context, profile *models.UserProfile, format exporter.Format) ([]byte, error) {
+	return []byte(string(format) + ":" + profile.JobID), nil
+}
+
+func (stubExporter) GetContentType(format exporter.Format) string {
+	return "application/x-" + string(format)
+}
+
+func (stubExporter) GetFileExtension(format exporter.Format) string {
+	return "." + string(format)
+}
+
+// fakeSavedQuestions records the questions saved through it. Methods a test needs but
+// the fake doesn't implement panic through the embedded nil interface.
+type fakeSavedQuestions struct {
+	repository.SavedQuestionRepository
+
+	saved      []*models.SaveQuestionRequest
+	embeddings [][]byte // Embedding of each saved question, nil if it had none
+
+	byJob       []*models.SavedInterviewQuestion // Served by GetSavedQuestionsByJob, whatever the job
+	failUpdates map[string]bool                  // Question IDs UpdateAnswer fails for
+
+	questions    []*models.SavedInterviewQuestion // Served by GetSavedQuestions and counted by the tag count methods like the Postgres repository
+	tagCountsErr error                            // Returned by the tag count methods
+	countErr     error                            // Returned by CountSavedQuestionsByJob
+	savedRefsErr error                            // Returned by GetSavedRefs
+
+	reassigns      []reassignCall         // Each ReassignSavedQuestions call
+	reassignResult *models.ReassignResult // Returned by ReassignSavedQuestions
+	reassignErr    error                  // Returned by ReassignSavedQuestions
+
+	mu      sync.Mutex
+	answers map[string]string // Answers saved by UpdateAnswer, by question ID
+}
+
+// reassignCall is the arguments of a ReassignSavedQuestions call
+type reassignCall struct {
+	userID, fromJobID, toJobID string
+	move                       bool
+}
+
+func (f *fakeSavedQuestions) ReassignSavedQuestions(ctx context.Context, userID, fromJobID, toJobID string, move bool) (*models.ReassignResult, error) {
+	f.reassigns = append(f.reassigns, reassignCall{userID, fromJobID, toJobID, move})
+	return f.reassignResult, f.reassignErr
+}
+
+func (f *fakeSavedQuestions) GetSavedQuestionsByJob(ctx context.Context, userID, jobID string) ([]*models.SavedInterviewQuestion, error) {
+	return f.byJob, nil
+}
+
+func (f *fakeSavedQuestions) GetSavedQuestions(ctx context.Context, userID string, limit, offset int) ([]*models.SavedInterviewQuestion, error) {
+	var questions []*models.SavedInterviewQuestion
+	for _, q := range f.questions {
+		if q.UserID == userID {
+			questions = append(questions, q)
+		}
+	}
+	questions = questions[min(offset, len(questions)):]
+	return questions[:min(limit, len(questions))], nil
+}
+
+func (f *fakeSavedQuestions) GetSavedQuestionsByAuthUserID(ctx context.Context, authUserID int, limit, offset int) ([]*models.SavedInterviewQuestion, error) {
+	var questions []*models.SavedInterviewQuestion
+	for _, q := range f.questions {
+		if q.AuthUserID != nil && *q.AuthUserID == authUserID {
+			questions = append(questions, q)
+		}
+	}
+	questions = questions[min(offset, len(questions)):]
+	return questions[:min(limit, len(questions))], nil
+}
+
+func (f *fakeSavedQuestions) CountSavedQuestionsByAuthUserID(ctx context.Context, authUserID int) (int, error) {
+	count := 0
+	for _, q := range f.questions {
+		if q.AuthUserID != nil && *q.AuthUserID == authUserID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeSavedQuestions) CountSavedQuestionsByJob(ctx context.Context, userID, jobID string) (int, error) {
+	if f.countErr != nil {
+		return 0, f.countErr
+	}
+	count := 0
+	for _, q := range f.questions {
+		if q.UserID == userID && q.JobID == jobID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeSavedQuestions) GetSavedRefs(ctx context.Context, userID string, refs []models.SavedQuestionRef) (map[models.SavedQuestionRef]bool, error) {
+	if f.savedRefsErr != nil {
+		return nil, f.savedRefsErr
+	}
+	saved := make(map[models.SavedQuestionRef]bool)
+	for _, q := range f.questions {
+		ref := models.SavedQuestionRef{JobID: q.JobID, QuestionID: q.QuestionID}
+		if q.UserID == userID && slices.Contains(refs, ref) {
+			saved[ref] = true
+		}
+	}
+	return saved, nil
+}
+
+func (f *fakeSavedQuestions) GetTagCounts(ctx context.Context, userID string) ([]*models.TagCount, error) {
+	return f.countTags(func(q *models.SavedInterviewQuestion) bool { return q.UserID == userID })
+}
+
+func (f *fakeSavedQuestions) GetTagCountsByAuthUserID(ctx context.Context, authUserID int) ([]*models.TagCount, error) {
+	return f.countTags(func(q *models.SavedInterviewQuestion) bool { return q.AuthUserID != nil && *q.AuthUserID == authUserID })
+}
+
+// countTags counts the questions matching owned per tag, most used first and ties by tag
+func (f *fakeSavedQuestions) countTags(owned func(*models.SavedInterviewQuestion) bool) ([]*models.TagCount, error) {
+	if f.tagCountsErr != nil {
+		return nil, f.tagCountsErr
+	}
+
+	var counts []*models.TagCount
+	index := make(map[string]*models.TagCount)
+	for _, q := range f.questions {
+		if !owned(q) {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, tag := range q.Tags {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if index[tag] == nil {
+				index[tag] = &models.TagCount{Tag: tag}
+				counts = append(counts, index[tag])
+			}
+			index[tag].Count++
+		}
+	}
+	slices.SortFunc(counts, func(a, b *models.TagCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	return counts, nil
+}
+
+func (f *fakeSavedQuestions) UpdateAnswer(ctx context.Context, userID, jobID, questionID, newAnswer string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failUpdates[questionID] {
+		return fmt.Errorf("question %s is locked", questionID)
+	}
+	if f.answers == nil {
+		f.answers = make(map[string]string)
+	}
+	f.answers[questionID] = newAnswer
+	return nil
+}
+
+func (f *fakeSavedQuestions) SaveQuestionWithEmbedding(ctx context.Context, req *models.SaveQuestionRequest, embedding []byte) (*models.SavedInterviewQuestion, error) {
+	f.saved = append(f.saved, req)
+	f.embeddings = append(f.embeddings, embedding)
+	return &models.SavedInterviewQuestion{
+		ID:         int64(len(f.saved)),
+		UserID:     req.UserID,
+		JobID:      req.JobID,
+		QuestionID: req.QuestionID,
+		Question:   req.Question,
+		Answer:     req.Answer,
+		Tags:       req.Tags,
+	}, nil
+}
+
+// flakyEmbedder fails its first failures calls and then embeds every text as vector
+type flakyEmbedder struct {
+	analyzer.EmbeddingGenerator
+
+	failures int
+	vector   []float32
+	calls    int
+}
+
+func (e *flakyEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	if e.calls <= e.failures {
+		return nil, fmt.Errorf("embedder unavailable (call %d)", e.calls)
+	}
+	return e.vector, nil
+}
+
+// recordingScheduler records the questions scheduled for background embedding
+type recordingScheduler struct {
+	scheduled []int64
+}
+
+func (s *recordingScheduler) Schedule(questionID int64) {
+	s.scheduled = append(s.scheduled, questionID)
+}
+
+// fakeUploadRepo keeps uploads in memory, filtering them like the Postgres repository.
+// Methods a test needs but the fake doesn't implement panic through the embedded nil
+// interface.
+type fakeUploadRepo struct {
+	repository.UploadRepository
+
+	uploads    []*models.Upload
+	lastFilter repository.UploadFilter // Filter of the latest list call
+	lastOrder  repository.UploadSort   // Order of the latest ListUploadsFiltered call
+}
+
+func (f *fakeUploadRepo) CreateUpload(ctx context.Context, upload *models.Upload) error {
+	upload.ID = len(f.uploads) + 1
+	f.uploads = append(f.uploads, upload)
+	return nil
+}
+
+func (f *fakeUploadRepo) GetUploadByID(ctx context.Context, id int) (*models.Upload, error) {
+	for _, upload := range f.uploads {
+		if upload.ID == id {
+			return upload, nil
+		}
+	}
+	return nil, fmt.Errorf("upload not found with ID: %d", id)
+}
+
+func (f *fakeUploadRepo) GetUploadFileContent(ctx context.Context, id int) ([]byte, error) {
+	upload, err := f.GetUploadByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return upload.FileContent, nil
+}
+
+func (f *fakeUploadRepo) DeleteUpload(ctx context.Context, id int) error {
+	for i, upload := range f.uploads {
+		if upload.ID == id {
+			f.uploads = append(f.uploads[:i], f.uploads[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("upload not found with ID: %d", id)
+}
+
+func (f *fakeUploadRepo) UpdateUploadMetadata(ctx context.Context, id int, tags []string, notes string) error {
+	upload, err := f.GetUploadByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	upload.Tags, upload.Notes = tags, notes
+	return nil
+}
+
+func (f *fakeUploadRepo) ListUploadsFiltered(ctx context.Context, filter repository.UploadFilter, order repository.UploadSort, limit, offset int) ([]*models.Upload, error) {
+	f.lastFilter, f.lastOrder = filter, order
+	matching := f.filter(filter)
+	if offset >= len(matching) {
+		return nil, nil
+	}
+	return matching[offset:min(offset+limit, len(matching))], nil
+}
+
+func (f *fakeUploadRepo) CountUploads(ctx context.Context, filter repository.UploadFilter) (int, error) {
+	return len(f.filter(filter)), nil
+}
+
+// filter returns the uploads matching filter, in the order they were added. Tests check
+// the order passed to ListUploadsFiltered; the sort itself is the repository's.
+func (f *fakeUploadRepo) filter(filter repository.UploadFilter) []*models.Upload {
+	var matching []*models.Upload
+	for _, upload := range f.uploads {
+		switch {
+		case filter.UserID != nil && (upload.UserID == nil || *upload.UserID != *filter.UserID):
+		case filter.TenantID != nil && upload.TenantID != *filter.TenantID:
+		case filter.Tag != "" && !slices.Contains(upload.Tags, filter.Tag):
+		case filter.Status == models.UploadStatusNotAnalyzed && upload.JobID != nil:
+		case filter.Status != "" && filter.Status != models.UploadStatusNotAnalyzed &&
+			(upload.JobStatus == nil || *upload.JobStatus != filter.Status):
+		case filter.CreatedFrom != nil && upload.CreatedAt.Before(*filter.CreatedFrom):
+		case filter.CreatedBefore != nil && !upload.CreatedAt.Before(*filter.CreatedBefore):
+		default:
+			matching = append(matching, upload)
+		}
+	}
+	return matching
+}
+
+// fakeChatMessages keeps chat messages in memory. Methods a test needs but the fake
+// doesn't implement panic through the embedded nil interface.
+type fakeChatMessages struct {
+	repository.ChatMessageRepository
+
+	messages []*models.ChatMessage
+}
+
+func (f *fakeChatMessages) CreateMessage(ctx context.Context, msg *models.ChatMessage) error {
+	msg.ID = int64(len(f.messages) + 1)
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func (f *fakeChatMessages) GetMessageByID(ctx context.Context, id int64) (*models.ChatMessage, error) {
+	for _, msg := range f.messages {
+		if msg.ID == id {
+			return msg, nil
+		}
+	}
+	return nil, fmt.Errorf("message not found: %d", id)
+}
+
+func (f *fakeChatMessages) GetMessageContent(ctx context.Context, id int64) ([]byte, error) {
+	msg, err := f.GetMessageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Content, nil
+}
+
+// session returns the messages of a session, in the order they were added
+func (f *fakeChatMessages) session(sessionID string) []*models.ChatMessage {
+	var matching []*models.ChatMessage
+	for _, msg := range f.messages {
+		if msg.SessionID != nil && *msg.SessionID == sessionID {
+			matching = append(matching, msg)
+		}
+	}
+	return matching
+}
+
+// conversation returns the messages between two users, in the order they were added
+func (f *fakeChatMessages) conversation(userID1, userID2 int) []*models.ChatMessage {
+	var matching []*models.ChatMessage
+	for _, msg := range f.messages {
+		if (msg.UserID == userID1 && msg.ToUserID == userID2) || (msg.UserID == userID2 && msg.ToUserID == userID1) {
+			matching = append(matching, msg)
+		}
+	}
+	return matching
+}
+
+// messagePage returns messages[offset:offset+limit]
+func messagePage(messages []*models.ChatMessage, limit, offset int) []*models.ChatMessage {
+	if offset >= len(messages) {
+		return nil
+	}
+	return messages[offset:min(offset+limit, len(messages))]
+}
+
+// matching returns the messages matching filter, in the order they were added
+func (f *fakeChatMessages) matching(filter repository.MessageFilter) []*models.ChatMessage {
+	sessions := make(map[string]bool)
+	for _, sessionID := range filter.SessionIDs {
+		sessions[sessionID] = true
+	}
+
+	var matching []*models.ChatMessage
+	for _, msg := range f.messages {
+		switch {
+		case filter.UserID != 0 && msg.UserID != filter.UserID && msg.ToUserID != filter.UserID:
+		case filter.WithUserID != nil && msg.UserID != *filter.WithUserID && msg.ToUserID != *filter.WithUserID:
+		case len(sessions) > 0 && (msg.SessionID == nil || !sessions[*msg.SessionID]):
+		case filter.MsgType != "" && msg.MsgType != filter.MsgType:
+		default:
+			matching = append(matching, msg)
+		}
+	}
+	return matching
+}
+
+func (f *fakeChatMessages) FindMessages(ctx context.Context, filter repository.MessageFilter, limit, offset int) ([]*models.ChatMessage, error) {
+	matching := f.matching(filter)
+	if !filter.OldestFirst {
+		slices.Reverse(matching)
+	}
+	return messagePage(matching, limit, offset), nil
+}
+
+func (f *fakeChatMessages) CountMatchingMessages(ctx context.Context, filter repository.MessageFilter) (int, error) {
+	return len(f.matching(filter)), nil
+}
+
+func (f *fakeChatMessages) GetMessagesBySession(ctx context.Context, sessionID string, limit, offset int) ([]*models.ChatMessage, error) {
+	return messagePage(f.session(sessionID), limit, offset), nil
+}
+
+func (f *fakeChatMessages) CountMessagesBySession(ctx context.Context, sessionID string) (int, error) {
+	return len(f.session(sessionID)), nil
+}
+
+func (f *fakeChatMessages) GetConversation(ctx context.Context, userID1, userID2, limit, offset int) ([]*models.ChatMessage, error) {
+	return messagePage(f.conversation(userID1, userID2), limit, offset), nil
+}
+
+func (f *fakeChatMessages) CountConversation(ctx context.Context, userID1, userID2 int) (int, error) {
+	return len(f.conversation(userID1, userID2)), nil
+}
+
+// fakeAnalysisRepo serves stored profiles. Methods a test needs but the fake doesn't
+// implement panic through the embedded nil interface.
+type fakeAnalysisRepo struct {
+	repository.AnalysisRepository
+
+	profiles map[string]*models.UserProfile // By job ID
+	jobs     map[string]*models.AnalysisJob // By job ID
+
+	failedJobs []*models.FailedJob // Most recently updated first, with their category set
+	failedErr  error               // Returned by CountFailedJobsByCategory and ListFailedJobs
+}
+
+func (f *fakeAnalysisRepo) GetJobByID(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	return job, nil
+}
+
+func (f *fakeAnalysisRepo) GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error) {
+	var jobs []*models.AnalysisJob
+	for _, job := range f.jobs {
+		if job.UploadID == uploadID {
+			jobs = append(jobs, job)
+		}
+	}
+	slices.SortFunc(jobs, func(a, b *models.AnalysisJob) int { return strings.Compare(a.JobID, b.JobID) })
+	return jobs, nil
+}
+
+func (f *fakeAnalysisRepo) UpdateJobError(ctx context.Context, jobID, errorCode, errorMessage string) error {
+	job, err := f.GetJobByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	job.Status = "failed"
+	job.ErrorCode = &errorCode
+	job.ErrorMessage = &errorMessage
+	return nil
+}
+
+func (f *fakeAnalysisRepo) DeleteJobsByUploadID(ctx context.Context, uploadID int) error {
+	for jobID, job := range f.jobs {
+		if job.UploadID == uploadID {
+			delete(f.jobs, jobID)
+		}
+	}
+	return nil
+}
+
+func (f *fakeAnalysisRepo) DeleteProfilesByUploadID(ctx context.Context, uploadID int) error {
+	for jobID := range f.profiles {
+		if job, ok := f.jobs[jobID]; ok && job.UploadID == uploadID {
+			delete(f.profiles, jobID)
+		}
+	}
+	return nil
+}
+
+func (f *fakeAnalysisRepo) GetProfileByJobID(ctx context.Context, jobID string) (*models.UserProfile, error) {
+	profile, ok := f.profiles[jobID]
+	if !ok {
+		return nil, fmt.Errorf("no profile for job %s", jobID)
+	}
+	return profile, nil
+}
+
+// CountFailedJobsByCategory counts failedJobs per category, largest first, with the
+// first message of each as its example
+func (f *fakeAnalysisRepo) CountFailedJobsByCategory(ctx context.Context) ([]models.FailedJobGroup, error) {
+	if f.failedErr != nil {
+		return nil, f.failedErr
+	}
+	groups := []models.FailedJobGroup{}
+	index := make(map[string]int)
+	for _, job := range f.failedJobs {
+		i, ok := index[job.ErrorCategory]
+		if !ok {
+			i = len(groups)
+			index[job.ErrorCategory] = i
+			groups = append(groups, models.FailedJobGroup{Category: job.ErrorCategory, Example: *job.ErrorMessage})
+		}
+		groups[i].Count++
+	}
+	slices.SortStableFunc(groups, func(a, b models.FailedJobGroup) int { return b.Count - a.Count })
+	return groups, nil
+}
+
+func (f *fakeAnalysisRepo) ListFailedJobs(ctx context.Context, category string, limit, offset int) ([]*models.FailedJob, error) {
+	if f.failedErr != nil {
+		return nil, f.failedErr
+	}
+	var jobs []*models.FailedJob
+	for _, job := range f.failedJobs {
+		if category == "" || job.ErrorCategory == category {
+			jobs = append(jobs, job)
+		}
+	}
+	if offset >= len(jobs) {
+		return nil, nil
+	}
+	return jobs[offset:min(offset+limit, len(jobs))], nil
+}
+
+// fakeUsers serves users by ID. Methods a test needs but the fake doesn't implement
+// panic through the embedded nil interface.
+type fakeUsers struct {
+	repository.UserRepository
+
+	users map[int]*models.User
+}
+
+func (f *fakeUsers) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, fmt.Errorf("no user %d", id)
+	}
+	return user, nil
+}
+
+// recordingLLM answers every prompt with response, or fails with err, recording the
+// prompts it was sent
+type recordingLLM struct {
+	response string
+	err      error
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (l *recordingLLM) Analyze(ctx context.Context, request *analyzer.AnalysisRequest) (*analyzer.AnalysisResponse, error) {
+	return nil, fmt.Errorf("Analyze is not supported")
+}
+
+func (l *recordingLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prompts = append(l.prompts, prompt)
+	if l.err != nil {
+		return "", l.err
+	}
+	return l.response, nil
+}
+
+// serve runs handler on a request and returns the recorded response
+func serve(handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// decodeBody decodes a JSON response into v, failing the test if it can't
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
@@ -3,54 +3,120 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/exporter"
+	"github.com/your-org/websocket-server/internal/guardrails"
+	"github.com/your-org/websocket-server/internal/prompts"
 	"github.com/your-org/websocket-server/internal/qamatcher"
 	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/internal/tier"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
 // InterviewHandler handles interview preparation requests
 type InterviewHandler struct {
-	llmClient            analyzer.LLMClient
-	analysisRepo         repository.AnalysisRepository
-	savedQuestionRepo    repository.SavedQuestionRepository
-	embedder             analyzer.EmbeddingGenerator
+	llmClient         analyzer.LLMClient
+	analysisRepo      repository.AnalysisRepository
+	savedQuestionRepo repository.SavedQuestionRepository
+	embedder          analyzer.EmbeddingGenerator
+	guardrails        *guardrails.Filter    // Redacts PII and flags disallowed content in generated answers
+	tiers             tier.Resolver         // Selects the LLM/embedding clients and limits for the caller
+	backfill          EmbeddingScheduler    // Embeds questions saved without an embedding (may be nil)
+	auth              Authenticator         // Resolves the caller for the embeddings export and prep packs
+	maxPromptChars    int                   // Interview prompts are trimmed to this length
+	pdf               *exporter.PDFExporter // Renders prep packs
 }
 
-// NewInterviewHandler creates a new interview handler instance
-func NewInterviewHandler(llmClient analyzer.LLMClient, analysisRepo repository.AnalysisRepository, savedQuestionRepo repository.SavedQuestionRepository, embedder analyzer.EmbeddingGenerator) *InterviewHandler {
+// EmbeddingScheduler schedules a saved question for background embedding
+type EmbeddingScheduler interface {
+	Schedule(questionID int64)
+}
+
+// Retry policy for embedding a question while saving it
+const (
+	saveEmbeddingAttempts = 3
+	saveEmbeddingBackoff  = 250 * time.Millisecond // Doubles after each failed attempt
+)
+
+// NewInterviewHandler creates a new interview handler instance.
+// A nil guardrail filter applies the default rules. A nil tier resolver uses
+// llmClient and embedder for every request without rate limits. A nil backfill
+// scheduler leaves questions saved without an embedding to the periodic backfill.
+// With a nil authenticator every caller is anonymous, so embeddings can't be exported.
+// A maxPromptChars of 0 or less uses DefaultMaxInterviewPromptChars.
+func NewInterviewHandler(llmClient analyzer.LLMClient, analysisRepo repository.AnalysisRepository, savedQuestionRepo repository.SavedQuestionRepository, embedder analyzer.EmbeddingGenerator, filter *guardrails.Filter, tiers tier.Resolver, backfill EmbeddingScheduler, auth Authenticator, maxPromptChars int) *InterviewHandler {
+	if filter == nil {
+		filter = guardrails.NewFilter(nil)
+	}
+	if tiers == nil {
+		tiers = tier.NewStaticResolver(tier.NewPlan(tier.Plan{LLMClient: llmClient, Embedder: embedder}, nil))
+	}
+	if maxPromptChars <= 0 {
+		maxPromptChars = DefaultMaxInterviewPromptChars
+	}
+
 	return &InterviewHandler{
 		llmClient:         llmClient,
 		analysisRepo:      analysisRepo,
 		savedQuestionRepo: savedQuestionRepo,
 		embedder:          embedder,
+		guardrails:        filter,
+		tiers:             tiers,
+		backfill:          backfill,
+		auth:              auth,
+		maxPromptChars:    maxPromptChars,
+		pdf:               exporter.NewPDFExporter(nil),
+	}
+}
+
+// resolvePlan selects the caller's plan and enforces its rate limit,
+// writing a 429 response when the limit is exceeded
+func (h *InterviewHandler) resolvePlan(w http.ResponseWriter, r *http.Request) (*tier.Plan, bool) {
+	plan, key := h.tiers.Resolve(r)
+	if !plan.Allow(key) {
+		log.Printf("Rate limit exceeded for %s (tier %q)", key, plan.Tier)
+		respondJSON(w, http.StatusTooManyRequests, map[string]string{
+			"error":   "Rate limit exceeded",
+			"message": fmt.Sprintf("Your plan allows %d generation requests per minute", plan.RequestsPerMinute),
+		})
+		return nil, false
 	}
+	return plan, true
 }
 
 // InterviewRequest represents the request to generate interview questions
 type InterviewRequest struct {
-	JobID          string `json:"job_id"`
-	JobTitle       string `json:"job_title"`
-	Level          string `json:"level"`
-	TargetCompany  string `json:"target_company"`
-	JobDescription string `json:"job_description"`
+	JobID           string `json:"job_id"`
+	JobTitle        string `json:"job_title"`
+	Level           string `json:"level"`
+	TargetCompany   string `json:"target_company"`
+	JobDescription  string `json:"job_description"`
 	JobRequirements string `json:"job_requirements"`
+	Language        string `json:"language,omitempty"`  // Language code for questions/answers (default: en)
+	AllowPII        bool   `json:"allow_pii,omitempty"` // Keep contact details (phone, email, address) in answers
 }
 
 // InterviewQuestion represents a generated interview question
 type InterviewQuestion struct {
-	ID         string   `json:"id"`         // Unique identifier for the question
+	ID         string   `json:"id"` // Derived from the job and question text with models.QuestionID
 	Question   string   `json:"question"`
 	Category   string   `json:"category"`
 	Difficulty string   `json:"difficulty"`
-	Tags       []string `json:"tags"`       // Keywords extracted from the question
-	Answer     string   `json:"answer"`     // Personalized answer for the candidate
+	Tags       []string `json:"tags"`   // Keywords extracted from the question
+	Answer     string   `json:"answer"` // Personalized answer for the candidate
+
+	Redacted []guardrails.PIIType `json:"redacted,omitempty"` // PII kinds removed from the answer
+	Flags    []string             `json:"flags,omitempty"`    // Disallowed terms found in the question or answer
 }
 
 // InterviewResponse represents the response with generated questions
@@ -63,11 +129,15 @@ type RegenerateAnswerRequest struct {
 	JobID    string `json:"job_id"`
 	Question string `json:"question"`
 	Category string `json:"category"`
+	Language string `json:"language,omitempty"`  // Language code for the answer (default: en)
+	AllowPII bool   `json:"allow_pii,omitempty"` // Keep contact details (phone, email, address) in the answer
 }
 
 // RegenerateAnswerResponse represents the response with a new answer
 type RegenerateAnswerResponse struct {
-	Answer string `json:"answer"`
+	Answer   string               `json:"answer"`
+	Redacted []guardrails.PIIType `json:"redacted,omitempty"` // PII kinds removed from the answer
+	Flags    []string             `json:"flags,omitempty"`    // Disallowed terms found in the answer
 }
 
 // HandleGenerateQuestions generates interview questions based on user profile and job details
@@ -91,6 +161,22 @@ func (h *InterviewHandler) HandleGenerateQuestions(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Validate and normalize the answer language
+	language, ok := normalizeLanguage(req.Language)
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Unsupported language",
+			"message": "Supported languages: " + supportedLanguageCodes(),
+		})
+		return
+	}
+	req.Language = language
+
+	plan, ok := h.resolvePlan(w, r)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 150*time.Second) // 2.5 minutes for generating 10 questions with answers
 	defer cancel()
 
@@ -103,7 +189,7 @@ func (h *InterviewHandler) HandleGenerateQuestions(w http.ResponseWriter, r *htt
 	}
 
 	// Generate interview questions using LLM
-	questions, err := h.generateInterviewQuestions(ctx, profile, &req)
+	questions, err := h.generateInterviewQuestions(ctx, plan.LLMClient, profile, &req)
 	if err != nil {
 		log.Printf("Error generating interview questions: %v", err)
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to generate interview questions"})
@@ -116,84 +202,81 @@ func (h *InterviewHandler) HandleGenerateQuestions(w http.ResponseWriter, r *htt
 }
 
 // generateInterviewQuestions uses LLM to generate interview questions
-func (h *InterviewHandler) generateInterviewQuestions(ctx context.Context, profile interface{}, req *InterviewRequest) ([]InterviewQuestion, error) {
+func (h *InterviewHandler) generateInterviewQuestions(ctx context.Context, llmClient analyzer.LLMClient, profile interface{}, req *InterviewRequest) ([]InterviewQuestion, error) {
 	// Build prompt for LLM
-	prompt := h.buildInterviewPrompt(profile, req)
+	prompt, err := h.buildInterviewPrompt(profile, req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Call LLM with the raw prompt (no resume analysis wrapper)
-	response, err := h.llmClient.GenerateFromPrompt(ctx, prompt)
+	response, err := llmClient.GenerateFromPrompt(ctx, prompt)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse the response to extract questions
-	questions := h.parseQuestionsFromLLMResponse(response)
+	questions := h.parseQuestionsFromLLMResponse(req.JobID, response)
+
+	// Apply guardrails to the generated content
+	for i := range questions {
+		answer := h.guardrails.Apply(questions[i].Answer, req.AllowPII)
+		question := h.guardrails.Apply(questions[i].Question, true)
+
+		questions[i].Answer = answer.Text
+		questions[i].Redacted = answer.Redacted
+		questions[i].Flags = mergeFlags(question.Flags, answer.Flags)
+		if len(questions[i].Flags) > 0 {
+			log.Printf("Warning: generated question %s flagged for disallowed content: %v", questions[i].ID, questions[i].Flags)
+		}
+	}
 
 	return questions, nil
 }
 
-// buildInterviewPrompt constructs the prompt for generating interview questions
-func (h *InterviewHandler) buildInterviewPrompt(profile interface{}, req *InterviewRequest) string {
+// mergeFlags combines two sorted flag lists without duplicates
+func mergeFlags(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, flag := range append(append([]string{}, a...), b...) {
+		if !seen[flag] {
+			seen[flag] = true
+			merged = append(merged, flag)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// buildInterviewPrompt renders the interview questions prompt template, trimmed to the
+// handler's prompt size limit (see fitInterviewPrompt)
+func (h *InterviewHandler) buildInterviewPrompt(profile interface{}, req *InterviewRequest) (string, error) {
 	// Convert profile to JSON for inclusion in prompt
 	profileJSON, _ := json.MarshalIndent(profile, "", "  ")
 
-	prompt := "You are an expert technical interviewer and career coach. Based on the candidate's profile and the job details provided, generate exactly 10 interview questions that might be asked in the interview.\n\n"
-
-	prompt += "Candidate Profile:\n"
-	prompt += string(profileJSON)
-	prompt += "\n\n"
-
-	prompt += "Job Details:\n"
-	prompt += "Job Title: " + req.JobTitle + "\n"
-	prompt += "Level: " + req.Level + "\n"
-
-	if req.TargetCompany != "" {
-		prompt += "Target Company: " + req.TargetCompany + "\n"
+	data := prompts.InterviewQuestionsData{
+		ProfileJSON:     string(profileJSON),
+		JobTitle:        req.JobTitle,
+		Level:           req.Level,
+		TargetCompany:   req.TargetCompany,
+		JobDescription:  req.JobDescription,
+		JobRequirements: req.JobRequirements,
+		QuestionCount:   prompts.InterviewQuestionCount,
 	}
-
-	if req.JobDescription != "" {
-		prompt += "\nJob Description:\n" + req.JobDescription + "\n"
+	if req.Language != "" && req.Language != defaultLanguage {
+		data.Language = supportedLanguages[req.Language]
 	}
 
-	prompt += "\nJob Requirements:\n" + req.JobRequirements + "\n\n"
-
-	prompt += `Generate exactly 10 interview questions with personalized answers in JSON format. Mix technical, behavioral, and situational questions based on:
-1. The candidate's background and experience
-2. The job requirements and level
-3. Common interview questions for this type of role
-
-Return ONLY a JSON object with this exact structure (no additional text):
-{
-  "questions": [
-    {
-      "id": "q1",
-      "question": "Question text here",
-      "category": "Technical|Behavioral|Situational|Problem-Solving",
-      "difficulty": "Easy|Medium|Hard",
-      "tags": ["keyword1", "keyword2", "keyword3"],
-      "answer": "Personalized answer based on candidate's profile"
-    }
-  ]
+	return fitInterviewPrompt(req.JobID, profile, data, h.maxPromptChars)
 }
 
-Important Instructions:
-- Generate EXACTLY 10 questions
-- For each question, generate an ID (q1, q2, q3, etc.)
-- Extract 3-5 relevant keywords from each question as tags (lowercase, single words or short phrases)
-- Include the category and difficulty as tags as well (e.g., ["technical", "medium", "python", "backend", "databases"])
-- For the answer field: Write a personalized, strong answer that the candidate could use, incorporating their actual experience, projects, and skills from their profile
-- The answer should be 2-4 paragraphs, specific to the candidate's background
-- Use real examples from their resume/profile in the answers
-- Questions should be relevant to both the candidate's profile and the job requirements
-- Balance technical and behavioral questions appropriately for the level
-- Consider the candidate's strengths and potential gaps
-- Return ONLY the JSON, no markdown formatting or additional text`
-
-	return prompt
-}
-
-// parseQuestionsFromLLMResponse parses interview questions from raw LLM response string
-func (h *InterviewHandler) parseQuestionsFromLLMResponse(response string) []InterviewQuestion {
+// parseQuestionsFromLLMResponse parses interview questions from raw LLM response string.
+// Any IDs the LLM returned are replaced with ones derived from the question text, and
+// repeated questions are dropped.
+func (h *InterviewHandler) parseQuestionsFromLLMResponse(jobID, response string) []InterviewQuestion {
 	// Try to parse the response as JSON
 	var result struct {
 		Questions []InterviewQuestion `json:"questions"`
@@ -232,7 +315,35 @@ func (h *InterviewHandler) parseQuestionsFromLLMResponse(response string) []Inte
 		return []InterviewQuestion{}
 	}
 
-	return result.Questions
+	// Normalize category, difficulty and tags so filtering works regardless of how the
+	// LLM formatted them. Unknown categories and difficulties are dropped rather than
+	// failing the whole generation.
+	questions := make([]InterviewQuestion, 0, len(result.Questions))
+	seen := make(map[string]bool, len(result.Questions))
+	for i := range result.Questions {
+		q := &result.Questions[i]
+
+		q.ID = models.QuestionID(jobID, q.Question)
+		if strings.TrimSpace(q.Question) == "" || seen[q.ID] {
+			continue
+		}
+		seen[q.ID] = true
+
+		category, err := models.NormalizeQuestionCategory(q.Category)
+		if err != nil {
+			log.Printf("Warning: dropping category of generated question %s: %v", q.ID, err)
+		}
+		difficulty, err := models.NormalizeQuestionDifficulty(q.Difficulty)
+		if err != nil {
+			log.Printf("Warning: dropping difficulty of generated question %s: %v", q.ID, err)
+		}
+		q.Category, q.Difficulty = category, difficulty
+
+		q.Tags = normalizeTags(q.Tags)
+		questions = append(questions, *q)
+	}
+
+	return questions
 }
 
 // HandleRegenerateAnswer regenerates a single answer for a specific question
@@ -256,6 +367,21 @@ func (h *InterviewHandler) HandleRegenerateAnswer(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Validate and normalize the answer language
+	language, ok := normalizeLanguage(req.Language)
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Unsupported language",
+			"message": "Supported languages: " + supportedLanguageCodes(),
+		})
+		return
+	}
+
+	plan, ok := h.resolvePlan(w, r)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
@@ -268,7 +394,7 @@ func (h *InterviewHandler) HandleRegenerateAnswer(w http.ResponseWriter, r *http
 	}
 
 	// Generate new answer using LLM
-	answer, err := h.generateSingleAnswer(ctx, profile, req.Question, req.Category)
+	filtered, err := h.generateSingleAnswer(ctx, plan.LLMClient, profile, req.Question, req.Category, language, req.AllowPII)
 	if err != nil {
 		log.Printf("Error regenerating answer: %v", err)
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to regenerate answer"})
@@ -276,48 +402,331 @@ func (h *InterviewHandler) HandleRegenerateAnswer(w http.ResponseWriter, r *http
 	}
 
 	// Return new answer
-	respondJSON(w, http.StatusOK, RegenerateAnswerResponse{Answer: answer})
+	respondJSON(w, http.StatusOK, RegenerateAnswerResponse{
+		Answer:   filtered.Text,
+		Redacted: filtered.Redacted,
+		Flags:    filtered.Flags,
+	})
 	log.Printf("Regenerated answer for question in job %s", req.JobID)
 }
 
-// generateSingleAnswer generates a personalized answer for a single question
-func (h *InterviewHandler) generateSingleAnswer(ctx context.Context, profile interface{}, question string, category string) (string, error) {
+// generateSingleAnswer generates a personalized answer for a single question and applies the guardrails to it
+func (h *InterviewHandler) generateSingleAnswer(ctx context.Context, llmClient analyzer.LLMClient, profile interface{}, question string, category string, language string, allowPII bool) (*guardrails.Result, error) {
 	// Convert profile to JSON for inclusion in prompt
 	profileJSON, _ := json.MarshalIndent(profile, "", "  ")
 
-	prompt := "You are an expert career coach helping a candidate prepare for interviews.\n\n"
-	prompt += "Candidate Profile:\n"
-	prompt += string(profileJSON)
-	prompt += "\n\n"
-	prompt += "Interview Question:\n"
-	prompt += question + "\n\n"
-	if category != "" {
-		prompt += "Question Category: " + category + "\n\n"
+	data := prompts.InterviewAnswerData{
+		ProfileJSON: string(profileJSON),
+		Question:    question,
+		Category:    category,
+	}
+	if language != defaultLanguage {
+		data.Language = supportedLanguages[language]
 	}
 
-	prompt += `Generate a strong, personalized answer that the candidate could use for this interview question.
-
-Requirements:
-- Write 2-4 paragraphs
-- Use specific examples from the candidate's actual experience, projects, and skills
-- Make it sound natural and conversational, not overly formal
-- Incorporate real details from their profile (companies, technologies, projects, etc.)
-- Show both technical depth and soft skills where appropriate
-- Make the candidate sound confident but not arrogant
-
-Return ONLY the answer text, no additional commentary or JSON formatting.`
+	prompt, err := prompts.Render(prompts.InterviewAnswer, data)
+	if err != nil {
+		return nil, err
+	}
 
 	// Call LLM with the prompt
-	response, err := h.llmClient.GenerateFromPrompt(ctx, prompt)
+	response, err := llmClient.GenerateFromPrompt(ctx, prompt)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Clean the response (remove any markdown formatting)
 	answer := strings.TrimSpace(response)
 	answer = strings.Trim(answer, "`\"")
 
-	return answer, nil
+	filtered := h.guardrails.Apply(answer, allowPII)
+	if filtered.Flagged() {
+		log.Printf("Warning: generated answer flagged for disallowed content: %v", filtered.Flags)
+	}
+
+	return &filtered, nil
+}
+
+// regenerateAllBatchSize is how many answers HandleRegenerateAllAnswers generates concurrently
+const regenerateAllBatchSize = 4
+
+// Outcomes of regenerating one saved answer
+const (
+	regenerateStatusUpdated     = "updated"
+	regenerateStatusFailed      = "failed"
+	regenerateStatusRateLimited = "rate_limited" // Not attempted: the plan's limit was reached
+	regenerateStatusLowQuality  = "low_quality"  // Generated but not saved: it failed the quality check
+)
+
+// RegenerateAllAnswersRequest represents the request to regenerate every saved answer of a job
+type RegenerateAllAnswersRequest struct {
+	UserID   string `json:"user_id"`
+	JobID    string `json:"job_id"`
+	Language string `json:"language,omitempty"`  // Language code for the answers (default: en)
+	AllowPII bool   `json:"allow_pii,omitempty"` // Keep contact details (phone, email, address) in the answers
+}
+
+// RegeneratedAnswer reports the outcome of regenerating one saved answer
+type RegeneratedAnswer struct {
+	QuestionID string               `json:"question_id"`
+	Status     string               `json:"status"`           // updated, failed, rate_limited or low_quality
+	Answer     string               `json:"answer,omitempty"` // The new answer; saved only when updated
+	Issues     []string             `json:"issues,omitempty"` // Why a low_quality answer wasn't saved
+	Error      string               `json:"error,omitempty"`
+	Redacted   []guardrails.PIIType `json:"redacted,omitempty"` // PII kinds removed from the answer
+	Flags      []string             `json:"flags,omitempty"`    // Disallowed terms found in the answer
+}
+
+// RegenerateAllAnswersResponse represents the outcome of regenerating a job's saved answers
+type RegenerateAllAnswersResponse struct {
+	JobID       string              `json:"job_id"`
+	Updated     int                 `json:"updated"`
+	Failed      int                 `json:"failed"`
+	RateLimited int                 `json:"rate_limited"`
+	LowQuality  int                 `json:"low_quality"` // Answers held back for regeneration
+	Results     []RegeneratedAnswer `json:"results"`     // In saved question order
+}
+
+// HandleRegenerateAllAnswers regenerates the answers of all of a user's saved questions for a
+// job from the current profile and saves them. Each answer counts against the caller's plan;
+// once the limit is reached the remaining questions are reported as rate_limited. A failed
+// question is reported without stopping the others. Answers failing the guardrails' quality
+// check (e.g. truncated) are not saved and are reported as low_quality, keeping the old answer.
+func (h *InterviewHandler) HandleRegenerateAllAnswers(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req RegenerateAllAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	// Validate required fields
+	if req.UserID == "" || req.JobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required fields: user_id, job_id"})
+		return
+	}
+
+	// Validate and normalize the answer language
+	language, ok := normalizeLanguage(req.Language)
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Unsupported language",
+			"message": "Supported languages: " + supportedLanguageCodes(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Minute)
+	defer cancel()
+
+	questions, err := h.savedQuestionRepo.GetSavedQuestionsByJob(ctx, req.UserID, req.JobID)
+	if err != nil {
+		log.Printf("Error getting saved questions for job %s: %v", req.JobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve saved questions"})
+		return
+	}
+
+	response := RegenerateAllAnswersResponse{JobID: req.JobID, Results: []RegeneratedAnswer{}}
+	if len(questions) == 0 {
+		respondJSON(w, http.StatusOK, response)
+		return
+	}
+
+	// Get user profile from database
+	profile, err := h.analysisRepo.GetProfileByJobID(ctx, req.JobID)
+	if err != nil {
+		log.Printf("Error getting profile for job %s: %v", req.JobID, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Profile not found"})
+		return
+	}
+
+	plan, key := h.tiers.Resolve(r)
+	response.Results = make([]RegeneratedAnswer, len(questions))
+	limited := false
+	for start := 0; start < len(questions); start += regenerateAllBatchSize {
+		end := start + regenerateAllBatchSize
+		if end > len(questions) {
+			end = len(questions)
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			q := questions[i]
+			if limited || !plan.Allow(key) {
+				limited = true
+				response.Results[i] = RegeneratedAnswer{QuestionID: q.QuestionID, Status: regenerateStatusRateLimited}
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, q *models.SavedInterviewQuestion) {
+				defer wg.Done()
+				response.Results[i] = h.regenerateSavedAnswer(ctx, plan.LLMClient, profile, q, language, req.AllowPII)
+			}(i, q)
+		}
+		wg.Wait()
+	}
+
+	for _, result := range response.Results {
+		switch result.Status {
+		case regenerateStatusUpdated:
+			response.Updated++
+		case regenerateStatusFailed:
+			response.Failed++
+		case regenerateStatusRateLimited:
+			response.RateLimited++
+		case regenerateStatusLowQuality:
+			response.LowQuality++
+		}
+	}
+
+	// Nothing was attempted, so report the limit like the other generation endpoints
+	if response.RateLimited == len(questions) {
+		log.Printf("Rate limit exceeded for %s (tier %q)", key, plan.Tier)
+		respondJSON(w, http.StatusTooManyRequests, map[string]string{
+			"error":   "Rate limit exceeded",
+			"message": fmt.Sprintf("Your plan allows %d generation requests per minute", plan.RequestsPerMinute),
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+	log.Printf("Regenerated answers for job %s: %d updated, %d failed, %d rate limited, %d low quality",
+		req.JobID, response.Updated, response.Failed, response.RateLimited, response.LowQuality)
+}
+
+// regenerateSavedAnswer generates a new answer for a saved question and saves it if it
+// passes the quality check
+func (h *InterviewHandler) regenerateSavedAnswer(ctx context.Context, llmClient analyzer.LLMClient, profile interface{}, q *models.SavedInterviewQuestion, language string, allowPII bool) RegeneratedAnswer {
+	result := RegeneratedAnswer{QuestionID: q.QuestionID, Status: regenerateStatusFailed}
+
+	var category string
+	if q.Category != nil {
+		category = *q.Category
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	filtered, err := h.generateSingleAnswer(ctx, llmClient, profile, q.Question, category, language, allowPII)
+	if err != nil {
+		log.Printf("Error regenerating answer for question %s in job %s: %v", q.QuestionID, q.JobID, err)
+		result.Error = "Failed to regenerate answer"
+		return result
+	}
+
+	result.Answer = filtered.Text
+	result.Redacted = filtered.Redacted
+	result.Flags = filtered.Flags
+
+	// Nobody reviews these answers before they replace the saved ones
+	if issues := h.guardrails.CheckAnswer(*filtered); len(issues) > 0 {
+		log.Printf("Not saving regenerated answer for question %s in job %s: %v", q.QuestionID, q.JobID, issues)
+		result.Status = regenerateStatusLowQuality
+		result.Issues = issues
+		return result
+	}
+
+	if err := h.savedQuestionRepo.UpdateAnswer(ctx, q.UserID, q.JobID, q.QuestionID, filtered.Text); err != nil {
+		log.Printf("Error saving regenerated answer for question %s in job %s: %v", q.QuestionID, q.JobID, err)
+		result.Error = "Failed to save answer"
+		return result
+	}
+
+	result.Status = regenerateStatusUpdated
+	return result
+}
+
+// HandleDownloadPrepPack renders a job's analyzed profile and the authenticated caller's
+// saved questions and answers for it as a single PDF. The caller must have access to the
+// job. Either part may be missing; if both are, 404.
+func (h *InterviewHandler) HandleDownloadPrepPack(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	caller, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	// The pack holds the caller's own saved questions; user_id may only name the caller
+	userID := strconv.Itoa(caller)
+	if requested := r.URL.Query().Get("user_id"); requested != "" && requested != userID {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": "Cannot export the prep pack of another user"})
+		return
+	}
+
+	jobID := r.URL.Query().Get("job_id")
+	if jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required parameter: job_id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	job, err := h.analysisRepo.GetJobByID(ctx, jobID)
+	if err != nil {
+		log.Printf("Error getting job %s for prep pack: %v", jobID, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Job not found"})
+		return
+	}
+	if !authorizeOwner(w, r, h.auth, job.UserID, "You do not have access to this job") {
+		return
+	}
+
+	questions, err := h.savedQuestionRepo.GetSavedQuestionsByJob(ctx, userID, jobID)
+	if err != nil {
+		log.Printf("Error getting saved questions for job %s: %v", jobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve saved questions"})
+		return
+	}
+
+	// A job that was never analyzed, or failed, still gets a pack of its questions
+	profile, err := h.analysisRepo.GetProfileByJobID(ctx, jobID)
+	if err != nil {
+		log.Printf("No profile for prep pack of job %s: %v", jobID, err)
+		profile = nil
+	}
+
+	if profile == nil && len(questions) == 0 {
+		respondJSON(w, http.StatusNotFound, map[string]string{
+			"error":   "Nothing to export",
+			"message": "The job has no analyzed profile and no saved questions",
+		})
+		return
+	}
+
+	data, err := h.pdf.ExportPrepPackPDF(ctx, &exporter.PrepPack{JobID: jobID, Profile: profile, Questions: questions})
+	if err != nil {
+		log.Printf("Error exporting prep pack for job %s: %v", jobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{
+			"error":   "Export failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	fileName := "interview_prep_pack_" + unsafeFileNameChars.ReplaceAllString(jobID, "_") + ".pdf"
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error writing prep pack: %v", err)
+	}
+
+	log.Printf("Exported prep pack for job %s (%d questions, profile: %t)", jobID, len(questions), profile != nil)
 }
 
 // HandleSaveQuestion saves a question-answer pair for the user
@@ -336,21 +745,40 @@ func (h *InterviewHandler) HandleSaveQuestion(w http.ResponseWriter, r *http.Req
 	}
 
 	// Validate required fields
-	if req.UserID == "" || req.JobID == "" || req.QuestionID == "" || req.Question == "" || req.Answer == "" {
+	if req.UserID == "" || req.JobID == "" || req.Question == "" || req.Answer == "" {
 		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required fields"})
 		return
 	}
 
+	// Questions saved without an ID get the one generation would have given them
+	if req.QuestionID == "" {
+		req.QuestionID = models.QuestionID(req.JobID, req.Question)
+	}
+
+	// Store canonical categories and difficulties so filtering isn't fragmented by spelling
+	if err := req.Normalize(); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid question", "message": err.Error()})
+		return
+	}
+
+	// Derive tags server-side when the client didn't send any
+	if len(req.Tags) == 0 {
+		req.Tags = deriveQuestionTags(req.Question, req.Category, req.Difficulty)
+	} else {
+		req.Tags = normalizeTags(req.Tags)
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second) // Increased for embedding generation
 	defer cancel()
 
-	// Generate embedding for the question
+	// Generate embedding for the question with the caller's plan (not rate limited)
+	plan, _ := h.tiers.Resolve(r)
 	var questionEmbedding []byte
-	if h.embedder != nil {
-		embedding, err := h.embedder.GenerateEmbedding(ctx, req.Question)
+	if plan.Embedder != nil {
+		embedding, err := generateEmbeddingWithRetry(ctx, plan.Embedder, req.Question)
 		if err != nil {
 			log.Printf("Warning: Failed to generate embedding for question %s: %v", req.QuestionID, err)
-			// Continue without embedding - it can be generated later on-the-fly
+			// Continue without embedding - the backfill worker generates it later
 		} else {
 			questionEmbedding, err = qamatcher.SerializeEmbedding(embedding)
 			if err != nil {
@@ -368,14 +796,132 @@ func (h *InterviewHandler) HandleSaveQuestion(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Hand questions saved without an embedding to the backfill worker so they
+	// become matchable once the embedder recovers
+	embeddingPending := questionEmbedding == nil
+	if embeddingPending && h.backfill != nil {
+		h.backfill.Schedule(saved.ID)
+	}
+
 	// Return success with saved data
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"success": true,
-		"saved":   saved,
+		"success":           true,
+		"saved":             saved,
+		"embedding_pending": embeddingPending,
 	})
 	log.Printf("Saved question %s for user %s in job %s", req.QuestionID, req.UserID, req.JobID)
 }
 
+// generateEmbeddingWithRetry embeds text, retrying transient failures with exponential
+// backoff. It gives up early when ctx is done.
+func generateEmbeddingWithRetry(ctx context.Context, embedder analyzer.EmbeddingGenerator, text string) ([]float32, error) {
+	backoff := saveEmbeddingBackoff
+	var err error
+	for attempt := 1; attempt <= saveEmbeddingAttempts; attempt++ {
+		var embedding []float32
+		embedding, err = embedder.GenerateEmbedding(ctx, text)
+		if err == nil {
+			return embedding, nil
+		}
+		if attempt == saveEmbeddingAttempts {
+			break
+		}
+
+		log.Printf("Embedding attempt %d/%d failed, retrying in %s: %v", attempt, saveEmbeddingAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("embedding cancelled after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return nil, fmt.Errorf("embedding failed after %d attempts: %w", saveEmbeddingAttempts, err)
+}
+
+// Modes of ReassignSavedQuestionsRequest
+const (
+	reassignModeCopy = "copy"
+	reassignModeMove = "move"
+)
+
+// ReassignSavedQuestionsRequest asks to copy or move a user's saved questions from one
+// job to another, e.g. after re-uploading a resume
+type ReassignSavedQuestionsRequest struct {
+	UserID    string `json:"user_id"`
+	FromJobID string `json:"from_job_id"`
+	ToJobID   string `json:"to_job_id"`
+	Mode      string `json:"mode,omitempty"` // copy (default) or move
+}
+
+// ReassignSavedQuestionsResponse reports the outcome of reassigning saved questions
+type ReassignSavedQuestionsResponse struct {
+	FromJobID string `json:"from_job_id"`
+	ToJobID   string `json:"to_job_id"`
+	Mode      string `json:"mode"`
+	*models.ReassignResult
+}
+
+// HandleReassignSavedQuestions handles POST /api/interview/saved-questions/reassign
+// Copies or moves a user's saved questions to another job. Questions already saved under
+// the target job are kept and reported as skipped; a move removes them from the source
+// job either way.
+func (h *InterviewHandler) HandleReassignSavedQuestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ReassignSavedQuestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if req.UserID == "" || req.FromJobID == "" || req.ToJobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required fields: user_id, from_job_id, to_job_id"})
+		return
+	}
+	if req.FromJobID == req.ToJobID {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "from_job_id and to_job_id must differ"})
+		return
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if mode == "" {
+		mode = reassignModeCopy
+	}
+	if mode != reassignModeCopy && mode != reassignModeMove {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid mode (use copy or move)"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// The target job has to exist, so the questions don't end up under a mistyped ID
+	if _, err := h.analysisRepo.GetJobByID(ctx, req.ToJobID); err != nil {
+		log.Printf("Error getting job %s for reassigning saved questions: %v", req.ToJobID, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Target job not found"})
+		return
+	}
+
+	result, err := h.savedQuestionRepo.ReassignSavedQuestions(ctx, req.UserID, req.FromJobID, req.ToJobID, mode == reassignModeMove)
+	if err != nil {
+		log.Printf("Error reassigning saved questions from job %s to %s: %v", req.FromJobID, req.ToJobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to reassign saved questions"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ReassignSavedQuestionsResponse{
+		FromJobID:      req.FromJobID,
+		ToJobID:        req.ToJobID,
+		Mode:           mode,
+		ReassignResult: result,
+	})
+	log.Printf("Reassigned saved questions of job %s to %s (%s): %d reassigned, %d skipped, %d removed",
+		req.FromJobID, req.ToJobID, mode, result.Reassigned, result.Skipped, result.Removed)
+}
+
 // HandleCheckSaved checks if a question is already saved
 func (h *InterviewHandler) HandleCheckSaved(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
@@ -402,6 +948,101 @@ func (h *InterviewHandler) HandleCheckSaved(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// maxCheckSavedBatch caps how many questions HandleCheckSavedBatch checks at once
+const maxCheckSavedBatch = 100
+
+// HandleCheckSavedBatch checks which of several questions a user has saved in one
+// round trip, e.g. for every question of a results page. The response maps job IDs to
+// question IDs to saved status, with an entry for every requested question.
+func (h *InterviewHandler) HandleCheckSavedBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CheckSavedBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if req.UserID == "" || len(req.Questions) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required fields: user_id and questions"})
+		return
+	}
+
+	if len(req.Questions) > maxCheckSavedBatch {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid request",
+			"message": fmt.Sprintf("Maximum %d questions can be checked at once", maxCheckSavedBatch),
+		})
+		return
+	}
+
+	for _, ref := range req.Questions {
+		if ref.JobID == "" || ref.QuestionID == "" {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Every question needs a job_id and a question_id"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	saved, err := h.savedQuestionRepo.GetSavedRefs(ctx, req.UserID, req.Questions)
+	if err != nil {
+		log.Printf("Error checking saved status of %d questions: %v", len(req.Questions), err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to check saved status"})
+		return
+	}
+
+	result := make(map[string]map[string]bool)
+	for _, ref := range req.Questions {
+		if result[ref.JobID] == nil {
+			result[ref.JobID] = make(map[string]bool)
+		}
+		result[ref.JobID][ref.QuestionID] = saved[ref]
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":  req.UserID,
+		"is_saved": result,
+	})
+}
+
+// HandleCountSavedQuestions returns how many questions a user saved for a job, for
+// showing counts without fetching the questions
+func (h *InterviewHandler) HandleCountSavedQuestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	jobID := r.URL.Query().Get("job_id")
+
+	if userID == "" || jobID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required parameters: user_id and job_id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	count, err := h.savedQuestionRepo.CountSavedQuestionsByJob(ctx, userID, jobID)
+	if err != nil {
+		log.Printf("Error counting saved questions for user %s, job %s: %v", userID, jobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to count saved questions"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"job_id":  jobID,
+		"count":   count,
+	})
+}
+
 // HandleGetSavedQuestions retrieves saved questions with pagination and tag filtering
 // Supports both user_id (string) and auth_user_id (integer) for filtering
 func (h *InterviewHandler) HandleGetSavedQuestions(w http.ResponseWriter, r *http.Request) {
@@ -415,25 +1056,13 @@ func (h *InterviewHandler) HandleGetSavedQuestions(w http.ResponseWriter, r *htt
 	}
 
 	// Parse pagination parameters
-	limit := 20 // default
-	offset := 0 // default
-
-	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
-		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 && parsedLimit <= 100 {
-			limit = parsedLimit
-		}
-	}
-
-	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
-		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
-	}
+	page := ParsePagination(r, 20, 100)
 
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
 	var questions []*models.SavedInterviewQuestion
+	var total int
 	var err error
 
 	// Prefer auth_user_id if provided, otherwise use user_id
@@ -443,9 +1072,15 @@ func (h *InterviewHandler) HandleGetSavedQuestions(w http.ResponseWriter, r *htt
 			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid auth_user_id"})
 			return
 		}
-		questions, err = h.savedQuestionRepo.GetSavedQuestionsByAuthUserID(ctx, authUserID, limit, offset)
+		questions, err = h.savedQuestionRepo.GetSavedQuestionsByAuthUserID(ctx, authUserID, page.Limit, page.Offset)
+		if err == nil {
+			total, err = h.savedQuestionRepo.CountSavedQuestionsByAuthUserID(ctx, authUserID)
+		}
 	} else {
-		questions, err = h.savedQuestionRepo.GetSavedQuestions(ctx, userID, limit, offset)
+		questions, err = h.savedQuestionRepo.GetSavedQuestions(ctx, userID, page.Limit, page.Offset)
+		if err == nil {
+			total, err = h.savedQuestionRepo.CountSavedQuestions(ctx, userID)
+		}
 	}
 
 	if err != nil {
@@ -454,19 +1089,179 @@ func (h *InterviewHandler) HandleGetSavedQuestions(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Filter by tags if provided
+	// Filter by tags if provided. Filtering applies within the page, so total and
+	// has_more describe the unfiltered list.
+	pageCount := len(questions)
 	tagsParam := r.URL.Query().Get("tags")
 	if tagsParam != "" {
 		filterTags := strings.Split(tagsParam, ",")
 		questions = filterQuestionsByTags(questions, filterTags)
 	}
 
+	if questions == nil {
+		questions = []*models.SavedInterviewQuestion{}
+	}
+
 	// Return questions with pagination info
+	respondJSON(w, http.StatusOK, NewPage(questions, pageCount, total, page))
+}
+
+// Encodings of exported embeddings
+const (
+	embeddingEncodingFloat  = "float"  // JSON arrays of numbers
+	embeddingEncodingBase64 = "base64" // Base64 of the little-endian float32 values, as stored
+)
+
+// SavedQuestionEmbedding is a saved question with its embedding, for running another
+// vector search over the questions or migrating them
+type SavedQuestionEmbedding struct {
+	QuestionID      string    `json:"question_id"`
+	JobID           string    `json:"job_id"`
+	Question        string    `json:"question"`
+	Answer          string    `json:"answer"`
+	Dimensions      int       `json:"dimensions"`                 // 0 when the question has no usable embedding
+	Embedding       []float32 `json:"embedding,omitempty"`        // With encoding=float
+	EmbeddingBase64 []byte    `json:"embedding_base64,omitempty"` // With encoding=base64
+	Error           string    `json:"error,omitempty"`            // Why the stored embedding couldn't be read
+}
+
+// EmbeddingExportPage is a page of SavedQuestionEmbedding items
+type EmbeddingExportPage struct {
+	Page
+	Encoding string `json:"encoding"` // float or base64
+}
+
+// HandleExportEmbeddings handles GET /api/interview/embeddings/export?limit=100&offset=0&encoding=float
+// Returns the authenticated user's saved questions with their embeddings, newest first.
+// Embeddings are JSON arrays by default, or base64 of the stored bytes with encoding=base64.
+func (h *InterviewHandler) HandleExportEmbeddings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("encoding")))
+	if encoding == "" {
+		encoding = embeddingEncodingFloat
+	}
+	if encoding != embeddingEncodingFloat && encoding != embeddingEncodingBase64 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid encoding (use float or base64)"})
+		return
+	}
+
+	// Each embedding is several thousand numbers, so pages are capped well below the
+	// other list endpoints' totals
+	page := ParsePagination(r, 100, 500)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	questions, err := h.savedQuestionRepo.GetSavedQuestionsByAuthUserID(ctx, userID, page.Limit, page.Offset)
+	if err != nil {
+		log.Printf("Error getting saved questions of user %d for embedding export: %v", userID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve saved questions"})
+		return
+	}
+
+	total, err := h.savedQuestionRepo.CountSavedQuestionsByAuthUserID(ctx, userID)
+	if err != nil {
+		log.Printf("Error counting saved questions of user %d: %v", userID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve saved questions"})
+		return
+	}
+
+	items := make([]SavedQuestionEmbedding, 0, len(questions))
+	for _, q := range questions {
+		items = append(items, exportEmbedding(q, encoding))
+	}
+
+	respondJSON(w, http.StatusOK, EmbeddingExportPage{
+		Page:     NewPage(items, len(items), total, page),
+		Encoding: encoding,
+	})
+}
+
+// exportEmbedding deserializes a saved question's embedding for HandleExportEmbeddings
+func exportEmbedding(q *models.SavedInterviewQuestion, encoding string) SavedQuestionEmbedding {
+	item := SavedQuestionEmbedding{
+		QuestionID: q.QuestionID,
+		JobID:      q.JobID,
+		Question:   q.Question,
+		Answer:     q.Answer,
+	}
+	if len(q.QuestionEmbedding) == 0 {
+		return item
+	}
+
+	embedding, err := qamatcher.DeserializeEmbedding(q.QuestionEmbedding)
+	if err != nil {
+		log.Printf("Warning: unreadable embedding for saved question %d: %v", q.ID, err)
+		item.Error = "Stored embedding is invalid"
+		return item
+	}
+
+	item.Dimensions = len(embedding)
+	if encoding == embeddingEncodingBase64 {
+		item.EmbeddingBase64 = q.QuestionEmbedding
+	} else {
+		item.Embedding = embedding
+	}
+	return item
+}
+
+// HandleGetTagCounts returns the tags of a user's saved questions with how many questions
+// have each, most used first, for filtering the saved questions by tag.
+// Supports both user_id (string) and auth_user_id (integer) like HandleGetSavedQuestions.
+func (h *InterviewHandler) HandleGetTagCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	authUserIDStr := r.URL.Query().Get("auth_user_id")
+
+	if userID == "" && authUserIDStr == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required parameter: user_id or auth_user_id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var counts []*models.TagCount
+	var err error
+
+	// Prefer auth_user_id if provided, otherwise use user_id
+	if authUserIDStr != "" {
+		authUserID, parseErr := strconv.Atoi(authUserIDStr)
+		if parseErr != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid auth_user_id"})
+			return
+		}
+		counts, err = h.savedQuestionRepo.GetTagCountsByAuthUserID(ctx, authUserID)
+	} else {
+		counts, err = h.savedQuestionRepo.GetTagCounts(ctx, userID)
+	}
+
+	if err != nil {
+		log.Printf("Error getting tag counts: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve tag counts"})
+		return
+	}
+
+	if counts == nil {
+		counts = []*models.TagCount{}
+	}
+
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"questions": questions,
-		"limit":     limit,
-		"offset":    offset,
-		"count":     len(questions),
+		"tags": counts,
 	})
 }
 
@@ -479,7 +1274,7 @@ func filterQuestionsByTags(questions []*models.SavedInterviewQuestion, filterTag
 	// Normalize filter tags to lowercase
 	normalizedFilters := make(map[string]bool)
 	for _, tag := range filterTags {
-		normalizedFilters[strings.ToLower(strings.TrimSpace(tag))] = true
+		normalizedFilters[models.CanonicalQuestionTag(strings.ToLower(strings.TrimSpace(tag)))] = true
 	}
 
 	filtered := make([]*models.SavedInterviewQuestion, 0)
@@ -515,3 +1310,130 @@ func filterQuestionsByTags(questions []*models.SavedInterviewQuestion, filterTag
 
 	return filtered
 }
+
+const (
+	// maxDerivedKeywords is the number of keywords extracted from a question when tags are missing
+	maxDerivedKeywords = 4
+
+	// minKeywordLength is the minimum length of a word to be considered a keyword
+	minKeywordLength = 3
+)
+
+// tagStopWords contains common words that never make useful tags
+var tagStopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "an": true, "and": true, "any": true,
+	"are": true, "as": true, "at": true, "be": true, "been": true, "before": true, "being": true,
+	"between": true, "both": true, "but": true, "by": true, "can": true, "could": true, "describe": true,
+	"did": true, "do": true, "does": true, "doing": true, "during": true, "each": true, "example": true,
+	"explain": true, "for": true, "from": true, "give": true, "had": true, "has": true, "have": true,
+	"how": true, "if": true, "in": true, "into": true, "is": true, "it": true, "its": true, "me": true,
+	"more": true, "most": true, "my": true, "of": true, "on": true, "or": true, "other": true, "our": true,
+	"over": true, "should": true, "so": true, "some": true, "such": true, "tell": true, "than": true,
+	"that": true, "the": true, "their": true, "them": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "those": true, "through": true, "time": true, "to": true, "us": true,
+	"was": true, "walk": true, "we": true, "were": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "who": true, "why": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true,
+}
+
+// deriveQuestionTags builds tags for a question following the same rules as the generation prompt:
+// lowercase keywords extracted from the question, plus the category and difficulty
+func deriveQuestionTags(question, category, difficulty string) []string {
+	tags := make([]string, 0, maxDerivedKeywords+2)
+	seen := make(map[string]bool)
+
+	addTag := func(tag string) {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			return
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	addTag(category)
+	addTag(difficulty)
+
+	// Split on anything that can't be part of a technical term (keep e.g. "c++", "node.js", "ci/cd")
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#./-", r)
+	})
+
+	keywords := 0
+	for _, word := range words {
+		if keywords >= maxDerivedKeywords {
+			break
+		}
+
+		word = strings.Trim(word, ".-/")
+		if len(word) < minKeywordLength || tagStopWords[word] || seen[word] {
+			continue
+		}
+
+		addTag(word)
+		keywords++
+	}
+
+	return tags
+}
+
+// defaultLanguage is the language used when a request doesn't specify one
+const defaultLanguage = "en"
+
+// supportedLanguages maps supported language codes to the name used in prompts
+var supportedLanguages = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"zh": "Chinese (Simplified)",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"hi": "Hindi",
+}
+
+// normalizeLanguage validates a language code and returns it in canonical form.
+// An empty code resolves to the default language.
+func normalizeLanguage(language string) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return defaultLanguage, true
+	}
+
+	if _, ok := supportedLanguages[language]; !ok {
+		return "", false
+	}
+
+	return language, true
+}
+
+// supportedLanguageCodes returns the supported language codes as a sorted, comma-separated list
+func supportedLanguageCodes() string {
+	codes := make([]string, 0, len(supportedLanguages))
+	for code := range supportedLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return strings.Join(codes, ", ")
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and duplicate entries.
+// Tags naming a category or difficulty use its canonical spelling.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = models.CanonicalQuestionTag(strings.ToLower(strings.TrimSpace(tag)))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/your-org/websocket-server/internal/prompts"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// DefaultMaxInterviewPromptChars bounds the interview questions prompt (about 12k
+// tokens), leaving the model room for ten questions with answers
+const DefaultMaxInterviewPromptChars = 48000
+
+// Limits applied when trimming an oversized interview prompt
+const (
+	truncatedMarker           = "\n[... truncated]"
+	minTruncatedFieldChars    = 500 // Job text kept at the least, even if the prompt stays over the limit
+	essentialDescriptionChars = 300 // Experience descriptions are cut to this in an essential profile
+)
+
+// fitInterviewPrompt renders the interview questions prompt, shrinking it when it is
+// longer than maxChars. The job description is cut first, then the profile is reduced to
+// its essentials, then the job requirements are cut, since they drive the questions
+// most. Every step that was needed is logged.
+func fitInterviewPrompt(jobID string, profile interface{}, data prompts.InterviewQuestionsData, maxChars int) (string, error) {
+	prompt, err := prompts.Render(prompts.InterviewQuestions, data)
+	if err != nil || len(prompt) <= maxChars {
+		return prompt, err
+	}
+	originalLen := len(prompt)
+	var trimmed []string
+
+	if data.JobDescription != "" {
+		data.JobDescription = truncateText(data.JobDescription, max(len(data.JobDescription)-(len(prompt)-maxChars), minTruncatedFieldChars))
+		trimmed = append(trimmed, "job description")
+		if prompt, err = prompts.Render(prompts.InterviewQuestions, data); err != nil {
+			return "", err
+		}
+	}
+
+	if len(prompt) > maxChars {
+		if essentials, ok := essentialProfileJSON(profile); ok {
+			data.ProfileJSON = essentials
+			trimmed = append(trimmed, "profile")
+			if prompt, err = prompts.Render(prompts.InterviewQuestions, data); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if len(prompt) > maxChars {
+		data.JobRequirements = truncateText(data.JobRequirements, max(len(data.JobRequirements)-(len(prompt)-maxChars), minTruncatedFieldChars))
+		trimmed = append(trimmed, "job requirements")
+		if prompt, err = prompts.Render(prompts.InterviewQuestions, data); err != nil {
+			return "", err
+		}
+	}
+
+	log.Printf("Interview prompt for job %s was %d chars, over the %d limit: trimmed %s to %d chars",
+		jobID, originalLen, maxChars, strings.Join(trimmed, ", "), len(prompt))
+	return prompt, nil
+}
+
+// truncateText cuts text to at most maxLen bytes including truncatedMarker, at a word
+// boundary when there is one in the last fifth of the kept text
+func truncateText(text string, maxLen int) string {
+	if len(text) <= maxLen {
+		return text
+	}
+
+	cut := max(maxLen-len(truncatedMarker), 0)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if space := strings.LastIndexAny(text[:cut], " \t\n"); space > cut*4/5 {
+		cut = space
+	}
+
+	return strings.TrimRight(text[:cut], " \t\n") + truncatedMarker
+}
+
+// essentialProfile is the part of a profile interview questions are based on
+type essentialProfile struct {
+	Summary        *string                  `json:"summary,omitempty"`
+	TotalWorkYears *float64                 `json:"total_work_years,omitempty"`
+	Skills         map[string][]string      `json:"skills,omitempty"`
+	Experience     []models.ExperienceEntry `json:"experience,omitempty"`
+	Education      []models.EducationEntry  `json:"education,omitempty"`
+	Strengths      []string                 `json:"strengths,omitempty"`
+}
+
+// essentialProfileJSON renders the essentials of a *models.UserProfile as compact JSON,
+// dropping contact details, recommendations and metadata and shortening experience
+// descriptions. ok is false for other profile types.
+func essentialProfileJSON(profile interface{}) (string, bool) {
+	p, ok := profile.(*models.UserProfile)
+	if !ok || p == nil {
+		return "", false
+	}
+
+	essentials := essentialProfile{
+		Summary:        p.Summary,
+		TotalWorkYears: p.TotalWorkYears,
+		Skills:         p.Skills,
+		Education:      p.Education,
+		Strengths:      p.Strengths,
+	}
+	for _, exp := range p.Experience {
+		exp.Description = truncateText(exp.Description, essentialDescriptionChars)
+		exp.Flags = nil
+		essentials.Experience = append(essentials.Experience, exp)
+	}
+
+	data, err := json.Marshal(essentials)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
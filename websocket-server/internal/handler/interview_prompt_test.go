@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"short text kept", "Go and SQL", 20, "Go and SQL"},
+		{"cut at a word", "Build distributed systems in Go and SQL today", 42, "Build distributed systems" + truncatedMarker},
+		{"cut inside a long word", strings.Repeat("x", 40), 20, strings.Repeat("x", 4) + truncatedMarker},
+		{"runes not split", "ééééééééééé", 18, "é" + truncatedMarker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateText(tt.text, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+			if len(got) > max(tt.maxLen, len(truncatedMarker)) || !utf8.ValidString(got) {
+				t.Errorf("truncated text %q is longer than %d or invalid UTF-8", got, tt.maxLen)
+			}
+		})
+	}
+}
+
+func TestBuildInterviewPromptLimit(t *testing.T) {
+	summary := "Backend engineer building payment APIs"
+	email := "ada@example.com"
+	profile := func(descriptionWords int) *models.UserProfile {
+		return &models.UserProfile{
+			Email:      &email,
+			Summary:    &summary,
+			Experience: []models.ExperienceEntry{{Company: "Acme", Role: "Engineer", Description: strings.Repeat("shipped ", descriptionWords)}},
+		}
+	}
+	requirements := "5 years of Go; PostgreSQL; Kubernetes"
+	build := func(t *testing.T, maxChars int, p *models.UserProfile, req *InterviewRequest) string {
+		t.Helper()
+		h := NewInterviewHandler(nil, nil, nil, nil, nil, nil, nil, nil, maxChars)
+		prompt, err := h.buildInterviewPrompt(p, req)
+		if err != nil {
+			t.Fatalf("buildInterviewPrompt: %v", err)
+		}
+		return prompt
+	}
+
+	// The prompt without any job text, so limits leave the job text a known room
+	base := len(build(t, 1<<30, profile(10), &InterviewRequest{JobID: "job_1"}))
+
+	t.Run("small prompt unchanged", func(t *testing.T) {
+		req := &InterviewRequest{JobID: "job_1", JobDescription: "Build APIs", JobRequirements: requirements}
+		prompt := build(t, 0, profile(10), req)
+		if strings.Contains(prompt, truncatedMarker) || !strings.Contains(prompt, "Build APIs") || !strings.Contains(prompt, email) {
+			t.Errorf("prompt was trimmed:\n%s", prompt)
+		}
+	})
+
+	t.Run("oversized job description", func(t *testing.T) {
+		limit := base + 2000
+		req := &InterviewRequest{JobID: "job_1", JobDescription: strings.Repeat("Lorem ipsum ", 10000), JobRequirements: requirements}
+		prompt := build(t, limit, profile(10), req)
+		if len(prompt) > limit {
+			t.Errorf("prompt is %d chars, over the %d limit", len(prompt), limit)
+		}
+		if !strings.Contains(prompt, truncatedMarker) {
+			t.Error("job description isn't truncated")
+		}
+		// Only the description had to go
+		if !strings.Contains(prompt, requirements) || !strings.Contains(prompt, email) {
+			t.Error("requirements or full profile were trimmed too")
+		}
+	})
+
+	t.Run("oversized profile", func(t *testing.T) {
+		limit := base + 2000
+		req := &InterviewRequest{JobID: "job_1", JobDescription: strings.Repeat("Lorem ipsum ", 10000), JobRequirements: requirements}
+		prompt := build(t, limit, profile(2000), req)
+		if len(prompt) > limit {
+			t.Errorf("prompt is %d chars, over the %d limit", len(prompt), limit)
+		}
+		if strings.Contains(prompt, email) || !strings.Contains(prompt, summary) || !strings.Contains(prompt, requirements) {
+			t.Error("profile isn't reduced to its essentials with the requirements kept")
+		}
+	})
+
+	t.Run("oversized requirements", func(t *testing.T) {
+		limit := base + 1500
+		req := &InterviewRequest{JobID: "job_1", JobDescription: strings.Repeat("Lorem ipsum ", 10000), JobRequirements: strings.Repeat("Go ", 10000)}
+		prompt := build(t, limit, profile(10), req)
+		if len(prompt) > limit {
+			t.Errorf("prompt is %d chars, over the %d limit", len(prompt), limit)
+		}
+		if !strings.Contains(prompt, "Go"+truncatedMarker) {
+			t.Error("job requirements aren't truncated")
+		}
+	})
+}
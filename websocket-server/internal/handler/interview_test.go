@@ -0,0 +1,1108 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/guardrails"
+	"github.com/your-org/websocket-server/internal/qamatcher"
+	"github.com/your-org/websocket-server/internal/tier"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestHandleSaveQuestionTags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "derived from the question",
+			body: `{"user_id":"u1","job_id":"job_1","question":"How would you scale a Kubernetes cluster running Node.js services?","answer":"Carefully","category":"technical","difficulty":"Hard"}`,
+			want: []string{"technical", "hard", "scale", "kubernetes", "cluster", "running"},
+		},
+		{
+			name: "derived without category",
+			body: `{"user_id":"u1","job_id":"job_1","question":"Describe your experience with C++ and CI/CD","answer":"Lots"}`,
+			want: []string{"experience", "c++", "ci/cd"},
+		},
+		{
+			name: "empty tags derived",
+			body: `{"user_id":"u1","job_id":"job_1","question":"Explain Golang channels","answer":"Pipes","tags":[]}`,
+			want: []string{"golang", "channels"},
+		},
+		{
+			name: "provided tags kept",
+			body: `{"user_id":"u1","job_id":"job_1","question":"Explain Golang channels","answer":"Pipes","tags":["Concurrency"," go ","concurrency","Behavioural"]}`,
+			want: []string{"concurrency", "go", "behavioral"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSavedQuestions{}
+			h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+
+			w := serve(h.HandleSaveQuestion, httptest.NewRequest(http.MethodPost, "/api/interview/save", strings.NewReader(tt.body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			if len(repo.saved) != 1 {
+				t.Fatalf("saved %d questions, want 1", len(repo.saved))
+			}
+
+			tags := repo.saved[0].Tags
+			if !reflect.DeepEqual(tags, tt.want) {
+				t.Errorf("tags = %q, want %q", tags, tt.want)
+			}
+			for _, tag := range tags {
+				if tag != strings.ToLower(tag) {
+					t.Errorf("tag %q is not lowercase", tag)
+				}
+			}
+		})
+	}
+}
+
+func TestDeriveQuestionTags(t *testing.T) {
+	tests := []struct {
+		name                           string
+		question, category, difficulty string
+		want                           []string
+	}{
+		{"stop words skipped", "What is the best way to do this?", "", "", []string{"best", "way"}},
+		{"keywords capped", "Kafka Redis Postgres Docker Terraform", "", "", []string{"kafka", "redis", "postgres", "docker"}},
+		{"repeated words once", "Redis redis REDIS caching", "", "", []string{"redis", "caching"}},
+		{"category not repeated", "Technical design of technical systems", "technical", "easy", []string{"technical", "easy", "design", "systems"}},
+		{"no keywords", "Why?", "", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deriveQuestionTags(tt.question, tt.category, tt.difficulty)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deriveQuestionTags(%q, %q, %q) = %q, want %q", tt.question, tt.category, tt.difficulty, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterviewLanguage(t *testing.T) {
+	repo := &fakeAnalysisRepo{profiles: map[string]*models.UserProfile{"job_1": {JobID: "job_1", Name: strPtr("Ada")}}}
+
+	tests := []struct {
+		name         string
+		path         string
+		handler      func(*InterviewHandler) http.HandlerFunc
+		body         string
+		wantStatus   int
+		wantInPrompt string // Language instruction expected in the prompt, "" for none
+	}{
+		{
+			name:         "questions in Spanish",
+			path:         "/api/interview/generate",
+			handler:      func(h *InterviewHandler) http.HandlerFunc { return h.HandleGenerateQuestions },
+			body:         `{"job_id":"job_1","job_title":"Engineer","job_requirements":"Go","language":"ES"}`,
+			wantStatus:   http.StatusOK,
+			wantInPrompt: "Write the question and answer text in Spanish",
+		},
+		{
+			name:       "questions in English by default",
+			path:       "/api/interview/generate",
+			handler:    func(h *InterviewHandler) http.HandlerFunc { return h.HandleGenerateQuestions },
+			body:       `{"job_id":"job_1","job_title":"Engineer","job_requirements":"Go"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "questions in an unsupported language",
+			path:       "/api/interview/generate",
+			handler:    func(h *InterviewHandler) http.HandlerFunc { return h.HandleGenerateQuestions },
+			body:       `{"job_id":"job_1","job_title":"Engineer","job_requirements":"Go","language":"xx"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "answer in Japanese",
+			path:         "/api/interview/regenerate",
+			handler:      func(h *InterviewHandler) http.HandlerFunc { return h.HandleRegenerateAnswer },
+			body:         `{"job_id":"job_1","question":"Why Go?","language":"ja"}`,
+			wantStatus:   http.StatusOK,
+			wantInPrompt: "Write the answer in Japanese",
+		},
+		{
+			name:       "answer in English",
+			path:       "/api/interview/regenerate",
+			handler:    func(h *InterviewHandler) http.HandlerFunc { return h.HandleRegenerateAnswer },
+			body:       `{"job_id":"job_1","question":"Why Go?","language":"en"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "answer in an unsupported language",
+			path:       "/api/interview/regenerate",
+			handler:    func(h *InterviewHandler) http.HandlerFunc { return h.HandleRegenerateAnswer },
+			body:       `{"job_id":"job_1","question":"Why Go?","language":"klingon"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &recordingLLM{response: `{"questions":[]}`}
+			h := NewInterviewHandler(llm, repo, nil, nil, nil, nil, nil, nil, 0)
+
+			w := serve(tt.handler(h), httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(llm.prompts) != 0 {
+					t.Errorf("LLM called for a rejected request")
+				}
+				return
+			}
+
+			if len(llm.prompts) != 1 {
+				t.Fatalf("LLM called %d times, want 1", len(llm.prompts))
+			}
+			prompt := llm.prompts[0]
+			if tt.wantInPrompt != "" && !strings.Contains(prompt, tt.wantInPrompt) {
+				t.Errorf("prompt lacks %q:\n%s", tt.wantInPrompt, prompt)
+			}
+			if tt.wantInPrompt == "" && strings.Contains(prompt, "Language") {
+				t.Errorf("English prompt has a language instruction:\n%s", prompt)
+			}
+		})
+	}
+}
+
+func TestInterviewGuardrails(t *testing.T) {
+	repo := &fakeAnalysisRepo{profiles: map[string]*models.UserProfile{"job_1": {JobID: "job_1", Name: strPtr("Ada")}}}
+
+	t.Run("regenerated answer with a phone number is redacted", func(t *testing.T) {
+		llm := &recordingLLM{response: "Call me on 555-123-4567 and I will walk you through it."}
+		h := NewInterviewHandler(llm, repo, nil, nil, nil, nil, nil, nil, 0)
+
+		body := `{"job_id":"job_1","question":"How can we reach you?"}`
+		w := serve(h.HandleRegenerateAnswer, httptest.NewRequest(http.MethodPost, "/api/interview/regenerate", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		var resp RegenerateAnswerResponse
+		decodeBody(t, w, &resp)
+		if strings.Contains(resp.Answer, "555-123-4567") || !strings.Contains(resp.Answer, "[REDACTED]") {
+			t.Errorf("answer not redacted: %q", resp.Answer)
+		}
+		if !reflect.DeepEqual(resp.Redacted, []guardrails.PIIType{guardrails.PIIPhone}) {
+			t.Errorf("redacted = %v, want [phone]", resp.Redacted)
+		}
+	})
+
+	t.Run("opted-in answer keeps the phone number", func(t *testing.T) {
+		llm := &recordingLLM{response: "Call me on 555-123-4567 and I will walk you through it."}
+		h := NewInterviewHandler(llm, repo, nil, nil, nil, nil, nil, nil, 0)
+
+		body := `{"job_id":"job_1","question":"How can we reach you?","allow_pii":true}`
+		w := serve(h.HandleRegenerateAnswer, httptest.NewRequest(http.MethodPost, "/api/interview/regenerate", strings.NewReader(body)))
+
+		var resp RegenerateAnswerResponse
+		decodeBody(t, w, &resp)
+		if !strings.Contains(resp.Answer, "555-123-4567") || len(resp.Redacted) != 0 {
+			t.Errorf("opted-in answer changed: %q (redacted %v)", resp.Answer, resp.Redacted)
+		}
+	})
+
+	t.Run("generated questions are filtered and flagged", func(t *testing.T) {
+		llm := &recordingLLM{response: `{"questions":[
+			{"question":"Why did the shit deploy fail?","category":"technical","difficulty":"medium","answer":"Email ada@example.com for the postmortem."},
+			{"question":"Why Go?","category":"technical","difficulty":"easy","answer":"It keeps services simple."}
+		]}`}
+		h := NewInterviewHandler(llm, repo, nil, nil, nil, nil, nil, nil, 0)
+
+		body := `{"job_id":"job_1","job_title":"Engineer","job_requirements":"Go"}`
+		w := serve(h.HandleGenerateQuestions, httptest.NewRequest(http.MethodPost, "/api/interview/generate", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		var resp InterviewResponse
+		decodeBody(t, w, &resp)
+		if len(resp.Questions) != 2 {
+			t.Fatalf("got %d questions, want 2", len(resp.Questions))
+		}
+
+		flagged := resp.Questions[0]
+		if strings.Contains(flagged.Answer, "ada@example.com") {
+			t.Errorf("answer not redacted: %q", flagged.Answer)
+		}
+		if !reflect.DeepEqual(flagged.Redacted, []guardrails.PIIType{guardrails.PIIEmail}) {
+			t.Errorf("redacted = %v, want [email]", flagged.Redacted)
+		}
+		if !reflect.DeepEqual(flagged.Flags, []string{"shit"}) {
+			t.Errorf("flags = %v, want [shit]", flagged.Flags)
+		}
+
+		clean := resp.Questions[1]
+		if len(clean.Flags) != 0 || len(clean.Redacted) != 0 {
+			t.Errorf("clean question flagged %v, redacted %v", clean.Flags, clean.Redacted)
+		}
+	})
+}
+
+func TestMergeFlags(t *testing.T) {
+	tests := []struct {
+		a, b, want []string
+	}{
+		{nil, nil, nil},
+		{nil, []string{"shit"}, []string{"shit"}},
+		{[]string{"shit"}, nil, []string{"shit"}},
+		{[]string{"shit"}, []string{"bastard", "shit"}, []string{"bastard", "shit"}},
+	}
+
+	for _, tt := range tests {
+		if got := mergeFlags(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mergeFlags(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestInterviewTiers(t *testing.T) {
+	repo := &fakeAnalysisRepo{profiles: map[string]*models.UserProfile{"job_1": {JobID: "job_1", Name: strPtr("Ada")}}}
+	users := &fakeUsers{users: map[int]*models.User{1: {ID: 1, Tier: tier.Free}, 2: {ID: 2, Tier: tier.Paid}}}
+
+	standard := &recordingLLM{response: "Standard answer."}
+	mini := &recordingLLM{response: "Mini answer."}
+	large := &recordingLLM{response: "Large answer."}
+
+	defaultPlan := tier.NewPlan(tier.Plan{LLMClient: standard}, nil)
+	plans := map[string]*tier.Plan{
+		tier.Free: tier.NewPlan(tier.Plan{Tier: tier.Free, LLMClient: mini, RequestsPerMinute: 2}, defaultPlan),
+		tier.Paid: tier.NewPlan(tier.Plan{Tier: tier.Paid, LLMClient: large}, defaultPlan),
+	}
+	h := NewInterviewHandler(standard, repo, nil, nil, nil, tier.NewUserResolver(headerAuth{}, users, defaultPlan, plans), nil, nil, 0)
+
+	regenerate := func(userID int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/interview/regenerate", strings.NewReader(`{"job_id":"job_1","question":"Why Go?"}`))
+		if userID != 0 {
+			r = asUser(r, userID)
+		}
+		return serve(h.HandleRegenerateAnswer, r)
+	}
+
+	t.Run("free user gets the cheaper model and rate limit", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := regenerate(1)
+			var resp RegenerateAnswerResponse
+			decodeBody(t, w, &resp)
+			if w.Code != http.StatusOK || resp.Answer != "Mini answer." {
+				t.Fatalf("request %d = %d %q, want the mini model's answer", i+1, w.Code, resp.Answer)
+			}
+		}
+
+		if w := regenerate(1); w.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want 429 over the free limit", w.Code)
+		}
+		if len(mini.prompts) != 2 {
+			t.Errorf("mini model called %d times, want 2", len(mini.prompts))
+		}
+	})
+
+	t.Run("paid user gets the stronger model without a limit", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			w := regenerate(2)
+			var resp RegenerateAnswerResponse
+			decodeBody(t, w, &resp)
+			if w.Code != http.StatusOK || resp.Answer != "Large answer." {
+				t.Fatalf("request %d = %d %q, want the large model's answer", i+1, w.Code, resp.Answer)
+			}
+		}
+	})
+
+	t.Run("anonymous user gets the default plan", func(t *testing.T) {
+		w := regenerate(0)
+		var resp RegenerateAnswerResponse
+		decodeBody(t, w, &resp)
+		if resp.Answer != "Standard answer." {
+			t.Errorf("answer = %q, want the default model's", resp.Answer)
+		}
+	})
+}
+
+func TestHandleSaveQuestionNormalizesCategoryAndDifficulty(t *testing.T) {
+	tests := []struct {
+		name           string
+		category       string
+		difficulty     string
+		wantStatus     int
+		wantCategory   string
+		wantDifficulty string
+	}{
+		{"variant spellings", "behavioural", "intermediate", http.StatusOK, models.CategoryBehavioral, models.DifficultyMedium},
+		{"canonical values", "Problem-Solving", "Hard", http.StatusOK, models.CategoryProblemSolving, models.DifficultyHard},
+		{"empty values", "", "", http.StatusOK, "", ""},
+		{"unknown category", "trivia", "easy", http.StatusBadRequest, "", ""},
+		{"unknown difficulty", "technical", "impossible", http.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSavedQuestions{}
+			h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+
+			body := fmt.Sprintf(`{"user_id":"u1","job_id":"job_1","question":"Tell me about a conflict","answer":"Calmly","category":%q,"difficulty":%q}`, tt.category, tt.difficulty)
+			w := serve(h.HandleSaveQuestion, httptest.NewRequest(http.MethodPost, "/api/interview/save", strings.NewReader(body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(repo.saved) != 0 {
+					t.Errorf("invalid question was saved")
+				}
+				return
+			}
+
+			saved := repo.saved[0]
+			if saved.Category != tt.wantCategory || saved.Difficulty != tt.wantDifficulty {
+				t.Errorf("saved %q/%q, want %q/%q", saved.Category, saved.Difficulty, tt.wantCategory, tt.wantDifficulty)
+			}
+		})
+	}
+}
+
+func TestParseQuestionsNormalizesCategoryAndDifficulty(t *testing.T) {
+	h := NewInterviewHandler(nil, nil, nil, nil, nil, nil, nil, nil, 0)
+	response := `{"questions":[
+		{"question":"Describe a disagreement","category":"Behavioural","difficulty":"intermediate","tags":["Behavioural","teamwork"]},
+		{"question":"Design a cache","category":"trivia","difficulty":"impossible"}
+	]}`
+
+	questions := h.parseQuestionsFromLLMResponse("job_1", response)
+	if len(questions) != 2 {
+		t.Fatalf("parsed %d questions, want 2 (unknown values don't drop a question)", len(questions))
+	}
+
+	first, second := questions[0], questions[1]
+	if first.Category != models.CategoryBehavioral || first.Difficulty != models.DifficultyMedium {
+		t.Errorf("first question = %q/%q, want %q/%q", first.Category, first.Difficulty, models.CategoryBehavioral, models.DifficultyMedium)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"behavioral", "teamwork"}) {
+		t.Errorf("tags = %q, want canonical spellings", first.Tags)
+	}
+	if second.Category != "" || second.Difficulty != "" {
+		t.Errorf("unknown values kept as %q/%q, want them dropped", second.Category, second.Difficulty)
+	}
+}
+
+func TestHandleSaveQuestionEmbeddingRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		wantCalls     int
+		wantEmbedding bool
+	}{
+		{"first attempt succeeds", 0, 1, true},
+		{"transient failure is retried", 1, 2, true},
+		{"persistent failure schedules a backfill", saveEmbeddingAttempts, saveEmbeddingAttempts, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSavedQuestions{}
+			embedder := &flakyEmbedder{failures: tt.failures, vector: []float32{0.6, 0.8}}
+			backfill := &recordingScheduler{}
+			h := NewInterviewHandler(nil, nil, repo, embedder, nil, nil, backfill, nil, 0)
+
+			body := `{"user_id":"u1","job_id":"job_1","question":"Explain Golang channels","answer":"Pipes"}`
+			w := serve(h.HandleSaveQuestion, httptest.NewRequest(http.MethodPost, "/api/interview/save", strings.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+			if embedder.calls != tt.wantCalls {
+				t.Errorf("embedder called %d times, want %d", embedder.calls, tt.wantCalls)
+			}
+			if got := repo.embeddings[0] != nil; got != tt.wantEmbedding {
+				t.Errorf("saved with embedding = %v, want %v", got, tt.wantEmbedding)
+			}
+
+			var resp struct {
+				EmbeddingPending bool `json:"embedding_pending"`
+			}
+			decodeBody(t, w, &resp)
+			if resp.EmbeddingPending == tt.wantEmbedding {
+				t.Errorf("embedding_pending = %v, want %v", resp.EmbeddingPending, !tt.wantEmbedding)
+			}
+
+			var wantScheduled []int64
+			if !tt.wantEmbedding {
+				wantScheduled = []int64{1}
+			}
+			if !reflect.DeepEqual(backfill.scheduled, wantScheduled) {
+				t.Errorf("scheduled %v, want %v", backfill.scheduled, wantScheduled)
+			}
+		})
+	}
+}
+
+func TestGenerateEmbeddingWithRetryStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	embedder := &flakyEmbedder{failures: saveEmbeddingAttempts}
+	if _, err := generateEmbeddingWithRetry(ctx, embedder, "question"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if embedder.calls != 1 {
+		t.Errorf("embedder called %d times after cancellation, want 1", embedder.calls)
+	}
+}
+
+func TestHandleRegenerateAllAnswers(t *testing.T) {
+	// Long enough to pass the quality check and be saved
+	const newAnswer = "I moved our billing service to Go and halved its p99 latency without slowing our releases."
+	profiles := &fakeAnalysisRepo{profiles: map[string]*models.UserProfile{"job_1": {JobID: "job_1", Name: strPtr("Ada")}}}
+	questions := func(n int) []*models.SavedInterviewQuestion {
+		var saved []*models.SavedInterviewQuestion
+		for i := 1; i <= n; i++ {
+			saved = append(saved, &models.SavedInterviewQuestion{
+				UserID: "u1", JobID: "job_1", QuestionID: fmt.Sprintf("q%d", i), Question: fmt.Sprintf("Question %d?", i),
+			})
+		}
+		return saved
+	}
+	regenerateAll := func(h *InterviewHandler, body string) *httptest.ResponseRecorder {
+		return serve(h.HandleRegenerateAllAnswers, httptest.NewRequest(http.MethodPost, "/api/interview/regenerate-all", strings.NewReader(body)))
+	}
+	statuses := func(resp RegenerateAllAnswersResponse) []string {
+		var got []string
+		for _, result := range resp.Results {
+			got = append(got, result.QuestionID+":"+result.Status)
+		}
+		return got
+	}
+
+	t.Run("answers are regenerated and saved", func(t *testing.T) {
+		repo := &fakeSavedQuestions{byJob: questions(5)}
+		llm := &recordingLLM{response: "`" + newAnswer + "`"}
+		h := NewInterviewHandler(llm, profiles, repo, nil, nil, nil, nil, nil, 0)
+
+		w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`)
+		var resp RegenerateAllAnswersResponse
+		decodeBody(t, w, &resp)
+		if w.Code != http.StatusOK || resp.Updated != 5 || resp.Failed != 0 || resp.RateLimited != 0 {
+			t.Fatalf("status %d, response %+v; want 5 updated", w.Code, resp)
+		}
+		want := []string{"q1:updated", "q2:updated", "q3:updated", "q4:updated", "q5:updated"}
+		if got := statuses(resp); !reflect.DeepEqual(got, want) {
+			t.Errorf("results = %v, want %v", got, want)
+		}
+		for _, q := range repo.byJob {
+			if repo.answers[q.QuestionID] != newAnswer {
+				t.Errorf("%s saved %q, want the cleaned new answer", q.QuestionID, repo.answers[q.QuestionID])
+			}
+		}
+		if len(llm.prompts) != 5 || !strings.Contains(llm.prompts[0], `"name": "Ada"`) {
+			t.Errorf("LLM called %d times, want 5 with the current profile", len(llm.prompts))
+		}
+	})
+
+	t.Run("failures do not abort the batch", func(t *testing.T) {
+		repo := &fakeSavedQuestions{byJob: questions(3), failUpdates: map[string]bool{"q2": true}}
+		h := NewInterviewHandler(&recordingLLM{response: newAnswer}, profiles, repo, nil, nil, nil, nil, nil, 0)
+
+		w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`)
+		var resp RegenerateAllAnswersResponse
+		decodeBody(t, w, &resp)
+		want := []string{"q1:updated", "q2:failed", "q3:updated"}
+		if got := statuses(resp); w.Code != http.StatusOK || !reflect.DeepEqual(got, want) {
+			t.Fatalf("status %d, results %v; want 200 and %v", w.Code, got, want)
+		}
+		if resp.Updated != 2 || resp.Failed != 1 || resp.Results[1].Error != "Failed to save answer" {
+			t.Errorf("response = %+v, want q2 reported as failing to save", resp)
+		}
+		if _, ok := repo.answers["q3"]; !ok {
+			t.Error("q3 not saved after q2 failed")
+		}
+	})
+
+	t.Run("generation failures are reported", func(t *testing.T) {
+		repo := &fakeSavedQuestions{byJob: questions(2)}
+		h := NewInterviewHandler(&recordingLLM{err: errors.New("model unavailable")}, profiles, repo, nil, nil, nil, nil, nil, 0)
+
+		w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`)
+		var resp RegenerateAllAnswersResponse
+		decodeBody(t, w, &resp)
+		if w.Code != http.StatusOK || resp.Failed != 2 || len(repo.answers) != 0 {
+			t.Errorf("status %d, response %+v; want both failed and nothing saved", w.Code, resp)
+		}
+	})
+
+	t.Run("low quality answers are held back", func(t *testing.T) {
+		repo := &fakeSavedQuestions{byJob: questions(2)}
+		truncated := "I moved our billing service to Go and halved its p99 latency, which let the team finally"
+		h := NewInterviewHandler(&recordingLLM{response: truncated}, profiles, repo, nil, nil, nil, nil, nil, 0)
+
+		w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`)
+		var resp RegenerateAllAnswersResponse
+		decodeBody(t, w, &resp)
+		want := []string{"q1:low_quality", "q2:low_quality"}
+		if got := statuses(resp); w.Code != http.StatusOK || !reflect.DeepEqual(got, want) {
+			t.Fatalf("status %d, results %v; want 200 and %v", w.Code, got, want)
+		}
+		if resp.LowQuality != 2 || resp.Updated != 0 || !reflect.DeepEqual(resp.Results[0].Issues, []string{guardrails.IssueTruncated}) {
+			t.Errorf("response = %+v, want both held back as truncated", resp)
+		}
+		if resp.Results[0].Answer != truncated {
+			t.Errorf("answer = %q, want the held back answer returned for review", resp.Results[0].Answer)
+		}
+		if len(repo.answers) != 0 {
+			t.Errorf("saved %v, want the old answers kept", repo.answers)
+		}
+	})
+
+	t.Run("questions over the rate limit are reported", func(t *testing.T) {
+		repo := &fakeSavedQuestions{byJob: questions(6)}
+		llm := &recordingLLM{response: newAnswer}
+		plan := tier.NewPlan(tier.Plan{LLMClient: llm, RequestsPerMinute: 5}, nil)
+		h := NewInterviewHandler(llm, profiles, repo, nil, nil, tier.NewStaticResolver(plan), nil, nil, 0)
+
+		w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`)
+		var resp RegenerateAllAnswersResponse
+		decodeBody(t, w, &resp)
+		if w.Code != http.StatusOK || resp.Updated != 5 || resp.RateLimited != 1 || resp.Results[5].Status != "rate_limited" {
+			t.Fatalf("status %d, response %+v; want 5 updated and q6 rate limited", w.Code, resp)
+		}
+
+		// With nothing left in the limit the whole request is refused
+		if w := regenerateAll(h, `{"user_id":"u1","job_id":"job_1"}`); w.Code != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want 429 once the limit is used up", w.Code)
+		}
+		if len(llm.prompts) != 5 {
+			t.Errorf("LLM called %d times, want 5", len(llm.prompts))
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		h := NewInterviewHandler(&recordingLLM{}, profiles, &fakeSavedQuestions{byJob: questions(1)}, nil, nil, nil, nil, nil, 0)
+		for _, body := range []string{`{"user_id":"u1"}`, `{"user_id":"u1","job_id":"job_1","language":"klingon"}`, `not json`} {
+			if w := regenerateAll(h, body); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", body, w.Code)
+			}
+		}
+		if w := regenerateAll(h, `{"user_id":"u1","job_id":"job_2"}`); w.Code != http.StatusNotFound {
+			t.Errorf("unknown profile: status = %d, want 404", w.Code)
+		}
+	})
+}
+
+func TestHandleDownloadPrepPack(t *testing.T) {
+	repo := &fakeAnalysisRepo{
+		profiles: map[string]*models.UserProfile{"job_1": {JobID: "job_1", Name: strPtr("Ada")}},
+		jobs: map[string]*models.AnalysisJob{
+			"job_1": {JobID: "job_1", UserID: intPtr(7)},
+			"job_2": {JobID: "job_2", UserID: intPtr(7)},
+		},
+	}
+	saved := []*models.SavedInterviewQuestion{{UserID: "7", JobID: "job_1", Question: "Why Go?", Answer: "It compiles fast."}}
+
+	tests := []struct {
+		name       string
+		caller     int // 0 for no session
+		query      string
+		questions  []*models.SavedInterviewQuestion
+		wantStatus int
+	}{
+		{"profile and questions", 7, "?job_id=job_1", saved, http.StatusOK},
+		{"profile only", 7, "?job_id=job_1", nil, http.StatusOK},
+		{"questions only", 7, "?job_id=job_2&user_id=7", saved, http.StatusOK},
+		{"nothing to export", 7, "?job_id=job_2", nil, http.StatusNotFound},
+		{"unknown job", 7, "?job_id=job_3", saved, http.StatusNotFound},
+		{"missing job ID", 7, "", saved, http.StatusBadRequest},
+		{"another user's job", 8, "?job_id=job_1", saved, http.StatusForbidden},
+		{"another user's questions", 7, "?job_id=job_1&user_id=8", saved, http.StatusForbidden},
+		{"no session", 0, "?job_id=job_1", saved, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewInterviewHandler(nil, repo, &fakeSavedQuestions{byJob: tt.questions}, nil, nil, nil, nil, headerAuth{}, 0)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/interview/prep-pack"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleDownloadPrepPack, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != "application/pdf" {
+				t.Errorf("content type = %q, want application/pdf", got)
+			}
+			if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, "interview_prep_pack_job_") {
+				t.Errorf("content disposition = %q", got)
+			}
+			if !strings.HasPrefix(w.Body.String(), "%PDF-") {
+				t.Errorf("body is not a PDF")
+			}
+		})
+	}
+}
+
+func TestGeneratedQuestionIDs(t *testing.T) {
+	h := NewInterviewHandler(nil, nil, nil, nil, nil, nil, nil, nil, 0)
+
+	// Models number their questions from q1 on every generation
+	first := h.parseQuestionsFromLLMResponse("job_1", `{"questions":[
+		{"id":"q1","question":"Why Go?"},
+		{"id":"q2","question":"Describe an outage you handled"},
+		{"id":"q3","question":"why  go?"},
+		{"id":"q4","question":"  "}
+	]}`)
+	second := h.parseQuestionsFromLLMResponse("job_1", `{"questions":[
+		{"id":"q1","question":"Design a rate limiter"},
+		{"id":"q2","question":"Why Go?"}
+	]}`)
+
+	if len(first) != 2 {
+		t.Fatalf("first generation has %d questions, want 2 without the repeat and the blank one", len(first))
+	}
+	ids := make(map[string]string)
+	for _, q := range append(first, second...) {
+		if q.ID != models.QuestionID("job_1", q.Question) {
+			t.Errorf("%q has ID %q, want one derived from its text", q.Question, q.ID)
+		}
+		if other, ok := ids[q.ID]; ok && other != q.Question {
+			t.Errorf("%q and %q share ID %q", other, q.Question, q.ID)
+		}
+		ids[q.ID] = q.Question
+	}
+
+	// The same question asked again keeps its ID, so saving it updates the saved copy
+	if first[0].ID != second[1].ID {
+		t.Errorf("Why Go? has IDs %q and %q across generations", first[0].ID, second[1].ID)
+	}
+	if len(ids) != 3 {
+		t.Errorf("generations have %d distinct IDs, want 3", len(ids))
+	}
+}
+
+func TestHandleSaveQuestionDerivesQuestionID(t *testing.T) {
+	repo := &fakeSavedQuestions{}
+	h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+	save := func(body string) {
+		t.Helper()
+		w := serve(h.HandleSaveQuestion, httptest.NewRequest(http.MethodPost, "/api/interview/save", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	save(`{"user_id":"u1","job_id":"job_1","question":"Why Go?","answer":"It compiles fast."}`)
+	save(`{"user_id":"u1","job_id":"job_1","question":"Why Go? ","answer":"Simple concurrency."}`)
+	save(`{"user_id":"u1","job_id":"job_1","question_id":"custom","question":"Why Go?","answer":"Tooling."}`)
+
+	// Re-saving the same question without an ID uses the same upsert key
+	want := models.QuestionID("job_1", "Why Go?")
+	if repo.saved[0].QuestionID != want || repo.saved[1].QuestionID != want {
+		t.Errorf("saved IDs %q and %q, want both %q", repo.saved[0].QuestionID, repo.saved[1].QuestionID, want)
+	}
+	if repo.saved[2].QuestionID != "custom" {
+		t.Errorf("explicit ID replaced with %q", repo.saved[2].QuestionID)
+	}
+}
+
+func TestHandleGetTagCounts(t *testing.T) {
+	ada := 7
+	repo := &fakeSavedQuestions{questions: []*models.SavedInterviewQuestion{
+		{UserID: "u1", Tags: []string{"go", "concurrency"}},
+		{UserID: "u1", Tags: []string{"go", "behavioral", "go"}},
+		{UserID: "u1", Tags: []string{"kubernetes", "go", "behavioral"}},
+		{UserID: "u1"},
+		{UserID: "u2", Tags: []string{"python", "python"}},
+		{UserID: "u3", AuthUserID: &ada, Tags: []string{"sql"}},
+		{UserID: "u4", AuthUserID: &ada, Tags: []string{"sql", "go"}},
+	}}
+	h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []models.TagCount
+	}{
+		{
+			name:  "by user_id, most used first and ties by tag",
+			query: "user_id=u1",
+			want:  []models.TagCount{{Tag: "go", Count: 3}, {Tag: "behavioral", Count: 2}, {Tag: "concurrency", Count: 1}, {Tag: "kubernetes", Count: 1}},
+		},
+		{
+			name:  "repeated tag counts its question once",
+			query: "user_id=u2",
+			want:  []models.TagCount{{Tag: "python", Count: 1}},
+		},
+		{
+			name:  "by auth_user_id, preferred over user_id",
+			query: "auth_user_id=7&user_id=u1",
+			want:  []models.TagCount{{Tag: "sql", Count: 2}, {Tag: "go", Count: 1}},
+		},
+		{
+			name:  "no questions",
+			query: "user_id=nobody",
+			want:  []models.TagCount{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleGetTagCounts, httptest.NewRequest(http.MethodGet, "/api/interview/tags?"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var body struct {
+				Tags []models.TagCount `json:"tags"`
+			}
+			decodeBody(t, w, &body)
+			if body.Tags == nil || !reflect.DeepEqual(body.Tags, tt.want) {
+				t.Errorf("tags = %+v, want %+v", body.Tags, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, tt := range []struct {
+			method, query string
+			want          int
+		}{
+			{http.MethodPost, "user_id=u1", http.StatusMethodNotAllowed},
+			{http.MethodGet, "", http.StatusBadRequest},
+			{http.MethodGet, "auth_user_id=ada", http.StatusBadRequest},
+		} {
+			w := serve(h.HandleGetTagCounts, httptest.NewRequest(tt.method, "/api/interview/tags?"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s ?%s: status = %d, want %d", tt.method, tt.query, w.Code, tt.want)
+			}
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		failing := &fakeSavedQuestions{tagCountsErr: errors.New("connection refused")}
+		h := NewInterviewHandler(nil, nil, failing, nil, nil, nil, nil, nil, 0)
+
+		w := serve(h.HandleGetTagCounts, httptest.NewRequest(http.MethodGet, "/api/interview/tags?user_id=u1", nil))
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "connection refused") {
+			t.Errorf("status = %d (%s), want 500 without the cause", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestHandleCountSavedQuestions(t *testing.T) {
+	repo := &fakeSavedQuestions{questions: []*models.SavedInterviewQuestion{
+		{UserID: "u1", JobID: "job_1", QuestionID: "q1"},
+		{UserID: "u1", JobID: "job_1", QuestionID: "q2"},
+		{UserID: "u1", JobID: "job_1", QuestionID: "q3"},
+		{UserID: "u1", JobID: "job_2", QuestionID: "q1"},
+		{UserID: "u2", JobID: "job_1", QuestionID: "q1"},
+	}}
+	h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"user_id=u1&job_id=job_1", 3},
+		{"user_id=u1&job_id=job_2", 1},
+		{"user_id=u2&job_id=job_1", 1},
+		{"user_id=u2&job_id=job_2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(h.HandleCountSavedQuestions, httptest.NewRequest(http.MethodGet, "/api/interview/saved-count?"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var body struct {
+				UserID string `json:"user_id"`
+				JobID  string `json:"job_id"`
+				Count  int    `json:"count"`
+			}
+			decodeBody(t, w, &body)
+			if body.Count != tt.want || tt.query != "user_id="+body.UserID+"&job_id="+body.JobID {
+				t.Errorf("body = %+v, want a count of %d for %s", body, tt.want, tt.query)
+			}
+		})
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, tt := range []struct {
+			method, query string
+			want          int
+		}{
+			{http.MethodPost, "user_id=u1&job_id=job_1", http.StatusMethodNotAllowed},
+			{http.MethodGet, "user_id=u1", http.StatusBadRequest},
+			{http.MethodGet, "job_id=job_1", http.StatusBadRequest},
+		} {
+			w := serve(h.HandleCountSavedQuestions, httptest.NewRequest(tt.method, "/api/interview/saved-count?"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Errorf("%s ?%s: status = %d, want %d", tt.method, tt.query, w.Code, tt.want)
+			}
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		failing := &fakeSavedQuestions{countErr: errors.New("connection refused")}
+		h := NewInterviewHandler(nil, nil, failing, nil, nil, nil, nil, nil, 0)
+
+		w := serve(h.HandleCountSavedQuestions, httptest.NewRequest(http.MethodGet, "/api/interview/saved-count?user_id=u1&job_id=job_1", nil))
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "connection refused") {
+			t.Errorf("status = %d (%s), want 500 without the cause", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestHandleCheckSavedBatch(t *testing.T) {
+	repo := &fakeSavedQuestions{questions: []*models.SavedInterviewQuestion{
+		{UserID: "u1", JobID: "job_1", QuestionID: "q1"},
+		{UserID: "u1", JobID: "job_2", QuestionID: "q2"},
+		{UserID: "u2", JobID: "job_1", QuestionID: "q2"},
+	}}
+	h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, nil, 0)
+
+	check := func(body string) *httptest.ResponseRecorder {
+		return serve(h.HandleCheckSavedBatch, httptest.NewRequest(http.MethodPost, "/api/interview/check-saved-batch", strings.NewReader(body)))
+	}
+
+	t.Run("saved and unsaved questions", func(t *testing.T) {
+		w := check(`{"user_id": "u1", "questions": [
+			{"job_id": "job_1", "question_id": "q1"},
+			{"job_id": "job_1", "question_id": "q2"},
+			{"job_id": "job_2", "question_id": "q2"},
+			{"job_id": "job_3", "question_id": "q1"}
+		]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			UserID  string                     `json:"user_id"`
+			IsSaved map[string]map[string]bool `json:"is_saved"`
+		}
+		decodeBody(t, w, &body)
+
+		want := map[string]map[string]bool{
+			"job_1": {"q1": true, "q2": false}, // q2 of job_1 is saved by another user
+			"job_2": {"q2": true},
+			"job_3": {"q1": false},
+		}
+		if body.UserID != "u1" || !reflect.DeepEqual(body.IsSaved, want) {
+			t.Errorf("body = %+v, want %v for u1", body, want)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tooMany := `{"user_id": "u1", "questions": [` + strings.Repeat(`{"job_id": "j", "question_id": "q"},`, maxCheckSavedBatch) + `{"job_id": "j", "question_id": "q"}]}`
+		for name, body := range map[string]string{
+			"malformed":           `{"user_id": `,
+			"no user":             `{"questions": [{"job_id": "job_1", "question_id": "q1"}]}`,
+			"no questions":        `{"user_id": "u1", "questions": []}`,
+			"question without ID": `{"user_id": "u1", "questions": [{"job_id": "job_1"}]}`,
+			"too many questions":  tooMany,
+		} {
+			if w := check(body); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", name, w.Code)
+			}
+		}
+
+		w := serve(h.HandleCheckSavedBatch, httptest.NewRequest(http.MethodGet, "/api/interview/check-saved-batch", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("GET: status = %d, want 405", w.Code)
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		failing := &fakeSavedQuestions{savedRefsErr: errors.New("connection refused")}
+		h := NewInterviewHandler(nil, nil, failing, nil, nil, nil, nil, nil, 0)
+
+		w := serve(h.HandleCheckSavedBatch, httptest.NewRequest(http.MethodPost, "/api/interview/check-saved-batch",
+			strings.NewReader(`{"user_id": "u1", "questions": [{"job_id": "job_1", "question_id": "q1"}]}`)))
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "connection refused") {
+			t.Errorf("status = %d (%s), want 500 without the cause", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestHandleExportEmbeddings(t *testing.T) {
+	vector := []float32{0.125, -2.5, 3.25e-3, 1e-7}
+	stored, err := qamatcher.SerializeEmbedding(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &fakeSavedQuestions{questions: []*models.SavedInterviewQuestion{
+		{AuthUserID: intPtr(7), QuestionID: "q_embedded", JobID: "job_1", Question: "Why Go?", Answer: "Simplicity.", QuestionEmbedding: stored},
+		{AuthUserID: intPtr(7), QuestionID: "q_pending", JobID: "job_1", Question: "Why SQL?"},
+		{AuthUserID: intPtr(7), QuestionID: "q_corrupt", JobID: "job_2", Question: "Why Rust?", QuestionEmbedding: []byte{1, 2, 3}},
+		{AuthUserID: intPtr(8), QuestionID: "q_other", JobID: "job_3", Question: "Why Java?", QuestionEmbedding: stored},
+	}}
+	h := NewInterviewHandler(nil, nil, repo, nil, nil, nil, nil, headerAuth{}, 0)
+
+	type exportPage struct {
+		Items    []SavedQuestionEmbedding `json:"items"`
+		Total    int                      `json:"total"`
+		Limit    int                      `json:"limit"`
+		Offset   int                      `json:"offset"`
+		Encoding string                   `json:"encoding"`
+	}
+	export := func(t *testing.T, query string) exportPage {
+		t.Helper()
+		w := serve(h.HandleExportEmbeddings, asUser(httptest.NewRequest(http.MethodGet, "/api/interview/embeddings/export?"+query, nil), 7))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		var page exportPage
+		decodeBody(t, w, &page)
+		return page
+	}
+	items := func(page exportPage) map[string]SavedQuestionEmbedding {
+		byID := make(map[string]SavedQuestionEmbedding)
+		for _, item := range page.Items {
+			byID[item.QuestionID] = item
+		}
+		return byID
+	}
+
+	t.Run("float", func(t *testing.T) {
+		page := export(t, "")
+		if page.Encoding != "float" || page.Total != 3 {
+			t.Errorf("page = %s encoding, %d total; want float, 3", page.Encoding, page.Total)
+		}
+
+		got := items(page)
+		if _, ok := got["q_other"]; ok {
+			t.Error("export includes another user's question")
+		}
+		embedded := got["q_embedded"]
+		if embedded.Dimensions != len(vector) || !reflect.DeepEqual(embedded.Embedding, vector) || embedded.EmbeddingBase64 != nil {
+			t.Errorf("embedded question = %+v, want the %d stored values exactly", embedded, len(vector))
+		}
+		if pending := got["q_pending"]; pending.Dimensions != 0 || pending.Embedding != nil || pending.Error != "" {
+			t.Errorf("question without an embedding = %+v", pending)
+		}
+		if corrupt := got["q_corrupt"]; corrupt.Dimensions != 0 || corrupt.Embedding != nil || corrupt.Error == "" {
+			t.Errorf("question with an invalid embedding = %+v, want an error", corrupt)
+		}
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		embedded := items(export(t, "encoding=Base64"))["q_embedded"]
+		if embedded.Embedding != nil || !reflect.DeepEqual(embedded.EmbeddingBase64, stored) {
+			t.Fatalf("embedded question = %+v, want the stored bytes", embedded)
+		}
+		decoded, err := qamatcher.DeserializeEmbedding(embedded.EmbeddingBase64)
+		if err != nil || !reflect.DeepEqual(decoded, vector) || embedded.Dimensions != len(vector) {
+			t.Errorf("decoded embedding = %v, %v; want %v", decoded, err, vector)
+		}
+	})
+
+	t.Run("page size capped", func(t *testing.T) {
+		page := export(t, "limit=10000&offset=1")
+		if page.Limit != 500 || page.Offset != 1 || len(page.Items) != 2 {
+			t.Errorf("page = limit %d, offset %d, %d items; want 500, 1, 2", page.Limit, page.Offset, len(page.Items))
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		if w := serve(h.HandleExportEmbeddings, httptest.NewRequest(http.MethodGet, "/api/interview/embeddings/export", nil)); w.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous status = %d, want 401", w.Code)
+		}
+		w := serve(h.HandleExportEmbeddings, asUser(httptest.NewRequest(http.MethodGet, "/api/interview/embeddings/export?encoding=npy", nil), 7))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("unknown encoding status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestHandleReassignSavedQuestions(t *testing.T) {
+	jobs := &fakeAnalysisRepo{jobs: map[string]*models.AnalysisJob{"job_new": {JobID: "job_new"}}}
+	reassign := func(repo *fakeSavedQuestions, body string) *httptest.ResponseRecorder {
+		h := NewInterviewHandler(nil, jobs, repo, nil, nil, nil, nil, nil, 0)
+		r := httptest.NewRequest(http.MethodPost, "/api/interview/saved-questions/reassign", strings.NewReader(body))
+		return serve(h.HandleReassignSavedQuestions, r)
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		wantMode string
+	}{
+		{"copy by default", "", "copy"},
+		{"copy", "copy", "copy"},
+		{"move", "Move", "move"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSavedQuestions{reassignResult: &models.ReassignResult{Reassigned: 2, Skipped: 1, SkippedIDs: []string{"q_dup"}}}
+			if tt.wantMode == "move" {
+				repo.reassignResult.Removed = 3
+			}
+			body := fmt.Sprintf(`{"user_id": "u1", "from_job_id": "job_old", "to_job_id": "job_new", "mode": %q}`, tt.mode)
+			w := reassign(repo, body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			want := []reassignCall{{"u1", "job_old", "job_new", tt.wantMode == "move"}}
+			if !reflect.DeepEqual(repo.reassigns, want) {
+				t.Errorf("calls = %+v, want %+v", repo.reassigns, want)
+			}
+			var got ReassignSavedQuestionsResponse
+			decodeBody(t, w, &got)
+			if got.Mode != tt.wantMode || got.Reassigned != 2 || got.Skipped != 1 || !reflect.DeepEqual(got.SkippedIDs, []string{"q_dup"}) || got.Removed != repo.reassignResult.Removed {
+				t.Errorf("response = %+v %+v", got, got.ReassignResult)
+			}
+		})
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+			want int
+		}{
+			{"invalid body", `{`, http.StatusBadRequest},
+			{"missing target", `{"user_id": "u1", "from_job_id": "job_old"}`, http.StatusBadRequest},
+			{"same job", `{"user_id": "u1", "from_job_id": "job_new", "to_job_id": "job_new"}`, http.StatusBadRequest},
+			{"unknown mode", `{"user_id": "u1", "from_job_id": "job_old", "to_job_id": "job_new", "mode": "swap"}`, http.StatusBadRequest},
+			{"unknown target", `{"user_id": "u1", "from_job_id": "job_old", "to_job_id": "job_typo"}`, http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := &fakeSavedQuestions{}
+				if w := reassign(repo, tt.body); w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+				if len(repo.reassigns) != 0 {
+					t.Errorf("saved questions were reassigned: %+v", repo.reassigns)
+				}
+			})
+		}
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		repo := &fakeSavedQuestions{reassignErr: errors.New("connection refused")}
+		w := reassign(repo, `{"user_id": "u1", "from_job_id": "job_old", "to_job_id": "job_new", "mode": "move"}`)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", w.Code)
+		}
+	})
+}
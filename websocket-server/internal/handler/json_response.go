@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+)
+
+// prettyJSONAll makes respondJSON indent every response, see SetPrettyJSON
+var prettyJSONAll atomic.Bool
+
+// SetPrettyJSON makes respondJSON indent every response (e.g. from a PRETTY_JSON
+// setting). Otherwise only requests marked by PrettyJSON are indented.
+func SetPrettyJSON(enabled bool) {
+	prettyJSONAll.Store(enabled)
+}
+
+// prettyResponseWriter marks a response that respondJSON should indent
+type prettyResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *prettyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// PrettyJSON indents the JSON responses of requests with a true pretty query parameter
+// (pretty=true or pretty=1), for reading responses in a browser or with curl
+func PrettyJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pretty, err := strconv.ParseBool(r.URL.Query().Get("pretty")); err == nil && pretty {
+			w = &prettyResponseWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wantsPrettyJSON reports whether a response written to w should be indented
+func wantsPrettyJSON(w http.ResponseWriter) bool {
+	if prettyJSONAll.Load() {
+		return true
+	}
+	_, ok := w.(*prettyResponseWriter)
+	return ok
+}
+
+// maxNormalizeDepth bounds how deep normalizeEmpty descends, guarding against cycles
+const maxNormalizeDepth = 32
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// normalizeEmpty returns a copy of data in which nil slices and maps, at any depth, are
+// replaced by empty ones, so they serialize as [] and {} rather than null. Fields tagged
+// omitempty are still omitted. Byte slices and types with their own MarshalJSON are left
+// as they are; data itself is never modified.
+func normalizeEmpty(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	return normalizeValue(reflect.ValueOf(data), 0).Interface()
+}
+
+// normalizeValue returns v, or a normalized copy of it if it holds nil slices or maps
+func normalizeValue(v reflect.Value, depth int) reflect.Value {
+	if depth > maxNormalizeDepth || v.Type().Implements(jsonMarshalerType) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(normalizeValue(v.Elem(), depth+1))
+		return copied
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		elem := normalizeValue(v.Elem(), depth+1)
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(elem)
+		return copied
+
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v) // Also copies unexported fields, which can't be set one by one
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				copied.Field(i).Set(normalizeValue(v.Field(i), depth+1))
+			}
+		}
+		return copied
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		if v.IsNil() {
+			return reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(normalizeValue(v.Index(i), depth+1))
+		}
+		return copied
+
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.MakeMap(v.Type())
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), normalizeValue(iter.Value(), depth+1))
+		}
+		return copied
+	}
+
+	return v
+}
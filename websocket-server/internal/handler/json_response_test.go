@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	handler := PrettyJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{"name": "Ada", "skills": []string{"Go"}})
+	}))
+	const compact = `{"name":"Ada","skills":["Go"]}` + "\n"
+	const indented = "{\n  \"name\": \"Ada\",\n  \"skills\": [\n    \"Go\"\n  ]\n}\n"
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", compact},
+		{"?pretty=true", indented},
+		{"?pretty=1", indented},
+		{"?pretty=false", compact},
+		{"?pretty=yes", compact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/uploads"+tt.query, nil))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+		})
+	}
+
+	t.Run("enabled for every response", func(t *testing.T) {
+		SetPrettyJSON(true)
+		t.Cleanup(func() { SetPrettyJSON(false) })
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/uploads", nil))
+		if got := w.Body.String(); got != indented {
+			t.Errorf("body = %q, want it indented", got)
+		}
+	})
+
+	t.Run("flushing reaches the underlying writer", func(t *testing.T) {
+		var flushed bool
+		PrettyJSON(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flushed = http.NewResponseController(w).Flush() == nil
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?pretty=true", nil))
+		if !flushed {
+			t.Error("pretty response can't be flushed")
+		}
+	})
+}
+
+// stamp serializes itself, so normalizeEmpty must leave it alone
+type stamp struct{ tags []string }
+
+func (s stamp) MarshalJSON() ([]byte, error) {
+	if s.tags == nil {
+		return []byte(`"untagged"`), nil
+	}
+	return json.Marshal(s.tags)
+}
+
+func TestRespondJSONEmptyCollections(t *testing.T) {
+	type item struct {
+		Tags []string `json:"tags"`
+	}
+	type response struct {
+		Items    []item           `json:"items"`
+		Counts   map[string]int   `json:"counts"`
+		Optional []string         `json:"optional,omitempty"`
+		Nested   *item            `json:"nested"`
+		Missing  *item            `json:"missing"`
+		Any      interface{}      `json:"any"`
+		Raw      []byte           `json:"raw"`
+		Stamp    stamp            `json:"stamp"`
+		Extra    map[string][]int `json:"extra"`
+		At       time.Time        `json:"at"`
+		hidden   []string
+	}
+
+	data := response{
+		Items:  []item{{}, {Tags: []string{"go"}}},
+		Nested: &item{},
+		Any:    item{},
+		Extra:  map[string][]int{"none": nil},
+		hidden: []string{"kept"},
+	}
+
+	w := httptest.NewRecorder()
+	respondJSON(w, http.StatusOK, data)
+
+	want := `{"items":[{"tags":[]},{"tags":["go"]}],"counts":{},"nested":{"tags":[]},"missing":null,` +
+		`"any":{"tags":[]},"raw":null,"stamp":"untagged","extra":{"none":[]},"at":"0001-01-01T00:00:00Z"}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Errorf("body =\n%s\nwant\n%s", got, want)
+	}
+
+	// The response data itself isn't modified
+	if data.Items[0].Tags != nil || data.Nested.Tags != nil || data.Counts != nil || data.Extra["none"] != nil {
+		t.Errorf("respondJSON modified its data: %+v", data)
+	}
+
+	if got := normalizeEmpty(data).(response); got.hidden[0] != "kept" {
+		t.Errorf("unexported field = %v, want it copied", got.hidden)
+	}
+	if normalizeEmpty(nil) != nil {
+		t.Error("normalizeEmpty(nil) isn't nil")
+	}
+
+	// Nil top-level slices are sent as [] too
+	w = httptest.NewRecorder()
+	var none []item
+	respondJSON(w, http.StatusOK, none)
+	if got := strings.TrimSpace(w.Body.String()); got != "[]" {
+		t.Errorf("nil slice sent as %s, want []", got)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+)
+
+// QueueStatsProvider reports the load on the analysis worker pool
+type QueueStatsProvider interface {
+	QueueStats() analyzer.QueueStats
+}
+
+// MetricsHandler serves health and metrics endpoints that include analyzer load
+type MetricsHandler struct {
+	queue QueueStatsProvider
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(queue QueueStatsProvider) *MetricsHandler {
+	return &MetricsHandler{
+		queue: queue,
+	}
+}
+
+// HandleHealth handles health check requests. The server reports "degraded" while the
+// analysis queue is over its high-water mark and new jobs are being rejected.
+func (h *MetricsHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	stats := h.queue.QueueStats()
+
+	status := "healthy"
+	if !stats.AcceptingJobs {
+		status = "degraded"
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         status,
+		"timestamp":      time.Now().Unix(),
+		"analysis_queue": stats,
+	})
+}
+
+// HandleMetrics exposes analyzer worker pool metrics in the Prometheus text format
+func (h *MetricsHandler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := h.queue.QueueStats()
+	accepting := 0
+	if stats.AcceptingJobs {
+		accepting = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	for _, m := range []struct {
+		name  string
+		help  string
+		value int
+	}{
+		{"analyzer_jobs_running", "Analysis jobs holding a worker slot", stats.Running},
+		{"analyzer_jobs_queued", "Analysis jobs waiting for a worker slot", stats.Queued},
+		{"analyzer_workers_capacity", "Number of analysis worker slots", stats.Capacity},
+		{"analyzer_queue_high_water_mark", "Queued jobs allowed before new jobs are rejected (0 = unlimited)", stats.MaxQueued},
+		{"analyzer_accepting_jobs", "Whether new analysis jobs are accepted (1) or rejected (0)", accepting},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", m.name, m.help, m.name, m.name, m.value)
+	}
+}
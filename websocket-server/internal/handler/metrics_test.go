@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+)
+
+// stubQueue reports fixed queue stats
+type stubQueue analyzer.QueueStats
+
+func (q stubQueue) QueueStats() analyzer.QueueStats { return analyzer.QueueStats(q) }
+
+func TestHandleHealthQueueStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		stats      stubQueue
+		wantStatus string
+	}{
+		{"accepting jobs", stubQueue{Running: 2, Queued: 1, Capacity: 5, MaxQueued: 10, AcceptingJobs: true}, "healthy"},
+		{"over the high-water mark", stubQueue{Running: 5, Queued: 10, Capacity: 5, MaxQueued: 10}, "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewMetricsHandler(tt.stats)
+
+			w := serve(h.HandleHealth, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+			}
+
+			var body struct {
+				Status string              `json:"status"`
+				Queue  analyzer.QueueStats `json:"analysis_queue"`
+			}
+			decodeBody(t, w, &body)
+			if body.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", body.Status, tt.wantStatus)
+			}
+			if body.Queue != analyzer.QueueStats(tt.stats) {
+				t.Errorf("analysis_queue = %+v, want %+v", body.Queue, tt.stats)
+			}
+		})
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	h := NewMetricsHandler(stubQueue{Running: 5, Queued: 3, Capacity: 5, MaxQueued: 3})
+
+	w := serve(h.HandleMetrics, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+
+	for _, want := range []string{
+		"analyzer_jobs_running 5\n",
+		"analyzer_jobs_queued 3\n",
+		"analyzer_workers_capacity 5\n",
+		"analyzer_queue_high_water_mark 3\n",
+		"analyzer_accepting_jobs 0\n",
+		"# TYPE analyzer_jobs_queued gauge\n",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, w.Body.String())
+		}
+	}
+
+	w = serve(h.HandleMetrics, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", w.Code)
+	}
+}
+
+func TestHandleAnalyzeResumeQueueFull(t *testing.T) {
+	fake := &fakeAnalyzer{startErr: fmt.Errorf("starting job: %w", analyzer.ErrQueueFull)}
+	fake.upload(1, nil)
+	h := NewAnalysisHandler(fake, stubExporter{}, nil, nil, nil)
+
+	w := serve(h.HandleAnalyzeResume, httptest.NewRequest(http.MethodPost, "/api/analysis/start?id=1", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got == "" {
+		t.Errorf("no Retry-After header")
+	}
+
+	var body map[string]string
+	decodeBody(t, w, &body)
+	if body["error"] != "Analysis queue is full" {
+		t.Errorf("error = %q", body["error"])
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// Authenticator identifies the user making a request from its session token
+type Authenticator interface {
+	UserIDFromRequest(r *http.Request) (int, bool)
+}
+
+// callerID returns the authenticated caller's user ID. A nil authenticator treats
+// every request as anonymous.
+func callerID(auth Authenticator, r *http.Request) (int, bool) {
+	if auth == nil {
+		return 0, false
+	}
+	return auth.UserIDFromRequest(r)
+}
+
+// TenantResolver is implemented by authenticators that can also tell which tenant
+// (organization) the caller belongs to
+type TenantResolver interface {
+	TenantIDFromRequest(ctx context.Context, r *http.Request) (string, error)
+}
+
+// callerTenant returns the tenant whose data the caller may search. Anonymous callers, and
+// callers of authenticators that don't resolve tenants, belong to the default tenant "".
+func callerTenant(ctx context.Context, auth Authenticator, r *http.Request) (string, error) {
+	resolver, ok := auth.(TenantResolver)
+	if !ok {
+		return "", nil
+	}
+	return resolver.TenantIDFromRequest(ctx, r)
+}
+
+// AnonymousAccess is implemented by authenticators that can run in anonymous mode, where
+// uploads and jobs created without a session stay open to every caller
+type AnonymousAccess interface {
+	AllowsAnonymous() bool
+}
+
+// anonymousMode reports whether resources without an owner are open to every caller. A nil
+// authenticator treats every caller as anonymous, so it is always in anonymous mode; other
+// authenticators only when they allow it.
+func anonymousMode(auth Authenticator) bool {
+	if auth == nil {
+		return true
+	}
+	access, ok := auth.(AnonymousAccess)
+	return ok && access.AllowsAnonymous()
+}
+
+// authorizeUpload checks that the caller may access upload, writing an error response
+// when it may not. Anonymous uploads (no user_id) are accessible to everyone in anonymous
+// mode; other uploads only to their owner.
+func authorizeUpload(w http.ResponseWriter, r *http.Request, auth Authenticator, upload *models.Upload) bool {
+	return authorizeOwner(w, r, auth, upload.UserID, "You do not have access to this upload")
+}
+
+// authorizeJob checks that the caller may access an analysis job, with the same rules
+// as uploads: jobs created anonymously are accessible to everyone in anonymous mode, others
+// only to their owner
+func authorizeJob(w http.ResponseWriter, r *http.Request, auth Authenticator, status *models.AnalysisStatus) bool {
+	return authorizeOwner(w, r, auth, status.UserID, "You do not have access to this job")
+}
+
+// authorizeOwner requires the caller to be ownerID, responding 401 without a session and
+// 403 with deniedMessage. Resources without an owner are only open in anonymous mode;
+// otherwise no caller owns them.
+func authorizeOwner(w http.ResponseWriter, r *http.Request, auth Authenticator, ownerID *int, deniedMessage string) bool {
+	if ownerID == nil && anonymousMode(auth) {
+		return true
+	}
+
+	userID, ok := callerID(auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return false
+	}
+	if ownerID == nil || userID != *ownerID {
+		respondJSON(w, http.StatusForbidden, map[string]string{"error": deniedMessage})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Pagination holds the limit and offset of a list request
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// Page is the response envelope shared by list endpoints
+type Page struct {
+	Items   interface{} `json:"items"`
+	Total   int         `json:"total"`    // Number of items across all pages
+	Limit   int         `json:"limit"`    // Limit applied to this page
+	Offset  int         `json:"offset"`   // Offset of the first item in this page
+	HasMore bool        `json:"has_more"` // Whether items exist after this page
+}
+
+// ParsePagination reads the limit and offset query parameters.
+// A missing or invalid limit uses defaultLimit, limits above maxLimit are clamped
+// to maxLimit, and a missing, invalid or negative offset is 0.
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) Pagination {
+	p := Pagination{Limit: defaultLimit}
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		p.Limit = l
+	}
+	if p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o > 0 {
+		p.Offset = o
+	}
+
+	return p
+}
+
+// NewPage wraps a page of items in the list envelope. count is the number of items
+// in the page and total the number across all pages.
+func NewPage(items interface{}, count, total int, p Pagination) Page {
+	return Page{
+		Items:   items,
+		Total:   total,
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		HasMore: p.Offset+count < total,
+	}
+}
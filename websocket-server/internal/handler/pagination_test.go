@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Pagination
+	}{
+		{"", Pagination{Limit: 20}},
+		{"limit=5&offset=10", Pagination{Limit: 5, Offset: 10}},
+		{"limit=100", Pagination{Limit: 100}},
+		{"limit=101", Pagination{Limit: 100}},
+		{"limit=0", Pagination{Limit: 20}},
+		{"limit=-3", Pagination{Limit: 20}},
+		{"limit=many", Pagination{Limit: 20}},
+		{"offset=-5", Pagination{Limit: 20}},
+		{"offset=first", Pagination{Limit: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/list?"+tt.query, nil)
+			if got := ParsePagination(r, 20, 100); got != tt.want {
+				t.Errorf("ParsePagination(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// A default above the maximum is clamped too
+	r := httptest.NewRequest(http.MethodGet, "/api/list", nil)
+	if got := ParsePagination(r, 200, 100); got.Limit != 100 {
+		t.Errorf("limit = %d, want the default clamped to 100", got.Limit)
+	}
+}
+
+func TestNewPageHasMore(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		total       int
+		page        Pagination
+		wantHasMore bool
+	}{
+		{"first of several pages", 10, 25, Pagination{Limit: 10}, true},
+		{"middle page", 10, 25, Pagination{Limit: 10, Offset: 10}, true},
+		{"last page", 5, 25, Pagination{Limit: 10, Offset: 20}, false},
+		{"exactly one page", 10, 10, Pagination{Limit: 10}, false},
+		{"past the end", 0, 10, Pagination{Limit: 10, Offset: 30}, false},
+		{"empty", 0, 0, Pagination{Limit: 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage([]int{}, tt.count, tt.total, tt.page)
+			if page.HasMore != tt.wantHasMore {
+				t.Errorf("has_more = %t, want %t", page.HasMore, tt.wantHasMore)
+			}
+			if page.Total != tt.total || page.Limit != tt.page.Limit || page.Offset != tt.page.Offset {
+				t.Errorf("page = %+v, want total %d, limit %d, offset %d", page, tt.total, tt.page.Limit, tt.page.Offset)
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsPagination(t *testing.T) {
+	repo := &fakeUploadRepo{}
+	for i := 0; i < 5; i++ {
+		repo.uploads = append(repo.uploads, &models.Upload{ID: i + 1, UserID: intPtr(7)})
+	}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		query       string
+		wantIDs     []int
+		wantLimit   int
+		wantHasMore bool
+	}{
+		{"", []int{1, 2, 3, 4, 5}, 10, false},
+		{"limit=2", []int{1, 2}, 2, true},
+		{"limit=2&offset=2", []int{3, 4}, 2, true},
+		{"limit=2&offset=4", []int{5}, 2, false},
+		{"limit=1000", []int{1, 2, 3, 4, 5}, 100, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil), 7))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var page struct {
+				Items   []models.Upload `json:"items"`
+				Total   int             `json:"total"`
+				Limit   int             `json:"limit"`
+				HasMore bool            `json:"has_more"`
+			}
+			decodeBody(t, w, &page)
+
+			ids := []int{}
+			for _, upload := range page.Items {
+				ids = append(ids, upload.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("uploads = %v, want %v", ids, tt.wantIDs)
+			}
+			if page.Total != 5 || page.Limit != tt.wantLimit || page.HasMore != tt.wantHasMore {
+				t.Errorf("envelope = total %d, limit %d, has_more %t, want 5, %d, %t",
+					page.Total, page.Limit, page.HasMore, tt.wantLimit, tt.wantHasMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/qamatcher"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// Limits of a Q&A regression run
+const (
+	maxQARegressionQueries   = 100 // Test queries accepted per request
+	maxQARegressionQuestions = 500 // Saved questions loaded when no job is given
+)
+
+// QARegressionHandler runs test queries against a user's saved questions, so changes to
+// embeddings, metrics or thresholds can be checked for regressions in chat matching
+type QARegressionHandler struct {
+	savedQuestionRepo repository.SavedQuestionRepository
+	embedder          analyzer.EmbeddingGenerator
+	adminToken        string // Required in the X-Admin-Token header; empty disables the endpoint
+}
+
+// NewQARegressionHandler creates a new Q&A regression handler instance
+func NewQARegressionHandler(savedQuestionRepo repository.SavedQuestionRepository, embedder analyzer.EmbeddingGenerator, adminToken string) *QARegressionHandler {
+	return &QARegressionHandler{
+		savedQuestionRepo: savedQuestionRepo,
+		embedder:          embedder,
+		adminToken:        adminToken,
+	}
+}
+
+// QARegressionRequest is a set of test queries to match against a user's saved questions
+type QARegressionRequest struct {
+	UserID    string   `json:"user_id"`
+	JobID     string   `json:"job_id,omitempty"`    // Only match the questions saved for this job
+	Queries   []string `json:"queries"`             // Test queries, at most maxQARegressionQueries
+	Threshold *float64 `json:"threshold,omitempty"` // On the metric's scale; default depends on the metric
+	Metric    string   `json:"metric,omitempty"`    // cosine (default), dot or euclidean
+}
+
+// QARegressionResult is the outcome of one test query
+type QARegressionResult struct {
+	Query           string  `json:"query"`
+	MatchedQuestion string  `json:"matched_question,omitempty"` // Closest saved question, even below the threshold
+	QuestionID      string  `json:"question_id,omitempty"`
+	Similarity      float64 `json:"similarity"`
+	Passed          bool    `json:"passed"`          // Whether the similarity cleared the threshold
+	Error           string  `json:"error,omitempty"` // Set when the query couldn't be matched
+}
+
+// QARegressionReport is the outcome of a Q&A regression run
+type QARegressionReport struct {
+	UserID        string               `json:"user_id"`
+	JobID         string               `json:"job_id,omitempty"`
+	QuestionCount int                  `json:"question_count"` // Saved questions loaded into the matcher
+	Threshold     float64              `json:"threshold"`
+	Metric        string               `json:"metric"`
+	Passed        int                  `json:"passed"` // Queries that cleared the threshold
+	Results       []QARegressionResult `json:"results"`
+}
+
+// HandleRunQARegression handles POST /api/admin/chat/qa-test
+// Loads the user's saved questions into a fresh matcher and reports, for each test
+// query, the closest question, its similarity and whether it cleared the threshold
+func (h *QARegressionHandler) HandleRunQARegression(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	var req QARegressionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required field: user_id"})
+		return
+	}
+	if len(req.Queries) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Missing required field: queries"})
+		return
+	}
+	if len(req.Queries) > maxQARegressionQueries {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Too many queries (max %d)", maxQARegressionQueries),
+		})
+		return
+	}
+	for _, query := range req.Queries {
+		if strings.TrimSpace(query) == "" {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Queries must not be empty"})
+			return
+		}
+	}
+
+	metric, err := qamatcher.ParseMetric(req.Metric)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid metric", "message": err.Error()})
+		return
+	}
+
+	threshold := metric.DefaultThreshold()
+	if req.Threshold != nil {
+		if err := metric.ValidateThreshold(*req.Threshold); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid threshold", "message": err.Error()})
+			return
+		}
+		threshold = *req.Threshold
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	var questions []*models.SavedInterviewQuestion
+	if req.JobID != "" {
+		questions, err = h.savedQuestionRepo.GetSavedQuestionsByJob(ctx, req.UserID, req.JobID)
+	} else {
+		questions, err = h.savedQuestionRepo.GetSavedQuestions(ctx, req.UserID, maxQARegressionQuestions, 0)
+	}
+	if err != nil {
+		log.Printf("Error loading saved questions for user %s, job %s: %v", req.UserID, req.JobID, err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to load Q&A pairs"})
+		return
+	}
+
+	if len(questions) == 0 {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "No saved questions found"})
+		return
+	}
+
+	matcher := qamatcher.NewEmbeddingMatcher(h.embedder, threshold, metric)
+	if err := matcher.LoadQuestions(questions); err != nil {
+		log.Printf("Error loading questions into matcher: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to initialize Q&A matcher"})
+		return
+	}
+
+	report := QARegressionReport{
+		UserID:        req.UserID,
+		JobID:         req.JobID,
+		QuestionCount: matcher.Count(),
+		Threshold:     threshold,
+		Metric:        string(metric),
+		Results:       make([]QARegressionResult, 0, len(req.Queries)),
+	}
+
+	for _, query := range req.Queries {
+		result := QARegressionResult{Query: query}
+
+		match, err := matcher.FindMatch(ctx, query)
+		if err != nil {
+			log.Printf("Error matching Q&A regression query for user %s: %v", req.UserID, err)
+			result.Error = err.Error()
+		} else if match.TooShort {
+			result.Error = "Query is too short to be matched"
+		} else {
+			result.MatchedQuestion = match.Question
+			result.QuestionID = match.QuestionID
+			result.Similarity = match.Similarity
+			result.Passed = match.Found
+		}
+
+		if result.Passed {
+			report.Passed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	log.Printf("Q&A regression for user %s: %d of %d queries matched (threshold %.2f, metric %s)",
+		req.UserID, report.Passed, len(req.Queries), threshold, metric)
+
+	respondJSON(w, http.StatusOK, report)
+}
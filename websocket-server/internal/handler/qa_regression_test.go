@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// vectorEmbedder embeds the texts it holds as their vectors and fails for any other text
+type vectorEmbedder struct {
+	analyzer.EmbeddingGenerator
+
+	vectors map[string][]float32
+}
+
+func (e *vectorEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	vector, ok := e.vectors[text]
+	if !ok {
+		return nil, fmt.Errorf("no embedding for %q", text)
+	}
+	return vector, nil
+}
+
+func TestHandleRunQARegression(t *testing.T) {
+	const (
+		intro    = "Tell me about yourself"
+		conflict = "How do you handle conflict?"
+	)
+	embedder := &vectorEmbedder{vectors: map[string][]float32{
+		intro:                               {1, 0},
+		conflict:                            {0, 1},
+		"Could you introduce yourself?":     {1, 0},
+		"How do you resolve disagreements?": {0.6, 0.8}, // Cosine 0.8 to conflict
+	}}
+	repo := &fakeSavedQuestions{
+		questions: []*models.SavedInterviewQuestion{
+			{UserID: "u1", QuestionID: "q_intro", Question: intro, Answer: "I build backends."},
+			{UserID: "u1", QuestionID: "q_conflict", Question: conflict, Answer: "I listen first."},
+			{UserID: "u2", QuestionID: "q_other", Question: "Why Go?", Answer: "Simplicity."},
+		},
+		byJob: []*models.SavedInterviewQuestion{
+			{UserID: "u1", JobID: "job_1", QuestionID: "q_intro", Question: intro, Answer: "I build backends."},
+		},
+	}
+	h := NewQARegressionHandler(repo, embedder, "secret")
+
+	run := func(t *testing.T, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/api/admin/chat/qa-test", strings.NewReader(body))
+		r.Header.Set(AdminTokenHeader, "secret")
+		return serve(h.HandleRunQARegression, r)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		w := run(t, `{"user_id": "u1", "queries": [
+			"Could you introduce yourself?",
+			"How do you resolve disagreements?",
+			"Hi",
+			"Something never embedded"
+		], "threshold": 0.9}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		var report QARegressionReport
+		decodeBody(t, w, &report)
+
+		want := QARegressionReport{
+			UserID:        "u1",
+			QuestionCount: 2,
+			Threshold:     0.9,
+			Metric:        "cosine",
+			Passed:        1,
+			Results: []QARegressionResult{
+				{Query: "Could you introduce yourself?", MatchedQuestion: intro, QuestionID: "q_intro", Similarity: 1, Passed: true},
+				{Query: "How do you resolve disagreements?", MatchedQuestion: conflict, QuestionID: "q_conflict", Similarity: 0.8},
+				{Query: "Hi", Error: "Query is too short to be matched"},
+				{Query: "Something never embedded"},
+			},
+		}
+
+		// The similarity of the near miss depends on float32 rounding
+		if got := report.Results[1].Similarity; got < 0.79 || got > 0.81 {
+			t.Errorf("near miss similarity = %v, want 0.8", got)
+		}
+		report.Results[1].Similarity = 0.8
+		// The error of the failed query comes from the embedder
+		if !strings.Contains(report.Results[3].Error, "no embedding") {
+			t.Errorf("failed query error = %q, want the embedder's", report.Results[3].Error)
+		}
+		report.Results[3].Error = ""
+
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("report = %+v\nwant %+v", report, want)
+		}
+	})
+
+	t.Run("default threshold and job questions", func(t *testing.T) {
+		w := run(t, `{"user_id": "u1", "job_id": "job_1", "queries": ["How do you resolve disagreements?"]}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		var report QARegressionReport
+		decodeBody(t, w, &report)
+		if report.JobID != "job_1" || report.QuestionCount != 1 || report.Threshold != 0.75 || report.Metric != "cosine" {
+			t.Errorf("report = %+v, want job_1's question at the cosine default threshold", report)
+		}
+		// Only the job's questions are matched, so the closest one is the intro
+		if len(report.Results) != 1 || report.Results[0].QuestionID != "q_intro" || report.Results[0].Passed {
+			t.Errorf("results = %+v, want a near miss on q_intro", report.Results)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			token string
+			body  string
+			want  int
+		}{
+			{"no admin token", "", `{"user_id": "u1", "queries": ["Tell me more"]}`, http.StatusUnauthorized},
+			{"no user", "secret", `{"queries": ["Tell me more"]}`, http.StatusBadRequest},
+			{"no queries", "secret", `{"user_id": "u1", "queries": []}`, http.StatusBadRequest},
+			{"blank query", "secret", `{"user_id": "u1", "queries": ["  "]}`, http.StatusBadRequest},
+			{"too many queries", "secret", `{"user_id": "u1", "queries": [` + strings.Repeat(`"q",`, maxQARegressionQueries) + `"q"]}`, http.StatusBadRequest},
+			{"unknown metric", "secret", `{"user_id": "u1", "queries": ["Tell me more"], "metric": "jaccard"}`, http.StatusBadRequest},
+			{"no saved questions", "secret", `{"user_id": "nobody", "queries": ["Tell me more"]}`, http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodPost, "/api/admin/chat/qa-test", strings.NewReader(tt.body))
+				if tt.token != "" {
+					r.Header.Set(AdminTokenHeader, tt.token)
+				}
+				if w := serve(h.HandleRunQARegression, r); w.Code != tt.want {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+// Default signup rate limit per client address
+const (
+	DefaultSignupsPerIP = 5
+	DefaultSignupWindow = time.Hour
+)
+
+// Headers read by the signup gate
+const (
+	SignupSecretHeader = "X-Signup-Secret"
+	CaptchaTokenHeader = "X-Captcha-Token"
+)
+
+// CaptchaVerifier checks a CAPTCHA response token with its provider
+type CaptchaVerifier interface {
+	VerifyCaptcha(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// SignupProtection limits automated signups
+type SignupProtection struct {
+	MaxPerIP int           // Signups allowed per client address per Window (0 = DefaultSignupsPerIP, negative = unlimited)
+	Window   time.Duration // Sliding window of MaxPerIP (0 = DefaultSignupWindow)
+
+	// Optional gates, checked before the rate limit. Secret requires the value in the
+	// X-Signup-Secret header, e.g. for signups only through a trusted frontend; Captcha
+	// requires a token in the X-Captcha-Token header that it accepts.
+	Secret  string
+	Captcha CaptchaVerifier
+}
+
+// signupGuard enforces a SignupProtection
+type signupGuard struct {
+	protection SignupProtection
+	limiter    *addressLimiter // nil when unlimited
+}
+
+// newSignupGuard applies the defaults to protection. A nil protection uses the defaults
+// without gates.
+func newSignupGuard(protection *SignupProtection, clk clock.Clock) *signupGuard {
+	guard := &signupGuard{}
+	if protection != nil {
+		guard.protection = *protection
+	}
+
+	p := &guard.protection
+	if p.MaxPerIP == 0 {
+		p.MaxPerIP = DefaultSignupsPerIP
+	}
+	if p.Window <= 0 {
+		p.Window = DefaultSignupWindow
+	}
+	if p.MaxPerIP > 0 {
+		guard.limiter = newAddressLimiter(p.MaxPerIP, p.Window, clk)
+	}
+	return guard
+}
+
+// check reports whether a signup request may proceed, writing the error response when
+// it may not. Every request passing the gates counts against its address's limit,
+// whether or not the signup then succeeds.
+func (g *signupGuard) check(w http.ResponseWriter, r *http.Request) bool {
+	if g.protection.Secret != "" {
+		secret := r.Header.Get(SignupSecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(g.protection.Secret)) != 1 {
+			sendAuthError(w, "Signup is not allowed from this client", http.StatusForbidden)
+			return false
+		}
+	}
+
+	address := remoteHost(r)
+
+	if g.protection.Captcha != nil {
+		token := r.Header.Get(CaptchaTokenHeader)
+		if token == "" {
+			sendAuthError(w, "CAPTCHA is required", http.StatusForbidden)
+			return false
+		}
+		ok, err := g.protection.Captcha.VerifyCaptcha(r.Context(), token, address)
+		if err != nil {
+			sendAuthError(w, "Failed to verify CAPTCHA", http.StatusServiceUnavailable)
+			return false
+		}
+		if !ok {
+			sendAuthError(w, "CAPTCHA verification failed", http.StatusForbidden)
+			return false
+		}
+	}
+
+	if g.limiter != nil {
+		if retryAfter, ok := g.limiter.allow(address); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			sendAuthError(w, "Too many signups from this address, try again later", http.StatusTooManyRequests)
+			return false
+		}
+	}
+
+	return true
+}
+
+// remoteHost returns the host part of the request's remote address. Forwarding headers
+// aren't trusted, so behind a proxy every client shares the proxy's address.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// addressLimiter allows at most max events per address per sliding window
+type addressLimiter struct {
+	mu        sync.Mutex
+	max       int
+	window    time.Duration
+	clock     clock.Clock
+	events    map[string][]time.Time
+	lastPrune time.Time
+}
+
+// newAddressLimiter creates a per-address sliding-window rate limiter timed by clk
+func newAddressLimiter(max int, window time.Duration, clk clock.Clock) *addressLimiter {
+	return &addressLimiter{
+		max:    max,
+		window: window,
+		clock:  clock.OrReal(clk),
+		events: make(map[string][]time.Time),
+	}
+}
+
+// allow records an event for address and reports whether it is within the limit. When
+// it isn't, retryAfter is how long until the oldest event leaves the window.
+func (l *addressLimiter) allow(address string) (retryAfter time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	cutoff := now.Add(-l.window)
+
+	// Forget addresses without recent events once per window, so the map doesn't grow
+	// with every address ever seen
+	if now.Sub(l.lastPrune) >= l.window {
+		for key, events := range l.events {
+			if !events[len(events)-1].After(cutoff) {
+				delete(l.events, key)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	kept := l.events[address][:0]
+	for _, t := range l.events[address] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= l.max {
+		l.events[address] = kept
+		return kept[0].Sub(cutoff), false
+	}
+	l.events[address] = append(kept, now)
+	return 0, true
+}
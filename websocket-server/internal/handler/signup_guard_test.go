@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+// signupFrom posts a signup of email from the client address remoteAddr, with the
+// headers set, and returns the response
+func signupFrom(h *AuthHandler, remoteAddr, email string, headers map[string]string) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"name":"Grace","email":%q,"password":"secret"}`, email)
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/signup", strings.NewReader(body))
+	r.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return serve(h.Signup, r)
+}
+
+func TestSignupRateLimit(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	h := NewAuthHandler(NewLocalAuthProvider(&fakeUserRepo{}), fake, &SignupProtection{MaxPerIP: 3, Window: time.Hour}, false)
+
+	emails := 0
+	signup := func(remoteAddr string) *httptest.ResponseRecorder {
+		emails++
+		return signupFrom(h, remoteAddr, fmt.Sprintf("user%d@example.com", emails), nil)
+	}
+
+	// The port changes with every connection, so it isn't part of the address
+	for i := range 3 {
+		if w := signup(fmt.Sprintf("203.0.113.7:%d", 40000+i)); w.Code != http.StatusCreated {
+			t.Fatalf("signup %d: status %d, want 201: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+
+	fake.Advance(10 * time.Minute)
+	w := signup("203.0.113.7:40100")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("fourth signup: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3000" {
+		t.Errorf("Retry-After = %q, want 3000 seconds until the first signup leaves the window", got)
+	}
+
+	if w := signup("198.51.100.2:40000"); w.Code != http.StatusCreated {
+		t.Errorf("signup from another address: status %d, want 201", w.Code)
+	}
+
+	// Refused signups don't extend the wait
+	fake.Advance(50 * time.Minute)
+	if w := signup("203.0.113.7:40200"); w.Code != http.StatusCreated {
+		t.Errorf("signup after the window: status %d, want 201", w.Code)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		h := NewAuthHandler(NewLocalAuthProvider(&fakeUserRepo{}), fake, nil, false)
+		for i := range DefaultSignupsPerIP {
+			if w := signupFrom(h, "203.0.113.7:1", fmt.Sprintf("d%d@example.com", i), nil); w.Code != http.StatusCreated {
+				t.Fatalf("signup %d: status %d, want 201", i+1, w.Code)
+			}
+		}
+		if w := signupFrom(h, "203.0.113.7:1", "over@example.com", nil); w.Code != http.StatusTooManyRequests {
+			t.Errorf("signup over the default limit: status %d, want 429", w.Code)
+		}
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		h := NewAuthHandler(NewLocalAuthProvider(&fakeUserRepo{}), fake, &SignupProtection{MaxPerIP: -1}, false)
+		for i := range 20 {
+			if w := signupFrom(h, "203.0.113.7:1", fmt.Sprintf("u%d@example.com", i), nil); w.Code != http.StatusCreated {
+				t.Fatalf("signup %d: status %d, want 201", i+1, w.Code)
+			}
+		}
+	})
+}
+
+// stubCaptcha accepts the token "valid"; verification fails with err while it is set
+type stubCaptcha struct {
+	err error
+}
+
+func (s *stubCaptcha) VerifyCaptcha(ctx context.Context, token, remoteIP string) (bool, error) {
+	return token == "valid", s.err
+}
+
+func TestSignupGates(t *testing.T) {
+	const secret = "s3cret"
+	passing := map[string]string{SignupSecretHeader: secret, CaptchaTokenHeader: "valid"}
+
+	tests := []struct {
+		name       string
+		secret     bool  // Require the secret
+		captcha    bool  // Require a CAPTCHA
+		captchaErr error // CAPTCHA verification failure
+		headers    map[string]string
+		want       int
+	}{
+		{"no secret", true, false, nil, nil, http.StatusForbidden},
+		{"wrong secret", true, false, nil, map[string]string{SignupSecretHeader: "guess"}, http.StatusForbidden},
+		{"secret", true, false, nil, map[string]string{SignupSecretHeader: secret}, http.StatusCreated},
+		{"no CAPTCHA", false, true, nil, nil, http.StatusForbidden},
+		{"rejected CAPTCHA", false, true, nil, map[string]string{CaptchaTokenHeader: "bot"}, http.StatusForbidden},
+		{"CAPTCHA provider down", false, true, errors.New("timeout"), map[string]string{CaptchaTokenHeader: "valid"}, http.StatusServiceUnavailable},
+		{"CAPTCHA", false, true, nil, map[string]string{CaptchaTokenHeader: "valid"}, http.StatusCreated},
+		{"secret checked first", true, true, nil, map[string]string{CaptchaTokenHeader: "valid"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captcha := &stubCaptcha{err: tt.captchaErr}
+			protection := &SignupProtection{MaxPerIP: 1}
+			if tt.secret {
+				protection.Secret = secret
+			}
+			if tt.captcha {
+				protection.Captcha = captcha
+			}
+			h := NewAuthHandler(NewLocalAuthProvider(&fakeUserRepo{}), nil, protection, false)
+
+			if w := signupFrom(h, "203.0.113.7:1", "grace@example.com", tt.headers); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusCreated {
+				return
+			}
+
+			// Requests stopped by a gate don't use up the address's one signup
+			captcha.err = nil
+			if w := signupFrom(h, "203.0.113.7:1", "grace@example.com", passing); w.Code != http.StatusCreated {
+				t.Errorf("signup passing the gates: status %d, want 201: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
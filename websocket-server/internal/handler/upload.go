@@ -3,14 +3,18 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/your-org/websocket-server/internal/analyzer"
 	"github.com/your-org/websocket-server/internal/repository"
 	"github.com/your-org/websocket-server/pkg/models"
 )
@@ -27,11 +31,25 @@ const (
 type UploadHandler struct {
 	repo         repository.UploadRepository
 	analysisRepo repository.AnalysisRepository
+	auth         Authenticator // Resolves the caller for ownership checks
+	jobs         JobCanceller  // Stops unfinished analysis jobs of uploads deleted with force
+
+	// links signs shared download links; nil disables them
+	links *DownloadLinkSigner
+}
+
+// JobCanceller stops an analysis job that is queued or processing
+type JobCanceller interface {
+	CancelJob(ctx context.Context, jobID string) error
 }
 
-// NewUploadHandler creates a new upload handler instance
-func NewUploadHandler(repo repository.UploadRepository, analysisRepo repository.AnalysisRepository) *UploadHandler {
-	return &UploadHandler{repo: repo, analysisRepo: analysisRepo}
+// NewUploadHandler creates a new upload handler instance.
+// With a nil authenticator every caller is anonymous and can only access anonymous uploads.
+// With a nil job canceller, forced deletions mark unfinished jobs failed without stopping their workers.
+// A job canceller that is also an analyzer.UploadForgetter is told about deleted uploads.
+// With a nil link signer, shared download links are disabled.
+func NewUploadHandler(repo repository.UploadRepository, analysisRepo repository.AnalysisRepository, auth Authenticator, jobs JobCanceller, links *DownloadLinkSigner) *UploadHandler {
+	return &UploadHandler{repo: repo, analysisRepo: analysisRepo, auth: auth, jobs: jobs, links: links}
 }
 
 // HandleUpload processes multipart file upload requests
@@ -49,15 +67,35 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseMultipartForm(MaxUploadSize)
 	if err != nil {
 		log.Printf("Error parsing multipart form: %v", err)
-		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "File too large or invalid form data"})
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+				"error":   "File too large",
+				"message": fmt.Sprintf("file exceeds %d bytes", maxBytesErr.Limit),
+			})
+			return
+		}
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid form data"})
 		return
 	}
 
-	// Get user_id (optional but recommended for authenticated users)
+	// The owner is the signed-in caller; uploads without a session are anonymous. A
+	// user_id form field is only accepted when it names the caller.
 	var userID *int
+	if uid, ok := callerID(h.auth, r); ok {
+		userID = &uid
+	} else if !anonymousMode(h.auth) {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
 	if userIDStr := r.FormValue("user_id"); userIDStr != "" {
-		if uid, err := strconv.Atoi(userIDStr); err == nil {
-			userID = &uid
+		formID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+			return
+		}
+		if !authorizeOwner(w, r, h.auth, &formID, "You cannot upload for another user") {
+			return
 		}
 	}
 
@@ -75,6 +113,16 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		linkedinURL = &url
 	}
 
+	// Opt in to an email when analysis of the upload completes or fails (optional)
+	notifyEmail := false
+	if notifyStr := r.FormValue("notify_email"); notifyStr != "" {
+		notifyEmail, err = strconv.ParseBool(notifyStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid notify_email value"})
+			return
+		}
+	}
+
 	// Get file from form
 	file, fileHeader, err := r.FormFile("resume")
 	if err != nil {
@@ -108,20 +156,30 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	// The upload belongs to the caller's tenant, isolating its embeddings
+	tenantID, err := callerTenant(ctx, h.auth, r)
+	if err != nil {
+		log.Printf("Error resolving tenant for upload: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to save upload"})
+		return
+	}
+
 	// Create upload record
 	upload := &models.Upload{
 		UserID:      userID,
+		TenantID:    tenantID,
 		LinkedinURL: linkedinURL,
 		FileName:    fileHeader.Filename,
 		FileContent: fileContent,
 		FileSize:    int(fileHeader.Size),
 		MimeType:    mimeType,
+		NotifyEmail: notifyEmail,
 	}
 
 	// Store in database
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
-
 	err = h.repo.CreateUpload(ctx, upload)
 	if err != nil {
 		log.Printf("Error creating upload: %v", err)
@@ -136,6 +194,7 @@ func (h *UploadHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		FileName:    upload.FileName,
 		FileSize:    upload.FileSize,
 		MimeType:    upload.MimeType,
+		NotifyEmail: upload.NotifyEmail,
 		CreatedAt:   upload.CreatedAt,
 		Message:     "Resume uploaded successfully",
 	}
@@ -173,70 +232,210 @@ func (h *UploadHandler) HandleGetUpload(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
 	respondJSON(w, http.StatusOK, upload)
 }
 
-// HandleListUploads retrieves all uploads with pagination
-// Optionally filters by user_id if provided
+// validUploadStatuses lists the values accepted by the status filter of HandleListUploads.
+// These mirror the analysis_jobs status constraint plus a value for uploads never analyzed.
+var validUploadStatuses = map[string]bool{
+	"queued":                       true,
+	"extracting_text":              true,
+	"chunking":                     true,
+	"generating_embeddings":        true,
+	"analyzing":                    true,
+	"completed":                    true,
+	"failed":                       true,
+	"needs_review":                 true,
+	"dead_lettered":                true,
+	models.UploadStatusNotAnalyzed: true,
+}
+
+// uploadStatusValues returns the accepted status filter values in sorted order
+func uploadStatusValues() []string {
+	values := make([]string, 0, len(validUploadStatuses))
+	for v := range validUploadStatuses {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// uploadSortDefaults maps the accepted sort values of HandleListUploads to whether they
+// sort ascending when no order is given: names A to Z, dates and sizes largest first
+var uploadSortDefaults = map[string]bool{
+	repository.UploadSortCreatedAt: false,
+	repository.UploadSortFileSize:  false,
+	repository.UploadSortName:      true,
+}
+
+// dateLayout is the layout of date-only query parameters
+const dateLayout = "2006-01-02"
+
+// parseListDate parses a from/to date filter, either an RFC 3339 timestamp or a date
+// (UTC). A date given as the end of a range includes the whole day.
+func parseListDate(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// parseDateParam parses an optional date query parameter with parseListDate, writing an
+// error response when it is invalid
+func parseDateParam(w http.ResponseWriter, r *http.Request, param string, end bool) (*time.Time, bool) {
+	value := strings.TrimSpace(r.URL.Query().Get(param))
+	if value == "" {
+		return nil, true
+	}
+	t, err := parseListDate(value, end)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid " + param,
+			"message": param + " must be a date (YYYY-MM-DD) or an RFC 3339 timestamp",
+		})
+		return nil, false
+	}
+	return &t, true
+}
+
+// HandleListUploads retrieves the caller's uploads within the caller's tenant, with pagination
+// Requires a session; user_id may be given but must be the caller. Optionally filters by the status of the upload's analysis job, by tag and by
+// creation date (from, to), and sorts by created_at (default, newest first), file_size or
+// name with order=asc|desc
 func (h *UploadHandler) HandleListUploads(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get pagination parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-	userIDStr := r.URL.Query().Get("user_id")
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
 
-	limit := 10 // default
-	offset := 0 // default
+	// Get pagination parameters
+	page := ParsePagination(r, 10, 100)
+	query := r.URL.Query()
+	filter := repository.UploadFilter{
+		UserID: &userID,
+		Status: strings.ToLower(strings.TrimSpace(query.Get("status"))),
+		Tag:    strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+	}
 
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		id, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
+			return
+		}
+		if id != userID {
+			respondJSON(w, http.StatusForbidden, map[string]string{"error": "You can only list your own uploads"})
+			return
 		}
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	if filter.Status != "" && !validUploadStatuses[filter.Status] {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid status",
+			"message": "status must be one of: " + strings.Join(uploadStatusValues(), ", "),
+		})
+		return
+	}
+
+	if filter.CreatedFrom, ok = parseDateParam(w, r, "from", false); !ok {
+		return
+	}
+	if filter.CreatedBefore, ok = parseDateParam(w, r, "to", true); !ok {
+		return
+	}
+	if filter.CreatedFrom != nil && filter.CreatedBefore != nil && !filter.CreatedFrom.Before(*filter.CreatedBefore) {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "from must be before to"})
+		return
+	}
+
+	order := repository.UploadSort{Field: strings.ToLower(strings.TrimSpace(query.Get("sort")))}
+	if order.Field == "" {
+		order.Field = repository.UploadSortCreatedAt
+	}
+	ascending, ok := uploadSortDefaults[order.Field]
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error":   "Invalid sort",
+			"message": "sort must be one of: created_at, file_size, name",
+		})
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(query.Get("order"))) {
+	case "":
+		order.Ascending = ascending
+	case "asc":
+		order.Ascending = true
+	case "desc":
+		order.Ascending = false
+	default:
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid order (must be asc or desc)"})
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	var uploads []*models.Upload
-	var err error
-
-	// If user_id is provided, filter by user
-	if userIDStr != "" {
-		userID, parseErr := strconv.Atoi(userIDStr)
-		if parseErr != nil {
-			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid user_id"})
-			return
-		}
-		uploads, err = h.repo.ListUploadsByUserID(ctx, userID, limit, offset)
-	} else {
-		uploads, err = h.repo.ListUploads(ctx, limit, offset)
+	// Never list uploads outside the caller's tenant
+	tenantID, err := callerTenant(ctx, h.auth, r)
+	if err != nil {
+		log.Printf("Error resolving tenant for upload listing: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve uploads"})
+		return
 	}
+	filter.TenantID = &tenantID
 
+	uploads, err := h.repo.ListUploadsFiltered(ctx, filter, order, page.Limit, page.Offset)
 	if err != nil {
 		log.Printf("Error listing uploads: %v", err)
 		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve uploads"})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"uploads": uploads,
-		"count":   len(uploads),
-		"limit":   limit,
-		"offset":  offset,
-	})
+	total, err := h.repo.CountUploads(ctx, filter)
+	if err != nil {
+		log.Printf("Error counting uploads: %v", err)
+		respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve uploads"})
+		return
+	}
+
+	if uploads == nil {
+		uploads = []*models.Upload{}
+	}
+
+	respondJSON(w, http.StatusOK, NewPage(uploads, len(uploads), total, page))
 }
 
-// HandleDownloadFile serves the uploaded file for download
+// Content dispositions of a downloaded file
+const (
+	dispositionAttachment = "attachment"
+	dispositionInline     = "inline"
+)
+
+// inlineMimeTypes are the types browsers may display inline. Anything else, such as Word
+// documents that may carry macros, is always served as an attachment.
+var inlineMimeTypes = map[string]bool{
+	"application/pdf": true,
+}
+
+// HandleDownloadFile serves the uploaded file for download, or for display in the browser
+// with disposition=inline when its type is safe to display
 func (h *UploadHandler) HandleDownloadFile(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -256,6 +455,12 @@ func (h *UploadHandler) HandleDownloadFile(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	disposition, ok := parseDisposition(r.URL.Query().Get("disposition"))
+	if !ok {
+		http.Error(w, "Invalid disposition (must be inline or attachment)", http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
@@ -267,23 +472,75 @@ func (h *UploadHandler) HandleDownloadFile(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
+	h.writeUploadFile(ctx, w, upload, disposition)
+}
+
+// parseDisposition validates a disposition query parameter, defaulting to attachment
+func parseDisposition(disposition string) (string, bool) {
+	switch disposition {
+	case "":
+		return dispositionAttachment, true
+	case dispositionAttachment, dispositionInline:
+		return disposition, true
+	default:
+		return "", false
+	}
+}
+
+// writeUploadFile responds with the file of upload
+func (h *UploadHandler) writeUploadFile(ctx context.Context, w http.ResponseWriter, upload *models.Upload, disposition string) {
 	// Get file content
-	fileContent, err := h.repo.GetUploadFileContent(ctx, id)
+	fileContent, err := h.repo.GetUploadFileContent(ctx, upload.ID)
 	if err != nil {
 		log.Printf("Error getting file content: %v", err)
 		http.Error(w, "Failed to retrieve file", http.StatusInternalServerError)
 		return
 	}
 
+	// Never let the browser render a type that isn't safe to display inline
+	if !inlineMimeTypes[upload.MimeType] {
+		disposition = dispositionAttachment
+	}
+
 	// Set response headers
 	w.Header().Set("Content-Type", upload.MimeType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", upload.FileName))
+	w.Header().Set("Content-Disposition", contentDisposition(disposition, upload.FileName))
 	w.Header().Set("Content-Length", strconv.Itoa(len(fileContent)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 
 	// Write file content
 	w.Write(fileContent)
 }
 
+// contentDisposition formats a Content-Disposition header for a file. The file name comes
+// from the uploader, so control characters are dropped and quotes and backslashes replaced
+// before it's quoted, keeping it from ending the header or adding parameters. Non-ASCII
+// names are encoded as an RFC 2231 filename* parameter.
+func contentDisposition(disposition, fileName string) string {
+	fileName = strings.Map(func(r rune) rune {
+		switch {
+		case r < ' ' || r == 0x7f:
+			return -1
+		case r == '"' || r == '\\':
+			return '_'
+		}
+		return r
+	}, fileName)
+	fileName = strings.TrimSpace(fileName)
+	if fileName == "" {
+		fileName = "download"
+	}
+
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": fileName}); header != "" {
+		return header
+	}
+	return disposition
+}
+
 // isAllowedMimeType checks if the MIME type is in the allowed list
 func isAllowedMimeType(mimeType string) bool {
 	allowed := strings.Split(AllowedMimeTypes, ",")
@@ -327,6 +584,10 @@ func (h *UploadHandler) HandleAnalyzeResume(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
 	// TODO: Implement actual resume analysis logic here
 	// This is a placeholder that will be replaced with:
 	// - AI-powered resume parsing
@@ -359,7 +620,9 @@ func (h *UploadHandler) HandleAnalyzeResume(w http.ResponseWriter, r *http.Reque
 	log.Printf("Analysis job created: %s for upload ID: %d", jobID, id)
 }
 
-// HandleDeleteUpload deletes an upload and all related data
+// HandleDeleteUpload deletes an upload and all related data.
+// An upload with unfinished analysis jobs is only deleted with force=true, which cancels
+// the jobs first so their workers don't write to deleted rows.
 func (h *UploadHandler) HandleDeleteUpload(w http.ResponseWriter, r *http.Request) {
 	// Only allow DELETE requests
 	if r.Method != http.MethodDelete {
@@ -380,17 +643,57 @@ func (h *UploadHandler) HandleDeleteUpload(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	force := false
+	if forceStr := r.URL.Query().Get("force"); forceStr != "" {
+		force, err = strconv.ParseBool(forceStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid force value"})
+			return
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
 
-	// Verify the upload exists
-	_, err = h.repo.GetUploadByID(ctx, id)
+	// Verify the upload exists and belongs to the caller
+	upload, err := h.repo.GetUploadByID(ctx, id)
 	if err != nil {
 		log.Printf("Error getting upload for deletion: %v", err)
 		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
 		return
 	}
 
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
+	if h.analysisRepo != nil {
+		// Refuse to delete data a worker is still using, unless asked to stop it first
+		unfinished, err := h.unfinishedJobIDs(ctx, id)
+		if err != nil {
+			log.Printf("Error getting jobs for upload %d: %v", id, err)
+			respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to check analysis jobs"})
+			return
+		}
+
+		if len(unfinished) > 0 && !force {
+			respondJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":   "Upload has unfinished analysis jobs",
+				"message": "Wait for the analysis to finish, or delete with force=true to cancel it",
+				"job_ids": unfinished,
+			})
+			return
+		}
+
+		for _, jobID := range unfinished {
+			if err := h.cancelJob(ctx, jobID); err != nil {
+				log.Printf("Error cancelling job %s of upload %d: %v", jobID, id, err)
+				respondJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to cancel analysis job"})
+				return
+			}
+		}
+	}
+
 	// Delete related data in order:
 	// 1. Delete user profiles (depends on analysis jobs)
 	if h.analysisRepo != nil {
@@ -413,17 +716,267 @@ func (h *UploadHandler) HandleDeleteUpload(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Drop what the analyzer cached from the upload, e.g. its similar profiles
+	if forgetter, ok := h.jobs.(analyzer.UploadForgetter); ok {
+		forgetter.ForgetUpload(id)
+	}
+
 	log.Printf("Successfully deleted upload %d and all related data", id)
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "Upload and all related data deleted successfully",
 	})
 }
 
-// respondJSON sends a JSON response
+// unfinishedJobIDs returns the IDs of the upload's analysis jobs that haven't reached a final status
+func (h *UploadHandler) unfinishedJobIDs(ctx context.Context, uploadID int) ([]string, error) {
+	jobs, err := h.analysisRepo.GetJobsByUploadID(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, job := range jobs {
+		if !job.Finished() {
+			ids = append(ids, job.JobID)
+		}
+	}
+	return ids, nil
+}
+
+// cancelJob stops an unfinished job with the job canceller, or only marks it failed without one
+func (h *UploadHandler) cancelJob(ctx context.Context, jobID string) error {
+	if h.jobs != nil {
+		return h.jobs.CancelJob(ctx, jobID)
+	}
+	return h.analysisRepo.UpdateJobError(ctx, jobID, models.JobErrorCancelled, analyzer.CancelledJobMessage)
+}
+
+// HandlePinUpload pins or unpins an upload so it is kept by the retention policy
+// Query parameters: id (required), pinned=true|false (default true)
+func (h *UploadHandler) HandlePinUpload(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Upload ID is required"})
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid upload ID"})
+		return
+	}
+
+	pinned := true
+	if pinnedStr := r.URL.Query().Get("pinned"); pinnedStr != "" {
+		pinned, err = strconv.ParseBool(pinnedStr)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid pinned value"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	upload, err := h.repo.GetUploadByID(ctx, id)
+	if err != nil {
+		log.Printf("Error getting upload for pin: %v", err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
+	if err := h.repo.SetUploadPinned(ctx, id, pinned); err != nil {
+		log.Printf("Error updating pin for upload %d: %v", id, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	log.Printf("Upload %d pinned=%t", id, pinned)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":     id,
+		"pinned": pinned,
+	})
+}
+
+// Limits of upload tags and notes
+const (
+	maxUploadTags      = 20
+	maxUploadTagLength = 50
+	maxUploadNotes     = 5000
+)
+
+// UploadMetadataRequest updates the tags and notes of an upload. Omitted fields are unchanged.
+type UploadMetadataRequest struct {
+	Tags  *[]string `json:"tags,omitempty"`  // Replaces all tags; an empty list removes them
+	Notes *string   `json:"notes,omitempty"` // Replaces the notes; empty clears them
+}
+
+// HandleUpdateUploadMetadata sets the tags and notes of an upload
+func (h *UploadHandler) HandleUpdateUploadMetadata(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Upload ID is required"})
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid upload ID"})
+		return
+	}
+
+	var req UploadMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = normalizeUploadTags(*req.Tags); err != nil {
+			respondJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	if req.Notes != nil && len([]rune(*req.Notes)) > maxUploadNotes {
+		respondJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Notes must be at most %d characters", maxUploadNotes),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	upload, err := h.repo.GetUploadByID(ctx, id)
+	if err != nil {
+		log.Printf("Error getting upload for metadata update: %v", err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	if !authorizeUpload(w, r, h.auth, upload) {
+		return
+	}
+
+	if req.Tags == nil {
+		tags = upload.Tags
+	}
+	notes := upload.Notes
+	if req.Notes != nil {
+		notes = strings.TrimSpace(*req.Notes)
+	}
+
+	if err := h.repo.UpdateUploadMetadata(ctx, id, tags, notes); err != nil {
+		log.Printf("Error updating metadata of upload %d: %v", id, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	log.Printf("Upload %d metadata updated: %d tags, %d characters of notes", id, len(tags), len(notes))
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    id,
+		"tags":  tags,
+		"notes": notes,
+	})
+}
+
+// normalizeUploadTags lowercases and trims tags, dropping empty and duplicate entries,
+// and checks them against the tag limits
+func normalizeUploadTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > maxUploadTagLength {
+			return nil, fmt.Errorf("Tags must be at most %d characters", maxUploadTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxUploadTags {
+		return nil, fmt.Errorf("At most %d tags are allowed", maxUploadTags)
+	}
+	return normalized, nil
+}
+
+// HandleCheckUploadOwnership reports whether an upload belongs to the authenticated user
+func (h *UploadHandler) HandleCheckUploadOwnership(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Upload ID is required"})
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid upload ID"})
+		return
+	}
+
+	userID, ok := callerID(h.auth, r)
+	if !ok {
+		respondJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	owned, err := h.repo.OwnsUpload(ctx, userID, id)
+	if err != nil {
+		log.Printf("Error checking owner of upload %d: %v", id, err)
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "Upload not found"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"upload_id": id,
+		"user_id":   userID,
+		"owned":     owned,
+	})
+}
+
+// respondJSON sends a JSON response. Nil slices and maps are sent as [] and {} rather
+// than null, and the output is indented if requested (see PrettyJSON and SetPrettyJSON).
 func respondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	encoder := json.NewEncoder(w)
+	if wantsPrettyJSON(w) {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(normalizeEmpty(data)); err != nil {
 		log.Printf("Error encoding JSON response: %v", err)
 	}
 }
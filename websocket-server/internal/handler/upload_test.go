@@ -0,0 +1,817 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestHandleListUploadsStatus(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "done.pdf", JobID: strPtr("job_1"), JobStatus: strPtr("completed"), JobProgress: intPtr(100)},
+		{ID: 2, UserID: intPtr(7), FileName: "running.pdf", JobID: strPtr("job_2"), JobStatus: strPtr("analyzing"), JobProgress: intPtr(70)},
+		{ID: 3, UserID: intPtr(7), FileName: "new.pdf"},
+		{ID: 4, UserID: intPtr(7), FileName: "failed.pdf", JobID: strPtr("job_4"), JobStatus: strPtr("failed"), JobProgress: intPtr(25)},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int
+		wantFilter string // Status passed to the repository
+	}{
+		{"all", "", http.StatusOK, []int{1, 2, 3, 4}, ""},
+		{"completed", "status=completed", http.StatusOK, []int{1}, "completed"},
+		{"status ignores case", "status=Analyzing", http.StatusOK, []int{2}, "analyzing"},
+		{"not analyzed", "status=not_analyzed", http.StatusOK, []int{3}, models.UploadStatusNotAnalyzed},
+		{"no match", "status=queued", http.StatusOK, []int{}, "queued"},
+		{"unknown status", "status=done", http.StatusBadRequest, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.lastFilter.Status = ""
+			w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if repo.lastFilter.Status != tt.wantFilter {
+				t.Errorf("repository filtered by status %q, want %q", repo.lastFilter.Status, tt.wantFilter)
+			}
+
+			var page struct {
+				Items []models.Upload `json:"items"`
+				Total int             `json:"total"`
+			}
+			decodeBody(t, w, &page)
+
+			ids := []int{}
+			for _, upload := range page.Items {
+				ids = append(ids, upload.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || page.Total != len(tt.wantIDs) {
+				t.Errorf("uploads = %v (total %d), want %v", ids, page.Total, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsDateRange(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC) }
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "feb.pdf", CreatedAt: time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC)},
+		{ID: 2, UserID: intPtr(7), FileName: "early.pdf", CreatedAt: day(1, 0)},
+		{ID: 3, UserID: intPtr(7), FileName: "late.pdf", CreatedAt: day(10, 23)},
+		{ID: 4, UserID: intPtr(7), FileName: "after.pdf", CreatedAt: day(11, 0)},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []int
+	}{
+		{"no range", "", http.StatusOK, []int{1, 2, 3, 4}},
+		{"from a date", "from=2026-03-01", http.StatusOK, []int{2, 3, 4}},
+		{"to a date includes the whole day", "to=2026-03-10", http.StatusOK, []int{1, 2, 3}},
+		{"date range", "from=2026-03-01&to=2026-03-10", http.StatusOK, []int{2, 3}},
+		{"timestamps", "from=2026-03-01T00:30:00Z&to=2026-03-11T00:00:00Z", http.StatusOK, []int{3}},
+		{"timestamp with offset", "to=2026-03-01T01:00:00%2B01:00", http.StatusOK, []int{1}},
+		{"single day", "from=2026-03-11&to=2026-03-11", http.StatusOK, []int{4}},
+		{"invalid from", "from=March", http.StatusBadRequest, nil},
+		{"invalid to", "to=2026-13-01", http.StatusBadRequest, nil},
+		{"from after to", "from=2026-03-11&to=2026-03-01", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page struct {
+				Items []models.Upload `json:"items"`
+				Total int             `json:"total"`
+			}
+			decodeBody(t, w, &page)
+			ids := []int{}
+			for _, upload := range page.Items {
+				ids = append(ids, upload.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || page.Total != len(tt.wantIDs) {
+				t.Errorf("uploads = %v (total %d), want %v", ids, page.Total, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsSort(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{{ID: 1, UserID: intPtr(7), FileName: "a.pdf"}}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       repository.UploadSort
+	}{
+		{"default is newest first", "", http.StatusOK, repository.UploadSort{Field: repository.UploadSortCreatedAt}},
+		{"oldest first", "sort=created_at&order=asc", http.StatusOK, repository.UploadSort{Field: repository.UploadSortCreatedAt, Ascending: true}},
+		{"file size defaults to largest first", "sort=file_size", http.StatusOK, repository.UploadSort{Field: repository.UploadSortFileSize}},
+		{"smallest first", "sort=file_size&order=asc", http.StatusOK, repository.UploadSort{Field: repository.UploadSortFileSize, Ascending: true}},
+		{"name defaults to A to Z", "sort=name", http.StatusOK, repository.UploadSort{Field: repository.UploadSortName, Ascending: true}},
+		{"name Z to A", "sort=Name&order=DESC", http.StatusOK, repository.UploadSort{Field: repository.UploadSortName}},
+		{"order alone", "order=asc", http.StatusOK, repository.UploadSort{Field: repository.UploadSortCreatedAt, Ascending: true}},
+		{"unknown field", "sort=file_name", http.StatusBadRequest, repository.UploadSort{}},
+		{"column injection", "sort=created_at%3B+DROP+TABLE+user_uploads", http.StatusBadRequest, repository.UploadSort{}},
+		{"unknown order", "sort=name&order=up", http.StatusBadRequest, repository.UploadSort{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.lastOrder = repository.UploadSort{}
+			w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if repo.lastOrder != tt.want {
+				t.Errorf("repository sorted by %+v, want %+v", repo.lastOrder, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsJobFields(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "running.pdf", JobID: strPtr("job_1"), JobStatus: strPtr("analyzing"), JobProgress: intPtr(70)},
+		{ID: 2, UserID: intPtr(7), FileName: "new.pdf"},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads", nil), 7))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+
+	var page struct {
+		Items []map[string]any `json:"items"`
+	}
+	decodeBody(t, w, &page)
+	if len(page.Items) != 2 {
+		t.Fatalf("got %d uploads, want 2", len(page.Items))
+	}
+
+	analyzed, fresh := page.Items[0], page.Items[1]
+	if analyzed["job_id"] != "job_1" || analyzed["job_status"] != "analyzing" || analyzed["job_progress"] != float64(70) {
+		t.Errorf("analyzed upload = %v, want job job_1 analyzing at 70%%", analyzed)
+	}
+	for _, field := range []string{"job_id", "job_status", "job_progress"} {
+		if _, ok := fresh[field]; ok {
+			t.Errorf("upload without a job has %s", field)
+		}
+	}
+}
+
+// multipartBody returns the content type and body of an upload form with a resume of size
+// bytes and the given extra fields
+func multipartBody(t *testing.T, size int, fields map[string]string) (string, *bytes.Buffer) {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="resume"; filename="resume.pdf"`)
+	header.Set("Content-Type", "application/pdf")
+	part, err := form.CreatePart(header)
+	if err != nil {
+		t.Fatalf("CreatePart: %v", err)
+	}
+	part.Write(bytes.Repeat([]byte("a"), size))
+	form.Close()
+	return form.FormDataContentType(), &body
+}
+
+func TestHandleUploadSizeLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        *bytes.Buffer
+		wantStatus  int
+		wantError   string
+	}{
+		{
+			name:       "oversized body",
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  "File too large",
+		},
+		{
+			name:       "within limit",
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:        "malformed multipart",
+			contentType: "multipart/form-data; boundary=xyz",
+			body:        bytes.NewBufferString("--abc\r\nnot a form"),
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "Invalid form data",
+		},
+		{
+			name:        "not multipart",
+			contentType: "application/json",
+			body:        bytes.NewBufferString(`{"resume":"x"}`),
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "Invalid form data",
+		},
+	}
+	tests[0].contentType, tests[0].body = multipartBody(t, MaxUploadSize+1, nil)
+	tests[1].contentType, tests[1].body = multipartBody(t, 1024, nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUploadRepo{}
+			h := NewUploadHandler(repo, nil, nil, nil, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/upload", tt.body)
+			r.Header.Set("Content-Type", tt.contentType)
+			w := serve(h.HandleUpload, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusCreated {
+				if len(repo.uploads) != 1 || repo.uploads[0].FileSize != 1024 {
+					t.Errorf("stored uploads = %+v, want one of 1024 bytes", repo.uploads)
+				}
+				return
+			}
+
+			var body map[string]string
+			decodeBody(t, w, &body)
+			if body["error"] != tt.wantError {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge && !strings.Contains(body["message"], "10485760 bytes") {
+				t.Errorf("message = %q, want the limit in bytes", body["message"])
+			}
+			if len(repo.uploads) != 0 {
+				t.Errorf("rejected upload was stored")
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsIsScopedToCaller(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "mine.pdf"},
+		{ID: 2, UserID: intPtr(8), FileName: "theirs.pdf"},
+		{ID: 3, FileName: "anonymous.pdf"},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		caller     int // 0 for no session
+		wantStatus int
+		wantIDs    []int
+	}{
+		{"own uploads", "", 7, http.StatusOK, []int{1}},
+		{"own user_id", "user_id=7", 7, http.StatusOK, []int{1}},
+		{"other user's uploads", "user_id=8", 7, http.StatusForbidden, nil},
+		{"invalid user_id", "user_id=x", 7, http.StatusBadRequest, nil},
+		{"no session", "", 0, http.StatusUnauthorized, nil},
+		{"no session with user_id", "user_id=7", 0, http.StatusUnauthorized, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+			w := serve(h.HandleListUploads, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page struct {
+				Items []models.Upload `json:"items"`
+			}
+			decodeBody(t, w, &page)
+			ids := []int{}
+			for _, upload := range page.Items {
+				ids = append(ids, upload.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("uploads = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestHandleUploadOwner(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymous  bool // Anonymous mode
+		caller     int  // 0 for no session
+		formUserID string
+		wantStatus int
+		wantOwner  *int
+	}{
+		{"owner from session", false, 7, "", http.StatusCreated, intPtr(7)},
+		{"matching user_id", false, 7, "7", http.StatusCreated, intPtr(7)},
+		{"anonymous mode", true, 0, "", http.StatusCreated, nil},
+		{"no session outside anonymous mode", false, 0, "", http.StatusUnauthorized, nil},
+		{"other user's user_id", true, 7, "8", http.StatusForbidden, nil},
+		{"user_id without a session", true, 0, "8", http.StatusUnauthorized, nil},
+		{"invalid user_id", false, 7, "x", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var auth Authenticator = headerAuth{}
+			if tt.anonymous {
+				auth = anonymousHeaderAuth{}
+			}
+			repo := &fakeUploadRepo{}
+			h := NewUploadHandler(repo, nil, auth, nil, nil)
+
+			fields := map[string]string{}
+			if tt.formUserID != "" {
+				fields["user_id"] = tt.formUserID
+			}
+			contentType, body := multipartBody(t, 1024, fields)
+			r := httptest.NewRequest(http.MethodPost, "/api/upload", body)
+			r.Header.Set("Content-Type", contentType)
+			if tt.caller != 0 {
+				asUser(r, tt.caller)
+			}
+
+			w := serve(h.HandleUpload, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				if len(repo.uploads) != 0 {
+					t.Errorf("rejected upload was stored")
+				}
+				return
+			}
+			if len(repo.uploads) != 1 || !reflect.DeepEqual(repo.uploads[0].UserID, tt.wantOwner) {
+				t.Errorf("stored uploads = %+v, want one owned by %v", repo.uploads, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestUploadOwnershipOnDownloadAndDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		anonymous  bool // Anonymous mode
+		owner      *int
+		caller     int // 0 for no session
+		wantStatus int
+	}{
+		{"owner", false, intPtr(7), 7, http.StatusOK},
+		{"non-owner", false, intPtr(7), 8, http.StatusForbidden},
+		{"non-owner in anonymous mode", true, intPtr(7), 8, http.StatusForbidden},
+		{"no session", false, intPtr(7), 0, http.StatusUnauthorized},
+		{"anonymous upload in anonymous mode", true, nil, 8, http.StatusOK},
+		{"anonymous upload without a session in anonymous mode", true, nil, 0, http.StatusOK},
+		{"anonymous upload", false, nil, 8, http.StatusForbidden},
+		{"anonymous upload without a session", false, nil, 0, http.StatusUnauthorized},
+	}
+
+	handlers := []struct {
+		name   string
+		method string
+		serve  func(h *UploadHandler) http.HandlerFunc
+	}{
+		{"download", http.MethodGet, func(h *UploadHandler) http.HandlerFunc { return h.HandleDownloadFile }},
+		{"delete", http.MethodDelete, func(h *UploadHandler) http.HandlerFunc { return h.HandleDeleteUpload }},
+	}
+
+	for _, handler := range handlers {
+		for _, tt := range tests {
+			t.Run(handler.name+" "+tt.name, func(t *testing.T) {
+				repo := &fakeUploadRepo{uploads: []*models.Upload{
+					{ID: 1, UserID: tt.owner, FileName: "resume.pdf", MimeType: "application/pdf", FileContent: []byte("%PDF")},
+				}}
+				var auth Authenticator = headerAuth{}
+				if tt.anonymous {
+					auth = anonymousHeaderAuth{}
+				}
+				h := NewUploadHandler(repo, nil, auth, nil, nil)
+
+				r := httptest.NewRequest(handler.method, "/api/uploads/file?id=1", nil)
+				if tt.caller != 0 {
+					asUser(r, tt.caller)
+				}
+				w := serve(handler.serve(h), r)
+				if w.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+				}
+
+				deleted := len(repo.uploads) == 0
+				if wantDeleted := handler.method == http.MethodDelete && tt.wantStatus == http.StatusOK; deleted != wantDeleted {
+					t.Errorf("deleted = %t, want %t", deleted, wantDeleted)
+				}
+				if handler.method == http.MethodGet && tt.wantStatus == http.StatusOK && w.Body.String() != "%PDF" {
+					t.Errorf("body = %q, want the file", w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+// recordingCanceller cancels jobs by marking them failed in jobs, recording their IDs
+type recordingCanceller struct {
+	jobs      *fakeAnalysisRepo
+	cancelled []string
+}
+
+func (c *recordingCanceller) CancelJob(ctx context.Context, jobID string) error {
+	c.cancelled = append(c.cancelled, jobID)
+	return c.jobs.UpdateJobError(ctx, jobID, models.JobErrorCancelled, analyzer.CancelledJobMessage)
+}
+
+func TestHandleDeleteUploadWithUnfinishedJobs(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      map[string]string // Job statuses by job ID
+		query         string
+		canceller     bool
+		wantStatus    int
+		wantCancelled []string
+	}{
+		{
+			name:       "finished jobs don't block deletion",
+			statuses:   map[string]string{"job_a": "completed", "job_b": "failed", "job_c": "dead_lettered"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "running job blocks deletion",
+			statuses:   map[string]string{"job_a": "completed", "job_b": "analyzing", "job_c": "queued"},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:          "forced deletion cancels the running jobs",
+			statuses:      map[string]string{"job_a": "completed", "job_b": "analyzing", "job_c": "queued"},
+			query:         "&force=true",
+			canceller:     true,
+			wantStatus:    http.StatusOK,
+			wantCancelled: []string{"job_b", "job_c"},
+		},
+		{
+			name:       "forced deletion without a canceller marks the jobs failed",
+			statuses:   map[string]string{"job_b": "generating_embeddings"},
+			query:      "&force=1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid force",
+			statuses:   map[string]string{"job_b": "analyzing"},
+			query:      "&force=please",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUploadRepo{uploads: []*models.Upload{{ID: 1, UserID: intPtr(7), FileName: "resume.pdf"}}}
+			analysisRepo := &fakeAnalysisRepo{jobs: make(map[string]*models.AnalysisJob)}
+			for jobID, status := range tt.statuses {
+				analysisRepo.jobs[jobID] = &models.AnalysisJob{JobID: jobID, UploadID: 1, Status: status}
+			}
+			jobs := map[string]*models.AnalysisJob{}
+			for jobID, job := range analysisRepo.jobs {
+				jobs[jobID] = job
+			}
+
+			var canceller *recordingCanceller
+			var jobCanceller JobCanceller
+			if tt.canceller {
+				canceller = &recordingCanceller{jobs: analysisRepo}
+				jobCanceller = canceller
+			}
+			h := NewUploadHandler(repo, analysisRepo, headerAuth{}, jobCanceller, nil)
+
+			w := serve(h.HandleDeleteUpload, asUser(httptest.NewRequest(http.MethodDelete, "/api/uploads?id=1"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			deleted := len(repo.uploads) == 0
+			if wantDeleted := tt.wantStatus == http.StatusOK; deleted != wantDeleted {
+				t.Errorf("deleted = %t, want %t", deleted, wantDeleted)
+			}
+
+			if tt.wantStatus == http.StatusConflict {
+				var body struct {
+					JobIDs []string `json:"job_ids"`
+				}
+				decodeBody(t, w, &body)
+				if !reflect.DeepEqual(body.JobIDs, []string{"job_b", "job_c"}) {
+					t.Errorf("job_ids = %v, want the unfinished jobs", body.JobIDs)
+				}
+				for jobID, status := range tt.statuses {
+					if jobs[jobID].Status != status {
+						t.Errorf("job %s became %s, want it left %s", jobID, jobs[jobID].Status, status)
+					}
+				}
+			}
+
+			if canceller != nil && !reflect.DeepEqual(canceller.cancelled, tt.wantCancelled) {
+				t.Errorf("cancelled = %v, want %v", canceller.cancelled, tt.wantCancelled)
+			}
+			if tt.wantStatus == http.StatusOK && tt.query != "" {
+				// Every job that was running was stopped before its rows were deleted
+				for jobID, status := range tt.statuses {
+					job := jobs[jobID]
+					if status != "completed" && (job.Status != "failed" || job.ErrorMessage == nil || *job.ErrorMessage != analyzer.CancelledJobMessage) {
+						t.Errorf("job %s is %s, want it cancelled", jobID, job.Status)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUploadsAreScopedToTenant(t *testing.T) {
+	auth := tenantAuth{tenants: map[int]string{7: "acme", 8: ""}}
+
+	t.Run("upload stored under the caller's tenant", func(t *testing.T) {
+		for caller, want := range map[int]string{7: "acme", 8: ""} {
+			repo := &fakeUploadRepo{}
+			h := NewUploadHandler(repo, nil, auth, nil, nil)
+
+			contentType, body := multipartBody(t, 1024, nil)
+			r := asUser(httptest.NewRequest(http.MethodPost, "/api/upload", body), caller)
+			r.Header.Set("Content-Type", contentType)
+
+			if w := serve(h.HandleUpload, r); w.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+			}
+			if len(repo.uploads) != 1 || repo.uploads[0].TenantID != want {
+				t.Errorf("user %d's upload stored under tenant %q, want %q", caller, repo.uploads[0].TenantID, want)
+			}
+		}
+	})
+
+	t.Run("listing excludes other tenants", func(t *testing.T) {
+		repo := &fakeUploadRepo{uploads: []*models.Upload{
+			{ID: 1, UserID: intPtr(7), TenantID: "acme", FileName: "acme.pdf"},
+			{ID: 2, UserID: intPtr(7), FileName: "before joining acme.pdf"},
+			{ID: 3, UserID: intPtr(7), TenantID: "globex", FileName: "globex.pdf"},
+		}}
+		h := NewUploadHandler(repo, nil, auth, nil, nil)
+
+		w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads", nil), 7))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		var page struct {
+			Items []models.Upload `json:"items"`
+			Total int             `json:"total"`
+		}
+		decodeBody(t, w, &page)
+		if len(page.Items) != 1 || page.Items[0].ID != 1 || page.Total != 1 {
+			t.Errorf("page = %+v, want only upload 1", page)
+		}
+		if tenantID := repo.lastFilter.TenantID; tenantID == nil || *tenantID != "acme" {
+			t.Errorf("listed without the acme tenant filter: %+v", repo.lastFilter)
+		}
+	})
+
+	t.Run("unresolved tenant", func(t *testing.T) {
+		repo := &fakeUploadRepo{}
+		h := NewUploadHandler(repo, nil, auth, nil, nil)
+
+		if w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads", nil), 9)); w.Code != http.StatusInternalServerError {
+			t.Errorf("list status = %d, want 500", w.Code)
+		}
+
+		contentType, body := multipartBody(t, 1024, nil)
+		r := asUser(httptest.NewRequest(http.MethodPost, "/api/upload", body), 9)
+		r.Header.Set("Content-Type", contentType)
+		if w := serve(h.HandleUpload, r); w.Code != http.StatusInternalServerError || len(repo.uploads) != 0 {
+			t.Errorf("upload status = %d with %d stored, want 500 with none", w.Code, len(repo.uploads))
+		}
+	})
+}
+
+func TestHandleDownloadFileDisposition(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "resume.pdf", MimeType: "application/pdf", FileContent: []byte("%PDF")},
+		{ID: 2, UserID: intPtr(7), FileName: "resume.docx", MimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", FileContent: []byte("PK")},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantHeader string
+	}{
+		{"attachment by default", "id=1", http.StatusOK, `attachment; filename=resume.pdf`},
+		{"explicit attachment", "id=1&disposition=attachment", http.StatusOK, `attachment; filename=resume.pdf`},
+		{"inline PDF", "id=1&disposition=inline", http.StatusOK, `inline; filename=resume.pdf`},
+		{"inline refused for Word documents", "id=2&disposition=inline", http.StatusOK, `attachment; filename=resume.docx`},
+		{"unknown disposition", "id=1&disposition=render", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h.HandleDownloadFile, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads/download?"+tt.query, nil), 7))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Disposition"); got != tt.wantHeader {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantHeader)
+			}
+			if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+			}
+		})
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+	}{
+		{"plain", "resume.pdf", `attachment; filename=resume.pdf`},
+		{"spaces quoted", "my resume.pdf", `attachment; filename="my resume.pdf"`},
+		{"quotes replaced", `say "hi".pdf`, `attachment; filename="say _hi_.pdf"`},
+		{"backslashes replaced", `C:\resume.pdf`, `attachment; filename="C:_resume.pdf"`},
+		{"newlines dropped", "resume.pdf\r\nSet-Cookie: session=x", `attachment; filename="resume.pdfSet-Cookie: session=x"`},
+		{"parameters not injected", `resume.pdf"; filename="evil.exe`, `attachment; filename="resume.pdf_; filename=_evil.exe"`},
+		{"non-ASCII encoded", "résumé.pdf", `attachment; filename*=utf-8''r%C3%A9sum%C3%A9.pdf`},
+		{"empty", "\n\t ", `attachment; filename=download`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contentDisposition(dispositionAttachment, tt.fileName)
+			if got != tt.want {
+				t.Errorf("contentDisposition(%q) = %q, want %q", tt.fileName, got, tt.want)
+			}
+			if strings.ContainsAny(got, "\r\n") {
+				t.Errorf("header %q contains a line break", got)
+			}
+		})
+	}
+}
+
+func TestHandleUpdateUploadMetadata(t *testing.T) {
+	// tagsBody sets n distinct tags
+	tagsBody := func(n int) string {
+		tags := make([]string, n)
+		for i := range tags {
+			tags[i] = fmt.Sprintf("tag%d", i)
+		}
+		body, _ := json.Marshal(map[string][]string{"tags": tags})
+		return string(body)
+	}
+
+	newRepo := func() *fakeUploadRepo {
+		return &fakeUploadRepo{uploads: []*models.Upload{
+			{ID: 1, UserID: intPtr(7), FileName: "resume.pdf", Tags: []string{"2024"}, Notes: "Met at the meetup"},
+			{ID: 2, UserID: intPtr(8), FileName: "theirs.pdf"},
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		wantStatus int
+		wantTags   []string
+		wantNotes  string
+	}{
+		{"tags set", "id=1", `{"tags": [" Frontend", "referral", "FRONTEND", ""]}`, http.StatusOK, []string{"frontend", "referral"}, "Met at the meetup"},
+		{"notes updated", "id=1", `{"notes": "  Strong Go background  "}`, http.StatusOK, []string{"2024"}, "Strong Go background"},
+		{"both replaced", "id=1", `{"tags": ["backend"], "notes": "Follow up"}`, http.StatusOK, []string{"backend"}, "Follow up"},
+		{"tags removed and notes cleared", "id=1", `{"tags": [], "notes": ""}`, http.StatusOK, []string{}, ""},
+		{"tag too long", "id=1", `{"tags": ["` + strings.Repeat("x", maxUploadTagLength+1) + `"]}`, http.StatusBadRequest, nil, ""},
+		{"too many tags", "id=1", tagsBody(maxUploadTags + 1), http.StatusBadRequest, nil, ""},
+		{"most tags allowed", "id=1", tagsBody(maxUploadTags), http.StatusOK, nil, "Met at the meetup"},
+		{"notes too long", "id=1", `{"notes": "` + strings.Repeat("x", maxUploadNotes+1) + `"}`, http.StatusBadRequest, nil, ""},
+		{"invalid body", "id=1", `{"tags": "frontend"}`, http.StatusBadRequest, nil, ""},
+		{"missing ID", "", `{}`, http.StatusBadRequest, nil, ""},
+		{"unknown upload", "id=9", `{}`, http.StatusNotFound, nil, ""},
+		{"other user's upload", "id=2", `{"tags": ["mine"]}`, http.StatusForbidden, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+			r := asUser(httptest.NewRequest(http.MethodPost, "/api/uploads/metadata?"+tt.query, strings.NewReader(tt.body)), 7)
+			w := serve(h.HandleUpdateUploadMetadata, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if upload := repo.uploads[0]; !slices.Equal(upload.Tags, []string{"2024"}) || upload.Notes != "Met at the meetup" {
+					t.Errorf("rejected update changed upload 1 to %q, %q", upload.Tags, upload.Notes)
+				}
+				return
+			}
+
+			var resp struct {
+				Tags  []string `json:"tags"`
+				Notes string   `json:"notes"`
+			}
+			decodeBody(t, w, &resp)
+			stored := repo.uploads[0]
+			if tt.wantTags == nil {
+				if len(resp.Tags) != maxUploadTags || len(stored.Tags) != maxUploadTags {
+					t.Errorf("%d tags returned and %d stored, want %d", len(resp.Tags), len(stored.Tags), maxUploadTags)
+				}
+			} else if !reflect.DeepEqual(resp.Tags, tt.wantTags) || !slices.Equal(stored.Tags, tt.wantTags) {
+				t.Errorf("tags returned %q and stored %q, want %q", resp.Tags, stored.Tags, tt.wantTags)
+			}
+			if resp.Notes != tt.wantNotes || stored.Notes != tt.wantNotes {
+				t.Errorf("notes returned %q and stored %q, want %q", resp.Notes, stored.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestHandleListUploadsTag(t *testing.T) {
+	repo := &fakeUploadRepo{uploads: []*models.Upload{
+		{ID: 1, UserID: intPtr(7), FileName: "frontend.pdf", Tags: []string{"frontend", "2024"}},
+		{ID: 2, UserID: intPtr(7), FileName: "backend.pdf", Tags: []string{"backend", "referral"}},
+		{ID: 3, UserID: intPtr(7), FileName: "untagged.pdf"},
+		{ID: 4, UserID: intPtr(8), FileName: "theirs.pdf", Tags: []string{"frontend"}},
+	}}
+	h := NewUploadHandler(repo, nil, headerAuth{}, nil, nil)
+
+	tests := []struct {
+		query   string
+		wantIDs []int
+	}{
+		{"tag=frontend", []int{1}},
+		{"tag=+Referral+", []int{2}},
+		{"tag=design", []int{}},
+		{"", []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := serve(h.HandleListUploads, asUser(httptest.NewRequest(http.MethodGet, "/api/uploads?"+tt.query, nil), 7))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+
+			var page struct {
+				Items []models.Upload `json:"items"`
+			}
+			decodeBody(t, w, &page)
+			ids := []int{}
+			for _, upload := range page.Items {
+				ids = append(ids, upload.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("uploads = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -58,6 +58,7 @@ func (wsh *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Requ
 
 	// Create new client
 	client := hub.NewClient(wsh.hub, conn, clientID)
+	client.SetUpgradeRequest(r)
 
 	// Register the client
 	wsh.hub.Register(client)
@@ -99,9 +100,12 @@ func HandleHealth(w http.ResponseWriter, r *http.Request) {
 func (wsh *WebSocketHandler) HandleStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	remaining := wsh.hub.SimulationRemaining()
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"connected_clients": wsh.hub.GetClientCount(),
-		"timestamp":         time.Now().Unix(),
+		"connected_clients":            wsh.hub.GetClientCount(),
+		"simulation_active":            remaining > 0,
+		"simulation_remaining_seconds": remaining.Seconds(),
+		"timestamp":                    time.Now().Unix(),
 	})
 }
 
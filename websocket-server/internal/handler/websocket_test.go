@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+	"github.com/your-org/websocket-server/internal/hub"
+)
+
+func TestSimulateDisconnect(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	wsh := NewWebSocketHandler(hub.NewHub(&hub.HubConfig{Clock: fake}))
+
+	type stats struct {
+		Active    bool    `json:"simulation_active"`
+		Remaining float64 `json:"simulation_remaining_seconds"`
+	}
+	getStats := func(t *testing.T) stats {
+		t.Helper()
+		var got stats
+		decodeBody(t, serve(wsh.HandleStats, httptest.NewRequest(http.MethodGet, "/stats", nil)), &got)
+		return got
+	}
+	// connect returns the status of a plain HTTP connection request, which the upgrade
+	// rejects with 400 once the simulation no longer does with 503
+	connect := func() int {
+		return serve(wsh.HandleWebSocket, httptest.NewRequest(http.MethodGet, "/ws", nil)).Code
+	}
+
+	w := serve(wsh.HandleSimulateDisconnect, httptest.NewRequest(http.MethodPost, "/simulate-disconnect?duration=15", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if got := getStats(t); got != (stats{Active: true, Remaining: 15}) {
+		t.Errorf("stats = %+v, want active with 15s left", got)
+	}
+	if code := connect(); code != http.StatusServiceUnavailable {
+		t.Errorf("connection during the simulation = %d, want 503", code)
+	}
+
+	fake.Advance(10 * time.Second)
+	if got := getStats(t); got != (stats{Active: true, Remaining: 5}) {
+		t.Errorf("stats = %+v, want active with 5s left", got)
+	}
+
+	fake.Advance(5 * time.Second)
+	if got := getStats(t); got != (stats{}) {
+		t.Errorf("stats = %+v, want the simulation over", got)
+	}
+	if code := connect(); code == http.StatusServiceUnavailable {
+		t.Error("connection still refused after the simulation ended")
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		if w := serve(wsh.HandleSimulateDisconnect, httptest.NewRequest(http.MethodGet, "/simulate-disconnect", nil)); w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("GET status = %d, want 405", w.Code)
+		}
+		if w := serve(wsh.HandleSimulateDisconnect, httptest.NewRequest(http.MethodPost, "/simulate-disconnect?duration=soon", nil)); w.Code != http.StatusBadRequest {
+			t.Errorf("invalid duration status = %d, want 400", w.Code)
+		}
+	})
+}
@@ -3,7 +3,11 @@ package hub
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -23,15 +27,53 @@ const (
 
 	// Maximum message size allowed from peer.
 	maxMessageSize = 512 * 1024 // 512 KB
+
+	// Messages buffered for a client before broadcasts to it are dropped.
+	// Bounds the memory a slow consumer can hold.
+	sendBufferSize = 256
 )
 
+// errClientGone stops a streamed chat reply once its client has disconnected
+var errClientGone = errors.New("client disconnected")
+
 // Client represents a WebSocket client connection
 type Client struct {
 	hub       *Hub
 	conn      *websocket.Conn
 	send      chan []byte
+	done      chan struct{} // Closed by disconnect; nothing is queued on send afterwards
+	closeOnce sync.Once
 	id        string
+	sessionID string              // Chat session the client last reported
 	qaMatcher qamatcher.QAMatcher // Q&A matcher for this session
+	fallback  *fallbackResponder  // Reply used when no Q&A pair matches
+	missed    int                 // Consecutive broadcasts dropped because send was full; owned by the hub loop
+	request   *http.Request       // Upgrade request of the connection, nil if unknown
+
+	// Chat messages being answered concurrently, see dispatchChat
+	chatSlots chan struct{}   // Semaphore bounding the chat messages in flight
+	lastReply <-chan struct{} // Closed once the latest chat reply has been sent; owned by readPump
+
+	// Guards qaMatcher and fallback, which load_qa replaces while chat replies are using them
+	configMu sync.RWMutex
+
+	// Outcome of the most recent Q&A lookup, for diagnostics
+	matchMu        sync.Mutex
+	lastQueryAt    time.Time
+	lastSimilarity float64
+	lastMatched    bool
+}
+
+// QAState describes a client's Q&A matcher for diagnostics
+type QAState struct {
+	ClientID       string     `json:"client_id"`
+	Loaded         bool       `json:"loaded"`
+	QuestionCount  int        `json:"question_count"`
+	Threshold      float64    `json:"threshold"`
+	FallbackMode   string     `json:"fallback_mode"`
+	LastQueryAt    *time.Time `json:"last_query_at,omitempty"`   // Time of the last Q&A lookup
+	LastSimilarity *float64   `json:"last_similarity,omitempty"` // Best similarity found by that lookup
+	LastMatched    bool       `json:"last_matched"`              // Whether it cleared the threshold
 }
 
 // NewClient creates a new client instance
@@ -39,25 +81,106 @@ func NewClient(hub *Hub, conn *websocket.Conn, id string) *Client {
 	return &Client{
 		hub:       hub,
 		conn:      conn,
-		send:      make(chan []byte, 256),
+		send:      make(chan []byte, sendBufferSize),
+		done:      make(chan struct{}),
 		id:        id,
 		qaMatcher: nil, // Initially no Q&A matcher
+		fallback:  newFallbackResponder(FallbackConfig{Mode: FallbackModeEcho}),
+		chatSlots: make(chan struct{}, hub.maxConcurrentMessages),
 	}
 }
 
+// SetFallback configures how unmatched chat messages are answered and starts a new
+// conversation history. A nil config restores the default echo behavior.
+func (c *Client) SetFallback(config *FallbackConfig) {
+	if config == nil {
+		config = &FallbackConfig{Mode: FallbackModeEcho}
+	}
+	fallback := newFallbackResponder(*config)
+
+	c.configMu.Lock()
+	c.fallback = fallback
+	c.configMu.Unlock()
+}
+
+// fallbackResponder returns the responder set by SetFallback
+func (c *Client) fallbackResponder() *fallbackResponder {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.fallback
+}
+
+// SetUpgradeRequest records the HTTP request that opened the connection, so requests
+// made on the client's behalf (such as load_qa) can resolve the caller's tier. The request
+// is cloned without its context, which ends once the connection is upgraded.
+func (c *Client) SetUpgradeRequest(r *http.Request) {
+	c.request = r.Clone(context.Background())
+}
+
+// UpgradeRequest returns the request recorded by SetUpgradeRequest, or nil
+func (c *Client) UpgradeRequest() *http.Request {
+	return c.request
+}
+
+// ID returns the client's ID
+func (c *Client) ID() string {
+	return c.id
+}
+
 // SetQAMatcher sets the Q&A matcher for this client
 func (c *Client) SetQAMatcher(matcher qamatcher.QAMatcher) {
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
 	c.qaMatcher = matcher
 }
 
 // GetQAMatcher returns the Q&A matcher for this client
 func (c *Client) GetQAMatcher() qamatcher.QAMatcher {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
 	return c.qaMatcher
 }
 
+// QAState reports the client's Q&A matcher state and the outcome of its last lookup
+func (c *Client) QAState() QAState {
+	state := QAState{
+		ClientID:     c.id,
+		FallbackMode: string(c.fallbackResponder().config.Mode),
+	}
+
+	if matcher := c.GetQAMatcher(); matcher != nil {
+		state.QuestionCount = matcher.Count()
+		state.Loaded = state.QuestionCount > 0
+		state.Threshold = matcher.GetThreshold()
+	}
+
+	c.matchMu.Lock()
+	defer c.matchMu.Unlock()
+	if !c.lastQueryAt.IsZero() {
+		queriedAt := c.lastQueryAt
+		similarity := c.lastSimilarity
+		state.LastQueryAt = &queriedAt
+		state.LastSimilarity = &similarity
+		state.LastMatched = c.lastMatched
+	}
+
+	return state
+}
+
+// recordMatch stores the outcome of a Q&A lookup for QAState
+func (c *Client) recordMatch(result *qamatcher.MatchResult) {
+	c.matchMu.Lock()
+	defer c.matchMu.Unlock()
+	c.lastQueryAt = time.Now()
+	c.lastSimilarity = result.Similarity
+	c.lastMatched = result.Found
+}
+
 // readPump pumps messages from the WebSocket connection to the hub
 func (c *Client) readPump() {
 	defer func() {
+		// Replies still being computed are queued before the client is unregistered
+		c.waitForReplies()
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
@@ -83,92 +206,289 @@ func (c *Client) readPump() {
 		var msg models.Message
 		if err := json.Unmarshal(messageBytes, &msg); err != nil {
 			log.Printf("Error parsing message: %v", err)
+			c.sendError("Invalid message format")
 			continue
 		}
 
-		log.Printf("Received message from client %s: %s", c.id, msg.Content)
-
-		// Try to find a Q&A match first if matcher is loaded
-		var response models.Message
-		if c.qaMatcher != nil && c.qaMatcher.Count() > 0 {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			matchResult, err := c.qaMatcher.FindMatch(ctx, msg.Content)
-			cancel()
-
-			if err != nil {
-				log.Printf("Error finding Q&A match for client %s: %v", c.id, err)
-			} else if matchResult.Found {
-				// Found a matching Q&A pair
-				log.Printf("Q&A match found for client %s (similarity: %.2f): %s", c.id, matchResult.Similarity, matchResult.Question)
-				response = models.Message{
-					Type:       models.MessageTypeMessage,
-					Content:    matchResult.Answer,
-					Timestamp:  time.Now(),
-					Sender:     "assistant",
-					Metadata: map[string]interface{}{
-						"from_qa":    true,
-						"question":   matchResult.Question,
-						"similarity": matchResult.Similarity,
-					},
+		c.dispatch(&msg)
+	}
+}
+
+// messageHandlers maps inbound message types to their handlers.
+// An empty type is treated as a chat message for older clients.
+var messageHandlers = map[string]func(*Client, *models.Message){
+	"":                        (*Client).dispatchChat,
+	models.MessageTypeChat:    (*Client).dispatchChat,
+	models.MessageTypeMessage: (*Client).dispatchChat,
+	models.MessageTypeTyping:  (*Client).handleTyping,
+	models.MessageTypeResume:  (*Client).handleResume,
+	models.MessageTypePing:    (*Client).handlePing,
+	models.MessageTypeLoadQA:  (*Client).handleLoadQA,
+}
+
+// concurrentMessageTypes are the message types answered concurrently by dispatchChat.
+// Other messages are handled once every chat reply in flight has been sent.
+var concurrentMessageTypes = map[string]bool{
+	"":                        true,
+	models.MessageTypeChat:    true,
+	models.MessageTypeMessage: true,
+}
+
+// dispatch routes an inbound message to the handler for its type,
+// replying with an error message for unknown types
+func (c *Client) dispatch(msg *models.Message) {
+	handler, ok := messageHandlers[msg.Type]
+	if !concurrentMessageTypes[msg.Type] {
+		// Keeps replies in order, and load_qa from swapping the matcher under a lookup
+		c.waitForReplies()
+	}
+	if !ok {
+		log.Printf("Unknown message type %q from client %s", msg.Type, c.id)
+		c.sendError("Unsupported message type: " + msg.Type)
+		return
+	}
+	handler(c, msg)
+}
+
+// dispatchChat answers a chat message in the background, so a slow Q&A lookup or LLM
+// fallback doesn't hold up the client's next messages. At most MaxConcurrentMessages
+// are answered at once; beyond that, reading waits for a free slot. Replies are sent in
+// the order the messages arrived. Chunks of a streamed LLM reply are sent as they come
+// once the previous reply is out; until then, and while the send buffer is half full,
+// they are held back and sent together with the next chunk. Chunks still held back when
+// the generation ends are only sent as part of the complete reply, and the generation
+// stops if the client disconnects.
+func (c *Client) dispatchChat(msg *models.Message) {
+	c.chatSlots <- struct{}{}
+
+	previous := c.lastReply
+	sent := make(chan struct{})
+	c.lastReply = sent
+
+	go func() {
+		defer func() { <-c.chatSlots }()
+		defer close(sent)
+
+		var held strings.Builder
+		stream := func(chunk string) error {
+			select {
+			case <-c.done:
+				return errClientGone
+			default:
+			}
+
+			held.WriteString(chunk)
+			if previous != nil {
+				select {
+				case <-previous:
+					previous = nil
+				default:
+					return nil
 				}
 			}
+			// Keeps room for broadcasts and the complete reply
+			if len(c.send) >= cap(c.send)/2 {
+				return nil
+			}
+
+			c.sendMessage(models.Message{
+				Type:      models.MessageTypeChunk,
+				SessionID: msg.SessionID,
+				Content:   held.String(),
+				Timestamp: time.Now(),
+				Sender:    "assistant",
+			})
+			held.Reset()
+			return nil
+		}
+
+		// The reply is remembered by the responder that gave it, even if load_qa
+		// replaced it meanwhile
+		fallback := c.fallbackResponder()
+		response := c.chatReply(msg, fallback, stream)
+		if previous != nil {
+			<-previous
 		}
+		fallback.remember(msg.Content, response.Content)
+		c.sendMessage(response)
+	}()
+}
 
-		// If no Q&A match, use default response
-		if response.Content == "" {
+// waitForReplies waits until the replies to every chat message dispatched so far
+// have been sent. Each reply waits for the previous one, so the latest suffices.
+func (c *Client) waitForReplies() {
+	if c.lastReply != nil {
+		<-c.lastReply
+	}
+}
+
+// chatReply answers a chat query, using the Q&A matcher when one is loaded and fallback
+// otherwise. onChunk receives the pieces of a streamed LLM fallback answer.
+func (c *Client) chatReply(msg *models.Message, fallback *fallbackResponder, onChunk func(chunk string) error) models.Message {
+	log.Printf("Received message from client %s: %s", c.id, msg.Content)
+
+	// Try to find a Q&A match first if matcher is loaded
+	var response models.Message
+	if matcher := c.GetQAMatcher(); matcher != nil && matcher.Count() > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		matchResult, err := matcher.FindMatch(ctx, msg.Content)
+		cancel()
+
+		if err != nil {
+			log.Printf("Error finding Q&A match for client %s: %v", c.id, err)
+		} else {
+			c.recordMatch(matchResult)
+		}
+
+		if err == nil && matchResult.Found {
+			// Found a matching Q&A pair
+			log.Printf("Q&A match found for client %s (similarity: %.2f): %s", c.id, matchResult.Similarity, matchResult.Question)
 			response = models.Message{
 				Type:      models.MessageTypeMessage,
-				Content:   "Server received: " + msg.Content,
+				Content:   matchResult.Answer,
 				Timestamp: time.Now(),
 				Sender:    "assistant",
+				Metadata: map[string]interface{}{
+					"from_qa":    true,
+					"question":   matchResult.Question,
+					"similarity": matchResult.Similarity,
+				},
 			}
 		}
+	}
 
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			log.Printf("Error marshaling response: %v", err)
-			continue
+	// If no Q&A match, use the configured fallback response
+	if response.Content == "" {
+		content, metadata := fallback.respond(msg.Content, onChunk)
+		response = models.Message{
+			Type:      models.MessageTypeMessage,
+			Content:   content,
+			Timestamp: time.Now(),
+			Sender:    "assistant",
+			Metadata:  metadata,
 		}
+	}
 
-		// Send the response to this client
-		c.send <- responseBytes
+	response.SessionID = msg.SessionID
+	return response
+}
+
+// handleTyping handles typing indicators. There is no other participant to notify,
+// so the indicator only refreshes the client's session ID.
+func (c *Client) handleTyping(msg *models.Message) {
+	if msg.SessionID != "" {
+		c.sessionID = msg.SessionID
 	}
 }
 
+// handleResume re-associates a reconnected client with its previous session
+func (c *Client) handleResume(msg *models.Message) {
+	if msg.SessionID == "" {
+		c.sendError("Session ID is required to resume")
+		return
+	}
+
+	c.sessionID = msg.SessionID
+	log.Printf("Client %s resumed session %s", c.id, msg.SessionID)
+
+	qaLoaded := 0
+	if matcher := c.GetQAMatcher(); matcher != nil {
+		qaLoaded = matcher.Count()
+	}
+
+	c.sendMessage(models.Message{
+		Type:      models.MessageTypeSystem,
+		SessionID: msg.SessionID,
+		Content:   "Session resumed",
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"client_id": c.id,
+			"qa_loaded": qaLoaded,
+		},
+	})
+}
+
+// handlePing answers an application-level ping with a pong
+func (c *Client) handlePing(msg *models.Message) {
+	c.sendMessage(models.Message{
+		Type:      models.MessageTypePong,
+		SessionID: msg.SessionID,
+		Content:   msg.Content,
+		Timestamp: time.Now(),
+	})
+}
+
+// sendError sends an error message to this client
+func (c *Client) sendError(content string) {
+	c.sendMessage(models.Message{
+		Type:      models.MessageTypeError,
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+}
+
+// sendMessage marshals a message and queues it for this client
+func (c *Client) sendMessage(msg models.Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling response: %v", err)
+		return
+	}
+
+	// Send the response to this client
+	c.queue(data)
+}
+
+// queue adds data to the messages writePump sends, waiting for room in send. Once the
+// client is disconnected, data is dropped instead.
+func (c *Client) queue(data []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- data:
+	case <-c.done:
+	}
+}
+
+// disconnect stops the client: writePump sends what is already queued and closes the
+// connection, and replies still being computed are dropped instead of queued. The send
+// channel is never closed, so a reply racing the disconnect can't panic. Safe to call
+// more than once.
+func (c *Client) disconnect() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
 // writePump pumps messages from the hub to the WebSocket connection
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
+		// Replies waiting for room in send would otherwise wait forever
+		c.disconnect()
 		c.conn.Close()
 	}()
 
 	for {
 		select {
-		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+		case message := <-c.send:
+			if err := c.writeMessages(message); err != nil {
 				return
 			}
-			w.Write(message)
-
-			// Add queued messages to the current WebSocket message
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
-			}
 
-			if err := w.Close(); err != nil {
-				return
+		case <-c.done:
+			// The client was disconnected: flush what is queued, then close
+			if len(c.send) > 0 {
+				if err := c.writeMessages(<-c.send); err != nil {
+					return
+				}
 			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -179,13 +499,34 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeMessages writes message, along with the messages queued behind it, as a single
+// WebSocket message
+func (c *Client) writeMessages(message []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	w, err := c.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(message)
+
+	// Add queued messages to the current WebSocket message
+	n := len(c.send)
+	for i := 0; i < n; i++ {
+		w.Write([]byte{'\n'})
+		w.Write(<-c.send)
+	}
+
+	return w.Close()
+}
+
 // Run starts the client's read and write pumps
 func (c *Client) Run() {
 	go c.writePump()
 	go c.readPump()
 }
 
-// Send sends a message to the client's send channel
+// Send sends a message to the client's send channel. It is dropped once the client
+// is disconnected.
 func (c *Client) Send(message []byte) {
-	c.send <- message
+	c.queue(message)
 }
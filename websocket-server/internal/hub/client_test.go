@@ -0,0 +1,248 @@
+package hub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/qamatcher"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestClientDispatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         models.Message
+		wantType    string // Type of the reply, "" for none
+		wantContent string
+	}{
+		{"chat", models.Message{Type: models.MessageTypeChat, Content: "hello"}, models.MessageTypeMessage, "Server received: hello"},
+		{"message alias", models.Message{Type: models.MessageTypeMessage, Content: "hello"}, models.MessageTypeMessage, "Server received: hello"},
+		{"untyped is chat", models.Message{Content: "hello"}, models.MessageTypeMessage, "Server received: hello"},
+		{"typing", models.Message{Type: models.MessageTypeTyping, SessionID: "s1"}, "", ""},
+		{"ping", models.Message{Type: models.MessageTypePing, Content: "42"}, models.MessageTypePong, "42"},
+		{"resume", models.Message{Type: models.MessageTypeResume, SessionID: "s1"}, models.MessageTypeSystem, "Session resumed"},
+		{"resume without session", models.Message{Type: models.MessageTypeResume}, models.MessageTypeError, "Session ID is required to resume"},
+		{"unknown type", models.Message{Type: "dance", Content: "hello"}, models.MessageTypeError, "Unsupported message type: dance"},
+		{"pong is not inbound", models.Message{Type: models.MessageTypePong}, models.MessageTypeError, "Unsupported message type: pong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.dispatch(&tt.msg)
+
+			if tt.wantType == "" {
+				expectNoMessage(t, c)
+				return
+			}
+
+			reply := receive(t, c)
+			if reply.Type != tt.wantType || reply.Content != tt.wantContent {
+				t.Errorf("reply = %s %q, want %s %q", reply.Type, reply.Content, tt.wantType, tt.wantContent)
+			}
+			if reply.SessionID != tt.msg.SessionID {
+				t.Errorf("reply session = %q, want %q", reply.SessionID, tt.msg.SessionID)
+			}
+			expectNoMessage(t, c)
+		})
+	}
+}
+
+func TestClientDispatchSessionUpdates(t *testing.T) {
+	c := newTestClient(nil)
+
+	c.dispatch(&models.Message{Type: models.MessageTypeTyping, SessionID: "s1"})
+	if c.sessionID != "s1" {
+		t.Errorf("session after typing = %q, want s1", c.sessionID)
+	}
+
+	c.dispatch(&models.Message{Type: models.MessageTypeResume, SessionID: "s2"})
+	reply := receive(t, c)
+	if c.sessionID != "s2" {
+		t.Errorf("session after resume = %q, want s2", c.sessionID)
+	}
+	if reply.Metadata["client_id"] != "client_1" || reply.Metadata["qa_loaded"] != float64(0) {
+		t.Errorf("resume metadata = %v", reply.Metadata)
+	}
+}
+
+func TestClientQAState(t *testing.T) {
+	c := newTestClient(nil)
+
+	state := c.QAState()
+	if state.ClientID != "client_1" || state.Loaded || state.QuestionCount != 0 || state.FallbackMode != "echo" {
+		t.Errorf("unloaded state = %+v", state)
+	}
+	if state.LastQueryAt != nil || state.LastSimilarity != nil {
+		t.Errorf("unloaded state reports a lookup: %+v", state)
+	}
+
+	matcher := &stubMatcher{count: 3, threshold: 0.8, result: qamatcher.MatchResult{Question: "Why Go?", Similarity: 0.62}}
+	c.SetQAMatcher(matcher)
+
+	c.dispatch(chatMessage("Why Golang?"))
+	if reply := receive(t, c); reply.Content != "Server received: Why Golang?" {
+		t.Errorf("reply = %q, want the fallback", reply.Content)
+	}
+
+	state = c.QAState()
+	if !state.Loaded || state.QuestionCount != 3 || state.Threshold != 0.8 {
+		t.Errorf("loaded state = %+v", state)
+	}
+	if state.LastQueryAt == nil || state.LastSimilarity == nil || *state.LastSimilarity != 0.62 || state.LastMatched {
+		t.Errorf("state after a miss = %+v", state)
+	}
+
+	matcher.result = qamatcher.MatchResult{Question: "Why Go?", Answer: "Simplicity.", Similarity: 0.91, Found: true}
+	c.dispatch(chatMessage("Why Go?"))
+	if reply := receive(t, c); reply.Content != "Simplicity." {
+		t.Errorf("reply = %q, want the matched answer", reply.Content)
+	}
+
+	state = c.QAState()
+	if state.LastSimilarity == nil || *state.LastSimilarity != 0.91 || !state.LastMatched {
+		t.Errorf("state after a match = %+v", state)
+	}
+}
+
+// gateMatcher holds every lookup until a value is sent on release, tracking how many
+// lookups run at once. No lookup finds a match. Methods a test needs but the stub
+// doesn't implement panic through the embedded nil interface.
+type gateMatcher struct {
+	qamatcher.QAMatcher
+
+	started chan string // Receives each query as its lookup starts
+	release chan struct{}
+
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+}
+
+func newGateMatcher() *gateMatcher {
+	return &gateMatcher{started: make(chan string, 8), release: make(chan struct{})}
+}
+
+func (m *gateMatcher) FindMatch(ctx context.Context, query string) (*qamatcher.MatchResult, error) {
+	m.mu.Lock()
+	m.running++
+	m.maxRunning = max(m.maxRunning, m.running)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.running--
+		m.mu.Unlock()
+	}()
+
+	m.started <- query
+	select {
+	case <-m.release:
+		return &qamatcher.MatchResult{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (m *gateMatcher) Count() int { return 1 }
+
+func (m *gateMatcher) GetThreshold() float64 { return 0.8 }
+
+// waitStarted returns the query of the next lookup, failing the test if none starts
+func (m *gateMatcher) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case query := <-m.started:
+		return query
+	case <-time.After(5 * time.Second):
+		t.Fatal("no lookup started")
+		return ""
+	}
+}
+
+func TestDispatchChatConcurrency(t *testing.T) {
+	c := newTestClient(&HubConfig{MaxConcurrentMessages: 2})
+	matcher := newGateMatcher()
+	c.SetQAMatcher(matcher)
+
+	c.dispatch(chatMessage("one"))
+	c.dispatch(chatMessage("two"))
+	matcher.waitStarted(t)
+	matcher.waitStarted(t)
+
+	// A third message waits for a free slot before it is even looked up
+	dispatched := make(chan struct{})
+	go func() {
+		c.dispatch(chatMessage("three"))
+		close(dispatched)
+	}()
+	select {
+	case query := <-matcher.started:
+		t.Fatalf("lookup of %q started with 2 messages in flight", query)
+	case <-dispatched:
+		t.Fatal("third message dispatched with 2 messages in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	matcher.release <- struct{}{}
+	matcher.release <- struct{}{}
+	<-dispatched
+	if query := matcher.waitStarted(t); query != "three" {
+		t.Errorf("started lookup of %q, want three", query)
+	}
+	matcher.release <- struct{}{}
+
+	// Replies come in the order the messages arrived
+	for _, want := range []string{"one", "two", "three"} {
+		if reply := receive(t, c); reply.Content != "Server received: "+want {
+			t.Errorf("reply = %q, want the echo of %q", reply.Content, want)
+		}
+	}
+	expectNoMessage(t, c)
+
+	matcher.mu.Lock()
+	defer matcher.mu.Unlock()
+	if matcher.maxRunning != 2 {
+		t.Errorf("at most %d lookups ran at once, want 2", matcher.maxRunning)
+	}
+}
+
+func TestDisconnectMidReply(t *testing.T) {
+	h := NewHub(nil)
+	c := addClient(h, "client_1", 1)
+	go h.Run()
+
+	matcher := newGateMatcher()
+	c.SetQAMatcher(matcher)
+	c.dispatch(chatMessage("hello"))
+	matcher.waitStarted(t)
+
+	// The reply finds the send buffer full, and the client goes away before it drains
+	c.send <- []byte("queued")
+	h.Unregister(c)
+	matcher.release <- struct{}{}
+
+	replied := make(chan struct{})
+	go func() {
+		c.waitForReplies()
+		close(replied)
+	}()
+	select {
+	case <-replied:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reply still waiting for room in send after the disconnect")
+	}
+
+	if registered(h, c) {
+		t.Error("client still registered after unregistering")
+	}
+	if got := string(<-c.send); got != "queued" {
+		t.Errorf("queued message = %q, want the one queued before the disconnect", got)
+	}
+
+	// Messages sent after the disconnect are dropped
+	c.Send([]byte("late"))
+	c.sendError("late")
+	expectNoMessage(t, c)
+}
@@ -0,0 +1,70 @@
+package hub
+
+import "sync"
+
+const (
+	defaultHistoryTurns  = 5
+	maxHistoryTurns      = 20
+	defaultHistoryTokens = 1000
+)
+
+// conversationTurn is one exchange between the user and the assistant
+type conversationTurn struct {
+	Query string
+	Reply string
+}
+
+// conversationBuffer keeps the most recent turns of a client's conversation so the
+// LLM fallback can answer follow-ups such as "tell me more"
+type conversationBuffer struct {
+	mu        sync.Mutex
+	maxTurns  int
+	maxTokens int
+	turns     []conversationTurn
+}
+
+// newConversationBuffer creates a buffer holding at most maxTurns turns, of which
+// at most maxTokens (estimated) are returned by recent
+func newConversationBuffer(maxTurns, maxTokens int) *conversationBuffer {
+	return &conversationBuffer{
+		maxTurns:  maxTurns,
+		maxTokens: maxTokens,
+		turns:     make([]conversationTurn, 0, maxTurns),
+	}
+}
+
+// add records a turn, dropping the oldest one when the buffer is full
+func (b *conversationBuffer) add(query, reply string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.turns) == b.maxTurns {
+		copy(b.turns, b.turns[1:])
+		b.turns = b.turns[:len(b.turns)-1]
+	}
+	b.turns = append(b.turns, conversationTurn{Query: query, Reply: reply})
+}
+
+// recent returns the newest turns that fit in the token budget, oldest first
+func (b *conversationBuffer) recent() []conversationTurn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	budget := b.maxTokens
+	start := len(b.turns)
+	for start > 0 {
+		cost := estimateTokens(b.turns[start-1].Query) + estimateTokens(b.turns[start-1].Reply)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		start--
+	}
+
+	return append([]conversationTurn(nil), b.turns[start:]...)
+}
+
+// estimateTokens approximates the token count of text (about 4 characters per token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
@@ -0,0 +1,115 @@
+package hub
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConversationBufferKeepsRecentTurns(t *testing.T) {
+	buffer := newConversationBuffer(2, 1000)
+	buffer.add("q1", "r1")
+	buffer.add("q2", "r2")
+	buffer.add("q3", "r3")
+
+	want := []conversationTurn{{"q2", "r2"}, {"q3", "r3"}}
+	if got := buffer.recent(); !reflect.DeepEqual(got, want) {
+		t.Errorf("recent = %v, want %v", got, want)
+	}
+}
+
+func TestConversationBufferTokenBudget(t *testing.T) {
+	// Each turn costs 10 tokens: 20 characters of query and 20 of reply
+	turn := strings.Repeat("x", 20)
+
+	buffer := newConversationBuffer(5, 25)
+	buffer.add("old "+turn[4:], turn)
+	buffer.add("mid "+turn[4:], turn)
+	buffer.add("new "+turn[4:], turn)
+
+	got := buffer.recent()
+	if len(got) != 2 || !strings.HasPrefix(got[0].Query, "mid") || !strings.HasPrefix(got[1].Query, "new") {
+		t.Errorf("recent = %v, want the two newest turns", got)
+	}
+
+	// A turn over the whole budget leaves nothing to include
+	buffer.add("huge", strings.Repeat("x", 200))
+	if got := buffer.recent(); len(got) != 0 {
+		t.Errorf("recent = %v, want none", got)
+	}
+}
+
+func TestFallbackHistoryConfig(t *testing.T) {
+	llm := &stubLLM{response: "An answer."}
+
+	tests := []struct {
+		name      string
+		config    FallbackConfig
+		wantTurns int // Buffer size, 0 for no buffer
+	}{
+		{"default", FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm}, defaultHistoryTurns},
+		{"configured", FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, HistoryTurns: 3}, 3},
+		{"capped", FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, HistoryTurns: 100}, maxHistoryTurns},
+		{"disabled", FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, HistoryTurns: -1}, 0},
+		{"not in canned mode", FallbackConfig{Mode: FallbackModeCanned}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := newFallbackResponder(tt.config).history
+			if tt.wantTurns == 0 {
+				if history != nil {
+					t.Errorf("history kept with %d turns, want none", history.maxTurns)
+				}
+				return
+			}
+			if history == nil || history.maxTurns != tt.wantTurns {
+				t.Errorf("history = %+v, want %d turns", history, tt.wantTurns)
+			}
+		})
+	}
+}
+
+func TestFollowUpPromptIncludesPriorTurns(t *testing.T) {
+	llm := &stubLLM{response: "I chose Go for its simple concurrency."}
+	c := newTestClient(nil)
+	c.SetFallback(&FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm})
+
+	c.dispatch(chatMessage("Why Go?"))
+	receive(t, c)
+	c.dispatch(chatMessage("Tell me more"))
+	receive(t, c)
+
+	if llm.calls() != 2 {
+		t.Fatalf("LLM called %d times, want 2", llm.calls())
+	}
+	if strings.Contains(llm.prompts[0], "Conversation so far") {
+		t.Errorf("first prompt has history:\n%s", llm.prompts[0])
+	}
+
+	followUp := llm.prompts[1]
+	for _, want := range []string{
+		"Interviewer: Why Go?",
+		"Candidate: I chose Go for its simple concurrency.",
+		"Question: Tell me more",
+	} {
+		if !strings.Contains(followUp, want) {
+			t.Errorf("follow-up prompt lacks %q:\n%s", want, followUp)
+		}
+	}
+}
+
+func TestFollowUpWithoutHistory(t *testing.T) {
+	llm := &stubLLM{response: "An answer."}
+	c := newTestClient(nil)
+	c.SetFallback(&FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, HistoryTurns: -1})
+
+	c.dispatch(chatMessage("Why Go?"))
+	receive(t, c)
+	c.dispatch(chatMessage("Tell me more"))
+	receive(t, c)
+
+	if strings.Contains(llm.prompts[1], "Why Go?") {
+		t.Errorf("prompt includes history with it disabled:\n%s", llm.prompts[1])
+	}
+}
@@ -0,0 +1,244 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/prompts"
+)
+
+// FallbackMode selects how a client answers chat messages that have no Q&A match
+type FallbackMode string
+
+const (
+	FallbackModeEcho   FallbackMode = "echo"   // Echo the message back (default)
+	FallbackModeCanned FallbackMode = "canned" // Reply with a fixed message
+	FallbackModeLLM    FallbackMode = "llm"    // Generate an answer with the LLM
+)
+
+const (
+	// DefaultCannedResponse is used when canned mode is selected without a message,
+	// and when the LLM fallback is unavailable
+	DefaultCannedResponse = "I don't have a prepared answer for that yet. Try rephrasing your question."
+
+	defaultLLMFallbackTimeout = 20 * time.Second
+	defaultLLMCallsPerMinute  = 5
+)
+
+// FallbackConfig configures the response used when no Q&A pair matches
+type FallbackConfig struct {
+	Mode              FallbackMode
+	CannedResponse    string             // Used in canned mode and when the LLM fallback fails
+	LLMClient         analyzer.LLMClient // Required in LLM mode
+	Context           string             // Profile/job context included in LLM prompts
+	Timeout           time.Duration      // Per-call LLM timeout (default 20s)
+	MaxCallsPerMinute int                // LLM calls allowed per client per minute (default 5)
+	HistoryTurns      int                // Recent turns included in LLM prompts (default 5, max 20, negative disables)
+	HistoryTokens     int                // Approximate token budget for those turns (default 1000)
+	Stream            bool               // Stream LLM answers as message_chunk messages, if the LLM client supports it
+}
+
+// ParseFallbackMode validates a fallback mode name. An empty name selects echo mode.
+func ParseFallbackMode(mode string) (FallbackMode, error) {
+	switch FallbackMode(strings.ToLower(strings.TrimSpace(mode))) {
+	case "", FallbackModeEcho:
+		return FallbackModeEcho, nil
+	case FallbackModeCanned:
+		return FallbackModeCanned, nil
+	case FallbackModeLLM:
+		return FallbackModeLLM, nil
+	default:
+		return "", fmt.Errorf("unsupported fallback mode %q (use echo, canned or llm)", mode)
+	}
+}
+
+// fallbackResponder produces replies for unmatched chat messages
+type fallbackResponder struct {
+	config  FallbackConfig
+	limiter *rateLimiter
+	history *conversationBuffer // nil unless LLM mode with history enabled
+}
+
+// newFallbackResponder creates a responder, filling in defaults for unset options
+func newFallbackResponder(config FallbackConfig) *fallbackResponder {
+	if config.Mode == "" {
+		config.Mode = FallbackModeEcho
+	}
+	if config.CannedResponse == "" {
+		config.CannedResponse = DefaultCannedResponse
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultLLMFallbackTimeout
+	}
+	if config.MaxCallsPerMinute <= 0 {
+		config.MaxCallsPerMinute = defaultLLMCallsPerMinute
+	}
+
+	responder := &fallbackResponder{
+		config:  config,
+		limiter: newRateLimiter(config.MaxCallsPerMinute, time.Minute),
+	}
+
+	if config.Mode == FallbackModeLLM && config.HistoryTurns >= 0 {
+		turns := config.HistoryTurns
+		if turns == 0 {
+			turns = defaultHistoryTurns
+		}
+		if turns > maxHistoryTurns {
+			turns = maxHistoryTurns
+		}
+		tokens := config.HistoryTokens
+		if tokens <= 0 {
+			tokens = defaultHistoryTokens
+		}
+		responder.history = newConversationBuffer(turns, tokens)
+	}
+
+	return responder
+}
+
+// remember records a completed exchange so later LLM prompts can refer back to it
+func (f *fallbackResponder) remember(query, reply string) {
+	if f.history != nil {
+		f.history.add(query, reply)
+	}
+}
+
+// respond returns the reply content and metadata for an unmatched message. onChunk
+// receives the pieces of a streamed LLM answer, see respondLLM; it may be nil.
+func (f *fallbackResponder) respond(query string, onChunk func(chunk string) error) (string, map[string]interface{}) {
+	switch f.config.Mode {
+	case FallbackModeCanned:
+		return f.config.CannedResponse, map[string]interface{}{"fallback": string(FallbackModeCanned)}
+
+	case FallbackModeLLM:
+		return f.respondLLM(query, onChunk)
+
+	default:
+		return "Server received: " + query, nil
+	}
+}
+
+// respondLLM generates an answer with the LLM, using the canned response when the LLM is
+// unavailable, rate limited or fails. With streaming enabled, onChunk receives the answer
+// while it is generated; the returned reply is then marked "streamed" and "done" and
+// supersedes the streamed text, even when it is the canned response after a failure.
+// An error from onChunk stops the generation, which then counts as failed.
+func (f *fallbackResponder) respondLLM(query string, onChunk func(chunk string) error) (string, map[string]interface{}) {
+	if f.config.LLMClient == nil {
+		return f.config.CannedResponse, map[string]interface{}{"fallback": string(FallbackModeCanned)}
+	}
+	if !f.limiter.allow() {
+		log.Printf("LLM fallback rate limit reached, using canned response")
+		return f.config.CannedResponse, map[string]interface{}{
+			"fallback":     string(FallbackModeCanned),
+			"rate_limited": true,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.config.Timeout)
+	defer cancel()
+
+	var history []conversationTurn
+	if f.history != nil {
+		history = f.history.recent()
+	}
+
+	prompt, err := buildFallbackPrompt(f.config.Context, history, query)
+	if err != nil {
+		log.Printf("Failed to build LLM fallback prompt, using canned response: %v", err)
+		return f.config.CannedResponse, map[string]interface{}{"fallback": string(FallbackModeCanned)}
+	}
+
+	answer, streamed, err := f.generate(ctx, prompt, onChunk)
+
+	content, metadata := strings.TrimSpace(answer), map[string]interface{}{"fallback": string(FallbackModeLLM)}
+	if err != nil || content == "" {
+		log.Printf("LLM fallback failed, using canned response: %v", err)
+		content, metadata = f.config.CannedResponse, map[string]interface{}{"fallback": string(FallbackModeCanned)}
+	}
+	if streamed {
+		metadata["streamed"] = true
+		metadata["done"] = true
+	}
+	return content, metadata
+}
+
+// generate asks the LLM for an answer, streaming it to onChunk when streaming is enabled
+// and the LLM client supports it. It reports whether any chunk was streamed.
+func (f *fallbackResponder) generate(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, bool, error) {
+	streamer, ok := f.config.LLMClient.(analyzer.StreamingLLMClient)
+	if !f.config.Stream || onChunk == nil || !ok {
+		answer, err := f.config.LLMClient.GenerateFromPrompt(ctx, prompt)
+		return answer, false, err
+	}
+
+	streamed := false
+	answer, err := streamer.GenerateFromPromptStream(ctx, prompt, func(chunk string) error {
+		if chunk != "" {
+			streamed = true
+			if err := onChunk(chunk); err != nil {
+				return err
+			}
+		}
+		// Stops the generation once the timeout has passed
+		return ctx.Err()
+	})
+	return answer, streamed, err
+}
+
+// buildFallbackPrompt renders the LLM prompt for answering an unmatched question,
+// including recent conversation turns so follow-up questions can be understood
+func buildFallbackPrompt(profileContext string, history []conversationTurn, query string) (string, error) {
+	data := prompts.ChatFallbackData{Context: profileContext, Query: query}
+	for _, turn := range history {
+		data.History = append(data.History, prompts.ChatTurn{Query: turn.Query, Reply: turn.Reply})
+	}
+	return prompts.Render(prompts.ChatFallback, data)
+}
+
+// rateLimiter allows at most max events per sliding window
+type rateLimiter struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	events []time.Time
+}
+
+// newRateLimiter creates a sliding-window rate limiter
+func newRateLimiter(max int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		max:    max,
+		window: window,
+		events: make([]time.Time, 0, max),
+	}
+}
+
+// allow records an event and reports whether it is within the limit
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	// Drop events that fell out of the window
+	kept := l.events[:0]
+	for _, t := range l.events {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.events = kept
+
+	if len(l.events) >= l.max {
+		return false
+	}
+	l.events = append(l.events, now)
+	return true
+}
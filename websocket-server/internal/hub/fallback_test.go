@@ -0,0 +1,271 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestParseFallbackMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    FallbackMode
+		wantErr bool
+	}{
+		{"", FallbackModeEcho, false},
+		{"echo", FallbackModeEcho, false},
+		{" Canned ", FallbackModeCanned, false},
+		{"LLM", FallbackModeLLM, false},
+		{"magic", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFallbackMode(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseFallbackMode(%q) = %q, %v, want %q (error %t)", tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFallbackRespond(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       FallbackConfig
+		wantContent  string
+		wantFallback string // "fallback" metadata, "" for none
+		wantCalls    int    // LLM calls made
+	}{
+		{"echo by default", FallbackConfig{}, "Server received: Why Go?", "", 0},
+		{"echo", FallbackConfig{Mode: FallbackModeEcho}, "Server received: Why Go?", "", 0},
+		{"canned", FallbackConfig{Mode: FallbackModeCanned, CannedResponse: "Ask me later."}, "Ask me later.", "canned", 0},
+		{"canned default message", FallbackConfig{Mode: FallbackModeCanned}, DefaultCannedResponse, "canned", 0},
+		{"llm", FallbackConfig{Mode: FallbackModeLLM, LLMClient: &stubLLM{response: "  Because it is simple.\n"}}, "Because it is simple.", "llm", 1},
+		{"llm without client", FallbackConfig{Mode: FallbackModeLLM, CannedResponse: "Ask me later."}, "Ask me later.", "canned", 0},
+		{"llm fails", FallbackConfig{Mode: FallbackModeLLM, LLMClient: &stubLLM{err: errors.New("down")}}, DefaultCannedResponse, "canned", 1},
+		{"llm answers nothing", FallbackConfig{Mode: FallbackModeLLM, LLMClient: &stubLLM{response: "   "}}, DefaultCannedResponse, "canned", 1},
+		{"llm times out", FallbackConfig{Mode: FallbackModeLLM, LLMClient: &stubLLM{block: true}, Timeout: 10 * time.Millisecond}, DefaultCannedResponse, "canned", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, metadata := newFallbackResponder(tt.config).respond("Why Go?", nil)
+			if content != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+
+			gotFallback, _ := metadata["fallback"].(string)
+			if gotFallback != tt.wantFallback {
+				t.Errorf("fallback metadata = %q, want %q", gotFallback, tt.wantFallback)
+			}
+
+			if llm, ok := tt.config.LLMClient.(*stubLLM); ok && llm.calls() != tt.wantCalls {
+				t.Errorf("LLM called %d times, want %d", llm.calls(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFallbackLLMPrompt(t *testing.T) {
+	llm := &stubLLM{response: "Because it is simple."}
+	responder := newFallbackResponder(FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, Context: "Backend engineer at Acme"})
+
+	responder.respond("Why Go?", nil)
+	if llm.calls() != 1 {
+		t.Fatalf("LLM called %d times, want 1", llm.calls())
+	}
+	for _, want := range []string{"Backend engineer at Acme", "Question: Why Go?"} {
+		if !strings.Contains(llm.prompts[0], want) {
+			t.Errorf("prompt lacks %q:\n%s", want, llm.prompts[0])
+		}
+	}
+}
+
+func TestFallbackLLMRateLimit(t *testing.T) {
+	llm := &stubLLM{response: "An answer."}
+	responder := newFallbackResponder(FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, MaxCallsPerMinute: 2})
+
+	for i := 0; i < 2; i++ {
+		if content, _ := responder.respond("Why Go?", nil); content != "An answer." {
+			t.Fatalf("call %d = %q, want the LLM answer", i+1, content)
+		}
+	}
+
+	content, metadata := responder.respond("Why Go?", nil)
+	if content != DefaultCannedResponse || metadata["rate_limited"] != true {
+		t.Errorf("rate limited call = %q %v, want the canned response", content, metadata)
+	}
+	if llm.calls() != 2 {
+		t.Errorf("LLM called %d times, want 2", llm.calls())
+	}
+}
+
+func TestClientFallbackMode(t *testing.T) {
+	c := newTestClient(nil)
+	c.SetFallback(&FallbackConfig{Mode: FallbackModeCanned, CannedResponse: "Ask me later."})
+
+	c.dispatch(chatMessage("Why Go?"))
+	if reply := receive(t, c); reply.Content != "Ask me later." {
+		t.Errorf("reply = %q, want the canned response", reply.Content)
+	}
+
+	// A nil config restores echo mode
+	c.SetFallback(nil)
+	c.dispatch(chatMessage("Why Go?"))
+	if reply := receive(t, c); reply.Content != "Server received: Why Go?" {
+		t.Errorf("reply = %q, want the echo", reply.Content)
+	}
+}
+
+// streamLLM answers with chunks, streaming them as the pieces of its answer. With pauseAt
+// set, it reports on paused before streaming chunk pauseAt and waits for a value on
+// resume. Methods a test needs but the stub doesn't implement panic through the embedded
+// nil interface.
+type streamLLM struct {
+	analyzer.LLMClient
+
+	chunks  []string
+	pauseAt int
+	paused  chan struct{}
+	resume  chan struct{}
+
+	stopErr error // Error from onChunk that stopped the stream
+}
+
+func (l *streamLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(l.chunks, ""), nil
+}
+
+func (l *streamLLM) GenerateFromPromptStream(ctx context.Context, prompt string, onChunk func(chunk string) error) (string, error) {
+	for i, chunk := range l.chunks {
+		if l.pauseAt > 0 && i == l.pauseAt {
+			l.paused <- struct{}{}
+			<-l.resume
+		}
+		if err := onChunk(chunk); err != nil {
+			l.stopErr = err
+			return "", err
+		}
+	}
+	return strings.Join(l.chunks, ""), nil
+}
+
+// pausingLLM returns a streamLLM pausing before chunk pauseAt
+func pausingLLM(pauseAt int, chunks ...string) *streamLLM {
+	return &streamLLM{chunks: chunks, pauseAt: pauseAt, paused: make(chan struct{}), resume: make(chan struct{})}
+}
+
+// streamingClient returns a client answering with llm's streamed answers
+func streamingClient(llm analyzer.LLMClient) *Client {
+	c := newTestClient(nil)
+	c.SetFallback(&FallbackConfig{Mode: FallbackModeLLM, LLMClient: llm, Stream: true})
+	return c
+}
+
+// expectChunk fails the test unless the next message queued for c is a chunk of content
+func expectChunk(t *testing.T, c *Client, content string) {
+	t.Helper()
+	if msg := receive(t, c); msg.Type != models.MessageTypeChunk || msg.Content != content {
+		t.Errorf("message = %s %q, want chunk %q", msg.Type, msg.Content, content)
+	}
+}
+
+// expectStreamedReply fails the test unless the next message queued for c is the
+// complete reply ending a stream
+func expectStreamedReply(t *testing.T, c *Client, content string) {
+	t.Helper()
+	msg := receive(t, c)
+	if msg.Type != models.MessageTypeMessage || msg.Content != content {
+		t.Errorf("reply = %s %q, want message %q", msg.Type, msg.Content, content)
+	}
+	if msg.Metadata["streamed"] != true || msg.Metadata["done"] != true {
+		t.Errorf("reply metadata = %v, want streamed and done", msg.Metadata)
+	}
+}
+
+func TestClientStreamsFallback(t *testing.T) {
+	t.Run("chunks then the complete reply", func(t *testing.T) {
+		c := streamingClient(&streamLLM{chunks: []string{"Because ", "it is ", "simple."}})
+		c.dispatch(chatMessage("Why Go?"))
+
+		expectChunk(t, c, "Because ")
+		expectChunk(t, c, "it is ")
+		expectChunk(t, c, "simple.")
+		expectStreamedReply(t, c, "Because it is simple.")
+		expectNoMessage(t, c)
+	})
+
+	t.Run("streaming disabled", func(t *testing.T) {
+		c := newTestClient(nil)
+		c.SetFallback(&FallbackConfig{Mode: FallbackModeLLM, LLMClient: &streamLLM{chunks: []string{"Because ", "simple."}}})
+		c.dispatch(chatMessage("Why Go?"))
+
+		if reply := receive(t, c); reply.Type != models.MessageTypeMessage || reply.Content != "Because simple." || reply.Metadata["streamed"] != nil {
+			t.Errorf("reply = %s %q %v, want the unstreamed answer", reply.Type, reply.Content, reply.Metadata)
+		}
+		expectNoMessage(t, c)
+	})
+
+	t.Run("chunks coalesced while the send buffer is half full", func(t *testing.T) {
+		llm := pausingLLM(2, "a", "b", "c")
+		c := streamingClient(llm)
+		c.send = make(chan []byte, 4)
+		c.Send([]byte(`{"type":"system","content":"first"}`))
+		c.Send([]byte(`{"type":"system","content":"second"}`))
+
+		c.dispatch(chatMessage("Why Go?"))
+		<-llm.paused
+		receive(t, c)
+		receive(t, c)
+		llm.resume <- struct{}{}
+
+		expectChunk(t, c, "abc")
+		expectStreamedReply(t, c, "abc")
+		expectNoMessage(t, c)
+	})
+
+	t.Run("many chunks never fill the send buffer", func(t *testing.T) {
+		chunks := make([]string, 3*sendBufferSize)
+		for i := range chunks {
+			chunks[i] = "x"
+		}
+		c := streamingClient(&streamLLM{chunks: chunks})
+		c.dispatch(chatMessage("Why Go?"))
+		c.waitForReplies()
+
+		if queued := len(c.send); queued > sendBufferSize/2+1 {
+			t.Fatalf("%d messages queued, want at most half the buffer and the reply", queued)
+		}
+		var streamed strings.Builder
+		for len(c.send) > 1 {
+			streamed.WriteString(receive(t, c).Content)
+		}
+		expectStreamedReply(t, c, strings.Repeat("x", len(chunks)))
+		if !strings.HasPrefix(strings.Repeat("x", len(chunks)), streamed.String()) {
+			t.Errorf("chunks = %q, want a prefix of the answer", streamed.String())
+		}
+	})
+
+	t.Run("stopped when the client disconnects", func(t *testing.T) {
+		llm := pausingLLM(1, "Because ", "it is ", "simple.")
+		c := streamingClient(llm)
+		c.dispatch(chatMessage("Why Go?"))
+
+		<-llm.paused
+		c.disconnect()
+		llm.resume <- struct{}{}
+		c.waitForReplies()
+
+		if !errors.Is(llm.stopErr, errClientGone) {
+			t.Errorf("stream stopped with %v, want errClientGone", llm.stopErr)
+		}
+		expectChunk(t, c, "Because ")
+		expectNoMessage(t, c)
+	})
+}
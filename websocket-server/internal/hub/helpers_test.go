@@ -0,0 +1,103 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/qamatcher"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// newTestClient creates a client without a connection; its replies are read with receive
+func newTestClient(config *HubConfig) *Client {
+	return NewClient(NewHub(config), nil, "client_1")
+}
+
+// receive returns the next message queued for c, failing the test if none arrives
+func receive(t *testing.T, c *Client) models.Message {
+	t.Helper()
+	select {
+	case data := <-c.send:
+		var msg models.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decoding message %q: %v", data, err)
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message sent")
+		return models.Message{}
+	}
+}
+
+// expectNoMessage fails the test if a message is queued for c
+func expectNoMessage(t *testing.T, c *Client) {
+	t.Helper()
+	c.waitForReplies()
+	select {
+	case data := <-c.send:
+		t.Errorf("unexpected message %s", data)
+	default:
+	}
+}
+
+// stubLLM answers prompts with response, or fails with err, recording the prompts it was
+// sent. With block set it waits for the call's context to end instead. Methods a test
+// needs but the stub doesn't implement panic through the embedded nil interface.
+type stubLLM struct {
+	analyzer.LLMClient
+
+	response string
+	err      error
+	block    bool
+
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (l *stubLLM) GenerateFromPrompt(ctx context.Context, prompt string) (string, error) {
+	l.mu.Lock()
+	l.prompts = append(l.prompts, prompt)
+	l.mu.Unlock()
+
+	if l.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return l.response, l.err
+}
+
+// calls returns the number of prompts the stub was sent
+func (l *stubLLM) calls() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.prompts)
+}
+
+// chatMessage returns a chat message with content
+func chatMessage(content string) *models.Message {
+	return &models.Message{Type: models.MessageTypeChat, Content: content}
+}
+
+// stubMatcher reports count loaded questions and answers every query with result.
+// Methods a test needs but the stub doesn't implement panic through the embedded nil
+// interface.
+type stubMatcher struct {
+	qamatcher.QAMatcher
+
+	count     int
+	threshold float64
+	result    qamatcher.MatchResult
+}
+
+func (m *stubMatcher) FindMatch(ctx context.Context, query string) (*qamatcher.MatchResult, error) {
+	result := m.result
+	return &result, nil
+}
+
+func (m *stubMatcher) GetThreshold() float64 { return m.threshold }
+
+func (m *stubMatcher) Count() int { return m.count }
@@ -4,6 +4,8 @@ import (
 	"log"
 	"sync"
 	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
 )
 
 // Hub maintains the set of active clients and broadcasts messages to the clients
@@ -23,18 +25,60 @@ type Hub struct {
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
 
-	// Connection simulation state
+	// Connection simulation state: new connections are refused until simulationEnd
 	simulationActive bool
 	simulationEnd    time.Time
+	clock            clock.Clock
+
+	// Slow consumer handling
+	sendGrace         time.Duration
+	maxMissedMessages int
+
+	// Chat messages of a client answered at once
+	maxConcurrentMessages int
+
+	// Loads Q&A pairs for load_qa messages; nil when not installed
+	qaLoader QALoader
 }
 
-// NewHub creates a new Hub instance
-func NewHub() *Hub {
+// HubConfig controls how the hub treats clients that fall behind on broadcasts and how
+// many of a client's messages are processed at once
+type HubConfig struct {
+	SendGrace             time.Duration // How long a broadcast waits for full send buffers to drain (default 50ms)
+	MaxMissedMessages     int           // Consecutive broadcasts a client may miss before it is disconnected (default 5)
+	MaxConcurrentMessages int           // Chat messages of a client answered at once (default 2, 1 answers them one by one)
+	Clock                 clock.Clock   // Times disconnection simulations (default: system clock)
+}
+
+// NewHub creates a new Hub instance. A nil config uses the defaults.
+func NewHub(config *HubConfig) *Hub {
+	if config == nil {
+		config = &HubConfig{}
+	}
+
+	sendGrace := config.SendGrace
+	if sendGrace <= 0 {
+		sendGrace = 50 * time.Millisecond
+	}
+	maxMissed := config.MaxMissedMessages
+	if maxMissed <= 0 {
+		maxMissed = 5
+	}
+	maxConcurrent := config.MaxConcurrentMessages
+	if maxConcurrent <= 0 {
+		maxConcurrent = 2
+	}
+
 	return &Hub{
-		broadcast:  make(chan []byte, 256),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+		broadcast:         make(chan []byte, 256),
+		register:          make(chan *Client),
+		unregister:        make(chan *Client),
+		clients:           make(map[*Client]bool),
+		sendGrace:         sendGrace,
+		maxMissedMessages: maxMissed,
+		clock:             clock.OrReal(config.Clock),
+
+		maxConcurrentMessages: maxConcurrent,
 	}
 }
 
@@ -53,25 +97,92 @@ func (h *Hub) Run() {
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
+				client.disconnect()
 				log.Printf("Client %s unregistered. Total clients: %d", client.id, len(h.clients))
 			}
 			h.mu.Unlock()
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					// Client's send channel is full, close and remove it
-					close(client.send)
-					delete(h.clients, client)
-				}
+			h.deliver(message)
+		}
+	}
+}
+
+// deliver sends a broadcast to every client. Clients whose send buffer is full get a
+// shared grace period to drain it; a client that still has no room misses the message,
+// and one that misses maxMissedMessages broadcasts in a row is disconnected.
+func (h *Hub) deliver(message []byte) {
+	var full, stuck []*Client
+
+	// The read lock is held while waiting so no client is disconnected under us
+	h.mu.RLock()
+	for client := range h.clients {
+		select {
+		case client.send <- message:
+			client.missed = 0
+		default:
+			full = append(full, client)
+		}
+	}
+	if len(full) > 0 {
+		stuck = h.retrySlowClients(full, message)
+	}
+	h.mu.RUnlock()
+
+	if len(stuck) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	for _, client := range stuck {
+		if _, ok := h.clients[client]; ok {
+			delete(h.clients, client)
+			client.disconnect()
+			log.Printf("Client %s disconnected after missing %d broadcasts. Total clients: %d", client.id, client.missed, len(h.clients))
+		}
+	}
+	h.mu.Unlock()
+}
+
+// retrySlowClients waits up to sendGrace for room in the clients' full send buffers
+// and returns the clients that have now missed too many broadcasts
+func (h *Hub) retrySlowClients(clients []*Client, message []byte) []*Client {
+	var stuck []*Client
+
+	grace := time.NewTimer(h.sendGrace)
+	defer grace.Stop()
+	expired := false
+
+	for _, client := range clients {
+		delivered := false
+		if expired {
+			select {
+			case client.send <- message:
+				delivered = true
+			default:
+			}
+		} else {
+			select {
+			case client.send <- message:
+				delivered = true
+			case <-grace.C:
+				expired = true
 			}
-			h.mu.RUnlock()
+		}
+
+		if delivered {
+			client.missed = 0
+			continue
+		}
+
+		client.missed++
+		log.Printf("Client %s is slow, dropped broadcast (%d/%d missed)", client.id, client.missed, h.maxMissedMessages)
+		if client.missed >= h.maxMissedMessages {
+			stuck = append(stuck, client)
 		}
 	}
+
+	return stuck
 }
 
 // BroadcastMessage sends a message to all connected clients
@@ -96,43 +207,43 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
-// SimulateDisconnection simulates connection unavailability for the specified duration
+// SimulateDisconnection simulates connection unavailability for the specified duration.
+// The simulation ends once the hub's clock passes its end; there is no timer, so a fake
+// clock ends it by being advanced. A new simulation replaces a running one.
 func (h *Hub) SimulateDisconnection(duration time.Duration) {
 	h.mu.Lock()
 	h.simulationActive = true
-	h.simulationEnd = time.Now().Add(duration)
+	h.simulationEnd = h.clock.Now().Add(duration)
 	h.mu.Unlock()
 
 	log.Printf("Starting connection simulation for %v seconds", duration.Seconds())
 
 	// Disconnect all current clients
 	h.disconnectAllClients()
-
-	// Auto-restore after duration
-	go func() {
-		time.Sleep(duration)
-		h.mu.Lock()
-		h.simulationActive = false
-		h.mu.Unlock()
-		log.Println("Connection simulation ended, accepting new connections")
-	}()
 }
 
 // IsSimulationActive checks if connection simulation is currently active
 func (h *Hub) IsSimulationActive() bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	return h.SimulationRemaining() > 0
+}
 
-	// Double-check time-based expiration
-	if h.simulationActive && time.Now().After(h.simulationEnd) {
-		h.mu.RUnlock()
-		h.mu.Lock()
-		h.simulationActive = false
-		h.mu.Unlock()
-		h.mu.RLock()
+// SimulationRemaining returns how long the connection simulation has left, 0 when none
+// is active
+func (h *Hub) SimulationRemaining() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.simulationActive {
+		return 0
 	}
 
-	return h.simulationActive
+	remaining := h.simulationEnd.Sub(h.clock.Now())
+	if remaining <= 0 {
+		h.simulationActive = false
+		log.Println("Connection simulation ended, accepting new connections")
+		return 0
+	}
+	return remaining
 }
 
 // disconnectAllClients forcefully disconnects all connected clients
@@ -147,8 +258,8 @@ func (h *Hub) disconnectAllClients() {
 		client.conn.Close()
 		// Remove from clients map
 		delete(h.clients, client)
-		// Close send channel
-		close(client.send)
+		// Stop its pumps and pending replies
+		client.disconnect()
 	}
 }
 
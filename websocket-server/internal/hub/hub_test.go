@@ -0,0 +1,138 @@
+package hub
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/clock"
+)
+
+// addClient registers a client without a connection whose send buffer holds size
+// messages. It is added directly, as the hub loop would, since tests call deliver.
+func addClient(h *Hub, id string, size int) *Client {
+	c := NewClient(h, nil, id)
+	c.send = make(chan []byte, size)
+	h.clients[c] = true
+	return c
+}
+
+// registered reports whether c is still one of the hub's clients
+func registered(h *Hub, c *Client) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.clients[c]
+}
+
+func TestDeliverToSlowConsumers(t *testing.T) {
+	const grace = 20 * time.Millisecond
+	h := NewHub(&HubConfig{SendGrace: grace, MaxMissedMessages: 3})
+
+	fast := addClient(h, "fast", 8)
+	recovering := addClient(h, "recovering", 1)
+	stuck := addClient(h, "stuck", 1)
+
+	// Fill the slow clients' buffers
+	h.deliver([]byte("first"))
+	<-fast.send
+
+	// The recovering client drains its buffer within the grace period
+	go func() {
+		time.Sleep(grace / 4)
+		<-recovering.send
+	}()
+	h.deliver([]byte("second"))
+
+	if got := string(<-fast.send); got != "second" {
+		t.Errorf("fast client got %q, want second", got)
+	}
+	if got := string(<-recovering.send); got != "second" {
+		t.Errorf("recovering client got %q, want second", got)
+	}
+	if recovering.missed != 0 {
+		t.Errorf("recovering client missed %d broadcasts, want 0", recovering.missed)
+	}
+	if stuck.missed != 1 || !registered(h, stuck) {
+		t.Errorf("stuck client missed %d broadcasts (registered %v), want 1 and still registered", stuck.missed, registered(h, stuck))
+	}
+
+	for i := 0; i < 2; i++ {
+		h.deliver([]byte(fmt.Sprintf("more %d", i)))
+		<-fast.send
+		<-recovering.send
+	}
+
+	if registered(h, stuck) {
+		t.Error("stuck client still registered after missing 3 broadcasts")
+	}
+	if !registered(h, fast) || !registered(h, recovering) {
+		t.Error("clients keeping up were disconnected")
+	}
+	if got := h.GetClientCount(); got != 2 {
+		t.Errorf("client count = %d, want 2", got)
+	}
+}
+
+func TestDeliverResetsMissedBroadcasts(t *testing.T) {
+	h := NewHub(&HubConfig{SendGrace: time.Millisecond, MaxMissedMessages: 2})
+	c := addClient(h, "flaky", 1)
+
+	// Miss one broadcast, catch up, then miss another: the misses aren't consecutive
+	for round := 0; round < 3; round++ {
+		h.deliver([]byte("fill"))
+		h.deliver([]byte("missed"))
+		if c.missed != 1 {
+			t.Fatalf("round %d: missed = %d, want 1", round, c.missed)
+		}
+		<-c.send
+	}
+
+	if !registered(h, c) {
+		t.Error("client disconnected although it never missed 2 broadcasts in a row")
+	}
+}
+
+func TestNewHubDefaults(t *testing.T) {
+	h := NewHub(nil)
+	if h.sendGrace != 50*time.Millisecond || h.maxMissedMessages != 5 || h.maxConcurrentMessages != 2 {
+		t.Errorf("defaults = grace %v, missed %d, concurrent %d", h.sendGrace, h.maxMissedMessages, h.maxConcurrentMessages)
+	}
+}
+
+func TestSimulateDisconnection(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	h := NewHub(&HubConfig{Clock: fake})
+
+	if h.IsSimulationActive() || h.SimulationRemaining() != 0 {
+		t.Fatal("simulation active before one was started")
+	}
+
+	h.SimulateDisconnection(30 * time.Second)
+	if !h.IsSimulationActive() || h.SimulationRemaining() != 30*time.Second {
+		t.Fatalf("active = %v, remaining = %v; want active with 30s left", h.IsSimulationActive(), h.SimulationRemaining())
+	}
+
+	// Nothing ends the simulation while the clock stands still
+	fake.Advance(20 * time.Second)
+	if !h.IsSimulationActive() || h.SimulationRemaining() != 10*time.Second {
+		t.Fatalf("active = %v, remaining = %v; want active with 10s left", h.IsSimulationActive(), h.SimulationRemaining())
+	}
+
+	fake.Advance(10 * time.Second)
+	if h.IsSimulationActive() || h.SimulationRemaining() != 0 {
+		t.Fatalf("active = %v, remaining = %v; want the simulation over", h.IsSimulationActive(), h.SimulationRemaining())
+	}
+
+	t.Run("restarted", func(t *testing.T) {
+		h.SimulateDisconnection(time.Minute)
+		fake.Advance(50 * time.Second)
+		h.SimulateDisconnection(20 * time.Second)
+		if got := h.SimulationRemaining(); got != 20*time.Second {
+			t.Errorf("remaining = %v, want the new simulation's 20s", got)
+		}
+		fake.Advance(20 * time.Second)
+		if h.IsSimulationActive() {
+			t.Error("simulation still active after the new one ended")
+		}
+	})
+}
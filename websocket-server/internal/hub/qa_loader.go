@@ -0,0 +1,101 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// qaLoadTimeout bounds loading Q&A pairs for a load_qa message, matching the HTTP endpoint
+const qaLoadTimeout = 30 * time.Second
+
+// QALoadRequest is the metadata of a load_qa message, selecting the saved Q&A pairs
+// to load into the sending client's matcher. Fields mirror the HTTP load-qa request.
+type QALoadRequest struct {
+	UserID         string   `json:"user_id"`
+	JobID          string   `json:"job_id"`
+	Limit          int      `json:"limit,omitempty"`
+	FallbackMode   string   `json:"fallback_mode,omitempty"`
+	CannedResponse string   `json:"canned_response,omitempty"`
+	HistoryTurns   int      `json:"history_turns,omitempty"`
+	Stream         bool     `json:"stream,omitempty"`
+	Metric         string   `json:"metric,omitempty"`
+	Threshold      *float64 `json:"threshold,omitempty"`
+}
+
+// QALoadResult describes the Q&A pairs loaded for a client
+type QALoadResult struct {
+	Count        int
+	Threshold    float64
+	Metric       string
+	FallbackMode string
+	Message      string
+}
+
+// QALoader loads saved Q&A pairs into a client's matcher. Errors are reported to the
+// client as they are, so they must be fit for users.
+type QALoader func(ctx context.Context, client *Client, req QALoadRequest) (*QALoadResult, error)
+
+// SetQALoader installs the loader handling load_qa messages. Without one, load_qa
+// messages are answered with an error.
+func (h *Hub) SetQALoader(loader QALoader) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.qaLoader = loader
+}
+
+// getQALoader returns the installed QALoader, or nil
+func (h *Hub) getQALoader() QALoader {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.qaLoader
+}
+
+// handleLoadQA loads Q&A pairs for this client without the HTTP round trip, replying
+// with a system message carrying the number loaded. Chat messages sent after load_qa
+// are answered once loading finishes, so they already use the new pairs.
+func (c *Client) handleLoadQA(msg *models.Message) {
+	loader := c.hub.getQALoader()
+	if loader == nil {
+		c.sendError("Loading Q&A pairs over WebSocket is not available")
+		return
+	}
+
+	var req QALoadRequest
+	if raw, err := json.Marshal(msg.Metadata); err != nil || json.Unmarshal(raw, &req) != nil {
+		c.sendError("Invalid load_qa request: metadata must hold user_id, job_id and optional limit")
+		return
+	}
+	if req.UserID == "" || req.JobID == "" {
+		c.sendError("Invalid load_qa request: user_id and job_id are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), qaLoadTimeout)
+	defer cancel()
+
+	result, err := loader(ctx, c, req)
+	if err != nil {
+		log.Printf("Failed to load Q&A pairs for client %s (user: %s, job: %s): %v", c.id, req.UserID, req.JobID, err)
+		c.sendError(err.Error())
+		return
+	}
+
+	c.sendMessage(models.Message{
+		Type:      models.MessageTypeSystem,
+		SessionID: msg.SessionID,
+		Content:   result.Message,
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			"client_id":     c.id,
+			"job_id":        req.JobID,
+			"qa_loaded":     result.Count,
+			"threshold":     result.Threshold,
+			"metric":        result.Metric,
+			"fallback_mode": result.FallbackMode,
+		},
+	})
+}
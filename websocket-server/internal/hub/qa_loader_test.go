@@ -0,0 +1,77 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestClientLoadQA(t *testing.T) {
+	// loader installs a matcher of two questions, recording the request it was sent
+	var got QALoadRequest
+	loader := func(ctx context.Context, client *Client, req QALoadRequest) (*QALoadResult, error) {
+		got = req
+		if req.JobID == "job_broken" {
+			return nil, errors.New("Failed to load saved questions")
+		}
+		client.SetQAMatcher(&stubMatcher{count: 2, threshold: 0.8})
+		return &QALoadResult{Count: 2, Threshold: 0.8, Metric: "cosine", FallbackMode: "echo", Message: "Q&A pairs loaded successfully"}, nil
+	}
+
+	t.Run("loads the sender's questions", func(t *testing.T) {
+		c := newTestClient(nil)
+		c.hub.SetQALoader(loader)
+
+		c.dispatch(&models.Message{Type: models.MessageTypeLoadQA, SessionID: "s1", Metadata: map[string]interface{}{
+			"user_id": "u1", "job_id": "job_1", "limit": 5, "fallback_mode": "echo",
+		}})
+
+		reply := receive(t, c)
+		if reply.Type != models.MessageTypeSystem || reply.Content != "Q&A pairs loaded successfully" || reply.SessionID != "s1" {
+			t.Errorf("reply = %s %q in session %q, want the loader's system message in s1", reply.Type, reply.Content, reply.SessionID)
+		}
+		if reply.Metadata["qa_loaded"] != float64(2) || reply.Metadata["client_id"] != "client_1" || reply.Metadata["job_id"] != "job_1" {
+			t.Errorf("reply metadata = %v, want 2 pairs loaded for client_1's job_1", reply.Metadata)
+		}
+		if got.UserID != "u1" || got.JobID != "job_1" || got.Limit != 5 || got.FallbackMode != "echo" {
+			t.Errorf("loader request = %+v", got)
+		}
+		if state := c.QAState(); !state.Loaded || state.QuestionCount != 2 {
+			t.Errorf("state after load_qa = %+v, want 2 questions loaded", state)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		loader   QALoader
+		metadata map[string]interface{}
+		want     string
+	}{
+		{"no loader", nil, map[string]interface{}{"user_id": "u1", "job_id": "job_1"}, "Loading Q&A pairs over WebSocket is not available"},
+		{"missing job", loader, map[string]interface{}{"user_id": "u1"}, "Invalid load_qa request: user_id and job_id are required"},
+		{"missing metadata", loader, nil, "Invalid load_qa request: user_id and job_id are required"},
+		{"mistyped field", loader, map[string]interface{}{"user_id": "u1", "job_id": "job_1", "limit": "ten"}, "Invalid load_qa request: metadata must hold user_id, job_id and optional limit"},
+		{"loader error", loader, map[string]interface{}{"user_id": "u1", "job_id": "job_broken"}, "Failed to load saved questions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			if tt.loader != nil {
+				c.hub.SetQALoader(tt.loader)
+			}
+
+			c.dispatch(&models.Message{Type: models.MessageTypeLoadQA, Metadata: tt.metadata})
+
+			reply := receive(t, c)
+			if reply.Type != models.MessageTypeError || reply.Content != tt.want {
+				t.Errorf("reply = %s %q, want error %q", reply.Type, reply.Content, tt.want)
+			}
+			if c.QAState().Loaded {
+				t.Error("questions loaded despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// JobEmailConfig configures emails about finished analysis jobs
+type JobEmailConfig struct {
+	Enabled    bool   // Global switch; uploads also have to opt in with NotifyEmail
+	AppBaseURL string // Frontend URL the result link points to, e.g. https://app.example.com
+}
+
+// jobEmailData is the data available to the job email templates
+type jobEmailData struct {
+	Name      string // Owner's name, may be empty
+	FileName  string
+	ResultURL string
+	Error     string // Why the job failed, empty on completion
+}
+
+// Templates of the job emails, by outcome
+var (
+	jobCompletedSubject = "Your resume analysis is ready"
+	jobCompletedBody    = template.Must(template.New("job_completed").Parse(
+		`Hi{{if .Name}} {{.Name}}{{end}},
+
+The analysis of {{.FileName}} is complete. View the results at:
+
+{{.ResultURL}}
+`))
+
+	jobFailedSubject = "Your resume analysis failed"
+	jobFailedBody    = template.Must(template.New("job_failed").Parse(
+		`Hi{{if .Name}} {{.Name}}{{end}},
+
+The analysis of {{.FileName}} could not be completed{{if .Error}}: {{.Error}}{{end}}.
+
+You can check the job and retry it at:
+
+{{.ResultURL}}
+`))
+)
+
+// JobEmailNotifier emails the owner of an upload when an analysis job of the upload
+// completes or fails, if the upload opted in with NotifyEmail
+type JobEmailNotifier struct {
+	mailer     Mailer
+	uploadRepo repository.UploadRepository
+	userRepo   repository.UserRepository
+	config     JobEmailConfig
+}
+
+// NewJobEmailNotifier creates a new job email notifier
+func NewJobEmailNotifier(mailer Mailer, uploadRepo repository.UploadRepository, userRepo repository.UserRepository, config JobEmailConfig) *JobEmailNotifier {
+	config.AppBaseURL = strings.TrimRight(config.AppBaseURL, "/")
+	return &JobEmailNotifier{
+		mailer:     mailer,
+		uploadRepo: uploadRepo,
+		userRepo:   userRepo,
+		config:     config,
+	}
+}
+
+// JobFinished emails the job's owner about a completed, failed or dead-lettered job.
+// Nothing is sent when notifications are disabled, the upload didn't opt in or the
+// owner has no email address.
+func (n *JobEmailNotifier) JobFinished(ctx context.Context, job *models.AnalysisJob) error {
+	if !n.config.Enabled {
+		return nil
+	}
+
+	upload, err := n.uploadRepo.GetUploadByID(ctx, job.UploadID)
+	if err != nil {
+		return fmt.Errorf("failed to get upload %d: %w", job.UploadID, err)
+	}
+	if !upload.NotifyEmail {
+		return nil
+	}
+
+	ownerID := job.UserID
+	if ownerID == nil {
+		ownerID = upload.UserID
+	}
+	if ownerID == nil {
+		return nil
+	}
+
+	user, err := n.userRepo.GetUserByID(ctx, *ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to get user %d: %w", *ownerID, err)
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	msg, err := n.composeJobEmail(job, upload, user)
+	if err != nil || msg == nil {
+		return err
+	}
+
+	if err := n.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to email job %s result: %w", job.JobID, err)
+	}
+
+	log.Printf("Emailed user %d about job %s (%s)", user.ID, job.JobID, job.Status)
+	return nil
+}
+
+// composeJobEmail renders the email about a finished job, or returns nil for a job
+// that hasn't completed or failed
+func (n *JobEmailNotifier) composeJobEmail(job *models.AnalysisJob, upload *models.Upload, user *models.User) (*Message, error) {
+	data := jobEmailData{
+		Name:      user.Name,
+		FileName:  upload.FileName,
+		ResultURL: n.config.AppBaseURL + "/analysis/" + job.JobID,
+	}
+
+	var subject string
+	var body *template.Template
+	switch job.Status {
+	case "completed":
+		subject, body = jobCompletedSubject, jobCompletedBody
+	case "failed", "dead_lettered":
+		subject, body = jobFailedSubject, jobFailedBody
+		if job.ErrorMessage != nil {
+			data.Error = *job.ErrorMessage
+		}
+	default:
+		return nil, nil
+	}
+
+	var text strings.Builder
+	if err := body.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render job email: %w", err)
+	}
+
+	return &Message{To: user.Email, Subject: subject, Body: text.String()}, nil
+}
@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// stubMailer records the messages sent through it, or fails with err
+type stubMailer struct {
+	sent []*Message
+	err  error
+}
+
+func (m *stubMailer) Send(ctx context.Context, msg *Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// fakeUploads serves uploads by ID. Methods a test needs but the fake doesn't implement
+// panic through the embedded nil interface.
+type fakeUploads struct {
+	repository.UploadRepository
+
+	uploads map[int]*models.Upload
+}
+
+func (f *fakeUploads) GetUploadByID(ctx context.Context, id int) (*models.Upload, error) {
+	upload, ok := f.uploads[id]
+	if !ok {
+		return nil, fmt.Errorf("upload not found with ID: %d", id)
+	}
+	return upload, nil
+}
+
+// fakeUsers serves users by ID. Methods a test needs but the fake doesn't implement
+// panic through the embedded nil interface.
+type fakeUsers struct {
+	repository.UserRepository
+
+	users map[int]*models.User
+}
+
+func (f *fakeUsers) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, fmt.Errorf("no user %d", id)
+	}
+	return user, nil
+}
+
+func TestJobFinished(t *testing.T) {
+	owner, mailless := 7, 8
+	uploads := &fakeUploads{uploads: map[int]*models.Upload{
+		1: {ID: 1, UserID: &owner, FileName: "resume.pdf", NotifyEmail: true},
+		2: {ID: 2, UserID: &owner, FileName: "quiet.pdf"},
+		3: {ID: 3, FileName: "anonymous.pdf", NotifyEmail: true},
+		4: {ID: 4, UserID: &mailless, FileName: "mailless.pdf", NotifyEmail: true},
+	}}
+	users := &fakeUsers{users: map[int]*models.User{
+		owner:    {ID: owner, Name: "Ada", Email: "ada@example.com"},
+		mailless: {ID: mailless, Name: "Bob"},
+	}}
+	reason := "Embedding generation failed"
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		job         *models.AnalysisJob
+		wantSubject string
+		wantBody    []string
+	}{
+		{
+			name:        "completed",
+			job:         &models.AnalysisJob{JobID: "job_1", UploadID: 1, Status: "completed"},
+			wantSubject: jobCompletedSubject,
+			wantBody:    []string{"Hi Ada,", "resume.pdf is complete", "https://app.example.com/analysis/job_1"},
+		},
+		{
+			name:        "failed",
+			job:         &models.AnalysisJob{JobID: "job_2", UploadID: 1, Status: "failed", ErrorMessage: &reason},
+			wantSubject: jobFailedSubject,
+			wantBody:    []string{"resume.pdf could not be completed: Embedding generation failed.", "https://app.example.com/analysis/job_2"},
+		},
+		{
+			name:        "dead-lettered",
+			job:         &models.AnalysisJob{JobID: "job_3", UploadID: 1, Status: "dead_lettered"},
+			wantSubject: jobFailedSubject,
+			wantBody:    []string{"resume.pdf could not be completed.", "https://app.example.com/analysis/job_3"},
+		},
+		{
+			name:        "job owner preferred over upload owner",
+			job:         &models.AnalysisJob{JobID: "job_4", UploadID: 3, UserID: &owner, Status: "completed"},
+			wantSubject: jobCompletedSubject,
+			wantBody:    []string{"anonymous.pdf is complete"},
+		},
+		{
+			name:     "notifications disabled",
+			disabled: true,
+			job:      &models.AnalysisJob{JobID: "job_5", UploadID: 1, Status: "completed"},
+		},
+		{
+			name: "upload didn't opt in",
+			job:  &models.AnalysisJob{JobID: "job_6", UploadID: 2, Status: "completed"},
+		},
+		{
+			name: "no owner",
+			job:  &models.AnalysisJob{JobID: "job_7", UploadID: 3, Status: "completed"},
+		},
+		{
+			name: "owner without email",
+			job:  &models.AnalysisJob{JobID: "job_8", UploadID: 4, Status: "failed"},
+		},
+		{
+			name: "job still running",
+			job:  &models.AnalysisJob{JobID: "job_9", UploadID: 1, Status: "analyzing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &stubMailer{}
+			n := NewJobEmailNotifier(mailer, uploads, users, JobEmailConfig{Enabled: !tt.disabled, AppBaseURL: "https://app.example.com/"})
+
+			if err := n.JobFinished(context.Background(), tt.job); err != nil {
+				t.Fatalf("JobFinished: %v", err)
+			}
+
+			if tt.wantSubject == "" {
+				if len(mailer.sent) != 0 {
+					t.Errorf("sent %+v, want no email", mailer.sent)
+				}
+				return
+			}
+			if len(mailer.sent) != 1 {
+				t.Fatalf("sent %d emails, want 1", len(mailer.sent))
+			}
+			msg := mailer.sent[0]
+			if msg.To != "ada@example.com" || msg.Subject != tt.wantSubject {
+				t.Errorf("email to %q about %q, want ada@example.com about %q", msg.To, msg.Subject, tt.wantSubject)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(msg.Body, want) {
+					t.Errorf("body is missing %q:\n%s", want, msg.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestJobFinishedErrors(t *testing.T) {
+	owner := 7
+	uploads := &fakeUploads{uploads: map[int]*models.Upload{
+		1: {ID: 1, UserID: &owner, FileName: "resume.pdf", NotifyEmail: true},
+	}}
+	users := &fakeUsers{users: map[int]*models.User{owner: {ID: owner, Email: "ada@example.com"}}}
+	config := JobEmailConfig{Enabled: true}
+
+	t.Run("mailer failure", func(t *testing.T) {
+		mailer := &stubMailer{err: errors.New("connection refused")}
+		n := NewJobEmailNotifier(mailer, uploads, users, config)
+
+		err := n.JobFinished(context.Background(), &models.AnalysisJob{JobID: "job_1", UploadID: 1, Status: "completed"})
+		if err == nil || !strings.Contains(err.Error(), "job_1") || !errors.Is(err, mailer.err) {
+			t.Errorf("error = %v, want the mailer's naming the job", err)
+		}
+	})
+
+	t.Run("missing upload", func(t *testing.T) {
+		n := NewJobEmailNotifier(&stubMailer{}, uploads, users, config)
+
+		if err := n.JobFinished(context.Background(), &models.AnalysisJob{JobID: "job_2", UploadID: 9, Status: "completed"}); err == nil {
+			t.Error("expected an error for a missing upload")
+		}
+	})
+}
+
+func TestFormatMessage(t *testing.T) {
+	data := string(formatMessage("Resume Analyzer <noreply@example.com>", &Message{
+		To:      "ada@example.com\r\nBcc: eve@example.com",
+		Subject: "Your résumé",
+		Body:    "Line one\nLine two\r\n",
+	}))
+
+	header, body, ok := strings.Cut(data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no header separator in %q", data)
+	}
+	for _, want := range []string{
+		"From: Resume Analyzer <noreply@example.com>\r\n",
+		"To: ada@example.com Bcc: eve@example.com\r\n",
+		"Subject: =?utf-8?q?Your_r=C3=A9sum=C3=A9?=\r\n",
+		"Content-Type: text/plain; charset=UTF-8",
+	} {
+		if !strings.Contains(header+"\r\n", want) {
+			t.Errorf("header is missing %q:\n%s", want, header)
+		}
+	}
+	if strings.Contains(header, "\nBcc:") {
+		t.Errorf("recipient injected a header:\n%s", header)
+	}
+	if body != "Line one\r\nLine two\r\n" {
+		t.Errorf("body = %q, want CRLF line endings", body)
+	}
+}
@@ -0,0 +1,299 @@
+// Package prompts renders the LLM prompts used across the server from text/template
+// templates. Built-in defaults are embedded in the binary; any of them can be
+// replaced by a template file or a template string supplied through configuration.
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Names of the prompt templates. A template file overriding one is named <name>.tmpl.
+const (
+	Analysis           = "analysis"            // Resume analysis, rendered with AnalysisData
+	InterviewQuestions = "interview_questions" // Interview question generation, rendered with InterviewQuestionsData
+	InterviewAnswer    = "interview_answer"    // Single answer (re)generation, rendered with InterviewAnswerData
+	ChatFallback       = "chat_fallback"       // LLM answers to unmatched chat messages, rendered with ChatFallbackData
+	JobRecommendations = "job_recommendations" // Job recommendations alone, rendered with JobRecommendationsData
+	SearchRerank       = "search_rerank"       // Re-ranking of resume search results, rendered with SearchRerankData
+	SkillGap           = "skill_gap"           // Skills a profile is missing for a job description, rendered with SkillGapData
+)
+
+// InterviewQuestionCount is how many questions interview question generation asks for
+const InterviewQuestionCount = 10
+
+// templateExt is the file extension of prompt template files
+const templateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// AnalysisData is the data available to the analysis template
+type AnalysisData struct {
+	ResumeText      string
+	RetrievedChunks []string // Resume chunks retrieved from the vector store
+	LinkedInURL     string   // Empty when the resume has no LinkedIn profile
+	Fields          string   // JSON schema fields extracted by the current analysis pass
+}
+
+// InterviewQuestionsData is the data available to the interview questions template
+type InterviewQuestionsData struct {
+	ProfileJSON     string // Candidate profile as indented JSON
+	JobTitle        string
+	Level           string
+	TargetCompany   string // Optional
+	JobDescription  string // Optional
+	JobRequirements string
+	Language        string // Display name of the answer language, empty for English
+	QuestionCount   int    // Questions to generate, InterviewQuestionCount
+}
+
+// InterviewAnswerData is the data available to the single answer template
+type InterviewAnswerData struct {
+	ProfileJSON string // Candidate profile as indented JSON
+	Question    string
+	Category    string // Optional
+	Language    string // Display name of the answer language, empty for English
+}
+
+// ChatFallbackData is the data available to the chat fallback template
+type ChatFallbackData struct {
+	Context string     // Candidate and job context, may be empty
+	History []ChatTurn // Recent turns of the conversation, oldest first
+	Query   string
+}
+
+// JobRecommendationsData is the data available to the job recommendations template
+type JobRecommendationsData struct {
+	ProfileJSON string   // Candidate profile as indented JSON
+	Industry    string   // Industry to bias the recommendations towards, optional
+	Current     []string // Recommendations the profile already has
+}
+
+// SearchRerankData is the data available to the search re-ranking template
+type SearchRerankData struct {
+	Query      string
+	Candidates []RerankCandidate // In vector similarity order
+}
+
+// RerankCandidate is a search result to be re-ranked
+type RerankCandidate struct {
+	UploadID    int
+	ProfileJSON string // Candidate profile as compact JSON
+}
+
+// SkillGapData is the data available to the skill gap template
+type SkillGapData struct {
+	ProfileJSON    string // Candidate profile as indented JSON
+	JobDescription string
+}
+
+// ChatTurn is one exchange of a chat conversation
+type ChatTurn struct {
+	Query string
+	Reply string
+}
+
+// samples hold representative data for each template. Overrides are executed
+// against them when loaded, so a template referring to an unknown field fails at
+// startup rather than on the first request.
+var samples = map[string]interface{}{
+	Analysis: AnalysisData{
+		ResumeText:      "resume",
+		RetrievedChunks: []string{"chunk"},
+		LinkedInURL:     "https://www.linkedin.com/in/example",
+		Fields:          `  "name": "<full name or null>"`,
+	},
+	InterviewQuestions: InterviewQuestionsData{
+		ProfileJSON:     "{}",
+		JobTitle:        "Engineer",
+		Level:           "Senior",
+		TargetCompany:   "Example",
+		JobDescription:  "description",
+		JobRequirements: "requirements",
+		Language:        "Spanish",
+		QuestionCount:   InterviewQuestionCount,
+	},
+	InterviewAnswer: InterviewAnswerData{
+		ProfileJSON: "{}",
+		Question:    "question",
+		Category:    "Technical",
+		Language:    "Spanish",
+	},
+	ChatFallback: ChatFallbackData{
+		Context: "context",
+		History: []ChatTurn{{Query: "query", Reply: "reply"}},
+		Query:   "query",
+	},
+	JobRecommendations: JobRecommendationsData{
+		ProfileJSON: "{}",
+		Industry:    "Fintech",
+		Current:     []string{"Backend Engineer"},
+	},
+	SearchRerank: SearchRerankData{
+		Query:      "query",
+		Candidates: []RerankCandidate{{UploadID: 1, ProfileJSON: "{}"}},
+	},
+	SkillGap: SkillGapData{
+		ProfileJSON:    "{}",
+		JobDescription: "description",
+	},
+}
+
+// funcs are the functions available to prompt templates
+var funcs = template.FuncMap{
+	"inc":   func(i int) int { return i + 1 },
+	"fence": Fence,
+}
+
+// Markers enclosing untrusted content, such as resume text, in prompts
+const (
+	UntrustedBegin = "[BEGIN UNTRUSTED DATA]"
+	UntrustedEnd   = "[END UNTRUSTED DATA]"
+)
+
+// untrustedMarker matches the untrusted content markers, loosely, so content can't end its fence early
+var untrustedMarker = regexp.MustCompile(`(?i)\[\s*(begin|end)\s+untrusted\s+data\s*\]`)
+
+// Fence encloses untrusted text between UntrustedBegin and UntrustedEnd on their own
+// lines, removing any copies of the markers from the text. Templates call it as fence.
+func Fence(text string) string {
+	return UntrustedBegin + "\n" + untrustedMarker.ReplaceAllString(text, "") + "\n" + UntrustedEnd
+}
+
+// Registry holds the parsed prompt templates
+type Registry struct {
+	templates map[string]*template.Template
+}
+
+// NewRegistry creates a registry with the built-in templates, replaced by any of
+// overrides (template name to template text)
+func NewRegistry(overrides map[string]string) (*Registry, error) {
+	r := &Registry{templates: make(map[string]*template.Template, len(samples))}
+
+	for name := range samples {
+		text, err := defaultTemplates.ReadFile("templates/" + name + templateExt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read default prompt template %s: %w", name, err)
+		}
+		tmpl, err := parse(name, string(text))
+		if err != nil {
+			return nil, err
+		}
+		r.templates[name] = tmpl
+	}
+
+	for name, text := range overrides {
+		if _, ok := samples[name]; !ok {
+			return nil, fmt.Errorf("unknown prompt template %q (known: %s)", name, strings.Join(Names(), ", "))
+		}
+		tmpl, err := parse(name, text)
+		if err != nil {
+			return nil, err
+		}
+		if err := tmpl.Execute(&strings.Builder{}, samples[name]); err != nil {
+			return nil, fmt.Errorf("invalid prompt template %s: %w", name, err)
+		}
+		r.templates[name] = tmpl
+	}
+
+	return r, nil
+}
+
+// LoadDir creates a registry with the built-in templates, replaced by the
+// <name>.tmpl files found in dir. Other files in dir are ignored.
+func LoadDir(dir string) (*Registry, error) {
+	overrides := make(map[string]string)
+	for _, name := range Names() {
+		text, err := os.ReadFile(filepath.Join(dir, name+templateExt))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt template %s: %w", name, err)
+		}
+		overrides[name] = string(text)
+	}
+	return NewRegistry(overrides)
+}
+
+// Render executes the named template with data
+func (r *Registry) Render(name string, data interface{}) (string, error) {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown prompt template %q", name)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// Names returns the names of all prompt templates, sorted
+func Names() []string {
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parse parses a prompt template
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry *Registry
+)
+
+// Default returns the registry used by the prompt builders: the one installed
+// with SetDefault, or the built-in templates
+func Default() *Registry {
+	defaultMu.RLock()
+	r := defaultRegistry
+	defaultMu.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultRegistry == nil {
+		builtin, err := NewRegistry(nil)
+		if err != nil {
+			// The built-in templates are embedded, so this only fails if one of them is broken
+			panic(err)
+		}
+		defaultRegistry = builtin
+	}
+	return defaultRegistry
+}
+
+// SetDefault installs the registry used by the prompt builders, typically one
+// returned by LoadDir at startup
+func SetDefault(r *Registry) {
+	defaultMu.Lock()
+	defaultRegistry = r
+	defaultMu.Unlock()
+}
+
+// Render executes the named template of the default registry with data
+func Render(name string, data interface{}) (string, error) {
+	return Default().Render(name, data)
+}
@@ -0,0 +1,182 @@
+package qamatcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+)
+
+// BackfillConfig holds configuration for the embedding backfill worker
+type BackfillConfig struct {
+	BatchSize  int           // Questions fetched and embedded per batch
+	Interval   time.Duration // Time between backfill runs when the previous run succeeded
+	MaxBackoff time.Duration // Upper bound for the delay after consecutive failed runs
+
+	ScheduleDelay time.Duration // Delay before a run requested with Schedule, giving a failing embedder time to recover
+}
+
+// BackfillResult summarizes a single backfill run
+type BackfillResult struct {
+	Processed int `json:"processed"` // Questions found without an embedding
+	Updated   int `json:"updated"`   // Questions that now have a stored embedding
+	Failed    int `json:"failed"`    // Questions whose embedding could not be generated or stored
+}
+
+// EmbeddingBackfiller generates and stores embeddings for saved questions
+// that were persisted without one (e.g. because the embedder was unavailable)
+type EmbeddingBackfiller struct {
+	repo       repository.SavedQuestionRepository
+	embedder   analyzer.EmbeddingGenerator
+	batchSize  int
+	interval   time.Duration
+	maxBackoff time.Duration
+	mu         sync.Mutex // Ensures only one run is active at a time
+
+	scheduleDelay time.Duration
+	scheduled     chan struct{} // Signals Start to run early; buffered so requests coalesce
+}
+
+// NewEmbeddingBackfiller creates a new embedding backfill worker
+func NewEmbeddingBackfiller(repo repository.SavedQuestionRepository, embedder analyzer.EmbeddingGenerator, config *BackfillConfig) *EmbeddingBackfiller {
+	defaults := &BackfillConfig{
+		BatchSize:     50,
+		Interval:      10 * time.Minute,
+		MaxBackoff:    time.Hour,
+		ScheduleDelay: time.Minute,
+	}
+	if config == nil {
+		config = defaults
+	}
+
+	cfg := *config
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.MaxBackoff < cfg.Interval {
+		cfg.MaxBackoff = cfg.Interval
+	}
+	if cfg.ScheduleDelay <= 0 {
+		cfg.ScheduleDelay = defaults.ScheduleDelay
+	}
+
+	return &EmbeddingBackfiller{
+		repo:       repo,
+		embedder:   embedder,
+		batchSize:  cfg.BatchSize,
+		interval:   cfg.Interval,
+		maxBackoff: cfg.MaxBackoff,
+
+		scheduleDelay: cfg.ScheduleDelay,
+		scheduled:     make(chan struct{}, 1),
+	}
+}
+
+// Schedule asks the worker started with Start to run after ScheduleDelay instead of
+// waiting for the next interval, e.g. because a question was just saved without an
+// embedding. Requests made before the run happens are coalesced.
+func (b *EmbeddingBackfiller) Schedule(questionID int64) {
+	select {
+	case b.scheduled <- struct{}{}:
+		log.Printf("Embedding backfill scheduled for saved question %d", questionID)
+	default:
+		// A run is already scheduled and will pick this question up
+	}
+}
+
+// Start runs the backfill periodically until ctx is cancelled.
+// After a run with failures the delay doubles, up to MaxBackoff, and resets on the next clean run.
+func (b *EmbeddingBackfiller) Start(ctx context.Context) {
+	go func() {
+		delay := b.interval
+		for {
+			result, err := b.RunOnce(ctx)
+			if err != nil || result.Failed > 0 {
+				delay *= 2
+				if delay > b.maxBackoff {
+					delay = b.maxBackoff
+				}
+				log.Printf("Embedding backfill incomplete (err: %v), next run in %s", err, delay)
+			} else {
+				delay = b.interval
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Printf("Embedding backfill worker stopped")
+				return
+			case <-time.After(delay):
+			case <-b.scheduled:
+				select {
+				case <-ctx.Done():
+					log.Printf("Embedding backfill worker stopped")
+					return
+				case <-time.After(b.scheduleDelay):
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce embeds every saved question currently missing an embedding, in batches.
+// Questions that fail are skipped and retried on the next run.
+func (b *EmbeddingBackfiller) RunOnce(ctx context.Context) (*BackfillResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := &BackfillResult{}
+	var afterID int64
+
+	for {
+		questions, err := b.repo.GetQuestionsWithoutEmbedding(ctx, afterID, b.batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch questions without embedding: %w", err)
+		}
+		if len(questions) == 0 {
+			break
+		}
+
+		for _, q := range questions {
+			afterID = q.ID
+			result.Processed++
+
+			embedding, err := b.embedder.GenerateEmbedding(ctx, q.Question)
+			if err != nil {
+				log.Printf("Warning: Failed to generate embedding for saved question %d: %v", q.ID, err)
+				result.Failed++
+				continue
+			}
+
+			data, err := SerializeEmbedding(embedding)
+			if err != nil {
+				log.Printf("Warning: Failed to serialize embedding for saved question %d: %v", q.ID, err)
+				result.Failed++
+				continue
+			}
+
+			if err := b.repo.UpdateQuestionEmbedding(ctx, q.ID, data); err != nil {
+				log.Printf("Warning: Failed to store embedding for saved question %d: %v", q.ID, err)
+				result.Failed++
+				continue
+			}
+			result.Updated++
+		}
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Processed > 0 {
+		log.Printf("Embedding backfill: %d processed, %d updated, %d failed", result.Processed, result.Updated, result.Failed)
+	}
+
+	return result, nil
+}
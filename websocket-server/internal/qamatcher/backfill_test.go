@@ -0,0 +1,120 @@
+package qamatcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEmbeddingBackfillerRunOnce(t *testing.T) {
+	vectors := map[string][]float32{
+		"Why Go?":          {1, 0},
+		"What is a slice?": {0, 1},
+		"Explain defer":    {0.5, 0.5},
+	}
+
+	tests := []struct {
+		name         string
+		texts        []string
+		batchSize    int
+		want         BackfillResult
+		wantEmbedded []int64
+		wantFetches  int
+	}{
+		{"nothing to do", nil, 10, BackfillResult{}, nil, 1},
+		{"one question", []string{"Why Go?"}, 10, BackfillResult{Processed: 1, Updated: 1}, []int64{1}, 2},
+		{"several batches", []string{"Why Go?", "What is a slice?", "Explain defer"}, 2, BackfillResult{Processed: 3, Updated: 3}, []int64{1, 2, 3}, 3},
+		{"failures skipped", []string{"Why Go?", "Unknown", "Explain defer"}, 1, BackfillResult{Processed: 3, Updated: 2, Failed: 1}, []int64{1, 3}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeQuestionStore(tt.texts...)
+			b := NewEmbeddingBackfiller(store, &stubEmbedder{vectors: vectors}, &BackfillConfig{BatchSize: tt.batchSize})
+
+			result, err := b.RunOnce(context.Background())
+			if err != nil {
+				t.Fatalf("RunOnce: %v", err)
+			}
+			if *result != tt.want {
+				t.Errorf("result = %+v, want %+v", *result, tt.want)
+			}
+			if store.fetches != tt.wantFetches {
+				t.Errorf("fetched %d batches, want %d", store.fetches, tt.wantFetches)
+			}
+
+			var embedded []int64
+			for i, text := range tt.texts {
+				id := int64(i + 1)
+				data := store.embedding(id)
+				if data == nil {
+					continue
+				}
+				embedded = append(embedded, id)
+
+				embedding, err := DeserializeEmbedding(data)
+				if err != nil {
+					t.Fatalf("DeserializeEmbedding: %v", err)
+				}
+				if !reflect.DeepEqual(embedding, vectors[text]) {
+					t.Errorf("question %d embedded as %v, want %v", id, embedding, vectors[text])
+				}
+			}
+			if !reflect.DeepEqual(embedded, tt.wantEmbedded) {
+				t.Errorf("embedded questions = %v, want %v", embedded, tt.wantEmbedded)
+			}
+		})
+	}
+}
+
+func TestEmbeddingBackfillerRetriesFailures(t *testing.T) {
+	store := newFakeQuestionStore("Why Go?")
+	embedder := &stubEmbedder{vectors: map[string][]float32{}}
+	b := NewEmbeddingBackfiller(store, embedder, nil)
+
+	result, err := b.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if result.Failed != 1 || store.embedding(1) != nil {
+		t.Fatalf("first run = %+v, want the question to fail", *result)
+	}
+
+	// The embedder recovers; the next run picks the question up again
+	embedder.vectors["Why Go?"] = []float32{1, 0}
+	result, err = b.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if result.Updated != 1 || store.embedding(1) == nil {
+		t.Errorf("second run = %+v, want the question embedded", *result)
+	}
+}
+
+func TestEmbeddingBackfillerSchedule(t *testing.T) {
+	store := newFakeQuestionStore()
+	b := NewEmbeddingBackfiller(store, &stubEmbedder{vectors: map[string][]float32{"Why Go?": {1, 0}}}, &BackfillConfig{
+		Interval:      time.Hour,
+		ScheduleDelay: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.Start(ctx)
+
+	// A question saved without an embedding after the first run is embedded once scheduled,
+	// long before the next interval
+	store.mu.Lock()
+	store.questions[1] = newFakeQuestionStore("Why Go?").questions[1]
+	store.mu.Unlock()
+	b.Schedule(1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for store.embedding(1) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduled question was not embedded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -22,18 +22,26 @@ type questionEmbedding struct {
 
 // EmbeddingMatcher implements Q&A matching using semantic embedding similarity
 type EmbeddingMatcher struct {
-	embedder          analyzer.EmbeddingGenerator
-	questions         []*questionEmbedding
-	threshold         float64 // Minimum similarity score (0-1)
-	mu                sync.RWMutex
-	generateOnTheFly  bool // Whether to generate embeddings on-the-fly if not stored
+	embedder         analyzer.EmbeddingGenerator
+	questions        []*questionEmbedding
+	threshold        float64 // Minimum similarity score, on the metric's scale
+	metric           Metric
+	shortQueries     ShortQueryPolicy
+	mu               sync.RWMutex
+	generateOnTheFly bool // Whether to generate embeddings on-the-fly if not stored
 }
 
-// NewEmbeddingMatcher creates a new embedding-based Q&A matcher
-func NewEmbeddingMatcher(embedder analyzer.EmbeddingGenerator, threshold float64) *EmbeddingMatcher {
+// NewEmbeddingMatcher creates a new embedding-based Q&A matcher. The threshold is
+// interpreted on the metric's scale (see Metric); an empty metric uses DefaultMetric.
+func NewEmbeddingMatcher(embedder analyzer.EmbeddingGenerator, threshold float64, metric Metric) *EmbeddingMatcher {
+	if metric == "" {
+		metric = DefaultMetric
+	}
 	return &EmbeddingMatcher{
 		embedder:         embedder,
 		threshold:        threshold,
+		metric:           metric,
+		shortQueries:     DefaultShortQueryPolicy,
 		questions:        make([]*questionEmbedding, 0),
 		generateOnTheFly: true, // Enable on-the-fly generation for now
 	}
@@ -52,7 +60,7 @@ func (m *EmbeddingMatcher) LoadQuestions(questions []*models.SavedInterviewQuest
 
 		// Try to use stored embedding first
 		if len(q.QuestionEmbedding) > 0 {
-			embedding, err = deserializeEmbedding(q.QuestionEmbedding)
+			embedding, err = DeserializeEmbedding(q.QuestionEmbedding)
 			if err != nil {
 				// If deserialization fails and on-the-fly generation is enabled, generate new embedding
 				if m.generateOnTheFly {
@@ -85,7 +93,8 @@ func (m *EmbeddingMatcher) LoadQuestions(questions []*models.SavedInterviewQuest
 	return nil
 }
 
-// FindMatch searches for the best matching question using cosine similarity
+// FindMatch searches for the best matching question using the matcher's metric.
+// Queries shorter than the short query policy allows are not matched at all.
 func (m *EmbeddingMatcher) FindMatch(ctx context.Context, query string) (*MatchResult, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -94,24 +103,33 @@ func (m *EmbeddingMatcher) FindMatch(ctx context.Context, query string) (*MatchR
 		return &MatchResult{Found: false}, nil
 	}
 
+	words := countWords(query)
+	if words < m.shortQueries.MinWords {
+		return &MatchResult{Found: false, TooShort: true}, nil
+	}
+
 	// Generate embedding for the query
 	queryEmbedding, err := m.generateEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Find the best match using cosine similarity
+	// Find the best match; scores can be below -1 for dot and euclidean
 	var bestMatch *questionEmbedding
-	var bestSimilarity float64 = -1.0
+	var bestSimilarity float64
 
 	for _, q := range m.questions {
-		similarity := cosineSimilarity(queryEmbedding, q.Embedding)
-		if similarity > bestSimilarity {
+		similarity := m.metric.Similarity(queryEmbedding, q.Embedding)
+		if bestMatch == nil || similarity > bestSimilarity {
 			bestSimilarity = similarity
 			bestMatch = q
 		}
 	}
 
+	if words < m.shortQueries.ShortWords {
+		bestSimilarity -= m.shortQueries.Penalty
+	}
+
 	// Check if best match exceeds threshold
 	if bestMatch != nil && bestSimilarity >= m.threshold {
 		return &MatchResult{
@@ -123,7 +141,10 @@ func (m *EmbeddingMatcher) FindMatch(ctx context.Context, query string) (*MatchR
 		}, nil
 	}
 
+	// Report the closest question so near misses can be inspected
 	return &MatchResult{
+		Question:   bestMatch.Question,
+		QuestionID: bestMatch.QuestionID,
 		Similarity: bestSimilarity,
 		Found:      false,
 	}, nil
@@ -136,6 +157,11 @@ func (m *EmbeddingMatcher) GetThreshold() float64 {
 	return m.threshold
 }
 
+// Metric returns the similarity metric
+func (m *EmbeddingMatcher) Metric() Metric {
+	return m.metric
+}
+
 // SetThreshold updates the similarity threshold
 func (m *EmbeddingMatcher) SetThreshold(threshold float64) {
 	m.mu.Lock()
@@ -143,6 +169,21 @@ func (m *EmbeddingMatcher) SetThreshold(threshold float64) {
 	m.threshold = threshold
 }
 
+// ShortQueryPolicy returns how short queries are treated
+func (m *EmbeddingMatcher) ShortQueryPolicy() ShortQueryPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.shortQueries
+}
+
+// SetShortQueryPolicy changes how short queries are treated (DefaultShortQueryPolicy
+// unless set)
+func (m *EmbeddingMatcher) SetShortQueryPolicy(policy ShortQueryPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shortQueries = policy
+}
+
 // Clear removes all loaded questions from memory
 func (m *EmbeddingMatcher) Clear() {
 	m.mu.Lock()
@@ -200,8 +241,8 @@ func SerializeEmbedding(embedding []float32) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// deserializeEmbedding converts bytes back to a float32 slice
-func deserializeEmbedding(data []byte) ([]float32, error) {
+// DeserializeEmbedding converts bytes stored by SerializeEmbedding back to a float32 slice
+func DeserializeEmbedding(data []byte) ([]float32, error) {
 	if len(data)%4 != 0 {
 		return nil, fmt.Errorf("invalid embedding data length: %d", len(data))
 	}
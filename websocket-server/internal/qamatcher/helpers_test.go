@@ -0,0 +1,85 @@
+package qamatcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// stubEmbedder embeds texts with fixed vectors, failing for texts it has none for
+type stubEmbedder struct {
+	analyzer.EmbeddingGenerator
+
+	vectors map[string][]float32
+}
+
+func (e *stubEmbedder) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	vector, ok := e.vectors[text]
+	if !ok {
+		return nil, fmt.Errorf("no vector for %q", text)
+	}
+	return vector, nil
+}
+
+// fakeQuestionStore keeps saved questions and their embeddings in memory. Methods a
+// test needs but the fake doesn't implement panic through the embedded nil interface.
+type fakeQuestionStore struct {
+	repository.SavedQuestionRepository
+
+	mu        sync.Mutex
+	questions map[int64]*models.SavedInterviewQuestion
+	fetches   int // Calls of GetQuestionsWithoutEmbedding
+}
+
+// newFakeQuestionStore stores questions with the given texts, IDs from 1, without embeddings
+func newFakeQuestionStore(texts ...string) *fakeQuestionStore {
+	store := &fakeQuestionStore{questions: make(map[int64]*models.SavedInterviewQuestion)}
+	for i, text := range texts {
+		id := int64(i + 1)
+		store.questions[id] = &models.SavedInterviewQuestion{ID: id, Question: text}
+	}
+	return store
+}
+
+func (f *fakeQuestionStore) GetQuestionsWithoutEmbedding(ctx context.Context, afterID int64, limit int) ([]*models.SavedInterviewQuestion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetches++
+
+	var found []*models.SavedInterviewQuestion
+	for id, q := range f.questions {
+		if id > afterID && len(q.QuestionEmbedding) == 0 {
+			copied := *q
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (f *fakeQuestionStore) UpdateQuestionEmbedding(ctx context.Context, id int64, embedding []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	q, ok := f.questions[id]
+	if !ok {
+		return fmt.Errorf("question %d not found", id)
+	}
+	q.QuestionEmbedding = embedding
+	return nil
+}
+
+// embedding returns the stored embedding of a question, nil if it has none
+func (f *fakeQuestionStore) embedding(id int64) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.questions[id].QuestionEmbedding
+}
@@ -8,11 +8,12 @@ import (
 
 // MatchResult represents a matched Q&A pair with similarity score
 type MatchResult struct {
-	Question   string  // The matched question
-	Answer     string  // The corresponding answer
-	QuestionID string  // ID of the matched question
-	Similarity float64 // Similarity score (0-1)
+	Question   string  // The matched question, or the closest one when none cleared the threshold
+	Answer     string  // The corresponding answer, only set when Found
+	QuestionID string  // ID of the matched or closest question
+	Similarity float64 // Similarity score on the matcher's metric scale (0-1 for cosine matches)
 	Found      bool    // Whether a match was found
+	TooShort   bool    // Whether the query had too few words to be matched; the other fields are then empty
 }
 
 // QAMatcher defines the interface for Q&A matching strategies
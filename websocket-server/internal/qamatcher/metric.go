@@ -0,0 +1,111 @@
+package qamatcher
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Metric selects how the similarity of two embeddings is scored. Higher scores are
+// always more similar, and a match requires a score at or above the threshold, so the
+// threshold's scale depends on the metric:
+//
+//   - MetricCosine: cosine of the angle between the vectors, from -1 to 1 (e.g. 0.75)
+//   - MetricDot: dot product; equal to cosine for unit-length vectors but cheaper,
+//     unbounded otherwise
+//   - MetricEuclidean: negative Euclidean distance, at most 0 (e.g. -0.7 matches
+//     vectors at most 0.7 apart)
+type Metric string
+
+// Supported similarity metrics
+const (
+	MetricCosine    Metric = "cosine"
+	MetricDot       Metric = "dot"
+	MetricEuclidean Metric = "euclidean"
+)
+
+// DefaultMetric is used when no metric is chosen
+const DefaultMetric = MetricCosine
+
+// DefaultThreshold is the score a match needs when no threshold is chosen: 0.75 for
+// cosine and dot, and -0.7 for euclidean, which is about the same bar for unit-length
+// embeddings.
+func (m Metric) DefaultThreshold() float64 {
+	if m == MetricEuclidean {
+		return -0.7
+	}
+	return 0.75
+}
+
+// ValidateThreshold reports whether a threshold is on the metric's scale. Cosine
+// thresholds must be between -1 and 1 and euclidean thresholds at most 0; a threshold
+// outside that range would match everything or nothing.
+func (m Metric) ValidateThreshold(threshold float64) error {
+	if math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return fmt.Errorf("threshold must be a finite number")
+	}
+	switch m {
+	case MetricCosine:
+		if threshold < -1 || threshold > 1 {
+			return fmt.Errorf("cosine threshold %g is outside [-1, 1]", threshold)
+		}
+	case MetricEuclidean:
+		if threshold > 0 {
+			return fmt.Errorf("euclidean threshold %g is above 0 (it is a negated distance)", threshold)
+		}
+	}
+	return nil
+}
+
+// ParseMetric maps a metric name to a Metric. An empty name is DefaultMetric.
+func ParseMetric(name string) (Metric, error) {
+	switch metric := Metric(strings.ToLower(strings.TrimSpace(name))); metric {
+	case "":
+		return DefaultMetric, nil
+	case MetricCosine, MetricDot, MetricEuclidean:
+		return metric, nil
+	default:
+		return "", fmt.Errorf("unknown similarity metric %q (supported: cosine, dot, euclidean)", name)
+	}
+}
+
+// Similarity scores two embeddings with the metric. Embeddings of different
+// dimensions score 0 for cosine and dot, and as far apart as possible for euclidean.
+func (m Metric) Similarity(a, b []float32) float64 {
+	switch m {
+	case MetricDot:
+		return dotProduct(a, b)
+	case MetricEuclidean:
+		return negativeEuclidean(a, b)
+	default:
+		return cosineSimilarity(a, b)
+	}
+}
+
+// dotProduct calculates the dot product of two embeddings
+func dotProduct(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// negativeEuclidean calculates the negated Euclidean distance between two embeddings,
+// so that closer vectors score higher
+func negativeEuclidean(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return -math.MaxFloat64
+	}
+
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return -math.Sqrt(sum)
+}
@@ -0,0 +1,137 @@
+package qamatcher
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Metric
+		wantErr bool
+	}{
+		{"", MetricCosine, false},
+		{"cosine", MetricCosine, false},
+		{" DOT ", MetricDot, false},
+		{"Euclidean", MetricEuclidean, false},
+		{"manhattan", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMetric(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseMetric(%q) = %q, %v; want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMetricsRankQuestions(t *testing.T) {
+	// Against the query (1, 0) each metric prefers a different question: "aligned"
+	// points the same way, "long" has the largest dot product and "near" is closest
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"tell me":  {1, 0},
+		"aligned":  {0.5, 0},
+		"long":     {2, 1.5},
+		"near":     {0.9, 0.3},
+		"opposite": {-1, 0},
+	}}
+	questions := []*models.SavedInterviewQuestion{
+		{QuestionID: "aligned", Question: "aligned"},
+		{QuestionID: "long", Question: "long"},
+		{QuestionID: "near", Question: "near"},
+		{QuestionID: "opposite", Question: "opposite"},
+	}
+
+	tests := []struct {
+		metric         Metric
+		wantID         string
+		wantSimilarity float64
+	}{
+		{MetricCosine, "aligned", 1},
+		{MetricDot, "long", 2},
+		{MetricEuclidean, "near", -math.Sqrt(0.1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.metric), func(t *testing.T) {
+			matcher := NewEmbeddingMatcher(embedder, -100, tt.metric)
+			if err := matcher.LoadQuestions(questions); err != nil {
+				t.Fatalf("LoadQuestions: %v", err)
+			}
+
+			result, err := matcher.FindMatch(context.Background(), "tell me")
+			if err != nil {
+				t.Fatalf("FindMatch: %v", err)
+			}
+			if !result.Found || result.QuestionID != tt.wantID {
+				t.Fatalf("matched %q (found %v), want %q", result.QuestionID, result.Found, tt.wantID)
+			}
+			if math.Abs(result.Similarity-tt.wantSimilarity) > 1e-6 {
+				t.Errorf("similarity = %v, want %v", result.Similarity, tt.wantSimilarity)
+			}
+		})
+	}
+}
+
+func TestDefaultThresholdMatchesOnEveryMetric(t *testing.T) {
+	// Unit vectors with cosine 0.8 (distance 0.63) should match and ones with cosine
+	// 0.6 (distance 0.89) shouldn't, whatever the metric
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"tell me": {1, 0},
+		"similar": {0.8, 0.6},
+		"distant": {0.6, 0.8},
+	}}
+
+	for _, metric := range []Metric{MetricCosine, MetricDot, MetricEuclidean} {
+		t.Run(string(metric), func(t *testing.T) {
+			if err := metric.ValidateThreshold(metric.DefaultThreshold()); err != nil {
+				t.Fatalf("default threshold is invalid: %v", err)
+			}
+
+			for question, wantFound := range map[string]bool{"similar": true, "distant": false} {
+				matcher := NewEmbeddingMatcher(embedder, metric.DefaultThreshold(), metric)
+				if err := matcher.LoadQuestions([]*models.SavedInterviewQuestion{{QuestionID: question, Question: question}}); err != nil {
+					t.Fatalf("LoadQuestions: %v", err)
+				}
+				result, err := matcher.FindMatch(context.Background(), "tell me")
+				if err != nil {
+					t.Fatalf("FindMatch: %v", err)
+				}
+				if result.Found != wantFound {
+					t.Errorf("%s: found = %v (similarity %v), want %v", question, result.Found, result.Similarity, wantFound)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateThreshold(t *testing.T) {
+	tests := []struct {
+		metric    Metric
+		threshold float64
+		wantErr   bool
+	}{
+		{MetricCosine, 0.75, false},
+		{MetricCosine, -1, false},
+		{MetricCosine, 1, false},
+		{MetricCosine, 1.5, true},
+		{MetricCosine, -1.01, true},
+		{MetricDot, 12, false},
+		{MetricDot, -12, false},
+		{MetricEuclidean, -0.7, false},
+		{MetricEuclidean, 0, false},
+		{MetricEuclidean, 0.75, true},
+		{MetricDot, math.NaN(), true},
+		{MetricEuclidean, math.Inf(-1), true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.metric.ValidateThreshold(tt.threshold); (err != nil) != tt.wantErr {
+			t.Errorf("%s.ValidateThreshold(%v) = %v, want error %v", tt.metric, tt.threshold, err, tt.wantErr)
+		}
+	}
+}
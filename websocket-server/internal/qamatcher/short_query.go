@@ -0,0 +1,34 @@
+package qamatcher
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultMinQueryWords is how many words a query needs before a match is attempted.
+// Greetings and acknowledgements such as "hi" or "ok" carry too little meaning for
+// their embedding to be compared, and can clear the threshold against some question.
+const DefaultMinQueryWords = 2
+
+// ShortQueryPolicy keeps very short queries from matching stored questions by chance
+type ShortQueryPolicy struct {
+	MinWords int // Queries with fewer words are not matched; 0 or less matches every query
+
+	// Queries with at least MinWords but fewer than ShortWords words have Penalty
+	// subtracted from their similarity, so they need a closer match. The penalty is on
+	// the metric's scale, like the threshold; 0 disables it.
+	ShortWords int
+	Penalty    float64
+}
+
+// DefaultShortQueryPolicy gates queries below DefaultMinQueryWords and doesn't penalize
+// longer ones
+var DefaultShortQueryPolicy = ShortQueryPolicy{MinWords: DefaultMinQueryWords}
+
+// countWords counts the runs of letters and digits in a query, so punctuation such as
+// "hi!" or "?" doesn't count as a word
+func countWords(query string) int {
+	return len(strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
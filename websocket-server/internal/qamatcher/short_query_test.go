@@ -0,0 +1,78 @@
+package qamatcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"hi", 1},
+		{"hi!", 1},
+		{"?", 0},
+		{"", 0},
+		{"  ok, thanks  ", 2},
+		{"Why Go?", 2},
+		{"Why C++, not Go?", 4},
+	}
+
+	for _, tt := range tests {
+		if got := countWords(tt.query); got != tt.want {
+			t.Errorf("countWords(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFindMatchShortQueries(t *testing.T) {
+	// Every query embeds to the vector of the stored question except "close", which has
+	// cosine 0.8 to it. Queries without a vector fail if they are embedded.
+	embedder := &stubEmbedder{vectors: map[string][]float32{
+		"Tell me about yourself": {1, 0},
+		"Tell me more":           {1, 0},
+		"why Go":                 {0.8, 0.6},
+		"why did you pick Go":    {0.8, 0.6},
+		"ok":                     {1, 0},
+	}}
+	questions := []*models.SavedInterviewQuestion{{QuestionID: "q_intro", Question: "Tell me about yourself"}}
+
+	tests := []struct {
+		name         string
+		policy       *ShortQueryPolicy // nil for the default
+		query        string
+		wantFound    bool
+		wantTooShort bool
+	}{
+		{"greeting never matched", nil, "hi", false, true},
+		{"greeting with punctuation", nil, "hi!", false, true},
+		{"identical short query not matched", nil, "ok", false, true},
+		{"substantive query matched", nil, "Tell me more", true, false},
+		{"short query penalized", &ShortQueryPolicy{MinWords: 2, ShortWords: 4, Penalty: 0.3}, "why Go", false, false},
+		{"longer query not penalized", &ShortQueryPolicy{MinWords: 2, ShortWords: 4, Penalty: 0.3}, "why did you pick Go", true, false},
+		{"gate disabled", &ShortQueryPolicy{}, "ok", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewEmbeddingMatcher(embedder, 0.75, MetricCosine)
+			if tt.policy != nil {
+				matcher.SetShortQueryPolicy(*tt.policy)
+			}
+			if err := matcher.LoadQuestions(questions); err != nil {
+				t.Fatalf("LoadQuestions: %v", err)
+			}
+
+			result, err := matcher.FindMatch(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("FindMatch: %v", err)
+			}
+			if result.Found != tt.wantFound || result.TooShort != tt.wantTooShort {
+				t.Errorf("found = %v, too short = %v (similarity %v), want %v, %v", result.Found, result.TooShort, result.Similarity, tt.wantFound, tt.wantTooShort)
+			}
+		})
+	}
+}
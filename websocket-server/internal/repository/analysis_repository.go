@@ -15,14 +15,20 @@ type AnalysisRepository interface {
 	GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error)
 	UpdateJobStatus(ctx context.Context, jobID string, status string, progress int, currentStep string) error
 	UpdateExtractedText(ctx context.Context, jobID string, extractedText string) error
-	UpdateJobError(ctx context.Context, jobID string, errorMessage string) error
+	UpdateJobError(ctx context.Context, jobID string, errorCode string, errorMessage string) error // errorCode is one of the models.JobError* codes
+	FlagJobForReview(ctx context.Context, jobID string, reason string) error
+	DeadLetterJob(ctx context.Context, jobID string, reason string) error // Terminal: the job is not retried again
+	UpdateJobBasics(ctx context.Context, jobID string, basics *models.ProfileBasics) error
+	GetJobBasics(ctx context.Context, jobID string) (*models.ProfileBasics, error)
 	CompleteJob(ctx context.Context, jobID string) error
 
 	// Profile operations
-	CreateProfile(ctx context.Context, profile *models.UserProfile) error
+	SaveProfile(ctx context.Context, profile *models.UserProfile) error // Creates or replaces the profile of profile.JobID
 	GetProfileByJobID(ctx context.Context, jobID string) (*models.UserProfile, error)
 	GetProfileByUploadID(ctx context.Context, uploadID int) (*models.UserProfile, error)
+	GetProfilesByJobIDs(ctx context.Context, jobIDs []string) (map[string]*models.UserProfile, error) // Jobs without a profile are absent from the map
 	UpdateProfile(ctx context.Context, profile *models.UserProfile) error
+	PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) error // Updates only the fields the patch sets, at confidence 1
 
 	// Delete operations
 	DeleteJobsByUploadID(ctx context.Context, uploadID int) error
@@ -31,5 +37,12 @@ type AnalysisRepository interface {
 	BatchDeleteJobs(ctx context.Context, jobIDs []string) ([]string, error)
 
 	// Retry operations
-	ResetJobForRetry(ctx context.Context, jobID string) error
+	ResetJobForRetry(ctx context.Context, jobID string) error // Also increments the job's retry count
+
+	// Failure operations, covering failed and dead-lettered jobs
+	CountFailedJobsByCategory(ctx context.Context) ([]models.FailedJobGroup, error)                      // Largest category first
+	ListFailedJobs(ctx context.Context, category string, limit, offset int) ([]*models.FailedJob, error) // Most recently updated first; "" lists every category
+
+	// Aggregate operations
+	GetUserAnalytics(ctx context.Context, userID int, topSkills int) (*models.UserAnalytics, error)
 }
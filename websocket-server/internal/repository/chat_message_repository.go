@@ -29,6 +29,27 @@ type ChatMessageRepository interface {
 	// CountMessages counts total messages for a user
 	CountMessages(ctx context.Context, userID int) (int, error)
 
+	// CountMessagesBySession counts messages in a specific session
+	CountMessagesBySession(ctx context.Context, sessionID string) (int, error)
+
+	// CountConversation counts messages between two users
+	CountConversation(ctx context.Context, userID1, userID2 int) (int, error)
+
+	// FindMessages retrieves the messages matching a filter with pagination
+	FindMessages(ctx context.Context, filter MessageFilter, limit, offset int) ([]*models.ChatMessage, error)
+
+	// CountMatchingMessages counts the messages matching a filter
+	CountMatchingMessages(ctx context.Context, filter MessageFilter) (int, error)
+
 	// DeleteMessage deletes a message by ID
 	DeleteMessage(ctx context.Context, id int64) error
 }
+
+// MessageFilter selects chat messages. Zero-valued fields don't filter.
+type MessageFilter struct {
+	UserID      int                // Messages sent or received by the user
+	WithUserID  *int               // With UserID, only messages exchanged between the two users
+	SessionIDs  []string           // Messages in any of these sessions
+	MsgType     models.MessageType // Messages of this type
+	OldestFirst bool               // Order by creation time ascending instead of descending
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/lib/pq"
 	"github.com/your-org/websocket-server/internal/repository"
@@ -24,11 +26,16 @@ func NewAnalysisRepository(db *sql.DB) repository.AnalysisRepository {
 // CreateJob creates a new analysis job
 func (r *AnalysisPostgresRepository) CreateJob(ctx context.Context, job *models.AnalysisJob) error {
 	query := `
-		INSERT INTO analysis_jobs (job_id, upload_id, user_id, status, progress, current_step)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO analysis_jobs (job_id, upload_id, user_id, status, progress, current_step, additional_upload_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
+	additionalUploadIDs := job.AdditionalUploadIDs
+	if additionalUploadIDs == nil {
+		additionalUploadIDs = pq.Int64Array{}
+	}
+
 	err := r.db.QueryRowContext(
 		ctx,
 		query,
@@ -38,6 +45,7 @@ func (r *AnalysisPostgresRepository) CreateJob(ctx context.Context, job *models.
 		job.Status,
 		job.Progress,
 		job.CurrentStep,
+		additionalUploadIDs,
 	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
 
 	if err != nil {
@@ -51,7 +59,8 @@ func (r *AnalysisPostgresRepository) CreateJob(ctx context.Context, job *models.
 func (r *AnalysisPostgresRepository) GetJobByID(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
 	query := `
 		SELECT id, job_id, upload_id, user_id, status, progress, current_step,
-		       extracted_text, error_message, created_at, updated_at, completed_at
+		       extracted_text, error_message, error_code, created_at, updated_at, completed_at,
+		       additional_upload_ids, retry_count
 		FROM analysis_jobs
 		WHERE job_id = $1
 	`
@@ -67,9 +76,12 @@ func (r *AnalysisPostgresRepository) GetJobByID(ctx context.Context, jobID strin
 		&job.CurrentStep,
 		&job.ExtractedText,
 		&job.ErrorMessage,
+		&job.ErrorCode,
 		&job.CreatedAt,
 		&job.UpdatedAt,
 		&job.CompletedAt,
+		&job.AdditionalUploadIDs,
+		&job.RetryCount,
 	)
 
 	if err == sql.ErrNoRows {
@@ -86,7 +98,8 @@ func (r *AnalysisPostgresRepository) GetJobByID(ctx context.Context, jobID strin
 func (r *AnalysisPostgresRepository) GetJobsByUserID(ctx context.Context, userID int) ([]*models.AnalysisJob, error) {
 	query := `
 		SELECT id, job_id, upload_id, user_id, status, progress, current_step,
-		       extracted_text, error_message, created_at, updated_at, completed_at
+		       extracted_text, error_message, error_code, created_at, updated_at, completed_at,
+		       additional_upload_ids, retry_count
 		FROM analysis_jobs
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -111,9 +124,12 @@ func (r *AnalysisPostgresRepository) GetJobsByUserID(ctx context.Context, userID
 			&job.CurrentStep,
 			&job.ExtractedText,
 			&job.ErrorMessage,
+			&job.ErrorCode,
 			&job.CreatedAt,
 			&job.UpdatedAt,
 			&job.CompletedAt,
+			&job.AdditionalUploadIDs,
+			&job.RetryCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -132,7 +148,8 @@ func (r *AnalysisPostgresRepository) GetJobsByUserID(ctx context.Context, userID
 func (r *AnalysisPostgresRepository) GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error) {
 	query := `
 		SELECT id, job_id, upload_id, user_id, status, progress, current_step,
-		       extracted_text, error_message, created_at, updated_at, completed_at
+		       extracted_text, error_message, error_code, created_at, updated_at, completed_at,
+		       additional_upload_ids, retry_count
 		FROM analysis_jobs
 		WHERE upload_id = $1
 		ORDER BY created_at DESC
@@ -157,9 +174,12 @@ func (r *AnalysisPostgresRepository) GetJobsByUploadID(ctx context.Context, uplo
 			&job.CurrentStep,
 			&job.ExtractedText,
 			&job.ErrorMessage,
+			&job.ErrorCode,
 			&job.CreatedAt,
 			&job.UpdatedAt,
 			&job.CompletedAt,
+			&job.AdditionalUploadIDs,
+			&job.RetryCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -224,16 +244,16 @@ func (r *AnalysisPostgresRepository) UpdateJobStatus(ctx context.Context, jobID
 	return nil
 }
 
-// UpdateJobError updates the job with an error message and sets status to failed
-func (r *AnalysisPostgresRepository) UpdateJobError(ctx context.Context, jobID string, errorMessage string) error {
+// UpdateJobError updates the job with an error code and message and sets status to failed
+func (r *AnalysisPostgresRepository) UpdateJobError(ctx context.Context, jobID string, errorCode string, errorMessage string) error {
 	query := `
 		UPDATE analysis_jobs
-		SET status = 'failed', error_message = $1, updated_at = CURRENT_TIMESTAMP,
+		SET status = 'failed', error_code = $1, error_message = $2, updated_at = CURRENT_TIMESTAMP,
 		    completed_at = CURRENT_TIMESTAMP
-		WHERE job_id = $2
+		WHERE job_id = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, errorMessage, jobID)
+	result, err := r.db.ExecContext(ctx, query, errorCode, errorMessage, jobID)
 	if err != nil {
 		return fmt.Errorf("failed to update job error: %w", err)
 	}
@@ -250,6 +270,113 @@ func (r *AnalysisPostgresRepository) UpdateJobError(ctx context.Context, jobID s
 	return nil
 }
 
+// FlagJobForReview stops a job before analysis and marks it for manual review
+func (r *AnalysisPostgresRepository) FlagJobForReview(ctx context.Context, jobID string, reason string) error {
+	query := `
+		UPDATE analysis_jobs
+		SET status = 'needs_review', error_message = $1, current_step = 'Waiting for review',
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE job_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, reason, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to flag job for review: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	return nil
+}
+
+// DeadLetterJob gives up on a job that keeps failing, marking it dead_lettered for manual review
+func (r *AnalysisPostgresRepository) DeadLetterJob(ctx context.Context, jobID string, reason string) error {
+	query := `
+		UPDATE analysis_jobs
+		SET status = 'dead_lettered', error_message = $1, current_step = 'Retry limit reached',
+		    updated_at = CURRENT_TIMESTAMP, completed_at = COALESCE(completed_at, CURRENT_TIMESTAMP)
+		WHERE job_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, reason, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	return nil
+}
+
+// UpdateJobBasics stores the early results of a two-pass analysis
+func (r *AnalysisPostgresRepository) UpdateJobBasics(ctx context.Context, jobID string, basics *models.ProfileBasics) error {
+	basicsJSON, err := json.Marshal(basics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal basics: %w", err)
+	}
+
+	query := `
+		UPDATE analysis_jobs
+		SET basics = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE job_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, basicsJSON, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to update job basics: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+
+	return nil
+}
+
+// GetJobBasics retrieves the early results of a two-pass analysis, or nil if none are stored
+func (r *AnalysisPostgresRepository) GetJobBasics(ctx context.Context, jobID string) (*models.ProfileBasics, error) {
+	query := `SELECT basics FROM analysis_jobs WHERE job_id = $1`
+
+	var basicsJSON []byte
+	err := r.db.QueryRowContext(ctx, query, jobID).Scan(&basicsJSON)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job basics: %w", err)
+	}
+
+	if basicsJSON == nil {
+		return nil, nil
+	}
+
+	var basics models.ProfileBasics
+	if err := json.Unmarshal(basicsJSON, &basics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal basics: %w", err)
+	}
+
+	return &basics, nil
+}
+
 // CompleteJob marks a job as completed
 func (r *AnalysisPostgresRepository) CompleteJob(ctx context.Context, jobID string) error {
 	query := `
@@ -276,8 +403,41 @@ func (r *AnalysisPostgresRepository) CompleteJob(ctx context.Context, jobID stri
 	return nil
 }
 
-// CreateProfile creates a new user profile
-func (r *AnalysisPostgresRepository) CreateProfile(ctx context.Context, profile *models.UserProfile) error {
+// saveProfileQuery inserts a profile, or replaces every field of the profile its job
+// already has. The job's profile keeps its ID and creation time.
+const saveProfileQuery = `
+	INSERT INTO user_profile (
+		upload_id, job_id, name, email, phone, linkedin_url,
+		age, race, location, total_work_years,
+		skills, experience, education, summary, job_recommendations,
+		strengths, weaknesses, field_confidence, reported_work_years
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	ON CONFLICT (job_id) DO UPDATE SET
+		upload_id = EXCLUDED.upload_id,
+		name = EXCLUDED.name,
+		email = EXCLUDED.email,
+		phone = EXCLUDED.phone,
+		linkedin_url = EXCLUDED.linkedin_url,
+		age = EXCLUDED.age,
+		race = EXCLUDED.race,
+		location = EXCLUDED.location,
+		total_work_years = EXCLUDED.total_work_years,
+		skills = EXCLUDED.skills,
+		experience = EXCLUDED.experience,
+		education = EXCLUDED.education,
+		summary = EXCLUDED.summary,
+		job_recommendations = EXCLUDED.job_recommendations,
+		strengths = EXCLUDED.strengths,
+		weaknesses = EXCLUDED.weaknesses,
+		field_confidence = EXCLUDED.field_confidence,
+		reported_work_years = EXCLUDED.reported_work_years,
+		updated_at = NOW()
+	RETURNING id, created_at, updated_at
+`
+
+// SaveProfile stores the profile of a job, replacing any profile the job already has,
+// so a job that reaches its save step more than once still has a single profile
+func (r *AnalysisPostgresRepository) SaveProfile(ctx context.Context, profile *models.UserProfile) error {
 	// Convert struct fields to JSONB
 	skillsJSON, err := json.Marshal(profile.Skills)
 	if err != nil {
@@ -309,19 +469,18 @@ func (r *AnalysisPostgresRepository) CreateProfile(ctx context.Context, profile
 		return fmt.Errorf("failed to marshal weaknesses: %w", err)
 	}
 
-	query := `
-		INSERT INTO user_profile (
-			upload_id, job_id, name, email, phone, linkedin_url,
-			age, race, location, total_work_years,
-			skills, experience, education, summary, job_recommendations,
-			strengths, weaknesses
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
-		RETURNING id, created_at, updated_at
-	`
+	confidence := profile.FieldConfidence
+	if confidence == nil {
+		confidence = map[string]*float64{}
+	}
+	confidenceJSON, err := json.Marshal(confidence)
+	if err != nil {
+		return fmt.Errorf("failed to marshal field confidence: %w", err)
+	}
 
 	err = r.db.QueryRowContext(
 		ctx,
-		query,
+		saveProfileQuery,
 		profile.UploadID,
 		profile.JobID,
 		profile.Name,
@@ -339,30 +498,36 @@ func (r *AnalysisPostgresRepository) CreateProfile(ctx context.Context, profile
 		recommendationsJSON,
 		strengthsJSON,
 		weaknessesJSON,
+		confidenceJSON,
+		profile.ReportedWorkYears,
 	).Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
 
 	if err != nil {
-		return fmt.Errorf("failed to create user profile: %w", err)
+		return fmt.Errorf("failed to save user profile: %w", err)
 	}
 
 	return nil
 }
 
-// GetProfileByJobID retrieves a user profile by job ID
-func (r *AnalysisPostgresRepository) GetProfileByJobID(ctx context.Context, jobID string) (*models.UserProfile, error) {
-	query := `
-		SELECT id, upload_id, job_id, name, email, phone, linkedin_url,
-		       age, race, location, total_work_years,
-		       skills, experience, education, summary, job_recommendations,
-		       strengths, weaknesses, created_at, updated_at
-		FROM user_profile
-		WHERE job_id = $1
-	`
+// profileColumns are the user_profile columns read by scanProfile
+const profileColumns = `
+	id, upload_id, job_id, name, email, phone, linkedin_url,
+	age, race, location, total_work_years,
+	skills, experience, education, summary, job_recommendations,
+	strengths, weaknesses, field_confidence, reported_work_years, created_at, updated_at
+`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanProfile scans a row of profileColumns, unmarshaling the JSONB fields
+func scanProfile(row rowScanner) (*models.UserProfile, error) {
 	profile := &models.UserProfile{}
-	var skillsJSON, experienceJSON, educationJSON, recommendationsJSON, strengthsJSON, weaknessesJSON []byte
+	var skillsJSON, experienceJSON, educationJSON, recommendationsJSON, strengthsJSON, weaknessesJSON, confidenceJSON []byte
 
-	err := r.db.QueryRowContext(ctx, query, jobID).Scan(
+	err := row.Scan(
 		&profile.ID,
 		&profile.UploadID,
 		&profile.JobID,
@@ -381,15 +546,13 @@ func (r *AnalysisPostgresRepository) GetProfileByJobID(ctx context.Context, jobI
 		&recommendationsJSON,
 		&strengthsJSON,
 		&weaknessesJSON,
+		&confidenceJSON,
+		&profile.ReportedWorkYears,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("profile not found for job: %s", jobID)
-	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get profile: %w", err)
+		return nil, err
 	}
 
 	// Unmarshal JSONB fields
@@ -411,16 +574,64 @@ func (r *AnalysisPostgresRepository) GetProfileByJobID(ctx context.Context, jobI
 	if err := json.Unmarshal(weaknessesJSON, &profile.Weaknesses); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal weaknesses: %w", err)
 	}
+	if err := json.Unmarshal(confidenceJSON, &profile.FieldConfidence); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal field confidence: %w", err)
+	}
 
 	return profile, nil
 }
 
+// GetProfileByJobID retrieves a user profile by job ID
+func (r *AnalysisPostgresRepository) GetProfileByJobID(ctx context.Context, jobID string) (*models.UserProfile, error) {
+	query := `SELECT ` + profileColumns + ` FROM user_profile WHERE job_id = $1`
+
+	profile, err := scanProfile(r.db.QueryRowContext(ctx, query, jobID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("profile not found for job: %s", jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// GetProfilesByJobIDs retrieves the profiles of several jobs in one query, keyed by job ID.
+// Jobs without a profile are absent from the result.
+func (r *AnalysisPostgresRepository) GetProfilesByJobIDs(ctx context.Context, jobIDs []string) (map[string]*models.UserProfile, error) {
+	profiles := make(map[string]*models.UserProfile, len(jobIDs))
+	if len(jobIDs) == 0 {
+		return profiles, nil
+	}
+
+	query := `SELECT ` + profileColumns + ` FROM user_profile WHERE job_id = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(jobIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		profiles[profile.JobID] = profile
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating profile rows: %w", err)
+	}
+
+	return profiles, nil
+}
+
 // GetProfileByUploadID retrieves a user profile by upload ID
 func (r *AnalysisPostgresRepository) GetProfileByUploadID(ctx context.Context, uploadID int) (*models.UserProfile, error) {
 	query := `
 		SELECT id, upload_id, job_id, age, race, location, total_work_years,
 		       skills, experience, education, summary, job_recommendations,
-		       strengths, weaknesses, created_at, updated_at
+		       strengths, weaknesses, field_confidence, reported_work_years, created_at, updated_at
 		FROM user_profile
 		WHERE upload_id = $1
 		ORDER BY created_at DESC
@@ -428,7 +639,7 @@ func (r *AnalysisPostgresRepository) GetProfileByUploadID(ctx context.Context, u
 	`
 
 	profile := &models.UserProfile{}
-	var skillsJSON, experienceJSON, educationJSON, recommendationsJSON, strengthsJSON, weaknessesJSON []byte
+	var skillsJSON, experienceJSON, educationJSON, recommendationsJSON, strengthsJSON, weaknessesJSON, confidenceJSON []byte
 
 	err := r.db.QueryRowContext(ctx, query, uploadID).Scan(
 		&profile.ID,
@@ -445,6 +656,8 @@ func (r *AnalysisPostgresRepository) GetProfileByUploadID(ctx context.Context, u
 		&recommendationsJSON,
 		&strengthsJSON,
 		&weaknessesJSON,
+		&confidenceJSON,
+		&profile.ReportedWorkYears,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
 	)
@@ -475,6 +688,9 @@ func (r *AnalysisPostgresRepository) GetProfileByUploadID(ctx context.Context, u
 	if err := json.Unmarshal(weaknessesJSON, &profile.Weaknesses); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal weaknesses: %w", err)
 	}
+	if err := json.Unmarshal(confidenceJSON, &profile.FieldConfidence); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal field confidence: %w", err)
+	}
 
 	return profile, nil
 }
@@ -512,18 +728,32 @@ func (r *AnalysisPostgresRepository) UpdateProfile(ctx context.Context, profile
 		return fmt.Errorf("failed to marshal weaknesses: %w", err)
 	}
 
+	confidence := profile.FieldConfidence
+	if confidence == nil {
+		confidence = map[string]*float64{}
+	}
+	confidenceJSON, err := json.Marshal(confidence)
+	if err != nil {
+		return fmt.Errorf("failed to marshal field confidence: %w", err)
+	}
+
 	query := `
 		UPDATE user_profile
-		SET age = $1, race = $2, location = $3, total_work_years = $4,
-		    skills = $5, experience = $6, education = $7, summary = $8,
-		    job_recommendations = $9, strengths = $10, weaknesses = $11,
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $12
+		SET name = $1, email = $2, phone = $3, linkedin_url = $4,
+		    age = $5, race = $6, location = $7, total_work_years = $8,
+		    skills = $9, experience = $10, education = $11, summary = $12,
+		    job_recommendations = $13, strengths = $14, weaknesses = $15,
+		    field_confidence = $16, reported_work_years = $17, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $18
 	`
 
 	result, err := r.db.ExecContext(
 		ctx,
 		query,
+		profile.Name,
+		profile.Email,
+		profile.Phone,
+		profile.LinkedInURL,
 		profile.Age,
 		profile.Race,
 		profile.Location,
@@ -535,6 +765,8 @@ func (r *AnalysisPostgresRepository) UpdateProfile(ctx context.Context, profile
 		recommendationsJSON,
 		strengthsJSON,
 		weaknessesJSON,
+		confidenceJSON,
+		profile.ReportedWorkYears,
 		profile.ID,
 	)
 
@@ -554,6 +786,99 @@ func (r *AnalysisPostgresRepository) UpdateProfile(ctx context.Context, profile
 	return nil
 }
 
+// PatchProfile updates the fields a patch sets on the profile of a job, leaving the other
+// columns untouched, so concurrent changes to them aren't overwritten. Patched fields are
+// entered by hand, so their confidence is set to 1.
+func (r *AnalysisPostgresRepository) PatchProfile(ctx context.Context, jobID string, patch *models.ProfilePatch) error {
+	query, args, err := patchProfileQuery(jobID, patch)
+	if err != nil || query == "" {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to patch profile: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("profile not found for job: %s", jobID)
+	}
+
+	return nil
+}
+
+// patchProfileQuery builds the UPDATE of PatchProfile, setting only the columns of the
+// fields the patch sets; query is empty when it sets none
+func patchProfileQuery(jobID string, patch *models.ProfilePatch) (query string, args []interface{}, err error) {
+	columns := []struct {
+		name  string
+		set   bool
+		value interface{}
+		jsonb bool
+	}{
+		{"name", patch.Name != nil, patch.Name, false},
+		{"email", patch.Email != nil, patch.Email, false},
+		{"phone", patch.Phone != nil, patch.Phone, false},
+		{"linkedin_url", patch.LinkedInURL != nil, patch.LinkedInURL, false},
+		{"age", patch.Age != nil, patch.Age, false},
+		{"race", patch.Race != nil, patch.Race, false},
+		{"location", patch.Location != nil, patch.Location, false},
+		{"total_work_years", patch.TotalWorkYears != nil, patch.TotalWorkYears, false},
+		{"skills", patch.Skills != nil, patch.Skills, true},
+		{"experience", patch.Experience != nil, patch.Experience, true},
+		{"education", patch.Education != nil, patch.Education, true},
+		{"summary", patch.Summary != nil, patch.Summary, false},
+		{"job_recommendations", patch.JobRecommendations != nil, patch.JobRecommendations, true},
+		{"strengths", patch.Strengths != nil, patch.Strengths, true},
+		{"weaknesses", patch.Weaknesses != nil, patch.Weaknesses, true},
+	}
+
+	var assignments []string
+	for _, column := range columns {
+		if !column.set {
+			continue
+		}
+		value := column.value
+		if column.jsonb {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to marshal %s: %w", column.name, err)
+			}
+			value = data
+		}
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column.name, len(args)))
+	}
+	if len(assignments) == 0 {
+		return "", nil, nil
+	}
+
+	confidence := make(map[string]float64)
+	for _, field := range patch.Fields() {
+		confidence[field] = 1
+	}
+	confidenceJSON, err := json.Marshal(confidence)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal field confidence: %w", err)
+	}
+	args = append(args, confidenceJSON)
+	assignments = append(assignments, fmt.Sprintf("field_confidence = COALESCE(field_confidence, '{}'::jsonb) || $%d::jsonb", len(args)))
+
+	args = append(args, jobID)
+	query = fmt.Sprintf(`
+		UPDATE user_profile
+		SET %s, updated_at = CURRENT_TIMESTAMP
+		WHERE job_id = $%d
+	`, strings.Join(assignments, ", "), len(args))
+
+	return query, args, nil
+}
+
 // DeleteJobsByUploadID deletes all analysis jobs for a specific upload
 func (r *AnalysisPostgresRepository) DeleteJobsByUploadID(ctx context.Context, uploadID int) error {
 	query := `DELETE FROM analysis_jobs WHERE upload_id = $1`
@@ -619,9 +944,12 @@ func (r *AnalysisPostgresRepository) ResetJobForRetry(ctx context.Context, jobID
 	jobQuery := `
 		UPDATE analysis_jobs
 		SET status = 'queued',
+		    retry_count = retry_count + 1,
 		    progress = 0,
 		    current_step = '',
 		    error_message = NULL,
+		    error_code = NULL,
+		    basics = NULL,
 		    completed_at = NULL,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE job_id = $1
@@ -644,6 +972,156 @@ func (r *AnalysisPostgresRepository) ResetJobForRetry(ctx context.Context, jobID
 	return nil
 }
 
+// failedJobStatuses are the statuses of jobs that stopped on an error
+var failedJobStatuses = []string{"failed", "dead_lettered"}
+
+// failedJobCategoryRules bucket a failed job's error message by the keywords it contains,
+// in order, the first matching rule winning. A rule matches messages containing one of
+// keywords and, if set, one of alsoKeywords. The messages are free text built from
+// wrapped errors, so this is a heuristic; messages of dead-lettered jobs keep the last
+// error and are bucketed the same way.
+var failedJobCategoryRules = []struct {
+	category     string
+	keywords     []string
+	alsoKeywords []string
+}{
+	{models.ErrorCategoryPasswordProtected, []string{"password", "encrypted"}, nil},
+	{models.ErrorCategoryLLMTimeout, timeoutKeywords, []string{"llm"}},
+	{models.ErrorCategoryTimeout, timeoutKeywords, nil},
+	{models.ErrorCategoryCancelled, []string{"cancelled"}, nil},
+	{models.ErrorCategoryNoText, []string{"no text", "insufficient text", "file content is empty"}, nil},
+	{models.ErrorCategoryLowQuality, []string{"quality too low"}, nil},
+	{models.ErrorCategoryUnsupportedFile, []string{"unsupported", "not a valid"}, nil},
+	{models.ErrorCategoryLLM, []string{"llm"}, nil},
+	{models.ErrorCategoryEmbedding, []string{"embedding", "vector"}, nil},
+}
+
+// timeoutKeywords mark error messages of jobs that ran out of time
+var timeoutKeywords = []string{"timeout", "timed out", "deadline exceeded"}
+
+// failedJobCategory is an SQL expression for the error category of the analysis job j,
+// applying failedJobCategoryRules
+var failedJobCategory = failedJobCategoryCase("LOWER(COALESCE(j.error_message, ''))")
+
+// failedJobCategoryCase builds a CASE expression applying failedJobCategoryRules to the
+// lowercase message expression
+func failedJobCategoryCase(message string) string {
+	containsAny := func(keywords []string) string {
+		patterns := make([]string, len(keywords))
+		for i, keyword := range keywords {
+			patterns[i] = pq.QuoteLiteral("%" + keyword + "%")
+		}
+		return fmt.Sprintf("%s LIKE ANY (ARRAY[%s])", message, strings.Join(patterns, ", "))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	for _, rule := range failedJobCategoryRules {
+		condition := containsAny(rule.keywords)
+		if rule.alsoKeywords != nil {
+			condition += " AND " + containsAny(rule.alsoKeywords)
+		}
+		fmt.Fprintf(&sb, " WHEN %s THEN %s", condition, pq.QuoteLiteral(rule.category))
+	}
+	fmt.Fprintf(&sb, " ELSE %s END", pq.QuoteLiteral(models.ErrorCategoryOther))
+	return sb.String()
+}
+
+// CountFailedJobsByCategory counts the failed and dead-lettered jobs per error category,
+// largest category first, with each category's most common error message
+func (r *AnalysisPostgresRepository) CountFailedJobsByCategory(ctx context.Context) ([]models.FailedJobGroup, error) {
+	query := `
+		WITH messages AS (
+			SELECT ` + failedJobCategory + ` AS category,
+			       COALESCE(j.error_message, '') AS message, COUNT(*) AS jobs
+			FROM analysis_jobs j
+			WHERE j.status = ANY($1)
+			GROUP BY 1, 2
+		)
+		SELECT category, SUM(jobs)::INT,
+		       COALESCE((ARRAY_AGG(message ORDER BY jobs DESC, message) FILTER (WHERE message <> ''))[1], '')
+		FROM messages
+		GROUP BY category
+		ORDER BY 2 DESC, category
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(failedJobStatuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.FailedJobGroup{}
+	for rows.Next() {
+		var group models.FailedJobGroup
+		if err := rows.Scan(&group.Category, &group.Count, &group.Example); err != nil {
+			return nil, fmt.Errorf("failed to scan failed job count: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failed job counts: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ListFailedJobs retrieves failed and dead-lettered jobs with their upload's file name and
+// type and their error category, most recently updated first. A non-empty category only
+// keeps jobs of that error category.
+func (r *AnalysisPostgresRepository) ListFailedJobs(ctx context.Context, category string, limit, offset int) ([]*models.FailedJob, error) {
+	query := `
+		SELECT job_id, upload_id, file_name, mime_type, status, error_message, error_code,
+		       error_category, retry_count, created_at, updated_at
+		FROM (
+			SELECT j.id, j.job_id, j.upload_id, COALESCE(u.file_name, '') AS file_name,
+			       COALESCE(u.mime_type, '') AS mime_type, j.status, j.error_message, j.error_code,
+			       ` + failedJobCategory + ` AS error_category,
+			       j.retry_count, j.created_at, j.updated_at
+			FROM analysis_jobs j
+			LEFT JOIN user_uploads u ON u.id = j.upload_id
+			WHERE j.status = ANY($1)
+		) failed
+		WHERE $2::TEXT = '' OR error_category = $2
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(failedJobStatuses), category, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*models.FailedJob{}
+	for rows.Next() {
+		job := &models.FailedJob{}
+		err := rows.Scan(
+			&job.JobID,
+			&job.UploadID,
+			&job.FileName,
+			&job.MimeType,
+			&job.Status,
+			&job.ErrorMessage,
+			&job.ErrorCode,
+			&job.ErrorCategory,
+			&job.RetryCount,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan failed job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return jobs, nil
+}
+
 // BatchDeleteJobs deletes multiple analysis jobs and their associated profiles in a transaction
 func (r *AnalysisPostgresRepository) BatchDeleteJobs(ctx context.Context, jobIDs []string) ([]string, error) {
 	if len(jobIDs) == 0 {
@@ -681,8 +1159,8 @@ func (r *AnalysisPostgresRepository) BatchDeleteJobs(ctx context.Context, jobIDs
 
 		jobsFound[jobID] = true
 
-		// Only completed and failed jobs can be deleted
-		if status != "completed" && status != "failed" {
+		// Only finished jobs can be deleted
+		if status != "completed" && status != "failed" && status != "needs_review" && status != "dead_lettered" {
 			nonDeletableJobs = append(nonDeletableJobs, jobID)
 		}
 	}
@@ -707,7 +1185,7 @@ func (r *AnalysisPostgresRepository) BatchDeleteJobs(ctx context.Context, jobIDs
 	jobQuery := `
 		DELETE FROM analysis_jobs
 		WHERE job_id = ANY($1)
-		  AND status IN ('completed', 'failed')
+		  AND status IN ('completed', 'failed', 'needs_review', 'dead_lettered')
 	`
 
 	result, err := tx.ExecContext(ctx, jobQuery, pq.Array(jobIDs))
@@ -734,3 +1212,138 @@ func (r *AnalysisPostgresRepository) BatchDeleteJobs(ctx context.Context, jobIDs
 	return deletedJobs, nil
 }
 
+// Queries aggregating a user's statistics for GetUserAnalytics, all filtered on the user ($1)
+const (
+	userUploadCountQuery = `SELECT COUNT(*) FROM user_uploads WHERE user_id = $1`
+
+	userJobStatusQuery = `SELECT status, COUNT(*) FROM analysis_jobs WHERE user_id = $1 GROUP BY status`
+
+	// AVG skips profiles without work years, and is NULL when none has them
+	userProfileStatsQuery = `
+		SELECT COUNT(*), AVG(p.total_work_years)
+		FROM user_profile p
+		JOIN analysis_jobs j ON j.job_id = p.job_id
+		WHERE j.user_id = $1
+	`
+
+	// userProfileSkillsQuery flattens the skills of every category of the user's profiles
+	// into (profile ID, skill) rows. Profiles whose skills aren't a JSON object of arrays
+	// contribute nothing; topSkillCounts does the counting.
+	userProfileSkillsQuery = `
+		SELECT p.id, s.skill
+		FROM user_profile p
+		JOIN analysis_jobs j ON j.job_id = p.job_id
+		CROSS JOIN LATERAL jsonb_each(
+			CASE WHEN jsonb_typeof(p.skills) = 'object' THEN p.skills ELSE '{}'::jsonb END
+		) AS c(category, items)
+		CROSS JOIN LATERAL jsonb_array_elements_text(
+			CASE WHEN jsonb_typeof(c.items) = 'array' THEN c.items ELSE '[]'::jsonb END
+		) AS s(skill)
+		WHERE j.user_id = $1
+	`
+)
+
+// profileSkill is a row of userProfileSkillsQuery
+type profileSkill struct {
+	profileID int
+	skill     string
+}
+
+// topSkillCounts counts the profiles listing each skill, at most limit of them, the most
+// common first and ties by name. Skills are compared trimmed and lowercased, and a skill
+// listed several times by a profile, e.g. in two categories, counts once for it.
+func topSkillCounts(skills []profileSkill, limit int) []models.SkillCount {
+	profiles := make(map[string]map[int]bool)
+	for _, ps := range skills {
+		skill := strings.ToLower(strings.TrimSpace(ps.skill))
+		if skill == "" {
+			continue
+		}
+		if profiles[skill] == nil {
+			profiles[skill] = make(map[int]bool)
+		}
+		profiles[skill][ps.profileID] = true
+	}
+
+	counts := make([]models.SkillCount, 0, len(profiles))
+	for skill, ids := range profiles {
+		counts = append(counts, models.SkillCount{Skill: skill, Count: len(ids)})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Skill < counts[j].Skill
+	})
+
+	if len(counts) > limit {
+		counts = counts[:max(limit, 0)]
+	}
+	return counts
+}
+
+// GetUserAnalytics aggregates upload, job and profile statistics for a user
+func (r *AnalysisPostgresRepository) GetUserAnalytics(ctx context.Context, userID int, topSkills int) (*models.UserAnalytics, error) {
+	analytics := &models.UserAnalytics{
+		UserID:       userID,
+		JobsByStatus: make(map[string]int),
+		TopSkills:    []models.SkillCount{},
+	}
+
+	// Total uploads
+	if err := r.db.QueryRowContext(ctx, userUploadCountQuery, userID).Scan(&analytics.TotalUploads); err != nil {
+		return nil, fmt.Errorf("failed to count uploads: %w", err)
+	}
+
+	// Jobs by status
+	rows, err := r.db.QueryContext(ctx, userJobStatusQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs by status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan job status count: %w", err)
+		}
+		analytics.JobsByStatus[status] = count
+		analytics.TotalJobs += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job status rows: %w", err)
+	}
+
+	// Profile count and average work years
+	var avgWorkYears sql.NullFloat64
+	err = r.db.QueryRowContext(ctx, userProfileStatsQuery, userID).Scan(&analytics.TotalProfiles, &avgWorkYears)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate profiles: %w", err)
+	}
+	if avgWorkYears.Valid {
+		analytics.AverageWorkYears = &avgWorkYears.Float64
+	}
+
+	// Most common skills across all skill categories, counted once per profile
+	skillRows, err := r.db.QueryContext(ctx, userProfileSkillsQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate skills: %w", err)
+	}
+	defer skillRows.Close()
+
+	var skills []profileSkill
+	for skillRows.Next() {
+		var ps profileSkill
+		if err := skillRows.Scan(&ps.profileID, &ps.skill); err != nil {
+			return nil, fmt.Errorf("failed to scan profile skill: %w", err)
+		}
+		skills = append(skills, ps)
+	}
+	if err := skillRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating skill rows: %w", err)
+	}
+	analytics.TopSkills = topSkillCounts(skills, topSkills)
+
+	return analytics, nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/your-org/websocket-server/internal/repository"
 	"github.com/your-org/websocket-server/pkg/models"
 )
@@ -174,6 +176,117 @@ func (r *ChatMessagePostgresRepository) CountMessages(ctx context.Context, userI
 	return count, nil
 }
 
+// CountMessagesBySession counts messages in a specific session
+func (r *ChatMessagePostgresRepository) CountMessagesBySession(ctx context.Context, sessionID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM chat_messages
+		WHERE session_id = $1
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count session messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountConversation counts messages between two users
+func (r *ChatMessagePostgresRepository) CountConversation(ctx context.Context, userID1, userID2 int) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM chat_messages
+		WHERE (user_id = $1 AND to_user_id = $2) OR (user_id = $2 AND to_user_id = $1)
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID1, userID2).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count conversation messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindMessages retrieves the messages matching a filter with pagination
+func (r *ChatMessagePostgresRepository) FindMessages(ctx context.Context, filter repository.MessageFilter, limit, offset int) ([]*models.ChatMessage, error) {
+	where, args := messageFilterClause(filter)
+
+	order := "DESC"
+	if filter.OldestFirst {
+		order = "ASC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, to_user_id, msg_type, text_content, metadata, session_id, created_at
+		FROM chat_messages
+		%s
+		ORDER BY created_at %s, id %s
+		LIMIT $%d OFFSET $%d
+	`, where, order, order, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// CountMatchingMessages counts the messages matching a filter
+func (r *ChatMessagePostgresRepository) CountMatchingMessages(ctx context.Context, filter repository.MessageFilter) (int, error) {
+	where, args := messageFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM chat_messages
+		%s
+	`, where)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count matching messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// messageFilterClause builds the WHERE clause of a message filter and its arguments,
+// numbered from $1. An empty filter yields an empty clause.
+func messageFilterClause(filter repository.MessageFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.UserID != 0 {
+		user := param(filter.UserID)
+		if filter.WithUserID != nil {
+			other := param(*filter.WithUserID)
+			conditions = append(conditions, fmt.Sprintf("((user_id = %s AND to_user_id = %s) OR (user_id = %s AND to_user_id = %s))", user, other, other, user))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(user_id = %s OR to_user_id = %s)", user, user))
+		}
+	}
+	if len(filter.SessionIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("session_id = ANY(%s)", param(pq.Array(filter.SessionIDs))))
+	}
+	if filter.MsgType != "" {
+		conditions = append(conditions, fmt.Sprintf("msg_type = %s", param(filter.MsgType)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
 // DeleteMessage deletes a message by ID
 func (r *ChatMessagePostgresRepository) DeleteMessage(ctx context.Context, id int64) error {
 	query := `DELETE FROM chat_messages WHERE id = $1`
@@ -5,20 +5,31 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/internal/storage"
 	"github.com/your-org/websocket-server/pkg/models"
 )
 
-// PostgresRepository implements the UploadRepository interface for PostgreSQL
+// PostgresRepository implements the UploadRepository interface for PostgreSQL.
+// Upload metadata lives in user_uploads; file content goes through fileStore.
 type PostgresRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	fileStore storage.FileStore
 }
 
-// NewPostgresRepository creates a new PostgreSQL repository instance
+// NewPostgresRepository creates a new PostgreSQL repository instance that
+// stores file content in the same database
 func NewPostgresRepository(connectionString string) (repository.UploadRepository, error) {
+	return NewPostgresRepositoryWithFileStore(connectionString, nil)
+}
+
+// NewPostgresRepositoryWithFileStore creates a new PostgreSQL repository instance that
+// stores file content in fileStore. A nil fileStore uses the database (file_blobs table).
+func NewPostgresRepositoryWithFileStore(connectionString string, fileStore storage.FileStore) (repository.UploadRepository, error) {
 	db, err := sql.Open("postgres", connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
@@ -39,7 +50,11 @@ func NewPostgresRepository(connectionString string) (repository.UploadRepository
 
 	log.Println("PostgreSQL connection established successfully")
 
-	return &PostgresRepository{db: db}, nil
+	if fileStore == nil {
+		fileStore = storage.NewPostgresFileStore(db)
+	}
+
+	return &PostgresRepository{db: db, fileStore: fileStore}, nil
 }
 
 // GetDB returns the underlying database connection
@@ -48,26 +63,38 @@ func (r *PostgresRepository) GetDB() *sql.DB {
 	return r.db
 }
 
-// CreateUpload stores a new upload record in the database
+// CreateUpload stores the file content in the file store and a new upload record in the database.
+// The upload is stored under upload.TenantID, which the caller resolves; "" is the default tenant.
 func (r *PostgresRepository) CreateUpload(ctx context.Context, upload *models.Upload) error {
+	storageKey, err := r.fileStore.Put(ctx, upload.FileContent, upload.MimeType)
+	if err != nil {
+		return fmt.Errorf("failed to store file content: %w", err)
+	}
+
 	query := `
-		INSERT INTO user_uploads (user_id, linkedin_url, file_name, file_content, file_size, mime_type)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO user_uploads (user_id, linkedin_url, file_name, storage_key, file_size, mime_type, notify_email, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRowContext(
+	err = r.db.QueryRowContext(
 		ctx,
 		query,
 		upload.UserID,
 		upload.LinkedinURL,
 		upload.FileName,
-		upload.FileContent,
+		storageKey,
 		upload.FileSize,
 		upload.MimeType,
+		upload.NotifyEmail,
+		upload.TenantID,
 	).Scan(&upload.ID, &upload.CreatedAt, &upload.UpdatedAt)
 
 	if err != nil {
+		// Don't leave orphaned content behind
+		if delErr := r.fileStore.Delete(ctx, storageKey); delErr != nil {
+			log.Printf("Warning: failed to delete file content %s: %v", storageKey, delErr)
+		}
 		return fmt.Errorf("failed to create upload: %w", err)
 	}
 
@@ -78,7 +105,7 @@ func (r *PostgresRepository) CreateUpload(ctx context.Context, upload *models.Up
 // GetUploadByID retrieves an upload record by its ID (without file content)
 func (r *PostgresRepository) GetUploadByID(ctx context.Context, id int) (*models.Upload, error) {
 	query := `
-		SELECT id, user_id, linkedin_url, file_name, file_size, mime_type, created_at, updated_at
+		SELECT id, user_id, linkedin_url, file_name, file_size, mime_type, pinned, notify_email, tags, notes, tenant_id, created_at, updated_at
 		FROM user_uploads
 		WHERE id = $1
 	`
@@ -91,6 +118,11 @@ func (r *PostgresRepository) GetUploadByID(ctx context.Context, id int) (*models
 		&upload.FileName,
 		&upload.FileSize,
 		&upload.MimeType,
+		&upload.Pinned,
+		&upload.NotifyEmail,
+		pq.Array(&upload.Tags),
+		&upload.Notes,
+		&upload.TenantID,
 		&upload.CreatedAt,
 		&upload.UpdatedAt,
 	)
@@ -105,98 +137,157 @@ func (r *PostgresRepository) GetUploadByID(ctx context.Context, id int) (*models
 	return upload, nil
 }
 
-// ListUploads retrieves all upload records with pagination support
-func (r *PostgresRepository) ListUploads(ctx context.Context, limit, offset int) ([]*models.Upload, error) {
-	query := `
-		SELECT
-			u.id,
-			u.user_id,
-			u.linkedin_url,
-			u.file_name,
-			u.file_size,
-			u.mime_type,
-			u.created_at,
-			u.updated_at,
-			(
-				SELECT aj.job_id
-				FROM analysis_jobs aj
-				WHERE aj.upload_id = u.id
-				ORDER BY
-					CASE WHEN aj.status = 'completed' THEN 0 ELSE 1 END,
-					aj.created_at DESC
-				LIMIT 1
-			) as job_id
-		FROM user_uploads u
+// uploadListQuery selects upload metadata along with the latest analysis job of each
+// upload, so a re-run that is queued or failed is reported rather than an older result.
+// Callers append their own WHERE, ORDER BY and LIMIT clauses.
+const uploadListQuery = `
+	SELECT
+		u.id,
+		u.user_id,
+		u.linkedin_url,
+		u.file_name,
+		u.file_size,
+		u.mime_type,
+		u.pinned,
+		u.notify_email,
+		u.tags,
+		u.notes,
+		u.tenant_id,
+		u.created_at,
+		u.updated_at,
+		aj.job_id,
+		aj.status,
+		aj.progress
+	FROM user_uploads u
+	LEFT JOIN LATERAL (
+		SELECT j.job_id, j.status, j.progress
+		FROM analysis_jobs j
+		WHERE j.upload_id = u.id
+		ORDER BY j.created_at DESC
+		LIMIT 1
+	) aj ON true
+`
+
+// ListUploadsByUserID retrieves upload records for a specific user with pagination
+func (r *PostgresRepository) ListUploadsByUserID(ctx context.Context, userID, limit, offset int) ([]*models.Upload, error) {
+	query := uploadListQuery + `
+		WHERE u.user_id = $1
 		ORDER BY u.created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list uploads: %w", err)
+		return nil, fmt.Errorf("failed to list uploads by user: %w", err)
 	}
 	defer rows.Close()
 
-	var uploads []*models.Upload
-	for rows.Next() {
-		upload := &models.Upload{}
-		err := rows.Scan(
-			&upload.ID,
-			&upload.UserID,
-			&upload.LinkedinURL,
-			&upload.FileName,
-			&upload.FileSize,
-			&upload.MimeType,
-			&upload.CreatedAt,
-			&upload.UpdatedAt,
-			&upload.JobID,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan upload row: %w", err)
-		}
-		uploads = append(uploads, upload)
+	return scanUploads(rows)
+}
+
+// uploadSortColumns maps the upload sort fields to the expressions they order by. Only
+// these reach the query, so the sort field can't inject SQL.
+var uploadSortColumns = map[string]string{
+	repository.UploadSortCreatedAt: "u.created_at",
+	repository.UploadSortFileSize:  "u.file_size",
+	repository.UploadSortName:      "LOWER(u.file_name)",
+}
+
+// uploadFilterClause builds the WHERE clause restricting uploadListQuery to a filter and
+// its arguments, numbered from $1. An empty filter yields an empty clause.
+func uploadFilterClause(filter repository.UploadFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating upload rows: %w", err)
+	if filter.UserID != nil {
+		conditions = append(conditions, "u.user_id = "+param(*filter.UserID))
+	}
+	if filter.TenantID != nil {
+		conditions = append(conditions, "u.tenant_id = "+param(*filter.TenantID))
+	}
+	switch filter.Status {
+	case "":
+	case models.UploadStatusNotAnalyzed:
+		conditions = append(conditions, "aj.job_id IS NULL")
+	default:
+		conditions = append(conditions, "aj.status = "+param(filter.Status))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, param(filter.Tag)+" = ANY(u.tags)")
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "u.created_at >= "+param(*filter.CreatedFrom))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "u.created_at < "+param(*filter.CreatedBefore))
 	}
 
-	return uploads, nil
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
 }
 
-// ListUploadsByUserID retrieves upload records for a specific user with pagination
-func (r *PostgresRepository) ListUploadsByUserID(ctx context.Context, userID, limit, offset int) ([]*models.Upload, error) {
-	query := `
-		SELECT
-			u.id,
-			u.user_id,
-			u.linkedin_url,
-			u.file_name,
-			u.file_size,
-			u.mime_type,
-			u.created_at,
-			u.updated_at,
-			(
-				SELECT aj.job_id
-				FROM analysis_jobs aj
-				WHERE aj.upload_id = u.id
-				ORDER BY
-					CASE WHEN aj.status = 'completed' THEN 0 ELSE 1 END,
-					aj.created_at DESC
-				LIMIT 1
-			) as job_id
-		FROM user_uploads u
-		WHERE u.user_id = $1
-		ORDER BY u.created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+// uploadOrderClause builds the ORDER BY clause of uploadListQuery for an upload sort.
+// Uploads that sort equally are ordered by ID in the same direction, so pages are stable.
+func uploadOrderClause(order repository.UploadSort) (string, error) {
+	field := order.Field
+	if field == "" {
+		field = repository.UploadSortCreatedAt
+	}
+	column, ok := uploadSortColumns[field]
+	if !ok {
+		return "", fmt.Errorf("unknown upload sort field: %s", order.Field)
+	}
+	direction := "DESC"
+	if order.Ascending {
+		direction = "ASC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, u.id %s", column, direction, direction), nil
+}
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+// ListUploadsFiltered retrieves the upload records matching a filter, in the given order
+func (r *PostgresRepository) ListUploadsFiltered(ctx context.Context, filter repository.UploadFilter, order repository.UploadSort, limit, offset int) ([]*models.Upload, error) {
+	orderBy, err := uploadOrderClause(order)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list uploads by user: %w", err)
+		return nil, err
+	}
+
+	where, args := uploadFilterClause(filter)
+	query := uploadListQuery + where + fmt.Sprintf(`
+		%s
+		LIMIT $%d OFFSET $%d
+	`, orderBy, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filtered uploads: %w", err)
 	}
 	defer rows.Close()
 
+	return scanUploads(rows)
+}
+
+// CountUploads counts the upload records matching a filter
+func (r *PostgresRepository) CountUploads(ctx context.Context, filter repository.UploadFilter) (int, error) {
+	where, args := uploadFilterClause(filter)
+	query := `SELECT COUNT(*) FROM (` + uploadListQuery + where + `) counted`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count uploads: %w", err)
+	}
+
+	return count, nil
+}
+
+// scanUploads scans rows produced by uploadListQuery
+func scanUploads(rows *sql.Rows) ([]*models.Upload, error) {
 	var uploads []*models.Upload
 	for rows.Next() {
 		upload := &models.Upload{}
@@ -207,9 +298,16 @@ func (r *PostgresRepository) ListUploadsByUserID(ctx context.Context, userID, li
 			&upload.FileName,
 			&upload.FileSize,
 			&upload.MimeType,
+			&upload.Pinned,
+			&upload.NotifyEmail,
+			pq.Array(&upload.Tags),
+			&upload.Notes,
+			&upload.TenantID,
 			&upload.CreatedAt,
 			&upload.UpdatedAt,
 			&upload.JobID,
+			&upload.JobStatus,
+			&upload.JobProgress,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan upload row: %w", err)
@@ -217,20 +315,36 @@ func (r *PostgresRepository) ListUploadsByUserID(ctx context.Context, userID, li
 		uploads = append(uploads, upload)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating upload rows: %w", err)
 	}
 
 	return uploads, nil
 }
 
-// DeleteUpload removes an upload record by its ID
-func (r *PostgresRepository) DeleteUpload(ctx context.Context, id int) error {
-	query := `DELETE FROM user_uploads WHERE id = $1`
+// OwnsUpload reports whether the upload belongs to the given user
+func (r *PostgresRepository) OwnsUpload(ctx context.Context, userID, uploadID int) (bool, error) {
+	query := `SELECT COALESCE(user_id = $1, false) FROM user_uploads WHERE id = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var owned bool
+	err := r.db.QueryRowContext(ctx, query, userID, uploadID).Scan(&owned)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("upload not found with ID: %d", uploadID)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete upload: %w", err)
+		return false, fmt.Errorf("failed to check upload owner: %w", err)
+	}
+
+	return owned, nil
+}
+
+// SetUploadPinned marks an upload as pinned (exempt from retention cleanup) or unpinned
+func (r *PostgresRepository) SetUploadPinned(ctx context.Context, id int, pinned bool) error {
+	query := `UPDATE user_uploads SET pinned = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, pinned, id)
+	if err != nil {
+		return fmt.Errorf("failed to update upload pin: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -242,16 +356,94 @@ func (r *PostgresRepository) DeleteUpload(ctx context.Context, id int) error {
 		return fmt.Errorf("upload not found with ID: %d", id)
 	}
 
+	return nil
+}
+
+// UpdateUploadMetadata replaces the tags and notes of an upload
+func (r *PostgresRepository) UpdateUploadMetadata(ctx context.Context, id int, tags []string, notes string) error {
+	query := `UPDATE user_uploads SET tags = $1, notes = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, pq.Array(tags), notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update upload metadata: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("upload not found with ID: %d", id)
+	}
+
+	return nil
+}
+
+// ListExpiredUploadIDs returns IDs of unpinned uploads created before the cutoff, oldest first
+func (r *PostgresRepository) ListExpiredUploadIDs(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	query := `
+		SELECT id
+		FROM user_uploads
+		WHERE pinned = FALSE AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan upload ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upload rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// DeleteUpload removes an upload record by its ID along with its stored file content
+func (r *PostgresRepository) DeleteUpload(ctx context.Context, id int) error {
+	query := `DELETE FROM user_uploads WHERE id = $1 RETURNING storage_key`
+
+	var storageKey sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&storageKey)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("upload not found with ID: %d", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+
+	// The record is already gone, so a failure here only leaves unreferenced content behind
+	if storageKey.Valid {
+		if err := r.fileStore.Delete(ctx, storageKey.String); err != nil {
+			log.Printf("Warning: failed to delete file content %s for upload %d: %v", storageKey.String, id, err)
+		}
+	}
+
 	log.Printf("Upload deleted successfully with ID: %d", id)
 	return nil
 }
 
-// GetUploadFileContent retrieves only the file content for a specific upload
+// GetUploadFileContent retrieves only the file content for a specific upload.
+// Uploads created before file stores were introduced still keep their content inline.
 func (r *PostgresRepository) GetUploadFileContent(ctx context.Context, id int) ([]byte, error) {
-	query := `SELECT file_content FROM user_uploads WHERE id = $1`
+	query := `SELECT storage_key, file_content FROM user_uploads WHERE id = $1`
 
+	var storageKey sql.NullString
 	var fileContent []byte
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&fileContent)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&storageKey, &fileContent)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("upload not found with ID: %d", id)
@@ -260,6 +452,14 @@ func (r *PostgresRepository) GetUploadFileContent(ctx context.Context, id int) (
 		return nil, fmt.Errorf("failed to get file content: %w", err)
 	}
 
+	if !storageKey.Valid {
+		return fileContent, nil
+	}
+
+	fileContent, err = r.fileStore.Get(ctx, storageKey.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
 	return fileContent, nil
 }
 
@@ -0,0 +1,508 @@
+package postgres
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+func TestUploadFilterClause(t *testing.T) {
+	userID := 7
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   repository.UploadFilter
+		want     string
+		wantArgs []interface{}
+	}{
+		{"empty", repository.UploadFilter{}, "", nil},
+		{"user", repository.UploadFilter{UserID: &userID}, "WHERE u.user_id = $1", []interface{}{7}},
+		{"status", repository.UploadFilter{Status: "failed"}, "WHERE aj.status = $1", []interface{}{"failed"}},
+		{"not analyzed", repository.UploadFilter{Status: models.UploadStatusNotAnalyzed}, "WHERE aj.job_id IS NULL", nil},
+		{
+			"user and status",
+			repository.UploadFilter{UserID: &userID, Status: "completed"},
+			"WHERE u.user_id = $1 AND aj.status = $2",
+			[]interface{}{7, "completed"},
+		},
+		{"tag", repository.UploadFilter{Tag: "frontend"}, "WHERE $1 = ANY(u.tags)", []interface{}{"frontend"}},
+		{
+			"user, status and tag",
+			repository.UploadFilter{UserID: &userID, Status: "completed", Tag: "referral"},
+			"WHERE u.user_id = $1 AND aj.status = $2 AND $3 = ANY(u.tags)",
+			[]interface{}{7, "completed", "referral"},
+		},
+		{"created from", repository.UploadFilter{CreatedFrom: &from}, "WHERE u.created_at >= $1", []interface{}{from}},
+		{"created before", repository.UploadFilter{CreatedBefore: &before}, "WHERE u.created_at < $1", []interface{}{before}},
+		{
+			"user and date range",
+			repository.UploadFilter{UserID: &userID, CreatedFrom: &from, CreatedBefore: &before},
+			"WHERE u.user_id = $1 AND u.created_at >= $2 AND u.created_at < $3",
+			[]interface{}{7, from, before},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := uploadFilterClause(tt.filter)
+			if got != tt.want {
+				t.Errorf("clause = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestUploadOrderClause(t *testing.T) {
+	tests := []struct {
+		name  string
+		order repository.UploadSort
+		want  string
+	}{
+		{"default is newest first", repository.UploadSort{}, "ORDER BY u.created_at DESC, u.id DESC"},
+		{"oldest first", repository.UploadSort{Field: repository.UploadSortCreatedAt, Ascending: true}, "ORDER BY u.created_at ASC, u.id ASC"},
+		{"largest first", repository.UploadSort{Field: repository.UploadSortFileSize}, "ORDER BY u.file_size DESC, u.id DESC"},
+		{"smallest first", repository.UploadSort{Field: repository.UploadSortFileSize, Ascending: true}, "ORDER BY u.file_size ASC, u.id ASC"},
+		{"name ignoring case", repository.UploadSort{Field: repository.UploadSortName, Ascending: true}, "ORDER BY LOWER(u.file_name) ASC, u.id ASC"},
+		{"name descending", repository.UploadSort{Field: repository.UploadSortName}, "ORDER BY LOWER(u.file_name) DESC, u.id DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uploadOrderClause(tt.order)
+			if err != nil || got != tt.want {
+				t.Errorf("uploadOrderClause(%+v) = %q, %v; want %q", tt.order, got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		if got, err := uploadOrderClause(repository.UploadSort{Field: "file_name; DROP TABLE user_uploads"}); err == nil {
+			t.Errorf("uploadOrderClause = %q, want an error", got)
+		}
+	})
+}
+
+func TestSaveProfileQueryUpsertsOnJobID(t *testing.T) {
+	query := strings.Join(strings.Fields(saveProfileQuery), " ")
+
+	insert := regexp.MustCompile(`INSERT INTO user_profile \(([^)]*)\) VALUES \(([^)]*)\)`).FindStringSubmatch(query)
+	if insert == nil {
+		t.Fatalf("not an insert into user_profile: %s", query)
+	}
+	columns := strings.Split(strings.TrimSpace(insert[1]), ", ")
+	if placeholders := strings.Split(insert[2], ", "); len(placeholders) != len(columns) {
+		t.Fatalf("%d columns but %d placeholders", len(columns), len(placeholders))
+	}
+
+	_, update, ok := strings.Cut(query, "ON CONFLICT (job_id) DO UPDATE SET ")
+	if !ok {
+		t.Fatalf("query doesn't upsert on job_id: %s", query)
+	}
+	update, _, _ = strings.Cut(update, " RETURNING")
+
+	// Every column but the conflict key is replaced, and the row records the update
+	var want []string
+	for _, column := range columns {
+		if column != "job_id" {
+			want = append(want, column+" = EXCLUDED."+column)
+		}
+	}
+	want = append(want, "updated_at = NOW()")
+	if got := strings.Split(update, ", "); !reflect.DeepEqual(got, want) {
+		t.Errorf("update sets\n%q\nwant\n%q", got, want)
+	}
+
+	if !strings.HasSuffix(query, "RETURNING id, created_at, updated_at") {
+		t.Errorf("query doesn't return the stored row's ID and times: %s", query)
+	}
+}
+
+func TestMessageFilterClause(t *testing.T) {
+	systemUserID := models.SystemUserID
+
+	tests := []struct {
+		name     string
+		filter   repository.MessageFilter
+		want     string
+		wantArgs []interface{}
+	}{
+		{"empty", repository.MessageFilter{}, "", nil},
+		{"user", repository.MessageFilter{UserID: 7}, "WHERE (user_id = $1 OR to_user_id = $1)", []interface{}{7}},
+		{
+			"conversation",
+			repository.MessageFilter{UserID: 7, WithUserID: &systemUserID},
+			"WHERE ((user_id = $1 AND to_user_id = $2) OR (user_id = $2 AND to_user_id = $1))",
+			[]interface{}{7, systemUserID},
+		},
+		{"type", repository.MessageFilter{MsgType: models.MessageTypeAudio}, "WHERE msg_type = $1", []interface{}{models.MessageTypeAudio}},
+		{
+			"user, sessions and type",
+			repository.MessageFilter{UserID: 7, SessionIDs: []string{"s1", "s2"}, MsgType: models.MessageTypeAudio},
+			"WHERE (user_id = $1 OR to_user_id = $1) AND session_id = ANY($2) AND msg_type = $3",
+			[]interface{}{7, pq.Array([]string{"s1", "s2"}), models.MessageTypeAudio},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := messageFilterClause(tt.filter)
+			if got != tt.want {
+				t.Errorf("clause = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTagCountsQuery(t *testing.T) {
+	for _, column := range []string{"user_id", "auth_user_id"} {
+		t.Run(column, func(t *testing.T) {
+			query := strings.Join(strings.Fields(fmt.Sprintf(tagCountsQuery, column)), " ")
+
+			for _, want := range []string{
+				"SELECT tag, COUNT(DISTINCT q.id) AS question_count",
+				"unnest(q.tags) AS tag",
+				"WHERE q." + column + " = $1",
+				"GROUP BY tag",
+				"ORDER BY question_count DESC, tag ASC",
+			} {
+				if !strings.Contains(query, want) {
+					t.Errorf("query is missing %q: %s", want, query)
+				}
+			}
+		})
+	}
+}
+
+func TestUserAnalyticsQueries(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"uploads", userUploadCountQuery, []string{"SELECT COUNT(*) FROM user_uploads WHERE user_id = $1"}},
+		{"jobs by status", userJobStatusQuery, []string{
+			"SELECT status, COUNT(*) FROM analysis_jobs",
+			"WHERE user_id = $1",
+			"GROUP BY status",
+		}},
+		{"profiles", userProfileStatsQuery, []string{
+			// A plain AVG skips NULL work years instead of counting them as 0
+			"SELECT COUNT(*), AVG(p.total_work_years) FROM user_profile p",
+			"JOIN analysis_jobs j ON j.job_id = p.job_id",
+			"WHERE j.user_id = $1",
+		}},
+		{"skills", userProfileSkillsQuery, []string{
+			"SELECT p.id, s.skill FROM user_profile p",
+			"JOIN analysis_jobs j ON j.job_id = p.job_id",
+			"CROSS JOIN LATERAL jsonb_each( CASE WHEN jsonb_typeof(p.skills) = 'object' THEN p.skills ELSE '{}'::jsonb END ) AS c(category, items)",
+			"CROSS JOIN LATERAL jsonb_array_elements_text( CASE WHEN jsonb_typeof(c.items) = 'array' THEN c.items ELSE '[]'::jsonb END ) AS s(skill)",
+			"WHERE j.user_id = $1",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := strings.Join(strings.Fields(tt.query), " ")
+			for _, want := range tt.want {
+				if !strings.Contains(query, want) {
+					t.Errorf("query is missing %q: %s", want, query)
+				}
+			}
+			if strings.Contains(query, "COALESCE") {
+				t.Errorf("query replaces NULLs: %s", query)
+			}
+		})
+	}
+}
+
+func TestTopSkillCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		skills []profileSkill
+		limit  int
+		want   []models.SkillCount
+	}{
+		{"no profiles", nil, 10, []models.SkillCount{}},
+		{
+			"counted per profile",
+			[]profileSkill{{1, "Go"}, {1, "SQL"}, {2, "Go"}, {3, "Go"}, {3, "Rust"}},
+			10,
+			[]models.SkillCount{{Skill: "go", Count: 3}, {Skill: "rust", Count: 1}, {Skill: "sql", Count: 1}},
+		},
+		{
+			"same skill in two categories of one profile",
+			// Profile 1 lists Go as both technical and language; profile 2 lists it once
+			[]profileSkill{{1, "Go"}, {1, "SQL"}, {1, " go "}, {2, "GO"}},
+			10,
+			[]models.SkillCount{{Skill: "go", Count: 2}, {Skill: "sql", Count: 1}},
+		},
+		{"blank skills skipped", []profileSkill{{1, " "}, {1, ""}, {2, "Go"}}, 10, []models.SkillCount{{Skill: "go", Count: 1}}},
+		{
+			"limited",
+			[]profileSkill{{1, "Go"}, {2, "Go"}, {1, "SQL"}, {1, "Rust"}},
+			2,
+			[]models.SkillCount{{Skill: "go", Count: 2}, {Skill: "rust", Count: 1}},
+		},
+		{"zero limit", []profileSkill{{1, "Go"}}, 0, []models.SkillCount{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topSkillCounts(tt.skills, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("topSkillCounts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountByJobQuery(t *testing.T) {
+	query := strings.Join(strings.Fields(countByJobQuery), " ")
+
+	want := "SELECT COUNT(*) FROM saved_interview_questions WHERE user_id = $1 AND job_id = $2"
+	if query != want {
+		t.Errorf("query = %s\nwant %s", query, want)
+	}
+}
+
+func TestSavedRefsQuery(t *testing.T) {
+	refs := []models.SavedQuestionRef{
+		{JobID: "job_1", QuestionID: "q1"},
+		{JobID: "job_1", QuestionID: "q2"},
+		{JobID: "job_2", QuestionID: "q1"},
+	}
+
+	query, args := savedRefsQuery("u1", refs)
+	query = strings.Join(strings.Fields(query), " ")
+
+	want := "SELECT job_id, question_id FROM saved_interview_questions " +
+		"WHERE (user_id, job_id, question_id) IN (($1, $2, $3), ($1, $4, $5), ($1, $6, $7))"
+	if query != want {
+		t.Errorf("query = %s\nwant %s", query, want)
+	}
+	wantArgs := []interface{}{"u1", "job_1", "q1", "job_1", "q2", "job_2", "q1"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+// categorize applies failedJobCategoryRules to message in Go, as failedJobCategory does
+// in SQL: a keyword matches when the lowercase message contains it
+func categorize(message string) string {
+	message = strings.ToLower(message)
+	containsAny := func(keywords []string) bool {
+		for _, keyword := range keywords {
+			if strings.Contains(message, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range failedJobCategoryRules {
+		if containsAny(rule.keywords) && (rule.alsoKeywords == nil || containsAny(rule.alsoKeywords)) {
+			return rule.category
+		}
+	}
+	return models.ErrorCategoryOther
+}
+
+func TestFailedJobCategoryRules(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Text extraction failed: PDF is password protected", models.ErrorCategoryPasswordProtected},
+		{"failed to open PDF: file is Encrypted", models.ErrorCategoryPasswordProtected},
+		{"LLM analysis failed: context deadline exceeded", models.ErrorCategoryLLMTimeout},
+		{"LLM request timed out", models.ErrorCategoryLLMTimeout},
+		{"text extraction timed out or was cancelled: context deadline exceeded", models.ErrorCategoryTimeout},
+		{"Analysis cancelled by user", models.ErrorCategoryCancelled},
+		{"all PDF extraction methods failed: no text content found in PDF", models.ErrorCategoryNoText},
+		{"Insufficient text extracted: 12 characters (minimum 100)", models.ErrorCategoryNoText},
+		{"file content is empty", models.ErrorCategoryNoText},
+		{"Extracted text quality too low (score 0.20, minimum 0.50): mostly symbols", models.ErrorCategoryLowQuality},
+		{"unsupported MIME type: image/png", models.ErrorCategoryUnsupportedFile},
+		{"file is not a valid PDF (MIME type says PDF but signature is zip)", models.ErrorCategoryUnsupportedFile},
+		{"LLM analysis failed: invalid JSON in response", models.ErrorCategoryLLM},
+		{"Embedding generation failed: rate limited", models.ErrorCategoryEmbedding},
+		{"failed to store vectors", models.ErrorCategoryEmbedding},
+		{"disk full", models.ErrorCategoryOther},
+		{"", models.ErrorCategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := categorize(tt.message); got != tt.want {
+				t.Errorf("category of %q = %s, want %s", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailedJobCategoryCase(t *testing.T) {
+	expr := failedJobCategoryCase("m")
+
+	wantStart := "CASE WHEN m LIKE ANY (ARRAY['%password%', '%encrypted%']) THEN 'password_protected'" +
+		" WHEN m LIKE ANY (ARRAY['%timeout%', '%timed out%', '%deadline exceeded%']) AND m LIKE ANY (ARRAY['%llm%']) THEN 'llm_timeout'"
+	if !strings.HasPrefix(expr, wantStart) {
+		t.Errorf("expression starts %s\nwant %s", expr, wantStart)
+	}
+	if !strings.HasSuffix(expr, " ELSE 'other' END") {
+		t.Errorf("expression doesn't end with the other category: %s", expr)
+	}
+
+	// Every rule is a WHEN, in order, so the first matching rule wins
+	whens := regexp.MustCompile(`THEN '(\w+)'`).FindAllStringSubmatch(expr, -1)
+	if len(whens) != len(failedJobCategoryRules) {
+		t.Fatalf("%d WHEN clauses, want %d", len(whens), len(failedJobCategoryRules))
+	}
+	for i, when := range whens {
+		if when[1] != failedJobCategoryRules[i].category {
+			t.Errorf("WHEN %d is %s, want %s", i, when[1], failedJobCategoryRules[i].category)
+		}
+	}
+}
+
+func TestPlanReassign(t *testing.T) {
+	derived := func(jobID, question string) string { return models.QuestionID(jobID, question) }
+	sources := []reassignSource{
+		{id: 1, questionID: derived("job_old", "Why Go?"), question: "Why Go?"},
+		{id: 2, questionID: "custom_1", question: "Tell me about yourself"},
+		{id: 3, questionID: derived("job_old", "Describe a conflict"), question: "Describe a conflict"},
+	}
+
+	tests := []struct {
+		name        string
+		existing    []string // Question IDs already saved under job_new
+		wantCopies  []reassignCopy
+		wantSkipped []string
+	}{
+		{
+			"no conflicts",
+			nil,
+			[]reassignCopy{
+				{id: 1, sourceID: sources[0].questionID, questionID: derived("job_new", "Why Go?")},
+				{id: 2, sourceID: "custom_1", questionID: "custom_1"},
+				{id: 3, sourceID: sources[2].questionID, questionID: derived("job_new", "Describe a conflict")},
+			},
+			nil,
+		},
+		{
+			"derived question already saved",
+			[]string{derived("job_new", "Why Go?")},
+			[]reassignCopy{
+				{id: 2, sourceID: "custom_1", questionID: "custom_1"},
+				{id: 3, sourceID: sources[2].questionID, questionID: derived("job_new", "Describe a conflict")},
+			},
+			[]string{sources[0].questionID},
+		},
+		{
+			"custom question already saved",
+			[]string{"custom_1"},
+			[]reassignCopy{
+				{id: 1, sourceID: sources[0].questionID, questionID: derived("job_new", "Why Go?")},
+				{id: 3, sourceID: sources[2].questionID, questionID: derived("job_new", "Describe a conflict")},
+			},
+			[]string{"custom_1"},
+		},
+		{
+			"everything already saved",
+			[]string{derived("job_new", "Why Go?"), "custom_1", derived("job_new", "Describe a conflict")},
+			nil,
+			[]string{sources[0].questionID, "custom_1", sources[2].questionID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := make(map[string]bool)
+			for _, questionID := range tt.existing {
+				existing[questionID] = true
+			}
+
+			copies, skipped := planReassign(sources, existing, "job_old", "job_new")
+			if !reflect.DeepEqual(copies, tt.wantCopies) {
+				t.Errorf("copies = %+v, want %+v", copies, tt.wantCopies)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+
+	t.Run("sources sharing a target ID", func(t *testing.T) {
+		// A custom ID equal to another source's derived target ID is copied once
+		clash := []reassignSource{
+			{id: 1, questionID: derived("job_old", "Why Go?"), question: "Why Go?"},
+			{id: 2, questionID: derived("job_new", "Why Go?"), question: "Why Go"},
+		}
+		copies, skipped := planReassign(clash, nil, "job_old", "job_new")
+		if len(copies) != 1 || copies[0].id != 1 || !reflect.DeepEqual(skipped, []string{clash[1].questionID}) {
+			t.Errorf("copies = %+v, skipped = %v; want only the first source copied", copies, skipped)
+		}
+	})
+}
+
+func TestPatchProfileQuery(t *testing.T) {
+	summary := "Staff engineer"
+	skills := map[string][]string{"technical": {"Go"}}
+	location := "Berlin"
+
+	tests := []struct {
+		name     string
+		patch    models.ProfilePatch
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			"summary only",
+			models.ProfilePatch{Summary: &summary},
+			"UPDATE user_profile SET summary = $1, " +
+				"field_confidence = COALESCE(field_confidence, '{}'::jsonb) || $2::jsonb, updated_at = CURRENT_TIMESTAMP WHERE job_id = $3",
+			[]interface{}{&summary, []byte(`{"summary":1}`), "job_1"},
+		},
+		{
+			"columns and JSON lists",
+			models.ProfilePatch{Location: &location, Skills: &skills},
+			"UPDATE user_profile SET location = $1, skills = $2, " +
+				"field_confidence = COALESCE(field_confidence, '{}'::jsonb) || $3::jsonb, updated_at = CURRENT_TIMESTAMP WHERE job_id = $4",
+			[]interface{}{&location, []byte(`{"technical":["Go"]}`), []byte(`{"location":1,"skills":1}`), "job_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := patchProfileQuery("job_1", &tt.patch)
+			if err != nil {
+				t.Fatalf("patchProfileQuery: %v", err)
+			}
+			if query = strings.Join(strings.Fields(query), " "); query != tt.want {
+				t.Errorf("query = %s\nwant %s", query, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("empty patch", func(t *testing.T) {
+		if query, args, err := patchProfileQuery("job_1", &models.ProfilePatch{}); query != "" || args != nil || err != nil {
+			t.Errorf("patchProfileQuery = %q, %v, %v; want no query", query, args, err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/lib/pq"
 	"github.com/your-org/websocket-server/internal/repository"
@@ -144,6 +145,74 @@ func (r *SavedQuestionPostgresRepository) GetSavedQuestionsByAuthUserID(ctx cont
 	return questions, nil
 }
 
+// CountSavedQuestions counts all saved questions for a user
+func (r *SavedQuestionPostgresRepository) CountSavedQuestions(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM saved_interview_questions WHERE user_id = $1`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count saved questions: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountSavedQuestionsByAuthUserID counts all saved questions for an authenticated user
+func (r *SavedQuestionPostgresRepository) CountSavedQuestionsByAuthUserID(ctx context.Context, authUserID int) (int, error) {
+	query := `SELECT COUNT(*) FROM saved_interview_questions WHERE auth_user_id = $1`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, authUserID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count saved questions by auth user: %w", err)
+	}
+
+	return count, nil
+}
+
+// tagCountsQuery counts saved questions per tag for the owner column filtered on. Ties
+// are ordered by tag so the list is stable.
+const tagCountsQuery = `
+	SELECT tag, COUNT(DISTINCT q.id) AS question_count
+	FROM saved_interview_questions q, unnest(q.tags) AS tag
+	WHERE q.%s = $1
+	GROUP BY tag
+	ORDER BY question_count DESC, tag ASC
+`
+
+// GetTagCounts counts the saved questions of a user per tag, most used tags first
+func (r *SavedQuestionPostgresRepository) GetTagCounts(ctx context.Context, userID string) ([]*models.TagCount, error) {
+	return r.queryTagCounts(ctx, fmt.Sprintf(tagCountsQuery, "user_id"), userID)
+}
+
+// GetTagCountsByAuthUserID counts the saved questions of an authenticated user per tag, most used tags first
+func (r *SavedQuestionPostgresRepository) GetTagCountsByAuthUserID(ctx context.Context, authUserID int) ([]*models.TagCount, error) {
+	return r.queryTagCounts(ctx, fmt.Sprintf(tagCountsQuery, "auth_user_id"), authUserID)
+}
+
+// queryTagCounts runs a tag count query and scans its rows
+func (r *SavedQuestionPostgresRepository) queryTagCounts(ctx context.Context, query string, owner interface{}) ([]*models.TagCount, error) {
+	rows, err := r.db.QueryContext(ctx, query, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*models.TagCount
+	for rows.Next() {
+		var c models.TagCount
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+		counts = append(counts, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return counts, nil
+}
+
 // GetSavedQuestionsByJob retrieves saved questions for a specific job
 func (r *SavedQuestionPostgresRepository) GetSavedQuestionsByJob(ctx context.Context, userID, jobID string) ([]*models.SavedInterviewQuestion, error) {
 	query := `
@@ -182,6 +251,19 @@ func (r *SavedQuestionPostgresRepository) GetSavedQuestionsByJob(ctx context.Con
 	return questions, nil
 }
 
+// countByJobQuery counts the saved questions of a user for a job without fetching them
+const countByJobQuery = `SELECT COUNT(*) FROM saved_interview_questions WHERE user_id = $1 AND job_id = $2`
+
+// CountSavedQuestionsByJob counts the saved questions of a user for a specific job
+func (r *SavedQuestionPostgresRepository) CountSavedQuestionsByJob(ctx context.Context, userID, jobID string) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, countByJobQuery, userID, jobID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count saved questions by job: %w", err)
+	}
+
+	return count, nil
+}
+
 // IsSaved checks if a question is already saved
 func (r *SavedQuestionPostgresRepository) IsSaved(ctx context.Context, userID, jobID, questionID string) (bool, error) {
 	query := `
@@ -200,6 +282,53 @@ func (r *SavedQuestionPostgresRepository) IsSaved(ctx context.Context, userID, j
 	return exists, nil
 }
 
+// GetSavedRefs returns which of the given questions the user has saved
+func (r *SavedQuestionPostgresRepository) GetSavedRefs(ctx context.Context, userID string, refs []models.SavedQuestionRef) (map[models.SavedQuestionRef]bool, error) {
+	saved := make(map[models.SavedQuestionRef]bool)
+	if len(refs) == 0 {
+		return saved, nil
+	}
+
+	query, args := savedRefsQuery(userID, refs)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check saved questions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref models.SavedQuestionRef
+		if err := rows.Scan(&ref.JobID, &ref.QuestionID); err != nil {
+			return nil, fmt.Errorf("failed to scan saved question: %w", err)
+		}
+		saved[ref] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved questions: %w", err)
+	}
+
+	return saved, nil
+}
+
+// savedRefsQuery builds the query selecting which of refs the user has saved, matching
+// every (user, job, question) tuple at once, and its arguments
+func savedRefsQuery(userID string, refs []models.SavedQuestionRef) (string, []interface{}) {
+	// ($1, $2, $3), ($1, $4, $5), ... with the user ID shared by every tuple
+	args := []interface{}{userID}
+	tuples := make([]string, len(refs))
+	for i, ref := range refs {
+		args = append(args, ref.JobID, ref.QuestionID)
+		tuples[i] = fmt.Sprintf("($1, $%d, $%d)", len(args)-1, len(args))
+	}
+
+	query := `
+		SELECT job_id, question_id FROM saved_interview_questions
+		WHERE (user_id, job_id, question_id) IN (` + strings.Join(tuples, ", ") + `)
+	`
+	return query, args
+}
+
 // DeleteSavedQuestion deletes a saved question
 func (r *SavedQuestionPostgresRepository) DeleteSavedQuestion(ctx context.Context, userID, jobID, questionID string) error {
 	query := `
@@ -224,6 +353,155 @@ func (r *SavedQuestionPostgresRepository) DeleteSavedQuestion(ctx context.Contex
 	return nil
 }
 
+// reassignSource is a saved question to copy to another job
+type reassignSource struct {
+	id         int64
+	questionID string
+	question   string
+}
+
+// reassignCopy copies the saved question with row id to the target job as questionID
+type reassignCopy struct {
+	id         int64
+	sourceID   string // Question ID under the source job
+	questionID string
+}
+
+// planReassign picks the sources copied from fromJobID to toJobID and their question IDs
+// there. Sources whose question ID is in existing, or taken by an earlier source, conflict
+// on (user_id, job_id, question_id) and are skipped; their source question IDs are returned.
+func planReassign(sources []reassignSource, existing map[string]bool, fromJobID, toJobID string) ([]reassignCopy, []string) {
+	taken := make(map[string]bool, len(existing)+len(sources))
+	for questionID := range existing {
+		taken[questionID] = true
+	}
+
+	var copies []reassignCopy
+	var skipped []string
+	for _, s := range sources {
+		questionID := s.questionID
+		if questionID == models.QuestionID(fromJobID, s.question) {
+			questionID = models.QuestionID(toJobID, s.question)
+		}
+		if taken[questionID] {
+			skipped = append(skipped, s.questionID)
+			continue
+		}
+		taken[questionID] = true
+		copies = append(copies, reassignCopy{id: s.id, sourceID: s.questionID, questionID: questionID})
+	}
+	return copies, skipped
+}
+
+// ReassignSavedQuestions copies or moves a user's saved questions to another job in a
+// transaction. Question IDs derived from the source job (see models.QuestionID) are derived
+// again for the target job, so the questions match the target job's generated ones; other
+// IDs are kept. Conflicts on (user_id, job_id, question_id) keep the target's question.
+func (r *SavedQuestionPostgresRepository) ReassignSavedQuestions(ctx context.Context, userID, fromJobID, toJobID string, move bool) (*models.ReassignResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	selectQuery := `
+		SELECT id, question_id, question
+		FROM saved_interview_questions
+		WHERE user_id = $1 AND job_id = $2
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, selectQuery, userID, fromJobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved questions: %w", err)
+	}
+
+	var sources []reassignSource
+	for rows.Next() {
+		var s reassignSource
+		if err := rows.Scan(&s.id, &s.questionID, &s.question); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan saved question: %w", err)
+		}
+		sources = append(sources, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved questions: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	rows, err = tx.QueryContext(ctx, `SELECT question_id FROM saved_interview_questions WHERE user_id = $1 AND job_id = $2`, userID, toJobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved questions of target job: %w", err)
+	}
+	for rows.Next() {
+		var questionID string
+		if err := rows.Scan(&questionID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan saved question: %w", err)
+		}
+		existing[questionID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saved questions: %w", err)
+	}
+
+	// Copies every column but the job and question ID; the row keeps its created_at
+	copyQuery := `
+		INSERT INTO saved_interview_questions (
+			auth_user_id, user_id, job_id, question_id, question, answer,
+			category, difficulty, tags, job_title, company, question_embedding, created_at
+		)
+		SELECT auth_user_id, user_id, $2, $3, question, answer,
+			category, difficulty, tags, job_title, company, question_embedding, created_at
+		FROM saved_interview_questions
+		WHERE id = $1
+		ON CONFLICT (user_id, job_id, question_id) DO NOTHING
+	`
+
+	copies, skipped := planReassign(sources, existing, fromJobID, toJobID)
+	result := &models.ReassignResult{Skipped: len(skipped), SkippedIDs: skipped}
+	for _, c := range copies {
+		res, err := tx.ExecContext(ctx, copyQuery, c.id, toJobID, c.questionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy saved question %s: %w", c.sourceID, err)
+		}
+		copied, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		// A question saved under the target job since it was read also conflicts
+		if copied == 0 {
+			result.Skipped++
+			result.SkippedIDs = append(result.SkippedIDs, c.sourceID)
+		} else {
+			result.Reassigned++
+		}
+	}
+
+	if move && len(sources) > 0 {
+		res, err := tx.ExecContext(ctx, `DELETE FROM saved_interview_questions WHERE user_id = $1 AND job_id = $2`, userID, fromJobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove saved questions: %w", err)
+		}
+		removed, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		result.Removed = int(removed)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
 // UpdateAnswer updates the answer for a saved question
 func (r *SavedQuestionPostgresRepository) UpdateAnswer(ctx context.Context, userID, jobID, questionID, newAnswer string) error {
 	query := `
@@ -249,6 +527,71 @@ func (r *SavedQuestionPostgresRepository) UpdateAnswer(ctx context.Context, user
 	return nil
 }
 
+// GetQuestionsWithoutEmbedding retrieves saved questions that have no stored embedding
+func (r *SavedQuestionPostgresRepository) GetQuestionsWithoutEmbedding(ctx context.Context, afterID int64, limit int) ([]*models.SavedInterviewQuestion, error) {
+	query := `
+		SELECT id, auth_user_id, user_id, job_id, question_id, question, answer,
+			category, difficulty, tags, job_title, company, question_embedding, created_at, updated_at
+		FROM saved_interview_questions
+		WHERE (question_embedding IS NULL OR octet_length(question_embedding) = 0)
+		  AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query questions without embedding: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*models.SavedInterviewQuestion
+	for rows.Next() {
+		var q models.SavedInterviewQuestion
+		err := rows.Scan(
+			&q.ID, &q.AuthUserID, &q.UserID, &q.JobID, &q.QuestionID,
+			&q.Question, &q.Answer, &q.Category, &q.Difficulty,
+			&q.Tags, &q.JobTitle, &q.Company, &q.QuestionEmbedding,
+			&q.CreatedAt, &q.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved question: %w", err)
+		}
+		questions = append(questions, &q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return questions, nil
+}
+
+// UpdateQuestionEmbedding stores the embedding for a saved question
+func (r *SavedQuestionPostgresRepository) UpdateQuestionEmbedding(ctx context.Context, id int64, embedding []byte) error {
+	query := `
+		UPDATE saved_interview_questions
+		SET question_embedding = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, embedding, id)
+	if err != nil {
+		return fmt.Errorf("failed to update question embedding: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("question not found")
+	}
+
+	return nil
+}
+
 // nullString converts an empty string to sql.NullString
 func nullString(s string) sql.NullString {
 	if s == "" {
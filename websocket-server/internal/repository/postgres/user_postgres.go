@@ -13,7 +13,7 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User)
 	query := `
 		INSERT INTO users (name, email, password)
 		VALUES ($1, $2, $3)
-		RETURNING id, name, email, created_at, updated_at
+		RETURNING id, name, email, tier, tenant_id, default_export_format, created_at, updated_at
 	`
 
 	createdUser := &models.User{}
@@ -21,6 +21,9 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User)
 		&createdUser.ID,
 		&createdUser.Name,
 		&createdUser.Email,
+		&createdUser.Tier,
+		&createdUser.TenantID,
+		&createdUser.DefaultExportFormat,
 		&createdUser.CreatedAt,
 		&createdUser.UpdatedAt,
 	)
@@ -35,7 +38,7 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User)
 // GetUserByEmail retrieves a user by email address
 func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
+		SELECT id, name, email, password, tier, tenant_id, default_export_format, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -46,6 +49,9 @@ func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (
 		&user.Name,
 		&user.Email,
 		&user.Password,
+		&user.Tier,
+		&user.TenantID,
+		&user.DefaultExportFormat,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -63,7 +69,7 @@ func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (
 // GetUserByID retrieves a user by ID
 func (r *PostgresRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password, created_at, updated_at
+		SELECT id, name, email, password, tier, tenant_id, default_export_format, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -74,6 +80,9 @@ func (r *PostgresRepository) GetUserByID(ctx context.Context, id int) (*models.U
 		&user.Name,
 		&user.Email,
 		&user.Password,
+		&user.Tier,
+		&user.TenantID,
+		&user.DefaultExportFormat,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -88,6 +97,27 @@ func (r *PostgresRepository) GetUserByID(ctx context.Context, id int) (*models.U
 	return user, nil
 }
 
+// UpdateDefaultExportFormat sets the user's default export format ("" clears it)
+func (r *PostgresRepository) UpdateDefaultExportFormat(ctx context.Context, id int, format string) error {
+	query := `UPDATE users SET default_export_format = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, format, id)
+	if err != nil {
+		return fmt.Errorf("failed to update default export format: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found with ID: %d", id)
+	}
+
+	return nil
+}
+
 // EmailExists checks if an email is already registered
 func (r *PostgresRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
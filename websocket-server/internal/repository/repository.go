@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/your-org/websocket-server/pkg/models"
 )
@@ -16,12 +17,28 @@ type UploadRepository interface {
 	// GetUploadByID retrieves an upload record by its ID
 	GetUploadByID(ctx context.Context, id int) (*models.Upload, error)
 
-	// ListUploads retrieves all upload records with pagination support
-	ListUploads(ctx context.Context, limit, offset int) ([]*models.Upload, error)
-
 	// ListUploadsByUserID retrieves upload records for a specific user with pagination
 	ListUploadsByUserID(ctx context.Context, userID, limit, offset int) ([]*models.Upload, error)
 
+	// ListUploadsFiltered retrieves the upload records matching a filter, in the given order
+	ListUploadsFiltered(ctx context.Context, filter UploadFilter, order UploadSort, limit, offset int) ([]*models.Upload, error)
+
+	// CountUploads counts the upload records matching a filter
+	CountUploads(ctx context.Context, filter UploadFilter) (int, error)
+
+	// OwnsUpload reports whether the upload belongs to the given user.
+	// Anonymous uploads (no user_id) belong to no one.
+	OwnsUpload(ctx context.Context, userID, uploadID int) (bool, error)
+
+	// SetUploadPinned pins or unpins an upload; pinned uploads are exempt from retention cleanup
+	SetUploadPinned(ctx context.Context, id int, pinned bool) error
+
+	// UpdateUploadMetadata replaces the tags and notes of an upload
+	UpdateUploadMetadata(ctx context.Context, id int, tags []string, notes string) error
+
+	// ListExpiredUploadIDs returns IDs of unpinned uploads created before the given time, oldest first
+	ListExpiredUploadIDs(ctx context.Context, before time.Time, limit int) ([]int, error)
+
 	// DeleteUpload removes an upload record by its ID
 	DeleteUpload(ctx context.Context, id int) error
 
@@ -32,3 +49,26 @@ type UploadRepository interface {
 	// Close closes the database connection and releases resources
 	Close() error
 }
+
+// UploadFilter selects upload records. Zero-valued fields don't filter.
+type UploadFilter struct {
+	UserID        *int       // Uploads of this user
+	TenantID      *string    // Uploads of this tenant ("" is the default tenant)
+	Status        string     // Uploads whose latest analysis job has this status, or models.UploadStatusNotAnalyzed
+	Tag           string     // Uploads carrying this tag
+	CreatedFrom   *time.Time // Uploads created at or after this time
+	CreatedBefore *time.Time // Uploads created before this time
+}
+
+// Fields upload lists can be sorted by
+const (
+	UploadSortCreatedAt = "created_at"
+	UploadSortFileSize  = "file_size"
+	UploadSortName      = "name" // File name, ignoring case
+)
+
+// UploadSort orders upload lists. The zero value lists the newest uploads first.
+type UploadSort struct {
+	Field     string // One of the UploadSort* fields (empty = UploadSortCreatedAt)
+	Ascending bool
+}
@@ -20,15 +20,46 @@ type SavedQuestionRepository interface {
 	// GetSavedQuestionsByAuthUserID retrieves saved questions for an authenticated user
 	GetSavedQuestionsByAuthUserID(ctx context.Context, authUserID, limit, offset int) ([]*models.SavedInterviewQuestion, error)
 
+	// CountSavedQuestions counts all saved questions for a user
+	CountSavedQuestions(ctx context.Context, userID string) (int, error)
+
+	// CountSavedQuestionsByAuthUserID counts all saved questions for an authenticated user
+	CountSavedQuestionsByAuthUserID(ctx context.Context, authUserID int) (int, error)
+
+	// GetTagCounts counts the saved questions of a user per tag, most used tags first
+	GetTagCounts(ctx context.Context, userID string) ([]*models.TagCount, error)
+
+	// GetTagCountsByAuthUserID counts the saved questions of an authenticated user per tag, most used tags first
+	GetTagCountsByAuthUserID(ctx context.Context, authUserID int) ([]*models.TagCount, error)
+
 	// GetSavedQuestionsByJob retrieves saved questions for a specific job
 	GetSavedQuestionsByJob(ctx context.Context, userID, jobID string) ([]*models.SavedInterviewQuestion, error)
 
+	// CountSavedQuestionsByJob counts the saved questions of a user for a specific job
+	CountSavedQuestionsByJob(ctx context.Context, userID, jobID string) (int, error)
+
 	// IsSaved checks if a question is already saved
 	IsSaved(ctx context.Context, userID, jobID, questionID string) (bool, error)
 
+	// GetSavedRefs returns which of the given questions the user has saved, in one query.
+	// Questions not saved are absent from the result.
+	GetSavedRefs(ctx context.Context, userID string, refs []models.SavedQuestionRef) (map[models.SavedQuestionRef]bool, error)
+
 	// DeleteSavedQuestion deletes a saved question
 	DeleteSavedQuestion(ctx context.Context, userID, jobID, questionID string) error
 
+	// ReassignSavedQuestions copies a user's saved questions of fromJobID to toJobID, or
+	// moves them when move is set. Questions already saved under toJobID are kept and the
+	// copies skipped; a move still removes them from fromJobID.
+	ReassignSavedQuestions(ctx context.Context, userID, fromJobID, toJobID string, move bool) (*models.ReassignResult, error)
+
 	// UpdateAnswer updates the answer for a saved question
 	UpdateAnswer(ctx context.Context, userID, jobID, questionID, newAnswer string) error
+
+	// GetQuestionsWithoutEmbedding retrieves saved questions with a NULL or empty embedding,
+	// ordered by ID and starting after afterID (keyset pagination)
+	GetQuestionsWithoutEmbedding(ctx context.Context, afterID int64, limit int) ([]*models.SavedInterviewQuestion, error)
+
+	// UpdateQuestionEmbedding stores the embedding for a saved question
+	UpdateQuestionEmbedding(ctx context.Context, id int64, embedding []byte) error
 }
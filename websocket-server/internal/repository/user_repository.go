@@ -17,6 +17,10 @@ type UserRepository interface {
 	// GetUserByID retrieves a user by ID
 	GetUserByID(ctx context.Context, id int) (*models.User, error)
 
+	// UpdateDefaultExportFormat sets the export format used when the user's export
+	// requests name none; an empty format clears the preference
+	UpdateDefaultExportFormat(ctx context.Context, id int, format string) error
+
 	// EmailExists checks if an email is already registered
 	EmailExists(ctx context.Context, email string) (bool, error)
 }
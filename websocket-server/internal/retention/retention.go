@@ -0,0 +1,184 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+)
+
+// Config holds configuration for the retention policy
+type Config struct {
+	MaxAge    time.Duration // Uploads older than this are deleted unless pinned (0 disables cleanup)
+	Interval  time.Duration // Time between cleanup runs
+	BatchSize int           // Uploads deleted per query batch
+
+	// Forgetter is told about deleted uploads so cached data derived from them is dropped,
+	// e.g. the analyzer's similar profiles (nil = none)
+	Forgetter analyzer.UploadForgetter
+}
+
+// Result summarizes a single cleanup run
+type Result struct {
+	Uploads    int `json:"uploads"`    // Uploads deleted
+	Jobs       int `json:"jobs"`       // Analysis jobs deleted
+	Embeddings int `json:"embeddings"` // Uploads whose embeddings were removed from the vector store
+	Failed     int `json:"failed"`     // Uploads that could not be deleted
+}
+
+// Cleaner periodically deletes uploads past the retention window together with
+// their analysis jobs, profiles and embeddings. Pinned uploads are kept.
+type Cleaner struct {
+	uploadRepo   repository.UploadRepository
+	analysisRepo repository.AnalysisRepository
+	vectorStore  analyzer.VectorStore     // Optional
+	forgetter    analyzer.UploadForgetter // Optional
+	maxAge       time.Duration
+	interval     time.Duration
+	batchSize    int
+	mu           sync.Mutex // Ensures only one run is active at a time
+}
+
+// NewCleaner creates a new retention cleaner
+func NewCleaner(uploadRepo repository.UploadRepository, analysisRepo repository.AnalysisRepository, vectorStore analyzer.VectorStore, config *Config) *Cleaner {
+	if config == nil {
+		config = &Config{
+			MaxAge:    90 * 24 * time.Hour,
+			Interval:  24 * time.Hour,
+			BatchSize: 100,
+		}
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	return &Cleaner{
+		uploadRepo:   uploadRepo,
+		analysisRepo: analysisRepo,
+		vectorStore:  vectorStore,
+		forgetter:    config.Forgetter,
+		maxAge:       config.MaxAge,
+		interval:     interval,
+		batchSize:    batchSize,
+	}
+}
+
+// Start runs cleanup immediately and then on every interval until ctx is cancelled.
+// It does nothing when MaxAge is 0.
+func (c *Cleaner) Start(ctx context.Context) {
+	if c.maxAge <= 0 {
+		log.Printf("Retention cleanup disabled (no max age configured)")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := c.RunOnce(ctx); err != nil {
+				log.Printf("Retention cleanup failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Printf("Retention cleanup worker stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce deletes all unpinned uploads older than the retention window
+func (c *Cleaner) RunOnce(ctx context.Context) (*Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := &Result{}
+	if c.maxAge <= 0 {
+		return result, nil
+	}
+
+	cutoff := time.Now().Add(-c.maxAge)
+	failed := make(map[int]bool)
+
+	for {
+		ids, err := c.uploadRepo.ListExpiredUploadIDs(ctx, cutoff, c.batchSize+len(failed))
+		if err != nil {
+			return result, fmt.Errorf("failed to list expired uploads: %w", err)
+		}
+
+		// Skip uploads that already failed this run so they don't loop forever
+		pending := ids[:0]
+		for _, id := range ids {
+			if !failed[id] {
+				pending = append(pending, id)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		for _, id := range pending {
+			if err := c.deleteUpload(ctx, id, result); err != nil {
+				log.Printf("Warning: retention cleanup failed for upload %d: %v", id, err)
+				failed[id] = true
+				result.Failed++
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	log.Printf("Retention cleanup (older than %s): deleted %d uploads, %d jobs, embeddings for %d uploads; %d failed",
+		cutoff.Format(time.RFC3339), result.Uploads, result.Jobs, result.Embeddings, result.Failed)
+
+	return result, nil
+}
+
+// deleteUpload removes an upload and everything derived from it, updating result counts
+func (c *Cleaner) deleteUpload(ctx context.Context, uploadID int, result *Result) error {
+	if c.vectorStore != nil {
+		if err := c.vectorStore.DeleteByUploadID(ctx, uploadID); err != nil {
+			log.Printf("Warning: failed to delete embeddings for upload %d: %v", uploadID, err)
+		} else {
+			result.Embeddings++
+		}
+	}
+
+	jobs, err := c.analysisRepo.GetJobsByUploadID(ctx, uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	// Profiles depend on jobs, so delete them first
+	if err := c.analysisRepo.DeleteProfilesByUploadID(ctx, uploadID); err != nil {
+		return fmt.Errorf("failed to delete profiles: %w", err)
+	}
+	if err := c.analysisRepo.DeleteJobsByUploadID(ctx, uploadID); err != nil {
+		return fmt.Errorf("failed to delete jobs: %w", err)
+	}
+	if err := c.uploadRepo.DeleteUpload(ctx, uploadID); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	if c.forgetter != nil {
+		c.forgetter.ForgetUpload(uploadID)
+	}
+
+	result.Jobs += len(jobs)
+	result.Uploads++
+	return nil
+}
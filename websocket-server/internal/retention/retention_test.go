@@ -0,0 +1,201 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// store holds the data the fake repositories share
+type store struct {
+	uploads    map[int]*models.Upload
+	jobs       map[int]int  // Jobs per upload ID
+	profiles   map[int]bool // Upload IDs with a profile
+	embeddings map[int]bool // Upload IDs with embeddings
+	failDelete map[int]bool // Upload IDs whose deletion fails
+}
+
+// newStore creates a store with an upload of each age in days (IDs from 1), each with one
+// job, a profile and embeddings. Uploads listed in pinned are pinned.
+func newStore(ages []int, pinned ...int) *store {
+	s := &store{
+		uploads:    make(map[int]*models.Upload),
+		jobs:       make(map[int]int),
+		profiles:   make(map[int]bool),
+		embeddings: make(map[int]bool),
+		failDelete: make(map[int]bool),
+	}
+	for i, age := range ages {
+		id := i + 1
+		s.uploads[id] = &models.Upload{ID: id, CreatedAt: time.Now().AddDate(0, 0, -age)}
+		s.jobs[id] = 1
+		s.profiles[id] = true
+		s.embeddings[id] = true
+	}
+	for _, id := range pinned {
+		s.uploads[id].Pinned = true
+	}
+	return s
+}
+
+// remaining returns the IDs of the uploads still stored, sorted
+func (s *store) remaining() []int {
+	ids := []int{}
+	for id := range s.uploads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// fakeUploads serves the store's uploads. Methods a test needs but the fake doesn't
+// implement panic through the embedded nil interface.
+type fakeUploads struct {
+	repository.UploadRepository
+	*store
+}
+
+func (f fakeUploads) ListExpiredUploadIDs(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	var ids []int
+	for _, id := range f.remaining() {
+		if upload := f.uploads[id]; !upload.Pinned && upload.CreatedAt.Before(before) && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f fakeUploads) DeleteUpload(ctx context.Context, id int) error {
+	if f.failDelete[id] {
+		return errors.New("delete failed")
+	}
+	delete(f.uploads, id)
+	return nil
+}
+
+// fakeAnalysis serves the store's jobs and profiles. Methods a test needs but the fake
+// doesn't implement panic through the embedded nil interface.
+type fakeAnalysis struct {
+	repository.AnalysisRepository
+	*store
+}
+
+func (f fakeAnalysis) GetJobsByUploadID(ctx context.Context, uploadID int) ([]*models.AnalysisJob, error) {
+	jobs := make([]*models.AnalysisJob, f.jobs[uploadID])
+	for i := range jobs {
+		jobs[i] = &models.AnalysisJob{UploadID: uploadID}
+	}
+	return jobs, nil
+}
+
+func (f fakeAnalysis) DeleteProfilesByUploadID(ctx context.Context, uploadID int) error {
+	delete(f.profiles, uploadID)
+	return nil
+}
+
+func (f fakeAnalysis) DeleteJobsByUploadID(ctx context.Context, uploadID int) error {
+	delete(f.jobs, uploadID)
+	return nil
+}
+
+// fakeVectorStore drops the store's embeddings. Methods a test needs but the fake
+// doesn't implement panic through the embedded nil interface.
+type fakeVectorStore struct {
+	analyzer.VectorStore
+	*store
+}
+
+func (f fakeVectorStore) DeleteByUploadID(ctx context.Context, uploadID int) error {
+	delete(f.embeddings, uploadID)
+	return nil
+}
+
+// recordingForgetter records the uploads it was told to forget
+type recordingForgetter struct {
+	forgotten []int
+}
+
+func (f *recordingForgetter) ForgetUpload(uploadID int) {
+	f.forgotten = append(f.forgotten, uploadID)
+}
+
+func TestCleanerRunOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		ages          []int // Upload ages in days
+		pinned        []int
+		failDelete    []int
+		batchSize     int
+		want          Result
+		wantRemaining []int
+	}{
+		{"nothing expired", []int{1, 10}, nil, nil, 10, Result{}, []int{1, 2}},
+		{"expired removed", []int{1, 45, 10, 100}, nil, nil, 10, Result{Uploads: 2, Jobs: 2, Embeddings: 2}, []int{1, 3}},
+		{"pinned kept", []int{45, 100, 5}, []int{2}, nil, 10, Result{Uploads: 1, Jobs: 1, Embeddings: 1}, []int{2, 3}},
+		{"several batches", []int{40, 50, 60, 70, 80}, nil, nil, 2, Result{Uploads: 5, Jobs: 5, Embeddings: 5}, []int{}},
+		{"failures kept and counted", []int{40, 50, 60}, nil, []int{2}, 1, Result{Uploads: 2, Jobs: 2, Embeddings: 3, Failed: 1}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(tt.ages, tt.pinned...)
+			for _, id := range tt.failDelete {
+				s.failDelete[id] = true
+			}
+			forgetter := &recordingForgetter{}
+			cleaner := NewCleaner(fakeUploads{store: s}, fakeAnalysis{store: s}, fakeVectorStore{store: s}, &Config{
+				MaxAge:    30 * 24 * time.Hour,
+				BatchSize: tt.batchSize,
+				Forgetter: forgetter,
+			})
+
+			result, err := cleaner.RunOnce(context.Background())
+			if err != nil {
+				t.Fatalf("RunOnce: %v", err)
+			}
+			if *result != tt.want {
+				t.Errorf("result = %+v, want %+v", *result, tt.want)
+			}
+
+			remaining := s.remaining()
+			if !reflect.DeepEqual(remaining, tt.wantRemaining) {
+				t.Errorf("remaining uploads = %v, want %v", remaining, tt.wantRemaining)
+			}
+			if len(forgetter.forgotten) != tt.want.Uploads {
+				t.Errorf("forgot %v, want %d uploads", forgetter.forgotten, tt.want.Uploads)
+			}
+
+			// Derived data of unexpired uploads is kept, that of deleted ones removed
+			for id := range tt.ages {
+				id++
+				_, kept := s.uploads[id]
+				if kept && !s.failDelete[id] && (s.jobs[id] == 0 || !s.profiles[id]) {
+					t.Errorf("upload %d kept without its job or profile", id)
+				}
+				if !kept && (s.jobs[id] != 0 || s.profiles[id] || s.embeddings[id]) {
+					t.Errorf("upload %d deleted but its derived data kept", id)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanerDisabled(t *testing.T) {
+	s := newStore([]int{400})
+	cleaner := NewCleaner(fakeUploads{store: s}, fakeAnalysis{store: s}, nil, &Config{})
+
+	result, err := cleaner.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if *result != (Result{}) || len(s.uploads) != 1 {
+		t.Errorf("disabled cleanup = %+v with %d uploads left, want nothing deleted", *result, len(s.uploads))
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the HTTP server timeouts
+type Config struct {
+	ReadHeaderTimeout time.Duration // Time allowed to read request headers (guards against slowloris)
+	ReadTimeout       time.Duration // Time allowed to read the whole request, including the body
+	WriteTimeout      time.Duration // Time allowed from the end of the request headers to the end of the response
+	IdleTimeout       time.Duration // Time a keep-alive connection may sit idle between requests
+
+	// LongLivedPaths are exempt from ReadTimeout and WriteTimeout, e.g. the
+	// WebSocket endpoint and any SSE/streaming endpoints. A trailing "/" matches a prefix.
+	LongLivedPaths []string
+}
+
+// DefaultLongLivedPaths lists the endpoints that keep connections open
+var DefaultLongLivedPaths = []string{"/ws"}
+
+// DefaultConfig returns the timeouts used when none are configured
+func DefaultConfig() *Config {
+	return &Config{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      3 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		LongLivedPaths:    DefaultLongLivedPaths,
+	}
+}
+
+// ConfigFromEnv reads the timeouts from the SERVER_READ_HEADER_TIMEOUT,
+// SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT environment
+// variables (Go durations such as "30s"), using DefaultConfig for unset ones
+func ConfigFromEnv() (*Config, error) {
+	config := DefaultConfig()
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", &config.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", &config.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", &config.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", &config.IdleTimeout},
+	}
+	for _, d := range durations {
+		raw := strings.TrimSpace(os.Getenv(d.name))
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid %s %q: want a duration such as 30s", d.name, raw)
+		}
+		*d.value = value
+	}
+
+	return config, nil
+}
+
+// New creates an http.Server with the configured timeouts. A nil config uses
+// DefaultConfig, and a config without LongLivedPaths exempts DefaultLongLivedPaths.
+// WriteTimeout must cover the slowest regular handler (question generation allows up to 150s).
+func New(addr string, handler http.Handler, config *Config) *http.Server {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	longLivedPaths := config.LongLivedPaths
+	if len(longLivedPaths) == 0 {
+		longLivedPaths = DefaultLongLivedPaths
+	}
+
+	readHeaderTimeout := config.ReadHeaderTimeout
+	if readHeaderTimeout <= 0 {
+		readHeaderTimeout = config.ReadTimeout
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           WithoutDeadlines(handler, longLivedPaths),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+}
+
+// WithoutDeadlines clears the server's read and write deadlines for requests to
+// the given paths so that long-lived connections are not cut off by the server timeouts
+func WithoutDeadlines(next http.Handler, paths []string) http.Handler {
+	if len(paths) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if matchesPath(r.URL.Path, paths) {
+			rc := http.NewResponseController(w)
+			if err := rc.SetReadDeadline(time.Time{}); err != nil {
+				log.Printf("Warning: failed to clear read deadline for %s: %v", r.URL.Path, err)
+			}
+			if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+				log.Printf("Warning: failed to clear write deadline for %s: %v", r.URL.Path, err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matchesPath reports whether path equals one of paths, or starts with one ending in "/"
+func matchesPath(path string, paths []string) bool {
+	for _, p := range paths {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
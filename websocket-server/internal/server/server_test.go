@@ -0,0 +1,166 @@
+package server
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// start serves handler through New on a loopback listener and returns its base URL
+func start(t *testing.T, handler http.Handler, config *Config) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := New(ln.Addr().String(), handler, config)
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	return "http://" + ln.Addr().String()
+}
+
+// slowThenStream writes nothing for delay and then streams "chunk" lines
+func slowThenStream(delay time.Duration, chunks int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		for i := 0; i < chunks; i++ {
+			io.WriteString(w, "chunk\n")
+			http.NewResponseController(w).Flush()
+			time.Sleep(delay / 4)
+		}
+	}
+}
+
+func TestWriteTimeoutCutsSlowRequestsButNotLongLivedPaths(t *testing.T) {
+	const writeTimeout = 100 * time.Millisecond
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/slow", slowThenStream(3*writeTimeout, 4))
+	mux.Handle("/ws", slowThenStream(3*writeTimeout, 4))
+
+	// A non-nil config without LongLivedPaths must still exempt /ws
+	base := start(t, mux, &Config{WriteTimeout: writeTimeout})
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	t.Run("slow request is cut off", func(t *testing.T) {
+		resp, err := client.Get(base + "/api/slow")
+		if err == nil {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr == nil {
+				t.Fatalf("expected the response to be cut off, got %q", body)
+			}
+		}
+	})
+
+	t.Run("long-lived path streams past the deadline", func(t *testing.T) {
+		resp, err := client.Get(base + "/ws")
+		if err != nil {
+			t.Fatalf("GET /ws: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read /ws: %v", err)
+		}
+		if got := strings.Count(string(body), "chunk"); got != 4 {
+			t.Errorf("expected 4 chunks, got %d (%q)", got, body)
+		}
+	})
+}
+
+func TestReadHeaderTimeoutDropsSlowHeaders(t *testing.T) {
+	base := start(t, http.NotFoundHandler(), &Config{ReadHeaderTimeout: 100 * time.Millisecond})
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(base, "http://"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Send a partial request line and never finish the headers
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: x\r\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("expected the server to close the connection, got %v", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		for _, name := range []string{"SERVER_READ_HEADER_TIMEOUT", "SERVER_READ_TIMEOUT", "SERVER_WRITE_TIMEOUT", "SERVER_IDLE_TIMEOUT"} {
+			t.Setenv(name, "")
+		}
+
+		config, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatalf("ConfigFromEnv: %v", err)
+		}
+		if config.ReadHeaderTimeout != 10*time.Second || config.ReadTimeout != 30*time.Second ||
+			config.WriteTimeout != 3*time.Minute || config.IdleTimeout != 2*time.Minute {
+			t.Errorf("expected the default timeouts, got %+v", config)
+		}
+		if len(config.LongLivedPaths) != 1 || config.LongLivedPaths[0] != "/ws" {
+			t.Errorf("expected /ws to be long-lived, got %v", config.LongLivedPaths)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("SERVER_READ_HEADER_TIMEOUT", "5s")
+		t.Setenv("SERVER_READ_TIMEOUT", "")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "4m")
+		t.Setenv("SERVER_IDLE_TIMEOUT", " 90s ")
+
+		config, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatalf("ConfigFromEnv: %v", err)
+		}
+		if config.ReadHeaderTimeout != 5*time.Second || config.ReadTimeout != 30*time.Second ||
+			config.WriteTimeout != 4*time.Minute || config.IdleTimeout != 90*time.Second {
+			t.Errorf("unexpected config %+v", config)
+		}
+	})
+
+	for _, value := range []string{"soon", "30", "-1s"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			t.Setenv("SERVER_WRITE_TIMEOUT", value)
+
+			if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), "SERVER_WRITE_TIMEOUT") {
+				t.Errorf("expected an error naming SERVER_WRITE_TIMEOUT, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMatchesPath(t *testing.T) {
+	paths := []string{"/ws", "/api/stream/"}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/ws", true},
+		{"/ws/extra", false},
+		{"/wsx", false},
+		{"/api/stream/chat", true},
+		{"/api/stream", false},
+		{"/api/slow", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := matchesPath(tt.path, paths); got != tt.want {
+				t.Errorf("matchesPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PostgresFileStore stores file content in a BYTEA column of the file_blobs table
+type PostgresFileStore struct {
+	db *sql.DB
+}
+
+// NewPostgresFileStore creates a new Postgres-backed file store
+func NewPostgresFileStore(db *sql.DB) *PostgresFileStore {
+	return &PostgresFileStore{db: db}
+}
+
+// Put stores content and returns its generated key
+func (s *PostgresFileStore) Put(ctx context.Context, content []byte, contentType string) (string, error) {
+	key := uuid.New().String()
+
+	query := `INSERT INTO file_blobs (key, content, content_type) VALUES ($1, $2, $3)`
+	if _, err := s.db.ExecContext(ctx, query, key, content, contentType); err != nil {
+		return "", fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return key, nil
+}
+
+// Get retrieves the content stored under key
+func (s *PostgresFileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	query := `SELECT content FROM file_blobs WHERE key = $1`
+
+	var content []byte
+	err := s.db.QueryRowContext(ctx, query, key).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	return content, nil
+}
+
+// Delete removes the content stored under key
+func (s *PostgresFileStore) Delete(ctx context.Context, key string) error {
+	query := `DELETE FROM file_blobs WHERE key = $1`
+	if _, err := s.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBucket is an in-memory S3 bucket serving path-style object requests
+type fakeBucket struct {
+	mu           sync.Mutex
+	objects      map[string][]byte // By request path
+	contentTypes map[string]string
+	unsigned     int // Requests without a SigV4 Authorization header
+}
+
+func newFakeBucket() *fakeBucket {
+	return &fakeBucket{objects: make(map[string][]byte), contentTypes: make(map[string]string)}
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key-id/") || r.Header.Get("X-Amz-Date") == "" {
+		b.unsigned++
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		b.objects[r.URL.Path] = content
+		b.contentTypes[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		content, ok := b.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	case http.MethodDelete:
+		delete(b.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestS3Store(t *testing.T, bucket *fakeBucket) *S3FileStore {
+	t.Helper()
+
+	server := httptest.NewServer(bucket)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3FileStore(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "resumes",
+		AccessKeyID:     "key-id",
+		SecretAccessKey: "secret",
+		Prefix:          "uploads/",
+	})
+	if err != nil {
+		t.Fatalf("NewS3FileStore: %v", err)
+	}
+	return store
+}
+
+func TestS3FileStoreRoundTrip(t *testing.T) {
+	bucket := newFakeBucket()
+	store := newTestS3Store(t, bucket)
+	ctx := context.Background()
+
+	content := []byte("%PDF-1.4 resume bytes")
+	key, err := store.Put(ctx, content, "application/pdf")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if !strings.HasPrefix(key, "uploads/") {
+		t.Errorf("key %q lacks the configured prefix", key)
+	}
+	if ct := bucket.contentTypes["/resumes/"+key]; ct != "application/pdf" {
+		t.Errorf("stored content type = %q", ct)
+	}
+
+	got, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+
+	// Deleting a missing key is not an error
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+	if bucket.unsigned != 0 {
+		t.Errorf("%d requests were not signed", bucket.unsigned)
+	}
+}
+
+func TestS3FileStorePutKeysAreUnique(t *testing.T) {
+	store := newTestS3Store(t, newFakeBucket())
+	ctx := context.Background()
+
+	first, err := store.Put(ctx, []byte("a"), "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Put(ctx, []byte("b"), "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("both uploads stored under %q", first)
+	}
+
+	for key, want := range map[string]string{first: "a", second: "b"} {
+		got, err := store.Get(ctx, key)
+		if err != nil || string(got) != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+}
+
+func TestNewFileStore(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		want   string // Type of the store, "" for an error
+	}{
+		{"default", nil, "postgres"},
+		{"postgres", &Config{Backend: "Postgres"}, "postgres"},
+		{"s3", &Config{Backend: "s3", S3: S3Config{Endpoint: "http://localhost:9000", Bucket: "b", AccessKeyID: "k", SecretAccessKey: "s"}}, "s3"},
+		{"s3 without bucket", &Config{Backend: "s3", S3: S3Config{Endpoint: "http://localhost:9000", AccessKeyID: "k", SecretAccessKey: "s"}}, ""},
+		{"s3 without credentials", &Config{Backend: "s3", S3: S3Config{Endpoint: "http://localhost:9000", Bucket: "b"}}, ""},
+		{"unknown backend", &Config{Backend: "ftp"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewFileStore(tt.config, nil)
+			got := ""
+			switch store.(type) {
+			case *PostgresFileStore:
+				got = "postgres"
+			case *S3FileStore:
+				got = "s3"
+			}
+			if got != tt.want || (err != nil) != (tt.want == "") {
+				t.Errorf("NewFileStore = %T, %v, want %s", store, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeS3Path(t *testing.T) {
+	if got, want := escapeS3Path("/bucket/uploads/a b+c~d.pdf"), "/bucket/uploads/a%20b%2Bc~d.pdf"; got != want {
+		t.Errorf("escapeS3Path = %q, want %q", got, want)
+	}
+}
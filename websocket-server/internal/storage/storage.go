@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when no file exists for a key
+var ErrNotFound = errors.New("file not found")
+
+// FileStore stores uploaded file content outside of the upload metadata.
+// Keys are generated by the store and are opaque to callers.
+type FileStore interface {
+	// Put stores content and returns the key used to retrieve it
+	Put(ctx context.Context, content []byte, contentType string) (key string, err error)
+
+	// Get retrieves the content stored under key
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the content stored under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Backend names accepted by Config.Backend
+const (
+	BackendPostgres = "postgres"
+	BackendS3       = "s3"
+)
+
+// Config selects and configures the file storage backend
+type Config struct {
+	Backend string   // postgres (default) or s3
+	S3      S3Config // Used when Backend is s3
+}
+
+// NewFileStore creates the file store selected by config.
+// The Postgres backend stores content in the given database.
+func NewFileStore(config *Config, db *sql.DB) (FileStore, error) {
+	backend := BackendPostgres
+	if config != nil && config.Backend != "" {
+		backend = strings.ToLower(config.Backend)
+	}
+
+	switch backend {
+	case BackendPostgres:
+		return NewPostgresFileStore(db), nil
+	case BackendS3:
+		// Return a nil interface, not a nil *S3FileStore, when the config is invalid
+		store, err := NewS3FileStore(config.S3)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q (use %s or %s)", backend, BackendPostgres, BackendS3)
+	}
+}
@@ -0,0 +1,189 @@
+package tier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+)
+
+// Well-known tier names
+const (
+	Free = "free"
+	Paid = "paid"
+)
+
+// Plan is the set of clients and limits that apply to a tier.
+// Embedders of all plans should produce embeddings of the same model/dimension,
+// since saved question embeddings are compared across requests.
+type Plan struct {
+	Tier              string
+	LLMClient         analyzer.LLMClient
+	Embedder          analyzer.EmbeddingGenerator
+	RequestsPerMinute int // Expensive requests allowed per user per minute (0 = unlimited)
+
+	limiter *keyedLimiter
+}
+
+// Allow records a request for key and reports whether it is within the plan's rate limit
+func (p *Plan) Allow(key string) bool {
+	if p.RequestsPerMinute <= 0 {
+		return true
+	}
+	return p.limiter.allow(key)
+}
+
+// Authenticator identifies the user making a request
+type Authenticator interface {
+	UserIDFromRequest(r *http.Request) (int, bool)
+}
+
+// Resolver selects the plan that applies to a request. It also returns the key
+// that identifies the caller for rate limiting (the user, or the client address).
+type Resolver interface {
+	Resolve(r *http.Request) (*Plan, string)
+}
+
+// NewPlan prepares a plan for use, filling in the default plan's clients where unset
+func NewPlan(plan Plan, fallback *Plan) *Plan {
+	if fallback != nil {
+		if plan.LLMClient == nil {
+			plan.LLMClient = fallback.LLMClient
+		}
+		if plan.Embedder == nil {
+			plan.Embedder = fallback.Embedder
+		}
+	}
+	plan.limiter = newKeyedLimiter(plan.RequestsPerMinute, time.Minute)
+	return &plan
+}
+
+// StaticResolver applies the same plan to every request
+type StaticResolver struct {
+	plan *Plan
+}
+
+// NewStaticResolver creates a resolver that always returns plan
+func NewStaticResolver(plan *Plan) *StaticResolver {
+	return &StaticResolver{plan: plan}
+}
+
+// Resolve returns the static plan
+func (s *StaticResolver) Resolve(r *http.Request) (*Plan, string) {
+	return s.plan, clientKey(r)
+}
+
+// UserResolver selects the plan from the authenticated user's tier
+type UserResolver struct {
+	auth        Authenticator
+	users       repository.UserRepository
+	plans       map[string]*Plan
+	defaultPlan *Plan // Used for anonymous users and tiers without a plan
+}
+
+// NewUserResolver creates a resolver that looks up the tier of the authenticated user
+func NewUserResolver(auth Authenticator, users repository.UserRepository, defaultPlan *Plan, plans map[string]*Plan) *UserResolver {
+	normalized := make(map[string]*Plan, len(plans))
+	for name, plan := range plans {
+		normalized[strings.ToLower(name)] = plan
+	}
+
+	return &UserResolver{
+		auth:        auth,
+		users:       users,
+		plans:       normalized,
+		defaultPlan: defaultPlan,
+	}
+}
+
+// Resolve returns the plan for the request's user, or the default plan
+func (u *UserResolver) Resolve(r *http.Request) (*Plan, string) {
+	userID, ok := u.auth.UserIDFromRequest(r)
+	if !ok {
+		return u.defaultPlan, clientKey(r)
+	}
+	key := fmt.Sprintf("user:%d", userID)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		log.Printf("Warning: failed to look up tier for user %d, using default plan: %v", userID, err)
+		return u.defaultPlan, key
+	}
+
+	if plan, ok := u.plans[strings.ToLower(user.Tier)]; ok {
+		return plan, key
+	}
+	return u.defaultPlan, key
+}
+
+// clientKey identifies an anonymous caller by network address
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// keyedLimiter allows at most max events per key per sliding window
+type keyedLimiter struct {
+	mu        sync.Mutex
+	max       int
+	window    time.Duration
+	events    map[string][]time.Time
+	lastPrune time.Time
+}
+
+// newKeyedLimiter creates a per-key sliding-window rate limiter
+func newKeyedLimiter(max int, window time.Duration) *keyedLimiter {
+	return &keyedLimiter{
+		max:    max,
+		window: window,
+		events: make(map[string][]time.Time),
+	}
+}
+
+// allow records an event for key and reports whether it is within the limit
+func (l *keyedLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	// Forget keys whose window is empty once per window, so the map doesn't keep an
+	// entry for every user and address ever seen
+	if now.Sub(l.lastPrune) >= l.window {
+		for k, events := range l.events {
+			if !events[len(events)-1].After(cutoff) {
+				delete(l.events, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	// Drop events that fell out of the window
+	kept := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= l.max {
+		l.events[key] = kept
+		return false
+	}
+	l.events[key] = append(kept, now)
+	return true
+}
@@ -0,0 +1,149 @@
+package tier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/your-org/websocket-server/internal/analyzer"
+	"github.com/your-org/websocket-server/internal/repository"
+	"github.com/your-org/websocket-server/pkg/models"
+)
+
+// headerAuth authenticates requests by the X-Test-User header
+type headerAuth struct{}
+
+func (headerAuth) UserIDFromRequest(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.Header.Get("X-Test-User"))
+	return id, err == nil
+}
+
+// fakeUsers serves users by ID. Methods a test needs but the fake doesn't implement
+// panic through the embedded nil interface.
+type fakeUsers struct {
+	repository.UserRepository
+
+	users map[int]*models.User
+}
+
+func (f *fakeUsers) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, fmt.Errorf("no user %d", id)
+	}
+	return user, nil
+}
+
+// namedLLM identifies which plan's client a request was routed to
+type namedLLM struct {
+	analyzer.LLMClient
+	name string
+}
+
+func request(userID string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/interview/generate", nil)
+	r.RemoteAddr = "203.0.113.7:4242"
+	if userID != "" {
+		r.Header.Set("X-Test-User", userID)
+	}
+	return r
+}
+
+func TestUserResolver(t *testing.T) {
+	defaultPlan := NewPlan(Plan{Tier: "default", LLMClient: &namedLLM{name: "default"}}, nil)
+	free := NewPlan(Plan{Tier: Free, LLMClient: &namedLLM{name: "mini"}, RequestsPerMinute: 2}, defaultPlan)
+	paid := NewPlan(Plan{Tier: Paid, LLMClient: &namedLLM{name: "large"}}, defaultPlan)
+
+	users := &fakeUsers{users: map[int]*models.User{
+		1: {ID: 1, Tier: "free"},
+		2: {ID: 2, Tier: "PAID"},
+		3: {ID: 3, Tier: "enterprise"},
+		4: {ID: 4},
+	}}
+	resolver := NewUserResolver(headerAuth{}, users, defaultPlan, map[string]*Plan{"Free": free, "paid": paid})
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantPlan *Plan
+		wantKey  string
+	}{
+		{"free user", "1", free, "user:1"},
+		{"paid user", "2", paid, "user:2"},
+		{"tier without a plan", "3", defaultPlan, "user:3"},
+		{"user without a tier", "4", defaultPlan, "user:4"},
+		{"unknown user", "99", defaultPlan, "user:99"},
+		{"anonymous", "", defaultPlan, "addr:203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, key := resolver.Resolve(request(tt.userID))
+			if plan != tt.wantPlan {
+				t.Errorf("plan = %q, want %q", plan.Tier, tt.wantPlan.Tier)
+			}
+			if key != tt.wantKey {
+				t.Errorf("key = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestNewPlanFallsBackToDefaultClients(t *testing.T) {
+	embedder := analyzer.NewPlaceholderEmbeddingGenerator(8)
+	defaultPlan := NewPlan(Plan{LLMClient: &namedLLM{name: "default"}, Embedder: embedder}, nil)
+
+	plan := NewPlan(Plan{Tier: Free, LLMClient: &namedLLM{name: "mini"}}, defaultPlan)
+	if plan.LLMClient.(*namedLLM).name != "mini" {
+		t.Errorf("LLM = %q, want the plan's own", plan.LLMClient.(*namedLLM).name)
+	}
+	if plan.Embedder != embedder {
+		t.Errorf("embedder not inherited from the default plan")
+	}
+}
+
+func TestPlanAllow(t *testing.T) {
+	plan := NewPlan(Plan{RequestsPerMinute: 2}, nil)
+
+	for i := 0; i < 2; i++ {
+		if !plan.Allow("user:1") {
+			t.Fatalf("request %d rejected, want allowed", i+1)
+		}
+	}
+	if plan.Allow("user:1") {
+		t.Errorf("third request allowed, want rate limited")
+	}
+	if !plan.Allow("user:2") {
+		t.Errorf("another user's request rejected")
+	}
+
+	unlimited := NewPlan(Plan{}, nil)
+	for i := 0; i < 100; i++ {
+		if !unlimited.Allow("user:1") {
+			t.Fatalf("request %d rejected by an unlimited plan", i+1)
+		}
+	}
+}
+
+func TestKeyedLimiterWindow(t *testing.T) {
+	limiter := newKeyedLimiter(1, 20*time.Millisecond)
+
+	if !limiter.allow("a") || limiter.allow("a") {
+		t.Fatal("want the first request allowed and the second limited")
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	if !limiter.allow("b") {
+		t.Fatal("request rejected after the window passed")
+	}
+	if _, ok := limiter.events["a"]; ok {
+		t.Errorf("idle key kept after its window passed")
+	}
+	if !limiter.allow("a") {
+		t.Errorf("request rejected after the window passed")
+	}
+}
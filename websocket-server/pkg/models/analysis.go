@@ -1,23 +1,51 @@
 package models
 
 import (
+	"sort"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // AnalysisJob represents an asynchronous resume analysis job
 type AnalysisJob struct {
-	ID            int        `json:"id"`
-	JobID         string     `json:"job_id"`
-	UploadID      int        `json:"upload_id"`
-	UserID        *int       `json:"user_id,omitempty"` // Semantic reference to users.id
-	Status        string     `json:"status"`            // queued, extracting_text, chunking, generating_embeddings, analyzing, completed, failed
-	Progress      int        `json:"progress"`          // 0-100
-	CurrentStep   string     `json:"current_step"`      // Human-readable description
-	ExtractedText *string    `json:"extracted_text,omitempty"`
-	ErrorMessage  *string    `json:"error_message,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	ID                  int           `json:"id"`
+	JobID               string        `json:"job_id"`
+	UploadID            int           `json:"upload_id"`
+	UserID              *int          `json:"user_id,omitempty"`               // Semantic reference to users.id
+	AdditionalUploadIDs pq.Int64Array `json:"additional_upload_ids,omitempty"` // Uploads merged with UploadID into one profile
+	Status              string        `json:"status"`                          // queued, extracting_text, chunking, generating_embeddings, analyzing, completed, failed, needs_review, dead_lettered
+	Progress            int           `json:"progress"`                        // 0-100
+	CurrentStep         string        `json:"current_step"`                    // Human-readable description
+	ExtractedText       *string       `json:"extracted_text,omitempty"`
+	ErrorMessage        *string       `json:"error_message,omitempty"`
+	ErrorCode           *string       `json:"error_code,omitempty"` // One of the JobError* codes, set with ErrorMessage when the job fails
+	RetryCount          int           `json:"retry_count"`          // Times the job has been reset for retry
+	CreatedAt           time.Time     `json:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at"`
+	CompletedAt         *time.Time    `json:"completed_at,omitempty"`
+}
+
+// Error codes of failed jobs, naming the step that failed. Unlike the error message,
+// they are stable and meant for handling failures programmatically.
+const (
+	JobErrorExtraction       = "extraction_failed" // Text couldn't be extracted from a file
+	JobErrorInsufficientText = "insufficient_text" // Too little text was extracted, e.g. from a scanned document
+	JobErrorLowQualityText   = "low_quality_text"  // The extracted text scored below the quality threshold
+	JobErrorChunking         = "chunking_failed"   // The text couldn't be split into chunks
+	JobErrorEmbedding        = "embedding_failed"  // Embeddings couldn't be generated
+	JobErrorStorage          = "storage_failed"    // Embeddings or the profile couldn't be stored
+	JobErrorLLM              = "llm_failed"        // The LLM analysis failed or timed out
+	JobErrorCancelled        = "cancelled"         // The job was cancelled
+)
+
+// Finished reports whether the job has reached a final status, so no worker is processing it
+func (j *AnalysisJob) Finished() bool {
+	switch j.Status {
+	case "completed", "failed", "needs_review", "dead_lettered":
+		return true
+	}
+	return false
 }
 
 // UserProfile represents analyzed resume data
@@ -40,18 +68,21 @@ type UserProfile struct {
 	JobRecommendations []string            `json:"job_recommendations,omitempty"`
 	Strengths          []string            `json:"strengths,omitempty"`
 	Weaknesses         []string            `json:"weaknesses,omitempty"`
+	FieldConfidence    map[string]*float64 `json:"field_confidence,omitempty"`    // Extraction confidence (0-1) per field in ProfileFields, nil if not reported
+	ReportedWorkYears  *float64            `json:"reported_work_years,omitempty"` // LLM's total work years when far from the total computed from Experience
 	CreatedAt          time.Time           `json:"created_at"`
 	UpdatedAt          time.Time           `json:"updated_at"`
 }
 
 // ExperienceEntry represents a work experience entry
 type ExperienceEntry struct {
-	Company     string  `json:"company"`
-	Role        string  `json:"role"`
-	StartDate   *string `json:"start_date,omitempty"`
-	EndDate     *string `json:"end_date,omitempty"`
-	Years       float64 `json:"years"`
-	Description string  `json:"description,omitempty"`
+	Company     string   `json:"company"`
+	Role        string   `json:"role"`
+	StartDate   *string  `json:"start_date,omitempty"` // "YYYY-MM", "YYYY" or "Present" once normalized
+	EndDate     *string  `json:"end_date,omitempty"`
+	Years       float64  `json:"years"` // Recomputed from the dates when both are known
+	Description string   `json:"description,omitempty"`
+	Flags       []string `json:"flags,omitempty"` // Date problems: future_date, end_before_start, overlapping
 }
 
 // EducationEntry represents an education entry
@@ -63,15 +94,138 @@ type EducationEntry struct {
 
 // AnalysisStatus represents the current status of an analysis job (for API responses)
 type AnalysisStatus struct {
-	JobID         string     `json:"job_id"`
-	Status        string     `json:"status"`
-	Progress      int        `json:"progress"`
-	CurrentStep   string     `json:"current_step"`
-	ExtractedText *string    `json:"extracted_text,omitempty"` // Text extracted from resume
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty"`
-	ErrorMessage  *string    `json:"error_message,omitempty"`
+	JobID         string         `json:"job_id"`
+	UserID        *int           `json:"user_id,omitempty"`
+	Status        string         `json:"status"`
+	Progress      int            `json:"progress"`
+	CurrentStep   string         `json:"current_step"`
+	ExtractedText *string        `json:"extracted_text,omitempty"` // Text extracted from resume
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
+	ErrorMessage  *string        `json:"error_message,omitempty"`
+	ErrorCode     *string        `json:"error_code,omitempty"` // One of the JobError* codes for failed jobs
+	RetryCount    int            `json:"retry_count"`          // Times the job has been reset for retry
+	Basics        *ProfileBasics `json:"basics,omitempty"`     // Early results from two-pass analysis
+}
+
+// FullJob combines a job's status with its result, so clients get both in one call.
+// Result is only set once the job has completed.
+type FullJob struct {
+	Job    *AnalysisStatus `json:"job"`
+	Result *AnalysisResult `json:"result,omitempty"`
+}
+
+// FailedJob is a failed or dead-lettered analysis job with the file it was analyzing,
+// for debugging extraction and LLM failures
+type FailedJob struct {
+	JobID         string    `json:"job_id"`
+	UploadID      int       `json:"upload_id"`
+	FileName      string    `json:"file_name"`
+	MimeType      string    `json:"mime_type"`
+	Status        string    `json:"status"` // failed or dead_lettered
+	ErrorMessage  *string   `json:"error_message,omitempty"`
+	ErrorCode     *string   `json:"error_code,omitempty"` // One of the JobError* codes; nil for jobs that failed before codes were stored
+	ErrorCategory string    `json:"error_category"`       // One of the ErrorCategory* buckets of the error message
+	RetryCount    int       `json:"retry_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Error categories of failed jobs, bucketing their error messages by the keywords they contain
+const (
+	ErrorCategoryPasswordProtected = "password_protected"
+	ErrorCategoryLLMTimeout        = "llm_timeout"
+	ErrorCategoryTimeout           = "timeout"
+	ErrorCategoryCancelled         = "cancelled"
+	ErrorCategoryNoText            = "no_text"
+	ErrorCategoryLowQuality        = "low_quality"
+	ErrorCategoryUnsupportedFile   = "unsupported_file"
+	ErrorCategoryLLM               = "llm_error"
+	ErrorCategoryEmbedding         = "embedding_error"
+	ErrorCategoryOther             = "other"
+)
+
+// ErrorCategories lists every error category
+var ErrorCategories = []string{
+	ErrorCategoryPasswordProtected, ErrorCategoryLLMTimeout, ErrorCategoryTimeout,
+	ErrorCategoryCancelled, ErrorCategoryNoText, ErrorCategoryLowQuality,
+	ErrorCategoryUnsupportedFile, ErrorCategoryLLM, ErrorCategoryEmbedding, ErrorCategoryOther,
+}
+
+// FailedJobGroup counts the failed jobs of one error category
+type FailedJobGroup struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	Example  string `json:"example,omitempty"` // The category's most common error message
+}
+
+// ProfileBasics holds the contact info and skills extracted by the fast first pass of
+// a two-pass analysis, available before the full profile
+type ProfileBasics struct {
+	Name        *string             `json:"name,omitempty"`
+	Email       *string             `json:"email,omitempty"`
+	Phone       *string             `json:"phone,omitempty"`
+	LinkedInURL *string             `json:"linkedin_url,omitempty"`
+	Location    *string             `json:"location,omitempty"`
+	Skills      map[string][]string `json:"skills,omitempty"`
+}
+
+// UserAnalytics summarizes all resumes and analyses belonging to a user (for API responses)
+type UserAnalytics struct {
+	UserID           int            `json:"user_id"`
+	TotalUploads     int            `json:"total_uploads"`
+	TotalJobs        int            `json:"total_jobs"`
+	JobsByStatus     map[string]int `json:"jobs_by_status"`
+	TotalProfiles    int            `json:"total_profiles"`
+	AverageWorkYears *float64       `json:"average_work_years,omitempty"` // Across profiles that report work years
+	TopSkills        []SkillCount   `json:"top_skills"`
+}
+
+// SkillCount is the number of profiles that list a skill
+type SkillCount struct {
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+// SkillGapReport aggregates the skills a profile is missing across several job
+// descriptions (for API responses)
+type SkillGapReport struct {
+	JobID               string          `json:"job_id"`
+	JobDescriptionCount int             `json:"job_description_count"`
+	MissingSkills       []SkillGapCount `json:"missing_skills"`      // Most often missing first
+	PerJobDescription   [][]string      `json:"per_job_description"` // Missing skills of each job description, in request order
+}
+
+// SkillGapCount is a skill missing for some of the job descriptions of a SkillGapReport
+type SkillGapCount struct {
+	Skill           string `json:"skill"`
+	Count           int    `json:"count"`            // Number of job descriptions asking for it
+	JobDescriptions []int  `json:"job_descriptions"` // Indexes of those job descriptions in the request
+}
+
+// CandidateSummary is one candidate of an organization export: an upload with the
+// headline fields of its analysis. Profile fields are empty for uploads not yet analyzed.
+type CandidateSummary struct {
+	UploadID         int        `json:"upload_id"`
+	FileName         string     `json:"file_name"`
+	Tags             []string   `json:"tags"`
+	JobID            *string    `json:"job_id,omitempty"`
+	Status           string     `json:"status"` // Status of the upload's analysis job, or UploadStatusNotAnalyzed
+	Name             *string    `json:"name,omitempty"`
+	TopSkills        []string   `json:"top_skills"`
+	TotalWorkYears   *float64   `json:"total_work_years,omitempty"`
+	RecommendedRoles []string   `json:"recommended_roles"`
+	AnalyzedAt       *time.Time `json:"analyzed_at,omitempty"` // When the profile was last saved
+}
+
+// SimilarProfile is a profile of the same organization resembling another one, for
+// "similar candidates" suggestions
+type SimilarProfile struct {
+	JobID    string  `json:"job_id"`
+	UploadID int     `json:"upload_id"`
+	Name     *string `json:"name,omitempty"`
+	Score    float32 `json:"score"` // Best similarity of the profile's resume chunks (1 = identical)
 }
 
 // AnalysisResult represents the complete analysis result (for API responses)
@@ -94,6 +248,63 @@ type AnalysisResult struct {
 	JobRecommendations []string            `json:"job_recommendations,omitempty"`
 	Strengths          []string            `json:"strengths,omitempty"`
 	Weaknesses         []string            `json:"weaknesses,omitempty"`
+	FieldConfidence    map[string]*float64 `json:"field_confidence"`              // Every field in ProfileFields; null where the LLM gave no confidence
+	ReportedWorkYears  *float64            `json:"reported_work_years,omitempty"` // LLM's total work years when far from TotalWorkYears
 	CreatedAt          time.Time           `json:"created_at"`
 	CompletedAt        *time.Time          `json:"completed_at,omitempty"`
 }
+
+// ToProfile converts an AnalysisResult back into a UserProfile (e.g., for exporting)
+func (r *AnalysisResult) ToProfile() *UserProfile {
+	return &UserProfile{
+		UploadID:           r.UploadID,
+		JobID:              r.JobID,
+		Name:               r.Name,
+		Email:              r.Email,
+		Phone:              r.Phone,
+		LinkedInURL:        r.LinkedInURL,
+		Age:                r.Age,
+		Race:               r.Race,
+		Location:           r.Location,
+		TotalWorkYears:     r.TotalWorkYears,
+		Skills:             r.Skills,
+		Experience:         r.Experience,
+		Education:          r.Education,
+		Summary:            r.Summary,
+		JobRecommendations: r.JobRecommendations,
+		Strengths:          r.Strengths,
+		Weaknesses:         r.Weaknesses,
+		FieldConfidence:    r.FieldConfidence,
+		ReportedWorkYears:  r.ReportedWorkYears,
+		CreatedAt:          r.CreatedAt,
+	}
+}
+
+// ProfileFields lists the extracted profile fields, by their JSON name, that carry a
+// confidence score
+var ProfileFields = []string{
+	"name", "email", "phone", "linkedin_url", "location", "skills",
+	"age", "race", "total_work_years", "experience", "education",
+	"summary", "job_recommendations", "strengths", "weaknesses",
+}
+
+// CompleteFieldConfidence returns a copy of confidence with an entry for every field
+// in ProfileFields, nil for fields without a score
+func CompleteFieldConfidence(confidence map[string]*float64) map[string]*float64 {
+	complete := make(map[string]*float64, len(ProfileFields))
+	for _, field := range ProfileFields {
+		complete[field] = confidence[field]
+	}
+	return complete
+}
+
+// SkillCategories returns the categories of a skills map in sorted order, so
+// output built from the map is the same on every run
+func SkillCategories(skills map[string][]string) []string {
+	categories := make([]string, 0, len(skills))
+	for category := range skills {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
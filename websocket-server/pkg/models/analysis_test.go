@@ -0,0 +1,27 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSkillCategories(t *testing.T) {
+	skills := map[string][]string{
+		"Tools":      {"Docker"},
+		"Languages":  {"Go"},
+		"Frameworks": {"React"},
+		"Databases":  {"Postgres"},
+		"Cloud":      nil,
+	}
+	want := []string{"Cloud", "Databases", "Frameworks", "Languages", "Tools"}
+
+	for i := 0; i < 20; i++ {
+		if got := SkillCategories(skills); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SkillCategories = %v, want %v", got, want)
+		}
+	}
+
+	if got := SkillCategories(nil); len(got) != 0 {
+		t.Errorf("SkillCategories(nil) = %v, want none", got)
+	}
+}
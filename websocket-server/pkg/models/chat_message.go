@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -54,13 +55,82 @@ type ChatMessageMetadata struct {
 	FileSize int    `json:"file_size,omitempty"`
 }
 
+// knownMetadataFields lists the JSON keys accepted by ParseChatMessageMetadata
+var knownMetadataFields = map[string]bool{
+	"duration_ms":      true,
+	"mime_type":        true,
+	"sample_rate":      true,
+	"width":            true,
+	"height":           true,
+	"from_qa":          true,
+	"similarity":       true,
+	"matched_question": true,
+	"question":         true, // Legacy alias for matched_question sent by WebSocket Q&A responses
+	"file_name":        true,
+	"file_size":        true,
+}
+
+// ParseChatMessageMetadata decodes client supplied metadata, rejecting unknown fields,
+// and validates the result. Empty or null input yields nil metadata.
+func ParseChatMessageMetadata(raw json.RawMessage) (*ChatMessageMetadata, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object: %w", err)
+	}
+	for key := range fields {
+		if !knownMetadataFields[key] {
+			return nil, fmt.Errorf("unknown metadata field %q", key)
+		}
+	}
+
+	var meta ChatMessageMetadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	if question, ok := fields["question"]; ok && meta.MatchedQuestion == "" {
+		if err := json.Unmarshal(question, &meta.MatchedQuestion); err != nil {
+			return nil, fmt.Errorf("invalid metadata field \"question\": %w", err)
+		}
+	}
+
+	if err := meta.Validate(); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// Validate checks that metadata values are within their allowed ranges
+func (m *ChatMessageMetadata) Validate() error {
+	if m.Similarity < 0 || m.Similarity > 1 || math.IsNaN(m.Similarity) {
+		return fmt.Errorf("similarity must be between 0 and 1, got %v", m.Similarity)
+	}
+	if m.DurationMs < 0 {
+		return fmt.Errorf("duration_ms must not be negative")
+	}
+	if m.SampleRate < 0 {
+		return fmt.Errorf("sample_rate must not be negative")
+	}
+	if m.Width < 0 || m.Height < 0 {
+		return fmt.Errorf("width and height must not be negative")
+	}
+	if m.FileSize < 0 {
+		return fmt.Errorf("file_size must not be negative")
+	}
+	return nil
+}
+
 // SendTextMessageRequest represents a request to send a text message
 type SendTextMessageRequest struct {
-	UserID      int                  `json:"user_id"`
-	ToUserID    int                  `json:"to_user_id"`
-	TextContent string               `json:"text_content"`
-	SessionID   *string              `json:"session_id,omitempty"`
-	Metadata    *ChatMessageMetadata `json:"metadata,omitempty"`
+	UserID      int             `json:"user_id"`
+	ToUserID    int             `json:"to_user_id"`
+	TextContent string          `json:"text_content"`
+	SessionID   *string         `json:"session_id,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"` // Validated with ParseChatMessageMetadata
 }
 
 // SendAudioMessageRequest represents a request to send an audio message
@@ -97,14 +167,6 @@ type GetMessagesRequest struct {
 	Before    *int64  `json:"before,omitempty"` // Get messages before this ID
 }
 
-// GetMessagesResponse represents the response for getting messages
-type GetMessagesResponse struct {
-	Messages []ChatMessageResponse `json:"messages"`
-	Total    int                   `json:"total"`
-	Limit    int                   `json:"limit"`
-	Offset   int                   `json:"offset"`
-}
-
 // IsFromSystem returns true if the message is from the system
 func (m *ChatMessage) IsFromSystem() bool {
 	return m.UserID == SystemUserID
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseChatMessageMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    *ChatMessageMetadata
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"null", "null", nil, false},
+		{"audio", `{"duration_ms":1500,"mime_type":"audio/webm","sample_rate":48000}`, &ChatMessageMetadata{DurationMs: 1500, MimeType: "audio/webm", SampleRate: 48000}, false},
+		{"q&a match", `{"from_qa":true,"similarity":0.87,"matched_question":"Why Go?"}`, &ChatMessageMetadata{FromQA: true, Similarity: 0.87, MatchedQuestion: "Why Go?"}, false},
+		{"legacy question field", `{"from_qa":true,"similarity":1,"question":"Why Go?"}`, &ChatMessageMetadata{FromQA: true, Similarity: 1, MatchedQuestion: "Why Go?"}, false},
+		{"matched question wins over legacy field", `{"matched_question":"New","question":"Old"}`, &ChatMessageMetadata{MatchedQuestion: "New"}, false},
+		{"similarity zero", `{"similarity":0}`, &ChatMessageMetadata{}, false},
+		{"similarity above 1", `{"similarity":1.2}`, nil, true},
+		{"negative similarity", `{"similarity":-0.1}`, nil, true},
+		{"similarity as string", `{"similarity":"high"}`, nil, true},
+		{"from_qa as string", `{"from_qa":"yes"}`, nil, true},
+		{"unknown field", `{"similarity":0.5,"score":3}`, nil, true},
+		{"negative duration", `{"duration_ms":-1}`, nil, true},
+		{"negative size", `{"width":-10,"height":5}`, nil, true},
+		{"not an object", `[1,2]`, nil, true},
+		{"malformed", `{"similarity":`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChatMessageMetadata(json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadata = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatMessageMetadataValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    ChatMessageMetadata
+		wantErr bool
+	}{
+		{"zero value", ChatMessageMetadata{}, false},
+		{"similarity 1", ChatMessageMetadata{FromQA: true, Similarity: 1}, false},
+		{"similarity NaN", ChatMessageMetadata{Similarity: math.NaN()}, true},
+		{"similarity above 1", ChatMessageMetadata{Similarity: 1.01}, true},
+		{"negative sample rate", ChatMessageMetadata{SampleRate: -1}, true},
+		{"negative file size", ChatMessageMetadata{FileSize: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.meta.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
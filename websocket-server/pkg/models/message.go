@@ -17,4 +17,14 @@ const (
 	MessageTypeMessage = "message"
 	MessageTypeSystem  = "system"
 	MessageTypeError   = "error"
+
+	// Inbound message types sent by clients
+	MessageTypeChat   = "chat"    // Chat query (MessageTypeMessage is accepted as an alias)
+	MessageTypeTyping = "typing"  // Typing indicator, no reply
+	MessageTypeResume = "resume"  // Resume a previous session after reconnecting
+	MessageTypePing   = "ping"    // Application-level keepalive, answered with MessageTypePong
+	MessageTypeLoadQA = "load_qa" // Load saved Q&A pairs for this client; metadata holds user_id, job_id and limit
+
+	MessageTypePong  = "pong"
+	MessageTypeChunk = "message_chunk" // Piece of a streamed chat reply, followed by the complete MessageTypeMessage
 )
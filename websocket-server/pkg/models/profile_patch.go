@@ -0,0 +1,51 @@
+package models
+
+// ProfilePatch is a partial update of a UserProfile. Only the fields that are set are
+// changed; nil fields keep their stored value. Since a JSON null decodes to nil, a patch
+// can't clear a field, but it can set a list to an empty one.
+type ProfilePatch struct {
+	Name               *string              `json:"name,omitempty"`
+	Email              *string              `json:"email,omitempty"`
+	Phone              *string              `json:"phone,omitempty"`
+	LinkedInURL        *string              `json:"linkedin_url,omitempty"`
+	Age                *int                 `json:"age,omitempty"`
+	Race               *string              `json:"race,omitempty"`
+	Location           *string              `json:"location,omitempty"`
+	TotalWorkYears     *float64             `json:"total_work_years,omitempty"`
+	Skills             *map[string][]string `json:"skills,omitempty"`
+	Experience         *[]ExperienceEntry   `json:"experience,omitempty"`
+	Education          *[]EducationEntry    `json:"education,omitempty"`
+	Summary            *string              `json:"summary,omitempty"`
+	JobRecommendations *[]string            `json:"job_recommendations,omitempty"`
+	Strengths          *[]string            `json:"strengths,omitempty"`
+	Weaknesses         *[]string            `json:"weaknesses,omitempty"`
+}
+
+// Fields returns the JSON names of the fields the patch sets, in ProfileFields order
+func (p *ProfilePatch) Fields() []string {
+	set := map[string]bool{
+		"name":                p.Name != nil,
+		"email":               p.Email != nil,
+		"phone":               p.Phone != nil,
+		"linkedin_url":        p.LinkedInURL != nil,
+		"location":            p.Location != nil,
+		"skills":              p.Skills != nil,
+		"age":                 p.Age != nil,
+		"race":                p.Race != nil,
+		"total_work_years":    p.TotalWorkYears != nil,
+		"experience":          p.Experience != nil,
+		"education":           p.Education != nil,
+		"summary":             p.Summary != nil,
+		"job_recommendations": p.JobRecommendations != nil,
+		"strengths":           p.Strengths != nil,
+		"weaknesses":          p.Weaknesses != nil,
+	}
+
+	var fields []string
+	for _, field := range ProfileFields {
+		if set[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -30,7 +34,7 @@ type SaveQuestionRequest struct {
 	AuthUserID *int     `json:"auth_user_id,omitempty"` // Reference to authenticated user
 	UserID     string   `json:"user_id"`
 	JobID      string   `json:"job_id"`
-	QuestionID string   `json:"question_id"`
+	QuestionID string   `json:"question_id"` // Derived with QuestionID when empty
 	Question   string   `json:"question"`
 	Answer     string   `json:"answer"`
 	Category   string   `json:"category"`
@@ -39,3 +43,154 @@ type SaveQuestionRequest struct {
 	JobTitle   string   `json:"job_title"`
 	Company    string   `json:"company"`
 }
+
+// SavedQuestionRef identifies a question of a job
+type SavedQuestionRef struct {
+	JobID      string `json:"job_id"`
+	QuestionID string `json:"question_id"`
+}
+
+// CheckSavedBatchRequest asks which of a user's questions are saved
+type CheckSavedBatchRequest struct {
+	UserID    string             `json:"user_id"`
+	Questions []SavedQuestionRef `json:"questions"`
+}
+
+// ReassignResult reports how a user's saved questions were copied or moved to another job
+type ReassignResult struct {
+	Reassigned int      `json:"reassigned"` // Questions copied or moved to the target job
+	Skipped    int      `json:"skipped"`    // Questions already saved under the target job
+	Removed    int      `json:"removed"`    // Questions removed from the source job (moves only)
+	SkippedIDs []string `json:"skipped_question_ids,omitempty"`
+}
+
+// TagCount is a tag and how many of a user's saved questions have it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// QuestionID derives the ID of a question of a job from its text, ignoring case and
+// whitespace. Generated questions get their IDs this way, so IDs don't collide across
+// generations for the same job and saving the same question again updates it.
+func QuestionID(jobID, question string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(question)), " ")
+	sum := sha256.Sum256([]byte(jobID + "\x00" + normalized))
+	return "q_" + hex.EncodeToString(sum[:8])
+}
+
+// Canonical interview question categories
+const (
+	CategoryTechnical      = "Technical"
+	CategoryBehavioral     = "Behavioral"
+	CategorySituational    = "Situational"
+	CategoryProblemSolving = "Problem-Solving"
+)
+
+// Canonical interview question difficulties
+const (
+	DifficultyEasy   = "Easy"
+	DifficultyMedium = "Medium"
+	DifficultyHard   = "Hard"
+)
+
+// QuestionCategories lists the canonical categories
+var QuestionCategories = []string{CategoryTechnical, CategoryBehavioral, CategorySituational, CategoryProblemSolving}
+
+// QuestionDifficulties lists the canonical difficulties
+var QuestionDifficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard}
+
+// categoryAliases maps normalized spellings (see enumKey) to canonical categories
+var categoryAliases = map[string]string{
+	"technical":       CategoryTechnical,
+	"tech":            CategoryTechnical,
+	"technology":      CategoryTechnical,
+	"behavioral":      CategoryBehavioral,
+	"behavioural":     CategoryBehavioral,
+	"behavior":        CategoryBehavioral,
+	"behaviour":       CategoryBehavioral,
+	"situational":     CategorySituational,
+	"situation":       CategorySituational,
+	"scenario":        CategorySituational,
+	"scenario based":  CategorySituational,
+	"problem solving": CategoryProblemSolving,
+	"problemsolving":  CategoryProblemSolving,
+	"problem":         CategoryProblemSolving,
+}
+
+// difficultyAliases maps normalized spellings (see enumKey) to canonical difficulties
+var difficultyAliases = map[string]string{
+	"easy":         DifficultyEasy,
+	"simple":       DifficultyEasy,
+	"basic":        DifficultyEasy,
+	"beginner":     DifficultyEasy,
+	"medium":       DifficultyMedium,
+	"moderate":     DifficultyMedium,
+	"intermediate": DifficultyMedium,
+	"mid":          DifficultyMedium,
+	"hard":         DifficultyHard,
+	"difficult":    DifficultyHard,
+	"advanced":     DifficultyHard,
+	"challenging":  DifficultyHard,
+}
+
+// NormalizeQuestionCategory maps a category, including common variant spellings such
+// as "behavioural" or "problem solving", to its canonical form. An empty category
+// stays empty; unknown values are an error.
+func NormalizeQuestionCategory(category string) (string, error) {
+	return normalizeEnum(category, categoryAliases, "category", QuestionCategories)
+}
+
+// NormalizeQuestionDifficulty maps a difficulty, including variants such as
+// "intermediate", to its canonical form. An empty difficulty stays empty; unknown
+// values are an error.
+func NormalizeQuestionDifficulty(difficulty string) (string, error) {
+	return normalizeEnum(difficulty, difficultyAliases, "difficulty", QuestionDifficulties)
+}
+
+// CanonicalQuestionTag returns the lowercase canonical form of a tag naming a category
+// or difficulty (e.g. "behavioural" becomes "behavioral"), and other tags unchanged
+func CanonicalQuestionTag(tag string) string {
+	key := enumKey(tag)
+	if canonical, ok := categoryAliases[key]; ok {
+		return strings.ToLower(canonical)
+	}
+	if canonical, ok := difficultyAliases[key]; ok {
+		return strings.ToLower(canonical)
+	}
+	return tag
+}
+
+// Normalize canonicalizes the request's category and difficulty, rejecting unknown values
+func (r *SaveQuestionRequest) Normalize() error {
+	category, err := NormalizeQuestionCategory(r.Category)
+	if err != nil {
+		return err
+	}
+	difficulty, err := NormalizeQuestionDifficulty(r.Difficulty)
+	if err != nil {
+		return err
+	}
+	r.Category, r.Difficulty = category, difficulty
+	return nil
+}
+
+// normalizeEnum looks up value in aliases, returning an error listing allowed for unknown values
+func normalizeEnum(value string, aliases map[string]string, field string, allowed []string) (string, error) {
+	key := enumKey(value)
+	if key == "" {
+		return "", nil
+	}
+	if canonical, ok := aliases[key]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("invalid %s %q: must be one of %s", field, value, strings.Join(allowed, ", "))
+}
+
+// enumKey lowercases a value and collapses separators ("-", "_" and whitespace) to single spaces
+func enumKey(value string) string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return r == '-' || r == '_' || r == ' ' || r == '\t'
+	})
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeQuestionCategory(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"  ", "", false},
+		{"Technical", CategoryTechnical, false},
+		{"tech", CategoryTechnical, false},
+		{"behavioural", CategoryBehavioral, false},
+		{"BEHAVIOR", CategoryBehavioral, false},
+		{"scenario-based", CategorySituational, false},
+		{"Problem Solving", CategoryProblemSolving, false},
+		{"problem_solving", CategoryProblemSolving, false},
+		{" problem--solving ", CategoryProblemSolving, false},
+		{"trivia", "", true},
+		{"technicall", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := NormalizeQuestionCategory(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeQuestionCategory(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), CategoryProblemSolving) {
+				t.Errorf("error %q doesn't list the allowed categories", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeQuestionDifficulty(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"easy", DifficultyEasy, false},
+		{"Beginner", DifficultyEasy, false},
+		{"intermediate", DifficultyMedium, false},
+		{"MID", DifficultyMedium, false},
+		{"challenging", DifficultyHard, false},
+		{"Hard", DifficultyHard, false},
+		{"impossible", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := NormalizeQuestionDifficulty(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeQuestionDifficulty(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalQuestionTag(t *testing.T) {
+	tests := map[string]string{
+		"behavioural":     "behavioral",
+		"problem solving": "problem-solving",
+		"intermediate":    "medium",
+		"kubernetes":      "kubernetes",
+		"":                "",
+	}
+	for tag, want := range tests {
+		if got := CanonicalQuestionTag(tag); got != want {
+			t.Errorf("CanonicalQuestionTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestSaveQuestionRequestNormalize(t *testing.T) {
+	req := &SaveQuestionRequest{Category: "behavioural", Difficulty: "intermediate"}
+	if err := req.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if req.Category != CategoryBehavioral || req.Difficulty != DifficultyMedium {
+		t.Errorf("normalized to %q/%q, want %q/%q", req.Category, req.Difficulty, CategoryBehavioral, DifficultyMedium)
+	}
+
+	// An invalid value leaves the request unchanged
+	for _, req := range []*SaveQuestionRequest{
+		{Category: "trivia", Difficulty: "easy"},
+		{Category: "technical", Difficulty: "impossible"},
+	} {
+		before := *req
+		if err := req.Normalize(); err == nil {
+			t.Errorf("Normalize accepted %q/%q", before.Category, before.Difficulty)
+		}
+		if req.Category != before.Category || req.Difficulty != before.Difficulty {
+			t.Errorf("failed Normalize changed the request to %q/%q", req.Category, req.Difficulty)
+		}
+	}
+}
+
+func TestQuestionID(t *testing.T) {
+	id := QuestionID("job_1", "Why Go?")
+	if !strings.HasPrefix(id, "q_") || len(id) != len("q_")+16 {
+		t.Errorf("QuestionID = %q, want q_ and 16 hex digits", id)
+	}
+
+	tests := []struct {
+		name     string
+		jobID    string
+		question string
+		same     bool
+	}{
+		{"same text", "job_1", "Why Go?", true},
+		{"case and spacing", "job_1", "  why   GO? ", true},
+		{"other question", "job_1", "Why Rust?", false},
+		{"other job", "job_2", "Why Go?", false},
+		{"job and question boundary", "job_1W", "hy Go?", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuestionID(tt.jobID, tt.question); (got == id) != tt.same {
+				t.Errorf("QuestionID(%q, %q) = %q, same as %q: %v, want %v", tt.jobID, tt.question, got, id, got == id, tt.same)
+			}
+		})
+	}
+}
@@ -11,11 +11,21 @@ type Upload struct {
 	FileContent []byte    `json:"-"` // Excluded from JSON responses for security
 	FileSize    int       `json:"file_size"`
 	MimeType    string    `json:"mime_type"`
-	JobID       *string   `json:"job_id,omitempty"` // Optional job ID from analysis_jobs
+	JobID       *string   `json:"job_id,omitempty"`       // Optional job ID from analysis_jobs
+	JobStatus   *string   `json:"job_status,omitempty"`   // Status of the job referenced by JobID
+	JobProgress *int      `json:"job_progress,omitempty"` // Progress (0-100) of the job referenced by JobID
+	Pinned      bool      `json:"pinned"`                 // Pinned uploads are exempt from retention cleanup
+	NotifyEmail bool      `json:"notify_email"`           // Email the owner when analysis completes or fails
+	Tags        []string  `json:"tags"`                   // Lowercase labels for organizing uploads, e.g. "frontend", "referral"
+	Notes       string    `json:"notes"`                  // Free-form notes of the owner
+	TenantID    string    `json:"-"`                      // Organization of the owner, isolating its embeddings; empty for the default tenant
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// UploadStatusNotAnalyzed is the status filter value for uploads that have no analysis job
+const UploadStatusNotAnalyzed = "not_analyzed"
+
 // UploadRequest represents the data received from client upload request
 type UploadRequest struct {
 	UserID      *int    `json:"user_id,omitempty"`
@@ -32,6 +42,7 @@ type UploadResponse struct {
 	FileName    string    `json:"file_name"`
 	FileSize    int       `json:"file_size"`
 	MimeType    string    `json:"mime_type"`
+	NotifyEmail bool      `json:"notify_email"`
 	CreatedAt   time.Time `json:"created_at"`
 	Message     string    `json:"message"`
 }
@@ -4,21 +4,26 @@ import "time"
 
 // User represents a user account
 type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"` // Never expose password in JSON responses
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  int       `json:"id"`
+	Name                string    `json:"name"`
+	Email               string    `json:"email"`
+	Password            string    `json:"-"`                     // Never expose password in JSON responses
+	Tier                string    `json:"tier"`                  // Service tier selecting models and limits (e.g. free, paid)
+	TenantID            string    `json:"-"`                     // Organization the user belongs to; empty for the default tenant
+	DefaultExportFormat string    `json:"default_export_format"` // Export format used when a request names none; empty for the server default
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // UserResponse is the safe response without password
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  int       `json:"id"`
+	Name                string    `json:"name"`
+	Email               string    `json:"email"`
+	Tier                string    `json:"tier"`
+	DefaultExportFormat string    `json:"default_export_format,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // SignupRequest represents the signup request body
@@ -28,6 +33,11 @@ type SignupRequest struct {
 	Password string `json:"password"`
 }
 
+// ExportFormatRequest represents the request body setting a user's default export format
+type ExportFormatRequest struct {
+	Format string `json:"format"` // Empty clears the preference
+}
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -45,10 +55,12 @@ type AuthResponse struct {
 // ToResponse converts User to UserResponse (safe for JSON)
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:                  u.ID,
+		Name:                u.Name,
+		Email:               u.Email,
+		Tier:                u.Tier,
+		DefaultExportFormat: u.DefaultExportFormat,
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
 	}
 }